@@ -2,6 +2,7 @@
 package main
 
 import (
+    "context"
     "os"
     "log"
 
@@ -13,9 +14,16 @@ import (
     // "path/filepath"
 
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/config"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/keyring"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/ratelimit"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/routes"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/scheduler"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/wechat"
 )
 
 func main() {
@@ -48,6 +56,10 @@ func main() {
         &models.NutritionIntake{},
         &models.CarbonIntake{},
         &models.RefreshToken{},
+        &models.Session{},
+        &models.SigningKey{},
+        &models.UserIdentity{},
+        &models.LoginCode{},
         &models.FamilyDish{},
         &models.DislikedFoodPreference{},
         &models.UserRecipeHistory{},
@@ -57,6 +69,23 @@ func main() {
         &models.Draft{},
         &models.DraftImage{},
         &models.DraftParagraph{},
+        &models.DraftRevision{},
+        &models.NewsRevision{},
+        &models.NewsBlock{},
+        &models.DraftBlock{},
+        &models.UploadedImage{},
+        &models.Notification{},
+        &models.NewsView{},
+        &models.NewsDailyStat{},
+        &models.AuthorDailyStat{},
+        &models.UserFollow{},
+        &models.UserBlock{},
+        &models.Collection{},
+        &models.CollectionItem{},
+        &models.ShareCode{},
+        &models.ShareEvent{},
+        &models.PrivacySettings{},
+        &models.DietaryProfile{},
     )
     if err != nil {
         log.Fatal("自动迁移失败:", err)
@@ -67,12 +96,42 @@ func main() {
     router := gin.Default()
     router.MaxMultipartMemory = 8 << 20 
 
-    // 配置静态文件服务
-    BaseUploadPath := os.Getenv("BASE_UPLOAD_PATH")
-    if BaseUploadPath == "" {
-        BaseUploadPath = "./upload" // 默认路径
+    // 配置信任的反向代理（TRUSTED_PROXIES / TRUSTED_PLATFORM），默认不信任任何代理，按 IP 限流时不采信客户端伪造的 X-Forwarded-For
+    if err := middleware.ConfigureTrustedProxies(router); err != nil {
+        log.Fatal("反向代理配置错误:", err)
     }
-    router.Static("/static", BaseUploadPath)
+
+    // 配置上传文件的存储后端（STORAGE_BACKEND=local / s3）及静态文件服务
+    store, err := storage.FromEnv()
+    if err != nil {
+        log.Fatal("存储后端配置错误:", err)
+    }
+    storage.SetDefault(store)
+    routes.RegisterStaticRoutes(router, store)
+
+    // 加载 JWT 签名密钥（JWT_SECRET / JWT_KEYS_DIR），开启轮换时用 JWT_KEY_ENCRYPTION_KEY 解密并同步数据库中的密钥后再提供服务
+    signingKeys, err := keyring.FromEnv()
+    if err != nil {
+        log.Fatal("JWT 签名密钥配置错误:", err)
+    }
+    keyRotationInterval := keyring.RotationInterval()
+    if keyRotationInterval > 0 {
+        if err := signingKeys.Sync(db, keyRotationInterval, time.Now()); err != nil {
+            log.Fatal("同步 JWT 签名密钥失败:", err)
+        }
+    }
+    keyring.SetDefault(signingKeys)
+    routes.RegisterJWKSRoutes(router, signingKeys)
+
+    // 微信接口客户端（APP_ID / APP_SECRET），所有请求共用缓存的 access_token
+    wechat.SetDefault(wechat.NewClient(wechat.ConfigFromEnv()))
+
+    // 限流令牌桶的存储后端（RATE_LIMIT_BACKEND=memory / redis），多实例部署时使用 redis
+    rateLimitStore, err := ratelimit.FromEnv()
+    if err != nil {
+        log.Fatal("限流配置错误:", err)
+    }
+    ratelimit.SetDefault(rateLimitStore)
 
     // 配置CORS
     router.Use(cors.New(cors.Config{
@@ -83,29 +142,67 @@ func main() {
         MaxAge:           12 * time.Hour,  // 最大缓存时长
     }))
 
-    // 注册用户路由
-    utilsImpl := utils.UtilsImpl{} // 实现的真实 utils 方法
-    routes.RegisterUserRoutes(router, db, utilsImpl)
-
-    // 注册新闻路由
-    routes.RegisterNewsRoutes(router, db)
-
-    // 注册食物路由
-    routes.RegisterFoodRoutes(router, db)
-
-    // 注册家庭路由
-    routes.RegisterFamilyRoutes(router, db)
+    // 为每个请求分配请求 ID（X-Request-ID）
+    router.Use(middleware.RequestID())
 
-    // 注册食材偏好路由
-    routes.RegisterFoodPreferenceRoutes(router, db)
-
-    // 注册食材推荐路由
-    routes.RegisterRecommendRoutes(router, db)
-
-    // 注册营养和碳排放路由
-    routes.RegisterNutritionCarbonRoutes(router, db)
+    // 业务路由同时注册在原路径和 /api/v1 下：/api/v1 返回统一的响应格式和错误码，
+    // 原路径保持旧的响应格式，供尚未迁移的客户端使用
+    utilsImpl := utils.UtilsImpl{} // 实现的真实 utils 方法
+    apiV1 := router.Group(routes.APIPrefix, middleware.APIEnvelope())
+    for _, api := range []gin.IRouter{router, apiV1} {
+        routes.RegisterAPIRoutes(api, db, utilsImpl)
+    }
 
-    routes.RegisterAIRoutes(router, db)
+    // 接口文档：/openapi.json 和浏览页面 /docs
+    routes.RegisterOpenAPIRoutes(router)
+
+    // 启动后台任务
+    jobs := scheduler.New()
+    newsController := controllers.NewNewsController(db)
+    jobs.Add(scheduler.Job{
+        Name:     "publish_scheduled_drafts",
+        Interval: time.Minute,
+        Run: func(now time.Time) error {
+            _, err := newsController.PublishDueDrafts(now)
+            return err
+        },
+    })
+    uploadGCController := controllers.NewUploadGCController(db)
+    jobs.Add(scheduler.Job{
+        Name:     "collect_orphan_uploads",
+        Interval: 24 * time.Hour,
+        Run: func(now time.Time) error {
+            report, err := uploadGCController.CollectOrphanUploads(context.Background(), now, controllers.UploadGCGracePeriod(), false)
+            if err != nil {
+                return err
+            }
+            log.Printf("上传文件垃圾回收：删除 %d 个文件（%d 字节），%d 个孤立文件未超过宽限期", report.Deleted, report.FreedBytes, report.Pending)
+            return nil
+        },
+    })
+    accountController := controllers.NewAccountController(db)
+    jobs.Add(scheduler.Job{
+        Name:     "purge_deleted_accounts",
+        Interval: time.Hour,
+        Run: func(now time.Time) error {
+            purged, err := accountController.PurgeDueAccounts(context.Background(), now)
+            if purged > 0 {
+                log.Printf("注销 %d 个宽限期已结束的账号", purged)
+            }
+            return err
+        },
+    })
+    if keyRotationInterval > 0 {
+        jobs.Add(scheduler.Job{
+            Name:     "rotate_signing_keys",
+            Interval: time.Minute, // 每分钟同步一次，其他实例轮换出的新密钥最多一分钟后可用于验证
+            Run: func(now time.Time) error {
+                return signingKeys.Sync(db, keyRotationInterval, now)
+            },
+        })
+    }
+    jobs.Start()
+    defer jobs.Stop()
 
     // 启动服务器
     BaseSSLPath := os.Getenv("BASE_SSL_PATH")
@@ -7,6 +7,7 @@ import (
     "time"
 
     "github.com/joho/godotenv"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/ratelimit"
 )
 
 type Config struct {
@@ -33,8 +34,7 @@ func GetConfig() Config {
     }
 }
 
-// TODO 改为环境变量
-var JWTSecretKey = []byte("your_secret_key") // JWT 签名密钥
+// JWT 签名密钥从环境变量加载，见 internal/keyring.FromEnv
 
 // JWT 配置
 var JWTConfig = struct {
@@ -44,4 +44,28 @@ var JWTConfig = struct {
     AccessTokenExpiration:  30 * time.Minute, // Access Token 过期时间 TODO 改回 15 min
     // RefreshTokenExpiration: 7 * 24 * time.Hour, // Refresh Token 过期时间
     RefreshTokenExpiration: 7 * 24 * time.Hour, // Refresh Token 过期时间
-}
\ No newline at end of file
+}
+
+// 匿名访问公开新闻接口的限流配置（按 IP），可用环境变量 RATE_LIMIT_PUBLIC 覆盖
+var PublicRateLimit = ratelimit.Limit{
+    RequestsPerMinute: 60, // 每分钟补充的请求数
+    Burst:             20, // 允许的突发请求数
+}
+
+// 各路由组的默认限流配置，登录用户按用户 ID、匿名请求按 IP 计数；
+// 可用环境变量 RATE_LIMIT_<组名>=每分钟请求数,突发数 覆盖，如 RATE_LIMIT_AI=10,3
+var RateLimits = struct {
+    Auth    ratelimit.Limit // 登录、注册、发送验证码，会调用微信接口或发送短信邮件
+    AI      ratelimit.Limit // 调用付费的大模型接口
+    Comment ratelimit.Limit // 发表和编辑评论
+    Like    ratelimit.Limit // 点赞、收藏、点踩
+    Upload  ratelimit.Limit // 上传图片和头像
+    Share   ratelimit.Limit // 分享新闻，计入作者的分享统计
+}{
+    Auth:    ratelimit.Limit{RequestsPerMinute: 10, Burst: 5},
+    AI:      ratelimit.Limit{RequestsPerMinute: 10, Burst: 3},
+    Comment: ratelimit.Limit{RequestsPerMinute: 10, Burst: 5},
+    Like:    ratelimit.Limit{RequestsPerMinute: 60, Burst: 20},
+    Upload:  ratelimit.Limit{RequestsPerMinute: 20, Burst: 5},
+    Share:   ratelimit.Limit{RequestsPerMinute: 20, Burst: 10},
+}
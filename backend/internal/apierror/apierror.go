@@ -0,0 +1,161 @@
+// internal/apierror/apierror.go
+package apierror
+
+import (
+    "net/http"
+    "strings"
+    "unicode"
+
+    "github.com/gin-gonic/gin"
+)
+
+// ContextKey 控制器写入的 *Error 在 gin.Context 中的键，/api/v1 的响应信封据此生成错误码
+const ContextKey = "api_error"
+
+// 支持的语言
+const (
+    LangZh = "zh"
+    LangEn = "en"
+)
+
+// Error 带 HTTP 状态码和稳定错误码的接口错误。
+// Message 为旧接口返回的错误信息，迁移期间保持不变；MessageEn、MessageZh 为错误码对应的通用提示
+type Error struct {
+    Status    int
+    Code      string
+    Message   string
+    MessageEn string
+    MessageZh string
+}
+
+// New 创建错误，message 同时作为旧接口的错误信息和英文提示
+func New(status int, code, message, messageZh string) *Error {
+    return &Error{Status: status, Code: code, Message: message, MessageEn: message, MessageZh: messageZh}
+}
+
+func (e *Error) Error() string {
+    return e.Message
+}
+
+// WithMessage 返回错误码相同、旧接口错误信息为 message 的副本
+func (e *Error) WithMessage(message string) *Error {
+    copied := *e
+    copied.Message = message
+    return &copied
+}
+
+// Localize 返回 lang 语言的提示：Message 已是该语言时直接使用，否则使用错误码对应的通用提示
+func (e *Error) Localize(lang string) string {
+    chinese := containsHan(e.Message)
+    if lang == LangZh {
+        if chinese || e.MessageZh == "" {
+            return e.Message
+        }
+        return e.MessageZh
+    }
+    if !chinese || e.MessageEn == "" {
+        return e.Message
+    }
+    return e.MessageEn
+}
+
+// 通用错误，按 HTTP 状态码划分
+var (
+    ErrInvalidRequest   = New(http.StatusBadRequest, "invalid_request", "Invalid request body", "请求数据格式错误")
+    ErrInvalidParameter = New(http.StatusBadRequest, "invalid_parameter", "Invalid parameter", "请求参数无效")
+    ErrAlreadyExists    = New(http.StatusBadRequest, "already_exists", "Already exists", "记录已存在")
+    ErrNotAllowed       = New(http.StatusBadRequest, "operation_not_allowed", "Operation not allowed", "不允许执行该操作")
+    ErrUnauthorized     = New(http.StatusUnauthorized, "unauthorized", "Unauthorized", "用户未认证")
+    ErrInvalidToken     = New(http.StatusUnauthorized, "invalid_token", "Invalid token", "登录凭证无效或已过期")
+    ErrForbidden        = New(http.StatusForbidden, "forbidden", "Forbidden", "没有权限执行该操作")
+    ErrNotFound         = New(http.StatusNotFound, "not_found", "Not found", "请求的资源不存在")
+    ErrConflict         = New(http.StatusConflict, "conflict", "Conflict", "资源冲突")
+    ErrPayloadTooLarge  = New(http.StatusRequestEntityTooLarge, "payload_too_large", "Payload too large", "上传的内容过大")
+    ErrTooManyRequests  = New(http.StatusTooManyRequests, "too_many_requests", "Too many requests", "请求过于频繁，请稍后再试")
+    ErrInternal         = New(http.StatusInternalServerError, "internal_error", "Internal server error", "服务器内部错误")
+    ErrUpstream         = New(http.StatusInternalServerError, "upstream_error", "Upstream service error", "外部服务调用失败")
+)
+
+// 业务错误
+var (
+    ErrUserNotFound       = New(http.StatusNotFound, "user_not_found", "User not found", "用户不存在")
+    ErrNewsNotFound       = New(http.StatusNotFound, "news_not_found", "News not found", "新闻不存在")
+    ErrCommentNotFound    = New(http.StatusNotFound, "comment_not_found", "Comment not found", "评论不存在")
+    ErrDraftNotFound      = New(http.StatusNotFound, "draft_not_found", "Draft not found", "草稿不存在")
+    ErrCollectionNotFound = New(http.StatusNotFound, "collection_not_found", "Collection not found", "收藏夹不存在")
+    ErrFamilyNotFound     = New(http.StatusNotFound, "family_not_found", "Family not found", "家庭不存在")
+    ErrNotInFamily        = New(http.StatusBadRequest, "not_in_family", "You are not part of any family", "你还没有加入任何家庭")
+    ErrNotFamilyAdmin     = New(http.StatusForbidden, "not_family_admin", "You are not an admin of this family", "你不是该家庭的管理员")
+)
+
+// FromStatus 返回 HTTP 状态码对应的通用错误，用于没有写入 *Error 的错误响应
+func FromStatus(status int) *Error {
+    switch {
+    case status == http.StatusBadRequest:
+        return ErrInvalidParameter
+    case status == http.StatusUnauthorized:
+        return ErrUnauthorized
+    case status == http.StatusForbidden:
+        return ErrForbidden
+    case status == http.StatusNotFound:
+        return ErrNotFound
+    case status == http.StatusConflict:
+        return ErrConflict
+    case status == http.StatusRequestEntityTooLarge:
+        return ErrPayloadTooLarge
+    case status == http.StatusTooManyRequests:
+        return ErrTooManyRequests
+    case status >= http.StatusInternalServerError:
+        return ErrInternal
+    default:
+        return New(status, "error", http.StatusText(status), http.StatusText(status))
+    }
+}
+
+// Respond 写入错误响应。旧路由返回 {"error": Message}，/api/v1 路由由响应信封改写为统一格式
+func Respond(c *gin.Context, err *Error) {
+    c.Set(ContextKey, err)
+    c.JSON(err.Status, gin.H{"error": err.Message})
+}
+
+// Abort 写入错误响应并中止后续处理，用于中间件
+func Abort(c *gin.Context, err *Error) {
+    Respond(c, err)
+    c.Abort()
+}
+
+// FromContext 返回 Respond 写入的错误
+func FromContext(c *gin.Context) (*Error, bool) {
+    value, exists := c.Get(ContextKey)
+    if !exists {
+        return nil, false
+    }
+    err, ok := value.(*Error)
+    return err, ok
+}
+
+// Language 根据 lang 查询参数或 Accept-Language 请求头选择提示语言，默认中文
+func Language(c *gin.Context) string {
+    if lang := c.Query("lang"); lang == LangZh || lang == LangEn {
+        return lang
+    }
+    for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+        tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+        switch {
+        case strings.HasPrefix(tag, LangZh):
+            return LangZh
+        case strings.HasPrefix(tag, LangEn):
+            return LangEn
+        }
+    }
+    return LangZh
+}
+
+func containsHan(s string) bool {
+    for _, r := range s {
+        if unicode.Is(unicode.Han, r) {
+            return true
+        }
+    }
+    return false
+}
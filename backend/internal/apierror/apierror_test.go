@@ -0,0 +1,149 @@
+// internal/apierror/apierror_test.go
+package apierror_test
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+)
+
+func setupEnvelopeRouter() *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.Use(middleware.RequestID())
+    register := func(api gin.IRouter) {
+        api.GET("/ok", func(c *gin.Context) {
+            c.JSON(http.StatusOK, gin.H{"id": 1})
+        })
+        api.GET("/news", func(c *gin.Context) {
+            apierror.Respond(c, apierror.ErrNewsNotFound)
+        })
+        api.GET("/liked", func(c *gin.Context) {
+            apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("You have already liked this news"))
+        })
+        api.GET("/legacy", func(c *gin.Context) {
+            c.JSON(http.StatusForbidden, gin.H{"error": "用户未认证"})
+        })
+        api.GET("/file", func(c *gin.Context) {
+            c.Data(http.StatusOK, "application/zip", []byte("PK"))
+        })
+        api.DELETE("/empty", func(c *gin.Context) {
+            c.Status(http.StatusNoContent)
+        })
+    }
+    register(router)
+    register(router.Group("/api/v1", middleware.APIEnvelope()))
+    return router
+}
+
+func doRequest(router *gin.Engine, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+    req, _ := http.NewRequest(method, path, nil)
+    for k, v := range headers {
+        req.Header.Set(k, v)
+    }
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    return w
+}
+
+func TestLocalize(t *testing.T) {
+    assert.Equal(t, "Unauthorized", apierror.ErrUnauthorized.Localize(apierror.LangEn))
+    assert.Equal(t, "用户未认证", apierror.ErrUnauthorized.Localize(apierror.LangZh))
+
+    // 旧信息已是对应语言时直接使用
+    err := apierror.ErrInvalidRequest.WithMessage("无效的请求数据")
+    assert.Equal(t, "无效的请求数据", err.Localize(apierror.LangZh))
+    assert.Equal(t, "Invalid request body", err.Localize(apierror.LangEn))
+    assert.Equal(t, "invalid_request", err.Code)
+    assert.Equal(t, http.StatusBadRequest, err.Status)
+
+    // WithMessage 不修改原错误
+    assert.Equal(t, "Invalid request body", apierror.ErrInvalidRequest.Message)
+}
+
+func TestLegacyRoutesKeepFormat(t *testing.T) {
+    router := setupEnvelopeRouter()
+
+    w := doRequest(router, "GET", "/news", nil)
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    assert.JSONEq(t, `{"error":"News not found"}`, w.Body.String())
+    assert.Len(t, w.Header().Get("X-Request-ID"), 32)
+
+    w = doRequest(router, "GET", "/ok", nil)
+    assert.JSONEq(t, `{"id":1}`, w.Body.String())
+}
+
+func TestAPIV1Envelope(t *testing.T) {
+    router := setupEnvelopeRouter()
+
+    w := doRequest(router, "GET", "/api/v1/ok", map[string]string{"X-Request-ID": "req-1"})
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "req-1", w.Header().Get("X-Request-ID"))
+    assert.JSONEq(t, `{"data":{"id":1},"request_id":"req-1"}`, w.Body.String())
+
+    // 不合法的请求 ID 被替换
+    w = doRequest(router, "GET", "/api/v1/ok", map[string]string{"X-Request-ID": "bad id\n"})
+    var body struct {
+        RequestID string `json:"request_id"`
+    }
+    assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+    assert.Len(t, body.RequestID, 32)
+    assert.Equal(t, body.RequestID, w.Header().Get("X-Request-ID"))
+
+    // 错误码稳定，提示按语言返回
+    w = doRequest(router, "GET", "/api/v1/news", map[string]string{"X-Request-ID": "req-2"})
+    assert.Equal(t, http.StatusNotFound, w.Code)
+    assert.JSONEq(t, `{"error":{"code":"news_not_found","message":"新闻不存在"},"request_id":"req-2"}`, w.Body.String())
+
+    w = doRequest(router, "GET", "/api/v1/news", map[string]string{"X-Request-ID": "req-3", "Accept-Language": "en-US,en;q=0.9"})
+    assert.JSONEq(t, `{"error":{"code":"news_not_found","message":"News not found"},"request_id":"req-3"}`, w.Body.String())
+
+    w = doRequest(router, "GET", "/api/v1/liked?lang=en", map[string]string{"X-Request-ID": "req-4"})
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    assert.JSONEq(t, `{"error":{"code":"already_exists","message":"You have already liked this news"},"request_id":"req-4"}`, w.Body.String())
+
+    // 未使用 apierror 的错误响应按状态码推断错误码
+    w = doRequest(router, "GET", "/api/v1/legacy", map[string]string{"X-Request-ID": "req-5"})
+    assert.Equal(t, http.StatusForbidden, w.Code)
+    assert.JSONEq(t, `{"error":{"code":"forbidden","message":"用户未认证"},"request_id":"req-5"}`, w.Body.String())
+
+    // 非 JSON 响应和无响应体的状态码原样返回
+    w = doRequest(router, "GET", "/api/v1/file", nil)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+    assert.Equal(t, "PK", w.Body.String())
+
+    w = doRequest(router, "DELETE", "/api/v1/empty", nil)
+    assert.Equal(t, http.StatusNoContent, w.Code)
+    assert.Empty(t, w.Body.String())
+}
+
+// TestAPIV1EnvelopeStreamsFiles 非 JSON 响应在处理函数返回前就写到客户端，不在内存中缓存
+func TestAPIV1EnvelopeStreamsFiles(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    w := httptest.NewRecorder()
+    var sentBeforeReturn string
+    router.GET("/api/v1/export", middleware.APIEnvelope(), func(c *gin.Context) {
+        c.Header("Content-Type", "application/zip")
+        c.Status(http.StatusOK)
+        c.Writer.Write([]byte("PK"))
+        c.Writer.Flush()
+        sentBeforeReturn = w.Body.String()
+        c.Writer.Write([]byte("\x03\x04"))
+    })
+
+    req, _ := http.NewRequest("GET", "/api/v1/export", nil)
+    router.ServeHTTP(w, req)
+    assert.Equal(t, "PK", sentBeforeReturn)
+    assert.True(t, w.Flushed)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+    assert.Equal(t, "PK\x03\x04", w.Body.String())
+}
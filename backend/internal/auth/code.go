@@ -0,0 +1,200 @@
+// internal/auth/code.go
+package auth
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/subtle"
+    "errors"
+    "fmt"
+    "log"
+    "math/big"
+    "net/smtp"
+    "os"
+    "strings"
+    "time"
+
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+// 一次性验证码的配置
+const (
+    CodeLength      = 6
+    CodeTTL         = 10 * time.Minute
+    CodeResendAfter = time.Minute // 同一邮箱 / 手机号两次发送的最小间隔
+    CodeMaxAttempts = 5           // 每个验证码允许尝试的次数
+)
+
+var (
+    ErrCodeTooFrequent    = errors.New("Verification code requested too frequently")
+    ErrUnsupportedChannel = errors.New("Sending codes to this channel is not supported")
+)
+
+// Sender 发送一次性验证码，channel 为 models.IdentityEmail 或 models.IdentityPhone
+type Sender interface {
+    Send(ctx context.Context, channel, target, code string) error
+}
+
+// LogSender 只把验证码写入日志，用于开发和测试环境
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, channel, target, code string) error {
+    log.Printf("发送到 %s %s 的验证码: %s", channel, target, code)
+    return nil
+}
+
+// SMTPSender 通过 SMTP 发送邮件验证码，不支持手机号
+type SMTPSender struct {
+    Addr     string // host:port
+    Username string
+    Password string
+    From     string
+}
+
+func (s *SMTPSender) Send(ctx context.Context, channel, target, code string) error {
+    if channel != models.IdentityEmail {
+        return ErrUnsupportedChannel
+    }
+    host := s.Addr
+    if i := strings.LastIndex(host, ":"); i >= 0 {
+        host = host[:i]
+    }
+    var auth smtp.Auth
+    if s.Username != "" {
+        auth = smtp.PlainAuth("", s.Username, s.Password, host)
+    }
+    msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: Your login code\r\n\r\nYour login code is %s. It expires in %d minutes.\r\n",
+        s.From, target, code, int(CodeTTL/time.Minute))
+    return smtp.SendMail(s.Addr, auth, s.From, []string{target}, []byte(msg))
+}
+
+// SenderFromEnv 按 CODE_SENDER 环境变量创建验证码发送方：
+//   log（默认）：写入日志；smtp：SMTP_ADDR、SMTP_USERNAME、SMTP_PASSWORD、SMTP_FROM
+func SenderFromEnv() (Sender, error) {
+    switch backend := os.Getenv("CODE_SENDER"); backend {
+    case "", "log":
+        return LogSender{}, nil
+    case "smtp":
+        return &SMTPSender{
+            Addr:     os.Getenv("SMTP_ADDR"),
+            Username: os.Getenv("SMTP_USERNAME"),
+            Password: os.Getenv("SMTP_PASSWORD"),
+            From:     os.Getenv("SMTP_FROM"),
+        }, nil
+    default:
+        return nil, fmt.Errorf("unknown code sender %q", backend)
+    }
+}
+
+// CodeProvider 向邮箱或手机号发送一次性验证码登录，新的邮箱 / 手机号自动注册
+type CodeProvider struct {
+    DB     *gorm.DB
+    Sender Sender
+    now    func() time.Time
+}
+
+func NewCodeProvider(db *gorm.DB, sender Sender) *CodeProvider {
+    return &CodeProvider{DB: db, Sender: sender, now: time.Now}
+}
+
+func (p *CodeProvider) Name() string { return "code" }
+
+func (p *CodeProvider) AutoRegister() bool { return true }
+
+// SendCode 生成并发送验证码，同一目标在 CodeResendAfter 内只能发送一次
+func (p *CodeProvider) SendCode(ctx context.Context, creds Credentials) error {
+    channel, target, err := creds.Target()
+    if err != nil {
+        return err
+    }
+    now := p.now()
+
+    var recent int64
+    if err := p.DB.WithContext(ctx).Model(&models.LoginCode{}).
+        Where("channel = ? AND target = ? AND created_at > ?", channel, target, now.Add(-CodeResendAfter)).
+        Count(&recent).Error; err != nil {
+        return err
+    }
+    if recent > 0 {
+        return ErrCodeTooFrequent
+    }
+
+    code, err := randomCode()
+    if err != nil {
+        return err
+    }
+    if err := p.DB.WithContext(ctx).Create(&models.LoginCode{
+        Channel:   channel,
+        Target:    target,
+        CodeHash:  utils.HashToken(channel + ":" + target + ":" + code),
+        ExpiresAt: now.Add(CodeTTL),
+        CreatedAt: now,
+    }).Error; err != nil {
+        return err
+    }
+    return p.Sender.Send(ctx, channel, target, code)
+}
+
+// Authenticate 校验最近一次发送的验证码，验证通过后验证码失效
+func (p *CodeProvider) Authenticate(ctx context.Context, creds Credentials) (Identity, error) {
+    channel, target, err := creds.Target()
+    if err != nil {
+        return Identity{}, err
+    }
+    if creds.Code == "" {
+        return Identity{}, ErrInvalidCredentials
+    }
+    now := p.now()
+
+    var loginCode models.LoginCode
+    if err := p.DB.WithContext(ctx).
+        Where("channel = ? AND target = ? AND used_at IS NULL AND expires_at > ?", channel, target, now).
+        Order("created_at DESC").First(&loginCode).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return Identity{}, ErrInvalidCredentials
+        }
+        return Identity{}, err
+    }
+
+    // 比较前先用条件更新占用一次尝试机会，并发的错误尝试不会同时通过次数检查
+    reserved := p.DB.WithContext(ctx).Model(&models.LoginCode{}).
+        Where("id = ? AND attempts < ?", loginCode.ID, CodeMaxAttempts).
+        Update("attempts", gorm.Expr("attempts + 1"))
+    if reserved.Error != nil {
+        return Identity{}, reserved.Error
+    }
+    if reserved.RowsAffected == 0 {
+        return Identity{}, ErrInvalidCredentials
+    }
+
+    hash := utils.HashToken(channel + ":" + target + ":" + creds.Code)
+    if subtle.ConstantTimeCompare([]byte(hash), []byte(loginCode.CodeHash)) != 1 {
+        return Identity{}, ErrInvalidCredentials
+    }
+
+    // 条件更新保证同一个验证码只能使用一次
+    result := p.DB.WithContext(ctx).Model(&models.LoginCode{}).
+        Where("id = ? AND used_at IS NULL", loginCode.ID).Update("used_at", now)
+    if result.Error != nil {
+        return Identity{}, result.Error
+    }
+    if result.RowsAffected == 0 {
+        return Identity{}, ErrInvalidCredentials
+    }
+    return Identity{Provider: channel, Subject: target}, nil
+}
+
+// randomCode 生成 CodeLength 位数字验证码
+func randomCode() (string, error) {
+    max := big.NewInt(1)
+    for i := 0; i < CodeLength; i++ {
+        max.Mul(max, big.NewInt(10))
+    }
+    n, err := rand.Int(rand.Reader, max)
+    if err != nil {
+        return "", err
+    }
+    return fmt.Sprintf("%0*d", CodeLength, n), nil
+}
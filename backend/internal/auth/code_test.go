@@ -0,0 +1,144 @@
+// internal/auth/code_test.go
+package auth_test
+
+import (
+    "context"
+    "fmt"
+    "path/filepath"
+    "sync"
+    "testing"
+    "time"
+
+    "gorm.io/driver/sqlite"
+    "gorm.io/gorm"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/auth"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+// recordingSender 记录最近发送的验证码
+type recordingSender struct {
+    mu    sync.Mutex
+    codes map[string]string
+}
+
+func (s *recordingSender) Send(ctx context.Context, channel, target, code string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.codes[target] = code
+    return nil
+}
+
+func (s *recordingSender) code(target string) string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.codes[target]
+}
+
+func setupCodeProvider(t *testing.T, dsn string) (*auth.CodeProvider, *recordingSender, *gorm.DB) {
+    db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+    assert.NoError(t, err)
+    assert.NoError(t, db.AutoMigrate(&models.LoginCode{}))
+    sender := &recordingSender{codes: map[string]string{}}
+    return auth.NewCodeProvider(db, sender), sender, db
+}
+
+// wrongCode 返回一个与 code 不同的验证码
+func wrongCode(code string) string {
+    if code == "000000" {
+        return "000001"
+    }
+    return "000000"
+}
+
+func TestCodeProvider(t *testing.T) {
+    provider, sender, db := setupCodeProvider(t, ":memory:")
+    ctx := context.Background()
+
+    t.Run("Single Use", func(t *testing.T) {
+        creds := auth.Credentials{Email: "single@example.com"}
+        assert.NoError(t, provider.SendCode(ctx, creds))
+        assert.Equal(t, auth.ErrCodeTooFrequent, provider.SendCode(ctx, creds))
+
+        creds.Code = sender.code("single@example.com")
+        identity, err := provider.Authenticate(ctx, creds)
+        assert.NoError(t, err)
+        assert.Equal(t, auth.Identity{Provider: models.IdentityEmail, Subject: "single@example.com"}, identity)
+
+        _, err = provider.Authenticate(ctx, creds)
+        assert.Equal(t, auth.ErrInvalidCredentials, err)
+    })
+
+    t.Run("Expired", func(t *testing.T) {
+        creds := auth.Credentials{Email: "expired@example.com"}
+        assert.NoError(t, provider.SendCode(ctx, creds))
+        db.Model(&models.LoginCode{}).Where("target = ?", "expired@example.com").
+            Update("expires_at", time.Now().Add(-time.Second))
+
+        creds.Code = sender.code("expired@example.com")
+        _, err := provider.Authenticate(ctx, creds)
+        assert.Equal(t, auth.ErrInvalidCredentials, err)
+    })
+
+    t.Run("Max Attempts", func(t *testing.T) {
+        creds := auth.Credentials{Email: "guess@example.com"}
+        assert.NoError(t, provider.SendCode(ctx, creds))
+        code := sender.code("guess@example.com")
+
+        for i := 0; i < auth.CodeMaxAttempts; i++ {
+            _, err := provider.Authenticate(ctx, auth.Credentials{Email: "guess@example.com", Code: wrongCode(code)})
+            assert.Equal(t, auth.ErrInvalidCredentials, err)
+        }
+        // 用完尝试次数后正确的验证码也不再接受
+        creds.Code = code
+        _, err := provider.Authenticate(ctx, creds)
+        assert.Equal(t, auth.ErrInvalidCredentials, err)
+    })
+
+    t.Run("Correct After Wrong Attempts", func(t *testing.T) {
+        creds := auth.Credentials{Phone: "+8613800000000"}
+        assert.NoError(t, provider.SendCode(ctx, creds))
+        code := sender.code("+8613800000000")
+
+        for i := 0; i < auth.CodeMaxAttempts-1; i++ {
+            _, err := provider.Authenticate(ctx, auth.Credentials{Phone: "+8613800000000", Code: wrongCode(code)})
+            assert.Equal(t, auth.ErrInvalidCredentials, err)
+        }
+        creds.Code = code
+        _, err := provider.Authenticate(ctx, creds)
+        assert.NoError(t, err)
+    })
+}
+
+// TestCodeProviderConcurrentGuesses 并发的错误尝试不能超过 CodeMaxAttempts 次
+func TestCodeProviderConcurrentGuesses(t *testing.T) {
+    dsn := filepath.Join(t.TempDir(), "codes.db") + "?_busy_timeout=5000"
+    provider, sender, db := setupCodeProvider(t, dsn)
+    ctx := context.Background()
+
+    assert.NoError(t, provider.SendCode(ctx, auth.Credentials{Email: "race@example.com"}))
+    code := sender.code("race@example.com")
+
+    var wg sync.WaitGroup
+    for i := 0; i < 20; i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            guess := fmt.Sprintf("%06d", i)
+            if guess == code {
+                guess = wrongCode(code)
+            }
+            _, _ = provider.Authenticate(ctx, auth.Credentials{Email: "race@example.com", Code: guess})
+        }(i)
+    }
+    wg.Wait()
+
+    var loginCode models.LoginCode
+    assert.NoError(t, db.Where("target = ?", "race@example.com").First(&loginCode).Error)
+    assert.LessOrEqual(t, loginCode.Attempts, auth.CodeMaxAttempts)
+
+    _, err := provider.Authenticate(ctx, auth.Credentials{Email: "race@example.com", Code: code})
+    if loginCode.Attempts == auth.CodeMaxAttempts {
+        assert.Equal(t, auth.ErrInvalidCredentials, err)
+    }
+}
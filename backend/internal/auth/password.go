@@ -0,0 +1,70 @@
+// internal/auth/password.go
+package auth
+
+import (
+    "context"
+    "errors"
+    "unicode/utf8"
+
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+// 密码长度限制，bcrypt 只使用前 72 字节
+const (
+    MinPasswordLength = 8
+    MaxPasswordLength = 72
+)
+
+var ErrWeakPassword = errors.New("Password must be 8 to 72 characters")
+
+// PasswordProvider 邮箱或手机号加密码登录，密码在注册或关联身份时设置
+type PasswordProvider struct {
+    DB *gorm.DB
+}
+
+func NewPasswordProvider(db *gorm.DB) *PasswordProvider {
+    return &PasswordProvider{DB: db}
+}
+
+func (p *PasswordProvider) Name() string { return "password" }
+
+func (p *PasswordProvider) AutoRegister() bool { return false }
+
+// Authenticate 校验已设置密码的邮箱 / 手机号身份
+func (p *PasswordProvider) Authenticate(ctx context.Context, creds Credentials) (Identity, error) {
+    channel, target, err := creds.Target()
+    if err != nil {
+        return Identity{}, err
+    }
+    if creds.Password == "" {
+        return Identity{}, ErrInvalidCredentials
+    }
+
+    var identity models.UserIdentity
+    if err := p.DB.WithContext(ctx).Where("provider = ? AND subject = ?", channel, target).
+        First(&identity).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            // 身份不存在时同样做一次哈希比较，避免通过响应时间判断账号是否存在
+            utils.CheckPassword(dummyPasswordHash, creds.Password)
+            return Identity{}, ErrInvalidCredentials
+        }
+        return Identity{}, err
+    }
+    if identity.PasswordHash == "" || utils.CheckPassword(identity.PasswordHash, creds.Password) != nil {
+        return Identity{}, ErrInvalidCredentials
+    }
+    return Identity{Provider: channel, Subject: target}, nil
+}
+
+// HashPassword 校验密码长度后计算哈希
+func HashPassword(password string) (string, error) {
+    if n := utf8.RuneCountInString(password); n < MinPasswordLength || len(password) > MaxPasswordLength {
+        return "", ErrWeakPassword
+    }
+    return utils.HashPassword(password)
+}
+
+// dummyPasswordHash 用于身份不存在时的哈希比较，cost 与 bcrypt.DefaultCost 相同
+const dummyPasswordHash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
@@ -0,0 +1,116 @@
+// internal/auth/provider.go
+package auth
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "net/mail"
+    "strings"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+var (
+    ErrInvalidCredentials = errors.New("Invalid credentials")
+    ErrInvalidEmail       = errors.New("Invalid email address")
+    ErrInvalidPhone       = errors.New("Invalid phone number")
+    ErrMissingTarget      = errors.New("Email or phone is required")
+    ErrUnknownProvider    = errors.New("Unknown login provider")
+)
+
+// Credentials 登录请求中的凭证，不同的提供方使用不同的字段
+type Credentials struct {
+    Code     string `json:"code"`     // 微信登录 code 或一次性验证码
+    Email    string `json:"email"`
+    Phone    string `json:"phone"`
+    Password string `json:"password"`
+}
+
+// Identity 验证通过的登录身份，对应 models.UserIdentity 的 Provider 和 Subject
+type Identity struct {
+    Provider   string
+    Subject    string
+    SessionKey string // 微信会话密钥，其他身份为空
+}
+
+// Provider 一种登录方式
+type Provider interface {
+    // Name 登录方式的名称，即 /users/login/:provider 中的 provider
+    Name() string
+    // Authenticate 校验凭证并返回对应的身份，凭证错误时返回 ErrInvalidCredentials
+    Authenticate(ctx context.Context, creds Credentials) (Identity, error)
+    // AutoRegister 身份还没有关联用户时是否自动创建用户；密码登录只能用于已注册的身份
+    AutoRegister() bool
+}
+
+// Registry 按名称查找登录方式
+type Registry struct {
+    providers map[string]Provider
+}
+
+// NewRegistry 创建登录方式注册表
+func NewRegistry(providers ...Provider) *Registry {
+    r := &Registry{providers: make(map[string]Provider, len(providers))}
+    for _, p := range providers {
+        r.providers[p.Name()] = p
+    }
+    return r
+}
+
+// Get 返回指定名称的登录方式
+func (r *Registry) Get(name string) (Provider, error) {
+    p, ok := r.providers[name]
+    if !ok {
+        return nil, ErrUnknownProvider
+    }
+    return p, nil
+}
+
+// Target 从凭证中取出邮箱或手机号并规范化，返回身份类型和规范化后的值
+func (c Credentials) Target() (channel, target string, err error) {
+    switch {
+    case c.Email != "":
+        target, err = NormalizeEmail(c.Email)
+        return models.IdentityEmail, target, err
+    case c.Phone != "":
+        target, err = NormalizePhone(c.Phone)
+        return models.IdentityPhone, target, err
+    default:
+        return "", "", ErrMissingTarget
+    }
+}
+
+// NormalizeEmail 校验邮箱格式并转为小写
+func NormalizeEmail(email string) (string, error) {
+    addr, err := mail.ParseAddress(strings.TrimSpace(email))
+    if err != nil || addr.Name != "" || len(addr.Address) > 128 {
+        return "", ErrInvalidEmail
+    }
+    return strings.ToLower(addr.Address), nil
+}
+
+// NormalizePhone 去掉空格和短横线，保留开头的 +，要求 6 到 20 位数字
+func NormalizePhone(phone string) (string, error) {
+    phone = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(phone))
+    digits := strings.TrimPrefix(phone, "+")
+    if len(digits) < 6 || len(digits) > 20 {
+        return "", ErrInvalidPhone
+    }
+    for _, r := range digits {
+        if r < '0' || r > '9' {
+            return "", ErrInvalidPhone
+        }
+    }
+    return phone, nil
+}
+
+// SyntheticOpenID 为没有微信身份的用户生成占位的 OpenID，users.open_id 要求唯一且非空
+func SyntheticOpenID() (string, error) {
+    b := make([]byte, 12)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return "local_" + hex.EncodeToString(b), nil
+}
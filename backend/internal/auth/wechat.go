@@ -0,0 +1,41 @@
+// internal/auth/wechat.go
+package auth
+
+import (
+    "context"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/wechat"
+)
+
+// WeChatProvider 微信小程序登录，使用 wx.login 得到的 code 换取 OpenID。
+// 调用失败时返回 wechat.RequestError、wechat.ResponseError 或 wechat.APIError
+type WeChatProvider struct {
+    Client *wechat.Client // 为 nil 时使用 wechat.Default()
+}
+
+// NewWeChatProvider 创建使用全局微信客户端的登录方式
+func NewWeChatProvider() *WeChatProvider {
+    return &WeChatProvider{}
+}
+
+func (p *WeChatProvider) Name() string { return models.IdentityWeChat }
+
+func (p *WeChatProvider) AutoRegister() bool { return true }
+
+// Authenticate 调用 code2Session 接口
+func (p *WeChatProvider) Authenticate(ctx context.Context, creds Credentials) (Identity, error) {
+    if creds.Code == "" {
+        return Identity{}, ErrInvalidCredentials
+    }
+    client := p.Client
+    if client == nil {
+        client = wechat.Default()
+    }
+
+    session, err := client.Code2Session(ctx, creds.Code)
+    if err != nil {
+        return Identity{}, err
+    }
+    return Identity{Provider: models.IdentityWeChat, Subject: session.OpenID, SessionKey: session.SessionKey}, nil
+}
@@ -0,0 +1,524 @@
+// internal/controllers/account_controller.go
+package controllers
+
+import (
+    "archive/zip"
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "os"
+    "path"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+)
+
+// DefaultAccountDeletionGracePeriod 申请注销后默认保留账号的时间，期间可以撤销申请
+const DefaultAccountDeletionGracePeriod = 15 * 24 * time.Hour
+
+// DeletedUserNickname 注销后匿名化账号的昵称，其发布的新闻和评论显示为该昵称
+const DeletedUserNickname = "已注销用户"
+
+type AccountController struct {
+    DB *gorm.DB
+}
+
+func NewAccountController(db *gorm.DB) *AccountController {
+    return &AccountController{DB: db}
+}
+
+// AccountDeletionGracePeriod 读取 ACCOUNT_DELETION_GRACE_PERIOD（如 360h），未设置或无效时使用默认值
+func AccountDeletionGracePeriod() time.Duration {
+    if v := os.Getenv("ACCOUNT_DELETION_GRACE_PERIOD"); v != "" {
+        if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+            return d
+        }
+    }
+    return DefaultAccountDeletionGracePeriod
+}
+
+// exportSection 数据导出中的一类数据，Where 中的 @id 为用户 ID
+type exportSection struct {
+    Name  string
+    Table string
+    Where string
+}
+
+// accountExportSections 与用户相关的所有数据。新增与用户关联的表时需要在这里登记
+var accountExportSections = []exportSection{
+    {"profile", "users", "id = @id"},
+    {"identities", "user_identities", "user_id = @id"},
+    {"sessions", "sessions", "user_id = @id"},
+    {"news", "news", "author_id = @id"},
+    {"news_blocks", "news_blocks", "news_id IN (SELECT id FROM news WHERE author_id = @id)"},
+    {"news_paragraphs", "paragraphs", "news_id IN (SELECT id FROM news WHERE author_id = @id)"},
+    {"news_images", "news_images", "news_id IN (SELECT id FROM news WHERE author_id = @id)"},
+    {"news_revisions", "news_revisions", "editor_id = @id"},
+    {"drafts", "drafts", "author_id = @id"},
+    {"draft_blocks", "draft_blocks", "draft_id IN (SELECT id FROM drafts WHERE author_id = @id)"},
+    {"draft_paragraphs", "draft_paragraphs", "draft_id IN (SELECT id FROM drafts WHERE author_id = @id)"},
+    {"draft_images", "draft_images", "draft_id IN (SELECT id FROM drafts WHERE author_id = @id)"},
+    {"draft_revisions", "draft_revisions", "draft_id IN (SELECT id FROM drafts WHERE author_id = @id)"},
+    {"comments", "comments", "user_id = @id"},
+    {"liked_news", "user_likes_news", "user_id = @id"},
+    {"favorited_news", "user_favorites_news", "user_id = @id"},
+    {"disliked_news", "user_dislikes_news", "user_id = @id"},
+    {"viewed_news", "user_viewed_news", "user_id = @id"},
+    {"liked_comments", "user_likes_comments", "user_id = @id"},
+    {"view_history", "news_views", "user_id = @id"},
+    {"collections", "collections", "user_id = @id"},
+    {"collection_items", "collection_items", "collection_id IN (SELECT id FROM collections WHERE user_id = @id)"},
+    {"share_codes", "share_codes", "user_id = @id"},
+    {"share_events", "share_events", "user_id = @id"},
+    {"privacy_settings", "privacy_settings", "user_id = @id"},
+    {"dietary_profile", "dietary_profiles", "user_id = @id"},
+    {"following", "user_follows", "follower_id = @id"},
+    {"followers", "user_follows", "followee_id = @id"},
+    {"blocked_users", "user_blocks", "blocker_id = @id"},
+    {"notifications", "notifications", "user_id = @id"},
+    {"family", "families", "id IN (SELECT family_id FROM users WHERE id = @id) OR id IN (SELECT pending_family_id FROM users WHERE id = @id)"},
+    {"family_dishes", "family_dishes", "proposer_user_id = @id"},
+    {"food_preferences", "food_preferences", "user_id = @id"},
+    {"disliked_foods", "disliked_food_preferences", "user_id = @id"},
+    {"nutrition_goals", "nutrition_goals", "user_id = @id"},
+    {"nutrition_intakes", "nutrition_intakes", "user_id = @id"},
+    {"carbon_goals", "carbon_goals", "user_id = @id"},
+    {"carbon_intakes", "carbon_intakes", "user_id = @id"},
+    {"recipe_history", "user_recipe_histories", "user_id = @id"},
+    {"ingredient_history", "user_ingredient_histories", "user_id = @id"},
+    {"ingredient_preferences", "user_ingredient_preferences", "user_id = @id"},
+    {"last_selected_foods", "user_last_selected_foods", "user_id = @id"},
+    {"uploaded_images", "uploaded_images", "uploader_id = @id"},
+}
+
+// exportHiddenColumns 不导出的凭证类字段
+var exportHiddenColumns = map[string]bool{
+    "session_key":   true,
+    "password_hash": true,
+    "token_hash":    true,
+    "code_hash":     true,
+    "token":         true, // 家庭邀请 Token
+}
+
+// AccountExport 用户数据导出
+type AccountExport struct {
+    UserID     uint                                `json:"user_id"`
+    ExportedAt time.Time                           `json:"exported_at"`
+    Data       map[string][]map[string]interface{} `json:"data"`
+    Files      []string                            `json:"files"` // 上传的文件，ZIP 中位于 files/ 目录
+}
+
+// BuildAccountExport 收集用户的所有数据
+func (ac *AccountController) BuildAccountExport(userID uint, now time.Time) (*AccountExport, error) {
+    export := &AccountExport{UserID: userID, ExportedAt: now, Data: make(map[string][]map[string]interface{}), Files: []string{}}
+    for _, section := range accountExportSections {
+        var rows []map[string]interface{}
+        if err := ac.DB.Table(section.Table).Where(section.Where, sql.Named("id", userID)).Find(&rows).Error; err != nil {
+            return nil, fmt.Errorf("export %s: %w", section.Name, err)
+        }
+        for _, row := range rows {
+            for column, value := range row {
+                if exportHiddenColumns[column] {
+                    delete(row, column)
+                } else if b, ok := value.([]byte); ok {
+                    row[column] = string(b)
+                }
+            }
+        }
+        if rows == nil {
+            rows = []map[string]interface{}{}
+        }
+        export.Data[section.Name] = rows
+    }
+
+    // 头像和自己上传的图片
+    seen := map[string]bool{defaultAvatarKey: true}
+    addFile := func(key interface{}) {
+        if s, ok := key.(string); ok && s != "" && !seen[s] {
+            seen[s] = true
+            export.Files = append(export.Files, s)
+        }
+    }
+    for _, row := range export.Data["profile"] {
+        addFile(row["avatar_url"])
+    }
+    for _, row := range export.Data["uploaded_images"] {
+        addFile(row["path"])
+    }
+    return export, nil
+}
+
+// ExportAccountData 下载自己的全部数据：默认为 ZIP（每类数据一个 JSON 文件，以及上传的文件），?format=json 时只返回 JSON
+func (ac *AccountController) ExportAccountData(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    format := c.DefaultQuery("format", "zip")
+    if format != "zip" && format != "json" {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid format"))
+        return
+    }
+
+    export, err := ac.BuildAccountExport(userID.(uint), time.Now())
+    if err != nil {
+        log.Println("导出用户数据失败:", err)
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to export account data"))
+        return
+    }
+
+    switch format {
+    case "json":
+        c.Header("Content-Disposition", `attachment; filename="account-export.json"`)
+        c.JSON(http.StatusOK, export)
+    case "zip":
+        store, err := storage.Default()
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to export account data"))
+            return
+        }
+        c.Header("Content-Type", "application/zip")
+        c.Header("Content-Disposition", `attachment; filename="account-export.zip"`)
+        c.Status(http.StatusOK)
+        if err := writeAccountExportZip(c.Request.Context(), c.Writer, store, export); err != nil {
+            // 响应已经开始发送，只能记录错误
+            log.Println("写入导出文件失败:", err)
+        }
+    }
+}
+
+// writeAccountExportZip 写入导出的 ZIP：export.json 为概要，data/<类别>.json 为各类数据，files/ 下为上传的文件
+func writeAccountExportZip(ctx context.Context, w io.Writer, store storage.Storage, export *AccountExport) error {
+    zw := zip.NewWriter(w)
+    writeJSON := func(name string, v interface{}) error {
+        f, err := zw.Create(name)
+        if err != nil {
+            return err
+        }
+        enc := json.NewEncoder(f)
+        enc.SetIndent("", "  ")
+        return enc.Encode(v)
+    }
+
+    counts := make(map[string]int, len(export.Data))
+    for name, rows := range export.Data {
+        counts[name] = len(rows)
+        if err := writeJSON(path.Join("data", name+".json"), rows); err != nil {
+            return err
+        }
+    }
+
+    var files []string
+    for _, key := range export.Files {
+        r, err := store.Open(ctx, key)
+        if errors.Is(err, storage.ErrNotExist) {
+            continue
+        }
+        if err != nil {
+            return err
+        }
+        f, err := zw.Create(path.Join("files", key))
+        if err == nil {
+            _, err = io.Copy(f, r)
+        }
+        r.Close()
+        if err != nil {
+            return err
+        }
+        files = append(files, key)
+    }
+
+    if err := writeJSON("export.json", gin.H{
+        "user_id":     export.UserID,
+        "exported_at": export.ExportedAt,
+        "counts":      counts,
+        "files":       files,
+    }); err != nil {
+        return err
+    }
+    return zw.Close()
+}
+
+// deletionStatus 注销申请的状态
+func deletionStatus(user *models.User) gin.H {
+    return gin.H{
+        "scheduled":    user.DeletionScheduledAt != nil,
+        "scheduled_at": user.DeletionScheduledAt,
+    }
+}
+
+// GetAccountDeletion 查看注销申请状态
+func (ac *AccountController) GetAccountDeletion(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var user models.User
+    if err := ac.DB.First(&user, userID).Error; err != nil {
+        apierror.Respond(c, apierror.ErrUserNotFound)
+        return
+    }
+    c.JSON(http.StatusOK, deletionStatus(&user))
+}
+
+// RequestAccountDeletion 申请注销账号，宽限期结束后清除个人数据并匿名化发布的内容；重复申请不会推迟注销时间
+func (ac *AccountController) RequestAccountDeletion(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    scheduledAt := time.Now().Add(AccountDeletionGracePeriod())
+    if err := ac.DB.Model(&models.User{}).
+        Where("id = ? AND deletion_scheduled_at IS NULL AND anonymized_at IS NULL", userID).
+        UpdateColumn("deletion_scheduled_at", scheduledAt).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to schedule account deletion"))
+        return
+    }
+
+    var user models.User
+    if err := ac.DB.First(&user, userID).Error; err != nil {
+        apierror.Respond(c, apierror.ErrUserNotFound)
+        return
+    }
+    c.JSON(http.StatusAccepted, deletionStatus(&user))
+}
+
+// CancelAccountDeletion 宽限期内撤销注销申请
+func (ac *AccountController) CancelAccountDeletion(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    result := ac.DB.Model(&models.User{}).
+        Where("id = ? AND deletion_scheduled_at IS NOT NULL", userID).
+        UpdateColumn("deletion_scheduled_at", nil)
+    if result.Error != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to cancel account deletion"))
+        return
+    }
+    if result.RowsAffected == 0 {
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("No pending account deletion"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Account deletion cancelled"})
+}
+
+// PurgeDueAccounts 注销所有宽限期已结束的账号，返回注销的账号数
+func (ac *AccountController) PurgeDueAccounts(ctx context.Context, now time.Time) (int, error) {
+    var userIDs []uint
+    if err := ac.DB.Model(&models.User{}).
+        Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+        Pluck("id", &userIDs).Error; err != nil {
+        return 0, err
+    }
+
+    purged := 0
+    for _, id := range userIDs {
+        if err := ac.AnonymizeAccount(ctx, id, now); err != nil {
+            return purged, fmt.Errorf("anonymize user %d: %w", id, err)
+        }
+        purged++
+    }
+    return purged, nil
+}
+
+// AnonymizeAccount 注销账号：删除个人记录，退出家庭（管理员身份顺延或解散家庭），
+// 保留发布的新闻和评论但改为匿名的作者，并清理不再被引用的上传文件
+func (ac *AccountController) AnonymizeAccount(ctx context.Context, userID uint, now time.Time) error {
+    var user models.User
+    if err := ac.DB.First(&user, userID).Error; err != nil {
+        return err
+    }
+    avatarURL := user.AvatarURL
+
+    err := ac.DB.Transaction(func(tx *gorm.DB) error {
+        // 退出家庭和撤销加入申请
+        if user.FamilyID != nil {
+            var family models.Family
+            err := tx.First(&family, *user.FamilyID).Error
+            if err == nil {
+                err = removeFamilyMember(tx, &family, &user)
+            }
+            if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+                return err
+            }
+        }
+        if err := tx.Exec("DELETE FROM family_waiting_list WHERE user_id = ?", userID).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("proposer_user_id = ?", userID).Delete(&models.FamilyDish{}).Error; err != nil {
+            return err
+        }
+
+        // 点赞、收藏和点踩同时更新计数
+        for _, r := range models.CounterRelations {
+            var targetIDs []uint
+            if err := tx.Table(r.JoinTable).Where("user_id = ?", userID).Pluck(r.ForeignKey, &targetIDs).Error; err != nil {
+                return err
+            }
+            for _, targetID := range targetIDs {
+                removed, err := r.Unlink(tx, userID, targetID)
+                if err != nil {
+                    return err
+                }
+                if removed {
+                    if _, err := r.Adjust(tx, targetID, -1); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+                        return err
+                    }
+                }
+            }
+        }
+        // 浏览量统计的是浏览次数，删除浏览记录时不减少
+        if err := tx.Exec("DELETE FROM user_viewed_news WHERE user_id = ?", userID).Error; err != nil {
+            return err
+        }
+
+        // 草稿不公开，连同历史版本一起删除
+        drafts := tx.Model(&models.Draft{}).Select("id").Where("author_id = ?", userID)
+        for _, model := range []interface{}{&models.DraftParagraph{}, &models.DraftBlock{}, &models.DraftImage{}, &models.DraftRevision{}} {
+            if err := tx.Where("draft_id IN (?)", drafts).Delete(model).Error; err != nil {
+                return err
+            }
+        }
+        if err := tx.Where("author_id = ?", userID).Delete(&models.Draft{}).Error; err != nil {
+            return err
+        }
+
+        collections := tx.Model(&models.Collection{}).Select("id").Where("user_id = ?", userID)
+        if err := tx.Where("collection_id IN (?)", collections).Delete(&models.CollectionItem{}).Error; err != nil {
+            return err
+        }
+
+        // 邮箱和手机号的验证码按身份查找，需在删除身份之前删除
+        subjects := tx.Model(&models.UserIdentity{}).Select("subject").
+            Where("user_id = ? AND provider IN ?", userID, []string{models.IdentityEmail, models.IdentityPhone})
+        if err := tx.Where("target IN (?)", subjects).Delete(&models.LoginCode{}).Error; err != nil {
+            return err
+        }
+
+        personal := []struct {
+            model interface{}
+            where string
+        }{
+            {&models.RefreshToken{}, "user_id = @id"},
+            {&models.Session{}, "user_id = @id"},
+            {&models.UserIdentity{}, "user_id = @id"},
+            {&models.Collection{}, "user_id = @id"},
+            {&models.NewsView{}, "user_id = @id"},
+            {&models.ShareCode{}, "user_id = @id"},
+            {&models.ShareEvent{}, "user_id = @id"},
+            {&models.Notification{}, "user_id = @id OR actor_id = @id"},
+            {&models.UserFollow{}, "follower_id = @id OR followee_id = @id"},
+            {&models.UserBlock{}, "blocker_id = @id OR blocked_id = @id"},
+            {&models.FoodPreference{}, "user_id = @id"},
+            {&models.DislikedFoodPreference{}, "user_id = @id"},
+            {&models.NutritionGoal{}, "user_id = @id"},
+            {&models.NutritionIntake{}, "user_id = @id"},
+            {&models.CarbonGoal{}, "user_id = @id"},
+            {&models.CarbonIntake{}, "user_id = @id"},
+            {&models.UserRecipeHistory{}, "user_id = @id"},
+            {&models.UserIngredientHistory{}, "user_id = @id"},
+            {&models.UserIngredientPreference{}, "user_id = @id"},
+            {&models.UserLastSelectedFoods{}, "user_id = @id"},
+            {&models.PrivacySettings{}, "user_id = @id"},
+            {&models.DietaryProfile{}, "user_id = @id"},
+        }
+        for _, p := range personal {
+            if err := tx.Unscoped().Where(p.where, sql.Named("id", userID)).Delete(p.model).Error; err != nil {
+                return err
+            }
+        }
+
+        // 保留用户记录，新闻和评论显示为已注销用户
+        return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+            "nickname":              DeletedUserNickname,
+            "open_id":               fmt.Sprintf("deleted_%d", userID),
+            "session_key":           "",
+            "avatar_url":            defaultAvatarKey,
+            "family_id":             nil,
+            "pending_family_id":     nil,
+            "deletion_scheduled_at": nil,
+            "anonymized_at":         now,
+        }).Error
+    })
+    if err != nil {
+        return err
+    }
+
+    return ac.cleanupUserUploads(ctx, userID, avatarURL)
+}
+
+// cleanupUserUploads 删除用户的头像和上传的图片中不再被引用的文件，仍被引用的图片只去掉上传者
+func (ac *AccountController) cleanupUserUploads(ctx context.Context, userID uint, avatarURL string) error {
+    var images []models.UploadedImage
+    if err := ac.DB.Where("uploader_id = ?", userID).Find(&images).Error; err != nil {
+        return err
+    }
+    if len(images) == 0 && (avatarURL == "" || avatarURL == defaultAvatarKey) {
+        return nil
+    }
+
+    refs, err := NewUploadGCController(ac.DB).referencedUploads()
+    if err != nil {
+        return err
+    }
+    store, err := storage.Default()
+    if err != nil {
+        return err
+    }
+
+    handled := make(map[string]bool)
+    for i := range images {
+        img := &images[i]
+        handled[img.Path] = true
+        referenced := refs[img.Path]
+        for _, v := range img.VariantList() {
+            handled[v.URL] = true
+            referenced = referenced || refs[v.URL]
+        }
+        if referenced {
+            if err := ac.DB.Model(img).UpdateColumn("uploader_id", 0).Error; err != nil {
+                return err
+            }
+            continue
+        }
+
+        // 先删除图片记录，避免去重命中即将删除的文件
+        if err := ac.DB.Delete(img).Error; err != nil {
+            return err
+        }
+        err := store.List(ctx, img.ImageDir()+"/", func(obj storage.ObjectInfo) error {
+            return store.Delete(ctx, obj.Key)
+        })
+        if err != nil {
+            return err
+        }
+    }
+
+    // 早期未经处理的头像没有图片记录；其他用户首次上传的图片留给垃圾回收按目录处理
+    if avatarURL != "" && !handled[avatarURL] && !refs[avatarURL] && imageDirOf(avatarURL) == "" {
+        if _, err := storage.CleanKey(avatarURL); err == nil {
+            if err := store.Delete(ctx, avatarURL); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
@@ -0,0 +1,236 @@
+// internal/controllers/account_controller_test.go
+package controllers
+
+import (
+    "archive/zip"
+    "bytes"
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/driver/sqlite"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+// setupAccountTestDB 迁移所有与用户关联的模型
+func setupAccountTestDB() *gorm.DB {
+    db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+    if err != nil {
+        panic("failed to connect database")
+    }
+    if err := db.AutoMigrate(&models.User{}, &models.News{}, &models.NewsImage{}, &models.Paragraph{}, &models.Comment{},
+        &models.Family{}, &models.FamilyDish{}, &models.FoodPreference{}, &models.DislikedFoodPreference{},
+        &models.NutritionGoal{}, &models.NutritionIntake{}, &models.CarbonGoal{}, &models.CarbonIntake{},
+        &models.RefreshToken{}, &models.Session{}, &models.UserIdentity{}, &models.LoginCode{},
+        &models.UserRecipeHistory{}, &models.UserLastSelectedFoods{}, &models.UserIngredientHistory{}, &models.UserIngredientPreference{},
+        &models.Draft{}, &models.DraftImage{}, &models.DraftParagraph{}, &models.DraftRevision{}, &models.NewsRevision{},
+        &models.NewsBlock{}, &models.DraftBlock{}, &models.UploadedImage{}, &models.Notification{}, &models.NewsView{},
+        &models.UserFollow{}, &models.UserBlock{}, &models.Collection{}, &models.CollectionItem{},
+        &models.ShareCode{}, &models.ShareEvent{}, &models.PrivacySettings{}, &models.DietaryProfile{}); err != nil {
+        panic("failed to migrate models")
+    }
+    return db
+}
+
+func TestAccountExportAndDeletion(t *testing.T) {
+    db := setupAccountTestDB()
+    root := t.TempDir()
+    t.Setenv("BASE_UPLOAD_PATH", root)
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    ac := NewAccountController(db)
+    authGroup := router.Group("/users")
+    authGroup.Use(middleware.AuthMiddleware())
+    {
+        authGroup.GET("/account/export", ac.ExportAccountData)
+        authGroup.GET("/account/deletion", ac.GetAccountDeletion)
+        authGroup.POST("/account/deletion", ac.RequestAccountDeletion)
+        authGroup.DELETE("/account/deletion", ac.CancelAccountDeletion)
+    }
+
+    user := models.User{OpenID: "OpenID_Account_User", Nickname: "Alice", AvatarURL: "avatars/alice.jpg", SessionKey: "secret"}
+    db.Create(&user)
+    other := models.User{OpenID: "OpenID_Account_Other", Nickname: "Bob", AvatarURL: defaultAvatarKey}
+    db.Create(&other)
+    putUpload(t, root, "avatars/alice.jpg", time.Now())
+
+    // 用户发布的新闻和评论、点赞、草稿、个人记录
+    news := models.News{Title: "Alice news", AuthorID: user.ID, LikeCount: 2}
+    db.Create(&news)
+    otherNews := models.News{Title: "Bob news", AuthorID: other.ID, LikeCount: 1}
+    db.Create(&otherNews)
+    db.Create(&models.Comment{Content: "Alice comment", UserID: user.ID, NewsID: otherNews.ID})
+    models.NewsLikes.Link(db, user.ID, otherNews.ID)
+    models.NewsLikes.Link(db, other.ID, news.ID)
+    models.NewsLikes.Link(db, user.ID, news.ID)
+    draft := models.Draft{Title: "Alice draft", AuthorID: user.ID}
+    db.Create(&draft)
+    db.Create(&models.DraftParagraph{DraftID: draft.ID, Text: "secret draft"})
+    db.Create(&models.NutritionIntake{UserID: user.ID, Date: time.Now(), MealType: models.Lunch, Calories: 500})
+    db.Create(&models.FoodPreference{UserID: user.ID, Name: "vegan"})
+    db.Create(&models.UserIdentity{UserID: user.ID, Provider: models.IdentityEmail, Subject: "alice@example.com", PasswordHash: "hash"})
+    db.Create(&models.LoginCode{Channel: models.IdentityEmail, Target: "alice@example.com", CodeHash: "x", ExpiresAt: time.Now()})
+    db.Create(&models.UserFollow{FollowerID: other.ID, FolloweeID: user.ID})
+    db.Create(&models.Notification{UserID: other.ID, ActorID: user.ID, Type: models.NotificationLike, NewsID: otherNews.ID})
+    collection := models.Collection{UserID: user.ID, Name: "Favorites"}
+    db.Create(&collection)
+    db.Create(&models.CollectionItem{CollectionID: collection.ID, ItemType: models.CollectionItemNews, ItemID: otherNews.ID})
+    db.Create(&models.ShareCode{Code: "alice-share", NewsID: otherNews.ID, UserID: user.ID})
+    db.Create(&models.ShareEvent{NewsID: otherNews.ID, UserID: user.ID, Channel: models.ShareChannelCopyLink, Code: "alice-share"})
+    db.Create(&models.ShareCode{Code: "bob-share", NewsID: otherNews.ID, UserID: other.ID})
+    db.Create(&models.ShareEvent{NewsID: otherNews.ID, UserID: other.ID, Channel: models.ShareChannelMoment, Code: "bob-share"})
+    session := models.Session{UserID: user.ID, LastUsedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+    db.Create(&session)
+    db.Create(&models.RefreshToken{JTI: "account-jti", TokenHash: "x", UserID: user.ID, SessionID: session.ID})
+
+    // 用户是家庭唯一的管理员，注销后管理员顺延给其他成员
+    family := models.Family{Name: "Home", Token: "family-token", MemberCount: 2}
+    db.Create(&family)
+    db.Model(&family).Association("Admins").Append(&user)
+    db.Model(&family).Association("Members").Append(&other)
+    db.Model(&models.User{}).Where("id IN ?", []uint{user.ID, other.ID}).Update("family_id", family.ID)
+
+    request := func(method, url string, userID uint) *httptest.ResponseRecorder {
+        req, _ := http.NewRequest(method, url, nil)
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    t.Run("Export JSON", func(t *testing.T) {
+        w := request("GET", "/users/account/export?format=json", user.ID)
+        assert.Equal(t, http.StatusOK, w.Code)
+        var export AccountExport
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+        assert.Equal(t, user.ID, export.UserID)
+        assert.Len(t, export.Data, len(accountExportSections))
+        assert.Len(t, export.Data["profile"], 1)
+        assert.Equal(t, "Alice", export.Data["profile"][0]["nickname"])
+        assert.NotContains(t, export.Data["profile"][0], "session_key")
+        assert.NotContains(t, export.Data["identities"][0], "password_hash")
+        assert.NotContains(t, export.Data["family"][0], "token")
+        assert.Len(t, export.Data["news"], 1)
+        assert.Len(t, export.Data["comments"], 1)
+        assert.Len(t, export.Data["liked_news"], 2)
+        assert.Len(t, export.Data["draft_paragraphs"], 1)
+        assert.Len(t, export.Data["nutrition_intakes"], 1)
+        assert.Len(t, export.Data["followers"], 1)
+        assert.Equal(t, []string{"avatars/alice.jpg"}, export.Files)
+
+        w = request("GET", "/users/account/export?format=xml", user.ID)
+        assert.Equal(t, http.StatusBadRequest, w.Code)
+    })
+
+    t.Run("Export ZIP", func(t *testing.T) {
+        w := request("GET", "/users/account/export", user.ID)
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+
+        zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+        assert.NoError(t, err)
+        files := map[string][]byte{}
+        for _, f := range zr.File {
+            r, _ := f.Open()
+            data, _ := io.ReadAll(r)
+            r.Close()
+            files[f.Name] = data
+        }
+        assert.Contains(t, files, "export.json")
+        assert.Contains(t, files, "data/news.json")
+        assert.Equal(t, []byte("avatars/alice.jpg"), files["files/avatars/alice.jpg"])
+    })
+
+    t.Run("Request And Cancel Deletion", func(t *testing.T) {
+        w := request("DELETE", "/users/account/deletion", user.ID)
+        assert.Equal(t, http.StatusNotFound, w.Code)
+
+        w = request("POST", "/users/account/deletion", user.ID)
+        assert.Equal(t, http.StatusAccepted, w.Code)
+        var status map[string]interface{}
+        json.Unmarshal(w.Body.Bytes(), &status)
+        assert.Equal(t, true, status["scheduled"])
+
+        // 宽限期未结束时不会注销
+        purged, err := ac.PurgeDueAccounts(context.Background(), time.Now())
+        assert.NoError(t, err)
+        assert.Equal(t, 0, purged)
+
+        w = request("DELETE", "/users/account/deletion", user.ID)
+        assert.Equal(t, http.StatusOK, w.Code)
+        w = request("GET", "/users/account/deletion", user.ID)
+        json.Unmarshal(w.Body.Bytes(), &status)
+        assert.Equal(t, false, status["scheduled"])
+    })
+
+    t.Run("Purge After Grace Period", func(t *testing.T) {
+        w := request("POST", "/users/account/deletion", user.ID)
+        assert.Equal(t, http.StatusAccepted, w.Code)
+
+        purged, err := ac.PurgeDueAccounts(context.Background(), time.Now().Add(AccountDeletionGracePeriod()+time.Minute))
+        assert.NoError(t, err)
+        assert.Equal(t, 1, purged)
+
+        var anonymized models.User
+        db.First(&anonymized, user.ID)
+        assert.Equal(t, DeletedUserNickname, anonymized.Nickname)
+        assert.Equal(t, defaultAvatarKey, anonymized.AvatarURL)
+        assert.Empty(t, anonymized.SessionKey)
+        assert.NotEqual(t, user.OpenID, anonymized.OpenID)
+        assert.Nil(t, anonymized.FamilyID)
+        assert.NotNil(t, anonymized.AnonymizedAt)
+        assert.Nil(t, anonymized.DeletionScheduledAt)
+
+        // 发布的内容保留，个人记录删除
+        var count int64
+        db.Model(&models.News{}).Where("author_id = ?", user.ID).Count(&count)
+        assert.Equal(t, int64(1), count)
+        db.Model(&models.Comment{}).Where("user_id = ?", user.ID).Count(&count)
+        assert.Equal(t, int64(1), count)
+        for _, model := range []interface{}{&models.Draft{}, &models.DraftParagraph{}, &models.NutritionIntake{}, &models.FoodPreference{},
+            &models.UserIdentity{}, &models.LoginCode{}, &models.UserFollow{}, &models.Notification{}, &models.Collection{},
+            &models.CollectionItem{}, &models.Session{}, &models.RefreshToken{}} {
+            db.Unscoped().Model(model).Count(&count)
+            assert.Equal(t, int64(0), count, "%T", model)
+        }
+        // 分享记录和短码按用户删除，其他用户的分享不受影响
+        for _, model := range []interface{}{&models.ShareCode{}, &models.ShareEvent{}} {
+            db.Model(model).Where("user_id = ?", user.ID).Count(&count)
+            assert.Equal(t, int64(0), count, "%T", model)
+            db.Model(model).Where("user_id = ?", other.ID).Count(&count)
+            assert.Equal(t, int64(1), count, "%T", model)
+        }
+
+        // 点赞计数同步减少
+        db.First(&otherNews, otherNews.ID)
+        assert.Equal(t, 0, otherNews.LikeCount)
+        db.First(&news, news.ID)
+        assert.Equal(t, 1, news.LikeCount)
+
+        // 家庭管理员顺延
+        db.Preload("Admins").First(&family, family.ID)
+        assert.Equal(t, uint(1), family.MemberCount)
+        if assert.Len(t, family.Admins, 1) {
+            assert.Equal(t, other.ID, family.Admins[0].ID)
+        }
+
+        // 头像文件已删除
+        _, err = os.Stat(filepath.Join(root, "avatars", "alice.jpg"))
+        assert.True(t, os.IsNotExist(err))
+
+        // 已注销的账号不能再次申请
+        request("POST", "/users/account/deletion", user.ID)
+        db.First(&anonymized, user.ID)
+        assert.Nil(t, anonymized.DeletionScheduledAt)
+    })
+}
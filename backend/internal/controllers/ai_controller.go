@@ -13,6 +13,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
 )
 
 type AIController struct {
@@ -67,7 +68,7 @@ type LLMResponse struct {
 func (ac *AIController) CheckIngredientsRequest(c *gin.Context) (bool,IngredientsRequest){
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Respond(c, apierror.ErrUnauthorized)
 		return false,IngredientsRequest{}
 	}
 	log.Printf("Processing image analysis request for user: %v", userID)
@@ -75,12 +76,12 @@ func (ac *AIController) CheckIngredientsRequest(c *gin.Context) (bool,Ingredient
 	var req IngredientsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("Invalid request data: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("Invalid request data"))
 		return false,IngredientsRequest{}
 	}
 
 	if req.IngredientName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Ingredient name is required"})
+		apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Ingredient name is required"))
 		return false,IngredientsRequest{}
 	}
 	log.Printf("Recommending similar ingredients for: %s", req.IngredientName)
@@ -99,7 +100,7 @@ func (ac *AIController) RecommendSimilarIngredients(c *gin.Context) {
 	outputText, err := ac.callLLMChatAPI(prompt)
 	if err != nil {
 		log.Printf("Error calling LLM API: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error analyzing image: %v", err)})
+		apierror.Respond(c, apierror.ErrUpstream.WithMessage(fmt.Sprintf("Error analyzing image: %v", err)))
 		return
 	}
 
@@ -123,7 +124,7 @@ func (ac *AIController) IntroduceIngredient(c *gin.Context) {
 	outputText, err := ac.callLLMChatAPI(prompt)
 	if err != nil {
 		log.Printf("Error calling LLM API: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error analyzing image: %v", err)})
+		apierror.Respond(c, apierror.ErrUpstream.WithMessage(fmt.Sprintf("Error analyzing image: %v", err)))
 		return
 	}
 
@@ -138,7 +139,7 @@ func (ac *AIController) IntroduceIngredient(c *gin.Context) {
 func (ac *AIController) AnalyzeImage(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Respond(c, apierror.ErrUnauthorized)
 		return
 	}
 	log.Printf("Processing image analysis request for user: %v", userID)
@@ -146,12 +147,12 @@ func (ac *AIController) AnalyzeImage(c *gin.Context) {
 	var req ImageAnalysisRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("Invalid request data: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("Invalid request data"))
 		return
 	}
 
 	if req.ImageURL == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Image URL is required"})
+		apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Image URL is required"))
 		return
 	}
 	log.Printf("Analyzing image from URL: %s", req.ImageURL)
@@ -159,7 +160,7 @@ func (ac *AIController) AnalyzeImage(c *gin.Context) {
 	analysisText, err := ac.callLLMImageAPI(req.ImageURL)
 	if err != nil {
 		log.Printf("Error calling LLM API: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error analyzing image: %v", err)})
+		apierror.Respond(c, apierror.ErrUpstream.WithMessage(fmt.Sprintf("Error analyzing image: %v", err)))
 		return
 	}
 
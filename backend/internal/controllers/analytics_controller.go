@@ -0,0 +1,377 @@
+// internal/controllers/analytics_controller.go
+package controllers
+
+import (
+    "encoding/csv"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+const (
+    defaultStatDays     = 30
+    maxStatDays         = 365
+    defaultTopNewsLimit = 10
+    maxTopNewsLimit     = 50
+)
+
+// AnalyticsController 作者数据统计，均基于日汇总表，不扫描互动关联表
+type AnalyticsController struct {
+    DB *gorm.DB
+}
+
+func NewAnalyticsController(db *gorm.DB) *AnalyticsController {
+    return &AnalyticsController{DB: db}
+}
+
+// NewsStats 一段时间内的互动数
+type NewsStats struct {
+    Views     int `json:"views"`
+    Likes     int `json:"likes"`
+    Favorites int `json:"favorites"`
+    Shares    int `json:"shares"`
+    Comments  int `json:"comments"`
+}
+
+// DailyNewsStats 某一天的互动数
+type DailyNewsStats struct {
+    Date string `json:"date"`
+    NewsStats
+}
+
+// DailyFollowerStats 某一天的关注者变化
+type DailyFollowerStats struct {
+    Date          string `json:"date"`
+    NewFollowers  int    `json:"new_followers"`
+    LostFollowers int    `json:"lost_followers"`
+    Followers     int    `json:"followers"` // 当天结束时的关注者数
+}
+
+// TopNewsItem 时间段内某项指标最高的新闻
+type TopNewsItem struct {
+    NewsID uint   `json:"news_id"`
+    Title  string `json:"title"`
+    Value  int    `json:"value"`
+}
+
+// recordNewsStat 在互动成功后累加新闻的日汇总，失败只记录日志
+func recordNewsStat(db *gorm.DB, news *models.News, column string, delta int) {
+    logStatError(models.RecordNewsStat(db, news.ID, news.AuthorID, column, delta, time.Now()))
+}
+
+// recordFollowerStat 在关注关系变化后更新作者的日汇总，失败只记录日志
+func recordFollowerStat(db *gorm.DB, authorID uint, delta int) {
+    logStatError(models.RecordFollowerStat(db, authorID, delta, time.Now()))
+}
+
+// logStatError 统计是尽力而为的，写入失败不影响主操作
+func logStatError(err error) {
+    if err != nil {
+        log.Printf("更新统计日汇总失败: %v", err)
+    }
+}
+
+// statDates 解析 days 参数，返回从最早一天到今天的日期列表
+func statDates(c *gin.Context, now time.Time) ([]string, bool) {
+    days := defaultStatDays
+    if v := c.Query("days"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1 || n > maxStatDays {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid days"))
+            return nil, false
+        }
+        days = n
+    }
+    now = now.Local()
+    dates := make([]string, days)
+    for i := range dates {
+        dates[i] = models.StatDate(now.AddDate(0, 0, i-days+1))
+    }
+    return dates, true
+}
+
+// dailyNewsStats 按天汇总满足条件的新闻互动，没有数据的日期补 0，同时返回整个时间段的合计
+func (ac *AnalyticsController) dailyNewsStats(dates []string, query string, args ...interface{}) ([]DailyNewsStats, NewsStats, error) {
+    var rows []DailyNewsStats
+    err := ac.DB.Model(&models.NewsDailyStat{}).
+        Select("date, SUM(views) AS views, SUM(likes) AS likes, SUM(favorites) AS favorites, SUM(shares) AS shares, SUM(comments) AS comments").
+        Where(query, args...).
+        Where("date >= ? AND date <= ?", dates[0], dates[len(dates)-1]).
+        Group("date").
+        Scan(&rows).Error
+    if err != nil {
+        return nil, NewsStats{}, err
+    }
+    byDate := make(map[string]NewsStats, len(rows))
+    for _, row := range rows {
+        byDate[row.Date] = row.NewsStats
+    }
+
+    var period NewsStats
+    series := make([]DailyNewsStats, len(dates))
+    for i, date := range dates {
+        s := byDate[date]
+        series[i] = DailyNewsStats{Date: date, NewsStats: s}
+        period.Views += s.Views
+        period.Likes += s.Likes
+        period.Favorites += s.Favorites
+        period.Shares += s.Shares
+        period.Comments += s.Comments
+    }
+    return series, period, nil
+}
+
+// dailyFollowerStats 按天返回作者的关注者变化，由当前关注者数倒推每天结束时的人数
+func (ac *AnalyticsController) dailyFollowerStats(authorID uint, dates []string) ([]DailyFollowerStats, int64, error) {
+    var total int64
+    if err := ac.DB.Model(&models.UserFollow{}).Where("followee_id = ?", authorID).Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+    var rows []models.AuthorDailyStat
+    if err := ac.DB.Where("author_id = ? AND date >= ?", authorID, dates[0]).Find(&rows).Error; err != nil {
+        return nil, 0, err
+    }
+    byDate := make(map[string]models.AuthorDailyStat, len(rows))
+    for _, row := range rows {
+        byDate[row.Date] = row
+    }
+
+    series := make([]DailyFollowerStats, len(dates))
+    followers := int(total)
+    for i := len(dates) - 1; i >= 0; i-- {
+        row := byDate[dates[i]]
+        series[i] = DailyFollowerStats{
+            Date:          dates[i],
+            NewFollowers:  row.NewFollowers,
+            LostFollowers: row.LostFollowers,
+            Followers:     followers,
+        }
+        followers -= row.NewFollowers - row.LostFollowers
+    }
+    return series, total, nil
+}
+
+// lifetimeStats 由新闻上的计数得到累计互动数
+func (ac *AnalyticsController) lifetimeStats(query string, args ...interface{}) (NewsStats, int64, error) {
+    var totals struct {
+        Articles int64
+        NewsStats
+    }
+    err := ac.DB.Model(&models.News{}).
+        Select(`COUNT(*) AS articles, COALESCE(SUM(view_count), 0) AS views, COALESCE(SUM(like_count), 0) AS likes,
+            COALESCE(SUM(favorite_count), 0) AS favorites, COALESCE(SUM(share_count), 0) AS shares`).
+        Where(query, args...).
+        Scan(&totals).Error
+    if err != nil {
+        return NewsStats{}, 0, err
+    }
+    var comments int64
+    err = ac.DB.Model(&models.Comment{}).
+        Joins("JOIN news ON news.id = comments.news_id").
+        Where("comments.is_deleted = ?", false).
+        Where(query, args...).
+        Count(&comments).Error
+    if err != nil {
+        return NewsStats{}, 0, err
+    }
+    totals.Comments = int(comments)
+    return totals.NewsStats, totals.Articles, nil
+}
+
+// GetOverview 作者所有新闻的累计数据、按天的互动趋势和关注者增长
+func (ac *AnalyticsController) GetOverview(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+    dates, ok := statDates(c, time.Now())
+    if !ok {
+        return
+    }
+
+    totals, articles, err := ac.lifetimeStats("news.author_id = ?", userID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch analytics"))
+        return
+    }
+    daily, period, err := ac.dailyNewsStats(dates, "author_id = ?", userID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch analytics"))
+        return
+    }
+    followers, followerTotal, err := ac.dailyFollowerStats(userID.(uint), dates)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch analytics"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "articles": articles,
+        "totals":   totals,
+        "period":   period,
+        "daily":    daily,
+        "followers": gin.H{
+            "total": followerTotal,
+            "daily": followers,
+        },
+    })
+}
+
+// GetNewsAnalytics 单篇新闻的累计数据和按天的互动趋势，只有作者可以查看
+func (ac *AnalyticsController) GetNewsAnalytics(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+    newsID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
+        return
+    }
+    dates, ok := statDates(c, time.Now())
+    if !ok {
+        return
+    }
+
+    var news models.News
+    if err := ac.DB.Select("id, title, author_id").First(&news, newsID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrNewsNotFound)
+            return
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
+        return
+    }
+    if news.AuthorID != userID.(uint) {
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You do not have permission to view analytics for this news"))
+        return
+    }
+
+    totals, _, err := ac.lifetimeStats("news.id = ?", news.ID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch analytics"))
+        return
+    }
+    daily, period, err := ac.dailyNewsStats(dates, "news_id = ?", news.ID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch analytics"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "news_id": news.ID,
+        "title":   news.Title,
+        "totals":  totals,
+        "period":  period,
+        "daily":   daily,
+    })
+}
+
+// GetTopNews 时间段内某项指标最高的新闻，metric 为 views、likes、favorites、shares 或 comments
+func (ac *AnalyticsController) GetTopNews(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    metric := c.DefaultQuery("metric", models.StatViews)
+    valid := false
+    for _, column := range models.NewsStatColumns {
+        if column == metric {
+            valid = true
+            break
+        }
+    }
+    if !valid {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid metric"))
+        return
+    }
+    limit := defaultTopNewsLimit
+    if v := c.Query("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1 || n > maxTopNewsLimit {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid limit"))
+            return
+        }
+        limit = n
+    }
+    dates, ok := statDates(c, time.Now())
+    if !ok {
+        return
+    }
+
+    items := make([]TopNewsItem, 0, limit)
+    err := ac.DB.Model(&models.NewsDailyStat{}).
+        Select("news_daily_stats.news_id, news.title, SUM(news_daily_stats."+metric+") AS value").
+        Joins("JOIN news ON news.id = news_daily_stats.news_id").
+        Where("news_daily_stats.author_id = ? AND news_daily_stats.date >= ?", userID, dates[0]).
+        Group("news_daily_stats.news_id, news.title").
+        Having("SUM(news_daily_stats."+metric+") > 0").
+        Order("value DESC, news_daily_stats.news_id").
+        Limit(limit).
+        Scan(&items).Error
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch analytics"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "metric": metric,
+        "news":   items,
+    })
+}
+
+// ExportAnalytics 以 CSV 导出时间段内每篇新闻每天的互动数，只包含有数据的日期
+func (ac *AnalyticsController) ExportAnalytics(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+    dates, ok := statDates(c, time.Now())
+    if !ok {
+        return
+    }
+
+    var rows []struct {
+        Date   string
+        NewsID uint
+        Title  string
+        NewsStats
+    }
+    err := ac.DB.Model(&models.NewsDailyStat{}).
+        Select("news_daily_stats.*, news.title").
+        Joins("JOIN news ON news.id = news_daily_stats.news_id").
+        Where("news_daily_stats.author_id = ? AND news_daily_stats.date >= ?", userID, dates[0]).
+        Order("news_daily_stats.date, news_daily_stats.news_id").
+        Scan(&rows).Error
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch analytics"))
+        return
+    }
+
+    c.Header("Content-Type", "text/csv; charset=utf-8")
+    c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="analytics-%s.csv"`, dates[len(dates)-1]))
+    c.Status(http.StatusOK)
+    w := csv.NewWriter(c.Writer)
+    w.Write([]string{"date", "news_id", "title", "views", "likes", "favorites", "shares", "comments"})
+    for _, row := range rows {
+        w.Write([]string{
+            row.Date, strconv.FormatUint(uint64(row.NewsID), 10), row.Title,
+            strconv.Itoa(row.Views), strconv.Itoa(row.Likes), strconv.Itoa(row.Favorites),
+            strconv.Itoa(row.Shares), strconv.Itoa(row.Comments),
+        })
+    }
+    w.Flush()
+}
@@ -0,0 +1,186 @@
+// internal/controllers/analytics_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+func TestAuthorAnalytics(t *testing.T) {
+    db := setupNewsTestDB()
+    assert.NoError(t, db.AutoMigrate(&models.News{}, &models.Comment{}))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    newsController := NewNewsController(db)
+    userController := NewUserController(db, nil)
+    analyticsController := NewAnalyticsController(db)
+    authGroup := router.Group("")
+    authGroup.Use(middleware.AuthMiddleware())
+    {
+        authGroup.POST("/news/comments", newsController.AddComment)
+        authGroup.POST("/news/:id/like", newsController.LikeNews)
+        authGroup.DELETE("/news/:id/like", newsController.CancelLikeNews)
+        authGroup.POST("/news/:id/favorite", newsController.FavoriteNews)
+        authGroup.POST("/news/:id/view", newsController.ViewNews)
+        authGroup.POST("/users/:id/follow", userController.FollowUser)
+        authGroup.DELETE("/users/:id/follow", userController.UnfollowUser)
+        authGroup.GET("/analytics/overview", analyticsController.GetOverview)
+        authGroup.GET("/analytics/news/:id", analyticsController.GetNewsAnalytics)
+        authGroup.GET("/analytics/top", analyticsController.GetTopNews)
+        authGroup.GET("/analytics/export", analyticsController.ExportAnalytics)
+    }
+
+    author := models.User{OpenID: "OpenID_Analytics_Author", Nickname: "Author"}
+    db.Create(&author)
+    var readers []models.User
+    for i := 0; i < 3; i++ {
+        reader := models.User{OpenID: fmt.Sprintf("OpenID_Analytics_Reader_%d", i), Nickname: fmt.Sprintf("reader%d", i)}
+        db.Create(&reader)
+        readers = append(readers, reader)
+    }
+    popular := models.News{Title: "Popular, \"quoted\"", AuthorID: author.ID}
+    db.Create(&popular)
+    quiet := models.News{Title: "Quiet", AuthorID: author.ID}
+    db.Create(&quiet)
+    others := models.News{Title: "Someone Else's", AuthorID: readers[0].ID}
+    db.Create(&others)
+
+    request := func(method, url string, userID uint, body string) *httptest.ResponseRecorder {
+        req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+        if body != "" {
+            req.Header.Set("Content-Type", "application/json")
+        }
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+    decode := func(w *httptest.ResponseRecorder, v interface{}) {
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), v))
+    }
+
+    // 今天的互动：popular 被 3 人浏览、2 人点赞（其中 1 人取消）、1 人收藏、1 条评论；quiet 被 1 人浏览
+    for _, r := range readers {
+        assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/news/%d/view", popular.ID), r.ID, "").Code)
+    }
+    assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/news/%d/view", popular.ID), readers[0].ID, "").Code) // 去重
+    assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/news/%d/view", quiet.ID), readers[0].ID, "").Code)
+    assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/news/%d/like", popular.ID), readers[0].ID, "").Code)
+    assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/news/%d/like", popular.ID), readers[1].ID, "").Code)
+    assert.Equal(t, http.StatusOK, request("DELETE", fmt.Sprintf("/news/%d/like", popular.ID), readers[1].ID, "").Code)
+    assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/news/%d/favorite", popular.ID), readers[2].ID, "").Code)
+    assert.Equal(t, http.StatusCreated, request("POST", "/news/comments", readers[1].ID,
+        fmt.Sprintf(`{"news_id":%d,"content":"nice"}`, popular.ID)).Code)
+
+    // 关注：3 人关注，1 人取消；另有两天前的历史汇总
+    for _, r := range readers {
+        assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/users/%d/follow", author.ID), r.ID, "").Code)
+    }
+    w := request("POST", fmt.Sprintf("/users/%d/follow", author.ID), readers[0].ID, "")
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    assert.Equal(t, http.StatusBadRequest, request("POST", fmt.Sprintf("/users/%d/follow", author.ID), author.ID, "").Code)
+    w = request("DELETE", fmt.Sprintf("/users/%d/follow", author.ID), readers[2].ID, "")
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.JSONEq(t, `{"message":"User unfollowed successfully","follower_count":2}`, w.Body.String())
+
+    twoDaysAgo := models.StatDate(time.Now().AddDate(0, 0, -2))
+    db.Create(&models.AuthorDailyStat{AuthorID: author.ID, Date: twoDaysAgo, NewFollowers: 1})
+    early := models.User{OpenID: "OpenID_Analytics_Early", Nickname: "early"}
+    db.Create(&early)
+    db.Create(&models.UserFollow{FollowerID: early.ID, FolloweeID: author.ID})
+    db.Create(&models.NewsDailyStat{NewsID: quiet.ID, Date: twoDaysAgo, AuthorID: author.ID, Views: 10, Shares: 2})
+    today := models.StatDate(time.Now())
+
+    t.Run("Overview", func(t *testing.T) {
+        w := request("GET", "/analytics/overview?days=3", author.ID, "")
+        assert.Equal(t, http.StatusOK, w.Code)
+        var resp struct {
+            Articles  int64            `json:"articles"`
+            Totals    NewsStats        `json:"totals"`
+            Period    NewsStats        `json:"period"`
+            Daily     []DailyNewsStats `json:"daily"`
+            Followers struct {
+                Total int64                `json:"total"`
+                Daily []DailyFollowerStats `json:"daily"`
+            } `json:"followers"`
+        }
+        decode(w, &resp)
+        assert.Equal(t, int64(2), resp.Articles)
+        assert.Equal(t, NewsStats{Views: 4, Likes: 1, Favorites: 1, Comments: 1}, resp.Totals)
+        assert.Equal(t, NewsStats{Views: 14, Likes: 1, Favorites: 1, Shares: 2, Comments: 1}, resp.Period)
+        if assert.Len(t, resp.Daily, 3) {
+            assert.Equal(t, DailyNewsStats{Date: twoDaysAgo, NewsStats: NewsStats{Views: 10, Shares: 2}}, resp.Daily[0])
+            assert.Equal(t, NewsStats{}, resp.Daily[1].NewsStats)
+            assert.Equal(t, DailyNewsStats{Date: today, NewsStats: NewsStats{Views: 4, Likes: 1, Favorites: 1, Comments: 1}}, resp.Daily[2])
+        }
+        assert.Equal(t, int64(3), resp.Followers.Total)
+        if assert.Len(t, resp.Followers.Daily, 3) {
+            assert.Equal(t, DailyFollowerStats{Date: twoDaysAgo, NewFollowers: 1, Followers: 1}, resp.Followers.Daily[0])
+            assert.Equal(t, 1, resp.Followers.Daily[1].Followers)
+            assert.Equal(t, DailyFollowerStats{Date: today, NewFollowers: 3, LostFollowers: 1, Followers: 3}, resp.Followers.Daily[2])
+        }
+
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/analytics/overview?days=0", author.ID, "").Code)
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/analytics/overview?days=366", author.ID, "").Code)
+    })
+
+    t.Run("News Analytics", func(t *testing.T) {
+        w := request("GET", fmt.Sprintf("/analytics/news/%d?days=1", popular.ID), author.ID, "")
+        assert.Equal(t, http.StatusOK, w.Code)
+        var resp struct {
+            Totals NewsStats        `json:"totals"`
+            Daily  []DailyNewsStats `json:"daily"`
+        }
+        decode(w, &resp)
+        assert.Equal(t, NewsStats{Views: 3, Likes: 1, Favorites: 1, Comments: 1}, resp.Totals)
+        assert.Equal(t, []DailyNewsStats{{Date: today, NewsStats: resp.Totals}}, resp.Daily)
+
+        assert.Equal(t, http.StatusForbidden, request("GET", fmt.Sprintf("/analytics/news/%d", others.ID), author.ID, "").Code)
+        assert.Equal(t, http.StatusNotFound, request("GET", "/analytics/news/99999", author.ID, "").Code)
+    })
+
+    t.Run("Top News", func(t *testing.T) {
+        var resp struct {
+            News []TopNewsItem `json:"news"`
+        }
+        w := request("GET", "/analytics/top?metric=views&days=7", author.ID, "")
+        assert.Equal(t, http.StatusOK, w.Code)
+        decode(w, &resp)
+        assert.Equal(t, []TopNewsItem{{NewsID: quiet.ID, Title: "Quiet", Value: 11}, {NewsID: popular.ID, Title: popular.Title, Value: 3}}, resp.News)
+
+        // 时间段外的数据不计入
+        w = request("GET", "/analytics/top?metric=views&days=1&limit=1", author.ID, "")
+        decode(w, &resp)
+        assert.Equal(t, []TopNewsItem{{NewsID: popular.ID, Title: popular.Title, Value: 3}}, resp.News)
+
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/analytics/top?metric=title", author.ID, "").Code)
+    })
+
+    t.Run("Export CSV", func(t *testing.T) {
+        w := request("GET", "/analytics/export?days=7", author.ID, "")
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+        assert.Contains(t, w.Header().Get("Content-Disposition"), "analytics-"+today+".csv")
+
+        records, err := csv.NewReader(w.Body).ReadAll()
+        assert.NoError(t, err)
+        assert.Equal(t, [][]string{
+            {"date", "news_id", "title", "views", "likes", "favorites", "shares", "comments"},
+            {twoDaysAgo, fmt.Sprint(quiet.ID), "Quiet", "10", "0", "0", "2", "0"},
+            {today, fmt.Sprint(popular.ID), popular.Title, "3", "1", "1", "0", "1"},
+            {today, fmt.Sprint(quiet.ID), "Quiet", "1", "0", "0", "0", "0"},
+        }, records)
+    })
+}
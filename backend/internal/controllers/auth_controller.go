@@ -0,0 +1,481 @@
+// internal/controllers/auth_controller.go
+package controllers
+
+import (
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/auth"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/wechat"
+)
+
+// defaultAvatarPath 新用户的默认头像
+const defaultAvatarPath = "avatars/default.jpg"
+
+// LoginRequest 登录、注册和关联身份的请求体
+type LoginRequest struct {
+    auth.Credentials
+    Nickname   string `json:"nickname"`    // 仅注册时使用，为空时随机生成
+    DeviceName string `json:"device_name"` // 可选，显示在设备会话列表中
+}
+
+// IdentityItem 用户已关联的登录身份
+type IdentityItem struct {
+    ID          uint       `json:"id"`
+    Provider    string     `json:"provider"`
+    Subject     string     `json:"subject,omitempty"` // 邮箱或手机号，微信身份不返回 OpenID
+    HasPassword bool       `json:"has_password"`
+    CreatedAt   time.Time  `json:"created_at"`
+    LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+func newIdentityItem(identity models.UserIdentity) IdentityItem {
+    item := IdentityItem{
+        ID:          identity.ID,
+        Provider:    identity.Provider,
+        Subject:     identity.Subject,
+        HasPassword: identity.PasswordHash != "",
+        CreatedAt:   identity.CreatedAt,
+        LastLoginAt: identity.LastLoginAt,
+    }
+    if identity.Provider == models.IdentityWeChat {
+        item.Subject = ""
+    }
+    return item
+}
+
+// respondAuthError 把登录方式返回的错误转为响应
+func respondAuthError(c *gin.Context, err error) {
+    var requestErr *wechat.RequestError
+    var responseErr *wechat.ResponseError
+    var apiErr *wechat.APIError
+    switch {
+    case errors.As(err, &requestErr):
+        log.Println("调用微信 API 失败:", err)
+        apierror.Respond(c, apierror.ErrUpstream.WithMessage("Failed to call WeChat API"))
+    case errors.As(err, &responseErr):
+        log.Println("解析微信 API 响应失败:", err)
+        apierror.Respond(c, apierror.ErrUpstream.WithMessage("Failed to parse WeChat API response"))
+    case errors.As(err, &apiErr):
+        apierror.Respond(c, apierror.ErrUnauthorized.WithMessage(apiErr.Message))
+    case errors.Is(err, auth.ErrInvalidCredentials):
+        apierror.Respond(c, apierror.ErrUnauthorized.WithMessage(err.Error()))
+    case errors.Is(err, auth.ErrUnknownProvider):
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage(err.Error()))
+    case errors.Is(err, auth.ErrCodeTooFrequent):
+        apierror.Respond(c, apierror.ErrTooManyRequests.WithMessage(err.Error()))
+    case errors.Is(err, auth.ErrInvalidEmail), errors.Is(err, auth.ErrInvalidPhone),
+        errors.Is(err, auth.ErrMissingTarget), errors.Is(err, auth.ErrWeakPassword),
+        errors.Is(err, auth.ErrUnsupportedChannel):
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage(err.Error()))
+    default:
+        log.Println("登录验证失败:", err)
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to authenticate"))
+    }
+}
+
+// findIdentityUser 查找身份关联的用户，未关联时返回 nil；
+// 尚未迁移到 user_identities 的微信用户按 users.open_id 查找
+func findIdentityUser(db *gorm.DB, identity auth.Identity) (*models.User, error) {
+    var user models.User
+    err := db.Joins("JOIN user_identities ON user_identities.user_id = users.id").
+        Where("user_identities.provider = ? AND user_identities.subject = ?", identity.Provider, identity.Subject).
+        First(&user).Error
+    if err == nil {
+        return &user, nil
+    }
+    if !errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, err
+    }
+    if identity.Provider != models.IdentityWeChat {
+        return nil, nil
+    }
+
+    err = db.Where("open_id = ?", identity.Subject).First(&user).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &user, nil
+}
+
+// saveIdentity 保存用户的登录身份；同一身份已存在时改为关联到该用户（原用户已被删除的情况）
+func saveIdentity(tx *gorm.DB, userID uint, identity auth.Identity, passwordHash string) (*models.UserIdentity, error) {
+    row := models.UserIdentity{
+        UserID:       userID,
+        Provider:     identity.Provider,
+        Subject:      identity.Subject,
+        PasswordHash: passwordHash,
+    }
+    if err := tx.Clauses(clause.OnConflict{
+        Columns:   []clause.Column{{Name: "provider"}, {Name: "subject"}},
+        DoUpdates: clause.AssignmentColumns([]string{"user_id", "password_hash"}),
+    }).Create(&row).Error; err != nil {
+        return nil, err
+    }
+    return &row, nil
+}
+
+// createUserWithIdentity 创建新用户及其第一个登录身份，非微信用户使用占位的 OpenID
+func createUserWithIdentity(db *gorm.DB, identity auth.Identity, nickname, passwordHash string) (*models.User, error) {
+    openID := identity.Subject
+    if identity.Provider != models.IdentityWeChat {
+        var err error
+        if openID, err = auth.SyntheticOpenID(); err != nil {
+            return nil, err
+        }
+    }
+    if nickname = strings.TrimSpace(nickname); nickname == "" {
+        nickname = utils.GenerateRandomNickname()
+    }
+
+    user := models.User{
+        OpenID:     openID,
+        SessionKey: identity.SessionKey,
+        Nickname:   nickname,
+        AvatarURL:  defaultAvatarPath, // 使用默认头像
+        CreatedAt:  time.Now(),
+        UpdatedAt:  time.Now(),
+    }
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&user).Error; err != nil {
+            return err
+        }
+        _, err := saveIdentity(tx, user.ID, identity, passwordHash)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &user, nil
+}
+
+// loginIdentity 使用验证通过的身份登录，身份未关联用户且登录方式允许时自动注册
+func (uc *UserController) loginIdentity(c *gin.Context, provider auth.Provider, identity auth.Identity, deviceName string) {
+    user, err := findIdentityUser(uc.DB, identity)
+    if err != nil {
+        log.Println("查询数据库时发生错误:", err)
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Database error"))
+        return
+    }
+
+    if user == nil {
+        if !provider.AutoRegister() {
+            apierror.Respond(c, apierror.ErrUnauthorized.WithMessage(auth.ErrInvalidCredentials.Error()))
+            return
+        }
+        if user, err = createUserWithIdentity(uc.DB, identity, "", ""); err != nil {
+            log.Println("创建用户失败:", err)
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to create user"))
+            return
+        }
+    } else {
+        // 补全旧微信用户的身份记录，并更新微信会话密钥和最近登录时间
+        if identity.Provider == models.IdentityWeChat {
+            if err := uc.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserIdentity{
+                UserID: user.ID, Provider: identity.Provider, Subject: identity.Subject,
+            }).Error; err != nil {
+                log.Println("保存微信身份失败:", err)
+            }
+            if identity.SessionKey != "" {
+                uc.DB.Model(user).UpdateColumn("session_key", identity.SessionKey)
+            }
+        }
+    }
+    uc.DB.Model(&models.UserIdentity{}).
+        Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).
+        UpdateColumn("last_login_at", time.Now())
+
+    uc.issueTokens(c, http.StatusOK, user, deviceName)
+}
+
+// issueTokens 为用户创建设备会话并返回 Access Token 和 Refresh Token
+func (uc *UserController) issueTokens(c *gin.Context, status int, user *models.User, deviceName string) {
+    // 生成 Access Token
+    accessToken, err := uc.Utils.GenerateAccessToken(user.ID)
+    if err != nil {
+        log.Println("生成 Access Token 失败:", err)
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to generate access token"))
+        return
+    }
+
+    // 生成 Refresh Token
+    refreshToken, newRefreshToken, err := uc.newRefreshToken(user.ID)
+    if err != nil {
+        log.Println("生成 Refresh Token 失败:", err)
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to generate refresh token"))
+        return
+    }
+
+    // 每次登录创建一个设备会话，并存储 Refresh Token 到数据库
+    session := newSession(c, user.ID, deviceName)
+    if err := uc.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&session).Error; err != nil {
+            return err
+        }
+        newRefreshToken.SessionID = session.ID
+        return tx.Create(newRefreshToken).Error
+    }); err != nil {
+        log.Println("存储 Refresh Token 失败:", err)
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to store refresh token"))
+        return
+    }
+
+    c.JSON(status, gin.H{
+        "access_token":  accessToken,
+        "refresh_token": refreshToken,
+        "user": gin.H{
+            "id":         user.ID,
+            "nickname":   user.Nickname,
+            "avatar_url": user.AvatarURL,
+        },
+    })
+}
+
+// Login 使用指定的登录方式登录：wechat（code）、password（email / phone + password）、code（email / phone + 验证码）
+func (uc *UserController) Login(c *gin.Context) {
+    provider, err := uc.Auth.Get(c.Param("provider"))
+    if err != nil {
+        respondAuthError(c, err)
+        return
+    }
+
+    var request LoginRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+
+    identity, err := provider.Authenticate(c.Request.Context(), request.Credentials)
+    if err != nil {
+        respondAuthError(c, err)
+        return
+    }
+    uc.loginIdentity(c, provider, identity, request.DeviceName)
+}
+
+// SendLoginCode 向邮箱或手机号发送一次性验证码，用于验证码登录、注册和关联身份
+func (uc *UserController) SendLoginCode(c *gin.Context) {
+    var request auth.Credentials
+    if err := c.ShouldBindJSON(&request); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+    if err := uc.Codes.SendCode(c.Request.Context(), request); err != nil {
+        if errors.Is(err, auth.ErrCodeTooFrequent) || errors.Is(err, auth.ErrMissingTarget) ||
+            errors.Is(err, auth.ErrInvalidEmail) || errors.Is(err, auth.ErrInvalidPhone) ||
+            errors.Is(err, auth.ErrUnsupportedChannel) {
+            respondAuthError(c, err)
+            return
+        }
+        log.Println("发送验证码失败:", err)
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to send verification code"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Verification code sent"})
+}
+
+// Register 使用邮箱或手机号注册并设置密码，需要先通过 SendLoginCode 获取验证码
+func (uc *UserController) Register(c *gin.Context) {
+    var request LoginRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+
+    // 先校验密码，避免密码不合格时白白消耗验证码
+    passwordHash, err := auth.HashPassword(request.Password)
+    if err != nil {
+        respondAuthError(c, err)
+        return
+    }
+    identity, err := uc.Codes.Authenticate(c.Request.Context(), request.Credentials)
+    if err != nil {
+        respondAuthError(c, err)
+        return
+    }
+
+    existing, err := findIdentityUser(uc.DB, identity)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Database error"))
+        return
+    }
+    if existing != nil {
+        apierror.Respond(c, apierror.ErrConflict.WithMessage("Account already exists"))
+        return
+    }
+
+    user, err := createUserWithIdentity(uc.DB, identity, request.Nickname, passwordHash)
+    if err != nil {
+        log.Println("创建用户失败:", err)
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to create user"))
+        return
+    }
+    uc.issueTokens(c, http.StatusCreated, user, request.DeviceName)
+}
+
+// userIdentities 返回用户的所有登录身份，旧微信用户会先补全微信身份记录
+func (uc *UserController) userIdentities(userID uint) ([]models.UserIdentity, error) {
+    var user models.User
+    if err := uc.DB.Select("id, open_id").First(&user, userID).Error; err != nil {
+        return nil, err
+    }
+    if user.OpenID != "" && !strings.HasPrefix(user.OpenID, "local_") {
+        if err := uc.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserIdentity{
+            UserID: user.ID, Provider: models.IdentityWeChat, Subject: user.OpenID,
+        }).Error; err != nil {
+            return nil, err
+        }
+    }
+
+    var identities []models.UserIdentity
+    if err := uc.DB.Where("user_id = ?", userID).Order("id").Find(&identities).Error; err != nil {
+        return nil, err
+    }
+    return identities, nil
+}
+
+// GetIdentities 获取自己已关联的登录身份
+func (uc *UserController) GetIdentities(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    identities, err := uc.userIdentities(userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch identities"))
+        return
+    }
+    items := make([]IdentityItem, len(identities))
+    for i, identity := range identities {
+        items[i] = newIdentityItem(identity)
+    }
+    c.JSON(http.StatusOK, gin.H{"identities": items})
+}
+
+// LinkIdentity 为当前用户关联新的登录身份：wechat 使用微信 code，code 使用邮箱 / 手机号验证码，可同时设置密码
+func (uc *UserController) LinkIdentity(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+    provider, err := uc.Auth.Get(c.Param("provider"))
+    if err != nil {
+        respondAuthError(c, err)
+        return
+    }
+    if !provider.AutoRegister() {
+        // 密码只能证明已关联的身份，新的邮箱 / 手机号需要验证码
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("Use a verification code to link an email or phone"))
+        return
+    }
+
+    var request LoginRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+    var passwordHash string
+    if request.Password != "" && provider.Name() != models.IdentityWeChat {
+        if passwordHash, err = auth.HashPassword(request.Password); err != nil {
+            respondAuthError(c, err)
+            return
+        }
+    }
+
+    identity, err := provider.Authenticate(c.Request.Context(), request.Credentials)
+    if err != nil {
+        respondAuthError(c, err)
+        return
+    }
+    owner, err := findIdentityUser(uc.DB, identity)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Database error"))
+        return
+    }
+    if owner != nil {
+        if owner.ID == userID.(uint) {
+            apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("Identity already linked"))
+            return
+        }
+        apierror.Respond(c, apierror.ErrConflict.WithMessage("This identity is linked to another account"))
+        return
+    }
+
+    row, err := saveIdentity(uc.DB, userID.(uint), identity, passwordHash)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to link identity"))
+        return
+    }
+    c.JSON(http.StatusCreated, gin.H{"identity": newIdentityItem(*row)})
+}
+
+// UnlinkIdentity 取消关联登录身份，至少保留一种登录方式
+func (uc *UserController) UnlinkIdentity(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+    identityID, err := strconv.Atoi(c.Param("id"))
+    if err != nil || identityID <= 0 {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid identity ID"))
+        return
+    }
+
+    identities, err := uc.userIdentities(userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch identities"))
+        return
+    }
+    var target *models.UserIdentity
+    for i := range identities {
+        if identities[i].ID == uint(identityID) {
+            target = &identities[i]
+        }
+    }
+    if target == nil {
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("Identity not found"))
+        return
+    }
+    if len(identities) == 1 {
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("Cannot unlink the last login method"))
+        return
+    }
+
+    err = uc.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Delete(target).Error; err != nil {
+            return err
+        }
+        if target.Provider != models.IdentityWeChat {
+            return nil
+        }
+        // users.open_id 也会被当作微信身份，取消关联后换成占位值
+        openID, err := auth.SyntheticOpenID()
+        if err != nil {
+            return err
+        }
+        return tx.Model(&models.User{}).Where("id = ? AND open_id = ?", userID, target.Subject).
+            Updates(map[string]interface{}{"open_id": openID, "session_key": ""}).Error
+    })
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to unlink identity"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Identity unlinked successfully"})
+}
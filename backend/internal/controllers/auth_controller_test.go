@@ -0,0 +1,199 @@
+// internal/controllers/auth_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+// recordingSender 记录最近一次发送到每个目标的验证码
+type recordingSender struct {
+    codes map[string]string
+}
+
+func (s *recordingSender) Send(ctx context.Context, channel, target, code string) error {
+    s.codes[target] = code
+    return nil
+}
+
+func TestAlternativeLogin(t *testing.T) {
+    db := setupUserTestDB()
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    uc := NewUserController(db, utils.UtilsImpl{})
+    sender := &recordingSender{codes: map[string]string{}}
+    uc.Codes.Sender = sender
+    router.POST("/users/login/:provider", uc.Login)
+    router.POST("/users/send_code", uc.SendLoginCode)
+    router.POST("/users/register", uc.Register)
+    authGroup := router.Group("/users")
+    authGroup.Use(middleware.AuthMiddleware())
+    {
+        authGroup.GET("/identities", uc.GetIdentities)
+        authGroup.POST("/identities/:provider", uc.LinkIdentity)
+        authGroup.DELETE("/identities/:id", uc.UnlinkIdentity)
+    }
+
+    request := func(method, url string, body interface{}, userID uint) (int, map[string]interface{}) {
+        var reader *bytes.Buffer
+        if body != nil {
+            data, _ := json.Marshal(body)
+            reader = bytes.NewBuffer(data)
+        } else {
+            reader = bytes.NewBuffer(nil)
+        }
+        req, _ := http.NewRequest(method, url, reader)
+        req.Header.Set("Content-Type", "application/json")
+        if userID != 0 {
+            req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        var resp map[string]interface{}
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        return w.Code, resp
+    }
+    // sendCode 发送验证码并返回收到的验证码；同一目标的重发间隔在测试中清零
+    sendCode := func(creds gin.H, target string) string {
+        db.Model(&models.LoginCode{}).Where("target = ?", target).UpdateColumn("created_at", "2000-01-01 00:00:00")
+        status, _ := request("POST", "/users/send_code", creds, 0)
+        assert.Equal(t, http.StatusOK, status)
+        return sender.codes[target]
+    }
+    userIDOf := func(resp map[string]interface{}) uint {
+        user, _ := resp["user"].(map[string]interface{})
+        id, _ := user["id"].(float64)
+        return uint(id)
+    }
+
+    var userID uint
+    t.Run("Register With Email", func(t *testing.T) {
+        code := sendCode(gin.H{"email": "Alice@Example.com"}, "alice@example.com")
+        assert.Len(t, code, 6)
+
+        // 密码过短时不消耗验证码
+        status, _ := request("POST", "/users/register", gin.H{"email": "alice@example.com", "code": code, "password": "short"}, 0)
+        assert.Equal(t, http.StatusBadRequest, status)
+
+        status, _ = request("POST", "/users/register", gin.H{"email": "alice@example.com", "code": "000000x", "password": "correct horse"}, 0)
+        assert.Equal(t, http.StatusUnauthorized, status)
+
+        status, resp := request("POST", "/users/register", gin.H{
+            "email": "alice@example.com", "code": code, "password": "correct horse", "nickname": "Alice",
+        }, 0)
+        assert.Equal(t, http.StatusCreated, status)
+        assert.NotEmpty(t, resp["access_token"])
+        assert.NotEmpty(t, resp["refresh_token"])
+        userID = userIDOf(resp)
+
+        var user models.User
+        db.First(&user, userID)
+        assert.Equal(t, "Alice", user.Nickname)
+        assert.Contains(t, user.OpenID, "local_")
+
+        // 同一个验证码不能再次使用
+        status, _ = request("POST", "/users/register", gin.H{"email": "alice@example.com", "code": code, "password": "correct horse"}, 0)
+        assert.Equal(t, http.StatusUnauthorized, status)
+
+        code = sendCode(gin.H{"email": "alice@example.com"}, "alice@example.com")
+        status, _ = request("POST", "/users/register", gin.H{"email": "alice@example.com", "code": code, "password": "correct horse"}, 0)
+        assert.Equal(t, http.StatusConflict, status)
+    })
+
+    t.Run("Send Code Rate Limit", func(t *testing.T) {
+        status, _ := request("POST", "/users/send_code", gin.H{"phone": "+86 138-0000-0000"}, 0)
+        assert.Equal(t, http.StatusOK, status)
+        status, _ = request("POST", "/users/send_code", gin.H{"phone": "+8613800000000"}, 0)
+        assert.Equal(t, http.StatusTooManyRequests, status)
+        status, _ = request("POST", "/users/send_code", gin.H{"email": "not an email"}, 0)
+        assert.Equal(t, http.StatusBadRequest, status)
+    })
+
+    t.Run("Password Login", func(t *testing.T) {
+        status, resp := request("POST", "/users/login/password", gin.H{"email": "ALICE@example.com", "password": "correct horse"}, 0)
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, userID, userIDOf(resp))
+
+        status, resp = request("POST", "/users/login/password", gin.H{"email": "alice@example.com", "password": "wrong password"}, 0)
+        assert.Equal(t, http.StatusUnauthorized, status)
+        assert.Equal(t, "Invalid credentials", resp["error"])
+
+        // 未注册的账号返回相同的错误
+        status, resp = request("POST", "/users/login/password", gin.H{"email": "nobody@example.com", "password": "correct horse"}, 0)
+        assert.Equal(t, http.StatusUnauthorized, status)
+        assert.Equal(t, "Invalid credentials", resp["error"])
+
+        status, _ = request("POST", "/users/login/unknown", gin.H{}, 0)
+        assert.Equal(t, http.StatusNotFound, status)
+    })
+
+    t.Run("Code Login Auto Registers", func(t *testing.T) {
+        code := sendCode(gin.H{"email": "bob@example.com"}, "bob@example.com")
+        for i := 0; i < 5; i++ {
+            status, _ := request("POST", "/users/login/code", gin.H{"email": "bob@example.com", "code": "wrong"}, 0)
+            assert.Equal(t, http.StatusUnauthorized, status)
+        }
+        // 输错次数用完后正确的验证码也失效
+        status, _ := request("POST", "/users/login/code", gin.H{"email": "bob@example.com", "code": code}, 0)
+        assert.Equal(t, http.StatusUnauthorized, status)
+
+        code = sendCode(gin.H{"email": "bob@example.com"}, "bob@example.com")
+        status, resp := request("POST", "/users/login/code", gin.H{"email": "bob@example.com", "code": code}, 0)
+        assert.Equal(t, http.StatusOK, status)
+        assert.NotEqual(t, userID, userIDOf(resp))
+
+        code = sendCode(gin.H{"email": "alice@example.com"}, "alice@example.com")
+        status, resp = request("POST", "/users/login/code", gin.H{"email": "alice@example.com", "code": code}, 0)
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, userID, userIDOf(resp))
+    })
+
+    t.Run("Link And Unlink Identities", func(t *testing.T) {
+        status, _ := request("POST", "/users/identities/password", gin.H{"phone": "13900000000", "password": "correct horse"}, userID)
+        assert.Equal(t, http.StatusBadRequest, status)
+
+        code := sendCode(gin.H{"phone": "13900000000"}, "13900000000")
+        status, resp := request("POST", "/users/identities/code", gin.H{"phone": "139-0000-0000", "code": code, "password": "phone password"}, userID)
+        assert.Equal(t, http.StatusCreated, status)
+        identity, _ := resp["identity"].(map[string]interface{})
+        assert.Equal(t, "phone", identity["provider"])
+        assert.Equal(t, true, identity["has_password"])
+
+        status, resp = request("POST", "/users/login/password", gin.H{"phone": "13900000000", "password": "phone password"}, 0)
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, userID, userIDOf(resp))
+
+        // 已关联到其他账号的邮箱不能再关联
+        code = sendCode(gin.H{"email": "bob@example.com"}, "bob@example.com")
+        status, _ = request("POST", "/users/identities/code", gin.H{"email": "bob@example.com", "code": code}, userID)
+        assert.Equal(t, http.StatusConflict, status)
+
+        status, resp = request("GET", "/users/identities", nil, userID)
+        assert.Equal(t, http.StatusOK, status)
+        identities, _ := resp["identities"].([]interface{})
+        assert.Len(t, identities, 2)
+
+        var rows []models.UserIdentity
+        db.Where("user_id = ?", userID).Order("id").Find(&rows)
+        assert.Len(t, rows, 2)
+        status, _ = request("DELETE", fmt.Sprintf("/users/identities/%d", rows[0].ID), nil, userID)
+        assert.Equal(t, http.StatusOK, status)
+        status, resp = request("DELETE", fmt.Sprintf("/users/identities/%d", rows[1].ID), nil, userID)
+        assert.Equal(t, http.StatusBadRequest, status)
+        assert.Equal(t, "Cannot unlink the last login method", resp["error"])
+
+        status, _ = request("POST", "/users/login/password", gin.H{"email": "alice@example.com", "password": "correct horse"}, 0)
+        assert.Equal(t, http.StatusUnauthorized, status)
+    })
+}
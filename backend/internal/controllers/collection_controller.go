@@ -0,0 +1,743 @@
+// internal/controllers/collection_controller.go
+package controllers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+    "unicode/utf8"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+const (
+    defaultCollectionPageSize = 20
+    maxCollectionPageSize     = 50
+    maxCollectionDescLength   = 200
+)
+
+type CollectionController struct {
+    DB *gorm.DB
+}
+
+func NewCollectionController(db *gorm.DB) *CollectionController {
+    return &CollectionController{DB: db}
+}
+
+// CollectionResponse 收藏夹信息
+type CollectionResponse struct {
+    ID          uint      `json:"id"`
+    UserID      uint      `json:"user_id"`
+    Name        string    `json:"name"`
+    Description string    `json:"description"`
+    IsPublic    bool      `json:"is_public"`
+    Position    int       `json:"position"`
+    ItemCount   int64     `json:"item_count"`
+    CreatedAt   time.Time `json:"created_at"`
+    UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CollectionNewsInfo 收藏夹中新闻的摘要
+type CollectionNewsInfo struct {
+    ID    uint   `json:"id"`
+    Title string `json:"title"`
+}
+
+// CollectionRecipeInfo 收藏夹中食谱的摘要
+type CollectionRecipeInfo struct {
+    ID       uint   `json:"id"`
+    Name     string `json:"name"`
+    ImageURL string `json:"image_url"`
+    Category string `json:"category"`
+}
+
+// CollectionItemResponse 收藏夹中的一条内容，news 和 recipe 按类型只返回其一；内容已被删除时两者都为空
+type CollectionItemResponse struct {
+    ID       uint                  `json:"id"`
+    ItemType string                `json:"item_type"`
+    ItemID   uint                  `json:"item_id"`
+    Position int                   `json:"position"`
+    AddedAt  time.Time             `json:"added_at"`
+    News     *CollectionNewsInfo   `json:"news,omitempty"`
+    Recipe   *CollectionRecipeInfo `json:"recipe,omitempty"`
+}
+
+// validCollectionName 去掉首尾空白后校验收藏夹名称
+func validCollectionName(name string) (string, bool) {
+    name = strings.TrimSpace(name)
+    return name, name != "" && utf8.RuneCountInString(name) <= models.MaxCollectionNameLength
+}
+
+// hideBlockedNewsItems 过滤掉当前用户屏蔽的作者的新闻条目
+func hideBlockedNewsItems(viewerID uint) func(*gorm.DB) *gorm.DB {
+    return func(db *gorm.DB) *gorm.DB {
+        if viewerID == 0 {
+            return db
+        }
+        return db.Where("NOT (item_type = ? AND item_id IN (SELECT id FROM news WHERE author_id IN (SELECT blocked_id FROM user_blocks WHERE blocker_id = ?)))",
+            models.CollectionItemNews, viewerID)
+    }
+}
+
+// findOwnCollection 查找当前用户自己的收藏夹，出错时已写入响应
+func findOwnCollection(c *gin.Context, db *gorm.DB, userID uint, idStr string) (*models.Collection, bool) {
+    collectionID, err := strconv.ParseUint(idStr, 10, 64)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid collection ID"))
+        return nil, false
+    }
+    var collection models.Collection
+    if err := db.Where("id = ? AND user_id = ?", collectionID, userID).First(&collection).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrCollectionNotFound)
+            return nil, false
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find collection"))
+        return nil, false
+    }
+    return &collection, true
+}
+
+// collectionResponses 批量补充收藏夹的条目数
+func (cc *CollectionController) collectionResponses(viewerID uint, collections []models.Collection) ([]CollectionResponse, error) {
+    responses := make([]CollectionResponse, 0, len(collections))
+    if len(collections) == 0 {
+        return responses, nil
+    }
+    ids := make([]uint, len(collections))
+    for i, collection := range collections {
+        ids[i] = collection.ID
+    }
+
+    var counts []struct {
+        CollectionID uint
+        Count        int64
+    }
+    if err := cc.DB.Model(&models.CollectionItem{}).
+        Select("collection_id, COUNT(*) AS count").
+        Where("collection_id IN ?", ids).
+        Scopes(hideBlockedNewsItems(viewerID)).
+        Group("collection_id").
+        Scan(&counts).Error; err != nil {
+        return nil, err
+    }
+    itemCounts := make(map[uint]int64, len(counts))
+    for _, ic := range counts {
+        itemCounts[ic.CollectionID] = ic.Count
+    }
+
+    for _, collection := range collections {
+        responses = append(responses, CollectionResponse{
+            ID:          collection.ID,
+            UserID:      collection.UserID,
+            Name:        collection.Name,
+            Description: collection.Description,
+            IsPublic:    collection.IsPublic,
+            Position:    collection.Position,
+            ItemCount:   itemCounts[collection.ID],
+            CreatedAt:   collection.CreatedAt,
+            UpdatedAt:   collection.UpdatedAt,
+        })
+    }
+    return responses, nil
+}
+
+// itemResponses 批量加载条目对应的新闻和食谱
+func (cc *CollectionController) itemResponses(items []models.CollectionItem) ([]CollectionItemResponse, error) {
+    var newsIDs, recipeIDs []uint
+    for _, item := range items {
+        if item.ItemType == models.CollectionItemNews {
+            newsIDs = append(newsIDs, item.ItemID)
+        } else {
+            recipeIDs = append(recipeIDs, item.ItemID)
+        }
+    }
+
+    newsByID := make(map[uint]*CollectionNewsInfo)
+    if len(newsIDs) > 0 {
+        var newsList []models.News
+        if err := cc.DB.Select("id, title").Where("id IN ?", newsIDs).Find(&newsList).Error; err != nil {
+            return nil, err
+        }
+        for _, news := range newsList {
+            newsByID[news.ID] = &CollectionNewsInfo{ID: news.ID, Title: news.Title}
+        }
+    }
+    recipeByID := make(map[uint]*CollectionRecipeInfo)
+    if len(recipeIDs) > 0 {
+        var recipes []models.Recipe
+        if err := cc.DB.Select("id, name, image_url, category").Where("id IN ?", recipeIDs).Find(&recipes).Error; err != nil {
+            return nil, err
+        }
+        for _, recipe := range recipes {
+            recipeByID[recipe.ID] = &CollectionRecipeInfo{ID: recipe.ID, Name: recipe.Name, ImageURL: recipe.ImageURL, Category: recipe.Category}
+        }
+    }
+
+    responses := make([]CollectionItemResponse, 0, len(items))
+    for _, item := range items {
+        resp := CollectionItemResponse{
+            ID:       item.ID,
+            ItemType: item.ItemType,
+            ItemID:   item.ItemID,
+            Position: item.Position,
+            AddedAt:  item.CreatedAt,
+        }
+        if item.ItemType == models.CollectionItemNews {
+            resp.News = newsByID[item.ItemID]
+        } else {
+            resp.Recipe = recipeByID[item.ItemID]
+        }
+        responses = append(responses, resp)
+    }
+    return responses, nil
+}
+
+// GetCollections 获取收藏夹列表；指定 user_id 时获取该用户的公开收藏夹
+func (cc *CollectionController) GetCollections(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    ownerID := userID.(uint)
+    if v := c.Query("user_id"); v != "" {
+        id, err := strconv.ParseUint(v, 10, 64)
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid user ID"))
+            return
+        }
+        ownerID = uint(id)
+    }
+
+    query := cc.DB.Where("user_id = ?", ownerID)
+    if ownerID != userID.(uint) {
+        // 对方的主页对当前用户不可见时，公开的收藏夹也不可见
+        canView, err := models.CanViewProfile(cc.DB, userID.(uint), ownerID)
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch collections"))
+            return
+        }
+        if !canView {
+            c.JSON(http.StatusOK, gin.H{"collections": []CollectionResponse{}})
+            return
+        }
+        query = query.Where("is_public = ?", true).Scopes(models.NotBlockedBy(userID.(uint), "user_id"))
+    }
+    var collections []models.Collection
+    if err := query.Order("position ASC, id ASC").Find(&collections).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch collections"))
+        return
+    }
+
+    responses, err := cc.collectionResponses(userID.(uint), collections)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch collections"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"collections": responses})
+}
+
+// CreateCollectionRequest 创建收藏夹的请求体
+type CreateCollectionRequest struct {
+    Name        string `json:"name" binding:"required"`
+    Description string `json:"description"`
+    IsPublic    bool   `json:"is_public"`
+}
+
+// CreateCollection 新建收藏夹，排在列表最后
+func (cc *CollectionController) CreateCollection(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var req CreateCollectionRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+    name, ok := validCollectionName(req.Name)
+    if !ok {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid collection name"))
+        return
+    }
+    if utf8.RuneCountInString(req.Description) > maxCollectionDescLength {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Description is too long"))
+        return
+    }
+
+    var count int64
+    if err := cc.DB.Model(&models.Collection{}).Where("user_id = ? AND name = ?", userID, name).Count(&count).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to create collection"))
+        return
+    }
+    if count > 0 {
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("Collection name already exists"))
+        return
+    }
+
+    var maxPosition int
+    if err := cc.DB.Model(&models.Collection{}).Where("user_id = ?", userID).
+        Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to create collection"))
+        return
+    }
+
+    collection := models.Collection{
+        UserID:      userID.(uint),
+        Name:        name,
+        Description: req.Description,
+        IsPublic:    req.IsPublic,
+        Position:    maxPosition + 1,
+    }
+    if err := cc.DB.Create(&collection).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to create collection"))
+        return
+    }
+
+    responses, err := cc.collectionResponses(userID.(uint), []models.Collection{collection})
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch collection"))
+        return
+    }
+    c.JSON(http.StatusCreated, gin.H{
+        "message":    "Collection created successfully",
+        "collection": responses[0],
+    })
+}
+
+// UpdateCollectionRequest 修改收藏夹的请求体，只更新提供的字段
+type UpdateCollectionRequest struct {
+    Name        *string `json:"name"`
+    Description *string `json:"description"`
+    IsPublic    *bool   `json:"is_public"`
+}
+
+// UpdateCollection 修改收藏夹名称、描述或公开状态，未提供的字段保持不变
+func (cc *CollectionController) UpdateCollection(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var req UpdateCollectionRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+
+    collection, ok := findOwnCollection(c, cc.DB, userID.(uint), c.Param("id"))
+    if !ok {
+        return
+    }
+
+    updates := map[string]interface{}{}
+    if req.Name != nil {
+        name, ok := validCollectionName(*req.Name)
+        if !ok {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid collection name"))
+            return
+        }
+        var count int64
+        if err := cc.DB.Model(&models.Collection{}).
+            Where("user_id = ? AND name = ? AND id <> ?", collection.UserID, name, collection.ID).
+            Count(&count).Error; err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update collection"))
+            return
+        }
+        if count > 0 {
+            apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("Collection name already exists"))
+            return
+        }
+        updates["name"] = name
+    }
+    if req.Description != nil {
+        if utf8.RuneCountInString(*req.Description) > maxCollectionDescLength {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Description is too long"))
+            return
+        }
+        updates["description"] = *req.Description
+    }
+    if req.IsPublic != nil {
+        updates["is_public"] = *req.IsPublic
+    }
+
+    if len(updates) > 0 {
+        if err := cc.DB.Model(collection).Updates(updates).Error; err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update collection"))
+            return
+        }
+    }
+
+    responses, err := cc.collectionResponses(userID.(uint), []models.Collection{*collection})
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch collection"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{
+        "message":    "Collection updated successfully",
+        "collection": responses[0],
+    })
+}
+
+// DeleteCollection 删除收藏夹及其中的条目，其中的新闻仍保留在收藏中
+func (cc *CollectionController) DeleteCollection(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    collection, ok := findOwnCollection(c, cc.DB, userID.(uint), c.Param("id"))
+    if !ok {
+        return
+    }
+
+    err := cc.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Where("collection_id = ?", collection.ID).Delete(&models.CollectionItem{}).Error; err != nil {
+            return err
+        }
+        return tx.Delete(collection).Error
+    })
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to delete collection"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Collection deleted successfully"})
+}
+
+// reorder 按 ids 的顺序重新设置 position，ids 必须恰好包含 existing 中的每个 ID 一次
+func reorder(tx *gorm.DB, model interface{}, existing, ids []uint) (bool, error) {
+    if len(ids) != len(existing) {
+        return false, nil
+    }
+    remaining := make(map[uint]bool, len(existing))
+    for _, id := range existing {
+        remaining[id] = true
+    }
+    for _, id := range ids {
+        if !remaining[id] {
+            return false, nil
+        }
+        delete(remaining, id)
+    }
+    for i, id := range ids {
+        if err := tx.Model(model).Where("id = ?", id).UpdateColumn("position", i+1).Error; err != nil {
+            return true, err
+        }
+    }
+    return true, nil
+}
+
+// ReorderRequest 调整顺序的请求体，ids 需按新顺序列出全部条目
+type ReorderRequest struct {
+    IDs []uint `json:"ids" binding:"required"`
+}
+
+// ReorderCollections 调整收藏夹的顺序，ids 为全部收藏夹的新顺序
+func (cc *CollectionController) ReorderCollections(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var req ReorderRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+
+    valid := true
+    err := cc.DB.Transaction(func(tx *gorm.DB) error {
+        var existing []uint
+        if err := tx.Model(&models.Collection{}).Where("user_id = ?", userID).Pluck("id", &existing).Error; err != nil {
+            return err
+        }
+        var err error
+        valid, err = reorder(tx, &models.Collection{}, existing, req.IDs)
+        return err
+    })
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to reorder collections"))
+        return
+    }
+    if !valid {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("ids must list every collection exactly once"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Collections reordered successfully"})
+}
+
+// GetCollection 查看收藏夹及其中的条目，按顺序分页，cursor 为上一页最后一个条目的 ID；他人的收藏夹只有公开时可见
+func (cc *CollectionController) GetCollection(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    collectionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid collection ID"))
+        return
+    }
+    limit := defaultCollectionPageSize
+    if v := c.Query("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1 || n > maxCollectionPageSize {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid limit"))
+            return
+        }
+        limit = n
+    }
+
+    var collection models.Collection
+    if err := cc.DB.Where("id = ? AND (user_id = ? OR is_public = ?)", collectionID, userID, true).
+        Scopes(models.NotBlockedBy(userID.(uint), "user_id")).
+        First(&collection).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrCollectionNotFound)
+            return
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find collection"))
+        return
+    }
+    if collection.UserID != userID.(uint) {
+        canView, err := models.CanViewProfile(cc.DB, userID.(uint), collection.UserID)
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find collection"))
+            return
+        }
+        if !canView {
+            apierror.Respond(c, apierror.ErrCollectionNotFound)
+            return
+        }
+    }
+
+    query := cc.DB.Where("collection_id = ?", collection.ID).Scopes(hideBlockedNewsItems(userID.(uint)))
+    if v := c.Query("cursor"); v != "" {
+        var last models.CollectionItem
+        if err := cc.DB.Where("id = ? AND collection_id = ?", v, collection.ID).First(&last).Error; err != nil {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid cursor"))
+            return
+        }
+        query = query.Where("position > ? OR (position = ? AND id > ?)", last.Position, last.Position, last.ID)
+    }
+
+    var items []models.CollectionItem
+    if err := query.Order("position ASC, id ASC").Limit(limit + 1).Find(&items).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch collection items"))
+        return
+    }
+    hasMore := len(items) > limit
+    nextCursor := ""
+    if hasMore {
+        items = items[:limit]
+        nextCursor = strconv.FormatUint(uint64(items[limit-1].ID), 10)
+    }
+
+    collectionResp, err := cc.collectionResponses(userID.(uint), []models.Collection{collection})
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch collection"))
+        return
+    }
+    itemResp, err := cc.itemResponses(items)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch collection items"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "collection":  collectionResp[0],
+        "items":       itemResp,
+        "next_cursor": nextCursor,
+        "has_more":    hasMore,
+    })
+}
+
+// addCollectionItem 将内容追加到收藏夹末尾，已存在时返回 false
+func addCollectionItem(tx *gorm.DB, collectionID uint, itemType string, itemID uint) (*models.CollectionItem, bool, error) {
+    var maxPosition int
+    if err := tx.Model(&models.CollectionItem{}).Where("collection_id = ?", collectionID).
+        Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error; err != nil {
+        return nil, false, err
+    }
+    item := models.CollectionItem{CollectionID: collectionID, ItemType: itemType, ItemID: itemID, Position: maxPosition + 1}
+    result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&item)
+    if result.Error != nil {
+        return nil, false, result.Error
+    }
+    return &item, result.RowsAffected > 0, nil
+}
+
+// AddCollectionItemRequest 向收藏夹添加条目的请求体
+type AddCollectionItemRequest struct {
+    ItemType string `json:"item_type" binding:"required"`
+    ItemID   uint   `json:"item_id" binding:"required"`
+}
+
+// AddCollectionItem 向收藏夹添加新闻或食谱；添加新闻时同时收藏该新闻
+func (cc *CollectionController) AddCollectionItem(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var req AddCollectionItemRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+    if req.ItemType != models.CollectionItemNews && req.ItemType != models.CollectionItemRecipe {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid item type"))
+        return
+    }
+
+    collection, ok := findOwnCollection(c, cc.DB, userID.(uint), c.Param("id"))
+    if !ok {
+        return
+    }
+
+    var news models.News
+    if req.ItemType == models.CollectionItemNews {
+        if err := cc.DB.Select("id, author_id").First(&news, req.ItemID).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                apierror.Respond(c, apierror.ErrNewsNotFound)
+                return
+            }
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
+            return
+        }
+    } else {
+        var recipe models.Recipe
+        if err := cc.DB.Select("id").First(&recipe, req.ItemID).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                apierror.Respond(c, apierror.ErrNotFound.WithMessage("Recipe not found"))
+                return
+            }
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find recipe"))
+            return
+        }
+    }
+
+    var item *models.CollectionItem
+    added, favorited := false, false
+    err := cc.DB.Transaction(func(tx *gorm.DB) error {
+        var err error
+        item, added, err = addCollectionItem(tx, collection.ID, req.ItemType, req.ItemID)
+        if err != nil || !added || req.ItemType != models.CollectionItemNews {
+            return err
+        }
+        // 放入收藏夹的新闻同时计入收藏
+        favorited, err = models.NewsFavorites.Link(tx, userID.(uint), news.ID)
+        if err != nil || !favorited {
+            return err
+        }
+        _, err = models.NewsFavorites.Adjust(tx, news.ID, 1)
+        return err
+    })
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to add item to collection"))
+        return
+    }
+    if !added {
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("Item already in collection"))
+        return
+    }
+    if favorited {
+        recordNewsStat(cc.DB, &news, models.StatFavorites, 1)
+        logNotifyError(notifyNewsAuthor(cc.DB, &news, userID.(uint), models.NotificationFavorite))
+    }
+
+    responses, err := cc.itemResponses([]models.CollectionItem{*item})
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch collection items"))
+        return
+    }
+    c.JSON(http.StatusCreated, gin.H{
+        "message": "Item added to collection successfully",
+        "item":    responses[0],
+    })
+}
+
+// RemoveCollectionItem 从收藏夹移除条目，不取消对新闻的收藏
+func (cc *CollectionController) RemoveCollectionItem(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    collection, ok := findOwnCollection(c, cc.DB, userID.(uint), c.Param("id"))
+    if !ok {
+        return
+    }
+    itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 64)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid item ID"))
+        return
+    }
+
+    result := cc.DB.Where("id = ? AND collection_id = ?", itemID, collection.ID).Delete(&models.CollectionItem{})
+    if result.Error != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to remove item from collection"))
+        return
+    }
+    if result.RowsAffected == 0 {
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("Item not found"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Item removed from collection successfully"})
+}
+
+// ReorderCollectionItems 调整收藏夹内条目的顺序，ids 为全部条目 ID 的新顺序
+func (cc *CollectionController) ReorderCollectionItems(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var req ReorderRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+
+    collection, ok := findOwnCollection(c, cc.DB, userID.(uint), c.Param("id"))
+    if !ok {
+        return
+    }
+
+    valid := true
+    err := cc.DB.Transaction(func(tx *gorm.DB) error {
+        var existing []uint
+        if err := tx.Model(&models.CollectionItem{}).Where("collection_id = ?", collection.ID).Pluck("id", &existing).Error; err != nil {
+            return err
+        }
+        var err error
+        valid, err = reorder(tx, &models.CollectionItem{}, existing, req.IDs)
+        return err
+    })
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to reorder collection items"))
+        return
+    }
+    if !valid {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("ids must list every item exactly once"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Collection items reordered successfully"})
+}
@@ -0,0 +1,231 @@
+// internal/controllers/collection_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+func TestCollections(t *testing.T) {
+    db := setupNewsTestDB()
+    assert.NoError(t, db.AutoMigrate(&models.News{}, &models.Comment{}, &models.Food{}, &models.Recipe{}))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    newsController := NewNewsController(db)
+    userController := NewUserController(db, nil)
+    collectionController := NewCollectionController(db)
+    authGroup := router.Group("")
+    authGroup.Use(middleware.AuthMiddleware())
+    {
+        authGroup.POST("/news/:id/favorite", newsController.FavoriteNews)
+        authGroup.DELETE("/news/:id/favorite", newsController.CancelFavoriteNews)
+        authGroup.GET("/users/favorited", userController.GetMyFavoritedNews)
+        authGroup.GET("/collections", collectionController.GetCollections)
+        authGroup.POST("/collections", collectionController.CreateCollection)
+        authGroup.PUT("/collections/order", collectionController.ReorderCollections)
+        authGroup.GET("/collections/:id", collectionController.GetCollection)
+        authGroup.PUT("/collections/:id", collectionController.UpdateCollection)
+        authGroup.DELETE("/collections/:id", collectionController.DeleteCollection)
+        authGroup.POST("/collections/:id/items", collectionController.AddCollectionItem)
+        authGroup.PUT("/collections/:id/items/order", collectionController.ReorderCollectionItems)
+        authGroup.DELETE("/collections/:id/items/:item_id", collectionController.RemoveCollectionItem)
+    }
+
+    owner := models.User{OpenID: "OpenID_Collection_Owner", Nickname: "owner"}
+    db.Create(&owner)
+    visitor := models.User{OpenID: "OpenID_Collection_Visitor", Nickname: "visitor"}
+    db.Create(&visitor)
+    var news []models.News
+    for i := 0; i < 3; i++ {
+        n := models.News{Title: fmt.Sprintf("Saved %d", i), AuthorID: visitor.ID}
+        db.Create(&n)
+        news = append(news, n)
+    }
+    recipe := models.Recipe{Name: "番茄炒蛋", URL: "https://example.com/r", ImageURL: "/static/recipes/1.jpg", Category: "家常菜"}
+    db.Create(&recipe)
+
+    request := func(method, url string, userID uint, body string) *httptest.ResponseRecorder {
+        req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+        if body != "" {
+            req.Header.Set("Content-Type", "application/json")
+        }
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+    decode := func(w *httptest.ResponseRecorder, v interface{}) {
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), v))
+    }
+    type collectionPage struct {
+        Collection CollectionResponse       `json:"collection"`
+        Items      []CollectionItemResponse `json:"items"`
+        NextCursor string                   `json:"next_cursor"`
+        HasMore    bool                     `json:"has_more"`
+    }
+    type favoritesPage struct {
+        NewsIDs    []uint `json:"news_ids"`
+        NextCursor string `json:"next_cursor"`
+        HasMore    bool   `json:"has_more"`
+    }
+
+    var reading, meals CollectionResponse
+    t.Run("Create And Update", func(t *testing.T) {
+        w := request("POST", "/collections", owner.ID, `{"name":"  Reading  ","description":"later"}`)
+        assert.Equal(t, http.StatusCreated, w.Code)
+        var resp struct {
+            Collection CollectionResponse `json:"collection"`
+        }
+        decode(w, &resp)
+        reading = resp.Collection
+        assert.Equal(t, "Reading", reading.Name)
+        assert.False(t, reading.IsPublic)
+
+        w = request("POST", "/collections", owner.ID, `{"name":"Reading"}`)
+        assert.JSONEq(t, `{"error":"Collection name already exists"}`, w.Body.String())
+        w = request("POST", "/collections", owner.ID, `{"name":"   "}`)
+        assert.JSONEq(t, `{"error":"Invalid collection name"}`, w.Body.String())
+
+        w = request("POST", "/collections", owner.ID, `{"name":"Meals","is_public":true}`)
+        decode(w, &resp)
+        meals = resp.Collection
+        assert.Equal(t, reading.Position+1, meals.Position)
+
+        w = request("PUT", fmt.Sprintf("/collections/%d", reading.ID), owner.ID, `{"name":"Meals"}`)
+        assert.Equal(t, http.StatusBadRequest, w.Code)
+        w = request("PUT", fmt.Sprintf("/collections/%d", reading.ID), owner.ID, `{"name":"Articles"}`)
+        assert.Equal(t, http.StatusOK, w.Code)
+        decode(w, &resp)
+        assert.Equal(t, "Articles", resp.Collection.Name)
+        assert.Equal(t, "later", resp.Collection.Description)
+
+        assert.Equal(t, http.StatusNotFound, request("PUT", fmt.Sprintf("/collections/%d", reading.ID), visitor.ID, `{"name":"Mine"}`).Code)
+    })
+
+    t.Run("Add Items", func(t *testing.T) {
+        // 放入收藏夹的新闻同时计入收藏
+        w := request("POST", fmt.Sprintf("/collections/%d/items", reading.ID), owner.ID, fmt.Sprintf(`{"item_type":"news","item_id":%d}`, news[0].ID))
+        assert.Equal(t, http.StatusCreated, w.Code)
+        var stored models.News
+        db.First(&stored, news[0].ID)
+        assert.Equal(t, 1, stored.FavoriteCount)
+
+        w = request("POST", fmt.Sprintf("/collections/%d/items", reading.ID), owner.ID, fmt.Sprintf(`{"item_type":"news","item_id":%d}`, news[0].ID))
+        assert.JSONEq(t, `{"error":"Item already in collection"}`, w.Body.String())
+
+        // 已收藏的新闻放入另一个收藏夹时不重复计数
+        assert.Equal(t, http.StatusCreated, request("POST", fmt.Sprintf("/collections/%d/items", meals.ID), owner.ID,
+            fmt.Sprintf(`{"item_type":"news","item_id":%d}`, news[0].ID)).Code)
+        db.First(&stored, news[0].ID)
+        assert.Equal(t, 1, stored.FavoriteCount)
+
+        w = request("POST", fmt.Sprintf("/collections/%d/items", meals.ID), owner.ID, fmt.Sprintf(`{"item_type":"recipe","item_id":%d}`, recipe.ID))
+        assert.Equal(t, http.StatusCreated, w.Code)
+        var resp struct {
+            Item CollectionItemResponse `json:"item"`
+        }
+        decode(w, &resp)
+        if assert.NotNil(t, resp.Item.Recipe) {
+            assert.Equal(t, "番茄炒蛋", resp.Item.Recipe.Name)
+        }
+        assert.Nil(t, resp.Item.News)
+
+        assert.Equal(t, http.StatusNotFound, request("POST", fmt.Sprintf("/collections/%d/items", meals.ID), owner.ID, `{"item_type":"recipe","item_id":99999}`).Code)
+        assert.Equal(t, http.StatusBadRequest, request("POST", fmt.Sprintf("/collections/%d/items", meals.ID), owner.ID, `{"item_type":"food","item_id":1}`).Code)
+
+        // 收藏新闻时指定收藏夹
+        assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/news/%d/favorite?collection_id=%d", news[1].ID, reading.ID), owner.ID, "").Code)
+        assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/news/%d/favorite?collection_id=%d", news[2].ID, reading.ID), owner.ID, "").Code)
+        assert.Equal(t, http.StatusNotFound, request("POST", fmt.Sprintf("/news/%d/favorite?collection_id=%d", news[2].ID, reading.ID), visitor.ID, "").Code)
+    })
+
+    t.Run("View And Reorder", func(t *testing.T) {
+        var page collectionPage
+        decode(request("GET", fmt.Sprintf("/collections/%d?limit=2", reading.ID), owner.ID, ""), &page)
+        assert.Equal(t, int64(3), page.Collection.ItemCount)
+        assert.True(t, page.HasMore)
+        if assert.Len(t, page.Items, 2) {
+            assert.Equal(t, "Saved 0", page.Items[0].News.Title)
+        }
+        var rest collectionPage
+        decode(request("GET", fmt.Sprintf("/collections/%d?limit=2&cursor=%s", reading.ID, page.NextCursor), owner.ID, ""), &rest)
+        assert.False(t, rest.HasMore)
+        items := append(page.Items, rest.Items...)
+        if !assert.Len(t, items, 3) {
+            return
+        }
+
+        w := request("PUT", fmt.Sprintf("/collections/%d/items/order", reading.ID), owner.ID, fmt.Sprintf(`{"ids":[%d,%d]}`, items[2].ID, items[0].ID))
+        assert.Equal(t, http.StatusBadRequest, w.Code)
+        w = request("PUT", fmt.Sprintf("/collections/%d/items/order", reading.ID), owner.ID,
+            fmt.Sprintf(`{"ids":[%d,%d,%d]}`, items[2].ID, items[0].ID, items[1].ID))
+        assert.Equal(t, http.StatusOK, w.Code)
+
+        var favorites favoritesPage
+        decode(request("GET", fmt.Sprintf("/users/favorited?collection_id=%d", reading.ID), owner.ID, ""), &favorites)
+        assert.Equal(t, []uint{news[2].ID, news[0].ID, news[1].ID}, favorites.NewsIDs)
+
+        // 私密收藏夹他人不可见，公开的可以
+        assert.Equal(t, http.StatusNotFound, request("GET", fmt.Sprintf("/collections/%d", reading.ID), visitor.ID, "").Code)
+        assert.Equal(t, http.StatusOK, request("GET", fmt.Sprintf("/collections/%d", meals.ID), visitor.ID, "").Code)
+        var list struct {
+            Collections []CollectionResponse `json:"collections"`
+        }
+        decode(request("GET", fmt.Sprintf("/collections?user_id=%d", owner.ID), visitor.ID, ""), &list)
+        if assert.Len(t, list.Collections, 1) {
+            assert.Equal(t, meals.ID, list.Collections[0].ID)
+        }
+
+        assert.Equal(t, http.StatusOK, request("PUT", "/collections/order", owner.ID, fmt.Sprintf(`{"ids":[%d,%d]}`, meals.ID, reading.ID)).Code)
+        decode(request("GET", "/collections", owner.ID, ""), &list)
+        if assert.Len(t, list.Collections, 2) {
+            assert.Equal(t, meals.ID, list.Collections[0].ID)
+            assert.Equal(t, int64(2), list.Collections[0].ItemCount)
+        }
+        assert.Equal(t, http.StatusBadRequest, request("PUT", "/collections/order", owner.ID, fmt.Sprintf(`{"ids":[%d,%d]}`, meals.ID, meals.ID)).Code)
+    })
+
+    t.Run("Favorites Pagination", func(t *testing.T) {
+        var page favoritesPage
+        decode(request("GET", "/users/favorited?limit=2", owner.ID, ""), &page)
+        assert.Equal(t, []uint{news[2].ID, news[1].ID}, page.NewsIDs)
+        assert.True(t, page.HasMore)
+        decode(request("GET", "/users/favorited?limit=2&cursor="+page.NextCursor, owner.ID, ""), &page)
+        assert.Equal(t, []uint{news[0].ID}, page.NewsIDs)
+        assert.False(t, page.HasMore)
+
+        assert.Equal(t, http.StatusBadRequest, request("GET", "/users/favorited?limit=0", owner.ID, "").Code)
+    })
+
+    t.Run("Remove", func(t *testing.T) {
+        // 取消收藏时从所有收藏夹移除
+        assert.Equal(t, http.StatusOK, request("DELETE", fmt.Sprintf("/news/%d/favorite", news[0].ID), owner.ID, "").Code)
+        var count int64
+        db.Model(&models.CollectionItem{}).Where("item_type = ? AND item_id = ?", models.CollectionItemNews, news[0].ID).Count(&count)
+        assert.Equal(t, int64(0), count)
+
+        // 从收藏夹移除条目不取消收藏
+        var item models.CollectionItem
+        db.Where("collection_id = ? AND item_id = ?", reading.ID, news[1].ID).First(&item)
+        assert.Equal(t, http.StatusOK, request("DELETE", fmt.Sprintf("/collections/%d/items/%d", reading.ID, item.ID), owner.ID, "").Code)
+        assert.Equal(t, http.StatusNotFound, request("DELETE", fmt.Sprintf("/collections/%d/items/%d", reading.ID, item.ID), owner.ID, "").Code)
+        var stored models.News
+        db.First(&stored, news[1].ID)
+        assert.Equal(t, 1, stored.FavoriteCount)
+
+        assert.Equal(t, http.StatusOK, request("DELETE", fmt.Sprintf("/collections/%d", reading.ID), owner.ID, "").Code)
+        db.Model(&models.CollectionItem{}).Where("collection_id = ?", reading.ID).Count(&count)
+        assert.Equal(t, int64(0), count)
+        assert.Equal(t, http.StatusNotFound, request("GET", fmt.Sprintf("/collections/%d", reading.ID), owner.ID, "").Code)
+    })
+}
@@ -0,0 +1,145 @@
+// internal/controllers/counter_test.go
+package controllers
+
+import (
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+func TestConcurrentNewsCounters(t *testing.T) {
+    db := setupNewsTestDB()
+    assert.NoError(t, db.AutoMigrate(&models.News{}, &models.Comment{}))
+    // 内存数据库每个连接各自独立，限制为单连接让并发请求共享同一个库
+    sqlDB, _ := db.DB()
+    sqlDB.SetMaxOpenConns(1)
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    newsController := NewNewsController(db)
+    newsGroup := router.Group("/news")
+    newsGroup.Use(middleware.AuthMiddleware())
+    {
+        newsGroup.POST("/:id/like", newsController.LikeNews)
+        newsGroup.DELETE("/:id/like", newsController.CancelLikeNews)
+        newsGroup.POST("/:id/favorite", newsController.FavoriteNews)
+        newsGroup.POST("/:id/view", newsController.ViewNews)
+    }
+
+    news := models.News{Title: "Counted News"}
+    db.Create(&news)
+    var users []models.User
+    for i := 0; i < 5; i++ {
+        user := models.User{OpenID: fmt.Sprintf("OpenID_Counter_%d", i), Nickname: fmt.Sprintf("counter%d", i)}
+        db.Create(&user)
+        users = append(users, user)
+    }
+
+    // 并发发送请求，返回各状态码出现的次数
+    burst := func(method, action string, userIDs []uint) map[int]int {
+        var mu sync.Mutex
+        var wg sync.WaitGroup
+        codes := map[int]int{}
+        for _, userID := range userIDs {
+            wg.Add(1)
+            go func(userID uint) {
+                defer wg.Done()
+                req, _ := http.NewRequest(method, fmt.Sprintf("/news/%d/%s", news.ID, action), nil)
+                req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+                w := httptest.NewRecorder()
+                router.ServeHTTP(w, req)
+                mu.Lock()
+                codes[w.Code]++
+                mu.Unlock()
+            }(userID)
+        }
+        wg.Wait()
+        return codes
+    }
+    counts := func() models.News {
+        var n models.News
+        db.First(&n, news.ID)
+        return n
+    }
+    repeat := func(userID uint, n int) []uint {
+        ids := make([]uint, n)
+        for i := range ids {
+            ids[i] = userID
+        }
+        return ids
+    }
+
+    t.Run("Duplicate Likes Count Once", func(t *testing.T) {
+        codes := burst("POST", "like", repeat(users[0].ID, 8))
+        assert.Equal(t, 1, codes[http.StatusOK])
+        assert.Equal(t, 7, codes[http.StatusBadRequest])
+        assert.Equal(t, 1, counts().LikeCount)
+
+        codes = burst("DELETE", "like", repeat(users[0].ID, 8))
+        assert.Equal(t, 1, codes[http.StatusOK])
+        assert.Equal(t, 0, counts().LikeCount)
+    })
+
+    t.Run("Likes From Many Users", func(t *testing.T) {
+        var ids []uint
+        for _, u := range users {
+            ids = append(ids, u.ID, u.ID)
+        }
+        codes := burst("POST", "favorite", ids)
+        assert.Equal(t, len(users), codes[http.StatusOK])
+        assert.Equal(t, len(users), counts().FavoriteCount)
+        var rows int64
+        db.Table("user_favorites_news").Where("news_id = ?", news.ID).Count(&rows)
+        assert.Equal(t, int64(len(users)), rows)
+    })
+
+    t.Run("Duplicate Views Count Once", func(t *testing.T) {
+        codes := burst("POST", "view", repeat(users[2].ID, 8))
+        assert.Equal(t, 8, codes[http.StatusOK])
+        assert.Equal(t, 1, counts().ViewCount)
+        var views int64
+        db.Model(&models.NewsView{}).Where("user_id = ? AND news_id = ?", users[2].ID, news.ID).Count(&views)
+        assert.Equal(t, int64(1), views)
+    })
+
+    t.Run("Reconcile", func(t *testing.T) {
+        drifts, err := models.ReconcileCounters(db, false)
+        assert.NoError(t, err)
+        assert.Empty(t, drifts)
+
+        // 制造偏差：计数被直接修改，且存在一条未计数的评论点赞
+        comment := models.Comment{NewsID: news.ID, UserID: users[0].ID, Content: "counted"}
+        db.Create(&comment)
+        db.Model(&models.User{ID: users[1].ID}).Association("LikedComments").Append(&comment)
+        db.Model(&models.News{}).Where("id = ?", news.ID).UpdateColumns(map[string]interface{}{"like_count": 3, "favorite_count": 1})
+
+        drifts, err = models.ReconcileCounters(db, false)
+        assert.NoError(t, err)
+        assert.Equal(t, []models.CounterDrift{
+            {Counter: "news likes", ID: news.ID, Stored: 3, Actual: 0},
+            {Counter: "news favorites", ID: news.ID, Stored: 1, Actual: len(users)},
+            {Counter: "comment likes", ID: comment.ID, Stored: 0, Actual: 1},
+        }, drifts)
+        // 只报告时不修改计数
+        assert.Equal(t, 3, counts().LikeCount)
+
+        drifts, err = models.ReconcileCounters(db, true)
+        assert.NoError(t, err)
+        assert.Len(t, drifts, 3)
+        assert.Equal(t, 0, counts().LikeCount)
+        assert.Equal(t, len(users), counts().FavoriteCount)
+        db.First(&comment, comment.ID)
+        assert.Equal(t, 1, comment.LikeCount)
+
+        drifts, err = models.ReconcileCounters(db, false)
+        assert.NoError(t, err)
+        assert.Empty(t, drifts)
+    })
+}
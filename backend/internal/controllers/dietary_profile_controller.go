@@ -0,0 +1,121 @@
+// internal/controllers/dietary_profile_controller.go
+package controllers
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm/clause"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+// GetDietaryOptions 获取饮食档案中可选的过敏原、饮食限制、烹饪水平和厨房设备
+func (fpc *FoodPreferenceController) GetDietaryOptions(c *gin.Context) {
+    c.JSON(http.StatusOK, gin.H{
+        "allergens":         models.Allergens,
+        "restrictions":      models.DietaryRestrictions,
+        "cooking_skills":    models.CookingSkills,
+        "kitchen_equipment": models.KitchenEquipment,
+    })
+}
+
+// GetDietaryProfile 获取当前用户的饮食档案
+func (fpc *FoodPreferenceController) GetDietaryProfile(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    profile, err := models.GetDietaryProfile(fpc.DB, userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch dietary profile"))
+        return
+    }
+    c.JSON(http.StatusOK, profile)
+}
+
+// UpdateDietaryProfileRequest 修改饮食档案的请求体，只更新提供的字段
+type UpdateDietaryProfileRequest struct {
+    Allergens        *[]string `json:"allergens"`
+    Restrictions     *[]string `json:"restrictions"`
+    CookingSkill     *string   `json:"cooking_skill"`
+    KitchenEquipment *[]string `json:"kitchen_equipment"`
+    MealBudget       *float64  `json:"meal_budget"`
+}
+
+// UpdateDietaryProfile 更新当前用户的饮食档案，只修改请求中给出的字段；列表字段整体替换
+func (fpc *FoodPreferenceController) UpdateDietaryProfile(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var req UpdateDietaryProfileRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+    if req.Allergens != nil && !models.ValidDietaryCodes(models.Allergens, *req.Allergens) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid allergen"))
+        return
+    }
+    if req.Restrictions != nil && !models.ValidDietaryCodes(models.DietaryRestrictions, *req.Restrictions) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid dietary restriction"))
+        return
+    }
+    if req.CookingSkill != nil && *req.CookingSkill != "" && !models.ValidDietaryCodes(models.CookingSkills, []string{*req.CookingSkill}) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid cooking skill"))
+        return
+    }
+    if req.KitchenEquipment != nil && !models.ValidDietaryCodes(models.KitchenEquipment, *req.KitchenEquipment) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid kitchen equipment"))
+        return
+    }
+    if req.MealBudget != nil && *req.MealBudget < 0 {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid meal budget"))
+        return
+    }
+
+    profile, err := models.GetDietaryProfile(fpc.DB, userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch dietary profile"))
+        return
+    }
+    if req.Allergens != nil {
+        profile.Allergens = uniqueStrings(*req.Allergens)
+    }
+    if req.Restrictions != nil {
+        profile.Restrictions = uniqueStrings(*req.Restrictions)
+    }
+    if req.CookingSkill != nil {
+        profile.CookingSkill = *req.CookingSkill
+    }
+    if req.KitchenEquipment != nil {
+        profile.KitchenEquipment = uniqueStrings(*req.KitchenEquipment)
+    }
+    if req.MealBudget != nil {
+        profile.MealBudget = *req.MealBudget
+    }
+
+    if err := fpc.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&profile).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update dietary profile"))
+        return
+    }
+    c.JSON(http.StatusOK, profile)
+}
+
+// uniqueStrings 去掉重复项，保持原有顺序
+func uniqueStrings(values []string) []string {
+    seen := make(map[string]bool, len(values))
+    result := make([]string, 0, len(values))
+    for _, v := range values {
+        if !seen[v] {
+            seen[v] = true
+            result = append(result, v)
+        }
+    }
+    return result
+}
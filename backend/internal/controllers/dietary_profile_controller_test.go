@@ -0,0 +1,153 @@
+// internal/controllers/dietary_profile_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+func TestDietaryProfile(t *testing.T) {
+    db := setupRecommendTestDB(t)
+    router, rc := setupRecommendTestRouter(db)
+    user := setupRecommendTestUser(db)
+    fpc := &FoodPreferenceController{DB: db}
+
+    router.Use(func(c *gin.Context) {
+        c.Set("user_id", user.ID)
+        c.Next()
+    })
+    router.GET("/dietary_profile/options", fpc.GetDietaryOptions)
+    router.GET("/dietary_profile", fpc.GetDietaryProfile)
+    router.PUT("/dietary_profile", fpc.UpdateDietaryProfile)
+    router.POST("/ingredients/recommend", rc.RecommendIngredients)
+    router.POST("/recipes/recommend", rc.RecommendRecipes)
+
+    request := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+        data, _ := json.Marshal(body)
+        req, _ := http.NewRequest(method, url, bytes.NewBuffer(data))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+    foodID := func(name string) uint {
+        id, err := models.FindFoodIDByName(db, name)
+        assert.NoError(t, err, name)
+        return id
+    }
+    shrimp, milk, carrot, potato, pork := foodID("shrimp"), foodID("milk"), foodID("carrot"), foodID("potato"), foodID("pork")
+
+    t.Run("Update Profile", func(t *testing.T) {
+        w := request("GET", "/dietary_profile", nil)
+        assert.Equal(t, http.StatusOK, w.Code)
+        var profile models.DietaryProfile
+        json.Unmarshal(w.Body.Bytes(), &profile)
+        assert.Empty(t, profile.Allergens)
+        assert.NotNil(t, profile.Allergens)
+
+        w = request("GET", "/dietary_profile/options", nil)
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.Contains(t, w.Body.String(), `"code":"shellfish"`)
+
+        for _, body := range []gin.H{
+            {"allergens": []string{"cats"}},
+            {"restrictions": []string{"keto"}},
+            {"cooking_skill": "chef"},
+            {"kitchen_equipment": []string{"spaceship"}},
+            {"meal_budget": -1},
+        } {
+            w = request("PUT", "/dietary_profile", body)
+            assert.Equal(t, http.StatusBadRequest, w.Code, "%v", body)
+        }
+
+        w = request("PUT", "/dietary_profile", gin.H{
+            "allergens":         []string{"shellfish", "dairy", "dairy"},
+            "cooking_skill":     "beginner",
+            "kitchen_equipment": []string{"stove", "rice_cooker"},
+            "meal_budget":       30,
+        })
+        assert.Equal(t, http.StatusOK, w.Code)
+        // 部分更新不影响其他字段
+        w = request("PUT", "/dietary_profile", gin.H{"restrictions": []string{"halal"}})
+        assert.Equal(t, http.StatusOK, w.Code)
+
+        profile, err := models.GetDietaryProfile(db, user.ID)
+        assert.NoError(t, err)
+        assert.Equal(t, []string{"shellfish", "dairy"}, profile.Allergens)
+        assert.Equal(t, []string{"halal"}, profile.Restrictions)
+        assert.Equal(t, "beginner", profile.CookingSkill)
+        assert.Equal(t, []string{"stove", "rice_cooker"}, profile.KitchenEquipment)
+        assert.Equal(t, 30.0, profile.MealBudget)
+    })
+
+    t.Run("Allergens Excluded From Ingredients", func(t *testing.T) {
+        for i := 0; i < 5; i++ {
+            w := request("POST", "/ingredients/recommend", gin.H{"liked_ingredients": []uint{shrimp, milk}})
+            assert.Equal(t, http.StatusOK, w.Code)
+            var response IngredientRecommendResponse
+            json.Unmarshal(w.Body.Bytes(), &response)
+            assert.NotEmpty(t, response.RecommendedIngredients)
+            for _, ingredient := range response.RecommendedIngredients {
+                assert.NotContains(t, []uint{shrimp, milk, pork}, ingredient.ID)
+            }
+        }
+
+        // 上次选择的食材中的过敏和饮食限制排除的食材也不返回
+        db.Create(&models.UserLastSelectedFoods{UserID: user.ID, FoodID: shrimp})
+        db.Create(&models.UserLastSelectedFoods{UserID: user.ID, FoodID: pork})
+        db.Create(&models.UserLastSelectedFoods{UserID: user.ID, FoodID: carrot})
+        w := request("POST", "/ingredients/recommend", gin.H{"use_last_ingredients": true})
+        var response IngredientRecommendResponse
+        json.Unmarshal(w.Body.Bytes(), &response)
+        if assert.Len(t, response.RecommendedIngredients, 1) {
+            assert.Equal(t, carrot, response.RecommendedIngredients[0].ID)
+        }
+    })
+
+    t.Run("Allergens Excluded From Recipes", func(t *testing.T) {
+        creamy := models.Recipe{Name: "Creamy carrot soup", URL: "creamy", ImageURL: "creamy.jpg", Ingredients: `{"carrot": 100, "Milk": 200}`}
+        shrimpPotato := models.Recipe{Name: "Shrimp and potato", URL: "shrimp", ImageURL: "shrimp.jpg", Ingredients: `{"shrimp": 100, "potato": 200}`}
+        roast := models.Recipe{Name: "Roast carrot", URL: "roast", ImageURL: "roast.jpg", Ingredients: `{"carrot": 100}`}
+        for _, recipe := range []*models.Recipe{&creamy, &shrimpPotato, &roast} {
+            db.Create(recipe)
+        }
+        links := [][2]uint{{creamy.ID, carrot}, {creamy.ID, milk}, {shrimpPotato.ID, shrimp}, {shrimpPotato.ID, potato}, {roast.ID, carrot}}
+        for _, link := range links {
+            db.Exec("INSERT INTO food_recipes (recipe_id, food_id) VALUES (?, ?)", link[0], link[1])
+        }
+
+        for i := 0; i < 5; i++ {
+            w := request("POST", "/recipes/recommend", gin.H{"selected_ingredients": []uint{carrot, shrimp, potato}})
+            assert.Equal(t, http.StatusOK, w.Code)
+            var response RecipeRecommendResponse
+            json.Unmarshal(w.Body.Bytes(), &response)
+            // 只有 potato 的食谱含有虾，不能作为兜底返回
+            if assert.Len(t, response.RecommendedRecipes, 1) {
+                assert.Equal(t, roast.ID, response.RecommendedRecipes[0].RecipeID)
+            }
+        }
+    })
+
+    t.Run("Restrictions Excluded From Recipe Fallback", func(t *testing.T) {
+        // potato 的食谱分别含有过敏食材和清真限制的猪肉，都不能作为兜底返回
+        porkPotato := models.Recipe{Name: "Pork and potato", URL: "pork", ImageURL: "pork.jpg", Ingredients: `{"pork": 100, "potato": 200}`}
+        db.Create(&porkPotato)
+        db.Exec("INSERT INTO food_recipes (recipe_id, food_id) VALUES (?, ?)", porkPotato.ID, pork)
+        db.Exec("INSERT INTO food_recipes (recipe_id, food_id) VALUES (?, ?)", porkPotato.ID, potato)
+
+        for i := 0; i < 5; i++ {
+            w := request("POST", "/recipes/recommend", gin.H{"selected_ingredients": []uint{potato}})
+            assert.Equal(t, http.StatusOK, w.Code)
+            var response RecipeRecommendResponse
+            json.Unmarshal(w.Body.Bytes(), &response)
+            assert.Empty(t, response.RecommendedRecipes)
+        }
+    })
+}
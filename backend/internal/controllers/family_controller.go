@@ -11,6 +11,8 @@ import (
 	"time"
     "math"
 
+	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+
 	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
 	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -25,33 +27,36 @@ func NewFamilyController(db *gorm.DB) *FamilyController {
     return &FamilyController{DB: db}
 }
 
+// CreateFamilyRequest 创建家庭的请求体
+type CreateFamilyRequest struct {
+    Name string `json:"name" binding:"required"`
+}
+
 // 创建家庭
 func (fc *FamilyController) CreateFamily(c *gin.Context) {
     // 从 JWT 中解析用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 检查用户是否已属于某个家庭
     var user models.User
     if err := fc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
     // TODO 改回来
     if user.FamilyID != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "User already belongs to a family"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("User already belongs to a family"))
         return
     }
 
     // 解析请求体
-    var request struct {
-        Name string `json:"name" binding:"required"`
-    }
+    var request CreateFamilyRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
@@ -79,25 +84,25 @@ func (fc *FamilyController) CreateFamily(c *gin.Context) {
 
     // 插入新家庭记录
     if err := fc.DB.Create(&family).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to create family"))
         return
     }
 
     // 将当前用户设置为家庭管理员
     if err := fc.DB.Model(&family).Association("Admins").Append(&user); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add user as admin"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to add user as admin"))
         return
     }
 
     // 将家庭 ID 绑定到用户
     user.FamilyID = &family.ID
     if err := fc.DB.Save(&user).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to associate user with family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to associate user with family"))
         return
     }
     // 手动刷新 user 对象
     if err := fc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh user data"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to refresh user data"))
         return
     }
 
@@ -127,14 +132,14 @@ func (fc *FamilyController) FamilyDetails(c *gin.Context) {
     // 从 JWT 中解析用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 查询用户信息并预加载家庭信息
     var user models.User
     if err := fc.DB.Preload("Family.Admins").Preload("Family.Members").Preload("Family.WaitingList").Preload("PendingFamily").First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
@@ -145,7 +150,7 @@ func (fc *FamilyController) FamilyDetails(c *gin.Context) {
     }
     loc, err := time.LoadLocation(timezone)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timezone"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid timezone"))
         return
     }
 
@@ -192,6 +197,17 @@ func (fc *FamilyController) FamilyDetails(c *gin.Context) {
         realMembers := append(user.Family.Members, user.Family.Admins...)
         memberDailyData := make([]gin.H, 0, len(realMembers))
 
+        // 其他成员的饮食数据按其隐私设置返回，未共享的数据不计入家庭汇总
+        memberIDs := make([]uint, len(realMembers))
+        for i, m := range realMembers {
+            memberIDs[i] = m.ID
+        }
+        privacy, err := models.GetPrivacySettingsMap(fc.DB, memberIDs)
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch privacy settings"))
+            return
+        }
+
         var (
             totalCarbonGoalSum   float64
             totalCarbonIntakeSum float64
@@ -205,6 +221,9 @@ func (fc *FamilyController) FamilyDetails(c *gin.Context) {
         )
 
         for _, m := range realMembers {
+            sharesCarbon := m.ID == user.ID || privacy[m.ID].SharesCarbon()
+            sharesNutrition := m.ID == user.ID || privacy[m.ID].SharesNutrition()
+
             var carbonGoal models.CarbonGoal
             if err := fc.DB.Where("user_id = ? AND DATE(date) = DATE(?)", m.ID, utcToday).First(&carbonGoal).Error; err != nil {
                 carbonGoal.Emission = 0
@@ -246,28 +265,38 @@ func (fc *FamilyController) FamilyDetails(c *gin.Context) {
                 "nickname":   m.Nickname,
                 "avatar_url": m.AvatarURL,
 
-                "carbon_goal_emission": truncateToOneDecimal(carbonGoal.Emission),
-                "carbon_intake_sum":    truncateToOneDecimal(carbonIntakeSum),
-
-                "nutrition_goal": gin.H{
+                "shares_carbon":    sharesCarbon,
+                "shares_nutrition": sharesNutrition,
+            }
+            if sharesCarbon {
+                singleMemberData["carbon_goal_emission"] = truncateToOneDecimal(carbonGoal.Emission)
+                singleMemberData["carbon_intake_sum"] = truncateToOneDecimal(carbonIntakeSum)
+            }
+            if sharesNutrition {
+                singleMemberData["nutrition_goal"] = gin.H{
                     "calories":      truncateToOneDecimal(nutritionGoal.Calories),
                     "protein":       truncateToOneDecimal(nutritionGoal.Protein),
                     "fat":           truncateToOneDecimal(nutritionGoal.Fat),
                     "carbohydrates": truncateToOneDecimal(nutritionGoal.Carbohydrates),
                     "sodium":        truncateToOneDecimal(nutritionGoal.Sodium),
-                },
-                "nutrition_intake_sum": gin.H{
+                }
+                singleMemberData["nutrition_intake_sum"] = gin.H{
                     "calories":      truncateToOneDecimal(niCals),
                     "protein":       truncateToOneDecimal(niProtein),
                     "fat":           truncateToOneDecimal(niFat),
                     "carbohydrates": truncateToOneDecimal(niCarbs),
                     "sodium":        truncateToOneDecimal(niSodium),
-                },
+                }
             }
             memberDailyData = append(memberDailyData, singleMemberData)
 
-            totalCarbonGoalSum += carbonGoal.Emission
-            totalCarbonIntakeSum += carbonIntakeSum
+            if sharesCarbon {
+                totalCarbonGoalSum += carbonGoal.Emission
+                totalCarbonIntakeSum += carbonIntakeSum
+            }
+            if !sharesNutrition {
+                continue
+            }
 
             totalNutritionGoal.Calories += nutritionGoal.Calories
             totalNutritionGoal.Protein += nutritionGoal.Protein
@@ -327,14 +356,14 @@ func (fc *FamilyController) FamilyDetails(c *gin.Context) {
 func (fc *FamilyController) SearchFamily(c *gin.Context) {
     _, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取 token 参数
     token := c.Query("family_id")
     if token == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Token is required"))
         return
     }
 
@@ -342,10 +371,10 @@ func (fc *FamilyController) SearchFamily(c *gin.Context) {
     var family models.Family
     if err := fc.DB.Where("token = ?", token).First(&family).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
-            c.JSON(http.StatusNotFound, gin.H{"error": "Family not found"})
+            apierror.Respond(c, apierror.ErrFamilyNotFound)
             return
         }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to search family"))
         return
     }
 
@@ -363,53 +392,53 @@ func (fc *FamilyController) JoinFamily(c *gin.Context) {
     // 从 JWT 中解析当前用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取家庭 ID
     familyID, err := strconv.Atoi(c.Param("id"))
     if err != nil || familyID <= 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid family ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid family ID"))
         return
     }
 
     // 检查家庭是否存在
     var family models.Family
     if err := fc.DB.First(&family, familyID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Family not found"})
+        apierror.Respond(c, apierror.ErrFamilyNotFound)
         return
     }
 
     // 获取当前用户
     var user models.User
     if err := fc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     // 检查是否已属于某个家庭
     if user.FamilyID != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are already a member of a family"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("You are already a member of a family"))
         return
     }
 
     // 检查是否已经申请加入其他家庭
     if user.PendingFamilyID != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You have already requested to join another family"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("You have already requested to join another family"))
         return
     }
 
     // 更新用户的 PendingFamilyID 字段
     user.PendingFamilyID = &family.ID
     if err := fc.DB.Save(&user).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update pending family ID"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update pending family ID"))
         return
     }
 
     // 将用户添加到家庭的等待列表
     if err := fc.DB.Model(&family).Association("WaitingList").Append(&user); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add user to the waiting list"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to add user to the waiting list"))
         return
     }
 
@@ -418,33 +447,36 @@ func (fc *FamilyController) JoinFamily(c *gin.Context) {
     })
 }
 
+// FamilyMemberRequest 家庭管理操作的请求体，指定操作的用户
+type FamilyMemberRequest struct {
+    UserID uint `json:"user_id" binding:"required"`
+}
+
 // 批准加入家庭
 func (fc *FamilyController) AdmitJoinFamily(c *gin.Context) {
     // 从 JWT 中解析当前用户 ID
     adminUserID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取被批准用户的 ID
-    var request struct {
-        UserID uint `json:"user_id" binding:"required"`
-    }
+    var request FamilyMemberRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     // 获取当前用户的 FamilyID
     var adminUser models.User
     if err := fc.DB.Preload("Family.Admins").First(&adminUser, adminUserID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve admin user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve admin user"))
         return
     }
 
     if adminUser.FamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not part of any family"})
+        apierror.Respond(c, apierror.ErrNotInFamily)
         return
     }
 
@@ -457,31 +489,31 @@ func (fc *FamilyController) AdmitJoinFamily(c *gin.Context) {
         }
     }
     if !isAdmin {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You are not an admin of this family"})
+        apierror.Respond(c, apierror.ErrNotFamilyAdmin)
         return
     }
 
     // 获取目标家庭
     var family models.Family
     if err := fc.DB.Preload("WaitingList").Preload("Members").First(&family, adminUser.FamilyID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve family"))
         return
     }
 
     // 检查被批准用户是否在家庭的等待列表中
     var user models.User
     if err := fc.DB.First(&user, request.UserID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if user.PendingFamilyID == nil || *user.PendingFamilyID != family.ID {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "User is not in the waiting list of your family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("User is not in the waiting list of your family"))
         return // TODO 错误处理
     }
 
     if user.FamilyID != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "User has been in a family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("User has been in a family"))
         return // TODO 错误处理
     }
 
@@ -489,7 +521,7 @@ func (fc *FamilyController) AdmitJoinFamily(c *gin.Context) {
     user.FamilyID = &family.ID
     user.PendingFamilyID = nil
     if err := fc.DB.Save(&user).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user's family information"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update user's family information"))
         return
     }
 
@@ -509,14 +541,14 @@ func (fc *FamilyController) AdmitJoinFamily(c *gin.Context) {
     })
 
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update family membership"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update family membership"))
         return
     }
 
     // 更新家庭成员计数
     family.MemberCount++
     if err := fc.DB.Save(&family).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update family member count"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update family member count"))
         return
     }
 
@@ -532,35 +564,33 @@ func (fc *FamilyController) RejectJoinFamily(c *gin.Context) {
     // 从 JWT 中解析当前用户 ID
     adminUserID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取被拒绝用户的 ID
-    var request struct {
-        UserID uint `json:"user_id" binding:"required"`
-    }
+    var request FamilyMemberRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     // 获取当前用户的 FamilyID
     var adminUser models.User
     if err := fc.DB.Preload("Family.Admins").First(&adminUser, adminUserID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve admin user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve admin user"))
         return
     }
 
     if adminUser.FamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not part of any family"})
+        apierror.Respond(c, apierror.ErrNotInFamily)
         return
     }
 
     // 获取目标家庭
     var family models.Family
     if err := fc.DB.Preload("WaitingList").First(&family, adminUser.FamilyID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve family"))
         return
     }
     
@@ -573,32 +603,32 @@ func (fc *FamilyController) RejectJoinFamily(c *gin.Context) {
         }
     }
     if !isAdmin {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You are not an admin of this family"})
+        apierror.Respond(c, apierror.ErrNotFamilyAdmin)
         return
     }
 
     // 检查被拒绝用户是否在家庭的等待列表中
     var user models.User
     if err := fc.DB.First(&user, request.UserID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if user.PendingFamilyID == nil || *user.PendingFamilyID != family.ID {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "User is not in the waiting list of your family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("User is not in the waiting list of your family"))
         return
     }
 
     // 从等待列表中移除用户
     if err := fc.DB.Model(&family).Association("WaitingList").Delete(&user); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove user from waiting list"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to remove user from waiting list"))
         return
     }
 
     // 清除用户的 PendingFamilyID 字段
     user.PendingFamilyID = nil
     if err := fc.DB.Save(&user).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user's pending family information"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update user's pending family information"))
         return
     }
 
@@ -614,40 +644,40 @@ func (fc *FamilyController) CancelJoinFamily(c *gin.Context) {
     // 从 JWT 中解析当前用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取当前用户
     var user models.User
     if err := fc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     // 检查用户是否有待处理的家庭申请
     if user.PendingFamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You have not requested to join any family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You have not requested to join any family"))
         return
     }
 
     // 获取用户申请的家庭
     var family models.Family
     if err := fc.DB.Preload("WaitingList").First(&family, *user.PendingFamilyID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve family"))
         return
     }
 
     // 从等待列表中移除用户
     if err := fc.DB.Model(&family).Association("WaitingList").Delete(&user); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove user from waiting list"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to remove user from waiting list"))
         return
     }
 
     // 清除用户的 PendingFamilyID 字段
     user.PendingFamilyID = nil
     if err := fc.DB.Save(&user).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user's pending family information"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update user's pending family information"))
         return
     }
 
@@ -662,24 +692,24 @@ func (fc *FamilyController) CancelJoinFamily(c *gin.Context) {
 func (fc *FamilyController) PendingFamilyDetails(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     var user models.User
     if err := fc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if user.PendingFamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You have not requested to join any family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You have not requested to join any family"))
         return
     }
 
     var family models.Family
     if err := fc.DB.First(&family, user.PendingFamilyID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve family"))
         return
     }
 
@@ -694,32 +724,30 @@ func (fc *FamilyController) PendingFamilyDetails(c *gin.Context) {
 func (fc *FamilyController) SetMember(c *gin.Context) {
     adminUserID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    var request struct {
-        UserID  uint `json:"user_id" binding:"required"`
-    }
+    var request FamilyMemberRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     var adminUser models.User
     if err := fc.DB.First(&adminUser, adminUserID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if adminUser.FamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not part of any family"})
+        apierror.Respond(c, apierror.ErrNotInFamily)
         return
     }
 
     var family models.Family
     if err := fc.DB.Preload("Admins").Preload("Members").First(&family, adminUser.FamilyID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve family"))
         return
     }
 
@@ -732,13 +760,13 @@ func (fc *FamilyController) SetMember(c *gin.Context) {
         }
     }
     if !isAdmin {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not an admin of this family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You are not an admin of this family"))
         return
     }
 
     var user models.User
     if err := fc.DB.First(&user, request.UserID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
@@ -751,19 +779,19 @@ func (fc *FamilyController) SetMember(c *gin.Context) {
         }
     }
     if !isTargetAdmin {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "The target user is not an admin of the family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("The target user is not an admin of the family"))
         return
     }
 
     // 检查用户是否是家庭成员
     if *user.FamilyID != family.ID {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "The user is not in your family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("The user is not in your family"))
         return
     }
 
     // 防止管理员对自身权限进行修改
     if user.ID == adminUserID {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change your own role"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You cannot change your own role"))
         return
     }
 
@@ -781,7 +809,7 @@ func (fc *FamilyController) SetMember(c *gin.Context) {
     })
 
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user role"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update user role"))
         return
     }
 
@@ -796,32 +824,30 @@ func (fc *FamilyController) SetMember(c *gin.Context) {
 func (fc *FamilyController) SetAdmin(c *gin.Context) {
     adminUserID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    var request struct {
-        UserID  uint `json:"user_id" binding:"required"`
-    }
+    var request FamilyMemberRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     var adminUser models.User
     if err := fc.DB.First(&adminUser, adminUserID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if adminUser.FamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not part of any family"})
+        apierror.Respond(c, apierror.ErrNotInFamily)
         return
     }
 
     var family models.Family
     if err := fc.DB.Preload("Admins").Preload("Members").First(&family, adminUser.FamilyID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve family"))
         return
     }
 
@@ -834,13 +860,13 @@ func (fc *FamilyController) SetAdmin(c *gin.Context) {
         }
     }
     if !isAdmin {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not an admin of this family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You are not an admin of this family"))
         return
     }
 
     var user models.User
     if err := fc.DB.First(&user, request.UserID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
@@ -853,19 +879,19 @@ func (fc *FamilyController) SetAdmin(c *gin.Context) {
         }
     }
     if isTargetAdmin {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "The target user is already an admin of the family"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("The target user is already an admin of the family"))
         return
     }
 
     // 检查用户是否是家庭成员
     if *user.FamilyID != family.ID {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "The user is not in your family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("The user is not in your family"))
         return
     }
 
     // 防止管理员对自身权限进行修改
     if user.ID == adminUserID {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change your own role"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You cannot change your own role"))
         return
     }
 
@@ -883,7 +909,7 @@ func (fc *FamilyController) SetAdmin(c *gin.Context) {
     })
 
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user role"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update user role"))
         return
     }
 
@@ -899,140 +925,144 @@ func (fc *FamilyController) SetAdmin(c *gin.Context) {
 func (fc *FamilyController) LeaveFamily(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     var user models.User
     if err := fc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if user.FamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not part of any family"})
+        apierror.Respond(c, apierror.ErrNotInFamily)
         return
     }
 
     var family models.Family
     if err := fc.DB.Preload("Admins").Preload("Members").First(&family, *user.FamilyID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve family"))
         return
     }
 
     // 开始事务
     if err := fc.DB.Transaction(func(tx *gorm.DB) error {
-        // 1. 删除用户提出的菜品（FamilyDish 表中 ProposerUserID 为该用户的记录）
-        if err := tx.Where("proposer_user_id = ?", user.ID).Delete(&models.FamilyDish{}).Error; err != nil {
-            return err
-        }
+        return removeFamilyMember(tx, &family, &user)
+    }); err != nil {
+        fmt.Println(err)
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to leave family"))
+        return
+    }
 
-        // 2. 更新菜品状态（删除孤立菜品）
-        var remainingDishes []models.FamilyDish
-        if err := tx.Where("family_id = ?", family.ID).Find(&remainingDishes).Error; err != nil {
+    c.JSON(http.StatusOK, gin.H{"message": "Successfully left the family"})
+}
+
+// removeFamilyMember 把用户移出家庭：删除其提出的菜品，最后一位管理员退出时随机指派成员为管理员，
+// 没有成员时解散家庭
+func removeFamilyMember(tx *gorm.DB, family *models.Family, user *models.User) error {
+    // 1. 删除用户提出的菜品（FamilyDish 表中 ProposerUserID 为该用户的记录）
+    if err := tx.Where("proposer_user_id = ?", user.ID).Delete(&models.FamilyDish{}).Error; err != nil {
+        return err
+    }
+
+    // 2. 更新菜品状态（删除孤立菜品）
+    var remainingDishes []models.FamilyDish
+    if err := tx.Where("family_id = ?", family.ID).Find(&remainingDishes).Error; err != nil {
+        return err
+    }
+    for _, dish := range remainingDishes {
+        var proposerCount int64
+        if err := tx.Model(&models.FamilyDish{}).
+            Where("family_id = ? AND dish_id = ?", dish.FamilyID, dish.DishID).
+            Count(&proposerCount).Error; err != nil {
             return err
         }
-        for _, dish := range remainingDishes {
-            var proposerCount int64
-            if err := tx.Model(&models.FamilyDish{}).
-                Where("family_id = ? AND dish_id = ?", dish.FamilyID, dish.DishID).
-                Count(&proposerCount).Error; err != nil {
+        // 删除没有提议者的菜品
+        if proposerCount == 0 {
+            if err := tx.Delete(&dish).Error; err != nil {
                 return err
             }
-            // 删除没有提议者的菜品
-            if proposerCount == 0 {
-                if err := tx.Delete(&dish).Error; err != nil {
-                    return err
-                }
-            }
         }
+    }
 
-        if err := tx.Model(&family).Association("Admins").Delete(&user); err != nil {
-            return err
-        }
+    if err := tx.Model(family).Association("Admins").Delete(user); err != nil {
+        return err
+    }
 
-        if err := tx.Model(&family).Association("Members").Delete(&user); err != nil {
-            return err
-        }
+    if err := tx.Model(family).Association("Members").Delete(user); err != nil {
+        return err
+    }
 
-        family.MemberCount--
-        if err := tx.Save(&family).Error; err != nil {
-            return err
-        }
+    family.MemberCount--
+    if err := tx.Save(family).Error; err != nil {
+        return err
+    }
 
-        user.FamilyID = nil
-        if err := tx.Save(&user).Error; err != nil {
+    user.FamilyID = nil
+    if err := tx.Save(user).Error; err != nil {
+        return err
+    }
+
+    adminCount := tx.Model(family).Association("Admins").Count()
+
+    if adminCount == 0 && family.MemberCount > 0 {
+        var members []models.User
+        if err := tx.Model(family).Association("Members").Find(&members); err != nil {
             return err
         }
 
-        adminCount := tx.Model(&family).Association("Admins").Count()
+        if len(members) > 0 {
+            // 随机指派用户为管理员
+            newAdmin := members[rand.Intn(len(members))]
 
-        if adminCount == 0 && family.MemberCount > 0 {
-            var members []models.User
-            if err := tx.Model(&family).Association("Members").Find(&members); err != nil {
+            if err := tx.Model(family).Association("Members").Delete(&newAdmin); err != nil {
                 return err
             }
 
-            if len(members) > 0 {
-                // 随机指派用户为管理员
-                newAdmin := members[rand.Intn(len(members))]
-
-                if err := tx.Model(&family).Association("Members").Delete(&newAdmin); err != nil {
-                    return err
-                }
-
-                if err := tx.Model(&family).Association("Admins").Append(&newAdmin); err != nil {
-                    return err
-                }
-            }
-        }
-
-        if family.MemberCount == 0 {
-            if err := tx.Delete(&family).Error; err != nil {
+            if err := tx.Model(family).Association("Admins").Append(&newAdmin); err != nil {
                 return err
             }
         }
+    }
 
-        return nil
-    }); err != nil {
-        fmt.Println(err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave family"})
-        return
+    if family.MemberCount == 0 {
+        if err := tx.Delete(family).Error; err != nil {
+            return err
+        }
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Successfully left the family"})
+    return nil
 }
 
 // 踢出家庭
 func (fc *FamilyController) DeleteFamilyMember(c *gin.Context) {
     adminUserID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    var request struct {
-        UserID uint `json:"user_id" binding:"required"`
-    }
+    var request FamilyMemberRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     var adminUser models.User
     if err := fc.DB.First(&adminUser, adminUserID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Admin user not found"})
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("Admin user not found"))
         return
     }
 
     if adminUser.FamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not part of any family"})
+        apierror.Respond(c, apierror.ErrNotInFamily)
         return
     }
 
     var family models.Family
     if err := fc.DB.Preload("Admins").Preload("Members").First(&family, *adminUser.FamilyID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve family"))
         return
     }
 
@@ -1044,23 +1074,23 @@ func (fc *FamilyController) DeleteFamilyMember(c *gin.Context) {
         }
     }
     if !isAdmin {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not an admin of this family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You are not an admin of this family"))
         return
     }
 
     var user models.User
     if err := fc.DB.First(&user, request.UserID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if user.FamilyID == nil || *user.FamilyID != family.ID {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "The user is not in your family"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("The user is not in your family"))
         return
     }
 
     if user.ID == adminUserID {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot remove yourself"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You cannot remove yourself"))
         return
     }
 
@@ -1108,7 +1138,7 @@ func (fc *FamilyController) DeleteFamilyMember(c *gin.Context) {
         user.FamilyID = nil
         return tx.Save(&user).Error
     }); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove user from family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to remove user from family"))
         return
     }
 
@@ -1119,18 +1149,18 @@ func (fc *FamilyController) DeleteFamilyMember(c *gin.Context) {
 func (fc *FamilyController) BreakFamily(c *gin.Context) {
     adminUserID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     var adminUser models.User
     if err := fc.DB.Preload("Family.Admins").Preload("Family.Members").First(&adminUser, adminUserID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if adminUser.Family == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not part of any family"})
+        apierror.Respond(c, apierror.ErrNotInFamily)
         return
     }
 
@@ -1146,7 +1176,7 @@ func (fc *FamilyController) BreakFamily(c *gin.Context) {
     }
 
     if !isAdmin {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to dissolve this family"})
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You are not authorized to dissolve this family"))
         return
     }
 
@@ -1179,7 +1209,7 @@ func (fc *FamilyController) BreakFamily(c *gin.Context) {
 
         return nil
     }); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dissolve the family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to dissolve the family"))
         return
     }
 
@@ -1189,46 +1219,47 @@ func (fc *FamilyController) BreakFamily(c *gin.Context) {
     })
 }
 
+// AddDesiredDishRequest 添加想吃的菜的请求体，level_of_desire 为 0 到 2
+type AddDesiredDishRequest struct {
+    DishID        uint  `json:"dish_id" binding:"required"`
+    LevelOfDesire *uint `json:"level_of_desire" binding:"required,oneof=0 1 2"`
+}
+
 // AddDesiredDish 处理添加想吃的菜请求
 func (fc *FamilyController) AddDesiredDish(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    type AddDesiredDishRequest struct {
-        DishID        uint  `json:"dish_id" binding:"required"`
-        LevelOfDesire *uint `json:"level_of_desire" binding:"required,oneof=0 1 2"`
-    }
-
     var request AddDesiredDishRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     var user models.User
     if err := fc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if user.FamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not part of any family"})
+        apierror.Respond(c, apierror.ErrNotInFamily)
         return
     }
 
     var family models.Family
     if err := fc.DB.Preload("Dishes").First(&family, *user.FamilyID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve family"))
         return
     }
 
     // 检查菜品是否已被该用户提出
     var existingFamilyDish models.FamilyDish
     if err := fc.DB.Where("family_id = ? AND dish_id = ? AND proposer_user_id = ?", family.ID, request.DishID, user.ID).First(&existingFamilyDish).Error; err == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You have already desired this dish"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("You have already desired this dish"))
         return
     }
 
@@ -1242,44 +1273,45 @@ func (fc *FamilyController) AddDesiredDish(c *gin.Context) {
     }
 
     if err := fc.DB.Create(&familyDish).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add desired dish"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to add desired dish"))
         return
     }
 
     c.JSON(http.StatusOK, gin.H{"message": "Desired dish added successfully"})
 }
 
+// GetDesiredDishesResponse 家庭想吃的菜
+type GetDesiredDishesResponse struct {
+    DishID        uint   `json:"dish_id"`
+    LevelOfDesire uint   `json:"level_of_desire"`
+    ProposerUser  models.User   `json:"proposer_user"`
+}
+
 // 获取所有想吃菜品，按想吃程度排序
 func (fc *FamilyController) GetDesiredDishes(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     var user models.User
     if err := fc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if user.FamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not part of any family"})
+        apierror.Respond(c, apierror.ErrNotInFamily)
         return
     }
 
     var family models.Family
     if err := fc.DB.Preload("Dishes.Proposer").First(&family, *user.FamilyID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve family"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to retrieve family"))
         return
     }
 
-    type GetDesiredDishesResponse struct {
-        DishID        uint   `json:"dish_id"`
-        LevelOfDesire uint   `json:"level_of_desire"`
-        ProposerUser  models.User   `json:"proposer_user"`
-    }
-
     var response []GetDesiredDishesResponse
     for _, fd := range family.Dishes {
         response = append(response, GetDesiredDishesResponse{
@@ -1297,47 +1329,48 @@ func (fc *FamilyController) GetDesiredDishes(c *gin.Context) {
     c.JSON(http.StatusOK, response)
 }
 
+// DeleteFamilyDishRequest 删除想吃的菜的请求体
+type DeleteFamilyDishRequest struct {
+    DishID uint `json:"dish_id" binding:"required"`
+}
+
 // 删除想吃菜品
 func (fc *FamilyController) DeleteDesiredDish(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    type DeleteFamilyDishRequest struct {
-        DishID uint `json:"dish_id" binding:"required"`
-    }
-
     var request DeleteFamilyDishRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     var user models.User
     if err := fc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     if user.FamilyID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You are not part of any family"})
+        apierror.Respond(c, apierror.ErrNotInFamily)
         return
     }
 
     var familyDish models.FamilyDish
     if err := fc.DB.Where("family_id = ? AND dish_id = ? AND proposer_user_id = ?", *user.FamilyID, request.DishID, user.ID).First(&familyDish).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
-            c.JSON(http.StatusNotFound, gin.H{"error": "Desired dish not found or not proposed by you"})
+            apierror.Respond(c, apierror.ErrNotFound.WithMessage("Desired dish not found or not proposed by you"))
         } else {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete desired dish"})
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to delete desired dish"))
         }
         return
     }
 
     if err := fc.DB.Delete(&familyDish).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete desired dish"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to delete desired dish"))
         return
     }
 
@@ -28,7 +28,7 @@ func setupFamilyTestDB() *gorm.DB {
 	}
 
 	// 迁移所有相关模型
-	err = db.AutoMigrate(&models.User{}, &models.Family{}, &models.News{}, &models.FamilyDish{})
+	err = db.AutoMigrate(&models.User{}, &models.Family{}, &models.News{}, &models.FamilyDish{}, &models.PrivacySettings{})
 	if err != nil {
 		panic("failed to migrate models")
 	}
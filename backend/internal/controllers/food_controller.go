@@ -7,6 +7,7 @@ import (
     "strconv"
     "github.com/gin-gonic/gin"
     "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
 	"fmt"
 )
@@ -38,17 +39,13 @@ func (fc *FoodController) GetFoodNames(c *gin.Context) {
     }
     
     if language != "zh" && language != "en" {
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error": "Invalid language parameter. Use 'zh' or 'en'",
-        })
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid language parameter. Use 'zh' or 'en'"))
         return
     }
     
     names, err := models.GetAllFoodNames(fc.DB, language)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{
-            "error": err.Error(),
-        })
+        apierror.Respond(c, apierror.ErrInternal.WithMessage(err.Error()))
         return
     }
     c.JSON(http.StatusOK, names)
@@ -70,9 +67,7 @@ func (fc *FoodController) CalculateNutritionAndEmission(c *gin.Context) {
     // 绑定请求数据
     if err := c.ShouldBindJSON(&items); err != nil {
         log.Printf("Invalid request format: " + err.Error())
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error": "Invalid request format: " + err.Error(),
-        })
+        apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("Invalid request format: " + err.Error()))
         return
     }
     log.Printf("绑定请求数据成功")
@@ -81,16 +76,12 @@ func (fc *FoodController) CalculateNutritionAndEmission(c *gin.Context) {
     for _, item := range items {
         if item.Weight <= 0 {
             log.Printf("Invalid weight for food ID " + strconv.Itoa(int(item.ID)) + ": weight must be positive")
-            c.JSON(http.StatusBadRequest, gin.H{
-                "error": fmt.Sprintf("Invalid weight for food ID %d: weight must be positive", item.ID),
-            })
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage(fmt.Sprintf("Invalid weight for food ID %d: weight must be positive", item.ID)))
             return
         }
         if item.Price <= 0 {
             log.Printf("Invalid price for food ID " + strconv.Itoa(int(item.ID)) + ": price must be positive")
-            c.JSON(http.StatusBadRequest, gin.H{
-                "error": fmt.Sprintf("Invalid price for food ID %d: price must be positive", item.ID),
-            })
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage(fmt.Sprintf("Invalid price for food ID %d: price must be positive", item.ID)))
             return
         }
     }
@@ -100,9 +91,7 @@ func (fc *FoodController) CalculateNutritionAndEmission(c *gin.Context) {
     results, err := models.CalculateFoodNutritionAndEmission(fc.DB, items)
     if err != nil {
         log.Printf("Error calculating nutrition and emission: " + err.Error())
-        c.JSON(http.StatusInternalServerError, gin.H{
-            "error": err.Error(),
-        })
+        apierror.Respond(c, apierror.ErrInternal.WithMessage(err.Error()))
         return
     }
     log.Printf("计算结果成功")
@@ -10,6 +10,7 @@ import (
 
     "github.com/gin-gonic/gin"
     "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
 
     "log"
@@ -51,30 +52,33 @@ func validatePreference(preferenceName string) bool {
     return exists
 }
 
+// FoodPreferenceRequest 添加或删除食材偏好的请求体
+type FoodPreferenceRequest struct {
+    PreferenceName string `json:"preference_name" binding:"required"`
+}
+
 // AddFoodPreference 添加食物偏好
 func (fpc *FoodPreferenceController) AddFoodPreference(c *gin.Context) {
 
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    var request struct {
-        PreferenceName string `json:"preference_name" binding:"required"`
-    }
+    var request FoodPreferenceRequest
 
     if err := c.ShouldBindJSON(&request); err != nil {
         // log详细的错误信息
         log.Printf("错误信息: %v\n", err)
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     // 验证偏好是否存在 
     if !validatePreference(request.PreferenceName) {
         log.Printf("偏好不存在: %s\n", request.PreferenceName)
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preference name"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid preference name"))
         return
     }
 
@@ -82,7 +86,7 @@ func (fpc *FoodPreferenceController) AddFoodPreference(c *gin.Context) {
     var existingPreference models.FoodPreference
     result := fpc.DB.Where("user_id = ? AND name = ?", userID, request.PreferenceName).First(&existingPreference)
     if result.Error == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Preference already exists"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("Preference already exists"))
         return
     }
 
@@ -93,7 +97,7 @@ func (fpc *FoodPreferenceController) AddFoodPreference(c *gin.Context) {
     }
 
     if err := fpc.DB.Create(&preference).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add preference"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to add preference"))
         return
     }
 
@@ -107,28 +111,26 @@ func (fpc *FoodPreferenceController) AddFoodPreference(c *gin.Context) {
 func (fpc *FoodPreferenceController) DeleteFoodPreference(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    var request struct {
-        PreferenceName string `json:"preference_name" binding:"required"`
-    }
+    var request FoodPreferenceRequest
 
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     // 验证偏好是否存在
     if !validatePreference(request.PreferenceName) {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preference name"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid preference name"))
         return
     }
 
     result := fpc.DB.Where("user_id = ? AND name = ?", userID, request.PreferenceName).Delete(&models.FoodPreference{})
     if result.RowsAffected == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Preference not found"})
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("Preference not found"))
         return
     }
 
@@ -142,13 +144,13 @@ func (fpc *FoodPreferenceController) GetUserPreferences(c *gin.Context) {
     // 从上下文获取用户ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     var preferences []models.FoodPreference
     if err := fpc.DB.Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user preferences"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch user preferences"))
         return
     }
 
@@ -163,22 +165,25 @@ func (fpc *FoodPreferenceController) GetUserPreferences(c *gin.Context) {
     c.JSON(http.StatusOK, response)
 }
 
+// DislikedFoodPreferenceRequest 添加或删除不喜欢的食材的请求体
+type DislikedFoodPreferenceRequest struct {
+    FoodID uint `json:"food_id" binding:"required"`
+}
+
 // AddDislikedFoodPreference 添加不喜欢的食材偏好
 func (fpc *FoodPreferenceController) AddDislikedFoodPreference(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
         log.Println("未授权的访问尝试")
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    var request struct {
-        FoodID uint `json:"food_id" binding:"required"`
-    }
+    var request DislikedFoodPreferenceRequest
 
     if err := c.ShouldBindJSON(&request); err != nil {
         log.Printf("无效的请求体: %v", err)
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
@@ -186,7 +191,7 @@ func (fpc *FoodPreferenceController) AddDislikedFoodPreference(c *gin.Context) {
     var food models.Food
     if err := fpc.DB.First(&food, request.FoodID).Error; err != nil {
         log.Printf("添加不喜欢的食材偏好失败: %v", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add disliked preference"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to add disliked preference"))
         return
     }
 
@@ -195,7 +200,7 @@ func (fpc *FoodPreferenceController) AddDislikedFoodPreference(c *gin.Context) {
     result := fpc.DB.Where("user_id = ? AND food_id = ?", userID, request.FoodID).First(&existingPreference)
     if result.Error == nil {
         log.Printf("用户 %v 已存在对食材 %v 的不喜欢偏好", userID, request.FoodID)
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Disliked preference already exists"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("Disliked preference already exists"))
         return
     }
 
@@ -207,7 +212,7 @@ func (fpc *FoodPreferenceController) AddDislikedFoodPreference(c *gin.Context) {
 
     if err := fpc.DB.Create(&preference).Error; err != nil {
         log.Printf("创建不喜欢的食材偏好失败: %v", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add disliked preference"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to add disliked preference"))
         return
     }
 
@@ -223,24 +228,22 @@ func (fpc *FoodPreferenceController) DeleteDislikedFoodPreference(c *gin.Context
     userID, exists := c.Get("user_id")
     if !exists {
         log.Println("未授权的访问尝试")
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    var request struct {
-        FoodID uint `json:"food_id" binding:"required"`
-    }
+    var request DislikedFoodPreferenceRequest
 
     if err := c.ShouldBindJSON(&request); err != nil {
         log.Printf("无效的请求体: %v", err)
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     result := fpc.DB.Where("user_id = ? AND food_id = ?", userID, request.FoodID).Delete(&models.DislikedFoodPreference{})
     if result.RowsAffected == 0 {
         log.Printf("未找到用户 %v 对食材 %v 的不喜欢偏好", userID, request.FoodID)
-        c.JSON(http.StatusNotFound, gin.H{"error": "Disliked preference not found"})
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("Disliked preference not found"))
         return
     }
 
@@ -256,14 +259,14 @@ func (fpc *FoodPreferenceController) GetUserDislikedPreferences(c *gin.Context)
     userID, exists := c.Get("user_id")
     if !exists {
         log.Println("未授权的访问尝试")
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     var preferences []models.DislikedFoodPreference
     if err := fpc.DB.Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
         log.Printf("获取用户 %v 的不喜欢食材偏好失败: %v", userID, err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user disliked preferences"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch user disliked preferences"))
         return
     }
 
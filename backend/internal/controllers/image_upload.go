@@ -0,0 +1,172 @@
+// internal/controllers/image_upload.go
+package controllers
+
+import (
+    "context"
+    "errors"
+    "io"
+    "mime/multipart"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/imaging"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+)
+
+// imageStoragePrefix 处理后图片的存放目录：images/<类型>/<哈希前两位>/<哈希>/<文件名>
+func imageStoragePrefix(kind, hash string) string {
+    return filepath.ToSlash(filepath.Join("images", kind, hash[:2], hash))
+}
+
+// isProcessedImage 判断相对路径是否指向经过处理的图片
+func isProcessedImage(path string) bool {
+    return strings.HasPrefix(filepath.ToSlash(path), "images/")
+}
+
+// storeImage 校验并处理上传的图片，按内容哈希去重后保存所有版本，返回图片记录以及是否复用了已有图片
+func storeImage(ctx context.Context, db *gorm.DB, file *multipart.FileHeader, profile imaging.Profile, uploaderID uint) (*models.UploadedImage, bool, error) {
+    if file.Size > imaging.MaxFileSize {
+        return nil, false, imaging.ErrTooLarge
+    }
+
+    src, err := file.Open()
+    if err != nil {
+        return nil, false, err
+    }
+    defer src.Close()
+
+    data, err := io.ReadAll(io.LimitReader(src, imaging.MaxFileSize+1))
+    if err != nil {
+        return nil, false, err
+    }
+
+    // 相同内容已处理过时直接复用
+    hash := imaging.ContentHash(data)
+    var existing models.UploadedImage
+    if err := db.Where("hash = ? AND kind = ?", hash, profile.Kind).First(&existing).Error; err == nil {
+        // 刷新使用时间，避免刚被复用的图片被垃圾回收
+        if err := db.Model(&existing).UpdateColumn("updated_at", time.Now()).Error; err != nil {
+            return nil, false, err
+        }
+        return &existing, true, nil
+    } else if !errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, false, err
+    }
+
+    result, err := imaging.Process(data, profile)
+    if err != nil {
+        return nil, false, err
+    }
+
+    store, err := storage.Default()
+    if err != nil {
+        return nil, false, err
+    }
+
+    prefix := imageStoragePrefix(profile.Kind, result.Hash)
+    written := make(map[string]bool)
+    variants := make([]models.ImageVariant, 0, len(result.Variants))
+    for _, v := range result.Variants {
+        relativePath := prefix + "/" + v.File
+        if !written[v.File] {
+            if err := store.Put(ctx, relativePath, v.Data, storage.ContentTypeOf(v.File)); err != nil {
+                return nil, false, err
+            }
+            written[v.File] = true
+        }
+        variants = append(variants, models.ImageVariant{
+            Name:   v.Name,
+            Format: v.Format,
+            URL:    relativePath,
+            Width:  v.Width,
+            Height: v.Height,
+        })
+    }
+
+    canonical := result.Canonical(profile)
+    record := models.UploadedImage{
+        Hash:       result.Hash,
+        Kind:       profile.Kind,
+        UploaderID: uploaderID,
+        Format:     result.Format,
+        Width:      result.Width,
+        Height:     result.Height,
+        Path:       prefix + "/" + canonical.File,
+    }
+    if err := record.SetVariants(variants); err != nil {
+        return nil, false, err
+    }
+
+    if err := db.Create(&record).Error; err != nil {
+        // 并发上传相同内容时唯一索引冲突，使用已写入的记录
+        if lookupErr := db.Where("hash = ? AND kind = ?", hash, profile.Kind).First(&existing).Error; lookupErr == nil {
+            return &existing, true, nil
+        }
+        return nil, false, err
+    }
+    return &record, false, nil
+}
+
+// deleteUploadedFile 删除存储中的上传文件。经过处理的图片按内容去重、可能被多处引用，不在这里删除
+func deleteUploadedFile(ctx context.Context, key string) error {
+    if key == "" || isProcessedImage(key) {
+        return nil
+    }
+    store, err := storage.Default()
+    if err != nil {
+        return err
+    }
+    return store.Delete(ctx, key)
+}
+
+// respondImageError 将 storeImage 的错误写入响应：图片本身的问题返回 4xx 及具体原因，其余返回 500 和 fallback 信息
+func respondImageError(c *gin.Context, err error, fallback string) {
+    switch {
+    case errors.Is(err, imaging.ErrTooLarge):
+        apierror.Respond(c, apierror.ErrPayloadTooLarge.WithMessage(err.Error()))
+    case errors.Is(err, imaging.ErrEmpty),
+        errors.Is(err, imaging.ErrUnsupportedFormat),
+        errors.Is(err, imaging.ErrCorrupt),
+        errors.Is(err, imaging.ErrDimensions):
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage(err.Error()))
+    default:
+        apierror.Respond(c, apierror.ErrInternal.WithMessage(fallback))
+    }
+}
+
+// newsImageVariants 查询新闻图片和头像的各尺寸版本，并填入 images 的 Variants 字段
+func newsImageVariants(db *gorm.DB, images []models.NewsImage, avatarURL string) (map[string][]models.ImageVariant, error) {
+    paths := []string{avatarURL}
+    for _, img := range images {
+        paths = append(paths, img.URL)
+    }
+    variants, err := models.ImageVariantsByPath(db, paths)
+    if err != nil {
+        return nil, err
+    }
+    for i := range images {
+        images[i].Variants = variants[images[i].URL]
+    }
+    return variants, nil
+}
+
+// draftImageVariants 查询草稿图片和头像的各尺寸版本，并填入 images 的 Variants 字段
+func draftImageVariants(db *gorm.DB, images []models.DraftImage, avatarURL string) (map[string][]models.ImageVariant, error) {
+    paths := []string{avatarURL}
+    for _, img := range images {
+        paths = append(paths, img.URL)
+    }
+    variants, err := models.ImageVariantsByPath(db, paths)
+    if err != nil {
+        return nil, err
+    }
+    for i := range images {
+        images[i].Variants = variants[images[i].URL]
+    }
+    return variants, nil
+}
@@ -0,0 +1,152 @@
+// internal/controllers/image_upload_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "image"
+    "image/color"
+    "image/png"
+    "mime/multipart"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage/s3test"
+)
+
+// testImagePNG 生成一张不透明的渐变 PNG
+func testImagePNG(t *testing.T, width, height int) []byte {
+    img := image.NewRGBA(image.Rect(0, 0, width, height))
+    for y := 0; y < height; y++ {
+        for x := 0; x < width; x++ {
+            img.Set(x, y, color.RGBA{uint8(x * 255 / width), uint8(y * 255 / height), 128, 255})
+        }
+    }
+    var buf bytes.Buffer
+    if err := png.Encode(&buf, img); err != nil {
+        t.Fatalf("encode png: %v", err)
+    }
+    return buf.Bytes()
+}
+
+// uploadNewsImage 通过接口上传新闻图片
+func uploadNewsImage(t *testing.T, router http.Handler, userID uint, data []byte) (int, map[string]interface{}) {
+    body := &bytes.Buffer{}
+    writer := multipart.NewWriter(body)
+    part, _ := writer.CreateFormFile("image", "photo.png")
+    _, _ = part.Write(data)
+    writer.Close()
+
+    req, _ := http.NewRequest("POST", "/news/upload_image", body)
+    req.Header.Set("Content-Type", writer.FormDataContentType())
+    req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+
+    var resp map[string]interface{}
+    assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+    return w.Code, resp
+}
+
+func TestUploadImageDeduplicates(t *testing.T) {
+    db := setupNewsTestDB()
+    router := setupNewsRouter(db)
+    baseDir := t.TempDir()
+    t.Setenv("BASE_UPLOAD_PATH", baseDir)
+
+    user := models.User{OpenID: "OpenID_Dedup_User", Nickname: "DedupUser"}
+    db.Create(&user)
+
+    data := testImagePNG(t, 1200, 900)
+    status, first := uploadNewsImage(t, router, user.ID, data)
+    assert.Equal(t, http.StatusOK, status)
+    assert.Equal(t, false, first["deduplicated"])
+    assert.Equal(t, float64(1200), first["width"])
+    assert.Equal(t, float64(900), first["height"])
+
+    // 所有版本都已写入磁盘，原图小于 large 尺寸时 large 与 medium 共用文件
+    variants := first["variants"].([]interface{})
+    names := map[string]bool{}
+    for _, v := range variants {
+        variant := v.(map[string]interface{})
+        names[fmt.Sprintf("%s.%s", variant["name"], variant["format"])] = true
+        _, err := os.Stat(filepath.Join(baseDir, variant["url"].(string)))
+        assert.NoError(t, err)
+        assert.LessOrEqual(t, variant["width"].(float64), float64(1200))
+    }
+    // 每种尺寸都有 JPEG 和 WebP 两个版本
+    for _, name := range []string{"thumb", "small", "medium", "large"} {
+        assert.True(t, names[name+".jpeg"], name)
+        assert.True(t, names[name+".webp"], name)
+    }
+
+    // 相同内容再次上传时复用已有图片
+    status, second := uploadNewsImage(t, router, user.ID, data)
+    assert.Equal(t, http.StatusOK, status)
+    assert.Equal(t, true, second["deduplicated"])
+    assert.Equal(t, first["path"], second["path"])
+
+    var count int64
+    db.Model(&models.UploadedImage{}).Count(&count)
+    assert.Equal(t, int64(1), count)
+
+    // 新闻详情中的图片附带各尺寸版本
+    news := models.News{
+        Title:    "With Image",
+        AuthorID: user.ID,
+        Images:   []models.NewsImage{{URL: first["path"].(string), Description: "photo"}, {URL: "drafts/1/legacy.jpg"}},
+    }
+    db.AutoMigrate(&models.News{}, &models.Comment{})
+    db.Create(&news)
+
+    req, _ := http.NewRequest("GET", fmt.Sprintf("/news/details/news/%d", news.ID), nil)
+    req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(user.ID))
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var detail NewsDetailResponse
+    assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+    if assert.Len(t, detail.Images, 2) {
+        assert.Len(t, detail.Images[0].Variants, len(variants))
+        assert.Empty(t, detail.Images[1].Variants) // 未经处理的旧图片没有版本
+    }
+    if assert.Len(t, detail.Blocks, 2) {
+        assert.Len(t, detail.Blocks[0].Variants, len(variants))
+    }
+}
+
+func TestUploadImageToS3(t *testing.T) {
+    server := s3test.NewServer("uploads")
+    defer server.Close()
+    cfg := server.Config()
+    t.Setenv("STORAGE_BACKEND", "s3")
+    t.Setenv("S3_ENDPOINT", cfg.Endpoint)
+    t.Setenv("S3_BUCKET", cfg.Bucket)
+    t.Setenv("S3_ACCESS_KEY_ID", cfg.AccessKeyID)
+    t.Setenv("S3_SECRET_ACCESS_KEY", cfg.SecretAccessKey)
+
+    db := setupNewsTestDB()
+    router := setupNewsRouter(db)
+    user := models.User{OpenID: "OpenID_S3_User", Nickname: "S3User"}
+    db.Create(&user)
+
+    status, resp := uploadNewsImage(t, router, user.ID, testImagePNG(t, 200, 100))
+    assert.Equal(t, http.StatusOK, status)
+
+    // 所有版本都写入了对象存储
+    for _, v := range resp["variants"].([]interface{}) {
+        variant := v.(map[string]interface{})
+        data, contentType, ok := server.Object(variant["url"].(string))
+        assert.True(t, ok, variant["url"])
+        assert.NotEmpty(t, data)
+        assert.Equal(t, "image/"+variant["format"].(string), contentType)
+    }
+}
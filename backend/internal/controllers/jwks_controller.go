@@ -0,0 +1,28 @@
+// internal/controllers/jwks_controller.go
+package controllers
+
+import (
+    "fmt"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/keyring"
+)
+
+// jwksCacheMaxAge JWKS 的缓存时间（秒），轮换后其他服务最晚在此时间后获取到新公钥
+const jwksCacheMaxAge = 300
+
+// JWKSController 公开 JWT 验证公钥，供其他服务验证本服务签发的令牌
+type JWKSController struct {
+    Keyring *keyring.Keyring
+}
+
+func NewJWKSController(keys *keyring.Keyring) *JWKSController {
+    return &JWKSController{Keyring: keys}
+}
+
+// GetJWKS 返回仍可用于验证的 RS256 / EdDSA 公钥；只使用 HS256 时列表为空
+func (jc *JWKSController) GetJWKS(c *gin.Context) {
+    c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", jwksCacheMaxAge))
+    c.JSON(http.StatusOK, jc.Keyring.JWKS())
+}
@@ -0,0 +1,248 @@
+// internal/controllers/news_block_controller.go
+package controllers
+
+import (
+    "errors"
+    "fmt"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+// EmbeddedRecipe 正文中嵌入的食谱卡片
+type EmbeddedRecipe struct {
+    ID       uint    `json:"id"`
+    Name     string  `json:"name"`
+    URL      string  `json:"url"`
+    ImageURL string  `json:"image_url"`
+    Category string  `json:"category"`
+    Emission float64 `json:"emission"` // 按当前食物数据计算的碳排放
+}
+
+// EmbeddedFood 正文中嵌入的食物
+type EmbeddedFood struct {
+    ID         uint    `json:"id"`
+    ZhFoodName string  `json:"zh_food_name"`
+    EnFoodName string  `json:"en_food_name"`
+    ImageURL   string  `json:"image_url"`
+    Weight     float64 `json:"weight"`   // 单位：kg
+    Emission   float64 `json:"emission"` // 按当前食物数据计算的碳排放
+}
+
+// ContentBlockResponse 定义返回给客户端的正文块，嵌入的食谱和食物附带实时数据
+type ContentBlockResponse struct {
+    models.ContentBlock
+    Recipe      *EmbeddedRecipe       `json:"recipe,omitempty"`
+    Food        *EmbeddedFood         `json:"food,omitempty"`
+    Variants    []models.ImageVariant `json:"variants,omitempty"`    // 图片块的各尺寸版本
+    Unavailable bool                  `json:"unavailable,omitempty"` // 嵌入的食谱或食物已被删除
+}
+
+// resolveContent 根据请求生成正文内容：提供 blocks 时以其为准并校验，否则由旧的段落和图片字段生成正文块
+func (nc *NewsController) resolveContent(blocks []models.ContentBlock, paragraphs, imageDescriptions, imagePaths []string) (models.RevisionContent, error) {
+    if blocks == nil {
+        content := models.RevisionContent{
+            Paragraphs: paragraphs,
+            Images:     make([]models.RevisionImage, 0, len(imagePaths)),
+        }
+        for i, path := range imagePaths {
+            content.Images = append(content.Images, models.RevisionImage{URL: path, Description: imageDescriptions[i]})
+        }
+        return content.WithBlocks(), nil
+    }
+
+    normalized, err := models.NormalizeBlocks(blocks)
+    if err != nil {
+        return models.RevisionContent{}, err
+    }
+    if err := nc.checkEmbeddedRefs(normalized); err != nil {
+        return models.RevisionContent{}, err
+    }
+    return models.ContentFromBlocks(normalized), nil
+}
+
+// respondContentError 将 resolveContent 的错误写入响应：校验失败返回 400，其余返回 500
+func respondContentError(c *gin.Context, err error) {
+    var blockErr *models.BlockError
+    if errors.As(err, &blockErr) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage(blockErr.Error()))
+        return
+    }
+    apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to validate content blocks"))
+}
+
+// checkEmbeddedRefs 检查正文块引用的食谱和食物是否存在
+func (nc *NewsController) checkEmbeddedRefs(blocks []models.ContentBlock) error {
+    var recipeIDs, foodIDs []uint
+    for _, b := range blocks {
+        switch b.Type {
+        case models.BlockRecipe:
+            recipeIDs = append(recipeIDs, *b.RecipeID)
+        case models.BlockFood:
+            foodIDs = append(foodIDs, *b.FoodID)
+        }
+    }
+
+    recipes := map[uint]bool{}
+    if len(recipeIDs) > 0 {
+        var found []uint
+        if err := nc.DB.Model(&models.Recipe{}).Where("id IN ?", recipeIDs).Pluck("id", &found).Error; err != nil {
+            return err
+        }
+        for _, id := range found {
+            recipes[id] = true
+        }
+    }
+
+    foods := map[uint]bool{}
+    if len(foodIDs) > 0 {
+        var found []uint
+        if err := nc.DB.Model(&models.Food{}).Where("id IN ?", foodIDs).Pluck("id", &found).Error; err != nil {
+            return err
+        }
+        for _, id := range found {
+            foods[id] = true
+        }
+    }
+
+    for i, b := range blocks {
+        if b.Type == models.BlockRecipe && !recipes[*b.RecipeID] {
+            return &models.BlockError{Index: i, Reason: fmt.Sprintf("recipe %d not found", *b.RecipeID)}
+        }
+        if b.Type == models.BlockFood && !foods[*b.FoodID] {
+            return &models.BlockError{Index: i, Reason: fmt.Sprintf("food %d not found", *b.FoodID)}
+        }
+    }
+    return nil
+}
+
+// draftRowsOf 将正文内容转换为草稿的段落、图片和正文块记录
+func draftRowsOf(content models.RevisionContent) ([]models.DraftParagraph, []models.DraftImage, []models.DraftBlock) {
+    paragraphs := make([]models.DraftParagraph, 0, len(content.Paragraphs))
+    for _, text := range content.Paragraphs {
+        paragraphs = append(paragraphs, models.DraftParagraph{Text: text})
+    }
+    images := make([]models.DraftImage, 0, len(content.Images))
+    for _, img := range content.Images {
+        images = append(images, models.DraftImage{URL: img.URL, Description: img.Description})
+    }
+    return paragraphs, images, models.DraftBlocksOf(content.Blocks)
+}
+
+// newsRowsOf 将正文内容转换为新闻的段落、图片和正文块记录
+func newsRowsOf(content models.RevisionContent) ([]models.Paragraph, []models.NewsImage, []models.NewsBlock) {
+    paragraphs := make([]models.Paragraph, 0, len(content.Paragraphs))
+    for _, text := range content.Paragraphs {
+        paragraphs = append(paragraphs, models.Paragraph{Text: text})
+    }
+    images := make([]models.NewsImage, 0, len(content.Images))
+    for _, img := range content.Images {
+        images = append(images, models.NewsImage{URL: img.URL, Description: img.Description})
+    }
+    return paragraphs, images, models.NewsBlocksOf(content.Blocks)
+}
+
+// renderBlocks 为正文块附加嵌入食谱和食物的实时信息（名称、图片、碳排放）以及图片块的各尺寸版本
+func (nc *NewsController) renderBlocks(blocks []models.ContentBlock) ([]ContentBlockResponse, error) {
+    var recipeIDs, foodIDs []uint
+    var imagePaths []string
+    for _, b := range blocks {
+        if b.Type == models.BlockImage {
+            imagePaths = append(imagePaths, b.URL)
+        }
+        if b.RecipeID != nil {
+            recipeIDs = append(recipeIDs, *b.RecipeID)
+        }
+        if b.FoodID != nil {
+            foodIDs = append(foodIDs, *b.FoodID)
+        }
+    }
+
+    recipes := map[uint]*models.Recipe{}
+    if len(recipeIDs) > 0 {
+        var list []models.Recipe
+        if err := nc.DB.Preload("Foods").Where("id IN ?", recipeIDs).Find(&list).Error; err != nil {
+            return nil, err
+        }
+        for i := range list {
+            recipes[list[i].ID] = &list[i]
+        }
+    }
+
+    foods := map[uint]*models.Food{}
+    if len(foodIDs) > 0 {
+        var list []models.Food
+        if err := nc.DB.Where("id IN ?", foodIDs).Find(&list).Error; err != nil {
+            return nil, err
+        }
+        for i := range list {
+            foods[list[i].ID] = &list[i]
+        }
+    }
+
+    variants, err := models.ImageVariantsByPath(nc.DB, imagePaths)
+    if err != nil {
+        return nil, err
+    }
+
+    responses := make([]ContentBlockResponse, 0, len(blocks))
+    for _, b := range blocks {
+        resp := ContentBlockResponse{ContentBlock: b}
+        switch b.Type {
+        case models.BlockImage:
+            resp.Variants = variants[b.URL]
+        case models.BlockRecipe:
+            if recipe, ok := recipes[*b.RecipeID]; ok {
+                resp.Recipe = &EmbeddedRecipe{
+                    ID:       recipe.ID,
+                    Name:     recipe.Name,
+                    URL:      recipe.URL,
+                    ImageURL: recipe.ImageURL,
+                    Category: recipe.Category,
+                    Emission: models.RecipeEmission(recipe),
+                }
+            } else {
+                resp.Unavailable = true
+            }
+        case models.BlockFood:
+            if food, ok := foods[*b.FoodID]; ok {
+                resp.Food = &EmbeddedFood{
+                    ID:         food.ID,
+                    ZhFoodName: food.ZhFoodName,
+                    EnFoodName: food.EnFoodName,
+                    ImageURL:   food.ImageUrl,
+                    Weight:     b.Weight,
+                    Emission:   models.FoodEmission(food, b.Weight),
+                }
+            } else {
+                resp.Unavailable = true
+            }
+        }
+        responses = append(responses, resp)
+    }
+    return responses, nil
+}
+
+// blockLines 将正文块转为逐行比较的文本
+func blockLines(blocks []models.ContentBlock) []string {
+    lines := make([]string, 0, len(blocks))
+    for _, b := range blocks {
+        switch b.Type {
+        case models.BlockHeading:
+            lines = append(lines, b.Type+":"+strconv.Itoa(b.Level)+" "+b.Text)
+        case models.BlockQuote:
+            lines = append(lines, b.Type+" "+b.Text+" — "+b.Source)
+        case models.BlockImage:
+            lines = append(lines, b.Type+" "+b.URL+" "+b.Description)
+        case models.BlockRecipe:
+            lines = append(lines, b.Type+" "+strconv.FormatUint(uint64(*b.RecipeID), 10))
+        case models.BlockFood:
+            lines = append(lines, b.Type+" "+strconv.FormatUint(uint64(*b.FoodID), 10)+" "+strconv.FormatFloat(b.Weight, 'f', -1, 64)+"kg")
+        default:
+            lines = append(lines, b.Type+" "+b.Text)
+        }
+    }
+    return lines
+}
@@ -0,0 +1,216 @@
+// controllers/news_block_controller_test.go
+package controllers
+
+import (
+    "fmt"
+    "net/http"
+    "testing"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+// setupNewsBlockTestDB 初始化包含食物和食谱的测试数据库
+func setupNewsBlockTestDB() (*gorm.DB, models.Food, models.Recipe) {
+    db := setupNewsTestDB()
+    _ = db.AutoMigrate(&models.News{}, &models.Food{}, &models.Recipe{})
+
+    beef := models.Food{ZhFoodName: "牛肉", EnFoodName: "Beef", GHG: 60}
+    db.Create(&beef)
+    rice := models.Food{ZhFoodName: "大米", EnFoodName: "Rice", GHG: 4}
+    db.Create(&rice)
+
+    recipe := models.Recipe{
+        URL:         "https://example.com/beef-rice",
+        Name:        "Beef Rice",
+        Ingredients: `{"beef": 200, "rice": 500}`,
+        Foods:       []models.Food{beef, rice},
+    }
+    db.Create(&recipe)
+    return db, beef, recipe
+}
+
+func TestCreateDraftWithBlocks(t *testing.T) {
+    db, beef, recipe := setupNewsBlockTestDB()
+    router := setupNewsRouter(db)
+
+    user := models.User{OpenID: "OpenID_Blocks_User", Nickname: "BlocksUser"}
+    db.Create(&user)
+
+    blocks := []gin.H{
+        {"type": "heading", "text": "  Eat less beef  "},
+        {"type": "text", "text": "Beef has a large footprint."},
+        {"type": "image", "url": "drafts/1/beef.jpg", "description": "A steak"},
+        {"type": "food", "food_id": beef.ID, "weight": 0.5},
+        {"type": "quote", "text": "Less is more.", "source": "Someone"},
+        {"type": "recipe", "recipe_id": recipe.ID},
+    }
+
+    status, resp := doRevisionRequest(router, "POST", "/news/create_draft", user.ID, gin.H{
+        "title":  "Blocks Draft",
+        "blocks": blocks,
+    })
+    assert.Equal(t, http.StatusCreated, status)
+    draftID := uint(resp["draft_id"].(float64))
+
+    var draft models.Draft
+    db.Preload("Paragraphs").Preload("Images").Preload("Blocks", models.OrderedBlocks).First(&draft, draftID)
+    assert.Len(t, draft.Blocks, 6)
+    assert.Equal(t, models.BlockHeading, draft.Blocks[0].Type)
+    assert.Equal(t, "Eat less beef", draft.Blocks[0].Text)
+    assert.Equal(t, models.DefaultHeadingLevel, draft.Blocks[0].Level)
+    assert.Equal(t, 5, draft.Blocks[5].Position)
+
+    // 旧客户端使用的段落和图片由正文块推导
+    assert.Len(t, draft.Paragraphs, 3)
+    assert.Len(t, draft.Images, 1)
+    assert.Equal(t, "drafts/1/beef.jpg", draft.Images[0].URL)
+
+    t.Run("Details Include Live Carbon", func(t *testing.T) {
+        status, resp := doRevisionRequest(router, "GET", fmt.Sprintf("/news/details/draft/%d", draftID), user.ID, nil)
+        assert.Equal(t, http.StatusOK, status)
+        blocks := resp["blocks"].([]interface{})
+        assert.Len(t, blocks, 6)
+
+        food := blocks[3].(map[string]interface{})["food"].(map[string]interface{})
+        assert.Equal(t, "Beef", food["en_food_name"])
+        assert.Equal(t, float64(30), food["emission"])
+
+        // 200g 牛肉 * 60 + 500g 大米 * 4
+        embedded := blocks[5].(map[string]interface{})["recipe"].(map[string]interface{})
+        assert.Equal(t, "Beef Rice", embedded["name"])
+        assert.Equal(t, float64(14), embedded["emission"])
+
+        // 食物数据变化后碳排放随之更新
+        db.Model(&models.Food{}).Where("id = ?", beef.ID).Update("ghg", 40)
+        _, resp = doRevisionRequest(router, "GET", fmt.Sprintf("/news/details/draft/%d", draftID), user.ID, nil)
+        food = resp["blocks"].([]interface{})[3].(map[string]interface{})["food"].(map[string]interface{})
+        assert.Equal(t, float64(20), food["emission"])
+    })
+
+    t.Run("Convert Keeps Blocks", func(t *testing.T) {
+        status, resp := doRevisionRequest(router, "POST", "/news/convert_draft", user.ID, gin.H{"draft_id": draftID})
+        assert.Equal(t, http.StatusOK, status)
+        newsID := uint(resp["news_id"].(float64))
+
+        var news models.News
+        db.Preload("Blocks", models.OrderedBlocks).First(&news, newsID)
+        assert.Len(t, news.Blocks, 6)
+        assert.Equal(t, models.BlockRecipe, news.Blocks[5].Type)
+
+        var count int64
+        db.Model(&models.DraftBlock{}).Where("draft_id = ?", draftID).Count(&count)
+        assert.Equal(t, int64(0), count)
+    })
+}
+
+func TestCreateDraftInvalidBlocks(t *testing.T) {
+    db, beef, _ := setupNewsBlockTestDB()
+    router := setupNewsRouter(db)
+
+    user := models.User{OpenID: "OpenID_InvalidBlocks_User", Nickname: "InvalidBlocksUser"}
+    db.Create(&user)
+
+    tests := []struct {
+        name          string
+        blocks        []gin.H
+        expectedError string
+    }{
+        {
+            name:          "Unknown Type",
+            blocks:        []gin.H{{"type": "video", "url": "a.mp4"}},
+            expectedError: `Block 0: unknown block type "video"`,
+        },
+        {
+            name:          "Empty Text",
+            blocks:        []gin.H{{"type": "text", "text": "ok"}, {"type": "text", "text": "   "}},
+            expectedError: "Block 1: text is required",
+        },
+        {
+            name:          "Heading Level",
+            blocks:        []gin.H{{"type": "heading", "text": "Title", "level": 5}},
+            expectedError: "Block 0: heading level must be between 1 and 3",
+        },
+        {
+            name:          "Food Without Weight",
+            blocks:        []gin.H{{"type": "food", "food_id": beef.ID}},
+            expectedError: "Block 0: food weight must be greater than 0 and at most 100 kg",
+        },
+        {
+            name:          "Missing Recipe",
+            blocks:        []gin.H{{"type": "recipe", "recipe_id": 999}},
+            expectedError: "Block 0: recipe 999 not found",
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            status, resp := doRevisionRequest(router, "POST", "/news/create_draft", user.ID, gin.H{
+                "title":  "Invalid Blocks",
+                "blocks": tt.blocks,
+            })
+            assert.Equal(t, http.StatusBadRequest, status)
+            assert.Equal(t, tt.expectedError, resp["error"])
+        })
+    }
+
+    var count int64
+    db.Model(&models.Draft{}).Count(&count)
+    assert.Equal(t, int64(0), count)
+}
+
+func TestLegacyContentBlocks(t *testing.T) {
+    db, _, _ := setupNewsBlockTestDB()
+    router := setupNewsRouter(db)
+
+    user := models.User{OpenID: "OpenID_LegacyBlocks_User", Nickname: "LegacyBlocksUser"}
+    db.Create(&user)
+
+    t.Run("Legacy Request Synthesizes Blocks", func(t *testing.T) {
+        status, resp := doRevisionRequest(router, "POST", "/news/create_draft", user.ID, gin.H{
+            "title":              "Legacy Draft",
+            "paragraphs":         []string{"One", "Two"},
+            "image_paths":        []string{"drafts/1/a.jpg"},
+            "image_descriptions": []string{"A"},
+        })
+        assert.Equal(t, http.StatusCreated, status)
+
+        var blocks []models.DraftBlock
+        db.Where("draft_id = ?", uint(resp["draft_id"].(float64))).Order("position").Find(&blocks)
+        assert.Len(t, blocks, 3)
+        assert.Equal(t, models.BlockText, blocks[0].Type)
+        assert.Equal(t, models.BlockImage, blocks[2].Type)
+        assert.Equal(t, "A", blocks[2].Description)
+    })
+
+    t.Run("Migrate Existing Rows", func(t *testing.T) {
+        news := models.News{
+            Title:      "Old News",
+            AuthorID:   user.ID,
+            Paragraphs: []models.Paragraph{{Text: "Old paragraph"}},
+            Images:     []models.NewsImage{{URL: "news/old.jpg", Description: "Old"}},
+        }
+        db.Create(&news)
+        draft := models.Draft{Title: "Old Draft", AuthorID: user.ID, Paragraphs: []models.DraftParagraph{{Text: "Old draft paragraph"}}}
+        db.Create(&draft)
+
+        migratedNews, migratedDrafts, err := models.MigrateLegacyBlocks(db)
+        assert.NoError(t, err)
+        assert.Equal(t, 1, migratedNews)
+        assert.Equal(t, 1, migratedDrafts)
+
+        var blocks []models.NewsBlock
+        db.Where("news_id = ?", news.ID).Order("position").Find(&blocks)
+        assert.Len(t, blocks, 2)
+        assert.Equal(t, "Old paragraph", blocks[0].Text)
+        assert.Equal(t, "news/old.jpg", blocks[1].URL)
+
+        // 重复执行不会再次迁移
+        migratedNews, migratedDrafts, err = models.MigrateLegacyBlocks(db)
+        assert.NoError(t, err)
+        assert.Equal(t, 0, migratedNews)
+        assert.Equal(t, 0, migratedDrafts)
+    })
+}
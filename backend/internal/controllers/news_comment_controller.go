@@ -0,0 +1,455 @@
+// internal/controllers/news_comment_controller.go
+package controllers
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+const (
+    defaultCommentPageSize = 20
+    maxCommentPageSize     = 50
+)
+
+// 评论排序方式；评论 ID 按发布顺序递增，按时间排序时以 ID 作为游标
+const (
+    CommentSortNewest = "newest"
+    CommentSortOldest = "oldest"
+    CommentSortTop    = "top" // 点赞最多
+)
+
+var (
+    errInvalidCommentSort   = errors.New("invalid sort")
+    errInvalidCommentLimit  = errors.New("invalid limit")
+    errInvalidCommentCursor = errors.New("invalid cursor")
+)
+
+// CommentResponse 定义返回给客户端的单条评论，回复通过 reply_count 和回复分页接口按需加载
+type CommentResponse struct {
+    ID          uint       `json:"id"`
+    NewsID      uint       `json:"news_id"`
+    ParentID    *uint      `json:"parent_id"`
+    IsReply     bool       `json:"is_reply"`
+    Content     string     `json:"content"`
+    PublishTime time.Time  `json:"publish_time"`
+    EditedAt    *time.Time `json:"edited_at"`
+    LikeCount   int        `json:"like_count"`
+    UserID      uint       `json:"user_id"`
+    Author      AuthorInfo `json:"author"`
+    DidLike     *bool      `json:"did_like,omitempty"` // 匿名访问时省略
+    IsDeleted   bool       `json:"is_deleted"`
+    ReplyCount  int64      `json:"reply_count"` // 直接回复数
+}
+
+// CommentPageResponse 定义一页评论
+type CommentPageResponse struct {
+    Comments   []CommentResponse `json:"comments"`
+    NextCursor string            `json:"next_cursor"` // 为空表示没有更多
+    HasMore    bool              `json:"has_more"`
+}
+
+// commentCursor 记录上一页最后一条评论的位置
+type commentCursor struct {
+    Sort      string `json:"s"`
+    LikeCount int    `json:"l,omitempty"`
+    ID        uint   `json:"i"`
+}
+
+func encodeCommentCursor(cur commentCursor) string {
+    data, _ := json.Marshal(cur)
+    return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCommentCursor(s, sort string) (*commentCursor, error) {
+    if s == "" {
+        return nil, nil
+    }
+    data, err := base64.RawURLEncoding.DecodeString(s)
+    if err != nil {
+        return nil, errInvalidCommentCursor
+    }
+    var cur commentCursor
+    if err := json.Unmarshal(data, &cur); err != nil || cur.Sort != sort || cur.ID == 0 {
+        return nil, errInvalidCommentCursor
+    }
+    return &cur, nil
+}
+
+// commentPageQuery 分页参数
+type commentPageQuery struct {
+    Sort   string
+    Limit  int
+    Cursor *commentCursor
+}
+
+// parseCommentPageQuery 读取 sort、limit、cursor 查询参数
+func parseCommentPageQuery(c *gin.Context, defaultSort string) (commentPageQuery, error) {
+    q := commentPageQuery{Sort: c.DefaultQuery("sort", defaultSort), Limit: defaultCommentPageSize}
+    switch q.Sort {
+    case CommentSortNewest, CommentSortOldest, CommentSortTop:
+    default:
+        return q, errInvalidCommentSort
+    }
+    if v := c.Query("limit"); v != "" {
+        limit, err := strconv.Atoi(v)
+        if err != nil || limit < 1 || limit > maxCommentPageSize {
+            return q, errInvalidCommentLimit
+        }
+        q.Limit = limit
+    }
+    cur, err := decodeCommentCursor(c.Query("cursor"), q.Sort)
+    if err != nil {
+        return q, err
+    }
+    q.Cursor = cur
+    return q, nil
+}
+
+// respondCommentPageError 将分页参数错误转换为 400 响应
+func respondCommentPageError(c *gin.Context, err error) {
+    switch err {
+    case errInvalidCommentSort:
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid sort"))
+    case errInvalidCommentLimit:
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid limit"))
+    default:
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid cursor"))
+    }
+}
+
+// apply 为查询加上排序和游标条件。点赞数在翻页期间变化时，个别评论可能重复或跳过
+func (q commentPageQuery) apply(db *gorm.DB) *gorm.DB {
+    switch q.Sort {
+    case CommentSortOldest:
+        if q.Cursor != nil {
+            db = db.Where("id > ?", q.Cursor.ID)
+        }
+        return db.Order("id ASC")
+    case CommentSortTop:
+        if q.Cursor != nil {
+            db = db.Where("like_count < ? OR (like_count = ? AND id < ?)", q.Cursor.LikeCount, q.Cursor.LikeCount, q.Cursor.ID)
+        }
+        return db.Order("like_count DESC").Order("id DESC")
+    default:
+        if q.Cursor != nil {
+            db = db.Where("id < ?", q.Cursor.ID)
+        }
+        return db.Order("id DESC")
+    }
+}
+
+// loadCommentPage 查询一页评论，scope 限定新闻或父评论；不包含当前用户屏蔽的用户的评论
+func (nc *NewsController) loadCommentPage(userID uint, q commentPageQuery, scope func(*gorm.DB) *gorm.DB) (*CommentPageResponse, error) {
+    var comments []models.Comment
+    if err := q.apply(nc.DB.Scopes(scope, models.NotBlockedBy(userID, "user_id"))).
+        Preload("Author", func(db *gorm.DB) *gorm.DB {
+            return db.Select("id", "nickname", "avatar_url")
+        }).
+        Limit(q.Limit + 1).
+        Find(&comments).Error; err != nil {
+        return nil, err
+    }
+
+    page := &CommentPageResponse{Comments: []CommentResponse{}}
+    if len(comments) > q.Limit {
+        comments = comments[:q.Limit]
+        last := comments[len(comments)-1]
+        page.HasMore = true
+        page.NextCursor = encodeCommentCursor(commentCursor{Sort: q.Sort, LikeCount: last.LikeCount, ID: last.ID})
+    }
+
+    responses, err := nc.commentResponses(userID, comments)
+    if err != nil {
+        return nil, err
+    }
+    page.Comments = responses
+    return page, nil
+}
+
+// commentResponses 为一组评论批量补充回复数、点赞状态和作者头像版本
+func (nc *NewsController) commentResponses(userID uint, comments []models.Comment) ([]CommentResponse, error) {
+    responses := make([]CommentResponse, 0, len(comments))
+    if len(comments) == 0 {
+        return responses, nil
+    }
+
+    ids := make([]uint, len(comments))
+    avatars := make([]string, 0, len(comments))
+    for i, comment := range comments {
+        ids[i] = comment.ID
+        if !comment.IsDeleted {
+            avatars = append(avatars, comment.Author.AvatarURL)
+        }
+    }
+
+    // 直接回复数，不计入屏蔽的用户的回复
+    var counts []struct {
+        ParentID uint
+        Count    int64
+    }
+    if err := nc.DB.Model(&models.Comment{}).
+        Select("parent_id, COUNT(*) AS count").
+        Where("parent_id IN ?", ids).
+        Scopes(models.NotBlockedBy(userID, "user_id")).
+        Group("parent_id").
+        Scan(&counts).Error; err != nil {
+        return nil, err
+    }
+    replyCounts := make(map[uint]int64, len(counts))
+    for _, rc := range counts {
+        replyCounts[rc.ParentID] = rc.Count
+    }
+
+    // 当前用户点赞过的评论，匿名访问（userID 为 0）时不查询
+    var likedIDs []uint
+    if userID != 0 {
+        if err := nc.DB.Table("user_likes_comments").
+            Where("user_id = ? AND comment_id IN ?", userID, ids).
+            Pluck("comment_id", &likedIDs).Error; err != nil {
+            return nil, err
+        }
+    }
+    liked := make(map[uint]bool, len(likedIDs))
+    for _, id := range likedIDs {
+        liked[id] = true
+    }
+
+    variants, err := models.ImageVariantsByPath(nc.DB, avatars)
+    if err != nil {
+        return nil, err
+    }
+
+    for _, comment := range comments {
+        resp := CommentResponse{
+            ID:          comment.ID,
+            NewsID:      comment.NewsID,
+            ParentID:    comment.ParentID,
+            IsReply:     comment.IsReply,
+            Content:     comment.Content,
+            PublishTime: comment.PublishTime,
+            EditedAt:    comment.EditedAt,
+            LikeCount:   comment.LikeCount,
+            IsDeleted:   comment.IsDeleted,
+            ReplyCount:  replyCounts[comment.ID],
+        }
+        if userID != 0 {
+            didLike := liked[comment.ID]
+            resp.DidLike = &didLike
+        }
+        // 已删除的评论不再展示内容和作者
+        if comment.IsDeleted {
+            resp.Content = models.DeletedCommentContent
+        } else {
+            resp.UserID = comment.UserID
+            resp.Author = AuthorInfo{
+                ID:             comment.Author.ID,
+                Nickname:       comment.Author.Nickname,
+                AvatarURL:      comment.Author.AvatarURL,
+                AvatarVariants: variants[comment.Author.AvatarURL],
+            }
+        }
+        responses = append(responses, resp)
+    }
+    return responses, nil
+}
+
+// topLevelComments 限定为某条新闻的顶级评论
+func topLevelComments(newsID uint64) func(*gorm.DB) *gorm.DB {
+    return func(db *gorm.DB) *gorm.DB {
+        return db.Where("news_id = ? AND parent_id IS NULL", newsID)
+    }
+}
+
+// GetNewsComments 分页获取新闻的顶级评论，默认最新在前，允许匿名访问
+func (nc *NewsController) GetNewsComments(c *gin.Context) {
+    userID := optionalUserID(c)
+
+    newsID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
+        return
+    }
+
+    q, err := parseCommentPageQuery(c, CommentSortNewest)
+    if err != nil {
+        respondCommentPageError(c, err)
+        return
+    }
+
+    var count int64
+    if err := nc.DB.Model(&models.News{}).Where("id = ?", newsID).
+        Scopes(models.NotBlockedBy(userID, "author_id")). // 与新闻详情一致，屏蔽的用户的新闻按不存在处理
+        Count(&count).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch news"))
+        return
+    }
+    if count == 0 {
+        apierror.Respond(c, apierror.ErrNewsNotFound)
+        return
+    }
+
+    page, err := nc.loadCommentPage(userID, q, topLevelComments(newsID))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch comments"))
+        return
+    }
+    c.JSON(http.StatusOK, page)
+}
+
+// GetCommentReplies 分页获取评论的直接回复，默认最早在前，允许匿名访问
+func (nc *NewsController) GetCommentReplies(c *gin.Context) {
+    userID := optionalUserID(c)
+
+    commentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid comment ID"))
+        return
+    }
+
+    q, err := parseCommentPageQuery(c, CommentSortOldest)
+    if err != nil {
+        respondCommentPageError(c, err)
+        return
+    }
+
+    // 屏蔽的用户的评论连同其回复一起隐藏
+    var parent models.Comment
+    if err := nc.DB.Select("id").Scopes(models.NotBlockedBy(userID, "user_id")).First(&parent, commentID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrCommentNotFound)
+            return
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find comment"))
+        return
+    }
+
+    page, err := nc.loadCommentPage(userID, q, func(db *gorm.DB) *gorm.DB {
+        return db.Where("parent_id = ?", parent.ID)
+    })
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch replies"))
+        return
+    }
+    c.JSON(http.StatusOK, page)
+}
+
+// EditCommentRequest 编辑评论的请求体
+type EditCommentRequest struct {
+    Content string `json:"content" binding:"required"`
+}
+
+// EditComment 编辑自己的评论，记录编辑时间
+func (nc *NewsController) EditComment(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    commentID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid comment ID"))
+        return
+    }
+
+    var request EditCommentRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+
+    var comment models.Comment
+    if err := nc.DB.First(&comment, commentID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrCommentNotFound)
+            return
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find comment"))
+        return
+    }
+    if comment.UserID != userID.(uint) {
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You do not have permission to edit this comment"))
+        return
+    }
+    if comment.IsDeleted {
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("Cannot edit a deleted comment"))
+        return
+    }
+
+    now := time.Now()
+    if err := nc.DB.Model(&comment).Updates(map[string]interface{}{
+        "content":   request.Content,
+        "edited_at": now,
+    }).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to edit comment"))
+        return
+    }
+
+    // 只通知新增的 @提及，已通知过的用户不会重复收到
+    comment.Content = request.Content
+    logNotifyError(notifyMentions(nc.DB, &comment, map[uint]bool{comment.UserID: true}))
+
+    c.JSON(http.StatusOK, gin.H{
+        "message":   "Comment edited successfully",
+        "id":        comment.ID,
+        "content":   request.Content,
+        "edited_at": now,
+    })
+}
+
+// removeComment 删除评论：有回复时保留为占位以维持楼层结构，否则直接删除，
+// 并向上清理因此不再有回复的已删除父评论
+func removeComment(tx *gorm.DB, comment *models.Comment) error {
+    var replies int64
+    if err := tx.Model(&models.Comment{}).Where("parent_id = ?", comment.ID).Count(&replies).Error; err != nil {
+        return err
+    }
+    if replies > 0 {
+        return tx.Model(comment).Updates(map[string]interface{}{
+            "content":    "",
+            "is_deleted": true,
+        }).Error
+    }
+
+    for {
+        if err := tx.Exec("DELETE FROM user_likes_comments WHERE comment_id = ?", comment.ID).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.Notification{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Delete(&models.Comment{}, comment.ID).Error; err != nil {
+            return err
+        }
+        if comment.ParentID == nil {
+            return nil
+        }
+
+        var parent models.Comment
+        if err := tx.First(&parent, *comment.ParentID).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                return nil
+            }
+            return err
+        }
+        if !parent.IsDeleted {
+            return nil
+        }
+        if err := tx.Model(&models.Comment{}).Where("parent_id = ?", parent.ID).Count(&replies).Error; err != nil {
+            return err
+        }
+        if replies > 0 {
+            return nil
+        }
+        comment = &parent
+    }
+}
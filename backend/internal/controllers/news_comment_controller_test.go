@@ -0,0 +1,310 @@
+// internal/controllers/news_comment_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+// setupCommentRouter 初始化评论相关路由
+func setupCommentRouter(db *gorm.DB) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    newsController := NewNewsController(db)
+
+    newsGroup := router.Group("/news")
+    newsGroup.Use(middleware.AuthMiddleware())
+    {
+        newsGroup.POST("/comments", newsController.AddComment)
+        newsGroup.PUT("/comments/:id", newsController.EditComment)
+        newsGroup.DELETE("/comments/:id", newsController.DeleteComment)
+        newsGroup.GET("/comments/:id/replies", newsController.GetCommentReplies)
+        newsGroup.GET("/:id/comments", newsController.GetNewsComments)
+        newsGroup.POST("/:id/comment_like", newsController.LikeComment)
+        newsGroup.GET("/details/news/:id", newsController.GetNewsDetails)
+    }
+    return router
+}
+
+// setupCommentTestData 创建新闻、评论者以及一组带点赞和回复的评论
+func setupCommentTestData(t *testing.T) (*gorm.DB, models.User, models.News) {
+    db := setupNewsTestDB()
+    assert.NoError(t, db.AutoMigrate(&models.News{}, &models.Comment{}))
+
+    user := models.User{OpenID: "OpenID_Comment_User", Nickname: "Commenter", AvatarURL: "avatars/default.jpg"}
+    db.Create(&user)
+    news := models.News{Title: "Commented News", AuthorID: user.ID}
+    db.Create(&news)
+    return db, user, news
+}
+
+func createComment(db *gorm.DB, news models.News, user models.User, content string, likes int, parent *models.Comment) models.Comment {
+    comment := models.Comment{
+        NewsID:      news.ID,
+        UserID:      user.ID,
+        Content:     content,
+        LikeCount:   likes,
+        PublishTime: time.Now(),
+    }
+    if parent != nil {
+        comment.ParentID = &parent.ID
+        comment.IsReply = true
+    }
+    db.Create(&comment)
+    return comment
+}
+
+func getCommentPage(t *testing.T, router http.Handler, userID uint, url string) (int, CommentPageResponse) {
+    req, _ := http.NewRequest("GET", url, nil)
+    req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+
+    var page CommentPageResponse
+    if w.Code == http.StatusOK {
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+    }
+    return w.Code, page
+}
+
+func commentIDs(page CommentPageResponse) []uint {
+    ids := make([]uint, len(page.Comments))
+    for i, comment := range page.Comments {
+        ids[i] = comment.ID
+    }
+    return ids
+}
+
+func TestGetNewsComments(t *testing.T) {
+    db, user, news := setupCommentTestData(t)
+    router := setupCommentRouter(db)
+
+    c1 := createComment(db, news, user, "first", 3, nil)
+    c2 := createComment(db, news, user, "second", 5, nil)
+    c3 := createComment(db, news, user, "third", 3, nil)
+    c4 := createComment(db, news, user, "fourth", 0, nil)
+    createComment(db, news, user, "reply 1", 0, &c2)
+    createComment(db, news, user, "reply 2", 0, &c2)
+    db.Model(&user).Association("LikedComments").Append(&c3)
+
+    base := fmt.Sprintf("/news/%d/comments", news.ID)
+
+    t.Run("Newest With Cursor", func(t *testing.T) {
+        var ids []uint
+        cursor := ""
+        for pages := 0; pages < 3; pages++ {
+            status, page := getCommentPage(t, router, user.ID, base+"?limit=3&cursor="+cursor)
+            assert.Equal(t, http.StatusOK, status)
+            ids = append(ids, commentIDs(page)...)
+            if !page.HasMore {
+                assert.Empty(t, page.NextCursor)
+                break
+            }
+            cursor = page.NextCursor
+        }
+        // 只包含顶级评论
+        assert.Equal(t, []uint{c4.ID, c3.ID, c2.ID, c1.ID}, ids)
+    })
+
+    t.Run("Oldest", func(t *testing.T) {
+        status, page := getCommentPage(t, router, user.ID, base+"?sort=oldest&limit=2")
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, []uint{c1.ID, c2.ID}, commentIDs(page))
+        assert.True(t, page.HasMore)
+
+        status, page = getCommentPage(t, router, user.ID, base+"?sort=oldest&limit=2&cursor="+page.NextCursor)
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, []uint{c3.ID, c4.ID}, commentIDs(page))
+        assert.False(t, page.HasMore)
+    })
+
+    t.Run("Most Liked", func(t *testing.T) {
+        status, page := getCommentPage(t, router, user.ID, base+"?sort=top&limit=2")
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, []uint{c2.ID, c3.ID}, commentIDs(page))
+
+        // 点赞数相同的评论按 ID 继续翻页
+        status, page = getCommentPage(t, router, user.ID, base+"?sort=top&limit=2&cursor="+page.NextCursor)
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, []uint{c1.ID, c4.ID}, commentIDs(page))
+    })
+
+    t.Run("Reply Count And Did Like", func(t *testing.T) {
+        _, page := getCommentPage(t, router, user.ID, base+"?sort=oldest")
+        if assert.Len(t, page.Comments, 4) {
+            assert.Equal(t, int64(2), page.Comments[1].ReplyCount)
+            assert.Equal(t, int64(0), page.Comments[0].ReplyCount)
+            assert.True(t, *page.Comments[2].DidLike)
+            assert.False(t, *page.Comments[1].DidLike)
+            assert.Equal(t, "Commenter", page.Comments[0].Author.Nickname)
+        }
+    })
+
+    t.Run("Invalid Parameters", func(t *testing.T) {
+        _, newest := getCommentPage(t, router, user.ID, base+"?limit=1")
+        for _, query := range []string{"?sort=random", "?limit=0", "?limit=51", "?limit=abc", "?cursor=not-a-cursor", "?sort=top&cursor=" + newest.NextCursor} {
+            status, _ := getCommentPage(t, router, user.ID, base+query)
+            assert.Equal(t, http.StatusBadRequest, status, query)
+        }
+    })
+
+    t.Run("News Not Found", func(t *testing.T) {
+        status, _ := getCommentPage(t, router, user.ID, "/news/99999/comments")
+        assert.Equal(t, http.StatusNotFound, status)
+    })
+
+    t.Run("News Details Include First Page", func(t *testing.T) {
+        req, _ := http.NewRequest("GET", fmt.Sprintf("/news/details/news/%d", news.ID), nil)
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(user.ID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        assert.Equal(t, http.StatusOK, w.Code)
+
+        var detail NewsDetailResponse
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+        assert.Len(t, detail.Comments, 4)
+        assert.Equal(t, int64(6), detail.CommentCount)
+        assert.Empty(t, detail.CommentsNextCursor)
+    })
+}
+
+func TestGetCommentReplies(t *testing.T) {
+    db, user, news := setupCommentTestData(t)
+    router := setupCommentRouter(db)
+
+    parent := createComment(db, news, user, "parent", 0, nil)
+    r1 := createComment(db, news, user, "reply 1", 0, &parent)
+    r2 := createComment(db, news, user, "reply 2", 0, &parent)
+    r3 := createComment(db, news, user, "reply 3", 0, &parent)
+    createComment(db, news, user, "nested", 0, &r1)
+
+    url := fmt.Sprintf("/news/comments/%d/replies", parent.ID)
+    status, page := getCommentPage(t, router, user.ID, url+"?limit=2")
+    assert.Equal(t, http.StatusOK, status)
+    assert.Equal(t, []uint{r1.ID, r2.ID}, commentIDs(page))
+    assert.Equal(t, int64(1), page.Comments[0].ReplyCount) // 更深层的回复按需加载
+    assert.True(t, page.HasMore)
+
+    status, page = getCommentPage(t, router, user.ID, url+"?limit=2&cursor="+page.NextCursor)
+    assert.Equal(t, http.StatusOK, status)
+    assert.Equal(t, []uint{r3.ID}, commentIDs(page))
+    assert.False(t, page.HasMore)
+
+    status, page = getCommentPage(t, router, user.ID, url+"?sort=newest")
+    assert.Equal(t, http.StatusOK, status)
+    assert.Equal(t, []uint{r3.ID, r2.ID, r1.ID}, commentIDs(page))
+
+    status, _ = getCommentPage(t, router, user.ID, "/news/comments/99999/replies")
+    assert.Equal(t, http.StatusNotFound, status)
+    status, _ = getCommentPage(t, router, user.ID, "/news/comments/abc/replies")
+    assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestEditComment(t *testing.T) {
+    db, user, news := setupCommentTestData(t)
+    router := setupCommentRouter(db)
+
+    other := models.User{OpenID: "OpenID_Comment_Other", Nickname: "Other"}
+    db.Create(&other)
+    comment := createComment(db, news, user, "original", 0, nil)
+    deleted := createComment(db, news, user, "", 0, nil)
+    db.Model(&deleted).Update("is_deleted", true)
+
+    edit := func(userID uint, id string, body string) (int, map[string]interface{}) {
+        req, _ := http.NewRequest("PUT", "/news/comments/"+id, bytes.NewBufferString(body))
+        req.Header.Set("Content-Type", "application/json")
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        var resp map[string]interface{}
+        _ = json.Unmarshal(w.Body.Bytes(), &resp)
+        return w.Code, resp
+    }
+
+    tests := []struct {
+        name           string
+        userID         uint
+        commentID      string
+        body           string
+        expectedStatus int
+        expectedError  string
+    }{
+        {"Invalid Comment ID", user.ID, "abc", `{"content":"x"}`, http.StatusBadRequest, "Invalid comment ID"},
+        {"Missing Content", user.ID, fmt.Sprint(comment.ID), `{}`, http.StatusBadRequest, "Invalid request body"},
+        {"Comment Not Found", user.ID, "99999", `{"content":"x"}`, http.StatusNotFound, "Comment not found"},
+        {"No Permission", other.ID, fmt.Sprint(comment.ID), `{"content":"x"}`, http.StatusForbidden, "You do not have permission to edit this comment"},
+        {"Deleted Comment", user.ID, fmt.Sprint(deleted.ID), `{"content":"x"}`, http.StatusBadRequest, "Cannot edit a deleted comment"},
+        {"Success", user.ID, fmt.Sprint(comment.ID), `{"content":"edited"}`, http.StatusOK, ""},
+    }
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            status, resp := edit(tc.userID, tc.commentID, tc.body)
+            assert.Equal(t, tc.expectedStatus, status)
+            if tc.expectedError != "" {
+                assert.Equal(t, tc.expectedError, resp["error"])
+                return
+            }
+            assert.Equal(t, "Comment edited successfully", resp["message"])
+
+            var saved models.Comment
+            db.First(&saved, comment.ID)
+            assert.Equal(t, "edited", saved.Content)
+            assert.NotNil(t, saved.EditedAt)
+        })
+    }
+}
+
+func TestDeleteCommentKeepsThread(t *testing.T) {
+    db, user, news := setupCommentTestData(t)
+    router := setupCommentRouter(db)
+
+    parent := createComment(db, news, user, "parent", 1, nil)
+    reply := createComment(db, news, user, "reply", 0, &parent)
+    db.Model(&user).Association("LikedComments").Append(&parent, &reply)
+
+    request := func(method, url string, body string) int {
+        req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+        req.Header.Set("Content-Type", "application/json")
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(user.ID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w.Code
+    }
+
+    // 删除有回复的评论：保留占位，隐藏内容和作者
+    assert.Equal(t, http.StatusOK, request("DELETE", fmt.Sprintf("/news/comments/%d", parent.ID), ""))
+    _, page := getCommentPage(t, router, user.ID, fmt.Sprintf("/news/%d/comments", news.ID))
+    if assert.Len(t, page.Comments, 1) {
+        placeholder := page.Comments[0]
+        assert.True(t, placeholder.IsDeleted)
+        assert.Equal(t, models.DeletedCommentContent, placeholder.Content)
+        assert.Equal(t, uint(0), placeholder.UserID)
+        assert.Empty(t, placeholder.Author.Nickname)
+        assert.Equal(t, int64(1), placeholder.ReplyCount)
+    }
+
+    // 已删除的评论不能再删除、点赞或回复
+    assert.Equal(t, http.StatusNotFound, request("DELETE", fmt.Sprintf("/news/comments/%d", parent.ID), ""))
+    assert.Equal(t, http.StatusBadRequest, request("POST", fmt.Sprintf("/news/%d/comment_like", parent.ID), ""))
+    assert.Equal(t, http.StatusBadRequest, request("POST", "/news/comments",
+        fmt.Sprintf(`{"news_id":%d,"content":"late reply","is_reply":true,"parent_id":%d}`, news.ID, parent.ID)))
+
+    // 删除最后一条回复后，占位评论一并清理
+    assert.Equal(t, http.StatusOK, request("DELETE", fmt.Sprintf("/news/comments/%d", reply.ID), ""))
+    var count int64
+    db.Model(&models.Comment{}).Count(&count)
+    assert.Equal(t, int64(0), count)
+    db.Table("user_likes_comments").Count(&count)
+    assert.Equal(t, int64(0), count)
+}
@@ -3,21 +3,20 @@
 package controllers
 
 import (
-    "mime/multipart"
     // "encoding/json"
-    "path/filepath"
     "net/http"
     "strings"
     "strconv"
     "errors"
     "time"
     "fmt"
-    "os"
-    "io"
     // "bytes"
 
     "github.com/gin-gonic/gin"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/imaging"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
 )
 
@@ -29,11 +28,13 @@ func NewNewsController(db *gorm.DB) *NewsController {
     return &NewsController{DB: db}
 }
 
-// CreateDraftRequest 定义创建草稿的请求结构
-type CreateDraftRequest struct {
-    Title      string               `json:"title" binding:"required"`
-    Paragraphs []models.DraftParagraph `json:"paragraphs,omitempty"`
-    // Images 和 Descriptions 将通过 multipart/form-data 处理
+// DraftRequest 创建和更新草稿的请求结构
+type DraftRequest struct {
+    Title             string                `json:"title"`
+    Paragraphs        []string              `json:"paragraphs"`
+    ImageDescriptions []string              `json:"image_descriptions"`
+    ImagePaths        []string              `json:"image_paths"`
+    Blocks            []models.ContentBlock `json:"blocks"` // 提供时以正文块为准，忽略段落和图片字段
 }
 
 // CreateDraft 详细创建草稿
@@ -41,51 +42,37 @@ func (nc *NewsController) CreateDraft(c *gin.Context) {
     // 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 解析 JSON 请求体
-    var request struct {
-        Title             string   `json:"title"`
-        Paragraphs        []string `json:"paragraphs"`
-        ImageDescriptions []string `json:"image_descriptions"`
-        ImagePaths        []string `json:"image_paths"`
-    }
+    var request DraftRequest
 
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+        apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("Invalid request data"))
         return
     }
 
     // 校验必填字段
     if request.Title == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Title is required"))
         return
     }
 
     // 校验图片描述和图片路径数量是否匹配
     if len(request.ImageDescriptions) != len(request.ImagePaths) {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Number of image descriptions and image paths do not match"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Number of image descriptions and image paths do not match"))
         return
     }
 
-    // 构建段落对象
-    var paragraphs []models.DraftParagraph
-    for _, text := range request.Paragraphs {
-        paragraphs = append(paragraphs, models.DraftParagraph{
-            Text: text,
-        })
-    }
-
-    // 构建图片对象
-    var draftImages []models.DraftImage
-    for i, path := range request.ImagePaths {
-        draftImages = append(draftImages, models.DraftImage{
-            URL:         path,
-            Description: request.ImageDescriptions[i],
-        })
+    // 生成正文内容
+    content, err := nc.resolveContent(request.Blocks, request.Paragraphs, request.ImageDescriptions, request.ImagePaths)
+    if err != nil {
+        respondContentError(c, err)
+        return
     }
+    paragraphs, draftImages, blocks := draftRowsOf(content)
 
     // 初始化草稿对象
     draft := models.Draft{
@@ -95,11 +82,12 @@ func (nc *NewsController) CreateDraft(c *gin.Context) {
         UpdatedAt:  time.Now(),
         Paragraphs: paragraphs,
         Images:     draftImages,
+        Blocks:     blocks,
     }
 
     // 插入数据库
     if err := nc.DB.Create(&draft).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create draft"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to create draft"))
         return
     }
 
@@ -109,83 +97,42 @@ func (nc *NewsController) CreateDraft(c *gin.Context) {
     })
 }
 
-// UploadImage 处理单张图片上传，并返回图片的相对路径
+// UploadImage 处理单张图片上传：校验真实格式、去除 EXIF、生成多种尺寸，返回默认版本的相对路径
 func (nc *NewsController) UploadImage(c *gin.Context) {
     // 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取图片文件
     file, err := c.FormFile("image")
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Image file is required"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Image file is required"))
         return
     }
 
-    // 生成保存路径
-    relativePath, err := uploadImage(file, userID.(uint))
+    // 处理并保存图片，相同内容的图片只保存一份
+    image, deduplicated, err := storeImage(c.Request.Context(), nc.DB, file, imaging.NewsProfile, userID.(uint))
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image"})
+        respondImageError(c, err, "Failed to upload image")
         return
     }
 
     c.JSON(http.StatusOK, gin.H{
-        "message": "Image uploaded successfully",
-        "path":    relativePath,
+        "message":      "Image uploaded successfully",
+        "path":         image.Path,
+        "variants":     image.VariantList(),
+        "width":        image.Width,
+        "height":       image.Height,
+        "deduplicated": deduplicated,
     })
 }
 
-// uploadImage 处理图片文件上传，并返回图片 URL
-func uploadImage(file *multipart.FileHeader, draftID uint) (string, error) {
-    // 获取基地址
-    baseUploadPath := os.Getenv("BASE_UPLOAD_PATH")
-    if baseUploadPath == "" {
-        baseUploadPath = "./uploads" // 如果没有设置环境变量，使用默认路径
-    }
-
-    // 打开文件
-    src, err := file.Open()
-    if err != nil {
-        return "", err
-    }
-    defer src.Close()
-
-    // 生成保存路径，确保文件名唯一
-    timestamp := time.Now().UnixNano()
-    filename := fmt.Sprintf("%d_%s", timestamp, filepath.Base(file.Filename))
-    relativePath := filepath.Join("drafts", fmt.Sprintf("%d", draftID), filename)
-    savePath := filepath.Join(baseUploadPath, relativePath)
-
-    // 创建目标文件
-    out, err := createFile(savePath)
-    if err != nil {
-        return "", err
-    }
-    defer out.Close()
-
-    // 复制文件内容
-    _, err = io.Copy(out, src)
-    if err != nil {
-        return "", err
-    }
-
-    // 返回图片的相对路径
-    return relativePath, nil
-}
-
-// createFile 创建文件夹并创建目标文件
-func createFile(savePath string) (*os.File, error) {
-    // 创建文件夹
-    dir := filepath.Dir(savePath)
-    if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-        return nil, err
-    }
-
-    // 创建文件
-    return os.Create(savePath)
+// ConvertDraftToNewsRequest 将草稿发布为新闻的请求体
+type ConvertDraftToNewsRequest struct {
+    DraftID uint `json:"draft_id" binding:"required"`
 }
 
 // ConvertDraftToNews 处理将草稿转换为新闻的请求
@@ -193,32 +140,69 @@ func (nc *NewsController) ConvertDraftToNews(c *gin.Context) {
     // 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    type ConvertDraftToNewsRequest struct {
-        DraftID uint `json:"draft_id" binding:"required"`
-    }
-
     // 绑定 JSON 请求体
     var convertRequest ConvertDraftToNewsRequest
     if err := c.ShouldBindJSON(&convertRequest); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     // 查找草稿，确保草稿属于当前用户
     var draft models.Draft
-    if err := nc.DB.Preload("Paragraphs").Preload("Images").First(&draft, "id = ? AND author_id = ?", convertRequest.DraftID, userID.(uint)).Error; err != nil {
+    if err := nc.DB.Preload("Paragraphs").Preload("Images").Preload("Blocks", models.OrderedBlocks).First(&draft, "id = ? AND author_id = ?", convertRequest.DraftID, userID.(uint)).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
-            c.JSON(http.StatusNotFound, gin.H{"error": "Draft not found"})
+            apierror.Respond(c, apierror.ErrDraftNotFound)
             return
         }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find draft"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find draft"))
+        return
+    }
+
+    // 开始事务
+    tx := nc.DB.Begin()
+    if tx.Error != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to start transaction"))
         return
     }
 
+    // 发布草稿并删除草稿及其关联数据
+    news, err := publishDraft(tx, &draft)
+    if err != nil {
+        tx.Rollback()
+        apierror.Respond(c, apierror.ErrInternal.WithMessage(err.Error()))
+        return
+    }
+
+    // 提交事务
+    if err := tx.Commit().Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Draft converted to news successfully.",
+        "news_id": news.ID,
+    })
+}
+
+// 发布草稿时各步骤失败对应的错误，错误信息直接返回给客户端
+var (
+    errCreateNews            = errors.New("Failed to create news")
+    errDeleteDraftImages     = errors.New("Failed to delete draft images")
+    errDeleteDraftParagraphs = errors.New("Failed to delete draft paragraphs")
+    errDeleteDraftBlocks     = errors.New("Failed to delete draft blocks")
+    errDeleteDraftRevisions  = errors.New("Failed to delete draft revisions")
+    errDeleteDraft           = errors.New("Failed to delete draft")
+)
+
+// publishDraft 在事务中将草稿（需预加载段落、图片和正文块）转换为新闻，并删除草稿及其关联数据
+func publishDraft(tx *gorm.DB, draft *models.Draft) (*models.News, error) {
+    paragraphs, images, blocks := newsRowsOf(models.ContentOfDraft(draft).WithBlocks())
+
     // 初始化新闻对象
     news := models.News{
         Title:           draft.Title,
@@ -232,69 +216,39 @@ func (nc *NewsController) ConvertDraftToNews(c *gin.Context) {
         LikedByUsers:    []models.User{},
         FavoritedByUsers: []models.User{},
         DislikedByUsers: []models.User{},
-        Paragraphs:      []models.Paragraph{},
-        Images:          []models.NewsImage{},
-    }
-
-    // 复制段落
-    for _, p := range draft.Paragraphs {
-        news.Paragraphs = append(news.Paragraphs, models.Paragraph{
-            Text: p.Text,
-        })
-    }
-
-    // 复制图片，包括描述
-    for _, img := range draft.Images {
-        news.Images = append(news.Images, models.NewsImage{
-            URL:         img.URL,
-            Description: img.Description, // 复制描述
-        })
-    }
-
-    // 开始事务
-    tx := nc.DB.Begin()
-    if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
-        return
+        Paragraphs:      paragraphs,
+        Images:          images,
+        Blocks:          blocks,
     }
 
     // 创建新闻
     if err := tx.Create(&news).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create news"})
-        return
+        return nil, errCreateNews
     }
 
     // 删除关联的图片数据
     if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftImage{}).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete draft images"})
-        return
+        return nil, errDeleteDraftImages
     }
     // 删除关联的段落数据
     if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftParagraph{}).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete draft paragraphs"})
-        return
+        return nil, errDeleteDraftParagraphs
     }
-
-    // 删除草稿及其关联的段落和图片
-    if err := tx.Delete(&draft).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete draft"})
-        return
+    // 删除关联的正文块
+    if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftBlock{}).Error; err != nil {
+        return nil, errDeleteDraftBlocks
+    }
+    // 删除草稿的历史版本
+    if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftRevision{}).Error; err != nil {
+        return nil, errDeleteDraftRevisions
     }
 
-    // 提交事务
-    if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
-        return
+    // 删除草稿
+    if err := tx.Delete(draft).Error; err != nil {
+        return nil, errDeleteDraft
     }
 
-    c.JSON(http.StatusOK, gin.H{
-        "message": "Draft converted to news successfully.",
-        "news_id": news.ID,
-    })
+    return &news, nil
 }
 
 // UpdateDraft 更新草稿
@@ -302,132 +256,74 @@ func (nc *NewsController) UpdateDraft(c *gin.Context) {
     // 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取草稿 ID
     draftID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid draft ID"))
         return
     }
 
     // 查找草稿
     var draft models.Draft
-    if err := nc.DB.Preload("Images").First(&draft, draftID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Draft not found"})
+    if err := nc.DB.Preload("Paragraphs").Preload("Images").Preload("Blocks", models.OrderedBlocks).First(&draft, draftID).Error; err != nil {
+        apierror.Respond(c, apierror.ErrDraftNotFound)
         return
     }
 
     // 验证是否为草稿作者
     if draft.AuthorID != userID {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to edit this draft"})
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You do not have permission to edit this draft"))
         return
     }
 
     // 获取新上传的图片路径列表
-    var request struct {
-        Title             string   `json:"title"`
-        Paragraphs        []string `json:"paragraphs"`
-        ImageDescriptions []string `json:"image_descriptions"`
-        ImagePaths        []string `json:"image_paths"`
-    }
+    var request DraftRequest
 
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+        apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("Invalid request data"))
         return
     }
 
     // 校验必填字段
     if request.Title == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Title is required"))
         return
     }
     if len(request.ImageDescriptions) != len(request.ImagePaths) {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Number of image descriptions and image paths do not match"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Number of image descriptions and image paths do not match"))
         return
     }
 
-    // 删除旧草稿（包括旧图片的删除）
-    if err := nc.DB.Transaction(func(tx *gorm.DB) error {
-        // 删除关联段落
-        if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftParagraph{}).Error; err != nil {
-            return err
-        }
-
-        // 删除关联图片及本地文件（检查图片路径是否在上传列表中）
-        for _, image := range draft.Images {
-            filePath := filepath.Join(os.Getenv("BASE_UPLOAD_PATH"), image.URL)
-
-            // 如果该图片的路径不在新的图片路径列表中，则删除该图片
-            found := false
-            for _, newImagePath := range request.ImagePaths {
-                if image.URL == newImagePath {
-                    found = true
-                    break
-                }
-            }
-
-            // 如果图片路径不在新上传的路径中，才删除它
-            if !found {
-                if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
-                    return fmt.Errorf("failed to delete file: %s", filePath)
-                }
-            }
-        }
-
-        // 删除草稿图片记录
-        if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftImage{}).Error; err != nil {
-            return err
-        }
+    // 生成正文内容
+    content, err := nc.resolveContent(request.Blocks, request.Paragraphs, request.ImageDescriptions, request.ImagePaths)
+    if err != nil {
+        respondContentError(c, err)
+        return
+    }
 
-        // 删除草稿本体
-        if err := tx.Delete(&draft).Error; err != nil {
+    // 原地更新草稿，保留草稿 ID、历史版本和定时发布设置；保存历史版本和替换内容在同一事务中完成。
+    // 旧图片文件不在此处删除：历史版本仍可能引用它们，恢复版本时需要使用
+    if err := nc.DB.Transaction(func(tx *gorm.DB) error {
+        // 保存旧内容为历史版本
+        if err := saveDraftRevision(tx, &draft); err != nil {
             return err
         }
-
-        return nil
-    }); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete old draft"})
+        return replaceDraftContent(tx, &draft, request.Title, content)
+    }); errors.Is(err, errDraftGone) {
+        apierror.Respond(c, apierror.ErrDraftNotFound)
         return
-    }
-
-    // 构建新草稿并保存
-    var paragraphs []models.DraftParagraph
-    for _, text := range request.Paragraphs {
-        paragraphs = append(paragraphs, models.DraftParagraph{
-            Text: text,
-        })
-    }
-
-    // 构建新上传的图片对象
-    var draftImages []models.DraftImage
-    for i, path := range request.ImagePaths {
-        draftImages = append(draftImages, models.DraftImage{
-            URL:         path,
-            Description: request.ImageDescriptions[i],
-        })
-    }
-
-    // 创建新草稿
-    newDraft := models.Draft{
-        Title:      request.Title,
-        AuthorID:   userID.(uint),
-        CreatedAt:  time.Now(),
-        UpdatedAt:  time.Now(),
-        Paragraphs: paragraphs,
-        Images:     draftImages,
-    }
-
-    if err := nc.DB.Create(&newDraft).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create new draft"})
+    } else if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update draft"))
         return
     }
 
     c.JSON(http.StatusCreated, gin.H{
         "message":  "Draft updated successfully",
-        "draft_id": newDraft.ID,
+        "draft_id": draft.ID,
     })
 }
 
@@ -436,41 +332,44 @@ func (nc *NewsController) DeleteDraft(c *gin.Context) {
     // 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取草稿 ID
     draftID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid draft ID"))
         return
     }
 
     // 查找草稿
     var draft models.Draft
     if err := nc.DB.Preload("Images").First(&draft, draftID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Draft not found"})
+        apierror.Respond(c, apierror.ErrDraftNotFound)
         return
     }
 
     // 验证是否为草稿作者
     if draft.AuthorID != userID {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this draft"})
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You do not have permission to delete this draft"))
         return
     }
 
     // 删除草稿记录及其关联的段落和图片
     if err := nc.DB.Transaction(func(tx *gorm.DB) error {
-        // 删除关联的段落
+        // 删除关联的段落和正文块
         if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftParagraph{}).Error; err != nil {
             return err
         }
+        if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftBlock{}).Error; err != nil {
+            return err
+        }
 
-        // 删除关联的图片，同时删除本地文件
+        // 删除关联的图片，同时删除存储中的文件
         for _, image := range draft.Images {
-            if err := deleteLocalFile(image.URL); err != nil {
-                fmt.Printf("Failed to delete local file: %s, error: %v\n", image.URL, err)
+            if err := deleteUploadedFile(c.Request.Context(), image.URL); err != nil {
+                fmt.Printf("Failed to delete file: %s, error: %v\n", image.URL, err)
             }
         }
 
@@ -478,6 +377,11 @@ func (nc *NewsController) DeleteDraft(c *gin.Context) {
             return err
         }
 
+        // 删除草稿的历史版本
+        if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftRevision{}).Error; err != nil {
+            return err
+        }
+
         // 删除草稿
         if err := tx.Delete(&draft).Error; err != nil {
             return err
@@ -485,7 +389,7 @@ func (nc *NewsController) DeleteDraft(c *gin.Context) {
 
         return nil
     }); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete draft"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to delete draft"))
         return
     }
 
@@ -497,27 +401,27 @@ func (nc *NewsController) DeleteNews(c *gin.Context) {
     // 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取新闻 ID
     newsID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
     // 查找新闻
     var news models.News
     if err := nc.DB.Preload("Images").First(&news, newsID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+        apierror.Respond(c, apierror.ErrNewsNotFound)
         return
     }
 
     // 验证是否为新闻作者
     if news.AuthorID != userID {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this news"})
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You do not have permission to delete this news"))
         return
     }
 
@@ -539,26 +443,60 @@ func (nc *NewsController) DeleteNews(c *gin.Context) {
             return err
         }
         
-        // 删除关联的段落
+        // 删除关联的段落和正文块
         if err := tx.Where("news_id = ?", news.ID).Delete(&models.Paragraph{}).Error; err != nil {
             return err
         }
+        if err := tx.Where("news_id = ?", news.ID).Delete(&models.NewsBlock{}).Error; err != nil {
+            return err
+        }
 
         // 删除关联的图片
         if err := tx.Where("news_id = ?", news.ID).Delete(&models.NewsImage{}).Error; err != nil {
             return err
         }
 
+        // 删除新闻的历史版本
+        if err := tx.Where("news_id = ?", news.ID).Delete(&models.NewsRevision{}).Error; err != nil {
+            return err
+        }
+
         // **新增**：删除与该新闻关联的所有评论
         if err := tx.Where("news_id = ?", news.ID).Delete(&models.Comment{}).Error; err != nil {
             return err
         }
 
-        // 删除本地图片文件
+        // 删除与该新闻相关的通知
+        if err := tx.Where("news_id = ?", news.ID).Delete(&models.Notification{}).Error; err != nil {
+            return err
+        }
+
+        // 删除该新闻的阅读记录和统计数据
+        if err := tx.Where("news_id = ?", news.ID).Delete(&models.NewsView{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("news_id = ?", news.ID).Delete(&models.NewsDailyStat{}).Error; err != nil {
+            return err
+        }
+
+        // 删除分享短码和分享记录
+        if err := tx.Where("news_id = ?", news.ID).Delete(&models.ShareCode{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("news_id = ?", news.ID).Delete(&models.ShareEvent{}).Error; err != nil {
+            return err
+        }
+
+        // 从收藏夹中移除
+        if err := tx.Where("item_type = ? AND item_id = ?", models.CollectionItemNews, news.ID).Delete(&models.CollectionItem{}).Error; err != nil {
+            return err
+        }
+
+        // 删除存储中的图片文件
         for _, image := range news.Images {
-            if err := deleteLocalFile(image.URL); err != nil {
+            if err := deleteUploadedFile(c.Request.Context(), image.URL); err != nil {
                 // 记录日志，但不终止事务
-                fmt.Printf("Failed to delete local file: %s, error: %v\n", image.URL, err)
+                fmt.Printf("Failed to delete file: %s, error: %v\n", image.URL, err)
             }
         }
 
@@ -569,45 +507,25 @@ func (nc *NewsController) DeleteNews(c *gin.Context) {
 
         return nil
     }); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to delete news"))
         return
     }
 
     c.JSON(http.StatusOK, gin.H{"message": "News deleted successfully."})
 }
 
-// deleteLocalFile 删除本地文件
-func deleteLocalFile(filePath string) error {
-    baseUploadPath := os.Getenv("BASE_UPLOAD_PATH")
-    if baseUploadPath == "" {
-        baseUploadPath = "./uploads" // 如果没有设置环境变量，使用默认路径
-    }
-
-    // 拼接完整路径
-    fullPath := filepath.Join(baseUploadPath, filePath)
-    if err := os.Remove(fullPath); err != nil {
-        if os.IsNotExist(err) {
-            // 如果文件不存在，不认为是错误
-            return nil
-        }
-        return err
-    }
-
-    return nil
-}
-
 // GetMyNews 获取用户创建的新闻 ID 列表
 func (nc *NewsController) GetMyNews(c *gin.Context) {
     // 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     var newsList []models.News
     if err := nc.DB.Select("id").Where("author_id = ?", userID).Order("upload_time DESC").Find(&newsList).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch news"))
         return
     }
 
@@ -627,13 +545,13 @@ func (nc *NewsController) GetMyDrafts(c *gin.Context) {
     // 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     var draftList []models.Draft
     if err := nc.DB.Select("id").Where("author_id = ?", userID).Order("updated_at DESC").Find(&draftList).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch drafts"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch drafts"))
         return
     }
 
@@ -668,23 +586,51 @@ type PreviewNewsItem struct {
     AuthorNickname     string `json:"author_nickname"`
     AuthorAvatarURL    string `json:"author_avatar_url"`
     LikeCount          int    `json:"like_count"`
+    FirstImageVariants []models.ImageVariant `json:"first_image_variants,omitempty"`
+    AuthorAvatarVariants []models.ImageVariant `json:"author_avatar_variants,omitempty"`
 }
 
 // PreviewNews 处理新闻预览的请求
 func (nc *NewsController) PreviewNews(c *gin.Context) {
     var req PreviewNewsRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     var newsList []models.News
     if err := nc.DB.Preload("Author").Preload("Paragraphs").Preload("Images").
-        Where("id IN ?", req.IDs).Find(&newsList).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch news"})
+        Where("id IN ?", req.IDs).Scopes(models.NotBlockedBy(optionalUserID(c), "author_id")).Find(&newsList).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch news"))
+        return
+    }
+
+    previews, err := previewItems(nc.DB, newsList)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch image variants"))
         return
     }
 
+    c.JSON(http.StatusOK, PreviewNewsResponse{
+        Previews: previews,
+    })
+}
+
+// previewItems 由已预加载作者、段落和图片的新闻生成预览
+func previewItems(db *gorm.DB, newsList []models.News) ([]PreviewNewsItem, error) {
+    // 首图和作者头像的各尺寸版本
+    var paths []string
+    for _, news := range newsList {
+        if len(news.Images) > 0 {
+            paths = append(paths, news.Images[0].URL)
+        }
+        paths = append(paths, news.Author.AvatarURL)
+    }
+    variants, err := models.ImageVariantsByPath(db, paths)
+    if err != nil {
+        return nil, err
+    }
+
     previews := make([]PreviewNewsItem, 0, len(newsList))
     for _, news := range newsList {
         firstParagraphText := ""
@@ -708,13 +654,12 @@ func (nc *NewsController) PreviewNews(c *gin.Context) {
             AuthorNickname:     news.Author.Nickname,
             AuthorAvatarURL:    news.Author.AvatarURL,
             LikeCount:          news.LikeCount,
+            FirstImageVariants: variants[firstImageURL],
+            AuthorAvatarVariants: variants[news.Author.AvatarURL],
         }
         previews = append(previews, preview)
     }
-
-    c.JSON(http.StatusOK, PreviewNewsResponse{
-        Previews: previews,
-    })
+    return previews, nil
 }
 
 // PreviewDraftRequest 定义预览草稿的请求结构
@@ -734,20 +679,34 @@ type PreviewDraftItem struct {
     FirstParagraphText string `json:"first_paragraph_text"`
     FirstImageURL      string `json:"first_image_url"`
     ImageDescription   string `json:"image_description"` // 新增描述字段
+    FirstImageVariants []models.ImageVariant `json:"first_image_variants,omitempty"`
 }
 
 // PreviewDrafts 处理草稿预览的请求
 func (nc *NewsController) PreviewDrafts(c *gin.Context) {
     var req PreviewDraftRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     var drafts []models.Draft
     if err := nc.DB.Preload("Paragraphs").Preload("Images").
         Where("id IN ?", req.IDs).Find(&drafts).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch drafts"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch drafts"))
+        return
+    }
+
+    // 首图的各尺寸版本
+    var paths []string
+    for _, draft := range drafts {
+        if len(draft.Images) > 0 {
+            paths = append(paths, draft.Images[0].URL)
+        }
+    }
+    variants, err := models.ImageVariantsByPath(nc.DB, paths)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch image variants"))
         return
     }
 
@@ -771,6 +730,7 @@ func (nc *NewsController) PreviewDrafts(c *gin.Context) {
             FirstParagraphText: firstParagraphText,
             FirstImageURL:      firstImageURL,
             ImageDescription:   imageDescription, // 设置描述
+            FirstImageVariants: variants[firstImageURL],
         }
         previews = append(previews, preview)
     }
@@ -785,6 +745,7 @@ type AuthorInfo struct {
     ID         uint   `json:"id"`
     Nickname   string `json:"nickname"`
     AvatarURL  string `json:"avatar_url"`
+    AvatarVariants []models.ImageVariant `json:"avatar_variants,omitempty"`
 }
 
 // NewsDetailResponse 定义详细查看新闻的响应结构
@@ -800,24 +761,31 @@ type NewsDetailResponse struct {
     Author          AuthorInfo    `json:"author"`
     Paragraphs      []models.Paragraph `json:"paragraphs"`
     Images          []models.NewsImage `json:"images"`
-    Comments        []models.Comment   `json:"comments"`
+    Blocks          []ContentBlockResponse `json:"blocks"`
+    EditedAt        *time.Time    `json:"edited_at"`
+    Comments        []CommentResponse  `json:"comments"`           // 第一页顶级评论
+    CommentCount    int64              `json:"comment_count"`      // 未删除的评论总数（含回复）
+    CommentsNextCursor string          `json:"comments_next_cursor"` // 下一页评论的游标
 }
 
-// GetNewsDetails 详细查看单个新闻
-func (nc *NewsController) GetNewsDetails(c *gin.Context) {
-    // 获取用户 ID
-    userIDValue, exists := c.Get("user_id")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-        return
+// optionalUserID 返回可选认证下的当前用户 ID，匿名访问时返回 0
+func optionalUserID(c *gin.Context) uint {
+    if userID, exists := c.Get("user_id"); exists {
+        return userID.(uint)
     }
-    userID := userIDValue.(uint)
+    return 0
+}
+
+// GetNewsDetails 详细查看单个新闻，允许匿名访问，匿名时评论不包含点赞状态
+func (nc *NewsController) GetNewsDetails(c *gin.Context) {
+    // 获取用户 ID（匿名为 0）
+    userID := optionalUserID(c)
 
     // 获取新闻 ID
     newsIDStr := c.Param("id")
     newsID, err := strconv.ParseUint(newsIDStr, 10, 64)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
@@ -827,37 +795,44 @@ func (nc *NewsController) GetNewsDetails(c *gin.Context) {
         Preload("Author").
         Preload("Paragraphs").
         Preload("Images").
+        Preload("Blocks", models.OrderedBlocks).
+        Scopes(models.NotBlockedBy(userID, "author_id")). // 屏蔽的用户的新闻按不存在处理
         First(&news, "id = ?", newsID).
         Error; err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
-            c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+            apierror.Respond(c, apierror.ErrNewsNotFound)
             return
         }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch news details"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch news details"))
         return
     }
 
-    // 获取顶级评论
-    var topLevelComments []models.Comment
-    if err := nc.DB.Preload("Replies").
-        Preload("Author", func(db *gorm.DB) *gorm.DB {
-            return db.Select("id", "nickname", "avatar_url")
-        }).
-        Where("news_id = ? AND parent_id IS NULL", newsID).
-        Find(&topLevelComments).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+    // 只返回第一页顶级评论，其余通过评论分页接口加载
+    commentPage, err := nc.loadCommentPage(userID, commentPageQuery{Sort: CommentSortNewest, Limit: defaultCommentPageSize}, topLevelComments(newsID))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch comments"))
+        return
+    }
+    var commentCount int64
+    if err := nc.DB.Model(&models.Comment{}).Where("news_id = ? AND is_deleted = ?", newsID, false).
+        Scopes(models.NotBlockedBy(userID, "user_id")).Count(&commentCount).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch comments"))
         return
     }
 
-    // 先查找用户所有点赞过的评论 ID
-    likedCommentsMap, err := nc.fetchUserLikedCommentMap(userID)
+    // 正文块（未迁移的旧新闻由段落和图片生成）
+    blocks, err := nc.renderBlocks(models.ContentOfNews(&news).WithBlocks().Blocks)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user's liked comments"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch content blocks"))
         return
     }
 
-    // 构建递归评论结构,并标记 did_like
-    comments := nc.buildCommentTree(topLevelComments, likedCommentsMap)
+    // 图片和作者头像的各尺寸版本
+    variants, err := newsImageVariants(nc.DB, news.Images, news.Author.AvatarURL)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch image variants"))
+        return
+    }
 
     response := NewsDetailResponse{
         ID:            news.ID,
@@ -872,55 +847,20 @@ func (nc *NewsController) GetNewsDetails(c *gin.Context) {
             ID:        news.Author.ID,
             Nickname:  news.Author.Nickname,
             AvatarURL: news.Author.AvatarURL,
+            AvatarVariants: variants[news.Author.AvatarURL],
         },
         Paragraphs: news.Paragraphs,
         Images:     news.Images,
-        Comments:   comments,
+        Blocks:     blocks,
+        EditedAt:   news.EditedAt,
+        Comments:   commentPage.Comments,
+        CommentCount:       commentCount,
+        CommentsNextCursor: commentPage.NextCursor,
     }
 
     c.JSON(http.StatusOK, response)
 }
 
-// fetchUserLikedCommentMap 获取当前用户点赞过的评论，做成 map[评论ID] = true
-func (nc *NewsController) fetchUserLikedCommentMap(userID uint) (map[uint]bool, error) {
-    var user models.User
-    // 预加载 LikedComments
-    if err := nc.DB.Preload("LikedComments").First(&user, userID).Error; err != nil {
-        return nil, err
-    }
-
-    result := make(map[uint]bool)
-    for _, c := range user.LikedComments {
-        result[c.ID] = true
-    }
-    return result, nil
-}
-
-// buildCommentTree 构建递归评论结构，并标记 did_like 字段
-func (nc *NewsController) buildCommentTree(comments []models.Comment, likedCommentsMap map[uint]bool) []models.Comment {
-    for i := range comments {
-        // 判断该评论是否被当前用户点赞
-        if likedCommentsMap[comments[i].ID] {
-            // 可以自己给 Comment 模型新增字段 DidLike bool, 
-            // 但是也可以返回到 JSON 里，灵活处理：
-            comments[i].DidLike = true // 需要在 Comment 模型中加 DidLike 字段
-        }
-
-        // 继续递归处理 replies
-        var replies []models.Comment
-        if err := nc.DB.
-            Preload("Replies").
-            Preload("Author", func(db *gorm.DB) *gorm.DB {
-                return db.Select("id", "nickname", "avatar_url")
-            }).
-            Where("parent_id = ?", comments[i].ID).
-            Find(&replies).Error; err == nil {
-            comments[i].Replies = nc.buildCommentTree(replies, likedCommentsMap)
-        }
-    }
-    return comments
-}
-
 // DraftDetailResponse 定义详细查看草稿的响应结构
 type DraftDetailResponse struct {
     ID          uint                `json:"id"`
@@ -930,6 +870,8 @@ type DraftDetailResponse struct {
     UpdatedAt   time.Time           `json:"updated_at"`
     Paragraphs  []models.DraftParagraph `json:"paragraphs"`
     Images      []models.DraftImage `json:"images"`
+    Blocks      []ContentBlockResponse `json:"blocks"`
+    ScheduledAt *time.Time          `json:"scheduled_at"`
 }
 
 // GetDraftDetails 详细查看单个草稿
@@ -937,7 +879,7 @@ func (nc *NewsController) GetDraftDetails(c *gin.Context) {
     // 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
@@ -945,18 +887,32 @@ func (nc *NewsController) GetDraftDetails(c *gin.Context) {
     draftIDStr := c.Param("id")
     draftID, err := strconv.ParseUint(draftIDStr, 10, 64)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid draft ID"))
         return
     }
 
     var draft models.Draft
-    if err := nc.DB.Preload("Author").Preload("Paragraphs").Preload("Images").
+    if err := nc.DB.Preload("Author").Preload("Paragraphs").Preload("Images").Preload("Blocks", models.OrderedBlocks).
         First(&draft, "id = ? AND author_id = ?", draftID, userID).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
-            c.JSON(http.StatusNotFound, gin.H{"error": "Draft not found"})
+            apierror.Respond(c, apierror.ErrDraftNotFound)
             return
         }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch draft details"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch draft details"))
+        return
+    }
+
+    // 正文块（未迁移的旧草稿由段落和图片生成）
+    blocks, err := nc.renderBlocks(models.ContentOfDraft(&draft).WithBlocks().Blocks)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch content blocks"))
+        return
+    }
+
+    // 图片和作者头像的各尺寸版本
+    variants, err := draftImageVariants(nc.DB, draft.Images, draft.Author.AvatarURL)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch image variants"))
         return
     }
 
@@ -967,11 +923,14 @@ func (nc *NewsController) GetDraftDetails(c *gin.Context) {
             ID:        draft.Author.ID,
             Nickname:  draft.Author.Nickname,
             AvatarURL: draft.Author.AvatarURL,
+            AvatarVariants: variants[draft.Author.AvatarURL],
         },
         CreatedAt:  draft.CreatedAt,
         UpdatedAt:  draft.UpdatedAt,
         Paragraphs: draft.Paragraphs,
         Images:     draft.Images,
+        Blocks:     blocks,
+        ScheduledAt: draft.ScheduledAt,
     }
 
     c.JSON(http.StatusOK, response)
@@ -979,26 +938,21 @@ func (nc *NewsController) GetDraftDetails(c *gin.Context) {
 
 // GetNewsByViewCount 获取按观看量降序排序的新闻 ID 列表，每页 10 条
 func (nc *NewsController) GetNewsByViewCount(c *gin.Context) {
-    _, exists := c.Get("user_id")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-        return
-    }
-
     pageStr := c.Query("page")
     page, err := strconv.Atoi(pageStr)
     if err != nil || page < 1 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid page number"))
         return
     }
 
     var newsList []models.News
     if err := nc.DB.Select("id").
+        Scopes(models.NotBlockedBy(optionalUserID(c), "author_id")).
         Order("view_count DESC").
         Limit(10).
         Offset((page - 1) * 10).
         Find(&newsList).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch news"))
         return
     }
 
@@ -1015,26 +969,21 @@ func (nc *NewsController) GetNewsByViewCount(c *gin.Context) {
 
 // GetNewsByLikeCount 获取按点赞量降序排序的新闻 ID 列表，每页 10 条
 func (nc *NewsController) GetNewsByLikeCount(c *gin.Context) {
-    _, exists := c.Get("user_id")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-        return
-    }
-
     pageStr := c.Query("page")
     page, err := strconv.Atoi(pageStr)
     if err != nil || page < 1 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid page number"))
         return
     }
 
     var newsList []models.News
     if err := nc.DB.Select("id").
+        Scopes(models.NotBlockedBy(optionalUserID(c), "author_id")).
         Order("like_count DESC").
         Limit(10).
         Offset((page - 1) * 10).
         Find(&newsList).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch news"))
         return
     }
 
@@ -1051,26 +1000,21 @@ func (nc *NewsController) GetNewsByLikeCount(c *gin.Context) {
 
 // GetNewsByUploadTime 获取按上传时间降序排序的新闻 ID 列表，每页 10 条
 func (nc *NewsController) GetNewsByUploadTime(c *gin.Context) {
-    _, exists := c.Get("user_id")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-        return
-    }
-
     pageStr := c.Query("page")
     page, err := strconv.Atoi(pageStr)
     if err != nil || page < 1 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid page number"))
         return
     }
 
     var newsList []models.News
     if err := nc.DB.Select("id").
+        Scopes(models.NotBlockedBy(optionalUserID(c), "author_id")).
         Order("upload_time DESC").
         Limit(10).
         Offset((page - 1) * 10).
         Find(&newsList).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch news"))
         return
     }
 
@@ -1085,23 +1029,26 @@ func (nc *NewsController) GetNewsByUploadTime(c *gin.Context) {
     })
 }
 
+// AddCommentRequest 发表评论或回复的请求体
+type AddCommentRequest struct {
+    NewsID   uint   `json:"news_id" binding:"required"`
+    Content  string `json:"content" binding:"required"`
+    IsReply  bool   `json:"is_reply"`
+    ParentID *uint  `json:"parent_id,omitempty"`
+}
+
 func (nc *NewsController) AddComment(c *gin.Context) {
     // 从 JWT 中获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 请求体绑定
-    var commentRequest struct {
-        NewsID   uint   `json:"news_id" binding:"required"`
-        Content  string `json:"content" binding:"required"`
-        IsReply  bool   `json:"is_reply"`
-        ParentID *uint  `json:"parent_id,omitempty"`
-    }
+    var commentRequest AddCommentRequest
     if err := c.ShouldBindJSON(&commentRequest); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
@@ -1110,41 +1057,61 @@ func (nc *NewsController) AddComment(c *gin.Context) {
     }
 
     if commentRequest.IsReply && commentRequest.ParentID == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "ParentID is required for a reply"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("ParentID is required for a reply"))
         return
     }
 
     // 检查新闻是否存在
-    var newsExists bool
-    if err := nc.DB.Model(&models.News{}).Select("count(*) > 0").Where("id = ?", commentRequest.NewsID).Scan(&newsExists).Error; err != nil || !newsExists {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+    var news models.News
+    if err := nc.DB.Select("id, author_id").First(&news, commentRequest.NewsID).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
     // 校验父评论逻辑
+    var parentAuthorID uint
     if commentRequest.IsReply {
         if commentRequest.ParentID == nil || *commentRequest.ParentID == 0 {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "ParentID is required for a reply"})
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("ParentID is required for a reply"))
             return
         }
 
         var parentComment models.Comment
         if err := nc.DB.First(&parentComment, *commentRequest.ParentID).Error; err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Parent comment not found"})
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Parent comment not found"))
             return
         }
 
         if parentComment.NewsID != commentRequest.NewsID {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Parent comment does not belong to the specified news"})
+            apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("Parent comment does not belong to the specified news"))
             return
         }
+
+        if parentComment.IsDeleted {
+            apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("Cannot reply to a deleted comment"))
+            return
+        }
+        parentAuthorID = parentComment.UserID
     } else {
         if commentRequest.ParentID != nil && *commentRequest.ParentID != 0 {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "ParentID must be empty for a top-level comment"})
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("ParentID must be empty for a top-level comment"))
             return
         }
     }
 
+    // 被新闻作者或被回复者屏蔽时不能评论
+    var blockCount int64
+    if err := nc.DB.Model(&models.UserBlock{}).
+        Where("blocked_id = ? AND blocker_id IN ?", userID, []uint{news.AuthorID, parentAuthorID}).
+        Count(&blockCount).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to check block status"))
+        return
+    }
+    if blockCount > 0 {
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You cannot comment on this news"))
+        return
+    }
+
     // 创建评论对象
     comment := models.Comment{
         NewsID:      commentRequest.NewsID,
@@ -1158,13 +1125,19 @@ func (nc *NewsController) AddComment(c *gin.Context) {
 
     // 保存评论
     if err := nc.DB.Create(&comment).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add comment"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to add comment"))
         return
     }
 
+    // 累加新闻的评论日汇总
+    recordNewsStat(nc.DB, &news, models.StatComments, 1)
+
+    // 通知新闻作者或被回复者，以及被 @ 的用户
+    logNotifyError(notifyNewComment(nc.DB, &comment))
+
     var user models.User
     if err := nc.DB.Select("nickname, avatar_url").First(&user, userID.(uint)).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user info"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch user info"))
         return
     }
 
@@ -1192,7 +1165,7 @@ func (nc *NewsController) LikeComment(c *gin.Context) {
     // 1. 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
@@ -1200,77 +1173,80 @@ func (nc *NewsController) LikeComment(c *gin.Context) {
     commentIDStr := c.Param("id")
     commentID, err := strconv.ParseUint(commentIDStr, 10, 64)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid comment ID"))
         return
     }
 
     // 3. 开启事务
     tx := nc.DB.Begin()
     if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to start transaction"))
         return
     }
     defer func() {
         if r := recover(); r != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+            apierror.Respond(c, apierror.ErrInternal)
         }
     }()
 
     // 4. 查找评论
     var comment models.Comment
-    if err := tx.Preload("LikedByUsers").First(&comment, commentID).Error; err != nil {
+    if err := tx.Select("id, is_deleted").First(&comment, commentID).Error; err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
             tx.Rollback()
-            c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+            apierror.Respond(c, apierror.ErrCommentNotFound)
             return
         }
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find comment"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find comment"))
+        return
+    }
+    if comment.IsDeleted {
+        tx.Rollback()
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("Cannot like a deleted comment"))
         return
     }
 
     // 5. 查找用户
     var user models.User
-    if err := tx.Preload("LikedComments").First(&user, userID).Error; err != nil {
+    if err := tx.Select("id").First(&user, userID).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find user"))
         return
     }
 
-    // 6. 检查是否已点赞
-    for _, liked := range user.LikedComments {
-        if liked.ID == comment.ID {
-            tx.Rollback()
-            c.JSON(http.StatusBadRequest, gin.H{"error": "You have already liked this comment"})
-            return
-        }
+    // 6. 建立点赞关系；已点赞（包括并发的重复请求）时不修改计数
+    linked, err := models.CommentLikes.Link(tx, user.ID, comment.ID)
+    if err != nil {
+        tx.Rollback()
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to like comment"))
+        return
     }
-
-    // 7. 建立点赞关系
-    if err := tx.Model(&user).Association("LikedComments").Append(&comment); err != nil {
+    if !linked {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to like comment"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("You have already liked this comment"))
         return
     }
 
-    // 8. 增加评论点赞数
-    if err := tx.Model(&comment).UpdateColumn("like_count", gorm.Expr("like_count + ?", 1)).Error; err != nil {
+    // 7. 增加评论点赞数
+    likeCount, err := models.CommentLikes.Adjust(tx, comment.ID, 1)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment like_count"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update comment like_count"))
         return
     }
 
-    // 9. 提交事务
+    // 8. 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
         return
     }
 
-    // 10. 返回成功
+    // 9. 返回成功
     c.JSON(http.StatusOK, gin.H{
         "message":    "Comment liked successfully",
-        "like_count": comment.LikeCount + 1,
+        "like_count": likeCount,
     })
 }
 
@@ -1279,7 +1255,7 @@ func (nc *NewsController) CancelLikeComment(c *gin.Context) {
     // 1. 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
@@ -1287,20 +1263,20 @@ func (nc *NewsController) CancelLikeComment(c *gin.Context) {
     commentIDStr := c.Param("id")
     commentID, err := strconv.ParseUint(commentIDStr, 10, 64)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid comment ID"))
         return
     }
 
     // 3. 开启事务
     tx := nc.DB.Begin()
     if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to start transaction"))
         return
     }
     defer func() {
         if r := recover(); r != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+            apierror.Respond(c, apierror.ErrInternal)
         }
     }()
 
@@ -1309,60 +1285,53 @@ func (nc *NewsController) CancelLikeComment(c *gin.Context) {
     if err := tx.First(&comment, commentID).Error; err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
             tx.Rollback()
-            c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+            apierror.Respond(c, apierror.ErrCommentNotFound)
             return
         }
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find comment"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find comment"))
         return
     }
 
     // 5. 查找用户
     var user models.User
-    if err := tx.Preload("LikedComments").First(&user, userID).Error; err != nil {
+    if err := tx.Select("id").First(&user, userID).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find user"))
         return
     }
 
-    // 6. 检查是否已点赞
-    liked := false
-    for _, likedComment := range user.LikedComments {
-        if likedComment.ID == comment.ID {
-            liked = true
-            break
-        }
-    }
-    if !liked {
+    // 6. 删除点赞关系；未点赞时不修改计数
+    unlinked, err := models.CommentLikes.Unlink(tx, user.ID, comment.ID)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You have not liked this comment"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to cancel like on comment"))
         return
     }
-
-    // 7. 删除点赞关系
-    if err := tx.Model(&user).Association("LikedComments").Delete(&comment); err != nil {
+    if !unlinked {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel like on comment"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You have not liked this comment"))
         return
     }
 
-    // 8. 减少评论点赞数
-    if err := tx.Model(&comment).UpdateColumn("like_count", gorm.Expr("like_count - ?", 1)).Error; err != nil {
+    // 7. 减少评论点赞数
+    likeCount, err := models.CommentLikes.Adjust(tx, comment.ID, -1)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment like_count"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update comment like_count"))
         return
     }
 
-    // 9. 提交事务
+    // 8. 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
         return
     }
 
-    // 10. 返回成功
+    // 9. 返回成功
     c.JSON(http.StatusOK, gin.H{
         "message":    "Comment like canceled successfully",
-        "like_count": comment.LikeCount - 1,
+        "like_count": likeCount,
     })
 }
 
@@ -1370,94 +1339,68 @@ func (nc *NewsController) DeleteComment(c *gin.Context) {
     // 从 JWT 中获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取评论 ID
     commentID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid comment ID"))
         return
     }
 
-    // 查找评论
+    // 查找评论（已删除的评论视为不存在）
     var comment models.Comment
-    if err := nc.DB.First(&comment, commentID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+    if err := nc.DB.First(&comment, "id = ? AND is_deleted = ?", commentID, false).Error; err != nil {
+        apierror.Respond(c, apierror.ErrCommentNotFound)
         return
     }
 
     // 验证用户是否为评论作者
     if comment.UserID != userID {
-        c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this comment"})
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You do not have permission to delete this comment"))
         return
     }
 
-    // 删除评论及其子评论
+    // 有回复的评论保留为占位，没有回复的直接删除
     if err := nc.DB.Transaction(func(tx *gorm.DB) error {
-        // 递归删除子评论
-        if err := deleteCommentRecursive(tx, commentID); err != nil {
-            return err
-        }
-        return nil
+        return removeComment(tx, &comment)
     }); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to delete comment"))
         return
     }
 
     c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
 }
 
-// deleteCommentRecursive 删除评论及其所有子评论
-func deleteCommentRecursive(tx *gorm.DB, commentID int) error {
-    // 删除所有子评论
-    var childComments []models.Comment
-    if err := tx.Where("parent_id = ?", commentID).Find(&childComments).Error; err != nil {
-        return err
-    }
-
-    for _, child := range childComments {
-        if err := deleteCommentRecursive(tx, int(child.ID)); err != nil {
-            return err
-        }
-    }
-
-    // 删除当前评论
-    if err := tx.Delete(&models.Comment{}, commentID).Error; err != nil {
-        return err
-    }
-
-    return nil
-}
-
 // LikeNews 处理用户点赞新闻的请求
 func (nc *NewsController) LikeNews(c *gin.Context) {
     // 从 JWT 中获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取新闻 ID
     newsID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
     // 开始事务
     tx := nc.DB.Begin()
     if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to start transaction"))
         return
     }
 
     defer func() {
         if r := recover(); r != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+            apierror.Respond(c, apierror.ErrInternal)
         }
     }()
 
@@ -1466,54 +1409,56 @@ func (nc *NewsController) LikeNews(c *gin.Context) {
     if err := tx.First(&news, newsID).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
             tx.Rollback()
-            c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+            apierror.Respond(c, apierror.ErrNewsNotFound)
             return
         }
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
         return
     }
 
     // 检查用户是否已点赞
     var user models.User
-    if err := tx.Preload("LikedNews").First(&user, userID).Error; err != nil {
+    if err := tx.Select("id").First(&user, userID).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find user"))
         return
     }
 
-    // 如果用户已点赞，返回错误
-    for _, likedNews := range user.LikedNews {
-        if likedNews.ID == uint(newsID) {
-            tx.Rollback()
-            c.JSON(http.StatusBadRequest, gin.H{"error": "You have already liked this news"})
-            return
-        }
+    // 建立点赞关系；已点赞（包括并发的重复请求）时不修改计数
+    linked, err := models.NewsLikes.Link(tx, user.ID, news.ID)
+    if err != nil {
+        tx.Rollback()
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to like news"))
+        return
     }
-
-    // 建立点赞关系
-    if err := tx.Model(&user).Association("LikedNews").Append(&news); err != nil {
+    if !linked {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to like news"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("You have already liked this news"))
         return
     }
 
     // 增加点赞数
-    if err := tx.Model(&news).UpdateColumn("like_count", gorm.Expr("like_count + ?", 1)).Error; err != nil {
+    likeCount, err := models.NewsLikes.Adjust(tx, news.ID, 1)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update like count"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update like count"))
         return
     }
 
     // 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
         return
     }
 
+    // 通知新闻作者
+    recordNewsStat(nc.DB, &news, models.StatLikes, 1)
+    logNotifyError(notifyNewsAuthor(nc.DB, &news, user.ID, models.NotificationLike))
+
     c.JSON(http.StatusOK, gin.H{
         "message":     "News liked successfully",
-        "like_count":  news.LikeCount + 1,
+        "like_count":  likeCount,
     })
 }
 
@@ -1522,28 +1467,28 @@ func (nc *NewsController) CancelLikeNews(c *gin.Context) {
     // 从 JWT 中获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取新闻 ID
     newsID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
     // 开始事务
     tx := nc.DB.Begin()
     if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to start transaction"))
         return
     }
 
     defer func() {
         if r := recover(); r != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+            apierror.Respond(c, apierror.ErrInternal)
         }
     }()
 
@@ -1552,59 +1497,54 @@ func (nc *NewsController) CancelLikeNews(c *gin.Context) {
     if err := tx.First(&news, newsID).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
             tx.Rollback()
-            c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+            apierror.Respond(c, apierror.ErrNewsNotFound)
             return
         }
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
         return
     }
 
     // 查找用户
     var user models.User
-    if err := tx.Preload("LikedNews").First(&user, userID).Error; err != nil {
+    if err := tx.Select("id").First(&user, userID).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find user"))
         return
     }
 
-    // 检查用户是否已点赞
-    liked := false
-    for _, likedNews := range user.LikedNews {
-        if likedNews.ID == uint(newsID) {
-            liked = true
-            break
-        }
-    }
-    if !liked {
+    // 删除点赞关系；未点赞时不修改计数
+    unlinked, err := models.NewsLikes.Unlink(tx, user.ID, news.ID)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You have not liked this news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to cancel like"))
         return
     }
-
-    // 删除点赞关系
-    if err := tx.Model(&user).Association("LikedNews").Delete(&news); err != nil {
+    if !unlinked {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel like"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You have not liked this news"))
         return
     }
 
     // 减少点赞数
-    if err := tx.Model(&news).UpdateColumn("like_count", gorm.Expr("like_count - ?", 1)).Error; err != nil {
+    likeCount, err := models.NewsLikes.Adjust(tx, news.ID, -1)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update like count"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update like count"))
         return
     }
 
     // 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
         return
     }
 
+    recordNewsStat(nc.DB, &news, models.StatLikes, -1)
+
     c.JSON(http.StatusOK, gin.H{
         "message":     "News like canceled successfully",
-        "like_count":  news.LikeCount - 1,
+        "like_count":  likeCount,
     })
 }
 
@@ -1613,28 +1553,37 @@ func (nc *NewsController) FavoriteNews(c *gin.Context) {
     // 从 JWT 中获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取新闻 ID
     newsID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
+    // 可选：同时放入指定的收藏夹
+    var collection *models.Collection
+    if v := c.Query("collection_id"); v != "" {
+        var ok bool
+        if collection, ok = findOwnCollection(c, nc.DB, userID.(uint), v); !ok {
+            return
+        }
+    }
+
     // 开始事务
     tx := nc.DB.Begin()
     if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to start transaction"))
         return
     }
 
     defer func() {
         if r := recover(); r != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+            apierror.Respond(c, apierror.ErrInternal)
         }
     }()
 
@@ -1643,54 +1592,64 @@ func (nc *NewsController) FavoriteNews(c *gin.Context) {
     if err := tx.First(&news, newsID).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
             tx.Rollback()
-            c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+            apierror.Respond(c, apierror.ErrNewsNotFound)
             return
         }
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
         return
     }
 
     // 查找用户
     var user models.User
-    if err := tx.Preload("FavoritedNews").First(&user, userID).Error; err != nil {
+    if err := tx.Select("id").First(&user, userID).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find user"))
         return
     }
 
-    // 如果用户已收藏，返回错误
-    for _, favoritedNews := range user.FavoritedNews {
-        if favoritedNews.ID == uint(newsID) {
-            tx.Rollback()
-            c.JSON(http.StatusBadRequest, gin.H{"error": "You have already favorited this news"})
-            return
-        }
+    // 建立收藏关系；已收藏（包括并发的重复请求）时不修改计数
+    linked, err := models.NewsFavorites.Link(tx, user.ID, news.ID)
+    if err != nil {
+        tx.Rollback()
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to favorite news"))
+        return
     }
-
-    // 建立收藏关系
-    if err := tx.Model(&user).Association("FavoritedNews").Append(&news); err != nil {
+    if !linked {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to favorite news"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("You have already favorited this news"))
         return
     }
 
     // 增加收藏数
-    if err := tx.Model(&news).UpdateColumn("favorite_count", gorm.Expr("favorite_count + ?", 1)).Error; err != nil {
+    favoriteCount, err := models.NewsFavorites.Adjust(tx, news.ID, 1)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update favorite count"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update favorite count"))
         return
     }
 
+    if collection != nil {
+        if _, _, err := addCollectionItem(tx, collection.ID, models.CollectionItemNews, news.ID); err != nil {
+            tx.Rollback()
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to add news to collection"))
+            return
+        }
+    }
+
     // 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
         return
     }
 
+    // 通知新闻作者
+    recordNewsStat(nc.DB, &news, models.StatFavorites, 1)
+    logNotifyError(notifyNewsAuthor(nc.DB, &news, user.ID, models.NotificationFavorite))
+
     c.JSON(http.StatusOK, gin.H{
         "message":         "News favorited successfully",
-        "favorite_count":  news.FavoriteCount + 1,
+        "favorite_count":  favoriteCount,
     })
 }
 
@@ -1699,28 +1658,28 @@ func (nc *NewsController) CancelFavoriteNews(c *gin.Context) {
     // 从 JWT 中获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取新闻 ID
     newsID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
     // 开始事务
     tx := nc.DB.Begin()
     if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to start transaction"))
         return
     }
 
     defer func() {
         if r := recover(); r != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+            apierror.Respond(c, apierror.ErrInternal)
         }
     }()
 
@@ -1729,59 +1688,63 @@ func (nc *NewsController) CancelFavoriteNews(c *gin.Context) {
     if err := tx.First(&news, newsID).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
             tx.Rollback()
-            c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+            apierror.Respond(c, apierror.ErrNewsNotFound)
             return
         }
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
         return
     }
 
     // 查找用户
     var user models.User
-    if err := tx.Preload("FavoritedNews").First(&user, userID).Error; err != nil {
+    if err := tx.Select("id").First(&user, userID).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find user"))
         return
     }
 
-    // 检查用户是否已收藏
-    favorited := false
-    for _, favoritedNews := range user.FavoritedNews {
-        if favoritedNews.ID == uint(newsID) {
-            favorited = true
-            break
-        }
+    // 删除收藏关系；未收藏时不修改计数
+    unlinked, err := models.NewsFavorites.Unlink(tx, user.ID, news.ID)
+    if err != nil {
+        tx.Rollback()
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to cancel favorite"))
+        return
     }
-    if !favorited {
+    if !unlinked {
         tx.Rollback()
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You have not favorited this news"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You have not favorited this news"))
         return
     }
 
-    // 删除收藏关系
-    if err := tx.Model(&user).Association("FavoritedNews").Delete(&news); err != nil {
+    // 减少收藏数
+    favoriteCount, err := models.NewsFavorites.Adjust(tx, news.ID, -1)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel favorite"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update favorite count"))
         return
     }
 
-    // 减少收藏数
-    if err := tx.Model(&news).UpdateColumn("favorite_count", gorm.Expr("favorite_count - ?", 1)).Error; err != nil {
+    // 同时从自己的收藏夹中移除
+    if err := tx.Where("item_type = ? AND item_id = ? AND collection_id IN (?)", models.CollectionItemNews, news.ID,
+        tx.Model(&models.Collection{}).Select("id").Where("user_id = ?", user.ID)).
+        Delete(&models.CollectionItem{}).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update favorite count"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to remove news from collections"))
         return
     }
 
     // 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
         return
     }
 
+    recordNewsStat(nc.DB, &news, models.StatFavorites, -1)
+
     c.JSON(http.StatusOK, gin.H{
         "message":         "News favorite canceled successfully",
-        "favorite_count":  news.FavoriteCount - 1,
+        "favorite_count":  favoriteCount,
     })
 }
 
@@ -1790,28 +1753,28 @@ func (nc *NewsController) DislikeNews(c *gin.Context) {
     // 从 JWT 中获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取新闻 ID
     newsID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
     // 开始事务
     tx := nc.DB.Begin()
     if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to start transaction"))
         return
     }
 
     defer func() {
         if r := recover(); r != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+            apierror.Respond(c, apierror.ErrInternal)
         }
     }()
 
@@ -1820,54 +1783,52 @@ func (nc *NewsController) DislikeNews(c *gin.Context) {
     if err := tx.First(&news, newsID).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
             tx.Rollback()
-            c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+            apierror.Respond(c, apierror.ErrNewsNotFound)
             return
         }
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
         return
     }
 
     // 查找用户
     var user models.User
-    if err := tx.Preload("DislikedNews").First(&user, userID).Error; err != nil {
+    if err := tx.Select("id").First(&user, userID).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find user"))
         return
     }
 
-    // 如果用户已点踩，返回错误
-    for _, dislikedNews := range user.DislikedNews {
-        if dislikedNews.ID == uint(newsID) {
-            tx.Rollback()
-            c.JSON(http.StatusBadRequest, gin.H{"error": "You have already disliked this news"})
-            return
-        }
+    // 建立点踩关系；已点踩（包括并发的重复请求）时不修改计数
+    linked, err := models.NewsDislikes.Link(tx, user.ID, news.ID)
+    if err != nil {
+        tx.Rollback()
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to dislike news"))
+        return
     }
-
-    // 建立点踩关系
-    if err := tx.Model(&user).Association("DislikedNews").Append(&news); err != nil {
+    if !linked {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dislike news"})
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("You have already disliked this news"))
         return
     }
 
     // 增加点踩数
-    if err := tx.Model(&news).UpdateColumn("dislike_count", gorm.Expr("dislike_count + ?", 1)).Error; err != nil {
+    dislikeCount, err := models.NewsDislikes.Adjust(tx, news.ID, 1)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update dislike count"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update dislike count"))
         return
     }
 
     // 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
         return
     }
 
     c.JSON(http.StatusOK, gin.H{
         "message":         "News disliked successfully",
-        "dislike_count":   news.DislikeCount + 1,
+        "dislike_count":   dislikeCount,
     })
 }
 
@@ -1876,28 +1837,28 @@ func (nc *NewsController) CancelDislikeNews(c *gin.Context) {
     // 从 JWT 中获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取新闻 ID
     newsID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
     // 开始事务
     tx := nc.DB.Begin()
     if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to start transaction"))
         return
     }
 
     defer func() {
         if r := recover(); r != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+            apierror.Respond(c, apierror.ErrInternal)
         }
     }()
 
@@ -1906,90 +1867,83 @@ func (nc *NewsController) CancelDislikeNews(c *gin.Context) {
     if err := tx.First(&news, newsID).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
             tx.Rollback()
-            c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+            apierror.Respond(c, apierror.ErrNewsNotFound)
             return
         }
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
         return
     }
 
     // 查找用户
     var user models.User
-    if err := tx.Preload("DislikedNews").First(&user, userID).Error; err != nil {
+    if err := tx.Select("id").First(&user, userID).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find user"))
         return
     }
 
-    // 检查用户是否已点踩
-    disliked := false
-    for _, dislikedNews := range user.DislikedNews {
-        if dislikedNews.ID == uint(newsID) {
-            disliked = true
-            break
-        }
-    }
-    if !disliked {
+    // 删除点踩关系；未点踩时不修改计数
+    unlinked, err := models.NewsDislikes.Unlink(tx, user.ID, news.ID)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusBadRequest, gin.H{"error": "You have not disliked this news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to cancel dislike"))
         return
     }
-
-    // 删除点踩关系
-    if err := tx.Model(&user).Association("DislikedNews").Delete(&news); err != nil {
+    if !unlinked {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel dislike"})
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You have not disliked this news"))
         return
     }
 
     // 减少点踩数
-    if err := tx.Model(&news).UpdateColumn("dislike_count", gorm.Expr("dislike_count - ?", 1)).Error; err != nil {
+    dislikeCount, err := models.NewsDislikes.Adjust(tx, news.ID, -1)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update dislike count"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update dislike count"))
         return
     }
 
     // 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
         return
     }
 
     c.JSON(http.StatusOK, gin.H{
         "message":         "News dislike canceled successfully",
-        "dislike_count":   news.DislikeCount - 1,
+        "dislike_count":   dislikeCount,
     })
 }
 
 // 用户浏览新闻
-// ViewNews 处理用户浏览新闻的请求
+// ViewNews 处理用户浏览新闻的请求。同一用户在去重窗口内重复打开只计一次浏览量，返回的 view_id 用于上报阅读进度
 func (nc *NewsController) ViewNews(c *gin.Context) {
     // 从 JWT 获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取新闻 ID
     newsID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
     // 开始事务
     tx := nc.DB.Begin()
     if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to start transaction"))
         return
     }
 
     defer func() {
         if r := recover(); r != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+            apierror.Respond(c, apierror.ErrInternal)
         }
     }()
 
@@ -1998,83 +1952,89 @@ func (nc *NewsController) ViewNews(c *gin.Context) {
     if err := tx.First(&news, newsID).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
             tx.Rollback()
-            c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+            apierror.Respond(c, apierror.ErrNewsNotFound)
             return
         }
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
         return
     }
 
     // 查找用户
     var user models.User
-    if err := tx.Preload("ViewedNews").First(&user, userID).Error; err != nil {
+    if err := tx.Select("id").First(&user, userID).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find user"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find user"))
         return
     }
 
-    // 检查用户是否已浏览过该新闻
-    alreadyViewed := false
-    for _, viewedNews := range user.ViewedNews {
-        if viewedNews.ID == uint(newsID) {
-            alreadyViewed = true
-            break
-        }
+    // 先锁定用户与新闻的浏览关联行，同一用户并发打开同一篇新闻时只有一个请求能新建阅读记录
+    if err := models.NewsViews.Lock(tx, user.ID, news.ID); err != nil {
+        tx.Rollback()
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to record news view"))
+        return
     }
 
-    // 限制浏览记录最大数量
-    const maxViewed = 200
-    if !alreadyViewed {
-        viewedCount := tx.Model(&user).Association("ViewedNews").Count()
-
-        if viewedCount >= maxViewed {
-            // 删除最早的浏览记录
-            var oldestNews models.News
-            if err := tx.Raw(`
-                SELECT news.* FROM news
-                JOIN user_viewed_news ON news.id = user_viewed_news.news_id
-                WHERE user_viewed_news.user_id = ?
-                ORDER BY user_viewed_news.created_at ASC
-                LIMIT 1
-            `, userID).Scan(&oldestNews).Error; err != nil {
-                tx.Rollback()
-                c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch oldest viewed news"})
-                return
-            }
-
-            if oldestNews.ID != 0 {
-                if err := tx.Model(&user).Association("ViewedNews").Delete(&oldestNews); err != nil {
-                    tx.Rollback()
-                    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete oldest viewed news"})
-                    return
-                }
-            }
-        }
-
-        // 添加新的浏览记录
-        if err := tx.Model(&user).Association("ViewedNews").Append(&news); err != nil {
+    // 去重窗口内再次打开同一篇新闻时沿用上一次阅读记录，不增加浏览量。
+    // 窗口从开始阅读（created_at）算起，只更新最近活跃时间，反复打开不会延长窗口。
+    // 使用加锁读取，可重复读隔离级别下也能看到刚提交的阅读记录
+    now := time.Now()
+    var view models.NewsView
+    err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ? AND news_id = ? AND created_at >= ?", user.ID, news.ID, now.Add(-models.ViewDedupeWindow)).
+        Order("id DESC").Limit(1).Find(&view).Error
+    if err != nil {
+        tx.Rollback()
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to record news view"))
+        return
+    }
+    if view.ID != 0 {
+        if err := tx.Model(&view).UpdateColumn("updated_at", now).Error; err != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record news view"})
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to record news view"))
+            return
+        }
+        if err := tx.Commit().Error; err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
             return
         }
+        c.JSON(http.StatusOK, gin.H{
+            "message":    "News view recorded successfully",
+            "view_id":    view.ID,
+            "counted":    false,
+            "view_count": news.ViewCount,
+        })
+        return
+    }
+
+    // 新建阅读记录，用户已在锁定关联行时加入浏览过该新闻的用户列表
+    view = models.NewsView{UserID: user.ID, NewsID: news.ID}
+    if err := tx.Create(&view).Error; err != nil {
+        tx.Rollback()
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to record news view"))
+        return
     }
 
     // 更新新闻的浏览计数
-    if err := tx.Model(&news).UpdateColumn("view_count", gorm.Expr("view_count + ?", 1)).Error; err != nil {
+    viewCount, err := models.NewsViews.Adjust(tx, news.ID, 1)
+    if err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update view count"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update view count"))
         return
     }
 
     // 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to commit transaction"))
         return
     }
 
+    recordNewsStat(nc.DB, &news, models.StatViews, 1)
+
     c.JSON(http.StatusOK, gin.H{
-        "message": "News view recorded successfully",
+        "message":    "News view recorded successfully",
+        "view_id":    view.ID,
+        "counted":    true,
+        "view_count": viewCount,
     })
 }
 
@@ -2082,28 +2042,28 @@ func (nc *NewsController) GetUserNewsStatus(c *gin.Context) {
     // 从 JWT 中获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 获取新闻 ID
     newsID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
         return
     }
 
     // 查询新闻是否存在
     var newsExists bool
     if err := nc.DB.Model(&models.News{}).Select("count(*) > 0").Where("id = ?", newsID).Scan(&newsExists).Error; err != nil || !newsExists {
-        c.JSON(http.StatusNotFound, gin.H{"error": "News not found"})
+        apierror.Respond(c, apierror.ErrNewsNotFound)
         return
     }
 
     // 查询用户对新闻的操作状态
     var user models.User
     if err := nc.DB.Preload("LikedNews").Preload("FavoritedNews").Preload("DislikedNews").First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query user data"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query user data"))
         return
     }
 
@@ -2141,33 +2101,36 @@ func (nc *NewsController) GetUserNewsStatus(c *gin.Context) {
     })
 }
 
+// SearchNewsRequest 搜索新闻的请求体
+type SearchNewsRequest struct {
+    Query string `json:"query"`
+}
+
 func (nc *NewsController) SearchNews(c *gin.Context) {
     // 定义请求体结构
-    var requestBody struct {
-        Query string `json:"query"`
-    }
+    var requestBody SearchNewsRequest
 
     // 解析请求体
     if err := c.ShouldBindJSON(&requestBody); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     query := requestBody.Query
     if query == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Query string cannot be empty"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Query string cannot be empty"))
         return
     }
 
     // 拆分字符串按空格分词
     keywords := strings.Fields(query)
     if len(keywords) == 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Query string is invalid"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Query string is invalid"))
         return
     }
 
     // 构建动态查询条件
-    db := nc.DB.Model(&models.News{})
+    db := nc.DB.Model(&models.News{}).Scopes(models.NotBlockedBy(optionalUserID(c), "author_id"))
     for _, keyword := range keywords {
         db = db.Where("title LIKE ?", "%"+keyword+"%")
     }
@@ -2175,7 +2138,7 @@ func (nc *NewsController) SearchNews(c *gin.Context) {
     // 执行查询
     var newsList []models.News
     if err := db.Order("id DESC").Find(&newsList).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search news"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to search news"))
         return
     }
 
@@ -31,7 +31,10 @@ func setupNewsTestDB() *gorm.DB {
         panic("failed to connect database")
     }
     if err := db.AutoMigrate(&models.User{}, &models.Draft{}, &models.DraftParagraph{}, &models.DraftImage{},
-        &models.NewsImage{}, &models.Paragraph{}); err != nil {
+        &models.NewsImage{}, &models.Paragraph{}, &models.DraftRevision{}, &models.NewsRevision{},
+        &models.DraftBlock{}, &models.NewsBlock{}, &models.UploadedImage{}, &models.Notification{}, &models.NewsView{},
+        &models.NewsDailyStat{}, &models.AuthorDailyStat{}, &models.UserFollow{}, &models.UserBlock{}, &models.Collection{}, &models.CollectionItem{},
+        &models.ShareCode{}, &models.ShareEvent{}, &models.PrivacySettings{}); err != nil {
         panic("failed to migrate models")
     }
     return db
@@ -238,6 +241,9 @@ func TestCreateDraft(t *testing.T) {
 func TestUploadImage(t *testing.T) {
     db := setupNewsTestDB()
     router := setupNewsRouter(db)
+    // 上传到临时目录，测试结束后自动清理，不影响仓库中的文件
+    uploadDir := t.TempDir()
+    t.Setenv("BASE_UPLOAD_PATH", uploadDir)
 
     // 创建一个用户 => user
     user := models.User{
@@ -252,6 +258,7 @@ func TestUploadImage(t *testing.T) {
         fileField      string
         fileName       string
         setupFunc      func()
+        fileData       []byte // 为空时上传一张有效的 PNG
         expectedStatus int
         expectedBody   map[string]interface{}
     }{
@@ -273,6 +280,16 @@ func TestUploadImage(t *testing.T) {
             expectedStatus: http.StatusBadRequest,
             expectedBody:   map[string]interface{}{"error": "Image file is required"},
         },
+        {
+            name:           "Not An Image",
+            userID:         user.ID,
+            fileField:      "image",
+            fileName:       "fake.jpg",
+            fileData:       []byte("fake_image_data"),
+            setupFunc:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   map[string]interface{}{"error": "Unsupported image format"},
+        },
         {
             name:           "Failed To Upload Image (simulate error)",
             userID:         user.ID,
@@ -294,7 +311,7 @@ func TestUploadImage(t *testing.T) {
             fileName:       "success_test_image.jpg",
             setupFunc: func() {
                 // 恢复到有效的上传目录
-                os.Setenv("BASE_UPLOAD_PATH", "./test_uploads")
+                os.Setenv("BASE_UPLOAD_PATH", uploadDir)
             },
             expectedStatus: http.StatusOK,
             expectedBody:   map[string]interface{}{"message": "Image uploaded successfully"},
@@ -311,8 +328,11 @@ func TestUploadImage(t *testing.T) {
             if tc.fileField != "" {
                 // 构造一个虚拟文件
                 part, _ := writer.CreateFormFile(tc.fileField, tc.fileName)
-                // 写点内容假装是文件数据
-                _, _ = part.Write([]byte("fake_image_data"))
+                data := tc.fileData
+                if data == nil {
+                    data = testImagePNG(t, 64, 48)
+                }
+                _, _ = part.Write(data)
             }
             writer.Close()
 
@@ -335,21 +355,18 @@ func TestUploadImage(t *testing.T) {
             if tc.expectedStatus == http.StatusOK {
                 // 检查 message 字段
                 assert.Equal(t, tc.expectedBody["message"], resp["message"])
-                // 应该返回 path
+                // 应该返回 path 以及各尺寸版本
                 _, ok := resp["path"]
                 assert.True(t, ok)
+                variants, ok := resp["variants"].([]interface{})
+                assert.True(t, ok)
+                assert.NotEmpty(t, variants)
             } else {
                 // 错误情况检查
                 for k, v := range tc.expectedBody {
                     assert.Equal(t, v, resp[k])
                 }
             }
-
-            // 如果有生成文件，需要清理
-            if tc.expectedStatus == http.StatusOK {
-                // 移除测试生成的文件夹
-                os.RemoveAll("./test_uploads")
-            }
         })
     }
 }
@@ -632,47 +649,45 @@ func TestUpdateDraft(t *testing.T) {
             expectedBody:   map[string]interface{}{"error": "Number of image descriptions and image paths do not match"},
         },
         {
-            name:           "Failed To Delete Old Draft (simulate TX error)",
+            name:           "Failed To Update Draft (simulate TX error)",
             userID:         draftOwner.ID,
             draftID:        fmt.Sprintf("%d", ownedDraft.ID),
             requestBody: gin.H{
-                "title":              "NewTitleAfterDeleteFail",
-                "paragraphs":         []string{"P1"},
-                "image_descriptions": []string{},
-                "image_paths":        []string{},
+                "title":              "NewTitleAfterCreateFail",
+                "paragraphs":         []string{"P1", "P2"},
+                "image_descriptions": []string{"desc1"},
+                "image_paths":        []string{"path1"},
             },
             setupFunc: func() {
-                // 在事务中 delete(draft) 时注入错误
-                db.Callback().Delete().Before("gorm:delete").Register("force_delete_old_draft_err", func(tx *gorm.DB) {
-                    if tx.Statement.Table == "drafts" {
-                        tx.Error = fmt.Errorf("forced delete old draft error")
+                // 在写入新段落时注入错误，整个更新回滚，原草稿保持不变
+                db.Callback().Create().Before("gorm:create").Register("force_create_paragraph_err", func(tx *gorm.DB) {
+                    if tx.Statement.Table == "draft_paragraphs" {
+                        tx.Error = fmt.Errorf("forced create paragraph error")
                     }
                 })
             },
             expectedStatus: http.StatusInternalServerError,
-            expectedBody:   map[string]interface{}{"error": "Failed to delete old draft"},
+            expectedBody:   map[string]interface{}{"error": "Failed to update draft"},
         },
-        
         {
-            name:           "Failed To Create New Draft (simulate error)",
+            name:           "Draft Published Concurrently",
             userID:         draftOwner.ID,
             draftID:        fmt.Sprintf("%d", ownedDraft.ID),
             requestBody: gin.H{
-                "title":              "NewTitleAfterCreateFail",
-                "paragraphs":         []string{"P1", "P2"},
-                "image_descriptions": []string{"desc1"},
-                "image_paths":        []string{"path1"},
+                "title":      "NewTitleAfterPublish",
+                "paragraphs": []string{"Orphan"},
             },
             setupFunc: func() {
-                // 在新建 draft 时注入错误，使用唯一回调名称
-                db.Callback().Create().Before("gorm:create").Register("force_create_new_draft_err_test1", func(tx *gorm.DB) {
+                db.Callback().Create().Remove("force_create_paragraph_err")
+                // 读取草稿后、更新前草稿被定时任务发布（删除）
+                db.Callback().Update().Before("gorm:update").Register("publish_draft_concurrently", func(tx *gorm.DB) {
                     if tx.Statement.Table == "drafts" {
-                        tx.Error = fmt.Errorf("forced create new draft error")
+                        tx.Session(&gorm.Session{NewDB: true}).Exec("DELETE FROM drafts WHERE id = ?", ownedDraft.ID)
                     }
                 })
             },
-            expectedStatus: http.StatusInternalServerError,
-            expectedBody:   map[string]interface{}{"error": "Failed to delete old draft"},
+            expectedStatus: http.StatusNotFound,
+            expectedBody:   map[string]interface{}{"error": "Draft not found"},
         },
         {
             name:           "Success Update Draft",
@@ -686,10 +701,8 @@ func TestUpdateDraft(t *testing.T) {
                 // 这里故意保留 old_path2 以模拟只删除 old_path1
             },
             setupFunc: func() {
-                // 移除错误回调，使用唯一名称
-                db.Callback().Create().Remove("force_create_new_draft_err_test1")
-                db.Callback().Delete().Remove("force_delete_old_draft_err")
-                db.Callback().Update().Remove("force_save_pending_error")
+                // 移除注入的回调
+                db.Callback().Update().Remove("publish_draft_concurrently")
             },
             expectedStatus: http.StatusCreated,
             expectedBody:   map[string]interface{}{"message": "Draft updated successfully"},
@@ -719,9 +732,8 @@ func TestUpdateDraft(t *testing.T) {
 
             if tc.expectedStatus == http.StatusCreated {
                 assert.Equal(t, tc.expectedBody["message"], resp["message"])
-                // draft_id 应该存在
-                _, ok := resp["draft_id"]
-                assert.True(t, ok)
+                // 原地更新，草稿 ID 不变
+                assert.Equal(t, float64(ownedDraft.ID), resp["draft_id"])
             } else {
                 for k, v := range tc.expectedBody {
                     assert.Equal(t, v, resp[k])
@@ -729,6 +741,22 @@ func TestUpdateDraft(t *testing.T) {
             }
         })
     }
+
+    // 失败的更新全部回滚：没有留下无主段落，成功的更新保留创建时间并记录历史版本
+    var orphanCount int64
+    db.Model(&models.DraftParagraph{}).Where("text IN ?", []string{"P1", "Orphan"}).Count(&orphanCount)
+    assert.Equal(t, int64(0), orphanCount)
+
+    var updated models.Draft
+    assert.NoError(t, db.Preload("Paragraphs").Preload("Images").First(&updated, ownedDraft.ID).Error)
+    assert.Equal(t, "UpdatedTitle", updated.Title)
+    assert.Len(t, updated.Paragraphs, 2)
+    assert.Len(t, updated.Images, 2)
+    assert.WithinDuration(t, ownedDraft.CreatedAt, updated.CreatedAt, time.Second)
+
+    var revisionCount int64
+    db.Model(&models.DraftRevision{}).Where("draft_id = ?", ownedDraft.ID).Count(&revisionCount)
+    assert.Equal(t, int64(1), revisionCount)
 }
 
 func TestDeleteDraft(t *testing.T) {
@@ -2375,8 +2403,10 @@ func TestLikeComment(t *testing.T) {
                 db.Model(&user).Association("LikedComments").Clear()
 
                 // 模拟 append 出错
-                db.Callback().Update().Before("gorm:association").Register("force_append_err", func(tx *gorm.DB) {
-                    tx.Error = fmt.Errorf("forced append error")
+                db.Callback().Create().Before("gorm:create").Register("force_append_err", func(tx *gorm.DB) {
+                    if tx.Statement.Table == "user_likes_comments" {
+                        tx.Error = fmt.Errorf("forced append error")
+                    }
                 })
             },
             expectedStatus: http.StatusInternalServerError,
@@ -2387,7 +2417,7 @@ func TestLikeComment(t *testing.T) {
             userID:         user.ID,
             commentID:      fmt.Sprintf("%d", comment.ID),
             setupFunc: func() {
-                db.Callback().Update().Remove("force_append_err")
+                db.Callback().Create().Remove("force_append_err")
 
                 // mock updateColumn 出错
                 db.Callback().Update().Before("gorm:update").Register("force_update_like_count_err", func(tx *gorm.DB) {
@@ -2709,12 +2739,14 @@ func TestDeleteComment(t *testing.T) {
             userID:         user.ID,
             commentID:      fmt.Sprintf("%d", comment.ID),
             setupFunc: func() {
-                // 注入事务错误 / delete错误
-                db.Callback().Delete().Before("gorm:delete").Register("force_delete_comment_err", func(tx *gorm.DB) {
+                // 注入事务错误 / delete错误（有回复的评论通过 update 软删除）
+                forceErr := func(tx *gorm.DB) {
                     if tx.Statement.Table == "comments" {
                         tx.Error = fmt.Errorf("forced delete comment error")
                     }
-                })
+                }
+                db.Callback().Delete().Before("gorm:delete").Register("force_delete_comment_err", forceErr)
+                db.Callback().Update().Before("gorm:update").Register("force_delete_comment_err", forceErr)
             },
             expectedStatus: http.StatusInternalServerError,
             expectedError:  "Failed to delete comment",
@@ -2726,6 +2758,7 @@ func TestDeleteComment(t *testing.T) {
             setupFunc: func() {
                 // 移除回调
                 db.Callback().Delete().Remove("force_delete_comment_err")
+                db.Callback().Update().Remove("force_delete_comment_err")
             },
             expectedStatus: http.StatusOK,
             isSuccess:      true,
@@ -2752,7 +2785,14 @@ func TestDeleteComment(t *testing.T) {
 
             if tc.isSuccess {
                 assert.Equal(t, "Comment deleted successfully", resp["message"])
-                // 可以在此处再查数据库，确认 comment 以及其子评论已被删除
+                // 有回复的评论保留为占位，回复不受影响
+                var deleted models.Comment
+                assert.NoError(t, db.First(&deleted, comment.ID).Error)
+                assert.True(t, deleted.IsDeleted)
+                assert.Empty(t, deleted.Content)
+                var child models.Comment
+                assert.NoError(t, db.First(&child, childComment.ID).Error)
+                assert.False(t, child.IsDeleted)
             } else {
                 if tc.expectedError != "" {
                     assert.Equal(t, tc.expectedError, resp["error"])
@@ -2872,8 +2912,10 @@ func TestLikeNews(t *testing.T) {
                 // 移除点赞关系
                 db.Model(&user).Association("LikedNews").Clear()
                 // 注入 append 错误
-                db.Callback().Update().Before("gorm:association").Register("force_append_like_news_err", func(tx *gorm.DB) {
-                    tx.Error = fmt.Errorf("forced append error")
+                db.Callback().Create().Before("gorm:create").Register("force_append_like_news_err", func(tx *gorm.DB) {
+                    if tx.Statement.Table == "user_likes_news" {
+                        tx.Error = fmt.Errorf("forced append error")
+                    }
                 })
             },
             expectedStatus: http.StatusInternalServerError,
@@ -2884,7 +2926,7 @@ func TestLikeNews(t *testing.T) {
             userID:         user.ID,
             newsID:         fmt.Sprintf("%d", newsItem.ID),
             setupFunc: func() {
-                db.Callback().Update().Remove("force_append_like_news_err")
+                db.Callback().Create().Remove("force_append_like_news_err")
                 // mock updateColumn 出错
                 db.Callback().Update().Before("gorm:update").Register("force_update_likecount_err", func(tx *gorm.DB) {
                     if tx.Statement.Table == "news" {
@@ -3222,8 +3264,10 @@ func TestFavoriteNews(t *testing.T) {
                 db.Model(&user).Association("FavoritedNews").Clear()
 
                 // 注入 association 操作错误
-                db.Callback().Update().Before("gorm:association").Register("force_favorite_append_err", func(tx *gorm.DB) {
-                    tx.Error = fmt.Errorf("forced favorite association error")
+                db.Callback().Create().Before("gorm:create").Register("force_favorite_append_err", func(tx *gorm.DB) {
+                    if tx.Statement.Table == "user_favorites_news" {
+                        tx.Error = fmt.Errorf("forced favorite association error")
+                    }
                 })
             },
             expectedStatus: http.StatusInternalServerError,
@@ -3234,7 +3278,7 @@ func TestFavoriteNews(t *testing.T) {
             userID:         user.ID,
             newsID:         fmt.Sprintf("%d", newsItem.ID),
             setupFunc: func() {
-                db.Callback().Update().Remove("force_favorite_append_err")
+                db.Callback().Create().Remove("force_favorite_append_err")
 
                 // mock updateColumn 出错
                 db.Callback().Update().Before("gorm:update").Register("force_favorite_update_err", func(tx *gorm.DB) {
@@ -3567,8 +3611,10 @@ func TestDislikeNews(t *testing.T) {
                 // 移除点踩关系
                 db.Model(&user).Association("DislikedNews").Clear()
                 // 模拟 association 出错
-                db.Callback().Update().Before("gorm:association").Register("force_dislike_append_err", func(tx *gorm.DB) {
-                    tx.Error = fmt.Errorf("forced dislike association error")
+                db.Callback().Create().Before("gorm:create").Register("force_dislike_append_err", func(tx *gorm.DB) {
+                    if tx.Statement.Table == "user_dislikes_news" {
+                        tx.Error = fmt.Errorf("forced dislike association error")
+                    }
                 })
             },
             expectedStatus: http.StatusInternalServerError,
@@ -3579,7 +3625,7 @@ func TestDislikeNews(t *testing.T) {
             userID:         user.ID,
             newsID:         fmt.Sprintf("%d", newsItem.ID),
             setupFunc: func() {
-                db.Callback().Update().Remove("force_dislike_append_err")
+                db.Callback().Create().Remove("force_dislike_append_err")
                 // 注入 updateColumn 出错
                 db.Callback().Update().Before("gorm:update").Register("force_update_dislike_err", func(tx *gorm.DB) {
                     if tx.Statement.Table == "news" {
@@ -3899,8 +3945,10 @@ func TestViewNews(t *testing.T) {
                 db.Callback().Query().Remove("force_oldest_viewed_err")
                 db.Callback().Query().Remove("force_find_user_err_view")
                 db.Callback().Update().Remove("force_delete_oldest_err")
-                db.Callback().Update().Before("gorm:association").Register("force_record_view_err", func(tx *gorm.DB) {
-                    tx.Error = fmt.Errorf("forced record news view error")
+                db.Callback().Create().Before("gorm:create").Register("force_record_view_err", func(tx *gorm.DB) {
+                    if tx.Statement.Table == "user_viewed_news" {
+                        tx.Error = fmt.Errorf("forced record news view error")
+                    }
                 })
             },
             expectedStatus: http.StatusInternalServerError,
@@ -3911,7 +3959,7 @@ func TestViewNews(t *testing.T) {
             userID:         user.ID,
             newsID:         fmt.Sprintf("%d", newsItem.ID),
             setupFunc: func() {
-                db.Callback().Update().Remove("force_record_view_err")
+                db.Callback().Create().Remove("force_record_view_err")
                 // mock updateColumn 出错
                 db.Callback().Update().Before("gorm:update").Register("force_update_viewcount_err", func(tx *gorm.DB) {
                     if tx.Statement.Table == "news" {
@@ -0,0 +1,95 @@
+// internal/controllers/news_feed_controller.go
+package controllers
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+const (
+    feedPageSize    = 10
+    feedCacheMaxAge = 60 // 客户端缓存一页的秒数
+)
+
+// feedOrders 信息流支持的排序方式，相同时按 ID 降序保证分页稳定
+var feedOrders = map[string]string{
+    "view_count":  "view_count DESC, id DESC",
+    "like_count":  "like_count DESC, id DESC",
+    "upload_time": "upload_time DESC, id DESC",
+}
+
+// NewsFeedResponse 定义一页信息流
+type NewsFeedResponse struct {
+    Previews []PreviewNewsItem `json:"previews"`
+    Page     int               `json:"page"`
+    HasMore  bool              `json:"has_more"`
+}
+
+// GetNewsFeed 分页获取新闻预览卡片，合并了按排序获取 ID 和 PreviewNews 两次请求。
+// 响应带 ETag 和 Cache-Control，客户端携带 If-None-Match 且内容未变时返回 304
+func (nc *NewsController) GetNewsFeed(c *gin.Context) {
+    sort := c.DefaultQuery("sort", "upload_time")
+    order, ok := feedOrders[sort]
+    if !ok {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid sort"))
+        return
+    }
+
+    page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+    if err != nil || page < 1 {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid page number"))
+        return
+    }
+
+    // 每页的作者、段落、图片各用一次预加载查询
+    userID := optionalUserID(c)
+    var newsList []models.News
+    if err := nc.DB.Preload("Author").Preload("Paragraphs").Preload("Images").
+        Scopes(models.NotBlockedBy(userID, "author_id")).
+        Order(order).
+        Limit(feedPageSize + 1).
+        Offset((page - 1) * feedPageSize).
+        Find(&newsList).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch news"))
+        return
+    }
+    hasMore := len(newsList) > feedPageSize
+    if hasMore {
+        newsList = newsList[:feedPageSize]
+    }
+
+    previews, err := previewItems(nc.DB, newsList)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch image variants"))
+        return
+    }
+
+    body, err := json.Marshal(NewsFeedResponse{Previews: previews, Page: page, HasMore: hasMore})
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to encode news feed"))
+        return
+    }
+
+    // /api/v1 下响应体还会被统一包装并加上 request_id，字节不再相同，只能使用弱 ETag
+    etag := utils.WeakETag(body)
+    c.Header("ETag", etag)
+    // 登录用户的信息流排除了其屏蔽的作者，不能被共享缓存，内容也随 Authorization 变化
+    c.Header("Vary", "Authorization")
+    cacheScope := "public"
+    if userID != 0 {
+        cacheScope = "private"
+    }
+    c.Header("Cache-Control", fmt.Sprintf("%s, max-age=%d", cacheScope, feedCacheMaxAge))
+    if utils.ETagMatches(c.GetHeader("If-None-Match"), etag) {
+        c.Status(http.StatusNotModified)
+        return
+    }
+    c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
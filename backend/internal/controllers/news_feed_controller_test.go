@@ -0,0 +1,110 @@
+// internal/controllers/news_feed_controller_test.go
+package controllers
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+func TestNewsFeed(t *testing.T) {
+    db := setupNewsTestDB()
+    assert.NoError(t, db.AutoMigrate(&models.News{}))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    newsController := NewNewsController(db)
+    router.GET("/news/feed", newsController.GetNewsFeed)
+
+    var newsIDs []uint
+    base := time.Now().Add(-time.Hour)
+    for i := 0; i < 12; i++ {
+        author := models.User{OpenID: fmt.Sprintf("OpenID_Feed_Author_%d", i), Nickname: fmt.Sprintf("author%d", i)}
+        db.Create(&author)
+        news := models.News{Title: fmt.Sprintf("News %d", i), AuthorID: author.ID, LikeCount: i % 3, UploadTime: base.Add(time.Duration(i) * time.Minute)}
+        db.Create(&news)
+        db.Create(&models.Paragraph{NewsID: news.ID, Text: fmt.Sprintf("paragraph %d", i)})
+        db.Create(&models.NewsImage{NewsID: news.ID, URL: fmt.Sprintf("/static/images/news/%d.jpg", i), Description: "image"})
+        newsIDs = append(newsIDs, news.ID)
+    }
+
+    request := func(url, ifNoneMatch string) *httptest.ResponseRecorder {
+        req, _ := http.NewRequest("GET", url, nil)
+        if ifNoneMatch != "" {
+            req.Header.Set("If-None-Match", ifNoneMatch)
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+    previewIDs := func(resp NewsFeedResponse) []uint {
+        ids := make([]uint, len(resp.Previews))
+        for i, p := range resp.Previews {
+            ids[i] = p.ID
+        }
+        return ids
+    }
+
+    t.Run("Pages", func(t *testing.T) {
+        // 查询次数与每页新闻数无关
+        queries := 0
+        db.Callback().Query().After("gorm:query").Register("count_feed_queries", func(tx *gorm.DB) { queries++ })
+        w := request("/news/feed", "")
+        db.Callback().Query().Remove("count_feed_queries")
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.Equal(t, 5, queries) // 新闻、作者、段落、图片、图片尺寸
+
+        var resp NewsFeedResponse
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        assert.True(t, resp.HasMore)
+        if assert.Len(t, resp.Previews, 10) {
+            first := resp.Previews[0]
+            assert.Equal(t, newsIDs[11], first.ID)
+            assert.Equal(t, "author11", first.AuthorNickname)
+            assert.Equal(t, "paragraph 11", first.FirstParagraphText)
+            assert.Equal(t, "/static/images/news/11.jpg", first.FirstImageURL)
+        }
+
+        w = request("/news/feed?page=2", "")
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        assert.False(t, resp.HasMore)
+        assert.Equal(t, []uint{newsIDs[1], newsIDs[0]}, previewIDs(resp))
+
+        // 点赞数相同时按 ID 降序
+        w = request("/news/feed?sort=like_count&page=1", "")
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        assert.Equal(t, []uint{newsIDs[11], newsIDs[8], newsIDs[5], newsIDs[2]}, previewIDs(resp)[:4])
+
+        assert.Equal(t, http.StatusBadRequest, request("/news/feed?sort=title", "").Code)
+        assert.Equal(t, http.StatusBadRequest, request("/news/feed?page=0", "").Code)
+    })
+
+    t.Run("ETag", func(t *testing.T) {
+        w := request("/news/feed", "")
+        etag := w.Header().Get("ETag")
+        assert.True(t, strings.HasPrefix(etag, `W/"`), etag)
+        assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+        assert.Equal(t, "Authorization", w.Header().Get("Vary"))
+
+        w = request("/news/feed", etag)
+        assert.Equal(t, http.StatusNotModified, w.Code)
+        assert.Empty(t, w.Body.String())
+        assert.Equal(t, etag, w.Header().Get("ETag"))
+        assert.Equal(t, http.StatusNotModified, request("/news/feed", `"other", `+strings.TrimPrefix(etag, "W/")).Code)
+
+        // 内容变化后 ETag 随之变化
+        db.Model(&models.News{}).Where("id = ?", newsIDs[11]).Update("title", "Renamed")
+        w = request("/news/feed", etag)
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.NotEqual(t, etag, w.Header().Get("ETag"))
+    })
+}
@@ -0,0 +1,103 @@
+// internal/controllers/news_public_test.go
+package controllers
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/ratelimit"
+)
+
+func TestPublicNewsAccess(t *testing.T) {
+    db := setupNewsTestDB()
+    assert.NoError(t, db.AutoMigrate(&models.News{}, &models.Comment{}))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    newsController := NewNewsController(db)
+    publicGroup := router.Group("/news")
+    publicGroup.Use(middleware.OptionalAuthMiddleware(), middleware.AnonymousRateLimitMiddleware(ratelimit.Limit{RequestsPerMinute: 60, Burst: 3}))
+    {
+        publicGroup.GET("/details/news/:id", newsController.GetNewsDetails)
+        publicGroup.GET("/paginated/upload_time", newsController.GetNewsByUploadTime)
+        publicGroup.GET("/:id/comments", newsController.GetNewsComments)
+    }
+
+    author := models.User{OpenID: "OpenID_Public_Author", Nickname: "Author"}
+    db.Create(&author)
+    news := models.News{Title: "Public News", AuthorID: author.ID}
+    db.Create(&news)
+    comment := models.Comment{NewsID: news.ID, UserID: author.ID, Content: "first"}
+    db.Create(&comment)
+    db.Exec("INSERT INTO user_likes_comments (user_id, comment_id) VALUES (?, ?)", author.ID, comment.ID)
+
+    request := func(url, ip, token string) *httptest.ResponseRecorder {
+        req, _ := http.NewRequest("GET", url, nil)
+        req.RemoteAddr = ip + ":12345"
+        if token != "" {
+            req.Header.Set("Authorization", token)
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+    detailsURL := fmt.Sprintf("/news/details/news/%d", news.ID)
+
+    t.Run("Anonymous Read", func(t *testing.T) {
+        w := request(detailsURL, "10.0.0.1", "")
+        assert.Equal(t, http.StatusOK, w.Code)
+        var detail NewsDetailResponse
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+        assert.Equal(t, "Public News", detail.Title)
+        if assert.Len(t, detail.Comments, 1) {
+            assert.Nil(t, detail.Comments[0].DidLike)
+        }
+        assert.NotContains(t, w.Body.String(), "did_like")
+
+        w = request(fmt.Sprintf("/news/%d/comments", news.ID), "10.0.0.1", "")
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.NotContains(t, w.Body.String(), "did_like")
+
+        w = request("/news/paginated/upload_time?page=1", "10.0.0.1", "")
+        assert.Equal(t, http.StatusOK, w.Code)
+        assert.JSONEq(t, fmt.Sprintf(`{"news_ids":[%d]}`, news.ID), w.Body.String())
+    })
+
+    t.Run("Authenticated Read", func(t *testing.T) {
+        w := request(detailsURL, "10.0.0.2", "Bearer "+generateValidJWTNews(author.ID))
+        assert.Equal(t, http.StatusOK, w.Code)
+        var detail NewsDetailResponse
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+        if assert.Len(t, detail.Comments, 1) && assert.NotNil(t, detail.Comments[0].DidLike) {
+            assert.True(t, *detail.Comments[0].DidLike)
+        }
+
+        // 提供了无效令牌时不降级为匿名访问
+        w = request(detailsURL, "10.0.0.2", "Bearer invalid")
+        assert.Equal(t, http.StatusUnauthorized, w.Code)
+        w = request(detailsURL, "10.0.0.2", "Token abc")
+        assert.Equal(t, http.StatusUnauthorized, w.Code)
+        assert.Contains(t, w.Body.String(), "Invalid authorization header format")
+    })
+
+    t.Run("Rate Limit Per IP", func(t *testing.T) {
+        for i := 0; i < 3; i++ {
+            assert.Equal(t, http.StatusOK, request(detailsURL, "10.0.0.3", "").Code)
+        }
+        w := request(detailsURL, "10.0.0.3", "")
+        assert.Equal(t, http.StatusTooManyRequests, w.Code)
+        assert.JSONEq(t, `{"error":"Too many requests"}`, w.Body.String())
+        assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+        // 其他 IP 和已登录用户不受影响
+        assert.Equal(t, http.StatusOK, request(detailsURL, "10.0.0.4", "").Code)
+        assert.Equal(t, http.StatusOK, request(detailsURL, "10.0.0.3", "Bearer "+generateValidJWTNews(author.ID)).Code)
+    })
+}
@@ -0,0 +1,603 @@
+// internal/controllers/news_revision_controller.go
+package controllers
+
+import (
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+// RevisionItem 定义历史版本列表中的单项
+type RevisionItem struct {
+    ID        uint      `json:"id"`
+    Version   int       `json:"version"`
+    Title     string    `json:"title"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// RevisionDiffResponse 定义两个版本之间差异的响应结构
+type RevisionDiffResponse struct {
+    From         string         `json:"from"`
+    To           string         `json:"to"`
+    TitleChanged bool           `json:"title_changed"`
+    FromTitle    string         `json:"from_title"`
+    ToTitle      string         `json:"to_title"`
+    Paragraphs   []utils.DiffOp `json:"paragraphs"`
+    Images       []utils.DiffOp `json:"images"`
+    Blocks       []utils.DiffOp `json:"blocks"`
+}
+
+// ReviseNewsRequest 定义修订已发布新闻的请求结构
+type ReviseNewsRequest struct {
+    Title             string                `json:"title"`
+    Paragraphs        []string              `json:"paragraphs"`
+    ImageDescriptions []string              `json:"image_descriptions"`
+    ImagePaths        []string              `json:"image_paths"`
+    Blocks            []models.ContentBlock `json:"blocks"` // 提供时以正文块为准，忽略段落和图片字段
+}
+
+// ScheduleDraftRequest 定义设置定时发布的请求结构
+type ScheduleDraftRequest struct {
+    PublishAt time.Time `json:"publish_at" binding:"required"`
+}
+
+// saveDraftRevision 将草稿（需预加载段落、图片和正文块）的当前内容保存为新的历史版本。
+// 先锁定草稿行，同一草稿的并发保存依次分配版本号；(draft_id, version) 上的唯一索引兜底
+func saveDraftRevision(tx *gorm.DB, draft *models.Draft) error {
+    if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+        Where("id = ?", draft.ID).Limit(1).Find(&models.Draft{}).Error; err != nil {
+        return err
+    }
+
+    var latest int
+    if err := tx.Model(&models.DraftRevision{}).
+        Where("draft_id = ?", draft.ID).
+        Select("COALESCE(MAX(version), 0)").
+        Scan(&latest).Error; err != nil {
+        return err
+    }
+
+    content, err := models.EncodeRevisionContent(models.ContentOfDraft(draft))
+    if err != nil {
+        return err
+    }
+
+    revision := models.DraftRevision{
+        DraftID:   draft.ID,
+        Version:   latest + 1,
+        Title:     draft.Title,
+        Content:   content,
+        CreatedAt: time.Now(),
+    }
+    return tx.Create(&revision).Error
+}
+
+// saveNewsRevision 将新闻（需预加载段落、图片和正文块）的当前内容保存为新的历史版本，与 saveDraftRevision 一样先锁定新闻行
+func saveNewsRevision(tx *gorm.DB, news *models.News, editorID uint) error {
+    if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+        Where("id = ?", news.ID).Limit(1).Find(&models.News{}).Error; err != nil {
+        return err
+    }
+
+    var latest int
+    if err := tx.Model(&models.NewsRevision{}).
+        Where("news_id = ?", news.ID).
+        Select("COALESCE(MAX(version), 0)").
+        Scan(&latest).Error; err != nil {
+        return err
+    }
+
+    content, err := models.EncodeRevisionContent(models.ContentOfNews(news))
+    if err != nil {
+        return err
+    }
+
+    revision := models.NewsRevision{
+        NewsID:    news.ID,
+        Version:   latest + 1,
+        Title:     news.Title,
+        Content:   content,
+        EditorID:  editorID,
+        CreatedAt: time.Now(),
+    }
+    return tx.Create(&revision).Error
+}
+
+// errDraftGone 草稿在读取后已被发布或删除
+var errDraftGone = errors.New("Draft not found")
+
+// replaceDraftContent 用给定内容替换草稿的标题、段落、图片和正文块（早期快照没有正文块时由段落和图片生成）。
+// 先更新草稿本体，草稿已被定时任务发布或删除时返回 errDraftGone，事务回滚，不会留下无主的段落和图片
+func replaceDraftContent(tx *gorm.DB, draft *models.Draft, title string, content models.RevisionContent) error {
+    // 使用无关联的模型更新，避免 GORM 回写已预加载的旧段落和图片
+    result := tx.Model(&models.Draft{}).Where("id = ?", draft.ID).Updates(map[string]interface{}{
+        "title":      title,
+        "updated_at": time.Now(),
+    })
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return errDraftGone
+    }
+
+    if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftParagraph{}).Error; err != nil {
+        return err
+    }
+    if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftImage{}).Error; err != nil {
+        return err
+    }
+    if err := tx.Where("draft_id = ?", draft.ID).Delete(&models.DraftBlock{}).Error; err != nil {
+        return err
+    }
+
+    paragraphs, images, blocks := draftRowsOf(content.WithBlocks())
+    for i := range paragraphs {
+        paragraphs[i].DraftID = draft.ID
+        if err := tx.Create(&paragraphs[i]).Error; err != nil {
+            return err
+        }
+    }
+    for i := range images {
+        images[i].DraftID = draft.ID
+        if err := tx.Create(&images[i]).Error; err != nil {
+            return err
+        }
+    }
+    for i := range blocks {
+        blocks[i].DraftID = draft.ID
+        if err := tx.Create(&blocks[i]).Error; err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// findOwnedDraft 查找属于当前用户的草稿，失败时直接写入响应并返回 false
+func (nc *NewsController) findOwnedDraft(c *gin.Context, userID uint) (*models.Draft, bool) {
+    draftID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid draft ID"))
+        return nil, false
+    }
+
+    var draft models.Draft
+    if err := nc.DB.Preload("Paragraphs").Preload("Images").Preload("Blocks", models.OrderedBlocks).First(&draft, draftID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrDraftNotFound)
+            return nil, false
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find draft"))
+        return nil, false
+    }
+
+    if draft.AuthorID != userID {
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You do not have permission to access this draft"))
+        return nil, false
+    }
+    return &draft, true
+}
+
+// GetDraftRevisions 获取草稿的历史版本列表（按版本号降序）
+func (nc *NewsController) GetDraftRevisions(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    draft, ok := nc.findOwnedDraft(c, userID.(uint))
+    if !ok {
+        return
+    }
+
+    var revisions []models.DraftRevision
+    if err := nc.DB.Where("draft_id = ?", draft.ID).Order("version DESC").Find(&revisions).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch revisions"))
+        return
+    }
+
+    items := make([]RevisionItem, 0, len(revisions))
+    for _, r := range revisions {
+        items = append(items, RevisionItem{
+            ID:        r.ID,
+            Version:   r.Version,
+            Title:     r.Title,
+            CreatedAt: r.CreatedAt,
+        })
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "draft_id":  draft.ID,
+        "revisions": items,
+    })
+}
+
+// loadDraftVersion 读取草稿指定版本的标题和内容，version 为 "current" 时返回当前内容
+func (nc *NewsController) loadDraftVersion(draft *models.Draft, version string) (string, models.RevisionContent, error) {
+    if version == "" || version == "current" {
+        return draft.Title, models.ContentOfDraft(draft), nil
+    }
+
+    v, err := strconv.Atoi(version)
+    if err != nil {
+        return "", models.RevisionContent{}, err
+    }
+
+    var revision models.DraftRevision
+    if err := nc.DB.Where("draft_id = ? AND version = ?", draft.ID, v).First(&revision).Error; err != nil {
+        return "", models.RevisionContent{}, err
+    }
+
+    content, err := models.DecodeRevisionContent(revision.Content)
+    return revision.Title, content, err
+}
+
+// imageLines 将图片列表转为逐行比较的文本
+func imageLines(images []models.RevisionImage) []string {
+    lines := make([]string, 0, len(images))
+    for _, img := range images {
+        lines = append(lines, img.URL+" "+img.Description)
+    }
+    return lines
+}
+
+// DiffDraftRevisions 比较草稿的两个版本，from 与 to 为版本号或 "current"
+func (nc *NewsController) DiffDraftRevisions(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    draft, ok := nc.findOwnedDraft(c, userID.(uint))
+    if !ok {
+        return
+    }
+
+    from := c.Query("from")
+    to := c.DefaultQuery("to", "current")
+    if from == "" {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Query parameter 'from' is required"))
+        return
+    }
+
+    fromTitle, fromContent, err := nc.loadDraftVersion(draft, from)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("Revision not found"))
+        return
+    }
+    toTitle, toContent, err := nc.loadDraftVersion(draft, to)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("Revision not found"))
+        return
+    }
+
+    c.JSON(http.StatusOK, RevisionDiffResponse{
+        From:         from,
+        To:           to,
+        TitleChanged: fromTitle != toTitle,
+        FromTitle:    fromTitle,
+        ToTitle:      toTitle,
+        Paragraphs:   utils.DiffLines(fromContent.Paragraphs, toContent.Paragraphs),
+        Images:       utils.DiffLines(imageLines(fromContent.Images), imageLines(toContent.Images)),
+        Blocks:       utils.DiffLines(blockLines(fromContent.WithBlocks().Blocks), blockLines(toContent.WithBlocks().Blocks)),
+    })
+}
+
+// RestoreDraftRevision 将草稿恢复到指定版本，恢复前的内容会保存为新的历史版本
+func (nc *NewsController) RestoreDraftRevision(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    draft, ok := nc.findOwnedDraft(c, userID.(uint))
+    if !ok {
+        return
+    }
+
+    version, err := strconv.Atoi(c.Param("version"))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid revision version"))
+        return
+    }
+
+    var revision models.DraftRevision
+    if err := nc.DB.Where("draft_id = ? AND version = ?", draft.ID, version).First(&revision).Error; err != nil {
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("Revision not found"))
+        return
+    }
+
+    content, err := models.DecodeRevisionContent(revision.Content)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to decode revision"))
+        return
+    }
+
+    if err := nc.DB.Transaction(func(tx *gorm.DB) error {
+        if err := saveDraftRevision(tx, draft); err != nil {
+            return err
+        }
+        return replaceDraftContent(tx, draft, revision.Title, content)
+    }); errors.Is(err, errDraftGone) {
+        apierror.Respond(c, apierror.ErrDraftNotFound)
+        return
+    } else if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to restore revision"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message":  "Draft restored successfully",
+        "draft_id": draft.ID,
+        "version":  revision.Version,
+    })
+}
+
+// ScheduleDraft 设置草稿的定时发布时间
+func (nc *NewsController) ScheduleDraft(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    draft, ok := nc.findOwnedDraft(c, userID.(uint))
+    if !ok {
+        return
+    }
+
+    var request ScheduleDraftRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+
+    if !request.PublishAt.After(time.Now()) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Publish time must be in the future"))
+        return
+    }
+
+    publishAt := request.PublishAt.UTC()
+    if err := nc.DB.Model(&models.Draft{}).Where("id = ?", draft.ID).Update("scheduled_at", &publishAt).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to schedule draft"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message":      "Draft scheduled successfully",
+        "draft_id":     draft.ID,
+        "scheduled_at": publishAt,
+    })
+}
+
+// CancelScheduleDraft 取消草稿的定时发布
+func (nc *NewsController) CancelScheduleDraft(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    draft, ok := nc.findOwnedDraft(c, userID.(uint))
+    if !ok {
+        return
+    }
+
+    if draft.ScheduledAt == nil {
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("Draft is not scheduled"))
+        return
+    }
+
+    if err := nc.DB.Model(&models.Draft{}).Where("id = ?", draft.ID).Update("scheduled_at", nil).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to cancel schedule"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Draft schedule canceled successfully"})
+}
+
+// PublishDueDrafts 发布所有已到定时发布时间的草稿，返回成功发布的数量（供后台调度器调用）。
+// 每个草稿在发布事务中先认领，调度重叠或多个实例同时运行时同一草稿只发布一次
+func (nc *NewsController) PublishDueDrafts(now time.Time) (int, error) {
+    var draftIDs []uint
+    if err := nc.DB.Model(&models.Draft{}).
+        Where("scheduled_at IS NOT NULL AND scheduled_at <= ?", now.UTC()).
+        Pluck("id", &draftIDs).Error; err != nil {
+        return 0, err
+    }
+
+    published := 0
+    for _, draftID := range draftIDs {
+        var news *models.News
+        if err := nc.DB.Transaction(func(tx *gorm.DB) error {
+            claimed, err := claimScheduledDraft(tx, draftID, now)
+            if err != nil || !claimed {
+                return err
+            }
+
+            // 认领后重新读取草稿，按最新内容发布
+            var draft models.Draft
+            if err := tx.Preload("Paragraphs").Preload("Images").Preload("Blocks", models.OrderedBlocks).
+                First(&draft, draftID).Error; err != nil {
+                return err
+            }
+            news, err = publishDraft(tx, &draft)
+            return err
+        }); err != nil {
+            log.Printf("定时发布草稿 %d 失败: %v", draftID, err)
+            continue
+        }
+        if news == nil {
+            // 已被其他调度发布、取消定时或删除
+            continue
+        }
+        log.Printf("定时发布草稿 %d 成功，新闻 ID: %d", draftID, news.ID)
+        published++
+    }
+    return published, nil
+}
+
+// claimScheduledDraft 清空已到发布时间的草稿的 scheduled_at 以认领发布，草稿已被认领、取消定时或删除时返回 false
+func claimScheduledDraft(tx *gorm.DB, draftID uint, now time.Time) (bool, error) {
+    result := tx.Model(&models.Draft{}).
+        Where("id = ? AND scheduled_at IS NOT NULL AND scheduled_at <= ?", draftID, now.UTC()).
+        Update("scheduled_at", nil)
+    if result.Error != nil {
+        return false, result.Error
+    }
+    return result.RowsAffected > 0, nil
+}
+
+// ReviseNews 修订已发布的新闻：保存当前内容为历史版本后原地替换正文，保留互动数据和评论
+func (nc *NewsController) ReviseNews(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    newsID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
+        return
+    }
+
+    var news models.News
+    if err := nc.DB.Preload("Paragraphs").Preload("Images").Preload("Blocks", models.OrderedBlocks).First(&news, newsID).Error; err != nil {
+        apierror.Respond(c, apierror.ErrNewsNotFound)
+        return
+    }
+
+    if news.AuthorID != userID.(uint) {
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You do not have permission to edit this news"))
+        return
+    }
+
+    var request ReviseNewsRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("Invalid request data"))
+        return
+    }
+    if request.Title == "" {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Title is required"))
+        return
+    }
+    if len(request.ImageDescriptions) != len(request.ImagePaths) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Number of image descriptions and image paths do not match"))
+        return
+    }
+
+    content, err := nc.resolveContent(request.Blocks, request.Paragraphs, request.ImageDescriptions, request.ImagePaths)
+    if err != nil {
+        respondContentError(c, err)
+        return
+    }
+    paragraphs, images, blocks := newsRowsOf(content)
+
+    if err := nc.DB.Transaction(func(tx *gorm.DB) error {
+        if err := saveNewsRevision(tx, &news, userID.(uint)); err != nil {
+            return err
+        }
+
+        if err := tx.Where("news_id = ?", news.ID).Delete(&models.Paragraph{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("news_id = ?", news.ID).Delete(&models.NewsImage{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("news_id = ?", news.ID).Delete(&models.NewsBlock{}).Error; err != nil {
+            return err
+        }
+
+        for i := range paragraphs {
+            paragraphs[i].NewsID = news.ID
+            if err := tx.Create(&paragraphs[i]).Error; err != nil {
+                return err
+            }
+        }
+        for i := range images {
+            images[i].NewsID = news.ID
+            if err := tx.Create(&images[i]).Error; err != nil {
+                return err
+            }
+        }
+        for i := range blocks {
+            blocks[i].NewsID = news.ID
+            if err := tx.Create(&blocks[i]).Error; err != nil {
+                return err
+            }
+        }
+
+        now := time.Now()
+        return tx.Model(&models.News{}).Where("id = ?", news.ID).Updates(map[string]interface{}{
+            "title":     request.Title,
+            "edited_at": &now,
+        }).Error
+    }); err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to revise news"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "News revised successfully",
+        "news_id": news.ID,
+    })
+}
+
+// GetNewsRevisions 获取新闻的历史版本列表，仅作者可见
+func (nc *NewsController) GetNewsRevisions(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    newsID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
+        return
+    }
+
+    var news models.News
+    if err := nc.DB.Select("id", "author_id").First(&news, newsID).Error; err != nil {
+        apierror.Respond(c, apierror.ErrNewsNotFound)
+        return
+    }
+    if news.AuthorID != userID.(uint) {
+        apierror.Respond(c, apierror.ErrForbidden.WithMessage("You do not have permission to view revisions of this news"))
+        return
+    }
+
+    var revisions []models.NewsRevision
+    if err := nc.DB.Where("news_id = ?", news.ID).Order("version DESC").Find(&revisions).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch revisions"))
+        return
+    }
+
+    items := make([]RevisionItem, 0, len(revisions))
+    for _, r := range revisions {
+        items = append(items, RevisionItem{
+            ID:        r.ID,
+            Version:   r.Version,
+            Title:     r.Title,
+            CreatedAt: r.CreatedAt,
+        })
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "news_id":   news.ID,
+        "revisions": items,
+    })
+}
@@ -0,0 +1,335 @@
+// controllers/news_revision_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+)
+
+// setupNewsRevisionRouter 初始化修订与定时发布相关的路由
+func setupNewsRevisionRouter(db *gorm.DB) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.Default()
+    newsController := NewNewsController(db)
+
+    newsGroup := router.Group("/news")
+    newsGroup.Use(middleware.AuthMiddleware())
+    {
+        newsGroup.PUT("/drafts/:id", newsController.UpdateDraft)
+        newsGroup.GET("/drafts/:id/revisions", newsController.GetDraftRevisions)
+        newsGroup.GET("/drafts/:id/revisions/diff", newsController.DiffDraftRevisions)
+        newsGroup.POST("/drafts/:id/revisions/:version/restore", newsController.RestoreDraftRevision)
+        newsGroup.PUT("/drafts/:id/schedule", newsController.ScheduleDraft)
+        newsGroup.DELETE("/drafts/:id/schedule", newsController.CancelScheduleDraft)
+        newsGroup.PUT("/:id", newsController.ReviseNews)
+        newsGroup.GET("/:id/revisions", newsController.GetNewsRevisions)
+    }
+    return router
+}
+
+// doRevisionRequest 发送带认证的 JSON 请求并解析响应
+func doRevisionRequest(router *gin.Engine, method, path string, userID uint, body interface{}) (int, map[string]interface{}) {
+    var reader *bytes.Buffer
+    if body != nil {
+        bodyBytes, _ := json.Marshal(body)
+        reader = bytes.NewBuffer(bodyBytes)
+    } else {
+        reader = bytes.NewBuffer(nil)
+    }
+
+    req, _ := http.NewRequest(method, path, reader)
+    req.Header.Set("Content-Type", "application/json")
+    if userID != 0 {
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+    }
+
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+
+    var resp map[string]interface{}
+    _ = json.Unmarshal(w.Body.Bytes(), &resp)
+    return w.Code, resp
+}
+
+func TestDraftRevisions(t *testing.T) {
+    db := setupNewsTestDB()
+    _ = db.AutoMigrate(&models.News{})
+    router := setupNewsRevisionRouter(db)
+
+    owner := models.User{OpenID: "OpenID_Revision_Owner", Nickname: "RevisionOwner"}
+    db.Create(&owner)
+    other := models.User{OpenID: "OpenID_Revision_Other", Nickname: "RevisionOther"}
+    db.Create(&other)
+
+    draft := models.Draft{Title: "Version 1", AuthorID: owner.ID}
+    db.Create(&draft)
+    db.Create(&models.DraftParagraph{DraftID: draft.ID, Text: "First paragraph"})
+    db.Create(&models.DraftParagraph{DraftID: draft.ID, Text: "Second paragraph"})
+    draftPath := fmt.Sprintf("/news/drafts/%d", draft.ID)
+
+    // 更新草稿两次，每次都会保存旧内容
+    status, resp := doRevisionRequest(router, "PUT", draftPath, owner.ID, gin.H{
+        "title":      "Version 2",
+        "paragraphs": []string{"First paragraph", "Changed paragraph"},
+    })
+    assert.Equal(t, http.StatusCreated, status)
+    assert.Equal(t, float64(draft.ID), resp["draft_id"])
+
+    status, _ = doRevisionRequest(router, "PUT", draftPath, owner.ID, gin.H{
+        "title":      "Version 3",
+        "paragraphs": []string{"Only paragraph"},
+    })
+    assert.Equal(t, http.StatusCreated, status)
+
+    t.Run("List Revisions", func(t *testing.T) {
+        status, resp := doRevisionRequest(router, "GET", draftPath+"/revisions", owner.ID, nil)
+        assert.Equal(t, http.StatusOK, status)
+        revisions := resp["revisions"].([]interface{})
+        assert.Len(t, revisions, 2)
+        latest := revisions[0].(map[string]interface{})
+        assert.Equal(t, float64(2), latest["version"])
+        assert.Equal(t, "Version 2", latest["title"])
+    })
+
+    t.Run("Other User Forbidden", func(t *testing.T) {
+        status, resp := doRevisionRequest(router, "GET", draftPath+"/revisions", other.ID, nil)
+        assert.Equal(t, http.StatusForbidden, status)
+        assert.Equal(t, "You do not have permission to access this draft", resp["error"])
+    })
+
+    t.Run("Diff Against Current", func(t *testing.T) {
+        status, resp := doRevisionRequest(router, "GET", draftPath+"/revisions/diff?from=1", owner.ID, nil)
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, true, resp["title_changed"])
+        assert.Equal(t, "Version 1", resp["from_title"])
+        assert.Equal(t, "Version 3", resp["to_title"])
+
+        ops := resp["paragraphs"].([]interface{})
+        assert.Len(t, ops, 3)
+        assert.Equal(t, "delete", ops[0].(map[string]interface{})["op"])
+    })
+
+    t.Run("Diff Missing Revision", func(t *testing.T) {
+        status, resp := doRevisionRequest(router, "GET", draftPath+"/revisions/diff?from=42", owner.ID, nil)
+        assert.Equal(t, http.StatusNotFound, status)
+        assert.Equal(t, "Revision not found", resp["error"])
+    })
+
+    t.Run("Restore Revision", func(t *testing.T) {
+        status, resp := doRevisionRequest(router, "POST", draftPath+"/revisions/1/restore", owner.ID, nil)
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, "Draft restored successfully", resp["message"])
+
+        var restored models.Draft
+        db.Preload("Paragraphs").First(&restored, draft.ID)
+        assert.Equal(t, "Version 1", restored.Title)
+        assert.Len(t, restored.Paragraphs, 2)
+        assert.Equal(t, "Second paragraph", restored.Paragraphs[1].Text)
+
+        // 恢复前的内容被保存为第 3 个版本
+        var count int64
+        db.Model(&models.DraftRevision{}).Where("draft_id = ?", draft.ID).Count(&count)
+        assert.Equal(t, int64(3), count)
+        // 同一草稿的版本号唯一，并发保存不会产生重复的版本
+        assert.Error(t, db.Create(&models.DraftRevision{DraftID: draft.ID, Version: 3, Title: "Duplicate"}).Error)
+    })
+}
+
+func TestScheduleDraft(t *testing.T) {
+    db := setupNewsTestDB()
+    _ = db.AutoMigrate(&models.News{})
+    router := setupNewsRevisionRouter(db)
+
+    owner := models.User{OpenID: "OpenID_Schedule_Owner", Nickname: "ScheduleOwner"}
+    db.Create(&owner)
+
+    draft := models.Draft{Title: "Scheduled Draft", AuthorID: owner.ID}
+    db.Create(&draft)
+    db.Create(&models.DraftParagraph{DraftID: draft.ID, Text: "Scheduled paragraph"})
+    schedulePath := fmt.Sprintf("/news/drafts/%d/schedule", draft.ID)
+
+    tests := []struct {
+        name           string
+        method         string
+        requestBody    interface{}
+        expectedStatus int
+        expectedBody   map[string]interface{}
+    }{
+        {
+            name:           "Cancel Without Schedule",
+            method:         "DELETE",
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   map[string]interface{}{"error": "Draft is not scheduled"},
+        },
+        {
+            name:           "Invalid Request Body",
+            method:         "PUT",
+            requestBody:    "not_json",
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   map[string]interface{}{"error": "Invalid request body"},
+        },
+        {
+            name:           "Publish Time In The Past",
+            method:         "PUT",
+            requestBody:    gin.H{"publish_at": time.Now().Add(-time.Hour)},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   map[string]interface{}{"error": "Publish time must be in the future"},
+        },
+        {
+            name:           "Success Schedule",
+            method:         "PUT",
+            requestBody:    gin.H{"publish_at": time.Now().Add(time.Hour)},
+            expectedStatus: http.StatusOK,
+            expectedBody:   map[string]interface{}{"message": "Draft scheduled successfully"},
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            status, resp := doRevisionRequest(router, tc.method, schedulePath, owner.ID, tc.requestBody)
+            assert.Equal(t, tc.expectedStatus, status)
+            for k, v := range tc.expectedBody {
+                assert.Equal(t, v, resp[k])
+            }
+        })
+    }
+
+    t.Run("Publish Due Drafts", func(t *testing.T) {
+        nc := NewNewsController(db)
+
+        // 尚未到发布时间
+        published, err := nc.PublishDueDrafts(time.Now())
+        assert.NoError(t, err)
+        assert.Equal(t, 0, published)
+
+        // 到达发布时间后草稿被转换为新闻
+        published, err = nc.PublishDueDrafts(time.Now().Add(2 * time.Hour))
+        assert.NoError(t, err)
+        assert.Equal(t, 1, published)
+
+        var news models.News
+        assert.NoError(t, db.Preload("Paragraphs").Where("title = ?", "Scheduled Draft").First(&news).Error)
+        assert.Len(t, news.Paragraphs, 1)
+
+        var draftCount int64
+        db.Model(&models.Draft{}).Where("id = ?", draft.ID).Count(&draftCount)
+        assert.Equal(t, int64(0), draftCount)
+    })
+
+    t.Run("Claim Scheduled Draft Once", func(t *testing.T) {
+        publishAt := time.Now().Add(-time.Minute)
+        due := models.Draft{Title: "Claimed Draft", AuthorID: owner.ID, ScheduledAt: &publishAt}
+        db.Create(&due)
+
+        // 只有一次认领成功，其他调度随后不会再发布同一草稿
+        claimed, err := claimScheduledDraft(db, due.ID, time.Now())
+        assert.NoError(t, err)
+        assert.True(t, claimed)
+        claimed, err = claimScheduledDraft(db, due.ID, time.Now())
+        assert.NoError(t, err)
+        assert.False(t, claimed)
+
+        published, err := NewNewsController(db).PublishDueDrafts(time.Now())
+        assert.NoError(t, err)
+        assert.Equal(t, 0, published)
+        var newsCount int64
+        db.Model(&models.News{}).Where("title = ?", "Claimed Draft").Count(&newsCount)
+        assert.Equal(t, int64(0), newsCount)
+
+        // 尚未到发布时间的草稿不能被认领
+        future := time.Now().Add(time.Hour)
+        pending := models.Draft{Title: "Pending Draft", AuthorID: owner.ID, ScheduledAt: &future}
+        db.Create(&pending)
+        claimed, err = claimScheduledDraft(db, pending.ID, time.Now())
+        assert.NoError(t, err)
+        assert.False(t, claimed)
+    })
+}
+
+func TestReviseNews(t *testing.T) {
+    db := setupNewsTestDB()
+    _ = db.AutoMigrate(&models.News{})
+    router := setupNewsRevisionRouter(db)
+
+    author := models.User{OpenID: "OpenID_Revise_Author", Nickname: "ReviseAuthor"}
+    db.Create(&author)
+    other := models.User{OpenID: "OpenID_Revise_Other", Nickname: "ReviseOther"}
+    db.Create(&other)
+
+    news := models.News{Title: "Original Title", AuthorID: author.ID, LikeCount: 5, UploadTime: time.Now()}
+    db.Create(&news)
+    db.Create(&models.Paragraph{NewsID: news.ID, Text: "Original paragraph"})
+    newsPath := fmt.Sprintf("/news/%d", news.ID)
+
+    tests := []struct {
+        name           string
+        userID         uint
+        requestBody    interface{}
+        expectedStatus int
+        expectedBody   map[string]interface{}
+    }{
+        {
+            name:           "No Permission",
+            userID:         other.ID,
+            requestBody:    gin.H{"title": "Hijacked"},
+            expectedStatus: http.StatusForbidden,
+            expectedBody:   map[string]interface{}{"error": "You do not have permission to edit this news"},
+        },
+        {
+            name:           "Title Is Required",
+            userID:         author.ID,
+            requestBody:    gin.H{"title": ""},
+            expectedStatus: http.StatusBadRequest,
+            expectedBody:   map[string]interface{}{"error": "Title is required"},
+        },
+        {
+            name:   "Success Revise",
+            userID: author.ID,
+            requestBody: gin.H{
+                "title":              "Revised Title",
+                "paragraphs":         []string{"Revised paragraph"},
+                "image_descriptions": []string{"cover"},
+                "image_paths":        []string{"drafts/1/cover.jpg"},
+            },
+            expectedStatus: http.StatusOK,
+            expectedBody:   map[string]interface{}{"message": "News revised successfully"},
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            status, resp := doRevisionRequest(router, "PUT", newsPath, tc.userID, tc.requestBody)
+            assert.Equal(t, tc.expectedStatus, status)
+            for k, v := range tc.expectedBody {
+                assert.Equal(t, v, resp[k])
+            }
+        })
+    }
+
+    // 修订后保留 ID 与互动数据
+    var revised models.News
+    db.Preload("Paragraphs").Preload("Images").First(&revised, news.ID)
+    assert.Equal(t, "Revised Title", revised.Title)
+    assert.Equal(t, 5, revised.LikeCount)
+    assert.NotNil(t, revised.EditedAt)
+    assert.Len(t, revised.Paragraphs, 1)
+    assert.Len(t, revised.Images, 1)
+
+    status, resp := doRevisionRequest(router, "GET", newsPath+"/revisions", author.ID, nil)
+    assert.Equal(t, http.StatusOK, status)
+    revisions := resp["revisions"].([]interface{})
+    assert.Len(t, revisions, 1)
+    assert.Error(t, db.Create(&models.NewsRevision{NewsID: news.ID, Version: 1, Title: "Duplicate"}).Error)
+    assert.Equal(t, "Original Title", revisions[0].(map[string]interface{})["title"])
+}
@@ -0,0 +1,299 @@
+// internal/controllers/news_share_controller.go
+package controllers
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+// newsDetailPath 小程序新闻详情页
+const newsDetailPath = "/pagesNews/news_detail/news_detail"
+
+// ShareCard 小程序分享卡片所需的数据
+type ShareCard struct {
+    NewsID        uint                  `json:"news_id"`
+    Title         string                `json:"title"`
+    CoverImage    string                `json:"cover_image"`
+    CoverVariants []models.ImageVariant `json:"cover_variants,omitempty"`
+    Author        AuthorInfo            `json:"author"`
+    CarbonTip     string                `json:"carbon_tip"`
+    Path          string                `json:"path"` // 打开卡片时跳转的小程序页面，带分享短码
+}
+
+// findNewsForShare 根据路径参数查找新闻，出错时已写入响应
+func (nc *NewsController) findNewsForShare(c *gin.Context) (*models.News, bool) {
+    newsID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
+        return nil, false
+    }
+    var news models.News
+    if err := nc.DB.Select("id, title, author_id, share_count").
+        Scopes(models.NotBlockedBy(optionalUserID(c), "author_id")).First(&news, newsID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrNewsNotFound)
+            return nil, false
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
+        return nil, false
+    }
+    return &news, true
+}
+
+// shareCodeFor 返回用户分享该新闻的短码，首次分享时生成
+func shareCodeFor(db *gorm.DB, userID, newsID uint) (string, error) {
+    var existing models.ShareCode
+    if err := db.Where("user_id = ? AND news_id = ?", userID, newsID).Limit(1).Find(&existing).Error; err != nil {
+        return "", err
+    }
+    if existing.Code != "" {
+        return existing.Code, nil
+    }
+
+    for attempt := 0; attempt < 3; attempt++ {
+        code, err := utils.GenerateShareCode()
+        if err != nil {
+            return "", err
+        }
+        result := db.Clauses(clause.OnConflict{DoNothing: true}).
+            Create(&models.ShareCode{Code: code, NewsID: newsID, UserID: userID})
+        if result.Error != nil {
+            return "", result.Error
+        }
+        if result.RowsAffected > 0 {
+            return code, nil
+        }
+        // 短码冲突，或并发的请求已经为该用户生成了短码
+        if err := db.Where("user_id = ? AND news_id = ?", userID, newsID).Limit(1).Find(&existing).Error; err != nil {
+            return "", err
+        }
+        if existing.Code != "" {
+            return existing.Code, nil
+        }
+    }
+    return "", errors.New("failed to allocate share code")
+}
+
+// shareCard 生成分享卡片：封面取正文第一张图片，小贴士优先使用正文中嵌入的食谱或食物的碳排放
+func (nc *NewsController) shareCard(news *models.News, code string) (ShareCard, error) {
+    card := ShareCard{NewsID: news.ID, Title: news.Title}
+
+    path := fmt.Sprintf("%s?id=%d", newsDetailPath, news.ID)
+    if code != "" {
+        path += "&share_code=" + url.QueryEscape(code)
+    }
+    card.Path = path
+
+    var author models.User
+    if err := nc.DB.Select("id, nickname, avatar_url").Limit(1).Find(&author, news.AuthorID).Error; err != nil {
+        return card, err
+    }
+    card.Author = AuthorInfo{ID: author.ID, Nickname: author.Nickname, AvatarURL: author.AvatarURL}
+
+    var blocks []models.NewsBlock
+    if err := nc.DB.Where("news_id = ? AND type IN ?", news.ID, []string{models.BlockImage, models.BlockRecipe, models.BlockFood}).
+        Order("position").Find(&blocks).Error; err != nil {
+        return card, err
+    }
+    var embedded []models.ContentBlock
+    for _, b := range blocks {
+        if b.Type == models.BlockImage {
+            if card.CoverImage == "" {
+                card.CoverImage = b.URL
+            }
+        } else {
+            embedded = append(embedded, b.ContentBlock)
+        }
+    }
+    if card.CoverImage == "" {
+        // 旧数据没有正文块时使用第一张新闻图片
+        var image models.NewsImage
+        if err := nc.DB.Where("news_id = ?", news.ID).Order("id").Limit(1).Find(&image).Error; err != nil {
+            return card, err
+        }
+        card.CoverImage = image.URL
+    }
+
+    var paths []string
+    for _, p := range []string{card.CoverImage, author.AvatarURL} {
+        if p != "" {
+            paths = append(paths, p)
+        }
+    }
+    variants, err := models.ImageVariantsByPath(nc.DB, paths)
+    if err != nil {
+        return card, err
+    }
+    card.CoverVariants = variants[card.CoverImage]
+    card.Author.AvatarVariants = variants[author.AvatarURL]
+
+    rendered, err := nc.renderBlocks(embedded)
+    if err != nil {
+        return card, err
+    }
+    for _, b := range rendered {
+        if b.Recipe != nil && b.Recipe.Emission > 0 {
+            card.CarbonTip = fmt.Sprintf("文中的食谱「%s」碳排放约为 %.2f kg CO₂e", b.Recipe.Name, b.Recipe.Emission)
+            break
+        }
+        if b.Food != nil && b.Food.Emission > 0 {
+            card.CarbonTip = fmt.Sprintf("文中的 %.2f kg %s 碳排放约为 %.2f kg CO₂e", b.Food.Weight, b.Food.ZhFoodName, b.Food.Emission)
+            break
+        }
+    }
+    if card.CarbonTip == "" {
+        card.CarbonTip = models.CarbonTips[int(news.ID)%len(models.CarbonTips)]
+    }
+    return card, nil
+}
+
+// ShareNewsRequest 记录分享的请求体
+type ShareNewsRequest struct {
+    Channel string `json:"channel" binding:"required"`
+}
+
+// ShareNews 记录一次分享并返回分享短码和卡片数据
+func (nc *NewsController) ShareNews(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var req ShareNewsRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+    valid := false
+    for _, channel := range models.ShareChannels {
+        if channel == req.Channel {
+            valid = true
+            break
+        }
+    }
+    if !valid {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid channel"))
+        return
+    }
+
+    news, ok := nc.findNewsForShare(c)
+    if !ok {
+        return
+    }
+
+    code, err := shareCodeFor(nc.DB, userID.(uint), news.ID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to create share code"))
+        return
+    }
+
+    // 记录分享并原子地增加分享数；同一用户在 ShareDedupeWindow 内通过同一渠道重复分享只计一次，防止刷分享数
+    var shareCount int
+    counted := false
+    err = nc.DB.Transaction(func(tx *gorm.DB) error {
+        var recent int64
+        if err := tx.Model(&models.ShareEvent{}).
+            Where("user_id = ? AND news_id = ? AND channel = ? AND created_at > ?", userID, news.ID, req.Channel, time.Now().Add(-models.ShareDedupeWindow)).
+            Count(&recent).Error; err != nil {
+            return err
+        }
+        if recent == 0 {
+            event := models.ShareEvent{NewsID: news.ID, UserID: userID.(uint), Channel: req.Channel, Code: code}
+            if err := tx.Create(&event).Error; err != nil {
+                return err
+            }
+            if err := tx.Model(&models.News{}).Where("id = ?", news.ID).
+                UpdateColumn("share_count", gorm.Expr("share_count + ?", 1)).Error; err != nil {
+                return err
+            }
+            counted = true
+        }
+        return tx.Model(&models.News{}).Where("id = ?", news.ID).Select("share_count").Scan(&shareCount).Error
+    })
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to record share"))
+        return
+    }
+    if counted {
+        recordNewsStat(nc.DB, news, models.StatShares, 1)
+    }
+
+    card, err := nc.shareCard(news, code)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to build share card"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message":     "News shared successfully",
+        "share_code":  code,
+        "share_count": shareCount,
+        "card":        card,
+    })
+}
+
+// GetShareCard 预览分享卡片，不记录分享
+func (nc *NewsController) GetShareCard(c *gin.Context) {
+    news, ok := nc.findNewsForShare(c)
+    if !ok {
+        return
+    }
+    card, err := nc.shareCard(news, "")
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to build share card"))
+        return
+    }
+    c.JSON(http.StatusOK, card)
+}
+
+// ResolveShareCode 将分享短码解析为新闻，用于深链跳转，并累计短码的打开次数
+func (nc *NewsController) ResolveShareCode(c *gin.Context) {
+    var share models.ShareCode
+    if err := nc.DB.Where("code = ?", c.Param("code")).First(&share).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrNotFound.WithMessage("Share code not found"))
+            return
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to resolve share code"))
+        return
+    }
+
+    var news models.News
+    if err := nc.DB.Select("id, title, author_id").
+        Scopes(models.NotBlockedBy(optionalUserID(c), "author_id")).First(&news, share.NewsID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrNewsNotFound)
+            return
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find news"))
+        return
+    }
+
+    if err := nc.DB.Model(&share).UpdateColumn("open_count", gorm.Expr("open_count + ?", 1)).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to resolve share code"))
+        return
+    }
+
+    card, err := nc.shareCard(&news, share.Code)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to build share card"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{
+        "news_id":   news.ID,
+        "shared_by": share.UserID,
+        "card":      card,
+    })
+}
@@ -0,0 +1,145 @@
+// internal/controllers/news_share_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+func TestShareNews(t *testing.T) {
+    db := setupNewsTestDB()
+    assert.NoError(t, db.AutoMigrate(&models.News{}, &models.Food{}, &models.Recipe{}))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    newsController := NewNewsController(db)
+    newsGroup := router.Group("/news")
+    newsGroup.Use(middleware.AuthMiddleware())
+    {
+        newsGroup.POST("/:id/share", newsController.ShareNews)
+        newsGroup.GET("/:id/share_card", newsController.GetShareCard)
+        newsGroup.GET("/shared/:code", newsController.ResolveShareCode)
+    }
+
+    author := models.User{OpenID: "OpenID_Share_Author", Nickname: "Author", AvatarURL: "/static/avatars/a.jpg"}
+    db.Create(&author)
+    sharer := models.User{OpenID: "OpenID_Share_Sharer", Nickname: "Sharer"}
+    db.Create(&sharer)
+    other := models.User{OpenID: "OpenID_Share_Other", Nickname: "Other"}
+    db.Create(&other)
+
+    beef := models.Food{ZhFoodName: "牛肉", EnFoodName: "Beef", GHG: 60}
+    db.Create(&beef)
+    news := models.News{Title: "Eat Less Beef", AuthorID: author.ID}
+    db.Create(&news)
+    db.Create(&[]models.NewsBlock{
+        {NewsID: news.ID, ContentBlock: models.ContentBlock{Type: models.BlockText, Position: 0, Text: "intro"}},
+        {NewsID: news.ID, ContentBlock: models.ContentBlock{Type: models.BlockFood, Position: 1, FoodID: &beef.ID, Weight: 0.5}},
+        {NewsID: news.ID, ContentBlock: models.ContentBlock{Type: models.BlockImage, Position: 2, URL: "/static/images/news/cover.jpg"}},
+        {NewsID: news.ID, ContentBlock: models.ContentBlock{Type: models.BlockImage, Position: 3, URL: "/static/images/news/second.jpg"}},
+    })
+    plain := models.News{Title: "Plain", AuthorID: author.ID}
+    db.Create(&plain)
+
+    request := func(method, url string, userID uint, body string) (int, map[string]interface{}) {
+        req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+        if body != "" {
+            req.Header.Set("Content-Type", "application/json")
+        }
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        var resp map[string]interface{}
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        return w.Code, resp
+    }
+    shareURL := fmt.Sprintf("/news/%d/share", news.ID)
+
+    var code string
+    t.Run("Share", func(t *testing.T) {
+        status, resp := request("POST", shareURL, sharer.ID, `{"channel":"fax"}`)
+        assert.Equal(t, http.StatusBadRequest, status)
+        assert.Equal(t, "Invalid channel", resp["error"])
+        status, _ = request("POST", "/news/99999/share", sharer.ID, `{"channel":"moment"}`)
+        assert.Equal(t, http.StatusNotFound, status)
+
+        status, resp = request("POST", shareURL, sharer.ID, `{"channel":"wechat_friend"}`)
+        assert.Equal(t, http.StatusOK, status)
+        code = resp["share_code"].(string)
+        assert.Len(t, code, 8)
+        assert.Equal(t, float64(1), resp["share_count"])
+
+        card := resp["card"].(map[string]interface{})
+        assert.Equal(t, "Eat Less Beef", card["title"])
+        assert.Equal(t, "/static/images/news/cover.jpg", card["cover_image"])
+        assert.Equal(t, "Author", card["author"].(map[string]interface{})["nickname"])
+        assert.Equal(t, "文中的 0.50 kg 牛肉 碳排放约为 30.00 kg CO₂e", card["carbon_tip"])
+        assert.Equal(t, fmt.Sprintf("/pagesNews/news_detail/news_detail?id=%d&share_code=%s", news.ID, code), card["path"])
+
+        // 同一用户再次分享复用短码，其他用户获得新的短码
+        status, resp = request("POST", shareURL, sharer.ID, `{"channel":"copy_link"}`)
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, code, resp["share_code"])
+        assert.Equal(t, float64(2), resp["share_count"])
+        _, resp = request("POST", shareURL, other.ID, `{"channel":"moment"}`)
+        assert.NotEqual(t, code, resp["share_code"])
+        assert.Equal(t, float64(3), resp["share_count"])
+
+        // 同一用户在去重窗口内通过同一渠道重复分享不再计数
+        for i := 0; i < 3; i++ {
+            status, resp = request("POST", shareURL, sharer.ID, `{"channel":"wechat_friend"}`)
+            assert.Equal(t, http.StatusOK, status)
+            assert.Equal(t, code, resp["share_code"])
+            assert.Equal(t, float64(3), resp["share_count"])
+        }
+
+        var channels []string
+        db.Model(&models.ShareEvent{}).Where("news_id = ?", news.ID).Order("id").Pluck("channel", &channels)
+        assert.Equal(t, []string{"wechat_friend", "copy_link", "moment"}, channels)
+        var stat models.NewsDailyStat
+        db.Where("news_id = ?", news.ID).First(&stat)
+        assert.Equal(t, 3, stat.Shares)
+
+        // 超过去重窗口后再次计数
+        db.Model(&models.ShareEvent{}).Where("news_id = ? AND user_id = ?", news.ID, sharer.ID).
+            Update("created_at", time.Now().Add(-models.ShareDedupeWindow-time.Minute))
+        _, resp = request("POST", shareURL, sharer.ID, `{"channel":"wechat_friend"}`)
+        assert.Equal(t, float64(4), resp["share_count"])
+        db.Where("news_id = ?", news.ID).First(&stat)
+        assert.Equal(t, 4, stat.Shares)
+    })
+
+    t.Run("Share Card", func(t *testing.T) {
+        status, resp := request("GET", fmt.Sprintf("/news/%d/share_card", plain.ID), other.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, "", resp["cover_image"])
+        assert.Equal(t, models.CarbonTips[int(plain.ID)%len(models.CarbonTips)], resp["carbon_tip"])
+        assert.Equal(t, fmt.Sprintf("/pagesNews/news_detail/news_detail?id=%d", plain.ID), resp["path"])
+    })
+
+    t.Run("Resolve Code", func(t *testing.T) {
+        status, resp := request("GET", "/news/shared/"+code, other.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, float64(news.ID), resp["news_id"])
+        assert.Equal(t, float64(sharer.ID), resp["shared_by"])
+        request("GET", "/news/shared/"+code, other.ID, "")
+
+        var share models.ShareCode
+        db.First(&share, "code = ?", code)
+        assert.Equal(t, 2, share.OpenCount)
+
+        status, resp = request("GET", "/news/shared/nope", other.ID, "")
+        assert.Equal(t, http.StatusNotFound, status)
+        assert.Equal(t, "Share code not found", resp["error"])
+    })
+}
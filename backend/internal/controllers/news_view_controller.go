@@ -0,0 +1,84 @@
+// internal/controllers/news_view_controller.go
+package controllers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+// ViewProgressRequest 客户端上报的阅读进度，均为本次阅读的累计值
+type ViewProgressRequest struct {
+    DurationSeconds *int `json:"duration_seconds"`
+    ScrollDepth     *int `json:"scroll_depth"`
+}
+
+// ReportViewProgress 上报一次阅读的时长和滚动深度。上报值只增不减，乱序到达的旧进度不会覆盖新进度
+func (nc *NewsController) ReportViewProgress(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    viewID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid view ID"))
+        return
+    }
+
+    var req ViewProgressRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+    if req.DurationSeconds == nil && req.ScrollDepth == nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("duration_seconds or scroll_depth is required"))
+        return
+    }
+    if req.DurationSeconds != nil && (*req.DurationSeconds < 0 || *req.DurationSeconds > models.MaxViewDuration) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid duration"))
+        return
+    }
+    if req.ScrollDepth != nil && (*req.ScrollDepth < 0 || *req.ScrollDepth > 100) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid scroll depth"))
+        return
+    }
+
+    // 只能上报自己的阅读记录，他人的记录按不存在处理
+    var view models.NewsView
+    if err := nc.DB.Where("id = ? AND user_id = ?", viewID, userID).First(&view).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrNotFound.WithMessage("View not found"))
+            return
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to find view"))
+        return
+    }
+
+    updates := map[string]interface{}{"updated_at": time.Now()}
+    if req.DurationSeconds != nil && *req.DurationSeconds > view.DurationSeconds {
+        updates["duration_seconds"] = *req.DurationSeconds
+        view.DurationSeconds = *req.DurationSeconds
+    }
+    if req.ScrollDepth != nil && *req.ScrollDepth > view.ScrollDepth {
+        updates["scroll_depth"] = *req.ScrollDepth
+        view.ScrollDepth = *req.ScrollDepth
+    }
+    if err := nc.DB.Model(&view).UpdateColumns(updates).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update view progress"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message":          "View progress updated successfully",
+        "duration_seconds": view.DurationSeconds,
+        "scroll_depth":     view.ScrollDepth,
+    })
+}
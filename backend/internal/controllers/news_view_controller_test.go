@@ -0,0 +1,167 @@
+// internal/controllers/news_view_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+func TestViewTracking(t *testing.T) {
+    db := setupNewsTestDB()
+    assert.NoError(t, db.AutoMigrate(&models.News{}))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    newsController := NewNewsController(db)
+    userController := NewUserController(db, nil)
+    newsGroup := router.Group("/news")
+    newsGroup.Use(middleware.AuthMiddleware())
+    {
+        newsGroup.POST("/:id/view", newsController.ViewNews)
+        newsGroup.PUT("/views/:id", newsController.ReportViewProgress)
+    }
+    userGroup := router.Group("/users")
+    userGroup.Use(middleware.AuthMiddleware())
+    {
+        userGroup.GET("/viewed", userController.GetMyViewedNews)
+        userGroup.GET("/history", userController.GetViewHistory)
+        userGroup.DELETE("/history", userController.ClearViewHistory)
+    }
+
+    reader := models.User{OpenID: "OpenID_View_Reader", Nickname: "Reader"}
+    db.Create(&reader)
+    other := models.User{OpenID: "OpenID_View_Other", Nickname: "Other"}
+    db.Create(&other)
+    first := models.News{Title: "First Read"}
+    db.Create(&first)
+    second := models.News{Title: "Second Read"}
+    db.Create(&second)
+
+    request := func(method, url string, userID uint, body string) (int, map[string]interface{}) {
+        req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+        if body != "" {
+            req.Header.Set("Content-Type", "application/json")
+        }
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        var resp map[string]interface{}
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        return w.Code, resp
+    }
+    view := func(newsID uint) map[string]interface{} {
+        status, resp := request("POST", fmt.Sprintf("/news/%d/view", newsID), reader.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        return resp
+    }
+    viewCount := func(newsID uint) int {
+        var n models.News
+        db.First(&n, newsID)
+        return n.ViewCount
+    }
+
+    var firstViewID uint
+    t.Run("Dedupe Within Window", func(t *testing.T) {
+        resp := view(first.ID)
+        assert.Equal(t, true, resp["counted"])
+        assert.Equal(t, float64(1), resp["view_count"])
+        firstViewID = uint(resp["view_id"].(float64))
+
+        resp = view(first.ID)
+        assert.Equal(t, false, resp["counted"])
+        assert.Equal(t, float64(firstViewID), resp["view_id"])
+        assert.Equal(t, 1, viewCount(first.ID))
+
+        // 窗口从开始阅读时算起，期间反复打开或上报进度不会延长窗口；超出后再次打开记为新的一次阅读
+        db.Model(&models.NewsView{}).Where("id = ?", firstViewID).
+            UpdateColumn("created_at", time.Now().Add(-models.ViewDedupeWindow-time.Minute))
+        var stale models.NewsView
+        db.First(&stale, firstViewID)
+        assert.WithinDuration(t, time.Now(), stale.UpdatedAt, time.Minute)
+        resp = view(first.ID)
+        assert.Equal(t, true, resp["counted"])
+        assert.NotEqual(t, float64(firstViewID), resp["view_id"])
+        assert.Equal(t, 2, viewCount(first.ID))
+
+        view(second.ID)
+    })
+
+    t.Run("Report Progress", func(t *testing.T) {
+        url := fmt.Sprintf("/news/views/%d", firstViewID)
+        status, resp := request("PUT", url, reader.ID, `{"duration_seconds":90,"scroll_depth":60}`)
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, float64(90), resp["duration_seconds"])
+
+        // 乱序到达的旧进度不会覆盖新进度
+        status, resp = request("PUT", url, reader.ID, `{"duration_seconds":30,"scroll_depth":80}`)
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, float64(90), resp["duration_seconds"])
+        assert.Equal(t, float64(80), resp["scroll_depth"])
+
+        for body, expected := range map[string]string{
+            `{}`:                      "duration_seconds or scroll_depth is required",
+            `{"scroll_depth":101}`:    "Invalid scroll depth",
+            `{"duration_seconds":-1}`: "Invalid duration",
+        } {
+            status, resp = request("PUT", url, reader.ID, body)
+            assert.Equal(t, http.StatusBadRequest, status, body)
+            assert.Equal(t, expected, resp["error"], body)
+        }
+
+        status, resp = request("PUT", url, other.ID, `{"scroll_depth":10}`)
+        assert.Equal(t, http.StatusNotFound, status)
+        assert.Equal(t, "View not found", resp["error"])
+    })
+
+    t.Run("History", func(t *testing.T) {
+        status, resp := request("GET", "/users/viewed", reader.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, []interface{}{float64(second.ID), float64(first.ID)}, resp["news_ids"])
+
+        status, resp = request("GET", "/users/history?limit=2", reader.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        history := resp["history"].([]interface{})
+        if assert.Len(t, history, 2) {
+            assert.Equal(t, "Second Read", history[0].(map[string]interface{})["news_title"])
+            assert.Equal(t, "First Read", history[1].(map[string]interface{})["news_title"])
+        }
+        assert.Equal(t, true, resp["has_more"])
+
+        status, resp = request("GET", "/users/history?cursor="+resp["next_cursor"].(string), reader.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        history = resp["history"].([]interface{})
+        if assert.Len(t, history, 1) {
+            item := history[0].(map[string]interface{})
+            assert.Equal(t, float64(firstViewID), item["id"])
+            assert.Equal(t, float64(90), item["duration_seconds"])
+            assert.Equal(t, float64(80), item["scroll_depth"])
+        }
+        assert.Equal(t, false, resp["has_more"])
+    })
+
+    t.Run("Clear History", func(t *testing.T) {
+        status, resp := request("DELETE", fmt.Sprintf("/users/history?news_id=%d", first.ID), reader.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, float64(2), resp["deleted"])
+        _, resp = request("GET", "/users/viewed", reader.ID, "")
+        assert.Equal(t, []interface{}{float64(second.ID)}, resp["news_ids"])
+
+        status, resp = request("DELETE", "/users/history", reader.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        assert.Equal(t, float64(1), resp["deleted"])
+        _, resp = request("GET", "/users/history", reader.ID, "")
+        assert.Empty(t, resp["history"])
+        // 清空历史不影响已计入的浏览量
+        assert.Equal(t, 2, viewCount(first.ID))
+    })
+}
@@ -0,0 +1,397 @@
+// internal/controllers/notification_controller.go
+package controllers
+
+import (
+    "log"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+const (
+    defaultNotificationPageSize = 20
+    maxNotificationPageSize     = 50
+    notificationExcerptLength   = 50 // 通知中评论摘要的最大字数
+)
+
+type NotificationController struct {
+    DB *gorm.DB
+}
+
+func NewNotificationController(db *gorm.DB) *NotificationController {
+    return &NotificationController{DB: db}
+}
+
+// NotificationResponse 定义返回给客户端的单条通知
+type NotificationResponse struct {
+    ID        uint       `json:"id"`
+    Type      string     `json:"type"`
+    Actor     AuthorInfo `json:"actor"`
+    NewsID    uint       `json:"news_id"`
+    NewsTitle string     `json:"news_title"`
+    CommentID *uint      `json:"comment_id"`
+    Excerpt   string     `json:"excerpt"` // 评论内容摘要
+    IsRead    bool       `json:"is_read"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+// notify 创建一条通知。不通知用户自己的操作，也不通知被屏蔽用户的操作（包括 @）；相同的通知（如取消后再次点赞）只保留一条
+func notify(db *gorm.DB, n models.Notification) error {
+    if n.UserID == 0 || n.UserID == n.ActorID {
+        return nil
+    }
+    blocked, err := models.IsBlocked(db, n.UserID, n.ActorID)
+    if err != nil || blocked {
+        return err
+    }
+    query := db.Model(&models.Notification{}).
+        Where("user_id = ? AND actor_id = ? AND type = ? AND news_id = ?", n.UserID, n.ActorID, n.Type, n.NewsID)
+    if n.CommentID != nil {
+        query = query.Where("comment_id = ?", *n.CommentID)
+    } else {
+        query = query.Where("comment_id IS NULL")
+    }
+    var count int64
+    if err := query.Count(&count).Error; err != nil {
+        return err
+    }
+    if count > 0 {
+        return nil
+    }
+    return db.Create(&n).Error
+}
+
+// resolveMentions 将 @提及 解析为用户 ID：纯数字按用户 ID 匹配，其余按昵称精确匹配，昵称重复时无法确定对象而忽略
+func resolveMentions(db *gorm.DB, mentions []string) ([]uint, error) {
+    var ids []uint
+    var nicknames []string
+    for _, m := range mentions {
+        if id, err := strconv.ParseUint(m, 10, 64); err == nil {
+            ids = append(ids, uint(id))
+        } else {
+            nicknames = append(nicknames, m)
+        }
+    }
+
+    var users []models.User
+    if len(ids) > 0 {
+        if err := db.Select("id").Where("id IN ?", ids).Find(&users).Error; err != nil {
+            return nil, err
+        }
+    }
+    resolved := make([]uint, 0, len(mentions))
+    for _, u := range users {
+        resolved = append(resolved, u.ID)
+    }
+
+    if len(nicknames) > 0 {
+        var matches []models.User
+        if err := db.Select("id", "nickname").Where("nickname IN ?", nicknames).Find(&matches).Error; err != nil {
+            return nil, err
+        }
+        byNickname := make(map[string][]uint)
+        for _, u := range matches {
+            byNickname[u.Nickname] = append(byNickname[u.Nickname], u.ID)
+        }
+        for _, name := range nicknames {
+            if len(byNickname[name]) == 1 {
+                resolved = append(resolved, byNickname[name][0])
+            }
+        }
+    }
+    return resolved, nil
+}
+
+// notifyMentions 通知评论中被 @ 的用户，skip 中的用户已收到其它通知
+func notifyMentions(db *gorm.DB, comment *models.Comment, skip map[uint]bool) error {
+    userIDs, err := resolveMentions(db, utils.ParseMentions(comment.Content))
+    if err != nil {
+        return err
+    }
+    for _, id := range userIDs {
+        if skip[id] {
+            continue
+        }
+        skip[id] = true
+        if err := notify(db, models.Notification{
+            UserID:    id,
+            ActorID:   comment.UserID,
+            Type:      models.NotificationMention,
+            NewsID:    comment.NewsID,
+            CommentID: &comment.ID,
+        }); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// notifyNewComment 新评论通知：顶级评论通知新闻作者，回复通知父评论作者，并通知被 @ 的用户
+func notifyNewComment(db *gorm.DB, comment *models.Comment) error {
+    recipient := models.Notification{
+        ActorID:   comment.UserID,
+        NewsID:    comment.NewsID,
+        CommentID: &comment.ID,
+    }
+    if comment.ParentID != nil {
+        var parent models.Comment
+        if err := db.Select("id", "user_id").First(&parent, *comment.ParentID).Error; err != nil {
+            return err
+        }
+        recipient.UserID = parent.UserID
+        recipient.Type = models.NotificationReply
+    } else {
+        var news models.News
+        if err := db.Select("id", "author_id").First(&news, comment.NewsID).Error; err != nil {
+            return err
+        }
+        recipient.UserID = news.AuthorID
+        recipient.Type = models.NotificationComment
+    }
+    if err := notify(db, recipient); err != nil {
+        return err
+    }
+    return notifyMentions(db, comment, map[uint]bool{recipient.UserID: true, comment.UserID: true})
+}
+
+// notifyNewsAuthor 通知新闻作者其新闻被点赞或收藏
+func notifyNewsAuthor(db *gorm.DB, news *models.News, actorID uint, notificationType string) error {
+    return notify(db, models.Notification{
+        UserID:  news.AuthorID,
+        ActorID: actorID,
+        Type:    notificationType,
+        NewsID:  news.ID,
+    })
+}
+
+// logNotifyError 通知失败不影响主操作，只记录日志
+func logNotifyError(err error) {
+    if err != nil {
+        log.Printf("failed to create notification: %v", err)
+    }
+}
+
+// excerpt 截取内容的前若干个字
+func excerpt(content string) string {
+    runes := []rune(content)
+    if len(runes) <= notificationExcerptLength {
+        return content
+    }
+    return string(runes[:notificationExcerptLength]) + "…"
+}
+
+// notificationResponses 为通知批量补充触发者、新闻标题和评论摘要
+func (nc *NotificationController) notificationResponses(notifications []models.Notification) ([]NotificationResponse, error) {
+    responses := make([]NotificationResponse, 0, len(notifications))
+    if len(notifications) == 0 {
+        return responses, nil
+    }
+
+    var actorIDs, newsIDs, commentIDs []uint
+    for _, n := range notifications {
+        actorIDs = append(actorIDs, n.ActorID)
+        newsIDs = append(newsIDs, n.NewsID)
+        if n.CommentID != nil {
+            commentIDs = append(commentIDs, *n.CommentID)
+        }
+    }
+
+    var actors []models.User
+    if err := nc.DB.Select("id", "nickname", "avatar_url").Where("id IN ?", actorIDs).Find(&actors).Error; err != nil {
+        return nil, err
+    }
+    actorByID := make(map[uint]models.User, len(actors))
+    avatars := make([]string, 0, len(actors))
+    for _, a := range actors {
+        actorByID[a.ID] = a
+        avatars = append(avatars, a.AvatarURL)
+    }
+    variants, err := models.ImageVariantsByPath(nc.DB, avatars)
+    if err != nil {
+        return nil, err
+    }
+
+    var newsList []models.News
+    if err := nc.DB.Select("id", "title").Where("id IN ?", newsIDs).Find(&newsList).Error; err != nil {
+        return nil, err
+    }
+    titles := make(map[uint]string, len(newsList))
+    for _, news := range newsList {
+        titles[news.ID] = news.Title
+    }
+
+    comments := make(map[uint]models.Comment)
+    if len(commentIDs) > 0 {
+        var list []models.Comment
+        if err := nc.DB.Select("id", "content", "is_deleted").Where("id IN ?", commentIDs).Find(&list).Error; err != nil {
+            return nil, err
+        }
+        for _, comment := range list {
+            comments[comment.ID] = comment
+        }
+    }
+
+    for _, n := range notifications {
+        actor := actorByID[n.ActorID]
+        resp := NotificationResponse{
+            ID:   n.ID,
+            Type: n.Type,
+            Actor: AuthorInfo{
+                ID:             actor.ID,
+                Nickname:       actor.Nickname,
+                AvatarURL:      actor.AvatarURL,
+                AvatarVariants: variants[actor.AvatarURL],
+            },
+            NewsID:    n.NewsID,
+            NewsTitle: titles[n.NewsID],
+            CommentID: n.CommentID,
+            IsRead:    n.IsRead,
+            CreatedAt: n.CreatedAt,
+        }
+        if n.CommentID != nil {
+            if comment, ok := comments[*n.CommentID]; ok && !comment.IsDeleted {
+                resp.Excerpt = excerpt(comment.Content)
+            } else {
+                resp.Excerpt = models.DeletedCommentContent
+            }
+        }
+        responses = append(responses, resp)
+    }
+    return responses, nil
+}
+
+// unreadCount 用户的未读通知数
+func (nc *NotificationController) unreadCount(userID uint) (int64, error) {
+    var count int64
+    err := nc.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false).
+        Scopes(models.NotBlockedBy(userID, "actor_id")).Count(&count).Error
+    return count, err
+}
+
+// GetNotifications 分页获取通知，最新在前；unread=true 时只返回未读通知，cursor 为上一页最后一条通知的 ID
+func (nc *NotificationController) GetNotifications(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    limit := defaultNotificationPageSize
+    if v := c.Query("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1 || n > maxNotificationPageSize {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid limit"))
+            return
+        }
+        limit = n
+    }
+
+    // 屏蔽之前收到的通知也不再显示
+    query := nc.DB.Where("user_id = ?", userID).Scopes(models.NotBlockedBy(userID.(uint), "actor_id"))
+    if v := c.Query("cursor"); v != "" {
+        cursor, err := strconv.ParseUint(v, 10, 64)
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid cursor"))
+            return
+        }
+        query = query.Where("id < ?", cursor)
+    }
+    if c.Query("unread") == "true" {
+        query = query.Where("is_read = ?", false)
+    }
+
+    var notifications []models.Notification
+    if err := query.Order("id DESC").Limit(limit + 1).Find(&notifications).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch notifications"))
+        return
+    }
+    hasMore := len(notifications) > limit
+    nextCursor := ""
+    if hasMore {
+        notifications = notifications[:limit]
+        nextCursor = strconv.FormatUint(uint64(notifications[limit-1].ID), 10)
+    }
+
+    responses, err := nc.notificationResponses(notifications)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch notifications"))
+        return
+    }
+    unread, err := nc.unreadCount(userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch notifications"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "notifications": responses,
+        "unread_count":  unread,
+        "next_cursor":   nextCursor,
+        "has_more":      hasMore,
+    })
+}
+
+// GetUnreadCount 获取未读通知数
+func (nc *NotificationController) GetUnreadCount(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    unread, err := nc.unreadCount(userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch unread count"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+// MarkNotificationsReadRequest 标记通知已读的请求体，ids 为空时标记全部通知
+type MarkNotificationsReadRequest struct {
+    IDs []uint `json:"ids"`
+}
+
+// MarkNotificationsRead 将指定通知标记为已读，未提供 ids 时标记全部
+func (nc *NotificationController) MarkNotificationsRead(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var request MarkNotificationsReadRequest
+    if c.Request.ContentLength != 0 {
+        if err := c.ShouldBindJSON(&request); err != nil {
+            apierror.Respond(c, apierror.ErrInvalidRequest)
+            return
+        }
+    }
+
+    query := nc.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", userID, false)
+    if len(request.IDs) > 0 {
+        query = query.Where("id IN ?", request.IDs)
+    }
+    result := query.Updates(map[string]interface{}{"is_read": true, "read_at": time.Now()})
+    if result.Error != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to mark notifications as read"))
+        return
+    }
+
+    unread, err := nc.unreadCount(userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch unread count"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{
+        "message":      "Notifications marked as read",
+        "updated":      result.RowsAffected,
+        "unread_count": unread,
+    })
+}
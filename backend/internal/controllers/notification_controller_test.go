@@ -0,0 +1,227 @@
+// internal/controllers/notification_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+// setupNotificationRouter 初始化评论、点赞、收藏和通知路由
+func setupNotificationRouter(db *gorm.DB) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    newsController := NewNewsController(db)
+    notificationController := NewNotificationController(db)
+
+    newsGroup := router.Group("/news")
+    newsGroup.Use(middleware.AuthMiddleware())
+    {
+        newsGroup.POST("/comments", newsController.AddComment)
+        newsGroup.PUT("/comments/:id", newsController.EditComment)
+        newsGroup.POST("/:id/like", newsController.LikeNews)
+        newsGroup.DELETE("/:id/like", newsController.CancelLikeNews)
+        newsGroup.POST("/:id/favorite", newsController.FavoriteNews)
+    }
+    notificationGroup := router.Group("/notifications")
+    notificationGroup.Use(middleware.AuthMiddleware())
+    {
+        notificationGroup.GET("", notificationController.GetNotifications)
+        notificationGroup.GET("/unread_count", notificationController.GetUnreadCount)
+        notificationGroup.POST("/read", notificationController.MarkNotificationsRead)
+    }
+    return router
+}
+
+type notificationPage struct {
+    Notifications []NotificationResponse `json:"notifications"`
+    UnreadCount   int64                  `json:"unread_count"`
+    NextCursor    string                 `json:"next_cursor"`
+    HasMore       bool                   `json:"has_more"`
+}
+
+func TestParseMentions(t *testing.T) {
+    tests := []struct {
+        content  string
+        expected []string
+    }{
+        {"hello @alice and @bob.", []string{"alice", "bob"}},
+        {"@小明 你好，@小红！", []string{"小明", "小红"}},
+        {"mail me at alice@example.com", nil},
+        {"@42 @alice @alice @", []string{"42", "alice"}},
+        {"（@张三）＠李四", []string{"张三", "李四"}},
+    }
+    for _, tc := range tests {
+        assert.Equal(t, tc.expected, utils.ParseMentions(tc.content), tc.content)
+    }
+
+    many := ""
+    for i := 0; i < utils.MaxMentions+5; i++ {
+        many += fmt.Sprintf("@user%d ", i)
+    }
+    assert.Len(t, utils.ParseMentions(many), utils.MaxMentions)
+}
+
+func TestNotifications(t *testing.T) {
+    db := setupNewsTestDB()
+    assert.NoError(t, db.AutoMigrate(&models.News{}, &models.Comment{}))
+    router := setupNotificationRouter(db)
+
+    author := models.User{OpenID: "OpenID_Notify_Author", Nickname: "Author"}
+    db.Create(&author)
+    alice := models.User{OpenID: "OpenID_Notify_Alice", Nickname: "alice"}
+    db.Create(&alice)
+    bob := models.User{OpenID: "OpenID_Notify_Bob", Nickname: "bob"}
+    db.Create(&bob)
+    // 重名用户无法通过昵称提及
+    db.Create(&models.User{OpenID: "OpenID_Notify_Twin1", Nickname: "twin"})
+    db.Create(&models.User{OpenID: "OpenID_Notify_Twin2", Nickname: "twin"})
+
+    news := models.News{Title: "Notified News", AuthorID: author.ID}
+    db.Create(&news)
+
+    request := func(method, url string, userID uint, body string) (int, []byte) {
+        req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+        if body != "" {
+            req.Header.Set("Content-Type", "application/json")
+        }
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w.Code, w.Body.Bytes()
+    }
+    inbox := func(userID uint, query string) notificationPage {
+        status, body := request("GET", "/notifications"+query, userID, "")
+        assert.Equal(t, http.StatusOK, status)
+        var page notificationPage
+        assert.NoError(t, json.Unmarshal(body, &page))
+        return page
+    }
+    addComment := func(userID uint, content string, parentID uint) uint {
+        body := fmt.Sprintf(`{"news_id":%d,"content":%q}`, news.ID, content)
+        if parentID != 0 {
+            body = fmt.Sprintf(`{"news_id":%d,"content":%q,"is_reply":true,"parent_id":%d}`, news.ID, content, parentID)
+        }
+        status, resp := request("POST", "/news/comments", userID, body)
+        assert.Equal(t, http.StatusCreated, status)
+        var created struct {
+            Comment struct {
+                ID uint `json:"id"`
+            } `json:"comment"`
+        }
+        assert.NoError(t, json.Unmarshal(resp, &created))
+        return created.Comment.ID
+    }
+
+    // alice 评论新闻并提及 bob、作者本人和重名用户
+    topID := addComment(alice.ID, "great post @bob @Author @twin", 0)
+    // bob 回复 alice，并提及 alice（已收到回复通知）和自己
+    replyID := addComment(bob.ID, "thanks @alice @bob", topID)
+    // 作者回复自己的新闻下的评论不通知自己
+    addComment(author.ID, "welcome", replyID)
+
+    t.Run("Comment Mention And Reply", func(t *testing.T) {
+        page := inbox(author.ID, "")
+        if assert.Len(t, page.Notifications, 1) {
+            n := page.Notifications[0]
+            assert.Equal(t, models.NotificationComment, n.Type)
+            assert.Equal(t, alice.ID, n.Actor.ID)
+            assert.Equal(t, "Notified News", n.NewsTitle)
+            assert.Equal(t, "great post @bob @Author @twin", n.Excerpt)
+        }
+
+        page = inbox(alice.ID, "")
+        if assert.Len(t, page.Notifications, 1) {
+            assert.Equal(t, models.NotificationReply, page.Notifications[0].Type)
+            assert.Equal(t, replyID, *page.Notifications[0].CommentID)
+        }
+
+        // bob 收到 alice 的提及以及作者的回复
+        page = inbox(bob.ID, "")
+        assert.Equal(t, int64(2), page.UnreadCount)
+        if assert.Len(t, page.Notifications, 2) {
+            assert.Equal(t, models.NotificationReply, page.Notifications[0].Type)
+            assert.Equal(t, author.ID, page.Notifications[0].Actor.ID)
+            assert.Equal(t, models.NotificationMention, page.Notifications[1].Type)
+            assert.Equal(t, topID, *page.Notifications[1].CommentID)
+        }
+    })
+
+    t.Run("Edit Adds Mention", func(t *testing.T) {
+        status, _ := request("PUT", fmt.Sprintf("/news/comments/%d", topID), alice.ID, fmt.Sprintf(`{"content":"great post @bob @%d"}`, author.ID))
+        assert.Equal(t, http.StatusOK, status)
+
+        // bob 已被提及过，不重复通知；作者通过 ID 被提及
+        assert.Len(t, inbox(bob.ID, "").Notifications, 2)
+        page := inbox(author.ID, "")
+        if assert.Len(t, page.Notifications, 2) {
+            assert.Equal(t, models.NotificationMention, page.Notifications[0].Type)
+        }
+    })
+
+    t.Run("Like And Favorite", func(t *testing.T) {
+        likeURL := fmt.Sprintf("/news/%d/like", news.ID)
+        status, _ := request("POST", likeURL, alice.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        // 取消后再次点赞不重复通知
+        status, _ = request("DELETE", likeURL, alice.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        status, _ = request("POST", likeURL, alice.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        // 作者给自己点赞不通知
+        status, _ = request("POST", likeURL, author.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        status, _ = request("POST", fmt.Sprintf("/news/%d/favorite", news.ID), bob.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+
+        page := inbox(author.ID, "")
+        if assert.Len(t, page.Notifications, 4) {
+            assert.Equal(t, models.NotificationFavorite, page.Notifications[0].Type)
+            assert.Equal(t, bob.ID, page.Notifications[0].Actor.ID)
+            assert.Equal(t, models.NotificationLike, page.Notifications[1].Type)
+            assert.Nil(t, page.Notifications[1].CommentID)
+        }
+    })
+
+    t.Run("Pagination And Mark Read", func(t *testing.T) {
+        page := inbox(author.ID, "?limit=3")
+        assert.Len(t, page.Notifications, 3)
+        assert.True(t, page.HasMore)
+        rest := inbox(author.ID, "?limit=3&cursor="+page.NextCursor)
+        assert.Len(t, rest.Notifications, 1)
+        assert.False(t, rest.HasMore)
+
+        status, _ := request("GET", "/notifications?limit=100", author.ID, "")
+        assert.Equal(t, http.StatusBadRequest, status)
+
+        // 标记指定通知，其他用户的通知不受影响
+        status, body := request("POST", "/notifications/read", author.ID,
+            fmt.Sprintf(`{"ids":[%d,%d]}`, page.Notifications[0].ID, inbox(bob.ID, "").Notifications[0].ID))
+        assert.Equal(t, http.StatusOK, status)
+        var resp map[string]interface{}
+        assert.NoError(t, json.Unmarshal(body, &resp))
+        assert.Equal(t, float64(1), resp["updated"])
+        assert.Equal(t, float64(3), resp["unread_count"])
+
+        unread := inbox(author.ID, "?unread=true")
+        assert.Len(t, unread.Notifications, 3)
+        assert.Equal(t, int64(2), inbox(bob.ID, "").UnreadCount)
+
+        // 未提供 ids 时全部标记为已读
+        status, _ = request("POST", "/notifications/read", author.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        status, body = request("GET", "/notifications/unread_count", author.ID, "")
+        assert.Equal(t, http.StatusOK, status)
+        assert.JSONEq(t, `{"unread_count":0}`, string(body))
+    })
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+
 	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -170,7 +172,7 @@ func (nc *NutritionCarbonController) SetNutritionGoals(c *gin.Context){
     log.Printf("设置营养目标")
     userID, err := c.Get("user_id")
     if !err {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
     log.Printf("userID: %v", userID)
@@ -180,7 +182,7 @@ func (nc *NutritionCarbonController) SetNutritionGoals(c *gin.Context){
 
     if err := c.ShouldBindJSON(&goals); err != nil {
         log.Printf("请求体验证失败: %v", err)
-        c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+        apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("无效的请求数据"))
         return
     }
     log.Printf("请求体验证成功")
@@ -192,7 +194,7 @@ func (nc *NutritionCarbonController) SetNutritionGoals(c *gin.Context){
     }
     if ok, err := validateDate(dates); !ok {
         log.Printf("日期验证失败: %v", err)
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage(err.Error()))
         return
     }
     log.Printf("日期验证成功")
@@ -206,7 +208,7 @@ func (nc *NutritionCarbonController) SetNutritionGoals(c *gin.Context){
 
     if err := tx.Where("user_id = ? AND date < ?", userID, today.AddDate(0, 0, -7).Format("2006-01-02")).Delete(&models.NutritionGoal{}).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "删除一周前的目标失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("删除一周前的目标失败"))
         return
     }
     log.Printf("删除一周前的目标成功")
@@ -239,7 +241,7 @@ func (nc *NutritionCarbonController) SetNutritionGoals(c *gin.Context){
             
             if err := tx.Save(&existingGoal).Error; err != nil {
                 tx.Rollback()
-                c.JSON(http.StatusInternalServerError, gin.H{"error": "更新目标失败"})
+                apierror.Respond(c, apierror.ErrInternal.WithMessage("更新目标失败"))
                 return
             }
         } else {
@@ -256,7 +258,7 @@ func (nc *NutritionCarbonController) SetNutritionGoals(c *gin.Context){
             }
             if err := tx.Create(&newGoal).Error; err != nil {
                 tx.Rollback()
-                c.JSON(http.StatusInternalServerError, gin.H{"error": "创建目标失败"})
+                apierror.Respond(c, apierror.ErrInternal.WithMessage("创建目标失败"))
                 return
             }
         }
@@ -265,7 +267,7 @@ func (nc *NutritionCarbonController) SetNutritionGoals(c *gin.Context){
     
     // 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "提交事务失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("提交事务失败"))
         return
     }
     log.Printf("提交事务成功")
@@ -277,7 +279,7 @@ func (nc *NutritionCarbonController) SetCarbonGoals(c *gin.Context) {
     log.Printf("设置碳排放目标")
     userID, err := c.Get("user_id")
     if !err {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
     log.Printf("userID: %v", userID)
@@ -285,7 +287,7 @@ func (nc *NutritionCarbonController) SetCarbonGoals(c *gin.Context) {
     var goals []CarbonGoalRequest
 
     if err := c.ShouldBindJSON(&goals); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+        apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("无效的请求数据"))
         return
     }
     log.Printf("请求体验证成功")
@@ -295,7 +297,7 @@ func (nc *NutritionCarbonController) SetCarbonGoals(c *gin.Context) {
         dates = append(dates, goal.Date)
     }
     if ok, err := validateDate(dates); !ok {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage(err.Error()))
         return
     }
     log.Printf("日期验证成功")
@@ -309,7 +311,7 @@ func (nc *NutritionCarbonController) SetCarbonGoals(c *gin.Context) {
     today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, cst)
     if err := tx.Where("user_id = ? AND date < ?", userID, today.AddDate(0, 0, -7).Format("2006-01-02")).Delete(&models.CarbonGoal{}).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "删除一周前的目标失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("删除一周前的目标失败"))
         return
     }
     log.Printf("删除一周前的目标成功")
@@ -338,7 +340,7 @@ func (nc *NutritionCarbonController) SetCarbonGoals(c *gin.Context) {
             
             if err := tx.Save(&existingGoal).Error; err != nil {
                 tx.Rollback()
-                c.JSON(http.StatusInternalServerError, gin.H{"error": "更新目标失败"})
+                apierror.Respond(c, apierror.ErrInternal.WithMessage("更新目标失败"))
                 return
             }
         }  else {
@@ -351,7 +353,7 @@ func (nc *NutritionCarbonController) SetCarbonGoals(c *gin.Context) {
             }
             if err := tx.Create(&newGoal).Error; err != nil {
                 tx.Rollback()
-                c.JSON(http.StatusInternalServerError, gin.H{"error": "创建目标失败"})
+                apierror.Respond(c, apierror.ErrInternal.WithMessage("创建目标失败"))
                 return
             }
         }
@@ -359,7 +361,7 @@ func (nc *NutritionCarbonController) SetCarbonGoals(c *gin.Context) {
     log.Printf("处理每个目标成功")
     // 提交事务
     if err := tx.Commit().Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "提交事务失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("提交事务失败"))
         return
     }
     log.Printf("提交事务成功")
@@ -372,7 +374,7 @@ func (nc *NutritionCarbonController) GetNutritionGoals(c *gin.Context) {
     log.Printf("获取营养目标")  
     userID, err := c.Get("user_id")
     if !err {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
     log.Printf("userID: %v", userID)
@@ -383,7 +385,7 @@ func (nc *NutritionCarbonController) GetNutritionGoals(c *gin.Context) {
     today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, cst)
     if err := nc.DB.Where("user_id = ? AND date < ?", userID, today.AddDate(0, 0, -7).Format("2006-01-02")).Delete(&models.NutritionGoal{}).Error; err != nil {
         log.Printf("删除一周前的目标失败: %v", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "删除一周前的目标失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("删除一周前的目标失败"))
         return
     }
     log.Printf("删除一周前的目标成功")
@@ -414,7 +416,7 @@ func (nc *NutritionCarbonController) GetNutritionGoals(c *gin.Context) {
         userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
         Order("date").
         Find(&existingGoals).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "获取营养目标失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("获取营养目标失败"))
         return
     }
     log.Printf("查询存在的目标数据成功")
@@ -434,7 +436,7 @@ func (nc *NutritionCarbonController) GetCarbonGoals(c *gin.Context) {
     log.Printf("获取碳排放目标")
     userID, err := c.Get("user_id")
     if !err {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
     log.Printf("userID: %v", userID)
@@ -444,7 +446,7 @@ func (nc *NutritionCarbonController) GetCarbonGoals(c *gin.Context) {
     now := time.Now().In(cst)
     today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, cst)
     if err := nc.DB.Where("user_id = ? AND date < ?", userID, today.AddDate(0, 0, -7).Format("2006-01-02")).Delete(&models.CarbonGoal{}).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "删除一周前的目标失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("删除一周前的目标失败"))
         return
     }
 
@@ -473,7 +475,7 @@ func (nc *NutritionCarbonController) GetCarbonGoals(c *gin.Context) {
         userID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
         Order("date").
         Find(&existingGoals).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "获取碳排放目标失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("获取碳排放目标失败"))
         return
     }
     log.Printf("查询存在的目标数据成功")
@@ -494,7 +496,7 @@ func (nc *NutritionCarbonController) GetCarbonGoals(c *gin.Context) {
 func (nc *NutritionCarbonController) GetActualNutrition(c *gin.Context) {
     userID, err := c.Get("user_id")
     if !err {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
     log.Printf("userID: %v", userID)
@@ -503,7 +505,7 @@ func (nc *NutritionCarbonController) GetActualNutrition(c *gin.Context) {
     now := time.Now().In(cst)
     today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, cst)
     if err := nc.DB.Where("user_id = ? AND date < ?", userID, today.AddDate(0, 0, -7).Format("2006-01-02")).Delete(&models.NutritionIntake{}).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "删除7天前的摄入记录失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("删除7天前的摄入记录失败"))
         return
     }
     log.Printf("删除7天前的摄入记录成功")
@@ -540,7 +542,7 @@ func (nc *NutritionCarbonController) GetActualNutrition(c *gin.Context) {
         endDate.AddDate(0, 0, 1).Format("2006-01-02"), // Include full day
     ).Order("date, meal_type").
     Find(&actualIntakes).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "获取营养摄入记录失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("获取营养摄入记录失败"))
         return
     }
     log.Printf("查询实际摄入记录成功")  
@@ -579,7 +581,7 @@ func (nc *NutritionCarbonController) GetCarbonIntakes(c *gin.Context) {
     log.Printf("获取实际碳排放")
     userID, err := c.Get("user_id")
     if !err {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
     log.Printf("userID: %v", userID)
@@ -588,7 +590,7 @@ func (nc *NutritionCarbonController) GetCarbonIntakes(c *gin.Context) {
     now := time.Now().In(cst)
     today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, cst)
     if err := nc.DB.Where("user_id = ? AND date < ?", userID, today.AddDate(0, 0, -7).Format("2006-01-02")).Delete(&models.CarbonIntake{}).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "删除7天前的碳排放记录失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("删除7天前的碳排放记录失败"))
         return
     }
     log.Printf("删除7天前的碳排放记录成功")
@@ -621,7 +623,7 @@ func (nc *NutritionCarbonController) GetCarbonIntakes(c *gin.Context) {
         endDate.AddDate(0, 0, 1).Format("2006-01-02"), // Include full day
     ).Order("date, meal_type").
     Find(&actualIntakes).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "获取碳排放记录失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("获取碳排放记录失败"))
         return
     }
     // 累加实际摄入值到对应的默认记录中
@@ -655,26 +657,26 @@ func (nc *NutritionCarbonController) SetSharedNutritionCarbonIntake(c *gin.Conte
     log.Printf("设置共享营养碳排放")
     userID, err := c.Get("user_id")
     if !err {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
     log.Printf("userID: %v", userID)
     var req SharedNutritionCarbonIntakeRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+        apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("无效的请求数据"))
         return
     }
     log.Printf("请求体验证成功")
     // 验证用户分摊信息
     if valid, err := nc.validateUserShares(userID.(uint), req.UserShares); !valid {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage(err.Error()))
         return
     }
     log.Printf("验证用户分摊信息成功")
     // 开启事务
     tx := nc.DB.Begin()
     if tx.Error != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "开启事务失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("开启事务失败"))
         return
     }
     log.Printf("开启事务成功")
@@ -705,13 +707,13 @@ func (nc *NutritionCarbonController) SetSharedNutritionCarbonIntake(c *gin.Conte
         log.Printf("创建碳排放记录成功")
         if err := tx.Create(&nutritionIntake).Error; err != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "创建营养摄入记录失败"})
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("创建营养摄入记录失败"))
             return
         }
         log.Printf("创建营养摄入记录成功")
         if err := tx.Create(&carbonIntake).Error; err != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "创建碳排放记录失败"})
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("创建碳排放记录失败"))
             return
         }
         log.Printf("创建碳排放记录成功")
@@ -0,0 +1,148 @@
+// controllers/privacy_controller.go
+package controllers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+// GetPrivacySettings 获取当前用户的隐私设置
+func (uc *UserController) GetPrivacySettings(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    settings, err := models.GetPrivacySettings(uc.DB, userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch privacy settings"))
+        return
+    }
+    c.JSON(http.StatusOK, settings)
+}
+
+// UpdatePrivacySettingsRequest 修改隐私设置的请求体，只更新提供的字段
+type UpdatePrivacySettingsRequest struct {
+    ProfileVisibility *string `json:"profile_visibility"`
+    FamilyDataSharing *string `json:"family_data_sharing"`
+    LikesPublic       *bool   `json:"likes_public"`
+    FavoritesPublic   *bool   `json:"favorites_public"`
+}
+
+// UpdatePrivacySettings 更新当前用户的隐私设置，只修改请求中给出的字段
+func (uc *UserController) UpdatePrivacySettings(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var req UpdatePrivacySettingsRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        apierror.Respond(c, apierror.ErrInvalidRequest)
+        return
+    }
+    if req.ProfileVisibility != nil && !models.ValidProfileVisibility(*req.ProfileVisibility) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid profile visibility"))
+        return
+    }
+    if req.FamilyDataSharing != nil && !models.ValidFamilyDataSharing(*req.FamilyDataSharing) {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid family data sharing"))
+        return
+    }
+
+    settings, err := models.GetPrivacySettings(uc.DB, userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch privacy settings"))
+        return
+    }
+    if req.ProfileVisibility != nil {
+        settings.ProfileVisibility = *req.ProfileVisibility
+    }
+    if req.FamilyDataSharing != nil {
+        settings.FamilyDataSharing = *req.FamilyDataSharing
+    }
+    if req.LikesPublic != nil {
+        settings.LikesPublic = *req.LikesPublic
+    }
+    if req.FavoritesPublic != nil {
+        settings.FavoritesPublic = *req.FavoritesPublic
+    }
+
+    if err := uc.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&settings).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update privacy settings"))
+        return
+    }
+    c.JSON(http.StatusOK, settings)
+}
+
+// GetUserLikedNews 获取其他用户点赞的新闻 ID，对方未公开点赞列表时返回 403
+func (uc *UserController) GetUserLikedNews(c *gin.Context) {
+    uc.getPublicNewsList(c, models.NewsLikes.JoinTable, func(s models.PrivacySettings) bool { return s.LikesPublic })
+}
+
+// GetUserFavoritedNews 获取其他用户收藏的新闻 ID，对方未公开收藏列表时返回 403
+func (uc *UserController) GetUserFavoritedNews(c *gin.Context) {
+    uc.getPublicNewsList(c, models.NewsFavorites.JoinTable, func(s models.PrivacySettings) bool { return s.FavoritesPublic })
+}
+
+// getPublicNewsList 返回 joinTable 中 :id 用户关联的新闻 ID，关联关系没有时间戳，按新闻 ID 倒序；不包含当前用户屏蔽的作者的新闻
+func (uc *UserController) getPublicNewsList(c *gin.Context, joinTable string, public func(models.PrivacySettings) bool) {
+    viewerID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+    ownerID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid user ID"))
+        return
+    }
+
+    var owner models.User
+    if err := uc.DB.Select("id").First(&owner, ownerID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrUserNotFound)
+        } else {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query database"))
+        }
+        return
+    }
+
+    // 本人总是可以查看；其他人需要列表公开且有权查看主页
+    if owner.ID != viewerID.(uint) {
+        settings, err := models.GetPrivacySettings(uc.DB, owner.ID)
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query database"))
+            return
+        }
+        canView, err := models.CanViewProfile(uc.DB, viewerID.(uint), owner.ID)
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query database"))
+            return
+        }
+        if !public(settings) || !canView {
+            apierror.Respond(c, apierror.ErrForbidden.WithMessage("This list is private"))
+            return
+        }
+    }
+
+    newsIDs := []uint{}
+    if err := uc.DB.Table(joinTable).
+        Joins("JOIN news ON news.id = "+joinTable+".news_id").
+        Where(joinTable+".user_id = ?", owner.ID).
+        Scopes(models.NotBlockedBy(viewerID.(uint), "news.author_id")).
+        Order(joinTable + ".news_id DESC").Pluck(joinTable+".news_id", &newsIDs).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query database"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"news_ids": newsIDs})
+}
@@ -0,0 +1,211 @@
+// internal/controllers/privacy_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+func TestPrivacySettings(t *testing.T) {
+    db := setupAccountTestDB()
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    userController := NewUserController(db, nil)
+    familyController := NewFamilyController(db)
+    collectionController := NewCollectionController(db)
+    authGroup := router.Group("")
+    authGroup.Use(middleware.AuthMiddleware())
+    {
+        authGroup.GET("/users/privacy", userController.GetPrivacySettings)
+        authGroup.PUT("/users/privacy", userController.UpdatePrivacySettings)
+        authGroup.GET("/users/:id/profile", userController.GetUserProfile)
+        authGroup.GET("/users/:id/liked", userController.GetUserLikedNews)
+        authGroup.GET("/users/:id/favorited", userController.GetUserFavoritedNews)
+        authGroup.GET("/families/details", familyController.FamilyDetails)
+        authGroup.GET("/collections", collectionController.GetCollections)
+        authGroup.GET("/collections/:id", collectionController.GetCollection)
+    }
+
+    owner := models.User{OpenID: "OpenID_Privacy_Owner", Nickname: "Owner"}
+    db.Create(&owner)
+    follower := models.User{OpenID: "OpenID_Privacy_Follower", Nickname: "Follower"}
+    db.Create(&follower)
+    stranger := models.User{OpenID: "OpenID_Privacy_Stranger", Nickname: "Stranger"}
+    db.Create(&stranger)
+    db.Create(&models.UserFollow{FollowerID: follower.ID, FolloweeID: owner.ID})
+
+    news := models.News{Title: "Owner news", AuthorID: owner.ID}
+    db.Create(&news)
+    models.NewsLikes.Link(db, owner.ID, news.ID)
+    models.NewsFavorites.Link(db, owner.ID, news.ID)
+    collection := models.Collection{UserID: owner.ID, Name: "Public", IsPublic: true}
+    db.Create(&collection)
+
+    request := func(method, url string, userID uint, body interface{}) *httptest.ResponseRecorder {
+        var reader *bytes.Reader
+        if body != nil {
+            data, _ := json.Marshal(body)
+            reader = bytes.NewReader(data)
+        } else {
+            reader = bytes.NewReader(nil)
+        }
+        req, _ := http.NewRequest(method, url, reader)
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    t.Run("Defaults And Update", func(t *testing.T) {
+        w := request("GET", "/users/privacy", owner.ID, nil)
+        assert.Equal(t, http.StatusOK, w.Code)
+        var settings models.PrivacySettings
+        json.Unmarshal(w.Body.Bytes(), &settings)
+        assert.Equal(t, models.ProfilePublic, settings.ProfileVisibility)
+        assert.Equal(t, models.FamilyShareAll, settings.FamilyDataSharing)
+        assert.False(t, settings.LikesPublic)
+
+        w = request("PUT", "/users/privacy", owner.ID, gin.H{"profile_visibility": "everyone"})
+        assert.Equal(t, http.StatusBadRequest, w.Code)
+        w = request("PUT", "/users/privacy", owner.ID, gin.H{"family_data_sharing": "some"})
+        assert.Equal(t, http.StatusBadRequest, w.Code)
+
+        w = request("PUT", "/users/privacy", owner.ID, gin.H{"likes_public": true})
+        assert.Equal(t, http.StatusOK, w.Code)
+        // 部分更新不影响其他字段
+        w = request("PUT", "/users/privacy", owner.ID, gin.H{"family_data_sharing": models.FamilyShareCarbon})
+        assert.Equal(t, http.StatusOK, w.Code)
+        settings, err := models.GetPrivacySettings(db, owner.ID)
+        assert.NoError(t, err)
+        assert.True(t, settings.LikesPublic)
+        assert.False(t, settings.FavoritesPublic)
+        assert.Equal(t, models.FamilyShareCarbon, settings.FamilyDataSharing)
+    })
+
+    t.Run("Profile Visibility", func(t *testing.T) {
+        profile := func(viewerID uint) map[string]interface{} {
+            w := request("GET", fmt.Sprintf("/users/%d/profile", owner.ID), viewerID, nil)
+            assert.Equal(t, http.StatusOK, w.Code)
+            var resp map[string]interface{}
+            json.Unmarshal(w.Body.Bytes(), &resp)
+            return resp
+        }
+
+        resp := profile(stranger.ID)
+        assert.Equal(t, false, resp["profile_hidden"])
+        assert.Len(t, resp["news"], 1)
+
+        request("PUT", "/users/privacy", owner.ID, gin.H{"profile_visibility": models.ProfileFollowers})
+        resp = profile(stranger.ID)
+        assert.Equal(t, true, resp["profile_hidden"])
+        assert.Equal(t, "Owner", resp["nickname"])
+        assert.Len(t, resp["news"], 0)
+        assert.Equal(t, false, profile(follower.ID)["profile_hidden"])
+
+        // 主页不可见时公开的收藏夹也不可见
+        w := request("GET", fmt.Sprintf("/collections?user_id=%d", owner.ID), stranger.ID, nil)
+        assert.Equal(t, http.StatusOK, w.Code)
+        var collections struct {
+            Collections []CollectionResponse `json:"collections"`
+        }
+        json.Unmarshal(w.Body.Bytes(), &collections)
+        assert.Len(t, collections.Collections, 0)
+        w = request("GET", fmt.Sprintf("/collections/%d", collection.ID), stranger.ID, nil)
+        assert.Equal(t, http.StatusNotFound, w.Code)
+        w = request("GET", fmt.Sprintf("/collections/%d", collection.ID), follower.ID, nil)
+        assert.Equal(t, http.StatusOK, w.Code)
+
+        request("PUT", "/users/privacy", owner.ID, gin.H{"profile_visibility": models.ProfilePrivate})
+        assert.Equal(t, true, profile(follower.ID)["profile_hidden"])
+        assert.Equal(t, false, profile(owner.ID)["profile_hidden"])
+        request("PUT", "/users/privacy", owner.ID, gin.H{"profile_visibility": models.ProfilePublic})
+    })
+
+    t.Run("Liked And Favorited Lists", func(t *testing.T) {
+        w := request("GET", fmt.Sprintf("/users/%d/liked", owner.ID), stranger.ID, nil)
+        assert.Equal(t, http.StatusOK, w.Code)
+        var resp struct {
+            NewsIDs []uint `json:"news_ids"`
+        }
+        json.Unmarshal(w.Body.Bytes(), &resp)
+        assert.Equal(t, []uint{news.ID}, resp.NewsIDs)
+
+        w = request("GET", fmt.Sprintf("/users/%d/favorited", owner.ID), stranger.ID, nil)
+        assert.Equal(t, http.StatusForbidden, w.Code)
+        w = request("GET", fmt.Sprintf("/users/%d/favorited", owner.ID), owner.ID, nil)
+        assert.Equal(t, http.StatusOK, w.Code)
+
+        // 屏蔽作者后不再看到其新闻
+        db.Create(&models.UserBlock{BlockerID: stranger.ID, BlockedID: owner.ID})
+        w = request("GET", fmt.Sprintf("/users/%d/liked", owner.ID), stranger.ID, nil)
+        json.Unmarshal(w.Body.Bytes(), &resp)
+        assert.Empty(t, resp.NewsIDs)
+
+        w = request("GET", "/users/999/liked", stranger.ID, nil)
+        assert.Equal(t, http.StatusNotFound, w.Code)
+    })
+
+    t.Run("Family Data Sharing", func(t *testing.T) {
+        family := models.Family{Name: "Privacy Home", Token: "privacy-token", MemberCount: 2}
+        db.Create(&family)
+        db.Model(&family).Association("Admins").Append(&follower)
+        db.Model(&family).Association("Members").Append(&owner)
+        db.Model(&models.User{}).Where("id IN ?", []uint{owner.ID, follower.ID}).Update("family_id", family.ID)
+
+        now := time.Now().UTC()
+        for _, id := range []uint{owner.ID, follower.ID} {
+            db.Create(&models.CarbonIntake{UserID: id, Date: now, MealType: models.Lunch, Emission: 2})
+            db.Create(&models.NutritionIntake{UserID: id, Date: now, MealType: models.Lunch, Calories: 500})
+        }
+
+        details := func(viewerID uint) map[string]interface{} {
+            w := request("GET", "/families/details?timezone=UTC", viewerID, nil)
+            assert.Equal(t, http.StatusOK, w.Code)
+            var resp map[string]interface{}
+            json.Unmarshal(w.Body.Bytes(), &resp)
+            return resp
+        }
+        memberData := func(resp map[string]interface{}, userID uint) map[string]interface{} {
+            for _, m := range resp["member_daily_data"].([]interface{}) {
+                data := m.(map[string]interface{})
+                if uint(data["user_id"].(float64)) == userID {
+                    return data
+                }
+            }
+            return nil
+        }
+
+        // owner 只共享碳排放数据
+        resp := details(follower.ID)
+        data := memberData(resp, owner.ID)
+        assert.Equal(t, true, data["shares_carbon"])
+        assert.Equal(t, false, data["shares_nutrition"])
+        assert.Equal(t, 2.0, data["carbon_intake_sum"])
+        assert.NotContains(t, data, "nutrition_intake_sum")
+        sums := resp["family_sums"].(map[string]interface{})
+        assert.Equal(t, 4.0, sums["carbon_intake_sum"])
+        assert.Equal(t, 500.0, sums["nutrition_intake_sum"].(map[string]interface{})["calories"])
+
+        // 本人的数据总是可见
+        data = memberData(details(owner.ID), owner.ID)
+        assert.Equal(t, true, data["shares_nutrition"])
+        assert.Contains(t, data, "nutrition_intake_sum")
+
+        request("PUT", "/users/privacy", owner.ID, gin.H{"family_data_sharing": models.FamilyShareNone})
+        resp = details(follower.ID)
+        data = memberData(resp, owner.ID)
+        assert.NotContains(t, data, "carbon_intake_sum")
+        assert.Equal(t, 2.0, resp["family_sums"].(map[string]interface{})["carbon_intake_sum"])
+    })
+}
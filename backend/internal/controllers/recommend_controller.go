@@ -6,6 +6,7 @@ import (
     "time"
     "github.com/gin-gonic/gin"
     "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
     "encoding/json"
     "os"
@@ -176,6 +177,36 @@ func (ic *RecommendController) loadFoodPreferences(preferences []models.FoodPref
     return foodPos_id, foodNeg_id, nil
 }
 
+// 辅助函数：加载饮食档案中的过敏食材和饮食限制排除的食材。
+// 过敏和清真、素食等宗教或伦理上的饮食限制都是硬性约束，不会出现在任何推荐结果中（包括兜底的食谱）
+func (ic *RecommendController) loadDietaryExclusions(userID uint) (allergen_id []uint, restricted_id []uint, err error) {
+    profile, err := models.GetDietaryProfile(ic.DB, userID)
+    if err != nil {
+        return nil, nil, err
+    }
+    if allergen_id, err = profile.AllergenFoodIDs(ic.DB); err != nil {
+        return nil, nil, err
+    }
+    if restricted_id, err = profile.RestrictedFoodIDs(ic.DB); err != nil {
+        return nil, nil, err
+    }
+    return allergen_id, restricted_id, nil
+}
+
+// 辅助函数：食谱是否含有指定的食材
+func (ic *RecommendController) recipeContainsAny(recipeID uint, foodIDs []uint) (bool, error) {
+    ingredientIds, err := models.GetIngredientIDsByRecipeID(ic.DB, recipeID)
+    if err != nil {
+        return false, err
+    }
+    for _, id := range ingredientIds {
+        if slices.Contains(foodIDs, id) {
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
 // 辅助函数：采样食材
 func sample(foodScores []foodScore, n int) []uint {
     if len(foodScores) == 0 {
@@ -219,29 +250,36 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
     log.Printf("开始推荐食材")
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+        apierror.Respond(c, apierror.ErrUnauthorized.WithMessage("用户未认证"))
         return
     }
     log.Printf("用户ID: %v", userID)
     
     var request IngredientRecommendRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+        apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("Invalid request format"))
         return
     }
 
     // 添加参数验证
     if len(request.LikedIngredients) > 100 || len(request.DislikedIngredients) > 100 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Too many ingredients selected"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Too many ingredients selected"))
         return
     }
     log.Printf("request: %v", request)
 
+    // 饮食档案中的过敏原和饮食限制
+    allergen_id, restricted_id, err := ic.loadDietaryExclusions(userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get dietary profile"))
+        return
+    }
+
     // 如果使用上一次的食材，则直接返回上次的食材
     if request.UseLastIngredients {
         var lastSelectedFoods []models.UserLastSelectedFoods
         if err := ic.DB.Where("user_id = ?", userID).Find(&lastSelectedFoods).Error; err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get last selected foods"})
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get last selected foods"))
             return
         }
         
@@ -252,8 +290,11 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
             ImageURL string `json:"image_url"`
         }, 0, len(lastSelectedFoods))
 
-        // 获取每个食材的详细信息
+        // 获取每个食材的详细信息，跳过过敏和饮食限制排除的食材
         for _, food := range lastSelectedFoods {
+            if slices.Contains(allergen_id, food.FoodID) || slices.Contains(restricted_id, food.FoodID) {
+                continue
+            }
             var foodInfo models.Food
             if err := ic.DB.First(&foodInfo, food.FoodID).Error; err != nil {
                 continue
@@ -281,7 +322,7 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
     for _, id := range append(request.LikedIngredients, request.DislikedIngredients...) {
         var food models.Food
         if err := ic.DB.Table("foods").First(&food, id).Error; err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ingredient ID"})
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid ingredient ID"))
             return
         }
     }
@@ -292,13 +333,13 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
     twentyFourHoursAgo := time.Now().Add(-24 * time.Hour)
     if err := tx.Where("user_id = ? AND select_time < ?", userID, twentyFourHoursAgo).Delete(&models.UserIngredientHistory{}).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "清理历史记录失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("清理历史记录失败"))
         return
     }
     log.Printf("清理历史记录成功")
     if err := tx.Where("user_id = ? AND update_time < ?", userID, twentyFourHoursAgo).Delete(&models.UserIngredientPreference{}).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "清理偏好记录失败"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("清理偏好记录失败"))
         return
     }
     tx.Commit()
@@ -316,7 +357,7 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
         }
         if err := tx.Save(&preference).Error; err != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save preferences"})
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to save preferences"))
             return
         }
     }
@@ -330,7 +371,7 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
         }
         if err := tx.Save(&preference).Error; err != nil {
             tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save preferences"})
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to save preferences"))
             return
         }
     }
@@ -341,7 +382,7 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
     // 获取所有食物名称
     foodNameResponse, err := models.GetAllFoodNames(ic.DB, "en")
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get food names"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get food names"))
         return
     }
     log.Printf("获取所有食物名称成功")
@@ -354,7 +395,7 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
     // 基于历史选择的食材
     var historyIngredients []models.UserIngredientHistory
     if err := ic.DB.Where("user_id = ?", userID).Find(&historyIngredients).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get history ingredients"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get history ingredients"))
         return
     }
     log.Printf("获取历史食材成功")
@@ -365,7 +406,7 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
     // 基于用户偏好，用户这次传输进来的偏好以及没有过期的偏好
     var preferences []models.UserIngredientPreference
     if err := ic.DB.Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get preferences"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get preferences"))
         return
     }
     log.Printf("获取偏好食材成功")
@@ -383,14 +424,14 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
     // 获取设置界面用户的食物偏好类型
     foodPreferences, err := models.GetUserFoodPreferences(ic.DB, userID.(uint))
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get food preferences"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get food preferences"))
         return
     }
     log.Printf("获取设置界面用户的食物偏好类型成功")
     foodPos_id, foodNeg_id, err := ic.loadFoodPreferences(foodPreferences)
     if err != nil {
         log.Printf("获取食物偏好失败: %v", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get food preferences"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get food preferences"))
         return
     }
 
@@ -399,6 +440,11 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
     }
     log.Printf("更新食物偏好类型成功")
 
+    // 过敏和饮食限制排除的食材不参与采样
+    for _, id := range append(allergen_id, restricted_id...) {
+        delete(ingredientScores, id)
+    }
+
     // 将map转换为带ID的切片
     foodScores := make([]foodScore, 0, len(ingredientScores))
     for id, score := range ingredientScores {
@@ -406,7 +452,7 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
     }
 
     if len(foodScores) == 0 {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "No valid ingredients found"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("No valid ingredients found"))
         return
     }
     
@@ -458,7 +504,7 @@ func (ic *RecommendController) RecommendIngredients(c *gin.Context) {
         })
     }
     if len(recommendedIngredients) == 0 {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "No recommended ingredients"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("No recommended ingredients"))
         return
     }
     log.Printf("构造推荐食材响应成功")
@@ -474,7 +520,7 @@ func (ic *RecommendController) RecommendRecipes(c *gin.Context) {
     log.Printf("开始推荐菜谱")
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+        apierror.Respond(c, apierror.ErrUnauthorized.WithMessage("用户未认证"))
         return
     }
     log.Printf("用户ID: %v", userID)
@@ -483,7 +529,7 @@ func (ic *RecommendController) RecommendRecipes(c *gin.Context) {
     oneWeekAgo := time.Now().Add(-7 * 24 * time.Hour)
     if err := tx.Where("user_id = ? AND select_time < ?", userID, oneWeekAgo).Delete(&models.UserRecipeHistory{}).Error; err != nil {
         tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear history data"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to clear history data"))
         return
     }
     tx.Commit()
@@ -492,7 +538,7 @@ func (ic *RecommendController) RecommendRecipes(c *gin.Context) {
     // 获取用户历史菜谱
     var historyRecipes []models.UserRecipeHistory
     if err := ic.DB.Where("user_id = ?", userID).Find(&historyRecipes).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get history recipes"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get history recipes"))
         return
     }
     log.Printf("获取历史菜谱成功")
@@ -500,49 +546,57 @@ func (ic *RecommendController) RecommendRecipes(c *gin.Context) {
     // 获取请求体
     var request RecipeRecommendAndSetUserLastSelectedFoodsRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+        apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("Invalid request format"))
         return
     }
     log.Printf("获取请求体成功")
 
     // 验证SelectedIngredients不为空
     if len(request.SelectedIngredients) == 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "No ingredients selected"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("No ingredients selected"))
         return
     }
     
     // 获取设置界面用户的食物偏好类型
     foodPreferences, err := models.GetUserFoodPreferences(ic.DB, userID.(uint))
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get food preferences"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get food preferences"))
         return
     }
     log.Printf("获取设置界面用户的食物偏好类型成功")
     
     _, foodNeg_id, err := ic.loadFoodPreferences(foodPreferences)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get food preferences"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get food preferences"))
         return
     }
 
     // 合并foodNeg_id 与 request.DislikedIngredients
     foodNeg_id = append(foodNeg_id, request.DislikedIngredients...)
 
+    // 过敏和饮食限制排除的食材是硬性约束，含有这些食材的食谱一律不返回
+    allergen_id, restricted_id, err := ic.loadDietaryExclusions(userID.(uint))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get dietary profile"))
+        return
+    }
+    excluded_id := append(allergen_id, restricted_id...)
+
 
 
     // 对于每一个食材id，获取包含该食材的食谱
     var recipes []models.Recipe
     maxAttempts := 20
     for _, ingredientID := range request.SelectedIngredients {
-        // 跳过负面食材
-        if slices.Contains(foodNeg_id, ingredientID) {
+        // 跳过负面食材和排除的食材
+        if slices.Contains(foodNeg_id, ingredientID) || slices.Contains(excluded_id, ingredientID) {
             continue
         }
 
         // 验证ingredientID是否存在
         var food models.Food
         if err := ic.DB.First(&food, ingredientID).Error; err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ingredient ID"})
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid ingredient ID"))
             return
         }
 
@@ -581,7 +635,7 @@ func (ic *RecommendController) RecommendRecipes(c *gin.Context) {
 
                 containsNeg := false
                 for _, id := range ingredientIds {
-                    if slices.Contains(foodNeg_id, id) {
+                    if slices.Contains(foodNeg_id, id) || slices.Contains(excluded_id, id) {
                         containsNeg = true
                         break
                     }
@@ -603,9 +657,26 @@ func (ic *RecommendController) RecommendRecipes(c *gin.Context) {
                     log.Printf("没有可用的食谱")
                     continue
                 }
-                recipe, err := models.GetRecipeByID(ic.DB, availableRecipes[0])
+                // 可以含有负面食材，但不能含有过敏或饮食限制排除的食材
+                fallbackID := uint(0)
+                for _, recipeID := range availableRecipes {
+                    containsExcluded, err := ic.recipeContainsAny(recipeID, excluded_id)
+                    if err != nil {
+                        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get recipe"))
+                        return
+                    }
+                    if !containsExcluded {
+                        fallbackID = recipeID
+                        break
+                    }
+                }
+                if fallbackID == 0 {
+                    log.Printf("可用的食谱都含有过敏或饮食限制排除的食材")
+                    continue
+                }
+                recipe, err := models.GetRecipeByID(ic.DB, fallbackID)
                 if err != nil {
-                    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get recipe"})
+                    apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to get recipe"))
                     return
                 }
                 recipes = append(recipes, *recipe)
@@ -649,20 +720,20 @@ func (ic *RecommendController) SetUserSelectedFoods(c *gin.Context) {
     log.Printf("开始设置用户选择的食材")
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "用户未认证"})
+        apierror.Respond(c, apierror.ErrUnauthorized.WithMessage("用户未认证"))
         return
     }
 
     // 获取请求体
     var request RecipeRecommendAndSetUserLastSelectedFoodsRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+        apierror.Respond(c, apierror.ErrInvalidRequest.WithMessage("Invalid request format"))
         return
     }
 
     // 验证SelectedIngredients不为空
     if len(request.SelectedIngredients) == 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "No ingredients selected"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("No ingredients selected"))
         return
     }
     
@@ -670,7 +741,7 @@ func (ic *RecommendController) SetUserSelectedFoods(c *gin.Context) {
     for _, ingredientID := range request.SelectedIngredients {
         var food models.Food
         if err := ic.DB.First(&food, ingredientID).Error; err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ingredient ID"})
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid ingredient ID"))
             return
         }
     }
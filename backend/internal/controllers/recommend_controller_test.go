@@ -119,6 +119,7 @@ func setupRecommendTestDB(t *testing.T) *gorm.DB {
         &models.UserLastSelectedFoods{},    
         &models.Recipe{}, 
         &models.FoodPreference{},
+        &models.DietaryProfile{},
     )
 	if err != nil {
 		t.Fatalf("迁移测试数据库失败: %v", err)
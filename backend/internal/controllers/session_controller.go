@@ -0,0 +1,171 @@
+// internal/controllers/session_controller.go
+package controllers
+
+import (
+    "crypto/subtle"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/config"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+// 会话设备信息的最大长度，与 models.Session 的字段长度一致
+const (
+    maxDeviceNameLength = 100
+    maxUserAgentLength  = 255
+)
+
+// 刷新令牌轮换时的失败原因
+var (
+    errRefreshTokenReused = errors.New("refresh token reused")
+    errRevokeRefreshToken = errors.New("failed to revoke refresh token")
+    errStoreRefreshToken  = errors.New("failed to store refresh token")
+)
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, max int) string {
+    runes := []rune(s)
+    if len(runes) > max {
+        return string(runes[:max])
+    }
+    return s
+}
+
+// newSession 根据登录请求创建会话记录（未保存）
+func newSession(c *gin.Context, userID uint, deviceName string) models.Session {
+    now := time.Now()
+    return models.Session{
+        UserID:     userID,
+        DeviceName: truncateRunes(deviceName, maxDeviceNameLength),
+        UserAgent:  truncateRunes(c.Request.UserAgent(), maxUserAgentLength),
+        IP:         c.ClientIP(),
+        LastUsedAt: now,
+        ExpiresAt:  now.Add(config.JWTConfig.RefreshTokenExpiration),
+    }
+}
+
+// newRefreshToken 生成新的 refresh token，返回令牌原文和待保存的记录（SessionID 由调用方填写）
+func (uc *UserController) newRefreshToken(userID uint) (string, *models.RefreshToken, error) {
+    jti, err := utils.NewTokenID()
+    if err != nil {
+        return "", nil, err
+    }
+    token, err := uc.Utils.GenerateRefreshToken(userID, jti)
+    if err != nil {
+        return "", nil, err
+    }
+    return token, &models.RefreshToken{
+        JTI:       jti,
+        TokenHash: utils.HashToken(token),
+        UserID:    userID,
+        ExpiresAt: time.Now().Add(config.JWTConfig.RefreshTokenExpiration),
+    }, nil
+}
+
+// findRefreshToken 按 JTI 查找 refresh token，并校验令牌摘要
+func (uc *UserController) findRefreshToken(token, jti string) (*models.RefreshToken, error) {
+    if jti == "" {
+        return nil, gorm.ErrRecordNotFound
+    }
+    var stored models.RefreshToken
+    if err := uc.DB.Where("jti = ?", jti).First(&stored).Error; err != nil {
+        return nil, err
+    }
+    if subtle.ConstantTimeCompare([]byte(stored.TokenHash), []byte(utils.HashToken(token))) != 1 {
+        return nil, gorm.ErrRecordNotFound
+    }
+    return &stored, nil
+}
+
+// rotateRefreshToken 在一个事务中撤销旧令牌并保存同一会话的新令牌；
+// 旧令牌已被并发请求轮换时返回 errRefreshTokenReused
+func (uc *UserController) rotateRefreshToken(old *models.RefreshToken, next *models.RefreshToken) error {
+    return uc.DB.Transaction(func(tx *gorm.DB) error {
+        result := tx.Model(old).Where("revoked = ?", false).Update("revoked", true)
+        if result.Error != nil {
+            return errRevokeRefreshToken
+        }
+        if result.RowsAffected == 0 {
+            return errRefreshTokenReused
+        }
+        next.SessionID = old.SessionID
+        if err := tx.Create(next).Error; err != nil {
+            return errStoreRefreshToken
+        }
+        if err := tx.Model(&models.Session{}).Where("id = ?", old.SessionID).Updates(map[string]interface{}{
+            "last_used_at": time.Now(),
+            "expires_at":   next.ExpiresAt,
+        }).Error; err != nil {
+            return errStoreRefreshToken
+        }
+        return nil
+    })
+}
+
+// revokeReusedSession 已撤销的令牌被再次使用，说明令牌可能已泄露，注销它所在的整个会话
+func (uc *UserController) revokeReusedSession(c *gin.Context, sessionID uint) {
+    if err := uc.DB.Transaction(func(tx *gorm.DB) error {
+        return models.RevokeSession(tx, sessionID)
+    }); err != nil {
+        log.Printf("注销会话 %d 失败: %v\n", sessionID, err)
+    }
+    apierror.Respond(c, apierror.ErrInvalidToken.WithMessage("Refresh token reuse detected"))
+}
+
+// GetSessions 获取自己当前登录的设备，最近使用的在前
+func (uc *UserController) GetSessions(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var sessions []models.Session
+    if err := uc.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+        Order("last_used_at DESC").Order("id DESC").Find(&sessions).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch sessions"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession 远程登出某个设备：撤销其 refresh token，已签发的 access token 在过期前仍然有效
+func (uc *UserController) RevokeSession(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+    sessionID, err := strconv.Atoi(c.Param("id"))
+    if err != nil || sessionID <= 0 {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid session ID"))
+        return
+    }
+
+    var session models.Session
+    if err := uc.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+        First(&session).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrNotFound.WithMessage("Session not found"))
+            return
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch session"))
+        return
+    }
+
+    if err := uc.DB.Transaction(func(tx *gorm.DB) error {
+        return models.RevokeSession(tx, session.ID)
+    }); err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to revoke session"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
@@ -0,0 +1,131 @@
+// internal/controllers/session_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+func TestRefreshTokenRotationAndSessions(t *testing.T) {
+    db := setupUserTestDB()
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    uc := NewUserController(db, utils.UtilsImpl{})
+    router.POST("/users/refresh", uc.RefreshTokenHandler)
+    router.POST("/users/logout", uc.LogoutHandler)
+    authGroup := router.Group("/users")
+    authGroup.Use(middleware.AuthMiddleware())
+    {
+        authGroup.GET("/sessions", uc.GetSessions)
+        authGroup.DELETE("/sessions/:id", uc.RevokeSession)
+    }
+
+    user := models.User{OpenID: "OpenID_Session_User"}
+    db.Create(&user)
+    other := models.User{OpenID: "OpenID_Session_Other"}
+    db.Create(&other)
+
+    // login 模拟一次设备登录，返回会话和 refresh token
+    login := func(userID uint, device string) (models.Session, string) {
+        token, rt, err := uc.newRefreshToken(userID)
+        assert.NoError(t, err)
+        session := models.Session{UserID: userID, DeviceName: device, LastUsedAt: time.Now(), ExpiresAt: rt.ExpiresAt}
+        db.Create(&session)
+        rt.SessionID = session.ID
+        db.Create(rt)
+        return session, token
+    }
+    post := func(url, token string) (int, map[string]interface{}) {
+        body, _ := json.Marshal(gin.H{"refresh_token": token})
+        req, _ := http.NewRequest("POST", url, bytes.NewBuffer(body))
+        req.Header.Set("Content-Type", "application/json")
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        var resp map[string]interface{}
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        return w.Code, resp
+    }
+    authed := func(method, url string, userID uint) *httptest.ResponseRecorder {
+        req, _ := http.NewRequest(method, url, nil)
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    t.Run("Rotation And Reuse Detection", func(t *testing.T) {
+        _, first := login(user.ID, "phone")
+
+        status, resp := post("/users/refresh", first)
+        assert.Equal(t, http.StatusOK, status)
+        second, _ := resp["refresh_token"].(string)
+        assert.NotEqual(t, first, second)
+
+        // 只保存摘要，按 JTI 查找
+        claims, err := utils.ValidateToken(second)
+        assert.NoError(t, err)
+        var stored models.RefreshToken
+        assert.NoError(t, db.Where("jti = ?", claims.ID).First(&stored).Error)
+        assert.Equal(t, utils.HashToken(second), stored.TokenHash)
+        assert.False(t, stored.Revoked)
+
+        // 旧令牌被重放 => 整个会话被注销，连最新的令牌也失效
+        status, resp = post("/users/refresh", first)
+        assert.Equal(t, http.StatusUnauthorized, status)
+        assert.Equal(t, "Refresh token reuse detected", resp["error"])
+        status, resp = post("/users/refresh", second)
+        assert.Equal(t, http.StatusUnauthorized, status)
+        assert.Equal(t, "Refresh token is expired or revoked", resp["error"])
+
+        var active int64
+        db.Model(&models.RefreshToken{}).Where("session_id = ? AND revoked = ?", stored.SessionID, false).Count(&active)
+        assert.Equal(t, int64(0), active)
+    })
+
+    t.Run("List And Revoke Sessions", func(t *testing.T) {
+        phone, phoneToken := login(user.ID, "phone")
+        tablet, tabletToken := login(user.ID, "tablet")
+        otherSession, _ := login(other.ID, "laptop")
+
+        w := authed("GET", "/users/sessions", user.ID)
+        assert.Equal(t, http.StatusOK, w.Code)
+        var resp struct {
+            Sessions []models.Session `json:"sessions"`
+        }
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        ids := make([]uint, len(resp.Sessions))
+        for i, s := range resp.Sessions {
+            ids[i] = s.ID
+        }
+        assert.ElementsMatch(t, []uint{phone.ID, tablet.ID}, ids)
+
+        assert.Equal(t, http.StatusNotFound, authed("DELETE", fmt.Sprintf("/users/sessions/%d", otherSession.ID), user.ID).Code)
+        assert.Equal(t, http.StatusOK, authed("DELETE", fmt.Sprintf("/users/sessions/%d", tablet.ID), user.ID).Code)
+        assert.Equal(t, http.StatusNotFound, authed("DELETE", fmt.Sprintf("/users/sessions/%d", tablet.ID), user.ID).Code)
+
+        // 被远程登出的设备不能再刷新，其他设备不受影响
+        status, _ := post("/users/refresh", tabletToken)
+        assert.Equal(t, http.StatusUnauthorized, status)
+        status, resp2 := post("/users/refresh", phoneToken)
+        assert.Equal(t, http.StatusOK, status)
+
+        // 登出当前设备后会话从列表中消失
+        phoneToken, _ = resp2["refresh_token"].(string)
+        status, _ = post("/users/logout", phoneToken)
+        assert.Equal(t, http.StatusOK, status)
+        w = authed("GET", "/users/sessions", user.ID)
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        assert.Empty(t, resp.Sessions)
+    })
+}
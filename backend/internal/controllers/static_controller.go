@@ -0,0 +1,44 @@
+// internal/controllers/static_controller.go
+package controllers
+
+import (
+    "errors"
+    "net/http"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+)
+
+// StaticController 为对象存储中的上传文件提供 /static 访问地址
+type StaticController struct {
+    Storage storage.Storage
+}
+
+func NewStaticController(store storage.Storage) *StaticController {
+    return &StaticController{Storage: store}
+}
+
+// ServeFile 重定向到限时有效的签名 URL，客户端仍可使用 /static/<相对路径> 访问文件
+func (sc *StaticController) ServeFile(c *gin.Context) {
+    key, err := storage.CleanKey(strings.TrimPrefix(c.Param("filepath"), "/"))
+    if err != nil {
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("File not found"))
+        return
+    }
+
+    signedURL, err := sc.Storage.SignedURL(c.Request.Context(), key, storage.DefaultSignedURLExpiry)
+    if errors.Is(err, storage.ErrNotExist) {
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage("File not found"))
+        return
+    }
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to sign file URL"))
+        return
+    }
+
+    // 重定向的有效期不能超过签名本身
+    c.Header("Cache-Control", "private, max-age=300")
+    c.Redirect(http.StatusFound, signedURL)
+}
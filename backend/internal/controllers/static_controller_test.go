@@ -0,0 +1,58 @@
+// internal/controllers/static_controller_test.go
+package controllers
+
+import (
+    "context"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage/s3test"
+)
+
+func TestServeStaticFromS3(t *testing.T) {
+    server := s3test.NewServer("uploads")
+    defer server.Close()
+    store, err := storage.NewS3(server.Config())
+    assert.NoError(t, err)
+    assert.NoError(t, store.Put(context.Background(), "images/news/ab/abcd/large.jpg", []byte("jpeg-bytes"), "image/jpeg"))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    staticController := NewStaticController(store)
+    router.GET("/static/*filepath", staticController.ServeFile)
+
+    tests := []struct {
+        name           string
+        path           string
+        expectedStatus int
+    }{
+        {name: "Redirect To Signed URL", path: "/static/images/news/ab/abcd/large.jpg", expectedStatus: http.StatusFound},
+        {name: "Path Traversal", path: "/static/images/../../etc/passwd", expectedStatus: http.StatusNotFound},
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            req, _ := http.NewRequest("GET", tc.path, nil)
+            req.URL.Path = tc.path // 保留未规范化的路径
+            w := httptest.NewRecorder()
+            router.ServeHTTP(w, req)
+            assert.Equal(t, tc.expectedStatus, w.Code)
+
+            if tc.expectedStatus == http.StatusFound {
+                // 签名 URL 可直接下载文件
+                resp, err := http.Get(w.Header().Get("Location"))
+                if assert.NoError(t, err) {
+                    body, _ := io.ReadAll(resp.Body)
+                    resp.Body.Close()
+                    assert.Equal(t, http.StatusOK, resp.StatusCode)
+                    assert.Equal(t, "jpeg-bytes", string(body))
+                }
+            }
+        })
+    }
+}
@@ -0,0 +1,288 @@
+// internal/controllers/upload_gc_controller.go
+package controllers
+
+import (
+    "context"
+    "net/http"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+)
+
+// DefaultUploadGCGracePeriod 孤立文件默认保留时间，覆盖上传图片后尚未保存草稿的时间窗口
+const DefaultUploadGCGracePeriod = 72 * time.Hour
+
+// defaultAvatarKey 新用户的默认头像，始终保留
+const defaultAvatarKey = "avatars/default.jpg"
+
+type UploadGCController struct {
+    DB *gorm.DB
+}
+
+func NewUploadGCController(db *gorm.DB) *UploadGCController {
+    return &UploadGCController{DB: db}
+}
+
+// OrphanUpload 没有被任何新闻、草稿、历史版本或用户头像引用的文件
+type OrphanUpload struct {
+    Key     string    `json:"key"`
+    Size    int64     `json:"size"`
+    ModTime time.Time `json:"mod_time"`
+}
+
+// UploadGCReport 一次垃圾回收的结果
+type UploadGCReport struct {
+    DryRun        bool           `json:"dry_run"`
+    GracePeriod   string         `json:"grace_period"`
+    Scanned       int            `json:"scanned"`        // 扫描的文件数
+    Referenced    int            `json:"referenced"`     // 仍被引用的文件数
+    Pending       int            `json:"pending"`        // 未超过宽限期、暂不删除的孤立文件数
+    Orphans       []OrphanUpload `json:"orphans"`        // 超过宽限期的孤立文件，dry-run 时即将被删除的文件
+    Deleted       int            `json:"deleted"`        // 实际删除的文件数
+    FreedBytes    int64          `json:"freed_bytes"`    // 实际释放的空间
+    DeletedImages int            `json:"deleted_images"` // 删除的图片记录数
+}
+
+// UploadGCGracePeriod 读取 UPLOAD_GC_GRACE_PERIOD（如 72h），未设置或无效时使用默认值
+func UploadGCGracePeriod() time.Duration {
+    if v := os.Getenv("UPLOAD_GC_GRACE_PERIOD"); v != "" {
+        if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+            return d
+        }
+    }
+    return DefaultUploadGCGracePeriod
+}
+
+// imageDirOf 返回处理后图片文件所在的目录（images/<类型>/<哈希前两位>/<哈希>），其它文件返回空字符串
+func imageDirOf(key string) string {
+    if !isProcessedImage(key) {
+        return ""
+    }
+    parts := strings.SplitN(key, "/", 5)
+    if len(parts) < 5 {
+        return ""
+    }
+    return strings.Join(parts[:4], "/")
+}
+
+// lastUsedAt 图片最近一次上传的时间，早期记录没有 updated_at
+func lastUsedAt(img *models.UploadedImage) time.Time {
+    if img.UpdatedAt.After(img.CreatedAt) {
+        return img.UpdatedAt
+    }
+    return img.CreatedAt
+}
+
+// referencedUploads 收集所有被引用的文件：用户头像、新闻和草稿的图片及图片块，以及历史版本中的图片
+func (gc *UploadGCController) referencedUploads() (map[string]bool, error) {
+    refs := map[string]bool{defaultAvatarKey: true}
+    add := func(keys []string) {
+        for _, k := range keys {
+            if k != "" {
+                refs[k] = true
+            }
+        }
+    }
+
+    var keys []string
+    if err := gc.DB.Model(&models.User{}).Pluck("avatar_url", &keys).Error; err != nil {
+        return nil, err
+    }
+    add(keys)
+    for _, model := range []interface{}{&models.NewsImage{}, &models.DraftImage{}} {
+        keys = nil
+        if err := gc.DB.Model(model).Pluck("url", &keys).Error; err != nil {
+            return nil, err
+        }
+        add(keys)
+    }
+    for _, model := range []interface{}{&models.NewsBlock{}, &models.DraftBlock{}} {
+        keys = nil
+        if err := gc.DB.Model(model).Where("type = ?", models.BlockImage).Pluck("url", &keys).Error; err != nil {
+            return nil, err
+        }
+        add(keys)
+    }
+
+    // 恢复历史版本时会重新引用其中的图片
+    addRevision := func(encoded string) {
+        content, err := models.DecodeRevisionContent(encoded)
+        if err != nil {
+            return
+        }
+        for _, img := range content.Images {
+            add([]string{img.URL})
+        }
+        for _, b := range content.Blocks {
+            if b.Type == models.BlockImage {
+                add([]string{b.URL})
+            }
+        }
+    }
+    var newsRevisions []models.NewsRevision
+    if err := gc.DB.Select("id", "content").FindInBatches(&newsRevisions, 500, func(tx *gorm.DB, batch int) error {
+        for _, r := range newsRevisions {
+            addRevision(r.Content)
+        }
+        return nil
+    }).Error; err != nil {
+        return nil, err
+    }
+    var draftRevisions []models.DraftRevision
+    if err := gc.DB.Select("id", "content").FindInBatches(&draftRevisions, 500, func(tx *gorm.DB, batch int) error {
+        for _, r := range draftRevisions {
+            addRevision(r.Content)
+        }
+        return nil
+    }).Error; err != nil {
+        return nil, err
+    }
+    return refs, nil
+}
+
+// CollectOrphanUploads 扫描存储中的上传文件，删除未被引用且超过宽限期的文件。dryRun 时只生成报告。
+// 处理后的图片按目录整体判断：任一版本被引用则保留全部版本；删除时先删除图片记录，避免去重命中已删除的文件
+func (gc *UploadGCController) CollectOrphanUploads(ctx context.Context, now time.Time, grace time.Duration, dryRun bool) (*UploadGCReport, error) {
+    report := &UploadGCReport{DryRun: dryRun, GracePeriod: grace.String(), Orphans: []OrphanUpload{}}
+    cutoff := now.Add(-grace)
+
+    refs, err := gc.referencedUploads()
+    if err != nil {
+        return nil, err
+    }
+
+    // 图片记录及其是否被引用
+    var images []models.UploadedImage
+    if err := gc.DB.Find(&images).Error; err != nil {
+        return nil, err
+    }
+    imagesByDir := make(map[string]*models.UploadedImage, len(images))
+    referencedDirs := make(map[string]bool)
+    for i := range images {
+        dir := images[i].ImageDir()
+        imagesByDir[dir] = &images[i]
+        if refs[images[i].Path] {
+            referencedDirs[dir] = true
+        }
+        for _, v := range images[i].VariantList() {
+            if refs[v.URL] {
+                referencedDirs[dir] = true
+            }
+        }
+    }
+    for key := range refs {
+        if dir := imageDirOf(key); dir != "" {
+            referencedDirs[dir] = true
+        }
+    }
+
+    store, err := storage.Default()
+    if err != nil {
+        return nil, err
+    }
+
+    // 找出超过宽限期的孤立文件
+    var candidates []storage.ObjectInfo
+    for _, prefix := range storage.UploadPrefixes {
+        err := store.List(ctx, prefix, func(obj storage.ObjectInfo) error {
+            report.Scanned++
+            dir := imageDirOf(obj.Key)
+            if refs[obj.Key] || (dir != "" && referencedDirs[dir]) {
+                report.Referenced++
+                return nil
+            }
+
+            lastUsed := obj.ModTime
+            if img, ok := imagesByDir[dir]; ok && lastUsedAt(img).After(lastUsed) {
+                lastUsed = lastUsedAt(img)
+            }
+            if lastUsed.After(cutoff) {
+                report.Pending++
+                return nil
+            }
+            candidates = append(candidates, obj)
+            report.Orphans = append(report.Orphans, OrphanUpload{Key: obj.Key, Size: obj.Size, ModTime: obj.ModTime})
+            return nil
+        })
+        if err != nil {
+            return nil, err
+        }
+    }
+    if dryRun {
+        return report, nil
+    }
+
+    // 删除图片记录；记录在扫描后被重新使用（updated_at 已刷新）时保留其文件
+    skipDirs := make(map[string]bool)
+    for dir, img := range imagesByDir {
+        if referencedDirs[dir] || lastUsedAt(img).After(cutoff) {
+            continue
+        }
+        result := gc.DB.Where("id = ? AND COALESCE(updated_at, created_at) <= ?", img.ID, cutoff).Delete(&models.UploadedImage{})
+        if result.Error != nil {
+            return report, result.Error
+        }
+        if result.RowsAffected == 0 {
+            skipDirs[dir] = true
+            continue
+        }
+        report.DeletedImages++
+    }
+
+    for _, obj := range candidates {
+        if skipDirs[imageDirOf(obj.Key)] {
+            continue
+        }
+        if err := store.Delete(ctx, obj.Key); err != nil {
+            return report, err
+        }
+        report.Deleted++
+        report.FreedBytes += obj.Size
+    }
+    return report, nil
+}
+
+// parseGracePeriod 读取 grace_hours 查询参数，未提供时使用配置的宽限期
+func parseGracePeriod(c *gin.Context) (time.Duration, bool) {
+    v := c.Query("grace_hours")
+    if v == "" {
+        return UploadGCGracePeriod(), true
+    }
+    hours, err := strconv.Atoi(v)
+    if err != nil || hours < 0 {
+        return 0, false
+    }
+    return time.Duration(hours) * time.Hour, true
+}
+
+// ListOrphanUploads 报告孤立文件（dry-run，不删除任何文件）
+func (gc *UploadGCController) ListOrphanUploads(c *gin.Context) {
+    gc.runGC(c, true)
+}
+
+// CollectUploads 删除超过宽限期的孤立文件
+func (gc *UploadGCController) CollectUploads(c *gin.Context) {
+    gc.runGC(c, c.Query("dry_run") == "true")
+}
+
+func (gc *UploadGCController) runGC(c *gin.Context, dryRun bool) {
+    grace, ok := parseGracePeriod(c)
+    if !ok {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid grace period"))
+        return
+    }
+
+    report, err := gc.CollectOrphanUploads(c.Request.Context(), time.Now(), grace, dryRun)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to collect orphaned uploads"))
+        return
+    }
+    c.JSON(http.StatusOK, report)
+}
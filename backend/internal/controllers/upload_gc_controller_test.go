@@ -0,0 +1,205 @@
+// internal/controllers/upload_gc_controller_test.go
+package controllers
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "sort"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+)
+
+// setupUploadGCRouter 初始化管理员路由
+func setupUploadGCRouter(db *gorm.DB) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    uploadGCController := NewUploadGCController(db)
+    adminGroup := router.Group("/admin")
+    adminGroup.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+    {
+        adminGroup.GET("/uploads/orphans", uploadGCController.ListOrphanUploads)
+        adminGroup.POST("/uploads/gc", uploadGCController.CollectUploads)
+    }
+    return router
+}
+
+// putUpload 写入一个上传文件并设置修改时间
+func putUpload(t *testing.T, root, key string, modTime time.Time) {
+    assert.NoError(t, storage.NewLocal(root, "/static").Put(context.Background(), key, []byte(key), ""))
+    p := filepath.Join(root, filepath.FromSlash(key))
+    assert.NoError(t, os.Chtimes(p, modTime, modTime))
+}
+
+// createProcessedImage 写入一张处理后图片的两个版本并创建图片记录
+func createProcessedImage(t *testing.T, db *gorm.DB, root, hash string, usedAt time.Time) models.UploadedImage {
+    dir := imageStoragePrefix("news", hash)
+    img := models.UploadedImage{Hash: hash, Kind: "news", Path: dir + "/large.jpg"}
+    assert.NoError(t, img.SetVariants([]models.ImageVariant{
+        {Name: "thumb", Format: "jpeg", URL: dir + "/thumb.jpg"},
+        {Name: "large", Format: "jpeg", URL: dir + "/large.jpg"},
+    }))
+    assert.NoError(t, db.Create(&img).Error)
+    assert.NoError(t, db.Model(&img).UpdateColumns(map[string]interface{}{"created_at": usedAt, "updated_at": usedAt}).Error)
+
+    old := usedAt.Add(-time.Hour)
+    putUpload(t, root, dir+"/thumb.jpg", old)
+    putUpload(t, root, dir+"/large.jpg", old)
+    return img
+}
+
+func orphanKeys(report UploadGCReport) []string {
+    keys := make([]string, 0, len(report.Orphans))
+    for _, o := range report.Orphans {
+        keys = append(keys, o.Key)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+func TestCollectOrphanUploads(t *testing.T) {
+    db := setupNewsTestDB()
+    router := setupUploadGCRouter(db)
+    root := t.TempDir()
+    t.Setenv("BASE_UPLOAD_PATH", root)
+
+    admin := models.User{OpenID: "OpenID_GC_Admin", Nickname: "Admin", AvatarURL: "avatars/2_current.jpg"}
+    db.Create(&admin)
+    other := models.User{OpenID: "OpenID_GC_User", Nickname: "User", AvatarURL: "avatars/default.jpg"}
+    db.Create(&other)
+    t.Setenv("ADMIN_USER_IDS", fmt.Sprintf("999, %d", admin.ID))
+
+    now := time.Now()
+    old := now.Add(-10 * 24 * time.Hour)
+    recent := now.Add(-time.Hour)
+
+    putUpload(t, root, "avatars/default.jpg", old)
+    putUpload(t, root, "avatars/1_old.jpg", old)
+    putUpload(t, root, "avatars/2_current.jpg", old)
+    putUpload(t, root, "drafts/1/abandoned.jpg", old)
+    putUpload(t, root, "drafts/1/recent.jpg", recent)
+    putUpload(t, root, "drafts/2/in_revision.jpg", old)
+    putUpload(t, root, "drafts/3/in_block.jpg", old)
+
+    // 历史版本和正文块中的图片仍被引用
+    content, _ := models.EncodeRevisionContent(models.RevisionContent{Images: []models.RevisionImage{{URL: "drafts/2/in_revision.jpg"}}})
+    db.Create(&models.DraftRevision{DraftID: 2, Version: 1, Title: "v1", Content: content})
+    db.Create(&models.DraftBlock{DraftID: 3, ContentBlock: models.ContentBlock{Type: models.BlockImage, URL: "drafts/3/in_block.jpg"}})
+
+    // 被新闻引用的图片保留全部版本；未引用的旧图片整体删除；最近被复用的图片暂时保留
+    used := createProcessedImage(t, db, root, "aa11", old)
+    db.Create(&models.NewsImage{NewsID: 1, URL: used.Path})
+    unused := createProcessedImage(t, db, root, "bb22", old)
+    reused := createProcessedImage(t, db, root, "cc33", recent)
+
+    expectedOrphans := []string{
+        "avatars/1_old.jpg",
+        "drafts/1/abandoned.jpg",
+        imageStoragePrefix("news", "bb22") + "/large.jpg",
+        imageStoragePrefix("news", "bb22") + "/thumb.jpg",
+    }
+
+    request := func(method, path string, userID uint) (int, UploadGCReport) {
+        req, _ := http.NewRequest(method, path, nil)
+        req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        var report UploadGCReport
+        _ = json.Unmarshal(w.Body.Bytes(), &report)
+        return w.Code, report
+    }
+
+    t.Run("Non Admin", func(t *testing.T) {
+        status, _ := request("POST", "/admin/uploads/gc", other.ID)
+        assert.Equal(t, http.StatusForbidden, status)
+    })
+
+    t.Run("Invalid Grace Period", func(t *testing.T) {
+        status, _ := request("GET", "/admin/uploads/orphans?grace_hours=-1", admin.ID)
+        assert.Equal(t, http.StatusBadRequest, status)
+    })
+
+    t.Run("Dry Run", func(t *testing.T) {
+        status, report := request("GET", "/admin/uploads/orphans", admin.ID)
+        assert.Equal(t, http.StatusOK, status)
+        assert.True(t, report.DryRun)
+        assert.Equal(t, 13, report.Scanned)
+        assert.Equal(t, 6, report.Referenced)
+        assert.Equal(t, 3, report.Pending) // drafts/1/recent.jpg 以及 cc33 的两个版本
+        assert.Equal(t, expectedOrphans, orphanKeys(report))
+        assert.Equal(t, 0, report.Deleted)
+
+        // 不删除任何文件
+        _, err := os.Stat(filepath.Join(root, "avatars/1_old.jpg"))
+        assert.NoError(t, err)
+    })
+
+    t.Run("Collect", func(t *testing.T) {
+        status, report := request("POST", "/admin/uploads/gc", admin.ID)
+        assert.Equal(t, http.StatusOK, status)
+        assert.False(t, report.DryRun)
+        assert.Equal(t, 4, report.Deleted)
+        assert.Equal(t, 1, report.DeletedImages)
+
+        for _, key := range expectedOrphans {
+            _, err := os.Stat(filepath.Join(root, filepath.FromSlash(key)))
+            assert.True(t, os.IsNotExist(err), key)
+        }
+        for _, key := range []string{"avatars/default.jpg", "avatars/2_current.jpg", "drafts/1/recent.jpg", "drafts/2/in_revision.jpg", used.Path, reused.Path} {
+            _, err := os.Stat(filepath.Join(root, filepath.FromSlash(key)))
+            assert.NoError(t, err, key)
+        }
+
+        var count int64
+        db.Model(&models.UploadedImage{}).Where("id = ?", unused.ID).Count(&count)
+        assert.Equal(t, int64(0), count)
+        db.Model(&models.UploadedImage{}).Count(&count)
+        assert.Equal(t, int64(2), count)
+    })
+
+    t.Run("Grace Period Override", func(t *testing.T) {
+        status, report := request("POST", "/admin/uploads/gc?grace_hours=0&dry_run=true", admin.ID)
+        assert.Equal(t, http.StatusOK, status)
+        assert.True(t, report.DryRun)
+        assert.Equal(t, 0, report.Pending)
+        assert.Len(t, report.Orphans, 3)
+    })
+}
+
+func TestCollectOrphanUploadsSkipsReusedImage(t *testing.T) {
+    db := setupNewsTestDB()
+    root := t.TempDir()
+    t.Setenv("BASE_UPLOAD_PATH", root)
+
+    old := time.Now().Add(-10 * 24 * time.Hour)
+    img := createProcessedImage(t, db, root, "dd44", old)
+
+    // 扫描后、删除前图片被再次上传（去重命中会刷新 updated_at）
+    var once bool
+    db.Callback().Delete().Before("gorm:delete").Register("touch_before_gc_delete", func(tx *gorm.DB) {
+        if tx.Statement.Table == "uploaded_images" && !once {
+            once = true
+            tx.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Exec("UPDATE uploaded_images SET updated_at = ? WHERE id = ?", time.Now(), img.ID)
+        }
+    })
+    defer db.Callback().Delete().Remove("touch_before_gc_delete")
+
+    report, err := NewUploadGCController(db).CollectOrphanUploads(context.Background(), time.Now(), DefaultUploadGCGracePeriod, false)
+    assert.NoError(t, err)
+    assert.Len(t, report.Orphans, 2)
+    assert.Equal(t, 0, report.Deleted)
+    assert.Equal(t, 0, report.DeletedImages)
+    _, err = os.Stat(filepath.Join(root, filepath.FromSlash(img.Path)))
+    assert.NoError(t, err)
+}
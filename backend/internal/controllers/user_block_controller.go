@@ -0,0 +1,130 @@
+// internal/controllers/user_block_controller.go
+package controllers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+// BlockedUserItem 屏蔽列表中的一个用户
+type BlockedUserItem struct {
+    User      AuthorInfo `json:"user"`
+    BlockedAt time.Time  `json:"blocked_at"`
+}
+
+// blockTarget 解析并校验要屏蔽的用户，出错时已写入响应
+func (uc *UserController) blockTarget(c *gin.Context) (blockerID, blockedID uint, ok bool) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return 0, 0, false
+    }
+    targetID, err := strconv.Atoi(c.Param("id"))
+    if err != nil || targetID <= 0 {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid user ID"))
+        return 0, 0, false
+    }
+    if uint(targetID) == userID.(uint) {
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("Cannot block yourself"))
+        return 0, 0, false
+    }
+
+    var target models.User
+    if err := uc.DB.Select("id").First(&target, targetID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrUserNotFound)
+            return 0, 0, false
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch user data"))
+        return 0, 0, false
+    }
+    return userID.(uint), target.ID, true
+}
+
+// BlockUser 屏蔽用户，之后看不到对方的新闻和评论，对方也不能评论自己的新闻或 @ 自己
+func (uc *UserController) BlockUser(c *gin.Context) {
+    blockerID, blockedID, ok := uc.blockTarget(c)
+    if !ok {
+        return
+    }
+
+    result := uc.DB.Clauses(clause.OnConflict{DoNothing: true}).
+        Create(&models.UserBlock{BlockerID: blockerID, BlockedID: blockedID})
+    if result.Error != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to block user"))
+        return
+    }
+    if result.RowsAffected == 0 {
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("You have already blocked this user"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "User blocked successfully"})
+}
+
+// UnblockUser 取消屏蔽用户
+func (uc *UserController) UnblockUser(c *gin.Context) {
+    blockerID, blockedID, ok := uc.blockTarget(c)
+    if !ok {
+        return
+    }
+
+    result := uc.DB.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&models.UserBlock{})
+    if result.Error != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to unblock user"))
+        return
+    }
+    if result.RowsAffected == 0 {
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You have not blocked this user"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "User unblocked successfully"})
+}
+
+// GetBlockedUsers 获取自己屏蔽的用户，最近屏蔽的在前
+func (uc *UserController) GetBlockedUsers(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var blocks []models.UserBlock
+    if err := uc.DB.Where("blocker_id = ?", userID).Order("created_at DESC").Find(&blocks).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch blocked users"))
+        return
+    }
+    ids := make([]uint, len(blocks))
+    for i, b := range blocks {
+        ids[i] = b.BlockedID
+    }
+
+    var users []models.User
+    if len(ids) > 0 {
+        if err := uc.DB.Select("id, nickname, avatar_url").Where("id IN ?", ids).Find(&users).Error; err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch blocked users"))
+            return
+        }
+    }
+    byID := make(map[uint]models.User, len(users))
+    for _, u := range users {
+        byID[u.ID] = u
+    }
+
+    items := make([]BlockedUserItem, 0, len(blocks))
+    for _, b := range blocks {
+        u := byID[b.BlockedID]
+        items = append(items, BlockedUserItem{
+            User:      AuthorInfo{ID: b.BlockedID, Nickname: u.Nickname, AvatarURL: u.AvatarURL},
+            BlockedAt: b.CreatedAt,
+        })
+    }
+    c.JSON(http.StatusOK, gin.H{"blocked_users": items})
+}
@@ -0,0 +1,194 @@
+// internal/controllers/user_block_controller_test.go
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+func TestUserBlocking(t *testing.T) {
+    db := setupNewsTestDB()
+    assert.NoError(t, db.AutoMigrate(&models.News{}, &models.Comment{}))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    newsController := NewNewsController(db)
+    userController := NewUserController(db, nil)
+    notificationController := NewNotificationController(db)
+    publicGroup := router.Group("")
+    publicGroup.Use(middleware.OptionalAuthMiddleware())
+    {
+        publicGroup.GET("/news/details/news/:id", newsController.GetNewsDetails)
+        publicGroup.GET("/news/feed", newsController.GetNewsFeed)
+        publicGroup.GET("/news/paginated/upload_time", newsController.GetNewsByUploadTime)
+        publicGroup.POST("/news/search", newsController.SearchNews)
+        publicGroup.GET("/news/:id/comments", newsController.GetNewsComments)
+        publicGroup.GET("/news/comments/:id/replies", newsController.GetCommentReplies)
+    }
+    authGroup := router.Group("")
+    authGroup.Use(middleware.AuthMiddleware())
+    {
+        authGroup.POST("/news/comments", newsController.AddComment)
+        authGroup.POST("/users/:id/block", userController.BlockUser)
+        authGroup.DELETE("/users/:id/block", userController.UnblockUser)
+        authGroup.GET("/users/blocks", userController.GetBlockedUsers)
+        authGroup.GET("/users/liked", userController.GetMyLikedNews)
+        authGroup.GET("/notifications", notificationController.GetNotifications)
+    }
+
+    blocker := models.User{OpenID: "OpenID_Block_Blocker", Nickname: "blocker"}
+    db.Create(&blocker)
+    troll := models.User{OpenID: "OpenID_Block_Troll", Nickname: "troll"}
+    db.Create(&troll)
+    other := models.User{OpenID: "OpenID_Block_Other", Nickname: "other"}
+    db.Create(&other)
+
+    blockerNews := models.News{Title: "Blocker Writes", AuthorID: blocker.ID}
+    db.Create(&blockerNews)
+    trollNews := models.News{Title: "Troll Writes", AuthorID: troll.ID}
+    db.Create(&trollNews)
+    thread := models.Comment{NewsID: blockerNews.ID, UserID: other.ID, Content: "thread"}
+    db.Create(&thread)
+    trollReply := models.Comment{NewsID: blockerNews.ID, UserID: troll.ID, Content: "troll reply", IsReply: true, ParentID: &thread.ID}
+    db.Create(&trollReply)
+    trollComment := models.Comment{NewsID: blockerNews.ID, UserID: troll.ID, Content: "troll comment"}
+    db.Create(&trollComment)
+    db.Model(&troll).Association("LikedNews").Append(&trollNews)
+    db.Model(&blocker).Association("LikedNews").Append(&trollNews)
+    // 屏蔽前收到的通知
+    db.Create(&models.Notification{UserID: blocker.ID, ActorID: troll.ID, Type: models.NotificationComment, NewsID: blockerNews.ID})
+
+    request := func(method, url string, userID uint, body string) *httptest.ResponseRecorder {
+        req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+        if body != "" {
+            req.Header.Set("Content-Type", "application/json")
+        }
+        if userID != 0 {
+            req.Header.Set("Authorization", "Bearer "+generateValidJWTNews(userID))
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+    newsIDs := func(w *httptest.ResponseRecorder) []uint {
+        var resp struct {
+            NewsIDs []uint `json:"news_ids"`
+        }
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        return resp.NewsIDs
+    }
+
+    t.Run("Block And Unblock", func(t *testing.T) {
+        w := request("POST", fmt.Sprintf("/users/%d/block", blocker.ID), blocker.ID, "")
+        assert.Equal(t, http.StatusBadRequest, w.Code)
+        assert.JSONEq(t, `{"error":"Cannot block yourself"}`, w.Body.String())
+        assert.Equal(t, http.StatusNotFound, request("POST", "/users/99999/block", blocker.ID, "").Code)
+
+        assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/users/%d/block", other.ID), blocker.ID, "").Code)
+        assert.Equal(t, http.StatusOK, request("DELETE", fmt.Sprintf("/users/%d/block", other.ID), blocker.ID, "").Code)
+        w = request("DELETE", fmt.Sprintf("/users/%d/block", other.ID), blocker.ID, "")
+        assert.JSONEq(t, `{"error":"You have not blocked this user"}`, w.Body.String())
+
+        assert.Equal(t, http.StatusOK, request("POST", fmt.Sprintf("/users/%d/block", troll.ID), blocker.ID, "").Code)
+        w = request("POST", fmt.Sprintf("/users/%d/block", troll.ID), blocker.ID, "")
+        assert.JSONEq(t, `{"error":"You have already blocked this user"}`, w.Body.String())
+
+        w = request("GET", "/users/blocks", blocker.ID, "")
+        var resp struct {
+            BlockedUsers []BlockedUserItem `json:"blocked_users"`
+        }
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+        if assert.Len(t, resp.BlockedUsers, 1) {
+            assert.Equal(t, "troll", resp.BlockedUsers[0].User.Nickname)
+        }
+    })
+
+    t.Run("News Hidden", func(t *testing.T) {
+        assert.Equal(t, http.StatusNotFound, request("GET", fmt.Sprintf("/news/details/news/%d", trollNews.ID), blocker.ID, "").Code)
+        assert.Equal(t, http.StatusOK, request("GET", fmt.Sprintf("/news/details/news/%d", trollNews.ID), other.ID, "").Code)
+        assert.Equal(t, http.StatusOK, request("GET", fmt.Sprintf("/news/details/news/%d", trollNews.ID), 0, "").Code)
+
+        assert.Equal(t, []uint{blockerNews.ID}, newsIDs(request("GET", "/news/paginated/upload_time?page=1", blocker.ID, "")))
+        assert.Len(t, newsIDs(request("GET", "/news/paginated/upload_time?page=1", other.ID, "")), 2)
+        assert.Equal(t, []uint{}, newsIDs(request("GET", "/users/liked", blocker.ID, "")))
+
+        w := request("GET", "/news/feed", blocker.ID, "")
+        var feed NewsFeedResponse
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &feed))
+        if assert.Len(t, feed.Previews, 1) {
+            assert.Equal(t, blockerNews.ID, feed.Previews[0].ID)
+        }
+        assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+
+        w = request("POST", "/news/search", blocker.ID, `{"query":"Writes"}`)
+        assert.Contains(t, w.Body.String(), fmt.Sprintf(`"id":%d`, blockerNews.ID))
+        assert.NotContains(t, w.Body.String(), fmt.Sprintf(`"id":%d`, trollNews.ID))
+    })
+
+    t.Run("Comments Hidden", func(t *testing.T) {
+        _, page := getCommentPage(t, router, blocker.ID, fmt.Sprintf("/news/%d/comments", blockerNews.ID))
+        assert.Equal(t, []uint{thread.ID}, commentIDs(page))
+        if assert.Len(t, page.Comments, 1) {
+            assert.Equal(t, int64(0), page.Comments[0].ReplyCount)
+        }
+        _, page = getCommentPage(t, router, blocker.ID, fmt.Sprintf("/news/comments/%d/replies", thread.ID))
+        assert.Empty(t, page.Comments)
+        status, _ := getCommentPage(t, router, blocker.ID, fmt.Sprintf("/news/comments/%d/replies", trollComment.ID))
+        assert.Equal(t, http.StatusNotFound, status)
+
+        _, page = getCommentPage(t, router, other.ID, fmt.Sprintf("/news/%d/comments", blockerNews.ID))
+        assert.Equal(t, []uint{trollComment.ID, thread.ID}, commentIDs(page))
+
+        // 屏蔽的用户的新闻与详情一致按不存在处理，其评论也不可见
+        status, _ = getCommentPage(t, router, blocker.ID, fmt.Sprintf("/news/%d/comments", trollNews.ID))
+        assert.Equal(t, http.StatusNotFound, status)
+        status, _ = getCommentPage(t, router, other.ID, fmt.Sprintf("/news/%d/comments", trollNews.ID))
+        assert.Equal(t, http.StatusOK, status)
+
+        w := request("GET", fmt.Sprintf("/news/details/news/%d", blockerNews.ID), blocker.ID, "")
+        var detail NewsDetailResponse
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+        assert.Equal(t, int64(1), detail.CommentCount)
+    })
+
+    t.Run("Blocked User Cannot Comment Or Mention", func(t *testing.T) {
+        w := request("POST", "/news/comments", troll.ID, fmt.Sprintf(`{"news_id":%d,"content":"again"}`, blockerNews.ID))
+        assert.Equal(t, http.StatusForbidden, w.Code)
+        assert.JSONEq(t, `{"error":"You cannot comment on this news"}`, w.Body.String())
+
+        // 不能回复屏蔽者在他人新闻下的评论
+        blockerComment := models.Comment{NewsID: trollNews.ID, UserID: blocker.ID, Content: "mine"}
+        db.Create(&blockerComment)
+        w = request("POST", "/news/comments", troll.ID,
+            fmt.Sprintf(`{"news_id":%d,"content":"reply","is_reply":true,"parent_id":%d}`, trollNews.ID, blockerComment.ID))
+        assert.Equal(t, http.StatusForbidden, w.Code)
+
+        // @ 屏蔽者不产生通知，其他人仍可 @ 屏蔽者
+        w = request("POST", "/news/comments", troll.ID, fmt.Sprintf(`{"news_id":%d,"content":"hey @blocker"}`, trollNews.ID))
+        assert.Equal(t, http.StatusCreated, w.Code)
+        w = request("POST", "/news/comments", other.ID, fmt.Sprintf(`{"news_id":%d,"content":"hi @blocker"}`, trollNews.ID))
+        assert.Equal(t, http.StatusCreated, w.Code)
+
+        var actors []uint
+        db.Model(&models.Notification{}).Where("user_id = ? AND type = ?", blocker.ID, models.NotificationMention).Pluck("actor_id", &actors)
+        assert.Equal(t, []uint{other.ID}, actors)
+
+        // 屏蔽前收到的通知也不再显示
+        w = request("GET", "/notifications", blocker.ID, "")
+        var page notificationPage
+        assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+        for _, n := range page.Notifications {
+            assert.NotEqual(t, troll.ID, n.Actor.ID)
+        }
+        assert.Equal(t, int64(len(page.Notifications)), page.UnreadCount)
+    })
+}
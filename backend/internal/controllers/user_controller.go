@@ -2,19 +2,20 @@
 package controllers
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"time"
     "strconv"
     // "path/filepath"
     "errors"
 
+	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+
+	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/auth"
+	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/imaging"
 	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
 	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
-    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/config"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -22,388 +23,57 @@ import (
 type UserController struct {
     DB *gorm.DB
     Utils utils.UtilsInterface
+    Auth  *auth.Registry      // 可用的登录方式
+    Codes *auth.CodeProvider  // 一次性验证码登录，也用于注册和关联邮箱 / 手机号
 }
 
 func NewUserController(db *gorm.DB, utils utils.UtilsInterface) *UserController {
+    sender, err := auth.SenderFromEnv()
+    if err != nil {
+        log.Printf("验证码发送方配置错误，改为写入日志: %v\n", err)
+        sender = auth.LogSender{}
+    }
+    codes := auth.NewCodeProvider(db, sender)
     return &UserController{
         DB: db,
         Utils: utils,
+        Auth:  auth.NewRegistry(auth.NewWeChatProvider(), auth.NewPasswordProvider(db), codes),
+        Codes: codes,
     }
 }
 
-// WeChatAuth 用户注册/登录
-// func (uc *UserController) WeChatAuth(c *gin.Context) {
-//     log.Println("WeChatAuth 被调用")
-
-//     code := c.PostForm("code")
-//     nickname := c.PostForm("nickname")
-    
-//     if code == "" {
-//         c.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
-//         return
-//     }
-
-//     // 获取上传的文件
-//     file, _ := c.FormFile("avatar") // 头像文件是可选的
-
-//     // 调用微信 API 获取 open_id 和 session_key
-//     wechatAPIURL := os.Getenv("WECHAT_API_URL")
-//     if wechatAPIURL == "" {
-//         wechatAPIURL = "https://api.weixin.qq.com/sns/jscode2session"
-//     }
-
-//     // 构建请求 URL
-//     wxAPI := fmt.Sprintf(
-//         "%s?appid=%s&secret=%s&js_code=%s&grant_type=authorization_code",
-//         wechatAPIURL, os.Getenv("APP_ID"), os.Getenv("APP_SECRET"), code,
-//     )
-
-//     resp, err := http.Get(wxAPI)
-//     if err != nil {
-//         log.Println("调用微信 API 失败:", err)
-//         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to call WeChat API"})
-//         return
-//     }
-
-//     defer resp.Body.Close()
-
-//     var wxResponse struct {
-//         OpenID     string `json:"openid"`
-//         SessionKey string `json:"session_key"`
-//         ErrCode    int    `json:"errcode"`
-//         ErrMsg     string `json:"errmsg"`
-//     }
-//     if err := json.NewDecoder(resp.Body).Decode(&wxResponse); err != nil {
-//         log.Println("解析微信 API 响应失败:", err)
-//         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse WeChat API response"})
-//         return
-//     }
-
-//     // 微信 API 错误
-//     if wxResponse.ErrCode != 0 {
-//         log.Println("微信 API 返回错误:", wxResponse.ErrMsg)
-//         c.JSON(http.StatusUnauthorized, gin.H{"error": wxResponse.ErrMsg})
-//         return
-//     }
-
-//     // 检查用户是否已存在
-//     var user models.User
-
-//     if err := uc.DB.Preload("RefreshTokens").Where("open_id = ?", wxResponse.OpenID).First(&user).Error; err == nil {
-//         // 用户已存在，更新 SessionKey
-//         user.SessionKey = wxResponse.SessionKey
-
-//         // 更新昵称（如果提供）
-//         if nickname != "" && nickname != user.Nickname {
-//             user.Nickname = nickname
-//         }
-
-//         // 处理头像更新（如果上传了新头像）
-//         BaseUploadPath := os.Getenv("BASE_UPLOAD_PATH")
-//         if BaseUploadPath == "" {
-//             BaseUploadPath = "./uploads"
-//         }
-
-//         var relativePath string
-//         if file != nil {
-//             // 保存用户上传的头像
-//             timestamp := time.Now().Unix()
-//             relativePath = fmt.Sprintf("avatars/%d_%d.jpg", user.ID, timestamp)
-//             savePath := filepath.Join(BaseUploadPath, relativePath)
-//             if err := c.SaveUploadedFile(file, savePath); err != nil {
-//                 log.Println("保存用户上传的头像失败:", err)
-//                 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save uploaded avatar"})
-//                 return
-//             }
-
-//             // 删除旧头像文件（如果存在且不是默认头像）
-//             if user.AvatarURL != "" && user.AvatarURL != "avatars/default.jpg" {
-//                 oldPath := filepath.Join(BaseUploadPath, user.AvatarURL)
-//                 if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
-//                     log.Printf("无法删除旧头像文件: %s, 错误: %v\n", oldPath, err)
-//                 }
-//             }
-
-//             // 更新 AvatarURL
-//             user.AvatarURL = relativePath
-//         }
-
-//         // 如果未上传新头像，但提供了昵称，则仅更新昵称
-//         if nickname != "" || file != nil {
-//             user.UpdatedAt = time.Now()
-//             if err := uc.DB.Save(&user).Error; err != nil {
-//                 log.Println("更新用户信息失败:", err)
-//                 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user information"})
-//                 return
-//             }
-//         }
-//     } else if err == gorm.ErrRecordNotFound { // 用户不存在
-//         // 用户不存在，创建新用户
-//         user = models.User{
-//             OpenID:          wxResponse.OpenID,
-//             SessionKey:      wxResponse.SessionKey,
-//             Nickname:        nickname,
-//             FamilyID:        nil,
-//             PendingFamilyID: nil,
-//             RefreshTokens:   []models.RefreshToken{},
-//             CreatedAt:       time.Now(),
-//             UpdatedAt:       time.Now(),
-//             LikedNews:       []models.News{},
-//             FavoritedNews:   []models.News{},
-//             DislikedNews:    []models.News{},
-//             ViewedNews:      []models.News{},
-//         }
-
-//         // 如果未提供昵称，生成随机昵称
-//         if user.Nickname == "" {
-//             user.Nickname = utils.GenerateRandomNickname()
-//         }
-
-//         // 创建用户，获取 user.ID
-//         if err := uc.DB.Create(&user).Error; err != nil {
-//             log.Println("创建用户失败:", err)
-//             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
-//             return
-//         }
-
-//         // 处理头像
-//         BaseUploadPath := os.Getenv("BASE_UPLOAD_PATH")
-//         if BaseUploadPath == "" {
-//             BaseUploadPath = "./uploads"
-//         }
-
-//         var relativePath string
-//         if file != nil {
-//             // 保存用户上传的头像
-//             timestamp := time.Now().Unix()
-//             relativePath = fmt.Sprintf("avatars/%d_%d.jpg", user.ID, timestamp)
-//             savePath := filepath.Join(BaseUploadPath, relativePath)
-//             if err := c.SaveUploadedFile(file, savePath); err != nil {
-//                 log.Println("保存用户上传的头像失败:", err)
-//                 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save uploaded avatar"})
-//                 return
-//             }
-//         } else {
-//             // 使用默认头像
-//             relativePath = "avatars/default.jpg"
-//             defaultAvatarPath := filepath.Join(BaseUploadPath, "avatars", "default.jpg")
-//             newAvatarPath := filepath.Join(BaseUploadPath, relativePath)
-//             if err := utils.CopyFile(defaultAvatarPath, newAvatarPath); err != nil {
-//                 log.Printf("复制默认头像失败: %v\n", err)
-//                 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set default avatar"})
-//                 return
-//             }
-//         }
-
-//         // 设置头像 URL
-//         user.AvatarURL = relativePath
-
-//         // 更新用户的 AvatarURL
-//         if err := uc.DB.Save(&user).Error; err != nil {
-//             log.Println("更新用户头像失败:", err)
-//             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user avatar"})
-//             return
-//         }
-//     } else {
-//         log.Println("查询数据库时发生错误:", err)
-//         c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-//         return
-//     }
-
-//     // 生成 Access Token
-//     accessToken, err := utils.GenerateAccessToken(user.ID)
-//     if err != nil {
-//         log.Println("生成 Access Token 失败:", err)
-//         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access token"})
-//         return
-//     }
-
-//     // 生成 Refresh Token
-//     refreshToken, err := utils.GenerateRefreshToken(user.ID)
-//     if err != nil {
-//         log.Println("生成 Refresh Token 失败:", err)
-//         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate refresh token"})
-//         return
-//     }
-
-//     // 存储 Refresh Token 到数据库
-//     newRefreshToken := models.RefreshToken{
-//         Token:     refreshToken,
-//         UserID:    user.ID,
-//         ExpiresAt: time.Now().Add(config.JWTConfig.RefreshTokenExpiration),
-//         Revoked:   false,
-//     }
-//     if err := uc.DB.Create(&newRefreshToken).Error; err != nil {
-//         log.Println("存储 Refresh Token 失败:", err)
-//         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store refresh token"})
-//         return
-//     }
-
-//     // 返回成功响应，包括 Access Token 和 Refresh Token
-//     c.JSON(http.StatusOK, gin.H{
-//         "access_token":  accessToken,
-//         "refresh_token": refreshToken,
-//         "user": gin.H{
-//             "id":         user.ID,
-//             "nickname":   user.Nickname,
-//             "avatar_url": user.AvatarURL,
-//         },
-//     })
-// }
+// WeChatAuthRequest 微信登录的请求体
+type WeChatAuthRequest struct {
+    Code       string `json:"code" binding:"required"`
+    DeviceName string `json:"device_name"` // 可选，显示在设备会话列表中
+}
+
+// WeChatAuth 微信小程序登录，新的微信用户自动注册；等同于 POST /users/login/wechat
 func (uc *UserController) WeChatAuth(c *gin.Context) {
     log.Println("WeChatAuth 被调用")
 
-    var request struct {
-        Code string `json:"code" binding:"required"`
-    }
+    var request WeChatAuthRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-        return
-    }
-
-    code := request.Code
-
-    // 调用微信 API 获取 open_id 和 session_key
-    wechatAPIURL := os.Getenv("WECHAT_API_URL")
-    if wechatAPIURL == "" {
-        wechatAPIURL = "https://api.weixin.qq.com/sns/jscode2session"
-    }
-
-    fmt.Println(wechatAPIURL)
-
-    // 构建请求 URL
-    wxAPI := fmt.Sprintf(
-        "%s?appid=%s&secret=%s&js_code=%s&grant_type=authorization_code",
-        wechatAPIURL, os.Getenv("APP_ID"), os.Getenv("APP_SECRET"), code,
-    )
-
-    resp, err := http.Get(wxAPI)
-    if err != nil {
-        log.Println("调用微信 API 失败:", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to call WeChat API"})
-        return
-    }
-
-    defer resp.Body.Close()
-
-    var wxResponse struct {
-        OpenID     string `json:"openid"`
-        SessionKey string `json:"session_key"`
-        ErrCode    int    `json:"errcode"`
-        ErrMsg     string `json:"errmsg"`
-    }
-    if err := json.NewDecoder(resp.Body).Decode(&wxResponse); err != nil {
-        log.Println("解析微信 API 响应失败:", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse WeChat API response"})
-        return
-    }
-
-    // 微信 API 错误
-    if wxResponse.ErrCode != 0 {
-        log.Println("微信 API 返回错误:", wxResponse.ErrMsg)
-        c.JSON(http.StatusUnauthorized, gin.H{"error": wxResponse.ErrMsg})
-        return
-    }
-
-    // 检查用户是否已存在
-    var user models.User
-
-    if err := uc.DB.Preload("RefreshTokens").Where("open_id = ?", wxResponse.OpenID).First(&user).Error; err == nil {
-        // 用户已存在，更新 SessionKey
-        user.SessionKey = wxResponse.SessionKey
-    } else if err == gorm.ErrRecordNotFound { // 用户不存在
-        // 用户不存在，创建新用户
-        user = models.User{
-            OpenID:          wxResponse.OpenID,
-            SessionKey:      wxResponse.SessionKey,
-            Nickname:        utils.GenerateRandomNickname(),
-            FamilyID:        nil,
-            PendingFamilyID: nil,
-            RefreshTokens:   []models.RefreshToken{},
-            CreatedAt:       time.Now(),
-            UpdatedAt:       time.Now(),
-            LikedNews:       []models.News{},
-            FavoritedNews:   []models.News{},
-            DislikedNews:    []models.News{},
-            ViewedNews:      []models.News{},
-        }
-
-        // 创建用户，获取 user.ID
-        if err := uc.DB.Create(&user).Error; err != nil {
-            log.Println("创建用户失败:", err)
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
-            return
-        }
-
-        // 处理头像
-        BaseUploadPath := os.Getenv("BASE_UPLOAD_PATH")
-        if BaseUploadPath == "" {
-            BaseUploadPath = "./uploads"
-        }
-
-        // 使用默认头像
-        relativePath := "avatars/default.jpg"
-        // defaultAvatarPath := filepath.Join(BaseUploadPath, "avatars", "default.jpg")
-        // newAvatarPath := filepath.Join(BaseUploadPath, relativePath)
-        // if err := uc.Utils.CopyFile(defaultAvatarPath, newAvatarPath); err != nil {
-        //     log.Printf("复制默认头像失败: %v\n", err)
-        //     c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set default avatar"})
-        //     return
-        // }
-
-        user.AvatarURL = relativePath
-
-        // 更新用户的 AvatarURL
-        if err := uc.DB.Save(&user).Error; err != nil {
-            log.Println("更新用户头像失败:", err)
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user avatar"})
-            return
-        }
-    } else {
-        log.Println("查询数据库时发生错误:", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
-    // 生成 Access Token
-    accessToken, err := uc.Utils.GenerateAccessToken(user.ID)
+    provider, err := uc.Auth.Get(models.IdentityWeChat)
     if err != nil {
-        log.Println("生成 Access Token 失败:", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access token"})
+        apierror.Respond(c, apierror.ErrNotFound.WithMessage(err.Error()))
         return
     }
-
-    // 生成 Refresh Token
-    refreshToken, err := uc.Utils.GenerateRefreshToken(user.ID)
+    identity, err := provider.Authenticate(c.Request.Context(), auth.Credentials{Code: request.Code})
     if err != nil {
-        log.Println("生成 Refresh Token 失败:", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate refresh token"})
+        respondAuthError(c, err)
         return
     }
+    uc.loginIdentity(c, provider, identity, request.DeviceName)
+}
 
-    // 存储 Refresh Token 到数据库
-    newRefreshToken := models.RefreshToken{
-        Token:     refreshToken,
-        UserID:    user.ID,
-        ExpiresAt: time.Now().Add(config.JWTConfig.RefreshTokenExpiration),
-        Revoked:   false,
-    }
-    if err := uc.DB.Create(&newRefreshToken).Error; err != nil {
-        log.Println("存储 Refresh Token 失败:", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store refresh token"})
-        return
-    }
-
-    // 返回成功响应，包括 Access Token 和 Refresh Token
-    c.JSON(http.StatusOK, gin.H{
-        "access_token":  accessToken,
-        "refresh_token": refreshToken,
-        "user": gin.H{
-            "id":         user.ID,
-            "nickname":   user.Nickname,
-            "avatar_url": user.AvatarURL,
-        },
-    })
+// SetNicknameRequest 修改昵称的请求体
+type SetNicknameRequest struct {
+    Nickname string `json:"nickname" binding:"required"`
 }
 
 // 设置用户名
@@ -413,30 +83,28 @@ func (uc *UserController) SetNickname(c *gin.Context) {
     // 从上下文中获取用户 ID
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 解析请求体
-    var request struct {
-        Nickname string `json:"nickname" binding:"required"`
-    }
+    var request SetNicknameRequest
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     // 查询用户并更新昵称
     var user models.User
     if err := uc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     user.Nickname = request.Nickname
     user.UpdatedAt = time.Now()
     if err := uc.DB.Save(&user).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update nickname"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update nickname"))
         return
     }
 
@@ -450,45 +118,37 @@ func (uc *UserController) SetAvatar(c *gin.Context) {
     // 获取用户 ID
     id, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 检查用户是否存在
     var user models.User
     if err := uc.DB.First(&user, id).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
     // 获取上传的文件
     file, err := c.FormFile("avatar")
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to retrieve file"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Failed to retrieve file"))
         return
     }
 
-    // 获取基本路径
-    BaseUploadPath := os.Getenv("BASE_UPLOAD_PATH")
-    if BaseUploadPath == "" {
-        BaseUploadPath = "./uploads" // 默认路径
-    }
-
-    // 保存文件到服务器
-    timestamp := time.Now().Unix()
-    relativePath := fmt.Sprintf("avatars/%d_%d.jpg", user.ID, timestamp) // 文件的相对路径
-    savePath := fmt.Sprintf("%s/%s", BaseUploadPath, relativePath)       // 文件的完整路径
-    if err := c.SaveUploadedFile(file, savePath); err != nil {
-        log.Println("文件保存失败:", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+    // 校验并处理头像，生成多种尺寸，相同内容的图片只保存一份
+    image, _, err := storeImage(c.Request.Context(), uc.DB, file, imaging.AvatarProfile, user.ID)
+    if err != nil {
+        log.Println("头像处理失败:", err)
+        respondImageError(c, err, "Failed to save file")
         return
     }
+    relativePath := image.Path
 
-    // 删除旧头像文件（如果存在）
-    if user.AvatarURL != "" && user.AvatarURL != "avatars/default.jpg" {
-        oldPath := fmt.Sprintf("%s/%s", BaseUploadPath, user.AvatarURL)
-        if err := os.Remove(oldPath); err != nil {
-            log.Printf("无法删除旧头像文件: %s, 错误: %v\n", oldPath, err)
+    // 删除旧头像文件（如果存在）。经过处理的头像可能被多个用户共用，不在这里删除
+    if user.AvatarURL != "avatars/default.jpg" && user.AvatarURL != relativePath {
+        if err := deleteUploadedFile(c.Request.Context(), user.AvatarURL); err != nil {
+            log.Printf("无法删除旧头像文件: %s, 错误: %v\n", user.AvatarURL, err)
         }
     }
 
@@ -497,90 +157,96 @@ func (uc *UserController) SetAvatar(c *gin.Context) {
     user.UpdatedAt = time.Now()
     if err := uc.DB.Save(&user).Error; err != nil {
         log.Println("更新用户头像失败:", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update avatar"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to update avatar"))
         return
     }
 
     log.Println("用户头像设置成功:", relativePath)
-    c.JSON(http.StatusOK, gin.H{"message": "Avatar updated successfully", "avatar_url": relativePath})
+    c.JSON(http.StatusOK, gin.H{
+        "message":         "Avatar updated successfully",
+        "avatar_url":      relativePath,
+        "avatar_variants": image.VariantList(),
+    })
+}
+
+// RefreshTokenRequest 刷新令牌的请求体
+type RefreshTokenRequest struct {
+    RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // RefreshTokenHandler 处理刷新 Access Token 的请求
 func (uc *UserController) RefreshTokenHandler(c *gin.Context) {
-    type RefreshTokenRequest struct {
-        RefreshToken string `json:"refresh_token" binding:"required"`
-    }
-
     var req RefreshTokenRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     // 验证 Refresh Token
     claims, err := uc.Utils.ValidateToken(req.RefreshToken)
     if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        apierror.Respond(c, apierror.ErrInvalidToken.WithMessage(err.Error()))
         return
     }
 
     // 获取用户 ID
-    userID, err := strconv.Atoi(claims.Subject)
+    _, err = strconv.Atoi(claims.Subject)
     if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token subject"})
+        apierror.Respond(c, apierror.ErrInvalidToken.WithMessage("Invalid token subject"))
         return
     }
 
-    // 查找 Refresh Token 在数据库中
-    var storedRefreshToken models.RefreshToken
-    if err := uc.DB.Where("token = ?", req.RefreshToken).First(&storedRefreshToken).Error; err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token not found"})
+    // 按 JTI 查找 Refresh Token 并校验摘要
+    storedRefreshToken, err := uc.findRefreshToken(req.RefreshToken, claims.ID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidToken.WithMessage("Refresh token not found"))
         return
     }
 
-    // 检查 Refresh Token 是否已过期或被撤销
-    if storedRefreshToken.ExpiresAt.Before(time.Now()) || storedRefreshToken.Revoked {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token is expired or revoked"})
+    // 检查 Refresh Token 是否已过期，或所在会话已被登出
+    var session models.Session
+    if err := uc.DB.First(&session, storedRefreshToken.SessionID).Error; err != nil ||
+        session.RevokedAt != nil || storedRefreshToken.ExpiresAt.Before(time.Now()) {
+        apierror.Respond(c, apierror.ErrInvalidToken.WithMessage("Refresh token is expired or revoked"))
+        return
+    }
+
+    // 已轮换掉的令牌被再次使用，注销整个会话
+    if storedRefreshToken.Revoked {
+        uc.revokeReusedSession(c, session.ID)
         return
     }
 
     // 可选：验证用户存在
     var user models.User
     if err := uc.DB.First(&user, storedRefreshToken.UserID).Error; err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUnauthorized.WithMessage("User not found"))
         return
     }
 
     // 生成新的 Access Token
-    newAccessToken, err := uc.Utils.GenerateAccessToken(uint(userID))
+    newAccessToken, err := uc.Utils.GenerateAccessToken(user.ID)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access token"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to generate access token"))
         return
     }
 
-    // 可选：生成新的 Refresh Token，并撤销旧的
-    newRefreshToken, err := uc.Utils.GenerateRefreshToken(uint(userID))
+    // 生成新的 Refresh Token，并撤销旧的
+    newRefreshToken, newRT, err := uc.newRefreshToken(user.ID)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate refresh token"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to generate refresh token"))
         return
     }
-
-    // 创建新的 Refresh Token 记录
-    newRT := models.RefreshToken{
-        Token:     newRefreshToken,
-        UserID:    storedRefreshToken.UserID,
-        ExpiresAt: time.Now().Add(config.JWTConfig.RefreshTokenExpiration),
-        Revoked:   false,
-    }
-    if err := uc.DB.Create(&newRT).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store new refresh token"})
+    switch err := uc.rotateRefreshToken(storedRefreshToken, newRT); {
+    case errors.Is(err, errRefreshTokenReused):
+        // 并发请求已经用这个令牌刷新过
+        uc.revokeReusedSession(c, session.ID)
         return
-    }
-
-    // 撤销旧的 Refresh Token
-    storedRefreshToken.Revoked = true
-    if err := uc.DB.Save(&storedRefreshToken).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke old refresh token"})
+    case errors.Is(err, errRevokeRefreshToken):
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to revoke old refresh token"))
+        return
+    case err != nil:
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to store new refresh token"))
         return
     }
 
@@ -591,57 +257,51 @@ func (uc *UserController) RefreshTokenHandler(c *gin.Context) {
     })
 }
 
+// LogoutRequest 登出的请求体
+type LogoutRequest struct {
+    RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 // LogoutHandler 处理用户登出请求
 func (uc *UserController) LogoutHandler(c *gin.Context) {
-    type LogoutRequest struct {
-        RefreshToken string `json:"refresh_token" binding:"required"`
-    }
-
     var req LogoutRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+        apierror.Respond(c, apierror.ErrInvalidRequest)
         return
     }
 
     // 验证 Refresh Token
     claims, err := uc.Utils.ValidateToken(req.RefreshToken)
     if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+        apierror.Respond(c, apierror.ErrInvalidToken.WithMessage("Invalid refresh token"))
         return
     }
 
     // 获取用户 ID
     _, err = strconv.Atoi(claims.Subject)
     if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token subject"})
+        apierror.Respond(c, apierror.ErrInvalidToken.WithMessage("Invalid token subject"))
         return
     }
 
-    // 查找 Refresh Token 在数据库中
-    var storedRefreshToken models.RefreshToken
-    if err := uc.DB.Where("token = ?", req.RefreshToken).First(&storedRefreshToken).Error; err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token not found"})
+    // 按 JTI 查找 Refresh Token 并校验摘要
+    storedRefreshToken, err := uc.findRefreshToken(req.RefreshToken, claims.ID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInvalidToken.WithMessage("Refresh token not found"))
         return
     }
 
     // 检查 Refresh Token 是否已过期或被撤销
     if storedRefreshToken.ExpiresAt.Before(time.Now()) || storedRefreshToken.Revoked {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token is expired or revoked"})
+        apierror.Respond(c, apierror.ErrInvalidToken.WithMessage("Refresh token is expired or revoked"))
         return
     }
 
-    // 标记 Refresh Token 为已撤销
-    storedRefreshToken.Revoked = true
-    if err := uc.DB.Save(&storedRefreshToken).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
-        return
-    }
-
-    // 撤销所有关联的 Refresh Tokens（如果需要）
-    if err := uc.DB.Model(&models.RefreshToken{}).
-        Where("user_id = ? AND revoked = ?", storedRefreshToken.UserID, false).
-        Update("revoked", true).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke all refresh tokens"})
+    // 登出当前设备：注销令牌所在的会话，其他设备不受影响
+    if err := uc.DB.Transaction(func(tx *gorm.DB) error {
+        return models.RevokeSession(tx, storedRefreshToken.SessionID)
+    }); err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to revoke refresh token"))
         return
     }
 
@@ -653,7 +313,7 @@ func (uc *UserController) UserBasicDetails(c *gin.Context) {
 
     var user models.User
     if err := uc.DB.First(&user, userID).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        apierror.Respond(c, apierror.ErrUserNotFound)
         return
     }
 
@@ -668,43 +328,121 @@ func (uc *UserController) UserBasicDetails(c *gin.Context) {
     registeredDays := int(currentDate.Sub(createdDate).Hours()/24)
     fmt.Println(registeredDays)
 
+    // 经过处理的头像附带各尺寸版本
+    variants, err := models.ImageVariantsByPath(uc.DB, []string{user.AvatarURL})
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch avatar"))
+        return
+    }
+
     c.JSON(http.StatusOK, gin.H{
         "id":             user.ID,
         "nickname":       user.Nickname,
         "avatar_url":     user.AvatarURL,
+        "avatar_variants": variants[user.AvatarURL],
         "registered_days": registeredDays,
     })
 }
 
-// GetMyFavoritedNews 获取用户收藏的新闻 ID
+// GetMyFavoritedNews 获取用户收藏的新闻 ID，最近收藏的在前；指定 collection_id 时按收藏夹内的顺序返回该收藏夹中的新闻。
+// 分页参数 limit、cursor，cursor 为上一页的 next_cursor
 func (uc *UserController) GetMyFavoritedNews(c *gin.Context) {
     // 1. 获取 user_id
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    // 2. 查询 user 并预加载 FavoritedNews
+    limit := defaultCollectionPageSize
+    if v := c.Query("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1 || n > maxCollectionPageSize {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid limit"))
+            return
+        }
+        limit = n
+    }
+    var cursor uint64
+    if v := c.Query("cursor"); v != "" {
+        var err error
+        if cursor, err = strconv.ParseUint(v, 10, 64); err != nil {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid cursor"))
+            return
+        }
+    }
+
+    // 2. 查询 user
     var user models.User
-    if err := uc.DB.Preload("FavoritedNews").First(&user, userID).Error; err != nil {
+    if err := uc.DB.Select("id").First(&user, userID).Error; err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
-            c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+            apierror.Respond(c, apierror.ErrUserNotFound)
         } else {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query database"})
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query database"))
         }
         return
     }
 
-    // 3. 收集 ID
-    favoritedNewsIDs := make([]uint, 0, len(user.FavoritedNews))
-    for _, news := range user.FavoritedNews {
-        favoritedNewsIDs = append(favoritedNewsIDs, news.ID)
+    // 3. 查询一页新闻 ID，不包含屏蔽的用户的新闻
+    var rows []struct {
+        CursorKey uint // 分页游标：收藏夹条目 ID 或新闻 ID
+        NewsID    uint
+    }
+    if v := c.Query("collection_id"); v != "" {
+        collection, ok := findOwnCollection(c, uc.DB, user.ID, v)
+        if !ok {
+            return
+        }
+        query := uc.DB.Model(&models.CollectionItem{}).
+            Select("collection_items.id AS cursor_key, collection_items.item_id AS news_id").
+            Joins("JOIN news ON news.id = collection_items.item_id").
+            Where("collection_items.collection_id = ? AND collection_items.item_type = ?", collection.ID, models.CollectionItemNews).
+            Scopes(models.NotBlockedBy(user.ID, "news.author_id"))
+        if cursor != 0 {
+            var last models.CollectionItem
+            if err := uc.DB.Where("id = ? AND collection_id = ?", cursor, collection.ID).First(&last).Error; err != nil {
+                apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid cursor"))
+                return
+            }
+            query = query.Where("collection_items.position > ? OR (collection_items.position = ? AND collection_items.id > ?)",
+                last.Position, last.Position, last.ID)
+        }
+        if err := query.Order("collection_items.position ASC, collection_items.id ASC").Limit(limit + 1).Scan(&rows).Error; err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query database"))
+            return
+        }
+    } else {
+        // 收藏关系没有时间戳，按新闻 ID 倒序
+        query := uc.DB.Table("user_favorites_news").
+            Select("user_favorites_news.news_id AS cursor_key, user_favorites_news.news_id AS news_id").
+            Joins("JOIN news ON news.id = user_favorites_news.news_id").
+            Where("user_favorites_news.user_id = ?", user.ID).
+            Scopes(models.NotBlockedBy(user.ID, "news.author_id"))
+        if cursor != 0 {
+            query = query.Where("user_favorites_news.news_id < ?", cursor)
+        }
+        if err := query.Order("user_favorites_news.news_id DESC").Limit(limit + 1).Scan(&rows).Error; err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query database"))
+            return
+        }
+    }
+
+    hasMore := len(rows) > limit
+    nextCursor := ""
+    if hasMore {
+        rows = rows[:limit]
+        nextCursor = strconv.FormatUint(uint64(rows[limit-1].CursorKey), 10)
+    }
+    favoritedNewsIDs := make([]uint, 0, len(rows))
+    for _, row := range rows {
+        favoritedNewsIDs = append(favoritedNewsIDs, row.NewsID)
     }
 
     // 4. 返回 ID 列表
     c.JSON(http.StatusOK, gin.H{
-        "news_ids": favoritedNewsIDs,
+        "news_ids":    favoritedNewsIDs,
+        "next_cursor": nextCursor,
+        "has_more":    hasMore,
     })
 }
 
@@ -713,17 +451,17 @@ func (uc *UserController) GetMyLikedNews(c *gin.Context) {
     // 1. 获取 user_id
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
     // 2. 在数据库中找到此 user，并预加载 LikedNews
     var user models.User
-    if err := uc.DB.Preload("LikedNews").First(&user, userID).Error; err != nil {
+    if err := uc.DB.Preload("LikedNews", models.NotBlockedBy(userID.(uint), "author_id")).First(&user, userID).Error; err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
-            c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+            apierror.Respond(c, apierror.ErrUserNotFound)
         } else {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query database"})
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query database"))
         }
         return
     }
@@ -740,30 +478,38 @@ func (uc *UserController) GetMyLikedNews(c *gin.Context) {
     })
 }
 
-// GetMyViewedNews 获取用户最近看过的新闻 ID
+// GetMyViewedNews 获取用户最近看过的新闻 ID，按最近一次阅读时间倒序
 func (uc *UserController) GetMyViewedNews(c *gin.Context) {
     // 1. 获取 user_id
     userID, exists := c.Get("user_id")
     if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+        apierror.Respond(c, apierror.ErrUnauthorized)
         return
     }
 
-    // 2. 查询 user 并预加载 ViewedNews
+    // 2. 查询 user
     var user models.User
-    if err := uc.DB.Preload("ViewedNews").First(&user, userID).Error; err != nil {
+    if err := uc.DB.First(&user, userID).Error; err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
-            c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+            apierror.Respond(c, apierror.ErrUserNotFound)
         } else {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query database"})
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query database"))
         }
         return
     }
 
-    // 3. 收集 ID
-    viewedNewsIDs := make([]uint, 0, len(user.ViewedNews))
-    for _, news := range user.ViewedNews {
-        viewedNewsIDs = append(viewedNewsIDs, news.ID)
+    // 3. 收集 ID，没有阅读记录的旧数据排在最后，不包含屏蔽的用户的新闻
+    viewedNewsIDs := make([]uint, 0)
+    if err := uc.DB.Table("user_viewed_news").
+        Joins("LEFT JOIN (SELECT news_id, MAX(id) AS last_view_id FROM news_views WHERE user_id = ? GROUP BY news_id) v ON v.news_id = user_viewed_news.news_id", user.ID).
+        Where("user_viewed_news.user_id = ?", user.ID).
+        Where("user_viewed_news.news_id NOT IN (?)", uc.DB.Model(&models.News{}).Select("id").
+            Where("author_id IN (SELECT blocked_id FROM user_blocks WHERE blocker_id = ?)", user.ID)).
+        Order("v.last_view_id DESC, user_viewed_news.news_id").
+        Limit(maxViewedNews).
+        Pluck("user_viewed_news.news_id", &viewedNewsIDs).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to query database"))
+        return
     }
 
     // 4. 返回 ID 列表
@@ -776,7 +522,7 @@ func (uc *UserController) GetUserProfile(c *gin.Context) {
     // 获取路径参数中的用户 ID
     userID, err := strconv.Atoi(c.Param("id"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid user ID"))
         return
     }
 
@@ -784,21 +530,42 @@ func (uc *UserController) GetUserProfile(c *gin.Context) {
     var user models.User
     if err := uc.DB.First(&user, userID).Error; err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
-            c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+            apierror.Respond(c, apierror.ErrUserNotFound)
             return
         }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user data"})
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch user data"))
+        return
+    }
+
+    // 当前用户是否屏蔽了该用户，屏蔽时不返回其新闻
+    var viewerID uint
+    if v, exists := c.Get("user_id"); exists {
+        viewerID = v.(uint)
+    }
+    isBlocked, err := models.IsBlocked(uc.DB, viewerID, user.ID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch user data"))
+        return
+    }
+
+    // 对方的主页设置为仅关注者或仅自己可见时，只返回昵称和头像
+    canView, err := models.CanViewProfile(uc.DB, viewerID, user.ID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch user data"))
         return
     }
 
     // 查找用户创建的新闻
-    var news []struct {
+    news := []struct {
         ID    uint   `json:"id"`
         Title string `json:"title"`
-    }
-    if err := uc.DB.Model(&models.News{}).Select("id, title").Where("author_id = ?", user.ID).Find(&news).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user's news"})
-        return
+    }{}
+    if canView {
+        if err := uc.DB.Model(&models.News{}).Select("id, title").Where("author_id = ?", user.ID).
+            Scopes(models.NotBlockedBy(viewerID, "author_id")).Find(&news).Error; err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch user's news"))
+            return
+        }
     }
 
     // 返回用户信息和新闻列表
@@ -806,5 +573,7 @@ func (uc *UserController) GetUserProfile(c *gin.Context) {
         "nickname":  user.Nickname,
         "avatar_url": user.AvatarURL,
         "news":      news,
+        "is_blocked": isBlocked,
+        "profile_hidden": !canView,
     })
 }
\ No newline at end of file
@@ -5,22 +5,21 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	// "path/filepath"
 	"time"
 
 	// "strconv"
 	"testing"
-	"strings"
 	"github.com/golang-jwt/jwt/v4"
 
 	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
 	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
 	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/wechat"
+	"github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/wechat/wechattest"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
@@ -33,7 +32,7 @@ func setupUserTestDB() *gorm.DB {
 	if err != nil {
 		panic("failed to connect database")
 	}
-	if err := db.AutoMigrate(&models.User{}, &models.Family{}, &models.RefreshToken{}, &models.News{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Family{}, &models.RefreshToken{}, &models.Session{}, &models.News{}, &models.UploadedImage{}, &models.NewsView{}, &models.UserBlock{}, &models.UserIdentity{}, &models.LoginCode{}, &models.UserFollow{}, &models.PrivacySettings{}); err != nil {
 		panic("failed to migrate models")
 	}
 	return db
@@ -83,7 +82,7 @@ func generateValidJWTUser(userID uint) string {
 type MockUtils struct {
 	ValidateTokenFunc        func(tokenString string) (*jwt.RegisteredClaims, error)
     GenerateAccessTokenFunc  func(userID uint) (string, error)
-    GenerateRefreshTokenFunc func(userID uint) (string, error)
+    GenerateRefreshTokenFunc func(userID uint, jti string) (string, error)
     CopyFileFunc             func(src, dst string) error
 }
 
@@ -102,9 +101,9 @@ func (m *MockUtils) GenerateAccessToken(userID uint) (string, error) {
     return fmt.Sprintf("MockAccessToken_%d", userID), nil
 }
 
-func (m *MockUtils) GenerateRefreshToken(userID uint) (string, error) {
+func (m *MockUtils) GenerateRefreshToken(userID uint, jti string) (string, error) {
     if m.GenerateRefreshTokenFunc != nil {
-        return m.GenerateRefreshTokenFunc(userID)
+        return m.GenerateRefreshTokenFunc(userID, jti)
     }
     return fmt.Sprintf("MockRefreshToken_%d", userID), nil
 }
@@ -116,16 +115,6 @@ func (m *MockUtils) CopyFile(src, dst string) error {
     return nil
 }
 
-// MockRoundTripper 用于模拟 http.Client 的 Transport，从而在测试中自定义响应
-type MockRoundTripper struct {
-    RoundTripFunc func(req *http.Request) (*http.Response, error)
-}
-
-// RoundTrip 实现 http.RoundTripper 接口
-func (m *MockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-    return m.RoundTripFunc(req)
-}
-
 func TestGetUserProfile(t *testing.T) {
     db := setupUserTestDB()
     router := setupUserRouter(db, utils.UtilsImpl{})
@@ -283,7 +272,7 @@ func TestWeChatAuth(t *testing.T) {
         GenerateAccessTokenFunc: func(userID uint) (string, error) {
             return fmt.Sprintf("AccessToken_ForUser_%d", userID), nil
         },
-        GenerateRefreshTokenFunc: func(userID uint) (string, error) {
+        GenerateRefreshTokenFunc: func(userID uint, jti string) (string, error) {
             return fmt.Sprintf("RefreshToken_ForUser_%d", userID), nil
         },
         CopyFileFunc: func(src, dst string) error {
@@ -293,52 +282,12 @@ func TestWeChatAuth(t *testing.T) {
 
     router := setupUserRouter(db, mockUtils)
 
-    // 2. 设置一些环境变量 (可根据需要修改)
-    os.Setenv("WECHAT_API_URL", "http://mock-wechat-api.com/sns/jscode2session")
-    os.Setenv("APP_ID", "test_app_id")
-    os.Setenv("APP_SECRET", "test_app_secret")
-
-    // 3. 定义一个 mock HTTP server or transport 来模拟微信API
-
-    // ------- 准备一个可修改的 transport，用于模拟微信API返回 --------
-    var mockWeChatTransport http.RoundTripper = &MockRoundTripper{
-        RoundTripFunc: func(req *http.Request) (*http.Response, error) {
-            if strings.Contains(req.URL.String(), "call_wechat_api_fail") {
-                // 返回网络错误
-                return nil, fmt.Errorf("forced wechat api call error")
-            }
-
-            if strings.Contains(req.URL.String(), "bad_json_response") {
-                // 返回一个非JSON
-                return &http.Response{
-                    StatusCode: 200,
-                    Body:       io.NopCloser(bytes.NewBufferString("not_json")),
-                }, nil
-            }
-
-            if strings.Contains(req.URL.String(), "errcode_nonzero") {
-                // 返回一个微信错误，如 errcode=40029
-                respStr := `{"errcode": 40029, "errmsg": "invalid code"}` 
-                return &http.Response{
-                    StatusCode: 200,
-                    Body:       io.NopCloser(bytes.NewBufferString(respStr)),
-                }, nil
-            }
-
-            // 正常返回 => openid & session_key
-            respStr := `{"openid": "OpenID_WeChatAuthTest", "session_key": "SessionKey_12345"}`
-            return &http.Response{
-                StatusCode: 200,
-                Body:       io.NopCloser(bytes.NewBufferString(respStr)),
-            }, nil
-        },
-    }
-
-    oldTransport := http.DefaultTransport
-    http.DefaultTransport = mockWeChatTransport
-    defer func() {
-        http.DefaultTransport = oldTransport
-    }()
+    // 2. 启动进程内的微信接口服务，代替真实的微信 API
+    wechatServer := wechattest.NewServer()
+    defer wechatServer.Close()
+    wechat.SetDefault(wechatServer.Client())
+    defer wechat.SetDefault(nil)
+    wechatServer.SetRawResponse("bad_json_response", "not_json")
 
     // 4. 准备表驱动测试用例
     tests := []struct {
@@ -355,7 +304,7 @@ func TestWeChatAuth(t *testing.T) {
             setupFunc: func() {
                 // 移除回调
                 // mock token生成都正常
-                mockUtils.GenerateRefreshTokenFunc = func(userID uint) (string, error) {
+                mockUtils.GenerateRefreshTokenFunc = func(userID uint, jti string) (string, error) {
                     return fmt.Sprintf("RefreshToken_ForUser_%d", userID), nil
                 }
                 // 预先创建一个User(已有User) => openID=OpenID_WeChatAuthTest
@@ -395,7 +344,10 @@ func TestWeChatAuth(t *testing.T) {
         {
             name:           "WeChat API call fail",
             requestBody:    gin.H{"code": "call_wechat_api_fail"},
-            setupFunc:      func() {},
+            setupFunc: func() {
+                // 重试后仍然失败
+                wechatServer.FailNext(wechat.DefaultMaxRetries + 1)
+            },
             expectedStatus: http.StatusInternalServerError,
             expectedError:  "Failed to call WeChat API",
         },
@@ -438,8 +390,9 @@ func TestWeChatAuth(t *testing.T) {
                     return fmt.Errorf("forced copy file error")
                 }
             },
-            expectedStatus: http.StatusInternalServerError,
-            expectedError:  "Failed to store refresh token",
+            // 不再复制默认头像；令牌按随机 JTI 存储，重复登录生成相同的令牌字符串也不会冲突
+            expectedStatus: http.StatusOK,
+            isSuccess:      true,
         },
         {
             name: "Fail to generate access token",
@@ -461,7 +414,7 @@ func TestWeChatAuth(t *testing.T) {
                 mockUtils.GenerateAccessTokenFunc = func(userID uint) (string, error) {
                     return fmt.Sprintf("AccessToken_ForUser_%d", userID), nil
                 }
-                mockUtils.GenerateRefreshTokenFunc = func(userID uint) (string, error) {
+                mockUtils.GenerateRefreshTokenFunc = func(userID uint, jti string) (string, error) {
                     return "", fmt.Errorf("forced refresh token gen error")
                 }
             },
@@ -472,7 +425,7 @@ func TestWeChatAuth(t *testing.T) {
             name: "Fail to store refresh token",
             requestBody: gin.H{"code": "normal_code"},
             setupFunc: func() {
-                mockUtils.GenerateRefreshTokenFunc = func(userID uint) (string, error) {
+                mockUtils.GenerateRefreshTokenFunc = func(userID uint, jti string) (string, error) {
                     return "RefreshToken_Abc123", nil
                 }
                 // mock db.Create(&newRefreshToken).Error 出错
@@ -490,6 +443,8 @@ func TestWeChatAuth(t *testing.T) {
     // 5. 依次运行测试
     for _, tc := range tests {
         t.Run(tc.name, func(t *testing.T) {
+            // 微信登录 code 只能使用一次
+            wechatServer.AddCode("normal_code", "OpenID_WeChatAuthTest", "SessionKey_12345")
             tc.setupFunc()
 
             bodyBytes, _ := json.Marshal(tc.requestBody)
@@ -634,6 +589,7 @@ func TestSetNickname(t *testing.T) {
 func TestSetAvatar(t *testing.T) {
     db := setupUserTestDB()
     router := setupUserRouter(db, utils.UtilsImpl{})
+    t.Setenv("BASE_UPLOAD_PATH", t.TempDir())
 
     // 创建用户
     user := models.User{
@@ -648,6 +604,7 @@ func TestSetAvatar(t *testing.T) {
         userID         uint
         fileField      string   // form-data 中的字段名
         fileName       string   // 上传的文件名
+        fileData       []byte   // 为空时上传一张有效的 PNG
         setupFunc      func()
         expectedStatus int
         expectedError  string
@@ -680,6 +637,16 @@ func TestSetAvatar(t *testing.T) {
             expectedStatus: http.StatusBadRequest,
             expectedError:  "Failed to retrieve file",
         },
+        {
+            name:           "Not An Image",
+            userID:         user.ID,
+            fileField:      "avatar",
+            fileName:       "test_avatar.jpg",
+            fileData:       []byte("fake_image_data"),
+            setupFunc:      func() {},
+            expectedStatus: http.StatusBadRequest,
+            expectedError:  "Unsupported image format",
+        },
         {
             name:           "Failed to Update DB Avatar",
             userID:         user.ID,
@@ -719,8 +686,11 @@ func TestSetAvatar(t *testing.T) {
             writer := multipart.NewWriter(body)
             if tc.fileField != "" {
                 part, _ := writer.CreateFormFile(tc.fileField, tc.fileName)
-                // 写入少许字节模拟图片
-                part.Write([]byte("fake_image_data"))
+                data := tc.fileData
+                if data == nil {
+                    data = testImagePNG(t, 320, 240)
+                }
+                part.Write(data)
             }
             writer.Close()
 
@@ -746,6 +716,9 @@ func TestSetAvatar(t *testing.T) {
                 // resp["avatar_url"] 应该存在
                 _, ok := resp["avatar_url"]
                 assert.True(t, ok)
+                variants, ok := resp["avatar_variants"].([]interface{})
+                assert.True(t, ok)
+                assert.NotEmpty(t, variants)
             } else {
                 if tc.expectedError != "" {
                     assert.Equal(t, tc.expectedError, resp["error"])
@@ -769,13 +742,13 @@ func TestRefreshTokenHandler(t *testing.T) {
                 // Subject不合法
                 return &jwt.RegisteredClaims{Subject: "non_integer_id"}, nil
             }
-            // 缺省 => userID= 123
-            return &jwt.RegisteredClaims{Subject: "123"}, nil
+            // 缺省 => userID= 123，JTI 即令牌字符串
+            return &jwt.RegisteredClaims{Subject: "123", ID: tokenString}, nil
         },
         GenerateAccessTokenFunc: func(userID uint) (string, error) {
             return fmt.Sprintf("AccessToken_ForUser_%d", userID), nil
         },
-        GenerateRefreshTokenFunc: func(userID uint) (string, error) {
+        GenerateRefreshTokenFunc: func(userID uint, jti string) (string, error) {
             return fmt.Sprintf("RefreshToken_ForUser_%d", userID), nil
         },
     }
@@ -788,9 +761,13 @@ func TestRefreshTokenHandler(t *testing.T) {
     db.Create(&user)
 
     // 生成并存储一个合法的 old refresh token => token= "OldRefresh_123"
+    session := models.Session{UserID: user.ID, ExpiresAt: time.Now().Add(24*time.Hour)}
+    db.Create(&session)
     oldRT := models.RefreshToken{
-        Token:     "OldRefresh_123",
+        JTI:       "OldRefresh_123",
+        TokenHash: utils.HashToken("OldRefresh_123"),
         UserID:    user.ID,
+        SessionID: session.ID,
         ExpiresAt: time.Now().Add(24*time.Hour),
         Revoked:   false,
     }
@@ -843,8 +820,8 @@ func TestRefreshTokenHandler(t *testing.T) {
             name:           "Refresh Token Expired or Revoked",
             requestBody:    gin.H{"refresh_token": "OldRefresh_123"},
             setupFunc: func() {
-                // 让 oldRT 过期或 revoked
-                db.Model(&oldRT).Update("revoked", true)
+                // 让 oldRT 过期（已撤销的令牌被再次使用属于重放，见 TestRefreshTokenReuse）
+                db.Model(&oldRT).Update("expires_at", time.Now().Add(-time.Hour))
             },
             expectedStatus: http.StatusUnauthorized,
             expectedError:  "Refresh token is expired or revoked",
@@ -855,7 +832,7 @@ func TestRefreshTokenHandler(t *testing.T) {
             setupFunc: func() {
                 // 重新插回 oldRT, 并指向 不存在的UserID=999
                 db.Model(&oldRT).UpdateColumns(map[string]interface{}{
-                    "revoked": false,
+                    "expires_at": time.Now().Add(24*time.Hour),
                     "user_id": 999,
                 })
             },
@@ -883,7 +860,7 @@ func TestRefreshTokenHandler(t *testing.T) {
                 mockUtils.GenerateAccessTokenFunc = func(uid uint) (string, error) {
                     return "AccessToken_Success", nil
                 }
-                mockUtils.GenerateRefreshTokenFunc = func(uid uint) (string, error) {
+                mockUtils.GenerateRefreshTokenFunc = func(uid uint, jti string) (string, error) {
                     return "", fmt.Errorf("forced refresh token error")
                 }
             },
@@ -894,7 +871,7 @@ func TestRefreshTokenHandler(t *testing.T) {
             name:           "Fail to store new refresh token",
             requestBody:    gin.H{"refresh_token": "OldRefresh_123"},
             setupFunc: func() {
-                mockUtils.GenerateRefreshTokenFunc = func(uid uint) (string, error) {
+                mockUtils.GenerateRefreshTokenFunc = func(uid uint, jti string) (string, error) {
                     return "NewRefresh_ABC", nil
                 }
                 // mock db.Create(newRT) => error
@@ -931,7 +908,7 @@ func TestRefreshTokenHandler(t *testing.T) {
                 mockUtils.GenerateAccessTokenFunc = func(uid uint) (string, error) {
                     return "AccessToken_Success", nil
                 }
-                mockUtils.GenerateRefreshTokenFunc = func(uid uint) (string, error) {
+                mockUtils.GenerateRefreshTokenFunc = func(uid uint, jti string) (string, error) {
                     return "NewRefresh_Success", nil
                 }
                 // 让 oldRT 不过期
@@ -984,7 +961,7 @@ func TestLogoutHandler(t *testing.T) {
             if tokenString == "BadSubjectToken" {
                 return &jwt.RegisteredClaims{Subject:"non_int"}, nil
             }
-            return &jwt.RegisteredClaims{Subject:"123"}, nil
+            return &jwt.RegisteredClaims{Subject:"123", ID: tokenString}, nil
         },
         // 其余方法不涉及
     }
@@ -997,18 +974,24 @@ func TestLogoutHandler(t *testing.T) {
     db.Create(&user)
 
     // 创建 refresh token => "LogoutRefresh_123"
+    session := models.Session{UserID: user.ID, ExpiresAt: time.Now().Add(24*time.Hour)}
+    db.Create(&session)
     rt := models.RefreshToken{
-        Token:     "LogoutRefresh_123",
+        JTI:       "LogoutRefresh_123",
+        TokenHash: utils.HashToken("LogoutRefresh_123"),
         UserID:    user.ID,
+        SessionID: session.ID,
         ExpiresAt: time.Now().Add(24*time.Hour),
         Revoked:   false,
     }
     db.Create(&rt)
 
-    // 再给此用户多创建几个 refresh token => 也要同时撤销
+    // 同一会话中更早轮换出的 refresh token => 也要同时撤销
     rtExtra := models.RefreshToken{
-        Token:     "ExtraRefresh_1",
+        JTI:       "ExtraRefresh_1",
+        TokenHash: utils.HashToken("ExtraRefresh_1"),
         UserID:    user.ID,
+        SessionID: session.ID,
         ExpiresAt: time.Now().Add(24*time.Hour),
         Revoked:   false,
     }
@@ -0,0 +1,108 @@
+// internal/controllers/user_follow_controller.go
+package controllers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+// followTarget 解析并校验要关注的用户，出错时已写入响应
+func (uc *UserController) followTarget(c *gin.Context) (followerID, followeeID uint, ok bool) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return 0, 0, false
+    }
+    targetID, err := strconv.Atoi(c.Param("id"))
+    if err != nil || targetID <= 0 {
+        apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid user ID"))
+        return 0, 0, false
+    }
+    if uint(targetID) == userID.(uint) {
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("Cannot follow yourself"))
+        return 0, 0, false
+    }
+
+    var target models.User
+    if err := uc.DB.Select("id").First(&target, targetID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            apierror.Respond(c, apierror.ErrUserNotFound)
+            return 0, 0, false
+        }
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch user data"))
+        return 0, 0, false
+    }
+    return userID.(uint), target.ID, true
+}
+
+func (uc *UserController) followerCount(userID uint) (int64, error) {
+    var count int64
+    err := uc.DB.Model(&models.UserFollow{}).Where("followee_id = ?", userID).Count(&count).Error
+    return count, err
+}
+
+// FollowUser 关注用户
+func (uc *UserController) FollowUser(c *gin.Context) {
+    followerID, followeeID, ok := uc.followTarget(c)
+    if !ok {
+        return
+    }
+
+    result := uc.DB.Clauses(clause.OnConflict{DoNothing: true}).
+        Create(&models.UserFollow{FollowerID: followerID, FolloweeID: followeeID})
+    if result.Error != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to follow user"))
+        return
+    }
+    if result.RowsAffected == 0 {
+        apierror.Respond(c, apierror.ErrAlreadyExists.WithMessage("You are already following this user"))
+        return
+    }
+    recordFollowerStat(uc.DB, followeeID, 1)
+
+    count, err := uc.followerCount(followeeID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to count followers"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{
+        "message":        "User followed successfully",
+        "follower_count": count,
+    })
+}
+
+// UnfollowUser 取消关注用户
+func (uc *UserController) UnfollowUser(c *gin.Context) {
+    followerID, followeeID, ok := uc.followTarget(c)
+    if !ok {
+        return
+    }
+
+    result := uc.DB.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.UserFollow{})
+    if result.Error != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to unfollow user"))
+        return
+    }
+    if result.RowsAffected == 0 {
+        apierror.Respond(c, apierror.ErrNotAllowed.WithMessage("You are not following this user"))
+        return
+    }
+    recordFollowerStat(uc.DB, followeeID, -1)
+
+    count, err := uc.followerCount(followeeID)
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to count followers"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{
+        "message":        "User unfollowed successfully",
+        "follower_count": count,
+    })
+}
@@ -0,0 +1,125 @@
+// internal/controllers/view_history_controller.go
+package controllers
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+const (
+    defaultHistoryPageSize = 20
+    maxHistoryPageSize     = 50
+    maxViewedNews          = 200 // GetMyViewedNews 最多返回的新闻数
+)
+
+// ViewHistoryItem 阅读历史中的一条记录
+type ViewHistoryItem struct {
+    ID              uint      `json:"id"`
+    NewsID          uint      `json:"news_id"`
+    NewsTitle       string    `json:"news_title"`
+    DurationSeconds int       `json:"duration_seconds"`
+    ScrollDepth     int       `json:"scroll_depth"`
+    ViewedAt        time.Time `json:"viewed_at"`
+    LastActiveAt    time.Time `json:"last_active_at"`
+}
+
+// GetViewHistory 分页获取阅读历史，最近的在前；同一篇新闻的多次阅读分别列出，cursor 为上一页最后一条记录的 ID
+func (uc *UserController) GetViewHistory(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    limit := defaultHistoryPageSize
+    if v := c.Query("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1 || n > maxHistoryPageSize {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid limit"))
+            return
+        }
+        limit = n
+    }
+
+    query := uc.DB.Table("news_views").
+        Select(`news_views.id, news_views.news_id, news.title AS news_title, news_views.duration_seconds,
+            news_views.scroll_depth, news_views.created_at AS viewed_at, news_views.updated_at AS last_active_at`).
+        Joins("JOIN news ON news.id = news_views.news_id").
+        Where("news_views.user_id = ?", userID).
+        Scopes(models.NotBlockedBy(userID.(uint), "news.author_id"))
+    if v := c.Query("cursor"); v != "" {
+        cursor, err := strconv.ParseUint(v, 10, 64)
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid cursor"))
+            return
+        }
+        query = query.Where("news_views.id < ?", cursor)
+    }
+
+    items := make([]ViewHistoryItem, 0, limit+1)
+    if err := query.Order("news_views.id DESC").Limit(limit + 1).Scan(&items).Error; err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to fetch view history"))
+        return
+    }
+    hasMore := len(items) > limit
+    nextCursor := ""
+    if hasMore {
+        items = items[:limit]
+        nextCursor = strconv.FormatUint(uint64(items[limit-1].ID), 10)
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "history":     items,
+        "next_cursor": nextCursor,
+        "has_more":    hasMore,
+    })
+}
+
+// ClearViewHistory 清空阅读历史；指定 news_id 时只删除该新闻的记录。已计入的浏览量不会减少
+func (uc *UserController) ClearViewHistory(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        apierror.Respond(c, apierror.ErrUnauthorized)
+        return
+    }
+
+    var newsID uint64
+    if v := c.Query("news_id"); v != "" {
+        id, err := strconv.ParseUint(v, 10, 64)
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInvalidParameter.WithMessage("Invalid news ID"))
+            return
+        }
+        newsID = id
+    }
+
+    var deleted int64
+    err := uc.DB.Transaction(func(tx *gorm.DB) error {
+        cond, args := "user_id = ?", []interface{}{userID}
+        if newsID != 0 {
+            cond, args = cond+" AND news_id = ?", append(args, newsID)
+        }
+        result := tx.Where(cond, args...).Delete(&models.NewsView{})
+        if result.Error != nil {
+            return result.Error
+        }
+        deleted = result.RowsAffected
+        // 同时移出“看过的新闻”列表
+        return tx.Exec("DELETE FROM user_viewed_news WHERE "+cond, args...).Error
+    })
+    if err != nil {
+        apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to clear view history"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "View history cleared successfully",
+        "deleted": deleted,
+    })
+}
@@ -0,0 +1,85 @@
+// internal/imaging/exif.go
+package imaging
+
+import (
+    "bytes"
+    "encoding/binary"
+)
+
+// jpegOrientation 从 JPEG 的 EXIF（APP1）段读取 Orientation 标签，找不到或无法解析时返回 1
+func jpegOrientation(data []byte) int {
+    if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+        return 1
+    }
+
+    pos := 2
+    for pos+4 <= len(data) {
+        if data[pos] != 0xFF {
+            return 1
+        }
+        marker := data[pos+1]
+        if marker == 0xD8 || (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 || marker == 0xFF {
+            pos++
+            continue
+        }
+        if marker == 0xDA || marker == 0xD9 { // 图像数据开始，EXIF 只会出现在之前
+            return 1
+        }
+
+        length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+        if length < 2 || pos+2+length > len(data) {
+            return 1
+        }
+        segment := data[pos+4 : pos+2+length]
+        if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+            return tiffOrientation(segment[6:])
+        }
+        pos += 2 + length
+    }
+    return 1
+}
+
+// tiffOrientation 在 TIFF 结构的 IFD0 中查找 Orientation（0x0112）
+func tiffOrientation(tiff []byte) int {
+    if len(tiff) < 8 {
+        return 1
+    }
+
+    var order binary.ByteOrder
+    switch string(tiff[:2]) {
+    case "II":
+        order = binary.LittleEndian
+    case "MM":
+        order = binary.BigEndian
+    default:
+        return 1
+    }
+    if order.Uint16(tiff[2:4]) != 42 {
+        return 1
+    }
+
+    ifd := int(order.Uint32(tiff[4:8]))
+    if ifd < 8 || ifd+2 > len(tiff) {
+        return 1
+    }
+    count := int(order.Uint16(tiff[ifd : ifd+2]))
+    for i := 0; i < count; i++ {
+        entry := ifd + 2 + i*12
+        if entry+12 > len(tiff) {
+            return 1
+        }
+        if order.Uint16(tiff[entry:entry+2]) != 0x0112 {
+            continue
+        }
+        // 类型应为 SHORT（3），值直接存放在条目中
+        if order.Uint16(tiff[entry+2:entry+4]) != 3 {
+            return 1
+        }
+        value := int(order.Uint16(tiff[entry+8 : entry+10]))
+        if value < 1 || value > 8 {
+            return 1
+        }
+        return value
+    }
+    return 1
+}
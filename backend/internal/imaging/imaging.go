@@ -0,0 +1,243 @@
+// internal/imaging/imaging.go
+// 上传图片处理：校验真实格式、按 EXIF 方向摆正后去除元数据、生成多种尺寸（每种尺寸另有 WebP 版本）
+package imaging
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "image"
+    "image/draw"
+    "image/jpeg"
+    "image/png"
+    "net/http"
+
+    _ "image/gif" // 注册 GIF 解码器（动图只保留第一帧）
+)
+
+// 处理失败的原因，错误信息直接返回给客户端
+var (
+    ErrEmpty             = errors.New("Image file is empty")
+    ErrTooLarge          = errors.New("Image file is too large")
+    ErrUnsupportedFormat = errors.New("Unsupported image format")
+    ErrCorrupt           = errors.New("Image file is corrupt")
+    ErrDimensions        = errors.New("Image dimensions exceed the limit")
+)
+
+// 默认的大小限制
+const (
+    MaxFileSize  = 10 << 20   // 原始文件大小上限（字节）
+    MaxDimension = 10000      // 单边像素上限
+    MaxPixels    = 40_000_000 // 总像素上限，在完整解码前检查，防止解压炸弹
+    JPEGQuality  = 85
+    WebPQuality  = 80
+)
+
+// Size 一种输出尺寸，图片按比例缩放到最长边不超过 MaxEdge，不会放大
+type Size struct {
+    Name    string
+    MaxEdge int
+}
+
+// Profile 一类图片的输出尺寸配置，Canonical 为默认展示使用的尺寸
+type Profile struct {
+    Kind      string
+    Sizes     []Size // 由小到大
+    Canonical string
+}
+
+// 新闻图片和头像的输出配置
+var (
+    NewsProfile = Profile{
+        Kind:      "news",
+        Sizes:     []Size{{"thumb", 160}, {"small", 480}, {"medium", 1080}, {"large", 1920}},
+        Canonical: "large",
+    }
+    AvatarProfile = Profile{
+        Kind:      "avatar",
+        Sizes:     []Size{{"thumb", 96}, {"small", 256}, {"medium", 640}},
+        Canonical: "medium",
+    }
+)
+
+// Variant 一个输出文件。尺寸相同的多个名称共用同一个文件
+type Variant struct {
+    Name   string
+    Format string // jpeg / png / webp
+    File   string // 文件名，如 large.jpg
+    Width  int
+    Height int
+    Data   []byte
+}
+
+// Result 图片处理结果
+type Result struct {
+    Hash     string // 原始文件内容的 SHA-256
+    Format   string // 原始格式
+    Width    int    // 摆正后的原始宽度
+    Height   int
+    Variants []Variant
+}
+
+// Canonical 返回指定格式的默认展示版本
+func (r *Result) Canonical(profile Profile) *Variant {
+    for i := range r.Variants {
+        v := &r.Variants[i]
+        if v.Name == profile.Canonical && v.Format != "webp" {
+            return v
+        }
+    }
+    return nil
+}
+
+// ContentHash 计算文件内容的 SHA-256
+func ContentHash(data []byte) string {
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+// Sniff 根据文件内容识别图片格式，并读取尺寸（不完整解码）
+func Sniff(data []byte) (string, image.Config, error) {
+    if len(data) == 0 {
+        return "", image.Config{}, ErrEmpty
+    }
+
+    var format string
+    switch http.DetectContentType(data) {
+    case "image/jpeg":
+        format = "jpeg"
+    case "image/png":
+        format = "png"
+    case "image/gif":
+        format = "gif"
+    default:
+        // WebP 等其它格式没有可用的解码器
+        return "", image.Config{}, ErrUnsupportedFormat
+    }
+
+    cfg, decoded, err := image.DecodeConfig(bytes.NewReader(data))
+    if err != nil || decoded != format {
+        return "", image.Config{}, ErrCorrupt
+    }
+    return format, cfg, nil
+}
+
+// Process 校验并处理上传的图片，返回各尺寸的 JPEG/PNG 编码结果及对应的 WebP 版本
+func Process(data []byte, profile Profile) (*Result, error) {
+    if len(data) > MaxFileSize {
+        return nil, ErrTooLarge
+    }
+
+    format, cfg, err := Sniff(data)
+    if err != nil {
+        return nil, err
+    }
+    if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > MaxDimension || cfg.Height > MaxDimension ||
+        cfg.Width*cfg.Height > MaxPixels {
+        return nil, ErrDimensions
+    }
+
+    decoded, _, err := image.Decode(bytes.NewReader(data))
+    if err != nil {
+        return nil, ErrCorrupt
+    }
+
+    // 统一转换为预乘 RGBA 后按 EXIF 方向摆正；重新编码时不会写入任何元数据（包括 GPS）
+    src := toRGBA(decoded)
+    if format == "jpeg" {
+        src = applyOrientation(src, jpegOrientation(data))
+    }
+    bounds := src.Bounds()
+    opaque := src.Opaque()
+
+    result := &Result{
+        Hash:   ContentHash(data),
+        Format: format,
+        Width:  bounds.Dx(),
+        Height: bounds.Dy(),
+    }
+
+    // 由小到大生成各尺寸，尺寸与上一个相同（原图较小）时复用同一文件
+    var last []Variant
+    for _, size := range profile.Sizes {
+        w, h := fitWithin(bounds.Dx(), bounds.Dy(), size.MaxEdge)
+        if len(last) > 0 && last[0].Width == w && last[0].Height == h {
+            for _, v := range last {
+                v.Name = size.Name
+                result.Variants = append(result.Variants, v)
+            }
+            continue
+        }
+
+        resized := src
+        if w != bounds.Dx() || h != bounds.Dy() {
+            resized = resizeArea(src, w, h)
+        }
+
+        encoded, err := encodeSize(resized, size.Name, opaque)
+        if err != nil {
+            return nil, err
+        }
+        result.Variants = append(result.Variants, encoded...)
+        last = encoded
+    }
+
+    return result, nil
+}
+
+// encodeSize 将一种尺寸编码为 JPEG 和有损 WebP（不透明），或 PNG 和无损 WebP（含透明度）
+func encodeSize(img *image.RGBA, name string, opaque bool) ([]Variant, error) {
+    w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+    var buf, webpBuf bytes.Buffer
+    primary := Variant{Name: name, Width: w, Height: h}
+    if opaque {
+        if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: JPEGQuality}); err != nil {
+            return nil, fmt.Errorf("encode jpeg: %w", err)
+        }
+        primary.Format, primary.File, primary.Data = "jpeg", name+".jpg", buf.Bytes()
+        if err := EncodeWebP(&webpBuf, img, WebPQuality); err != nil {
+            return nil, fmt.Errorf("encode webp: %w", err)
+        }
+    } else {
+        if err := png.Encode(&buf, img); err != nil {
+            return nil, fmt.Errorf("encode png: %w", err)
+        }
+        primary.Format, primary.File, primary.Data = "png", name+".png", buf.Bytes()
+        if err := EncodeLosslessWebP(&webpBuf, img); err != nil {
+            return nil, fmt.Errorf("encode webp: %w", err)
+        }
+    }
+    webp := Variant{Name: name, Format: "webp", File: name + ".webp", Width: w, Height: h, Data: webpBuf.Bytes()}
+
+    return []Variant{primary, webp}, nil
+}
+
+// fitWithin 计算按比例缩放到最长边不超过 maxEdge 后的尺寸（不放大）
+func fitWithin(w, h, maxEdge int) (int, int) {
+    if w <= maxEdge && h <= maxEdge {
+        return w, h
+    }
+    if w >= h {
+        nh := h * maxEdge / w
+        if nh < 1 {
+            nh = 1
+        }
+        return maxEdge, nh
+    }
+    nw := w * maxEdge / h
+    if nw < 1 {
+        nw = 1
+    }
+    return nw, maxEdge
+}
+
+// toRGBA 将任意图像转换为以 (0,0) 为原点的预乘 RGBA
+func toRGBA(img image.Image) *image.RGBA {
+    b := img.Bounds()
+    dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+    draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+    return dst
+}
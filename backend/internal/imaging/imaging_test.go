@@ -0,0 +1,361 @@
+// internal/imaging/imaging_test.go
+package imaging
+
+import (
+    "bytes"
+    "encoding/binary"
+    "hash/crc32"
+    "image"
+    "image/color"
+    "image/jpeg"
+    "image/png"
+    "math"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "golang.org/x/image/webp"
+)
+
+// testImage 生成带渐变的测试图像
+func testImage(w, h int, withAlpha bool) *image.NRGBA {
+    img := image.NewNRGBA(image.Rect(0, 0, w, h))
+    for y := 0; y < h; y++ {
+        for x := 0; x < w; x++ {
+            a := uint8(255)
+            if withAlpha {
+                a = uint8((x * 255) / w)
+            }
+            img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 7), G: uint8(y * 3), B: uint8(x ^ y), A: a})
+        }
+    }
+    return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+    var buf bytes.Buffer
+    assert.NoError(t, png.Encode(&buf, img))
+    return buf.Bytes()
+}
+
+// withOrientation 在 JPEG 的 SOI 之后插入只包含 Orientation 和 GPS IFD 指针的 EXIF 段
+func withOrientation(jpegData []byte, orientation uint16) []byte {
+    tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+    entries := []byte{0x00, 0x02}
+    entries = append(entries, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, byte(orientation>>8), byte(orientation), 0x00, 0x00)
+    entries = append(entries, 0x88, 0x25, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00)
+    entries = append(entries, 0x00, 0x00, 0x00, 0x00)
+    payload := append([]byte("Exif\x00\x00"), append(tiff, entries...)...)
+
+    segment := []byte{0xFF, 0xE1, 0, 0}
+    binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+    segment = append(segment, payload...)
+
+    out := append([]byte{}, jpegData[:2]...)
+    out = append(out, segment...)
+    return append(out, jpegData[2:]...)
+}
+
+func TestSniff(t *testing.T) {
+    pngData := encodePNG(t, testImage(10, 8, false))
+
+    format, cfg, err := Sniff(pngData)
+    assert.NoError(t, err)
+    assert.Equal(t, "png", format)
+    assert.Equal(t, 10, cfg.Width)
+
+    _, _, err = Sniff([]byte("fake_image_data"))
+    assert.Equal(t, ErrUnsupportedFormat, err)
+
+    _, _, err = Sniff(pngData[:20])
+    assert.Equal(t, ErrCorrupt, err)
+
+    _, _, err = Sniff(nil)
+    assert.Equal(t, ErrEmpty, err)
+}
+
+func TestProcessSizes(t *testing.T) {
+    data := encodePNG(t, testImage(600, 300, false))
+
+    result, err := Process(data, NewsProfile)
+    assert.NoError(t, err)
+    assert.Equal(t, ContentHash(data), result.Hash)
+    assert.Equal(t, 600, result.Width)
+
+    byKey := map[string]Variant{}
+    for _, v := range result.Variants {
+        byKey[v.Name+"/"+v.Format] = v
+    }
+    // 不透明图片每种尺寸输出 JPEG 和有损 WebP
+    assert.Len(t, result.Variants, 8)
+    assert.Equal(t, 160, byKey["thumb/jpeg"].Width)
+    assert.Equal(t, 80, byKey["thumb/jpeg"].Height)
+    assert.Equal(t, 480, byKey["small/jpeg"].Width)
+    // 原图小于 medium/large，不放大，复用同一文件
+    assert.Equal(t, 600, byKey["large/jpeg"].Width)
+    assert.Equal(t, "medium.jpg", byKey["large/jpeg"].File)
+    assert.Equal(t, "medium.webp", byKey["large/webp"].File)
+    assert.Equal(t, "large", result.Canonical(NewsProfile).Name)
+
+    decoded, err := jpeg.Decode(bytes.NewReader(byKey["thumb/jpeg"].Data))
+    assert.NoError(t, err)
+    assert.Equal(t, image.Rect(0, 0, 160, 80), decoded.Bounds())
+}
+
+func TestProcessTransparentUsesPNG(t *testing.T) {
+    result, err := Process(encodePNG(t, testImage(50, 40, true)), AvatarProfile)
+    assert.NoError(t, err)
+    assert.Equal(t, "png", result.Variants[0].Format)
+    assert.Equal(t, "thumb.png", result.Variants[0].File)
+}
+
+// TestProcessWebPVariants 每种尺寸都有 WebP 版本：照片使用有损编码且小于 JPEG，透明图片使用无损编码
+func TestProcessWebPVariants(t *testing.T) {
+    cases := map[string]struct {
+        img   image.Image
+        chunk string
+    }{
+        "photo":       {smoothImage(1200, 800), "VP8 "},
+        "transparent": {testImage(600, 400, true), "VP8L"},
+    }
+    for name, c := range cases {
+        result, err := Process(encodePNG(t, c.img), NewsProfile)
+        if !assert.NoError(t, err, name) {
+            continue
+        }
+        primary := map[string]Variant{}
+        for _, v := range result.Variants {
+            if v.Format != "webp" {
+                primary[v.Name] = v
+            }
+        }
+        webps := 0
+        for _, v := range result.Variants {
+            if v.Format != "webp" {
+                continue
+            }
+            webps++
+            assert.Equal(t, c.chunk, string(v.Data[12:16]), "%s %s", name, v.File)
+            decoded, err := webp.Decode(bytes.NewReader(v.Data))
+            if assert.NoError(t, err, "%s %s", name, v.File) {
+                assert.Equal(t, image.Rect(0, 0, v.Width, v.Height), decoded.Bounds())
+            }
+            if name == "photo" {
+                assert.Less(t, len(v.Data), len(primary[v.Name].Data), v.File)
+            }
+        }
+        assert.Equal(t, 4, webps, name)
+    }
+}
+
+func TestProcessStripsExifAndAppliesOrientation(t *testing.T) {
+    var buf bytes.Buffer
+    assert.NoError(t, jpeg.Encode(&buf, testImage(40, 20, false), nil))
+    data := withOrientation(buf.Bytes(), 6)
+    assert.Equal(t, 6, jpegOrientation(data))
+
+    result, err := Process(data, AvatarProfile)
+    assert.NoError(t, err)
+    // 顺时针旋转 90° 后宽高互换
+    assert.Equal(t, 20, result.Width)
+    assert.Equal(t, 40, result.Height)
+
+    for _, v := range result.Variants {
+        if v.Format == "jpeg" {
+            assert.False(t, bytes.Contains(v.Data, []byte("Exif")), "variant %s still has EXIF", v.File)
+            assert.Equal(t, 1, jpegOrientation(v.Data))
+        }
+    }
+}
+
+func TestProcessLimits(t *testing.T) {
+    _, err := Process(make([]byte, MaxFileSize+1), NewsProfile)
+    assert.Equal(t, ErrTooLarge, err)
+
+    // 只修改 PNG 头中的宽度（并修正校验和），完整解码前就会被拒绝
+    data := encodePNG(t, testImage(4, 4, false))
+    binary.BigEndian.PutUint32(data[16:20], MaxDimension+1)
+    binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+    _, err = Process(data, NewsProfile)
+    assert.Equal(t, ErrDimensions, err)
+}
+
+func TestApplyOrientation(t *testing.T) {
+    src := toRGBA(testImage(3, 2, false))
+    for orientation := 1; orientation <= 8; orientation++ {
+        dst := applyOrientation(src, orientation)
+        if orientation >= 5 {
+            assert.Equal(t, image.Rect(0, 0, 2, 3), dst.Bounds())
+        } else {
+            assert.Equal(t, image.Rect(0, 0, 3, 2), dst.Bounds())
+        }
+    }
+    // 顺时针旋转 90°：源图左下角成为新图左上角
+    rotated := applyOrientation(src, 6)
+    assert.Equal(t, src.RGBAAt(0, 1), rotated.RGBAAt(0, 0))
+    assert.Equal(t, src.RGBAAt(2, 0), rotated.RGBAAt(1, 2))
+}
+
+func TestResizeAreaAverages(t *testing.T) {
+    src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+    for x := 0; x < 4; x++ {
+        v := uint8(0)
+        if x%2 == 1 {
+            v = 200
+        }
+        src.SetRGBA(x, 0, color.RGBA{v, v, v, 255})
+        src.SetRGBA(x, 1, color.RGBA{v, v, v, 255})
+    }
+    dst := resizeArea(src, 2, 1)
+    assert.Equal(t, color.RGBA{100, 100, 100, 255}, dst.RGBAAt(0, 0))
+    assert.Equal(t, color.RGBA{100, 100, 100, 255}, dst.RGBAAt(1, 0))
+}
+
+// smoothImage 生成颜色平滑变化的不透明图像，近似照片内容
+func smoothImage(w, h int) *image.NRGBA {
+    img := image.NewNRGBA(image.Rect(0, 0, w, h))
+    for y := 0; y < h; y++ {
+        for x := 0; x < w; x++ {
+            fx, fy := float64(x)/float64(w), float64(y)/float64(h)
+            img.SetNRGBA(x, y, color.NRGBA{
+                R: uint8(128 + 100*math.Sin(fx*6+fy*2)),
+                G: uint8(128 + 90*math.Cos(fy*5-fx*2)),
+                B: uint8(128 + 80*math.Sin((fx+fy)*4)),
+                A: 255,
+            })
+        }
+    }
+    return img
+}
+
+func TestEncodeLosslessWebPRoundTrip(t *testing.T) {
+    cases := map[string]image.Image{
+        "gradient":    testImage(37, 23, false),
+        "alpha":       testImage(16, 9, true),
+        "large alpha": testImage(300, 200, true),
+        "single":      image.NewNRGBA(image.Rect(0, 0, 5, 3)),
+        "two colours": twoColours(),
+        "skewed":      skewedImage(),
+    }
+    for name, img := range cases {
+        t.Run(name, func(t *testing.T) {
+            var buf bytes.Buffer
+            if !assert.NoError(t, EncodeLosslessWebP(&buf, img)) {
+                return
+            }
+            data := buf.Bytes()
+            assert.Equal(t, "RIFF", string(data[0:4]))
+            assert.Equal(t, "WEBP", string(data[8:12]))
+            assert.Equal(t, "VP8L", string(data[12:16]))
+            assert.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:8]))
+
+            decoded, err := webp.Decode(bytes.NewReader(data))
+            if !assert.NoError(t, err) || !assert.Equal(t, img.Bounds().Size(), decoded.Bounds().Size()) {
+                return
+            }
+
+            b := img.Bounds()
+            for y := 0; y < b.Dy(); y++ {
+                for x := 0; x < b.Dx(); x++ {
+                    want := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y))
+                    got := color.NRGBAModel.Convert(decoded.At(x, y))
+                    if !assert.Equal(t, want, got, "pixel %d,%d", x, y) {
+                        return
+                    }
+                }
+            }
+        })
+    }
+}
+
+// TestEncodeWebPLossy 有损编码的结果按 BT.601 有限范围换算回 RGB 后与原图足够接近
+func TestEncodeWebPLossy(t *testing.T) {
+    for _, size := range []image.Point{{1, 1}, {37, 23}, {160, 96}, {481, 250}} {
+        img := smoothImage(size.X, size.Y)
+        var buf bytes.Buffer
+        if !assert.NoError(t, EncodeWebP(&buf, img, WebPQuality)) {
+            continue
+        }
+        data := buf.Bytes()
+        assert.Equal(t, "VP8 ", string(data[12:16]))
+        assert.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:8]))
+
+        decoded, err := webp.Decode(bytes.NewReader(data))
+        if !assert.NoError(t, err, "%v", size) || !assert.Equal(t, size, decoded.Bounds().Size()) {
+            continue
+        }
+        ycc, ok := decoded.(*image.YCbCr)
+        if !assert.True(t, ok) {
+            continue
+        }
+
+        var sum float64
+        for y := 0; y < size.Y; y++ {
+            for x := 0; x < size.X; x++ {
+                yy := float64(ycc.Y[ycc.YOffset(x, y)]) - 16
+                cb := float64(ycc.Cb[ycc.COffset(x, y)]) - 128
+                cr := float64(ycc.Cr[ycc.COffset(x, y)]) - 128
+                want := img.NRGBAAt(x, y)
+                for i, got := range []float64{
+                    1.164*yy + 1.596*cr,
+                    1.164*yy - 0.813*cr - 0.391*cb,
+                    1.164*yy + 2.018*cb,
+                } {
+                    d := got - float64([]uint8{want.R, want.G, want.B}[i])
+                    sum += d * d
+                }
+            }
+        }
+        psnr := 10 * math.Log10(255*255/(sum/float64(3*size.X*size.Y)))
+        assert.Greater(t, psnr, 30.0, "%v", size)
+    }
+}
+
+// skewedImage 红色通道的取值频率呈斐波那契分布，不限制时霍夫曼码长会超过 15
+func skewedImage() image.Image {
+    img := image.NewNRGBA(image.Rect(0, 0, 128, 0))
+    var pix []uint8
+    a, b := 1, 1
+    for value := 0; value < 24; value++ {
+        for i := 0; i < a; i++ {
+            pix = append(pix, uint8(value), 0, 0, 255)
+        }
+        a, b = b, a+b
+    }
+    for len(pix)%(128*4) != 0 {
+        pix = append(pix, 0, 0, 0, 255)
+    }
+    img.Pix = pix
+    img.Rect = image.Rect(0, 0, 128, len(pix)/(128*4))
+    return img
+}
+
+func TestHuffmanLengthsLimit(t *testing.T) {
+    freq := make([]int, 30)
+    a, b := 1, 1
+    for i := range freq {
+        freq[i] = a
+        a, b = b, a+b
+    }
+    lengths := huffmanLengths(freq, vp8lMaxCodeLength)
+
+    kraft := 0.0
+    for _, l := range lengths {
+        assert.True(t, l > 0 && l <= vp8lMaxCodeLength)
+        kraft += 1 / float64(uint(1)<<l)
+    }
+    assert.Equal(t, 1.0, kraft)
+}
+
+func twoColours() image.Image {
+    img := image.NewNRGBA(image.Rect(0, 0, 6, 6))
+    for y := 0; y < 6; y++ {
+        for x := 0; x < 6; x++ {
+            if (x+y)%2 == 0 {
+                img.SetNRGBA(x, y, color.NRGBA{255, 255, 255, 255})
+            } else {
+                img.SetNRGBA(x, y, color.NRGBA{0, 0, 0, 255})
+            }
+        }
+    }
+    return img
+}
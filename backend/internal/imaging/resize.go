@@ -0,0 +1,148 @@
+// internal/imaging/resize.go
+package imaging
+
+import (
+    "image"
+    "math"
+)
+
+// span 目标像素覆盖的一段源像素及其权重
+type span struct {
+    index  int
+    weight float64
+}
+
+// areaSpans 计算缩小时每个目标像素覆盖的源像素及覆盖比例，每个目标像素的权重之和为 1
+func areaSpans(srcLen, dstLen int) [][]span {
+    scale := float64(srcLen) / float64(dstLen)
+    spans := make([][]span, dstLen)
+    for d := 0; d < dstLen; d++ {
+        start := float64(d) * scale
+        end := start + scale
+        for s := int(start); s < srcLen && float64(s) < end; s++ {
+            lo := math.Max(start, float64(s))
+            hi := math.Min(end, float64(s+1))
+            if hi > lo {
+                spans[d] = append(spans[d], span{index: s, weight: (hi - lo) / scale})
+            }
+        }
+    }
+    return spans
+}
+
+// resizeArea 使用面积平均（box filter）将预乘 RGBA 图像缩小到 w×h，逐行处理以控制内存
+func resizeArea(src *image.RGBA, w, h int) *image.RGBA {
+    sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+    dst := image.NewRGBA(image.Rect(0, 0, w, h))
+    columns := areaSpans(sw, w)
+    rows := areaSpans(sh, h)
+
+    // 每个源行参与哪些目标行
+    type contribution struct {
+        dstRow int
+        weight float64
+    }
+    bySourceRow := make([][]contribution, sh)
+    for y, list := range rows {
+        for _, s := range list {
+            bySourceRow[s.index] = append(bySourceRow[s.index], contribution{dstRow: y, weight: s.weight})
+        }
+    }
+
+    line := make([]float64, w*4)
+    acc := make([]float64, w*4*2) // 缩小时一个源行最多参与相邻两个目标行，两个槽位交替使用
+    accRow := [2]int{-1, -1}
+
+    flush := func(slot int) {
+        y := accRow[slot]
+        if y < 0 {
+            return
+        }
+        buf := acc[slot*w*4 : (slot+1)*w*4]
+        out := dst.Pix[y*dst.Stride : y*dst.Stride+w*4]
+        for i, v := range buf {
+            out[i] = uint8(math.Min(255, math.Max(0, math.Round(v))))
+            buf[i] = 0
+        }
+        accRow[slot] = -1
+    }
+
+    for sy := 0; sy < sh; sy++ {
+        if len(bySourceRow[sy]) == 0 {
+            continue
+        }
+
+        // 水平方向重采样当前源行
+        srcRow := src.Pix[sy*src.Stride : sy*src.Stride+sw*4]
+        for x, list := range columns {
+            var r, g, b, a float64
+            for _, s := range list {
+                p := srcRow[s.index*4 : s.index*4+4]
+                r += float64(p[0]) * s.weight
+                g += float64(p[1]) * s.weight
+                b += float64(p[2]) * s.weight
+                a += float64(p[3]) * s.weight
+            }
+            line[x*4], line[x*4+1], line[x*4+2], line[x*4+3] = r, g, b, a
+        }
+
+        // 垂直方向累加到对应的目标行，目标行不再有新的源行时写出
+        for _, c := range bySourceRow[sy] {
+            slot := c.dstRow % 2
+            if accRow[slot] != c.dstRow {
+                flush(slot)
+                accRow[slot] = c.dstRow
+            }
+            buf := acc[slot*w*4 : (slot+1)*w*4]
+            for i, v := range line {
+                buf[i] += v * c.weight
+            }
+        }
+        for slot := 0; slot < 2; slot++ {
+            if y := accRow[slot]; y >= 0 && rows[y][len(rows[y])-1].index == sy {
+                flush(slot)
+            }
+        }
+    }
+    flush(0)
+    flush(1)
+    return dst
+}
+
+// applyOrientation 按 EXIF Orientation（1-8）旋转或翻转图像
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+    if orientation < 2 || orientation > 8 {
+        return src
+    }
+
+    w, h := src.Bounds().Dx(), src.Bounds().Dy()
+    dw, dh := w, h
+    if orientation >= 5 {
+        dw, dh = h, w
+    }
+    dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+    for y := 0; y < dh; y++ {
+        for x := 0; x < dw; x++ {
+            var sx, sy int
+            switch orientation {
+            case 2: // 水平翻转
+                sx, sy = w-1-x, y
+            case 3: // 旋转 180°
+                sx, sy = w-1-x, h-1-y
+            case 4: // 垂直翻转
+                sx, sy = x, h-1-y
+            case 5: // 沿主对角线翻转
+                sx, sy = y, x
+            case 6: // 顺时针旋转 90°
+                sx, sy = y, h-1-x
+            case 7: // 沿副对角线翻转
+                sx, sy = w-1-y, h-1-x
+            case 8: // 逆时针旋转 90°
+                sx, sy = w-1-y, x
+            }
+            copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+        }
+    }
+    return dst
+}
@@ -0,0 +1,1164 @@
+// internal/imaging/vp8.go
+// 有损 WebP（VP8 关键帧）编码器，不透明图片使用。实现 RFC 6386 中的一个子集：
+// 亮度只用 16x16 整块预测（DC/V/H/TM），不用分段和环路滤波，整幅图使用同一量化参数，
+// 系数概率在默认值的基础上按本图的统计结果更新。重建过程与解码端逐位一致，误差不会在宏块间累积
+package imaging
+
+import (
+    "errors"
+    "image"
+    "io"
+    "math"
+)
+
+const (
+    vp8MaxDimension = 1 << 14
+    vp8NumPlanes    = 4
+    vp8NumBands     = 8
+    vp8NumContexts  = 3
+    vp8NumProbs     = 11
+)
+
+// 系数所属的平面，决定使用哪组概率
+const (
+    vp8PlaneYAfterY2 = 0 // 直流分量已由 Y2 块传输的亮度块
+    vp8PlaneY2       = 1
+    vp8PlaneUV       = 2
+)
+
+// 预测模式，与解码端的编号一致
+const (
+    vp8PredDC = iota
+    vp8PredTM
+    vp8PredVE
+    vp8PredHE
+)
+
+// 系数下标（按 zigzag 顺序）对应的频带
+var vp8Bands = [17]int{0, 1, 2, 3, 6, 4, 5, 6, 6, 6, 6, 6, 6, 6, 6, 7, 0}
+
+// vp8Zigzag 第 n 个传输的系数在 4x4 块中的位置
+var vp8Zigzag = [16]int{0, 1, 4, 8, 5, 2, 3, 6, 9, 12, 13, 10, 7, 11, 14, 15}
+
+// 类别 3-6 的额外比特概率
+var vp8Cat3456 = [4][]uint8{
+    {173, 148, 140},
+    {176, 155, 140, 135},
+    {180, 157, 141, 134, 130},
+    {254, 254, 243, 230, 196, 177, 153, 140, 133, 130, 129},
+}
+
+// 量化步长表（RFC 6386 14.1 节）
+var vp8DCQuant = [128]int{
+    4, 5, 6, 7, 8, 9, 10, 10, 11, 12, 13, 14, 15, 16, 17, 17,
+    18, 19, 20, 20, 21, 21, 22, 22, 23, 23, 24, 25, 25, 26, 27, 28,
+    29, 30, 31, 32, 33, 34, 35, 36, 37, 37, 38, 39, 40, 41, 42, 43,
+    44, 45, 46, 46, 47, 48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 58,
+    59, 60, 61, 62, 63, 64, 65, 66, 67, 68, 69, 70, 71, 72, 73, 74,
+    75, 76, 76, 77, 78, 79, 80, 81, 82, 83, 84, 85, 86, 87, 88, 89,
+    91, 93, 95, 96, 98, 100, 101, 102, 104, 106, 108, 110, 112, 114, 116, 118,
+    122, 124, 126, 128, 130, 132, 134, 136, 138, 140, 143, 145, 148, 151, 154, 157,
+}
+
+var vp8ACQuant = [128]int{
+    4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19,
+    20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34, 35,
+    36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
+    52, 53, 54, 55, 56, 57, 58, 60, 62, 64, 66, 68, 70, 72, 74, 76,
+    78, 80, 82, 84, 86, 88, 90, 92, 94, 96, 98, 100, 102, 104, 106, 108,
+    110, 112, 114, 116, 119, 122, 125, 128, 131, 134, 137, 140, 143, 146, 149, 152,
+    155, 158, 161, 164, 167, 170, 173, 177, 181, 185, 189, 193, 197, 201, 205, 209,
+    213, 217, 221, 225, 229, 234, 239, 245, 249, 254, 259, 264, 269, 274, 279, 284,
+}
+
+// EncodeWebP 将图像编码为有损 WebP，quality 取 1-100。透明度会被丢弃，含透明度的图片应使用 EncodeLosslessWebP
+func EncodeWebP(out io.Writer, img image.Image, quality int) error {
+    b := img.Bounds()
+    width, height := b.Dx(), b.Dy()
+    if width <= 0 || height <= 0 || width >= vp8MaxDimension || height >= vp8MaxDimension {
+        return errors.New("webp: invalid image dimensions")
+    }
+    quality = max(1, min(100, quality))
+
+    e := newVP8Encoder(img, (100-quality)*127/99)
+    for mby := 0; mby < e.mbh; mby++ {
+        for mbx := 0; mbx < e.mbw; mbx++ {
+            e.mbs = append(e.mbs, e.encodeMacroblock(mbx, mby))
+        }
+    }
+    return writeRIFF(out, "VP8 ", e.frame(width, height))
+}
+
+// vp8Quant 各类系数的量化步长，下标 0 为直流、1 为交流
+type vp8Quant struct {
+    index      int
+    y1, y2, uv [2]int
+}
+
+func newVP8Quant(q int) vp8Quant {
+    y2AC := vp8ACQuant[q] * 155 / 100
+    if y2AC < 8 {
+        y2AC = 8
+    }
+    return vp8Quant{
+        index: q,
+        y1:    [2]int{vp8DCQuant[q], vp8ACQuant[q]},
+        y2:    [2]int{vp8DCQuant[q] * 2, y2AC},
+        uv:    [2]int{vp8DCQuant[min(q, 117)], vp8ACQuant[q]},
+    }
+}
+
+// vp8Macroblock 一个宏块的编码决策，系数为量化后的值（按块内光栅顺序）
+type vp8Macroblock struct {
+    yMode, uvMode int
+    skip          bool
+    y2            [16]int
+    y             [16][16]int
+    uv            [8][16]int // 0-3 为 U，4-7 为 V
+}
+
+type vp8Encoder struct {
+    mbw, mbh         int
+    yStride, cStride int
+    quant            vp8Quant
+
+    // 原图的 YUV 4:2:0 平面，边缘复制补齐到宏块边界
+    srcY, srcU, srcV []uint8
+    // 重建结果，与解码端得到的图像一致，用于后续宏块的预测
+    recY, recU, recV []uint8
+
+    mbs []vp8Macroblock
+}
+
+func newVP8Encoder(img image.Image, q int) *vp8Encoder {
+    b := img.Bounds()
+    width, height := b.Dx(), b.Dy()
+    e := &vp8Encoder{
+        mbw:   (width + 15) / 16,
+        mbh:   (height + 15) / 16,
+        quant: newVP8Quant(q),
+    }
+    e.yStride, e.cStride = e.mbw*16, e.mbw*8
+    ySize, cSize := e.yStride*e.mbh*16, e.cStride*e.mbh*8
+    e.srcY, e.srcU, e.srcV = make([]uint8, ySize), make([]uint8, cSize), make([]uint8, cSize)
+    e.recY, e.recU, e.recV = make([]uint8, ySize), make([]uint8, cSize), make([]uint8, cSize)
+
+    rgb := func(x, y int) (int, int, int) {
+        r, g, bl, _ := nrgbaAt(img, b.Min.X+min(x, width-1), b.Min.Y+min(y, height-1))
+        return int(r), int(g), int(bl)
+    }
+    for y := 0; y < e.mbh*16; y++ {
+        for x := 0; x < e.yStride; x++ {
+            r, g, bl := rgb(x, y)
+            e.srcY[y*e.yStride+x] = uint8((16839*r + 33059*g + 6420*bl + 1<<15 + 16<<16) >> 16)
+        }
+    }
+    for y := 0; y < e.mbh*8; y++ {
+        for x := 0; x < e.cStride; x++ {
+            // 色度取 2x2 像素之和
+            var r, g, bl int
+            for _, d := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+                pr, pg, pb := rgb(2*x+d[0], 2*y+d[1])
+                r, g, bl = r+pr, g+pg, bl+pb
+            }
+            e.srcU[y*e.cStride+x] = clipUV(-9719*r - 19081*g + 28800*bl)
+            e.srcV[y*e.cStride+x] = clipUV(28800*r - 24116*g - 4684*bl)
+        }
+    }
+    return e
+}
+
+// clipUV 将 4 个像素之和的色度值换算为 8 位（BT.601 有限范围）
+func clipUV(v int) uint8 {
+    return clip8((v + 1<<17 + 128<<18) >> 18)
+}
+
+func clip8(v int) uint8 {
+    return uint8(max(0, min(255, v)))
+}
+
+// vp8Edges 宏块上方一行、左侧一列和左上角的重建像素；在图像边缘使用解码端约定的 127/129
+type vp8Edges struct {
+    top, left [16]uint8
+    corner    uint8
+}
+
+func edgesOf(plane []uint8, stride, size, mbx, mby int) vp8Edges {
+    var edges vp8Edges
+    x0, y0 := mbx*size, mby*size
+    for i := 0; i < size; i++ {
+        if mby == 0 {
+            edges.top[i] = 127
+        } else {
+            edges.top[i] = plane[(y0-1)*stride+x0+i]
+        }
+        if mbx == 0 {
+            edges.left[i] = 129
+        } else {
+            edges.left[i] = plane[(y0+i)*stride+x0-1]
+        }
+    }
+    switch {
+    case mby == 0:
+        edges.corner = 127
+    case mbx == 0:
+        edges.corner = 129
+    default:
+        edges.corner = plane[(y0-1)*stride+x0-1]
+    }
+    return edges
+}
+
+// vp8Predict 生成 size x size 的预测块；DC 模式在图像上边缘和左边缘只使用存在的一侧
+func vp8Predict(dst []uint8, size, mode int, edges *vp8Edges, mbx, mby int) {
+    switch mode {
+    case vp8PredDC:
+        sum, n := 0, 0
+        if mby > 0 {
+            for i := 0; i < size; i++ {
+                sum += int(edges.top[i])
+            }
+            n += size
+        }
+        if mbx > 0 {
+            for i := 0; i < size; i++ {
+                sum += int(edges.left[i])
+            }
+            n += size
+        }
+        avg := uint8(0x80)
+        if n > 0 {
+            avg = uint8((sum + n/2) / n)
+        }
+        for i := range dst[:size*size] {
+            dst[i] = avg
+        }
+    case vp8PredTM:
+        for y := 0; y < size; y++ {
+            for x := 0; x < size; x++ {
+                dst[y*size+x] = clip8(int(edges.left[y]) + int(edges.top[x]) - int(edges.corner))
+            }
+        }
+    case vp8PredVE:
+        for y := 0; y < size; y++ {
+            copy(dst[y*size:y*size+size], edges.top[:size])
+        }
+    case vp8PredHE:
+        for y := 0; y < size; y++ {
+            for x := 0; x < size; x++ {
+                dst[y*size+x] = edges.left[y]
+            }
+        }
+    }
+}
+
+// bestMode 选择与原图差的平方和最小的预测模式；色度的 U、V 共用一个模式
+func bestMode(size int, srcs [][]uint8, strides []int, edges []*vp8Edges, mbx, mby int) int {
+    best, bestCost := vp8PredDC, -1
+    pred := make([]uint8, size*size)
+    for mode := vp8PredDC; mode <= vp8PredHE; mode++ {
+        cost := 0
+        for p, src := range srcs {
+            vp8Predict(pred, size, mode, edges[p], mbx, mby)
+            for y := 0; y < size; y++ {
+                for x := 0; x < size; x++ {
+                    d := int(src[(mby*size+y)*strides[p]+mbx*size+x]) - int(pred[y*size+x])
+                    cost += d * d
+                }
+            }
+        }
+        if bestCost < 0 || cost < bestCost {
+            best, bestCost = mode, cost
+        }
+    }
+    return best
+}
+
+// encodeMacroblock 选择预测模式、变换并量化残差，同时按解码端的方式重建该宏块
+func (e *vp8Encoder) encodeMacroblock(mbx, mby int) vp8Macroblock {
+    var mb vp8Macroblock
+    q := &e.quant
+
+    // 亮度：16x16 预测，各 4x4 块的直流分量再经 WHT 变换后作为 Y2 块传输
+    yEdges := edgesOf(e.recY, e.yStride, 16, mbx, mby)
+    mb.yMode = bestMode(16, [][]uint8{e.srcY}, []int{e.yStride}, []*vp8Edges{&yEdges}, mbx, mby)
+    pred := make([]uint8, 256)
+    vp8Predict(pred, 16, mb.yMode, &yEdges, mbx, mby)
+
+    var coeffs [16][16]int
+    var dcs [16]int
+    for n := 0; n < 16; n++ {
+        x, y := (n%4)*4, (n/4)*4
+        src := e.srcY[(mby*16+y)*e.yStride+mbx*16+x:]
+        forwardDCT(&coeffs[n], src, e.yStride, pred[y*16+x:], 16)
+        dcs[n] = coeffs[n][0]
+    }
+
+    var y2 [16]int
+    forwardWHT(&y2, &dcs)
+    for i, c := range y2 {
+        mb.y2[i] = quantize(c, q.y2[min(i, 1)], true)
+        y2[i] = mb.y2[i] * q.y2[min(i, 1)]
+    }
+    inverseWHT(&dcs, &y2)
+
+    nonzero := false
+    for i := range mb.y2 {
+        nonzero = nonzero || mb.y2[i] != 0
+    }
+    for n := 0; n < 16; n++ {
+        rec := [16]int{dcs[n]}
+        for i := 1; i < 16; i++ {
+            mb.y[n][i] = quantize(coeffs[n][i], q.y1[1], false)
+            rec[i] = mb.y[n][i] * q.y1[1]
+            nonzero = nonzero || mb.y[n][i] != 0
+        }
+        x, y := (n%4)*4, (n/4)*4
+        inverseDCT(&rec, pred[y*16+x:], 16)
+    }
+    for y := 0; y < 16; y++ {
+        copy(e.recY[(mby*16+y)*e.yStride+mbx*16:], pred[y*16:y*16+16])
+    }
+
+    // 色度：U、V 各 8x8，共用一个预测模式
+    uEdges := edgesOf(e.recU, e.cStride, 8, mbx, mby)
+    vEdges := edgesOf(e.recV, e.cStride, 8, mbx, mby)
+    mb.uvMode = bestMode(8, [][]uint8{e.srcU, e.srcV}, []int{e.cStride, e.cStride}, []*vp8Edges{&uEdges, &vEdges}, mbx, mby)
+    for p, plane := range [2]struct {
+        src, rec []uint8
+        edges    *vp8Edges
+    }{{e.srcU, e.recU, &uEdges}, {e.srcV, e.recV, &vEdges}} {
+        cpred := make([]uint8, 64)
+        vp8Predict(cpred, 8, mb.uvMode, plane.edges, mbx, mby)
+        for n := 0; n < 4; n++ {
+            x, y := (n%2)*4, (n/2)*4
+            var c, rec [16]int
+            forwardDCT(&c, plane.src[(mby*8+y)*e.cStride+mbx*8+x:], e.cStride, cpred[y*8+x:], 8)
+            levels := &mb.uv[p*4+n]
+            for i := range c {
+                levels[i] = quantize(c[i], q.uv[min(i, 1)], i == 0)
+                rec[i] = levels[i] * q.uv[min(i, 1)]
+                nonzero = nonzero || levels[i] != 0
+            }
+            inverseDCT(&rec, cpred[y*8+x:], 8)
+        }
+        for y := 0; y < 8; y++ {
+            copy(plane.rec[(mby*8+y)*e.cStride+mbx*8:], cpred[y*8:y*8+8])
+        }
+    }
+
+    mb.skip = !nonzero
+    return mb
+}
+
+// quantize 量化一个系数；交流分量使用更大的死区
+func quantize(c, step int, dc bool) int {
+    bias := step * 3 / 8
+    if dc {
+        bias = step / 2
+    }
+    level := (abs(c) + bias) / step
+    if level > 2048 {
+        level = 2048
+    }
+    if c < 0 {
+        return -level
+    }
+    return level
+}
+
+func abs(v int) int {
+    if v < 0 {
+        return -v
+    }
+    return v
+}
+
+// forwardDCT 计算 4x4 残差（src - pred）的整数 DCT
+func forwardDCT(out *[16]int, src []uint8, srcStride int, pred []uint8, predStride int) {
+    var tmp [16]int
+    for i := 0; i < 4; i++ {
+        d0 := int(src[i*srcStride+0]) - int(pred[i*predStride+0])
+        d1 := int(src[i*srcStride+1]) - int(pred[i*predStride+1])
+        d2 := int(src[i*srcStride+2]) - int(pred[i*predStride+2])
+        d3 := int(src[i*srcStride+3]) - int(pred[i*predStride+3])
+        a0, a1, a2, a3 := d0+d3, d1+d2, d1-d2, d0-d3
+        tmp[0+i*4] = (a0 + a1) * 8
+        tmp[1+i*4] = (a2*2217 + a3*5352 + 1812) >> 9
+        tmp[2+i*4] = (a0 - a1) * 8
+        tmp[3+i*4] = (a3*2217 - a2*5352 + 937) >> 9
+    }
+    for i := 0; i < 4; i++ {
+        a0, a1 := tmp[0+i]+tmp[12+i], tmp[4+i]+tmp[8+i]
+        a2, a3 := tmp[4+i]-tmp[8+i], tmp[0+i]-tmp[12+i]
+        out[0+i] = (a0 + a1 + 7) >> 4
+        out[4+i] = (a2*2217 + a3*5352 + 12000) >> 16
+        if a3 != 0 {
+            out[4+i]++
+        }
+        out[8+i] = (a0 - a1 + 7) >> 4
+        out[12+i] = (a3*2217 - a2*5352 + 51000) >> 16
+    }
+}
+
+// forwardWHT 对 16 个 4x4 块的直流分量做 Walsh-Hadamard 变换
+func forwardWHT(out, in *[16]int) {
+    var tmp [16]int
+    for i := 0; i < 4; i++ {
+        a0, a1 := in[i*4+0]+in[i*4+2], in[i*4+1]+in[i*4+3]
+        a2, a3 := in[i*4+1]-in[i*4+3], in[i*4+0]-in[i*4+2]
+        tmp[0+i*4] = a0 + a1
+        tmp[1+i*4] = a3 + a2
+        tmp[2+i*4] = a3 - a2
+        tmp[3+i*4] = a0 - a1
+    }
+    for i := 0; i < 4; i++ {
+        a0, a1 := tmp[0+i]+tmp[8+i], tmp[4+i]+tmp[12+i]
+        a2, a3 := tmp[4+i]-tmp[12+i], tmp[0+i]-tmp[8+i]
+        out[0+i] = (a0 + a1) >> 1
+        out[4+i] = (a3 + a2) >> 1
+        out[8+i] = (a3 - a2) >> 1
+        out[12+i] = (a0 - a1) >> 1
+    }
+}
+
+// inverseWHT 与解码端的 inverseWHT16 一致，输出各 4x4 块的直流分量
+func inverseWHT(out, in *[16]int) {
+    var m [16]int32
+    for i := 0; i < 4; i++ {
+        a0 := int32(in[0+i]) + int32(in[12+i])
+        a1 := int32(in[4+i]) + int32(in[8+i])
+        a2 := int32(in[4+i]) - int32(in[8+i])
+        a3 := int32(in[0+i]) - int32(in[12+i])
+        m[0+i] = a0 + a1
+        m[8+i] = a0 - a1
+        m[4+i] = a3 + a2
+        m[12+i] = a3 - a2
+    }
+    for i := 0; i < 4; i++ {
+        dc := m[0+i*4] + 3
+        a0 := dc + m[3+i*4]
+        a1 := m[1+i*4] + m[2+i*4]
+        a2 := m[1+i*4] - m[2+i*4]
+        a3 := dc - m[3+i*4]
+        out[i*4+0] = int(int16((a0 + a1) >> 3))
+        out[i*4+1] = int(int16((a3 + a2) >> 3))
+        out[i*4+2] = int(int16((a0 - a1) >> 3))
+        out[i*4+3] = int(int16((a3 - a2) >> 3))
+    }
+}
+
+// inverseDCT 与解码端的 inverseDCT4 一致，将残差叠加到 dst 的 4x4 区域
+func inverseDCT(in *[16]int, dst []uint8, stride int) {
+    const (
+        c1 = 85627 // 65536 * cos(pi/8) * sqrt(2)
+        c2 = 35468 // 65536 * sin(pi/8) * sqrt(2)
+    )
+    var m [4][4]int32
+    for i := 0; i < 4; i++ {
+        a := int32(int16(in[i+0])) + int32(int16(in[i+8]))
+        b := int32(int16(in[i+0])) - int32(int16(in[i+8]))
+        c := (int32(int16(in[i+4]))*c2)>>16 - (int32(int16(in[i+12]))*c1)>>16
+        d := (int32(int16(in[i+4]))*c1)>>16 + (int32(int16(in[i+12]))*c2)>>16
+        m[i][0] = a + d
+        m[i][1] = b + c
+        m[i][2] = b - c
+        m[i][3] = a - d
+    }
+    for j := 0; j < 4; j++ {
+        dc := m[0][j] + 4
+        a := dc + m[2][j]
+        b := dc - m[2][j]
+        c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+        d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+        row := dst[j*stride:]
+        row[0] = clip8(int(row[0]) + int((a+d)>>3))
+        row[1] = clip8(int(row[1]) + int((b+c)>>3))
+        row[2] = clip8(int(row[2]) + int((b-c)>>3))
+        row[3] = clip8(int(row[3]) + int((a-d)>>3))
+    }
+}
+
+// vp8TokenCoder 按解码端的 token 树写入系数；enc 为 nil 时只统计各节点的比特，用于计算概率
+type vp8TokenCoder struct {
+    enc   *boolEncoder
+    probs *[vp8NumPlanes][vp8NumBands][vp8NumContexts][vp8NumProbs]uint8
+    stats *[vp8NumPlanes][vp8NumBands][vp8NumContexts][vp8NumProbs][2]int
+}
+
+func (c *vp8TokenCoder) node(plane, band, ctx, i int, bit bool) {
+    if c.enc == nil {
+        if bit {
+            c.stats[plane][band][ctx][i][1]++
+        } else {
+            c.stats[plane][band][ctx][i][0]++
+        }
+        return
+    }
+    c.enc.putBit(c.probs[plane][band][ctx][i], bit)
+}
+
+// fixed 写入概率固定的比特（额外比特和符号位），不参与统计
+func (c *vp8TokenCoder) fixed(prob uint8, bit bool) {
+    if c.enc != nil {
+        c.enc.putBit(prob, bit)
+    }
+}
+
+// block 写入一个 4x4 块的系数，first 为第一个传输的系数；返回块中是否有非零系数（0/1），作为相邻块的上下文
+func (c *vp8TokenCoder) block(plane, ctx int, levels *[16]int, first int) int {
+    last := -1
+    for n := 15; n >= first; n-- {
+        if levels[vp8Zigzag[n]] != 0 {
+            last = n
+            break
+        }
+    }
+
+    checkEOB := true
+    for n := first; n < 16; n++ {
+        band := vp8Bands[n]
+        if checkEOB {
+            c.node(plane, band, ctx, 0, n <= last)
+            if n > last {
+                break
+            }
+        }
+        v := levels[vp8Zigzag[n]]
+        if v == 0 {
+            // 零之后不检查块结束
+            c.node(plane, band, ctx, 1, false)
+            ctx, checkEOB = 0, false
+            continue
+        }
+        c.node(plane, band, ctx, 1, true)
+        c.value(plane, band, ctx, abs(v))
+        c.fixed(128, v < 0)
+        ctx, checkEOB = min(abs(v), 2), true
+    }
+    if last < 0 {
+        return 0
+    }
+    return 1
+}
+
+// value 写入非零系数的绝对值
+func (c *vp8TokenCoder) value(plane, band, ctx, v int) {
+    if v == 1 {
+        c.node(plane, band, ctx, 2, false)
+        return
+    }
+    c.node(plane, band, ctx, 2, true)
+    switch {
+    case v <= 4:
+        c.node(plane, band, ctx, 3, false)
+        if v == 2 {
+            c.node(plane, band, ctx, 4, false)
+        } else {
+            c.node(plane, band, ctx, 4, true)
+            c.node(plane, band, ctx, 5, v == 4)
+        }
+    case v <= 10:
+        c.node(plane, band, ctx, 3, true)
+        c.node(plane, band, ctx, 6, false)
+        if v <= 6 {
+            c.node(plane, band, ctx, 7, false)
+            c.fixed(159, v == 6)
+        } else {
+            c.node(plane, band, ctx, 7, true)
+            c.fixed(165, (v-7)&2 != 0)
+            c.fixed(145, (v-7)&1 != 0)
+        }
+    default:
+        c.node(plane, band, ctx, 3, true)
+        c.node(plane, band, ctx, 6, true)
+        cat := 3
+        switch {
+        case v < 19:
+            cat = 0
+        case v < 35:
+            cat = 1
+        case v < 67:
+            cat = 2
+        }
+        c.node(plane, band, ctx, 8, cat >= 2)
+        c.node(plane, band, ctx, 9+cat>>1, cat&1 == 1)
+        extra := v - (3 + 8<<cat)
+        tab := vp8Cat3456[cat]
+        for i, prob := range tab {
+            c.fixed(prob, extra>>(len(tab)-1-i)&1 == 1)
+        }
+    }
+}
+
+// tokens 按宏块顺序写入全部系数，非零上下文的维护方式与解码端的 parseResiduals 一致
+func (e *vp8Encoder) tokens(c *vp8TokenCoder) {
+    upY2 := make([]int, e.mbw)
+    upY := make([][4]int, e.mbw)
+    upC := make([][4]int, e.mbw)
+    for mby := 0; mby < e.mbh; mby++ {
+        var leftY2 int
+        var leftY, leftC [4]int
+        for mbx := 0; mbx < e.mbw; mbx++ {
+            mb := &e.mbs[mby*e.mbw+mbx]
+            if mb.skip {
+                leftY2, upY2[mbx] = 0, 0
+                leftY, upY[mbx] = [4]int{}, [4]int{}
+                leftC, upC[mbx] = [4]int{}, [4]int{}
+                continue
+            }
+
+            nz := c.block(vp8PlaneY2, leftY2+upY2[mbx], &mb.y2, 0)
+            leftY2, upY2[mbx] = nz, nz
+            for y := 0; y < 4; y++ {
+                nz := leftY[y]
+                for x := 0; x < 4; x++ {
+                    nz = c.block(vp8PlaneYAfterY2, nz+upY[mbx][x], &mb.y[y*4+x], 1)
+                    upY[mbx][x] = nz
+                }
+                leftY[y] = nz
+            }
+            for p := 0; p < 4; p += 2 {
+                for y := 0; y < 2; y++ {
+                    nz := leftC[y+p]
+                    for x := 0; x < 2; x++ {
+                        nz = c.block(vp8PlaneUV, nz+upC[mbx][x+p], &mb.uv[p*2+y*2+x], 0)
+                        upC[mbx][x+p] = nz
+                    }
+                    leftC[y+p] = nz
+                }
+            }
+        }
+    }
+}
+
+// frame 生成 VP8 关键帧：帧头、第一分区（帧参数和宏块模式）和系数分区
+func (e *vp8Encoder) frame(width, height int) []byte {
+    // 先统计各节点的比特，更新能节省码流的概率
+    var stats [vp8NumPlanes][vp8NumBands][vp8NumContexts][vp8NumProbs][2]int
+    e.tokens(&vp8TokenCoder{stats: &stats})
+    probs := vp8DefaultCoeffProbs
+    var update [vp8NumPlanes][vp8NumBands][vp8NumContexts][vp8NumProbs]bool
+    for p := range probs {
+        for b := range probs[p] {
+            for c := range probs[p][b] {
+                for i := range probs[p][b][c] {
+                    n0, n1 := stats[p][b][c][i][0], stats[p][b][c][i][1]
+                    if n0+n1 == 0 {
+                        continue
+                    }
+                    old, upd := probs[p][b][c][i], vp8CoeffUpdateProbs[p][b][c][i]
+                    prob := clampProb((255*n0 + (n0+n1)/2) / (n0 + n1))
+                    keep := bitsCost(old, n0, n1) + bitsCost(upd, 1, 0)
+                    change := bitsCost(prob, n0, n1) + bitsCost(upd, 0, 1) + 8
+                    if change < keep {
+                        probs[p][b][c][i], update[p][b][c][i] = prob, true
+                    }
+                }
+            }
+        }
+    }
+
+    skipped := 0
+    for _, mb := range e.mbs {
+        if mb.skip {
+            skipped++
+        }
+    }
+
+    fp := newBoolEncoder()
+    fp.putLiteral(0, 1) // 色彩空间
+    fp.putLiteral(0, 1) // 需要截断
+    fp.putLiteral(0, 1) // 不分段
+    fp.putLiteral(0, 1) // 普通环路滤波
+    fp.putLiteral(0, 6) // 滤波强度 0，即不滤波
+    fp.putLiteral(0, 3) // 锐度
+    fp.putLiteral(0, 1) // 不按参考帧和模式调整滤波
+    fp.putLiteral(0, 2) // 一个系数分区
+    fp.putLiteral(uint32(e.quant.index), 7)
+    for i := 0; i < 5; i++ {
+        fp.putLiteral(0, 1) // 各类系数不使用量化偏移
+    }
+    fp.putLiteral(0, 1) // 概率只用于本帧
+    for p := range probs {
+        for b := range probs[p] {
+            for c := range probs[p][b] {
+                for i := range probs[p][b][c] {
+                    fp.putBit(vp8CoeffUpdateProbs[p][b][c][i], update[p][b][c][i])
+                    if update[p][b][c][i] {
+                        fp.putLiteral(uint32(probs[p][b][c][i]), 8)
+                    }
+                }
+            }
+        }
+    }
+
+    skipProb := uint8(0)
+    if skipped > 0 {
+        skipProb = clampProb((255*(len(e.mbs)-skipped) + len(e.mbs)/2) / len(e.mbs))
+        fp.putLiteral(1, 1)
+        fp.putLiteral(uint32(skipProb), 8)
+    } else {
+        fp.putLiteral(0, 1)
+    }
+
+    for _, mb := range e.mbs {
+        if skipped > 0 {
+            fp.putBit(skipProb, mb.skip)
+        }
+        fp.putBit(145, true) // 16x16 亮度预测
+        switch mb.yMode {
+        case vp8PredDC:
+            fp.putBit(156, false)
+            fp.putBit(163, false)
+        case vp8PredVE:
+            fp.putBit(156, false)
+            fp.putBit(163, true)
+        case vp8PredHE:
+            fp.putBit(156, true)
+            fp.putBit(128, false)
+        case vp8PredTM:
+            fp.putBit(156, true)
+            fp.putBit(128, true)
+        }
+        fp.putBit(142, mb.uvMode != vp8PredDC)
+        if mb.uvMode != vp8PredDC {
+            fp.putBit(114, mb.uvMode != vp8PredVE)
+            if mb.uvMode != vp8PredVE {
+                fp.putBit(183, mb.uvMode == vp8PredTM)
+            }
+        }
+    }
+    first := fp.bytes()
+
+    tp := newBoolEncoder()
+    e.tokens(&vp8TokenCoder{enc: tp, probs: &probs})
+    tokens := tp.bytes()
+
+    // 帧标记：关键帧、版本 0、显示、第一分区长度；之后是起始码和尺寸（不缩放）
+    tag := uint32(1)<<4 | uint32(len(first))<<5
+    data := make([]byte, 0, 10+len(first)+len(tokens))
+    data = append(data, byte(tag), byte(tag>>8), byte(tag>>16))
+    data = append(data, 0x9d, 0x01, 0x2a)
+    data = append(data, byte(width), byte(width>>8), byte(height), byte(height>>8))
+    data = append(data, first...)
+    return append(data, tokens...)
+}
+
+func clampProb(p int) uint8 {
+    return uint8(max(1, min(255, p)))
+}
+
+// bitsCost 估算按给定概率编码 n0 个 0 和 n1 个 1 需要的比特数
+func bitsCost(prob uint8, n0, n1 int) float64 {
+    p := float64(prob) / 256
+    return -float64(n0)*math.Log2(p) - float64(n1)*math.Log2(1-p)
+}
+
+// boolEncoder VP8 的布尔算术编码器（RFC 6386 第 7 节）
+type boolEncoder struct {
+    buf      []byte
+    rng      uint32
+    bottom   uint32
+    bitCount int
+}
+
+func newBoolEncoder() *boolEncoder {
+    return &boolEncoder{rng: 255, bitCount: 24}
+}
+
+// putBit 写入一个比特，prob 为该比特为 0 的概率（/256）
+func (e *boolEncoder) putBit(prob uint8, bit bool) {
+    split := 1 + ((e.rng-1)*uint32(prob))>>8
+    if bit {
+        e.bottom += split
+        e.rng -= split
+    } else {
+        e.rng = split
+    }
+    for e.rng < 128 {
+        e.rng <<= 1
+        if e.bottom&(1<<31) != 0 {
+            e.carry()
+        }
+        e.bottom <<= 1
+        e.bitCount--
+        if e.bitCount == 0 {
+            e.buf = append(e.buf, byte(e.bottom>>24))
+            e.bottom &= 1<<24 - 1
+            e.bitCount = 8
+        }
+    }
+}
+
+// carry 将进位传递到已输出的字节
+func (e *boolEncoder) carry() {
+    i := len(e.buf) - 1
+    for i >= 0 && e.buf[i] == 0xff {
+        e.buf[i] = 0
+        i--
+    }
+    if i >= 0 {
+        e.buf[i]++
+    }
+}
+
+// putLiteral 以均匀概率从高位开始写入 n 比特
+func (e *boolEncoder) putLiteral(v uint32, n int) {
+    for i := n - 1; i >= 0; i-- {
+        e.putBit(128, v>>i&1 == 1)
+    }
+}
+
+// bytes 写入足够的填充比特，使缓冲的数据全部输出
+func (e *boolEncoder) bytes() []byte {
+    for i := 0; i < 32; i++ {
+        e.putBit(128, false)
+    }
+    return e.buf
+}
+
+// vp8CoeffUpdateProbs 更新各系数概率的标志位使用的概率（RFC 6386 13.4 节）
+var vp8CoeffUpdateProbs = [vp8NumPlanes][vp8NumBands][vp8NumContexts][vp8NumProbs]uint8{
+    {
+        {
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {176, 246, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {223, 241, 252, 255, 255, 255, 255, 255, 255, 255, 255},
+            {249, 253, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 244, 252, 255, 255, 255, 255, 255, 255, 255, 255},
+            {234, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {253, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 246, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {239, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {254, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 248, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {251, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {251, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {254, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 254, 253, 255, 254, 255, 255, 255, 255, 255, 255},
+            {250, 255, 254, 255, 254, 255, 255, 255, 255, 255, 255},
+            {254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+    },
+    {
+        {
+            {217, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {225, 252, 241, 253, 255, 255, 254, 255, 255, 255, 255},
+            {234, 250, 241, 250, 253, 255, 253, 254, 255, 255, 255},
+        },
+        {
+            {255, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {223, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {238, 253, 254, 254, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 248, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {249, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 253, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {247, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {252, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {253, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 254, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+            {250, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+    },
+    {
+        {
+            {186, 251, 250, 255, 255, 255, 255, 255, 255, 255, 255},
+            {234, 251, 244, 254, 255, 255, 255, 255, 255, 255, 255},
+            {251, 251, 243, 253, 254, 255, 254, 255, 255, 255, 255},
+        },
+        {
+            {255, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {236, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {251, 253, 253, 254, 254, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {254, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {254, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+    },
+    {
+        {
+            {248, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {250, 254, 252, 254, 255, 255, 255, 255, 255, 255, 255},
+            {248, 254, 249, 253, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 253, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+            {246, 253, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+            {252, 254, 251, 254, 254, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 254, 252, 255, 255, 255, 255, 255, 255, 255, 255},
+            {248, 254, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+            {253, 255, 254, 254, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 251, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {245, 251, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {253, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 251, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+            {252, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 252, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {249, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 255, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+            {250, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+        {
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+            {255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+        },
+    },
+}
+
+// vp8DefaultCoeffProbs 系数的默认概率（RFC 6386 13.5 节）
+var vp8DefaultCoeffProbs = [vp8NumPlanes][vp8NumBands][vp8NumContexts][vp8NumProbs]uint8{
+    {
+        {
+            {128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+            {128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+            {128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+        },
+        {
+            {253, 136, 254, 255, 228, 219, 128, 128, 128, 128, 128},
+            {189, 129, 242, 255, 227, 213, 255, 219, 128, 128, 128},
+            {106, 126, 227, 252, 214, 209, 255, 255, 128, 128, 128},
+        },
+        {
+            {1, 98, 248, 255, 236, 226, 255, 255, 128, 128, 128},
+            {181, 133, 238, 254, 221, 234, 255, 154, 128, 128, 128},
+            {78, 134, 202, 247, 198, 180, 255, 219, 128, 128, 128},
+        },
+        {
+            {1, 185, 249, 255, 243, 255, 128, 128, 128, 128, 128},
+            {184, 150, 247, 255, 236, 224, 128, 128, 128, 128, 128},
+            {77, 110, 216, 255, 236, 230, 128, 128, 128, 128, 128},
+        },
+        {
+            {1, 101, 251, 255, 241, 255, 128, 128, 128, 128, 128},
+            {170, 139, 241, 252, 236, 209, 255, 255, 128, 128, 128},
+            {37, 116, 196, 243, 228, 255, 255, 255, 128, 128, 128},
+        },
+        {
+            {1, 204, 254, 255, 245, 255, 128, 128, 128, 128, 128},
+            {207, 160, 250, 255, 238, 128, 128, 128, 128, 128, 128},
+            {102, 103, 231, 255, 211, 171, 128, 128, 128, 128, 128},
+        },
+        {
+            {1, 152, 252, 255, 240, 255, 128, 128, 128, 128, 128},
+            {177, 135, 243, 255, 234, 225, 128, 128, 128, 128, 128},
+            {80, 129, 211, 255, 194, 224, 128, 128, 128, 128, 128},
+        },
+        {
+            {1, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+            {246, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+            {255, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+        },
+    },
+    {
+        {
+            {198, 35, 237, 223, 193, 187, 162, 160, 145, 155, 62},
+            {131, 45, 198, 221, 172, 176, 220, 157, 252, 221, 1},
+            {68, 47, 146, 208, 149, 167, 221, 162, 255, 223, 128},
+        },
+        {
+            {1, 149, 241, 255, 221, 224, 255, 255, 128, 128, 128},
+            {184, 141, 234, 253, 222, 220, 255, 199, 128, 128, 128},
+            {81, 99, 181, 242, 176, 190, 249, 202, 255, 255, 128},
+        },
+        {
+            {1, 129, 232, 253, 214, 197, 242, 196, 255, 255, 128},
+            {99, 121, 210, 250, 201, 198, 255, 202, 128, 128, 128},
+            {23, 91, 163, 242, 170, 187, 247, 210, 255, 255, 128},
+        },
+        {
+            {1, 200, 246, 255, 234, 255, 128, 128, 128, 128, 128},
+            {109, 178, 241, 255, 231, 245, 255, 255, 128, 128, 128},
+            {44, 130, 201, 253, 205, 192, 255, 255, 128, 128, 128},
+        },
+        {
+            {1, 132, 239, 251, 219, 209, 255, 165, 128, 128, 128},
+            {94, 136, 225, 251, 218, 190, 255, 255, 128, 128, 128},
+            {22, 100, 174, 245, 186, 161, 255, 199, 128, 128, 128},
+        },
+        {
+            {1, 182, 249, 255, 232, 235, 128, 128, 128, 128, 128},
+            {124, 143, 241, 255, 227, 234, 128, 128, 128, 128, 128},
+            {35, 77, 181, 251, 193, 211, 255, 205, 128, 128, 128},
+        },
+        {
+            {1, 157, 247, 255, 236, 231, 255, 255, 128, 128, 128},
+            {121, 141, 235, 255, 225, 227, 255, 255, 128, 128, 128},
+            {45, 99, 188, 251, 195, 217, 255, 224, 128, 128, 128},
+        },
+        {
+            {1, 1, 251, 255, 213, 255, 128, 128, 128, 128, 128},
+            {203, 1, 248, 255, 255, 128, 128, 128, 128, 128, 128},
+            {137, 1, 177, 255, 224, 255, 128, 128, 128, 128, 128},
+        },
+    },
+    {
+        {
+            {253, 9, 248, 251, 207, 208, 255, 192, 128, 128, 128},
+            {175, 13, 224, 243, 193, 185, 249, 198, 255, 255, 128},
+            {73, 17, 171, 221, 161, 179, 236, 167, 255, 234, 128},
+        },
+        {
+            {1, 95, 247, 253, 212, 183, 255, 255, 128, 128, 128},
+            {239, 90, 244, 250, 211, 209, 255, 255, 128, 128, 128},
+            {155, 77, 195, 248, 188, 195, 255, 255, 128, 128, 128},
+        },
+        {
+            {1, 24, 239, 251, 218, 219, 255, 205, 128, 128, 128},
+            {201, 51, 219, 255, 196, 186, 128, 128, 128, 128, 128},
+            {69, 46, 190, 239, 201, 218, 255, 228, 128, 128, 128},
+        },
+        {
+            {1, 191, 251, 255, 255, 128, 128, 128, 128, 128, 128},
+            {223, 165, 249, 255, 213, 255, 128, 128, 128, 128, 128},
+            {141, 124, 248, 255, 255, 128, 128, 128, 128, 128, 128},
+        },
+        {
+            {1, 16, 248, 255, 255, 128, 128, 128, 128, 128, 128},
+            {190, 36, 230, 255, 236, 255, 128, 128, 128, 128, 128},
+            {149, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+        },
+        {
+            {1, 226, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+            {247, 192, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+            {240, 128, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+        },
+        {
+            {1, 134, 252, 255, 255, 128, 128, 128, 128, 128, 128},
+            {213, 62, 250, 255, 255, 128, 128, 128, 128, 128, 128},
+            {55, 93, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+        },
+        {
+            {128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+            {128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+            {128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+        },
+    },
+    {
+        {
+            {202, 24, 213, 235, 186, 191, 220, 160, 240, 175, 255},
+            {126, 38, 182, 232, 169, 184, 228, 174, 255, 187, 128},
+            {61, 46, 138, 219, 151, 178, 240, 170, 255, 216, 128},
+        },
+        {
+            {1, 112, 230, 250, 199, 191, 247, 159, 255, 255, 128},
+            {166, 109, 228, 252, 211, 215, 255, 174, 128, 128, 128},
+            {39, 77, 162, 232, 172, 180, 245, 178, 255, 255, 128},
+        },
+        {
+            {1, 52, 220, 246, 198, 199, 249, 220, 255, 255, 128},
+            {124, 74, 191, 243, 183, 193, 250, 221, 255, 255, 128},
+            {24, 71, 130, 219, 154, 170, 243, 182, 255, 255, 128},
+        },
+        {
+            {1, 182, 225, 249, 219, 240, 255, 224, 128, 128, 128},
+            {149, 150, 226, 252, 216, 205, 255, 171, 128, 128, 128},
+            {28, 108, 170, 242, 183, 194, 254, 223, 255, 255, 128},
+        },
+        {
+            {1, 81, 230, 252, 204, 203, 255, 192, 128, 128, 128},
+            {123, 102, 209, 247, 188, 196, 255, 233, 128, 128, 128},
+            {20, 95, 153, 243, 164, 173, 255, 203, 128, 128, 128},
+        },
+        {
+            {1, 222, 248, 255, 216, 213, 128, 128, 128, 128, 128},
+            {168, 175, 246, 252, 235, 205, 255, 255, 128, 128, 128},
+            {47, 116, 215, 255, 211, 212, 255, 255, 128, 128, 128},
+        },
+        {
+            {1, 121, 236, 253, 212, 214, 255, 255, 128, 128, 128},
+            {141, 84, 213, 252, 201, 202, 255, 219, 128, 128, 128},
+            {42, 80, 160, 240, 162, 185, 255, 205, 128, 128, 128},
+        },
+        {
+            {1, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+            {244, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+            {238, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+        },
+    },
+}
+
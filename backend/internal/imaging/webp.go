@@ -0,0 +1,733 @@
+// internal/imaging/webp.go
+// 无损 WebP（VP8L）编码器，含透明度的图片使用。标准库和已引入的依赖中都没有 WebP 编码器，这里实现规范中的一个子集：
+// subtract-green 和预测变换、颜色缓存、基于哈希链的 LZ77 反向引用，整幅图使用一组前缀码
+package imaging
+
+import (
+    "encoding/binary"
+    "errors"
+    "image"
+    "io"
+    "sort"
+)
+
+const (
+    vp8lSignature              = 0x2f
+    vp8lMaxDimension           = 1 << 14
+    vp8lMaxCodeLength          = 15
+    vp8lMaxCodeLenCode         = 7
+    vp8lTransformPredictor     = 0
+    vp8lTransformSubtractGreen = 2
+    vp8lPredictorBits          = 4 // 预测模式按 16x16 分块选择
+    vp8lCacheBits              = 10
+    vp8lLiteralAlphabet        = 256
+    vp8lLengthAlphabet         = 24
+    vp8lDistAlphabet           = 40
+    vp8lCodeLengthCodes        = 19
+    vp8lDistanceMapCodes       = 120 // 距离码 1-120 表示邻近的二维偏移
+    vp8lMinMatch               = 3
+    vp8lMaxMatch               = 4096
+    vp8lMaxDistance            = 1<<20 - vp8lDistanceMapCodes
+    vp8lHashBits               = 16
+    vp8lMaxChain               = 32 // 每个位置最多比较的候选数
+    vp8lColorCacheMultiplier   = 0x1e35a7bd
+)
+
+// 码长码的传输顺序
+var vp8lCodeLengthOrder = [vp8lCodeLengthCodes]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// vp8lDistanceMap 距离码 1-120 对应的偏移，高 4 位为行偏移，低 4 位为 8 减列偏移
+var vp8lDistanceMap = [vp8lDistanceMapCodes]uint8{
+    0x18, 0x07, 0x17, 0x19, 0x28, 0x06, 0x27, 0x29, 0x16, 0x1a,
+    0x26, 0x2a, 0x38, 0x05, 0x37, 0x39, 0x15, 0x1b, 0x36, 0x3a,
+    0x25, 0x2b, 0x48, 0x04, 0x47, 0x49, 0x14, 0x1c, 0x35, 0x3b,
+    0x46, 0x4a, 0x24, 0x2c, 0x58, 0x45, 0x4b, 0x34, 0x3c, 0x03,
+    0x57, 0x59, 0x13, 0x1d, 0x56, 0x5a, 0x23, 0x2d, 0x44, 0x4c,
+    0x55, 0x5b, 0x33, 0x3d, 0x68, 0x02, 0x67, 0x69, 0x12, 0x1e,
+    0x66, 0x6a, 0x22, 0x2e, 0x54, 0x5c, 0x43, 0x4d, 0x65, 0x6b,
+    0x32, 0x3e, 0x78, 0x01, 0x77, 0x79, 0x53, 0x5d, 0x11, 0x1f,
+    0x64, 0x6c, 0x42, 0x4e, 0x76, 0x7a, 0x21, 0x2f, 0x75, 0x7b,
+    0x31, 0x3f, 0x63, 0x6d, 0x52, 0x5e, 0x00, 0x74, 0x7c, 0x41,
+    0x4f, 0x10, 0x20, 0x62, 0x6e, 0x30, 0x73, 0x7d, 0x51, 0x5f,
+    0x40, 0x72, 0x7e, 0x61, 0x6f, 0x50, 0x71, 0x7f, 0x60, 0x70,
+}
+
+// 预测变换可选的模式：L、T、Average2(L, T)、Select、ClampAddSubtractFull
+var vp8lPredictorModes = [...]int{1, 2, 7, 11, 12}
+
+// bitWriter 按 VP8L 要求从低位开始写入比特
+type bitWriter struct {
+    buf   []byte
+    acc   uint64
+    nbits uint
+}
+
+func (w *bitWriter) write(bits uint32, n uint) {
+    w.acc |= uint64(bits) << w.nbits
+    w.nbits += n
+    for w.nbits >= 8 {
+        w.buf = append(w.buf, byte(w.acc))
+        w.acc >>= 8
+        w.nbits -= 8
+    }
+}
+
+func (w *bitWriter) bytes() []byte {
+    if w.nbits > 0 {
+        w.buf = append(w.buf, byte(w.acc))
+        w.acc, w.nbits = 0, 0
+    }
+    return w.buf
+}
+
+// prefixCode 一个规范前缀码，codes 已按写入顺序反转
+type prefixCode struct {
+    lengths []uint8
+    codes   []uint32
+    single  bool // 只有一个符号时不占用比特
+}
+
+func newPrefixCode(lengths []uint8) prefixCode {
+    used := 0
+    for _, l := range lengths {
+        if l > 0 {
+            used++
+        }
+    }
+    return prefixCode{lengths: lengths, codes: canonicalCodes(lengths), single: used <= 1}
+}
+
+func (p *prefixCode) put(w *bitWriter, symbol int) {
+    if p.single {
+        return
+    }
+    w.write(p.codes[symbol], uint(p.lengths[symbol]))
+}
+
+// EncodeLosslessWebP 将图像编码为无损 WebP，保留透明度
+func EncodeLosslessWebP(out io.Writer, img image.Image) error {
+    b := img.Bounds()
+    width, height := b.Dx(), b.Dy()
+    if width <= 0 || height <= 0 || width > vp8lMaxDimension || height > vp8lMaxDimension {
+        return errors.New("webp: invalid image dimensions")
+    }
+
+    // 取非预乘的 ARGB，并应用 subtract-green 变换
+    argb := make([]uint32, 0, width*height)
+    alphaUsed := false
+    for y := b.Min.Y; y < b.Max.Y; y++ {
+        for x := b.Min.X; x < b.Max.X; x++ {
+            r, g, bl, a := nrgbaAt(img, x, y)
+            if a != 0xff {
+                alphaUsed = true
+            }
+            argb = append(argb, uint32(a)<<24|uint32(r-g)<<16|uint32(g)<<8|uint32(bl-g))
+        }
+    }
+
+    w := &bitWriter{}
+    w.write(vp8lSignature, 8)
+    w.write(uint32(width-1), 14)
+    w.write(uint32(height-1), 14)
+    if alphaUsed {
+        w.write(1, 1)
+    } else {
+        w.write(0, 1)
+    }
+    w.write(0, 3) // 版本号
+
+    // 解码时按相反顺序撤销变换：先撤销预测，再加回绿色
+    w.write(1, 1)
+    w.write(vp8lTransformSubtractGreen, 2)
+
+    modes, residuals := predict(argb, width, height)
+    w.write(1, 1)
+    w.write(vp8lTransformPredictor, 2)
+    w.write(vp8lPredictorBits-2, 3)
+    writeImageData(w, modes, subSampleSize(width, vp8lPredictorBits), 0, false)
+
+    w.write(0, 1) // 没有更多变换
+
+    writeImageData(w, residuals, width, vp8lCacheBits, true)
+
+    return writeRIFF(out, "VP8L", w.bytes())
+}
+
+// subSampleSize 按 2^bits 分块后的块数
+func subSampleSize(size, bits int) int {
+    return (size + 1<<bits - 1) >> bits
+}
+
+// predict 为每个分块选择残差最小的预测模式，返回模式子图像和残差图像
+func predict(argb []uint32, width, height int) ([]uint32, []uint32) {
+    tilesX, tilesY := subSampleSize(width, vp8lPredictorBits), subSampleSize(height, vp8lPredictorBits)
+    modes := make([]uint32, tilesX*tilesY)
+    residuals := make([]uint32, len(argb))
+
+    for ty := 0; ty < tilesY; ty++ {
+        for tx := 0; tx < tilesX; tx++ {
+            x0, y0 := tx<<vp8lPredictorBits, ty<<vp8lPredictorBits
+            x1, y1 := min(x0+1<<vp8lPredictorBits, width), min(y0+1<<vp8lPredictorBits, height)
+
+            best, bestCost := vp8lPredictorModes[0], -1
+            for _, mode := range vp8lPredictorModes {
+                cost := 0
+                for y := y0; y < y1; y++ {
+                    for x := x0; x < x1; x++ {
+                        cost += residualCost(subPixels(argb[y*width+x], predictPixel(argb, width, x, y, mode)))
+                    }
+                }
+                if bestCost < 0 || cost < bestCost {
+                    best, bestCost = mode, cost
+                }
+            }
+            modes[ty*tilesX+tx] = 0xff000000 | uint32(best)<<8
+
+            for y := y0; y < y1; y++ {
+                for x := x0; x < x1; x++ {
+                    residuals[y*width+x] = subPixels(argb[y*width+x], predictPixel(argb, width, x, y, best))
+                }
+            }
+        }
+    }
+    return modes, residuals
+}
+
+// predictPixel 计算 (x, y) 处的预测值；第一行、第一列和左上角像素的预测方式与分块模式无关
+func predictPixel(argb []uint32, width, x, y, mode int) uint32 {
+    i := y*width + x
+    switch {
+    case x == 0 && y == 0:
+        return 0xff000000
+    case y == 0:
+        return argb[i-1]
+    case x == 0:
+        return argb[i-width]
+    }
+
+    l, t, tl := argb[i-1], argb[i-width], argb[i-width-1]
+    switch mode {
+    case 1:
+        return l
+    case 2:
+        return t
+    case 7:
+        return average2(l, t)
+    case 11:
+        // 与 T-TL 差异更小时选 L，否则选 T
+        if channelDistance(tl, t) < channelDistance(tl, l) {
+            return l
+        }
+        return t
+    default:
+        return clampAddSubtractFull(l, t, tl)
+    }
+}
+
+func average2(a, b uint32) uint32 {
+    var out uint32
+    for shift := 0; shift < 32; shift += 8 {
+        out |= ((a>>shift&0xff + b>>shift&0xff) / 2) << shift
+    }
+    return out
+}
+
+func channelDistance(a, b uint32) int {
+    d := 0
+    for shift := 0; shift < 32; shift += 8 {
+        v := int(a>>shift&0xff) - int(b>>shift&0xff)
+        if v < 0 {
+            v = -v
+        }
+        d += v
+    }
+    return d
+}
+
+func clampAddSubtractFull(a, b, c uint32) uint32 {
+    var out uint32
+    for shift := 0; shift < 32; shift += 8 {
+        v := int(a>>shift&0xff) + int(b>>shift&0xff) - int(c>>shift&0xff)
+        out |= uint32(max(0, min(255, v))) << shift
+    }
+    return out
+}
+
+// subPixels 按通道相减（模 256）
+func subPixels(a, b uint32) uint32 {
+    var out uint32
+    for shift := 0; shift < 32; shift += 8 {
+        out |= ((a>>shift - b>>shift) & 0xff) << shift
+    }
+    return out
+}
+
+// residualCost 残差各通道按有符号值取绝对值求和，用于比较预测模式
+func residualCost(r uint32) int {
+    cost := 0
+    for shift := 0; shift < 32; shift += 8 {
+        v := int(r >> shift & 0xff)
+        cost += min(v, 256-v)
+    }
+    return cost
+}
+
+// vp8lToken 一个编码单元：字面量像素、颜色缓存下标或反向引用
+type vp8lToken struct {
+    kind   uint8 // vp8lTokenLiteral / vp8lTokenCache / vp8lTokenCopy
+    argb   uint32
+    index  int // 颜色缓存下标
+    length int
+    dist   int // 距离码（已映射）
+}
+
+const (
+    vp8lTokenLiteral = iota
+    vp8lTokenCache
+    vp8lTokenCopy
+)
+
+// writeImageData 写入一段图像数据（主图像或变换的子图像）：颜色缓存参数、前缀码和像素
+func writeImageData(w *bitWriter, argb []uint32, width int, cacheBits uint, topLevel bool) {
+    tokens := backwardReferences(argb, width, cacheBits)
+
+    if cacheBits > 0 {
+        w.write(1, 1)
+        w.write(uint32(cacheBits), 4)
+    } else {
+        w.write(0, 1)
+    }
+    if topLevel {
+        w.write(0, 1) // 不使用多组前缀码
+    }
+
+    greenAlphabet := vp8lLiteralAlphabet + vp8lLengthAlphabet
+    if cacheBits > 0 {
+        greenAlphabet += 1 << cacheBits
+    }
+    green := make([]int, greenAlphabet)
+    var red, blue, alpha [256]int
+    dist := make([]int, vp8lDistAlphabet)
+    for _, t := range tokens {
+        switch t.kind {
+        case vp8lTokenLiteral:
+            green[t.argb>>8&0xff]++
+            red[t.argb>>16&0xff]++
+            blue[t.argb&0xff]++
+            alpha[t.argb>>24]++
+        case vp8lTokenCache:
+            green[vp8lLiteralAlphabet+vp8lLengthAlphabet+t.index]++
+        case vp8lTokenCopy:
+            symbol, _, _ := prefixEncode(t.length)
+            green[vp8lLiteralAlphabet+symbol]++
+            symbol, _, _ = prefixEncode(t.dist)
+            dist[symbol]++
+        }
+    }
+
+    codes := [5]prefixCode{
+        writeHuffmanCode(w, green),
+        writeHuffmanCode(w, red[:]),
+        writeHuffmanCode(w, blue[:]),
+        writeHuffmanCode(w, alpha[:]),
+        writeHuffmanCode(w, dist),
+    }
+
+    for _, t := range tokens {
+        switch t.kind {
+        case vp8lTokenLiteral:
+            codes[0].put(w, int(t.argb>>8&0xff))
+            codes[1].put(w, int(t.argb>>16&0xff))
+            codes[2].put(w, int(t.argb&0xff))
+            codes[3].put(w, int(t.argb>>24))
+        case vp8lTokenCache:
+            codes[0].put(w, vp8lLiteralAlphabet+vp8lLengthAlphabet+t.index)
+        case vp8lTokenCopy:
+            symbol, extra, nextra := prefixEncode(t.length)
+            codes[0].put(w, vp8lLiteralAlphabet+symbol)
+            w.write(extra, nextra)
+            symbol, extra, nextra = prefixEncode(t.dist)
+            codes[4].put(w, symbol)
+            w.write(extra, nextra)
+        }
+    }
+}
+
+// backwardReferences 用哈希链贪心查找反向引用，其余像素优先使用颜色缓存
+func backwardReferences(argb []uint32, width int, cacheBits uint) []vp8lToken {
+    n := len(argb)
+    head := make([]int32, 1<<vp8lHashBits)
+    for i := range head {
+        head[i] = -1
+    }
+    chain := make([]int32, n)
+    hashAt := func(i int) uint32 {
+        return ((argb[i]*vp8lColorCacheMultiplier)^(argb[i+1]*0x9e3779b1))>>(32-vp8lHashBits)
+    }
+    insert := func(i int) {
+        if i+1 < n {
+            h := hashAt(i)
+            chain[i] = head[h]
+            head[h] = int32(i)
+        }
+    }
+
+    // 解码端会把邻近偏移映射为短距离码，同一距离取最小的码
+    shortCodes := make(map[int]int, vp8lDistanceMapCodes)
+    for c := vp8lDistanceMapCodes; c >= 1; c-- {
+        v := int(vp8lDistanceMap[c-1])
+        if d := (v>>4)*width + 8 - v&0xf; d >= 1 {
+            shortCodes[d] = c
+        }
+    }
+
+    var cache []uint32
+    var cacheShift uint
+    if cacheBits > 0 {
+        cache = make([]uint32, 1<<cacheBits)
+        cacheShift = 32 - cacheBits
+    }
+    addToCache := func(p uint32) {
+        if cache != nil {
+            cache[(p*vp8lColorCacheMultiplier)>>cacheShift] = p
+        }
+    }
+
+    tokens := make([]vp8lToken, 0, n/2)
+    for i := 0; i < n; {
+        bestLen, bestDist := 0, 0
+        if i+1 < n {
+            limit := min(n-i, vp8lMaxMatch)
+            for cand, steps := head[hashAt(i)], 0; cand >= 0 && steps < vp8lMaxChain; cand, steps = chain[cand], steps+1 {
+                dist := i - int(cand)
+                if dist > vp8lMaxDistance {
+                    break
+                }
+                l := 0
+                for l < limit && argb[int(cand)+l] == argb[i+l] {
+                    l++
+                }
+                if l > bestLen {
+                    bestLen, bestDist = l, dist
+                    if l == limit {
+                        break
+                    }
+                }
+            }
+        }
+
+        if bestLen >= vp8lMinMatch {
+            code, ok := shortCodes[bestDist]
+            if !ok {
+                code = bestDist + vp8lDistanceMapCodes
+            }
+            tokens = append(tokens, vp8lToken{kind: vp8lTokenCopy, length: bestLen, dist: code})
+            for j := i; j < i+bestLen; j++ {
+                insert(j)
+                addToCache(argb[j])
+            }
+            i += bestLen
+            continue
+        }
+
+        p := argb[i]
+        if cache != nil && cache[(p*vp8lColorCacheMultiplier)>>cacheShift] == p {
+            tokens = append(tokens, vp8lToken{kind: vp8lTokenCache, index: int((p * vp8lColorCacheMultiplier) >> cacheShift)})
+        } else {
+            tokens = append(tokens, vp8lToken{kind: vp8lTokenLiteral, argb: p})
+        }
+        insert(i)
+        addToCache(p)
+        i++
+    }
+    return tokens
+}
+
+// prefixEncode 将长度或距离编码为前缀符号和额外比特
+func prefixEncode(v int) (int, uint32, uint) {
+    d := v - 1
+    if d < 4 {
+        return d, 0, 0
+    }
+    h := 0
+    for d>>(h+1) != 0 {
+        h++
+    }
+    second := (d >> (h - 1)) & 1
+    return 2*h + second, uint32(d & (1<<(h-1) - 1)), uint(h - 1)
+}
+
+// nrgbaAt 读取非预乘的 8 位颜色
+func nrgbaAt(img image.Image, x, y int) (uint8, uint8, uint8, uint8) {
+    switch m := img.(type) {
+    case *image.NRGBA:
+        i := m.PixOffset(x, y)
+        return m.Pix[i], m.Pix[i+1], m.Pix[i+2], m.Pix[i+3]
+    case *image.RGBA:
+        i := m.PixOffset(x, y)
+        r, g, b, a := m.Pix[i], m.Pix[i+1], m.Pix[i+2], m.Pix[i+3]
+        if a == 0xff {
+            return r, g, b, a
+        }
+        if a == 0 {
+            return 0, 0, 0, 0
+        }
+        return unpremultiply(r, a), unpremultiply(g, a), unpremultiply(b, a), a
+    }
+    r, g, b, a := img.At(x, y).RGBA()
+    if a == 0 {
+        return 0, 0, 0, 0
+    }
+    return uint8((r * 0xffff / a) >> 8), uint8((g * 0xffff / a) >> 8), uint8((b * 0xffff / a) >> 8), uint8(a >> 8)
+}
+
+func unpremultiply(c, a uint8) uint8 {
+    v := (uint32(c)*255 + uint32(a)/2) / uint32(a)
+    if v > 255 {
+        v = 255
+    }
+    return uint8(v)
+}
+
+// writeRIFF 写入 RIFF/WEBP 容器和一个图像数据块（VP8 或 VP8L）
+func writeRIFF(out io.Writer, fourcc string, data []byte) error {
+    pad := len(data) & 1
+    header := make([]byte, 20)
+    copy(header[0:4], "RIFF")
+    binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+len(data)+pad))
+    copy(header[8:12], "WEBP")
+    copy(header[12:16], fourcc)
+    binary.LittleEndian.PutUint32(header[16:20], uint32(len(data)))
+
+    if _, err := out.Write(header); err != nil {
+        return err
+    }
+    if _, err := out.Write(data); err != nil {
+        return err
+    }
+    if pad == 1 {
+        _, err := out.Write([]byte{0})
+        return err
+    }
+    return nil
+}
+
+// writeHuffmanCode 根据符号频率写入一个前缀码，并返回用于编码符号的码表
+func writeHuffmanCode(w *bitWriter, freq []int) prefixCode {
+    var symbols []int
+    for s, f := range freq {
+        if f > 0 {
+            symbols = append(symbols, s)
+        }
+    }
+
+    // 不超过两个符号且都小于 256 时使用简单码
+    if len(symbols) <= 2 && (len(symbols) == 0 || symbols[len(symbols)-1] < 256) {
+        lengths := make([]uint8, len(freq))
+        if len(symbols) == 0 {
+            symbols = []int{0}
+        }
+        w.write(1, 1)
+        w.write(uint32(len(symbols)-1), 1)
+        w.write(1, 1) // 第一个符号使用 8 比特
+        w.write(uint32(symbols[0]), 8)
+        lengths[symbols[0]] = 1
+        if len(symbols) == 2 {
+            w.write(uint32(symbols[1]), 8)
+            lengths[symbols[1]] = 1
+        }
+        return newPrefixCode(lengths)
+    }
+
+    lengths := huffmanLengths(freq, vp8lMaxCodeLength)
+    writeCodeLengths(w, lengths)
+    return newPrefixCode(lengths)
+}
+
+// codeLengthToken 码长序列中的一个符号：0-15 为码长，17/18 表示一段 0
+type codeLengthToken struct {
+    symbol int
+    extra  uint32
+    nextra uint
+}
+
+// writeCodeLengths 用普通码格式写入码长序列
+func writeCodeLengths(w *bitWriter, lengths []uint8) {
+    var tokens []codeLengthToken
+    for i := 0; i < len(lengths); {
+        if lengths[i] != 0 {
+            tokens = append(tokens, codeLengthToken{symbol: int(lengths[i])})
+            i++
+            continue
+        }
+        run := 0
+        for i+run < len(lengths) && lengths[i+run] == 0 {
+            run++
+        }
+        i += run
+        for run > 0 {
+            switch {
+            case run >= 11:
+                n := run
+                if n > 138 {
+                    n = 138
+                }
+                tokens = append(tokens, codeLengthToken{symbol: 18, extra: uint32(n - 11), nextra: 7})
+                run -= n
+            case run >= 3:
+                tokens = append(tokens, codeLengthToken{symbol: 17, extra: uint32(run - 3), nextra: 3})
+                run = 0
+            default:
+                tokens = append(tokens, codeLengthToken{symbol: 0})
+                run--
+            }
+        }
+    }
+
+    freq := make([]int, vp8lCodeLengthCodes)
+    for _, t := range tokens {
+        freq[t.symbol]++
+    }
+    clLengths := huffmanLengths(freq, vp8lMaxCodeLenCode)
+    clCode := newPrefixCode(clLengths)
+
+    count := vp8lCodeLengthCodes
+    for count > 4 && clLengths[vp8lCodeLengthOrder[count-1]] == 0 {
+        count--
+    }
+
+    w.write(0, 1) // 普通码
+    w.write(uint32(count-4), 4)
+    for i := 0; i < count; i++ {
+        w.write(uint32(clLengths[vp8lCodeLengthOrder[i]]), 3)
+    }
+    w.write(0, 1) // 码长数量等于字母表大小
+
+    for _, t := range tokens {
+        clCode.put(w, t.symbol)
+        if t.nextra > 0 {
+            w.write(t.extra, t.nextra)
+        }
+    }
+}
+
+// huffmanLengths 计算限制最大码长的霍夫曼码长；超过限制时抬高低频符号的计数后重试，结果始终是完整的前缀码
+func huffmanLengths(freq []int, limit int) []uint8 {
+    lengths := make([]uint8, len(freq))
+    var used []int
+    for s, f := range freq {
+        if f > 0 {
+            used = append(used, s)
+        }
+    }
+    switch len(used) {
+    case 0:
+        return lengths
+    case 1:
+        lengths[used[0]] = 1
+        return lengths
+    }
+
+    for minCount := 1; ; minCount *= 2 {
+        counts := make([]int, len(used))
+        for i, s := range used {
+            counts[i] = freq[s]
+            if counts[i] < minCount {
+                counts[i] = minCount
+            }
+        }
+        depths := treeDepths(counts)
+        max := 0
+        for _, d := range depths {
+            if d > max {
+                max = d
+            }
+        }
+        if max <= limit {
+            for i, s := range used {
+                lengths[s] = uint8(depths[i])
+            }
+            return lengths
+        }
+    }
+}
+
+// treeDepths 构建霍夫曼树并返回每个叶子的深度
+func treeDepths(counts []int) []int {
+    type node struct {
+        count       int
+        left, right int // 子节点下标，叶子为 -1
+    }
+    nodes := make([]node, 0, 2*len(counts))
+    for _, c := range counts {
+        nodes = append(nodes, node{count: c, left: -1, right: -1})
+    }
+
+    // 叶子按计数排序后使用双队列合并
+    leaves := make([]int, len(counts))
+    for i := range leaves {
+        leaves[i] = i
+    }
+    sort.SliceStable(leaves, func(a, b int) bool { return counts[leaves[a]] < counts[leaves[b]] })
+
+    var merged []int
+    li, mi := 0, 0
+    pick := func() int {
+        if li < len(leaves) && (mi >= len(merged) || nodes[leaves[li]].count <= nodes[merged[mi]].count) {
+            li++
+            return leaves[li-1]
+        }
+        mi++
+        return merged[mi-1]
+    }
+    for remaining := len(counts); remaining > 1; remaining-- {
+        a := pick()
+        b := pick()
+        nodes = append(nodes, node{count: nodes[a].count + nodes[b].count, left: a, right: b})
+        merged = append(merged, len(nodes)-1)
+    }
+
+    depths := make([]int, len(counts))
+    var walk func(n, depth int)
+    walk = func(n, depth int) {
+        if nodes[n].left < 0 {
+            depths[n] = depth
+            return
+        }
+        walk(nodes[n].left, depth+1)
+        walk(nodes[n].right, depth+1)
+    }
+    walk(len(nodes)-1, 0)
+    return depths
+}
+
+// canonicalCodes 由码长生成规范码（与 DEFLATE 相同的分配方式），并按写入顺序反转比特
+func canonicalCodes(lengths []uint8) []uint32 {
+    var count [vp8lMaxCodeLength + 1]uint32
+    for _, l := range lengths {
+        if l > 0 {
+            count[l]++
+        }
+    }
+    var next [vp8lMaxCodeLength + 2]uint32
+    code := uint32(0)
+    for bits := 1; bits <= vp8lMaxCodeLength; bits++ {
+        code = (code + count[bits-1]) << 1
+        next[bits] = code
+    }
+
+    codes := make([]uint32, len(lengths))
+    for s, l := range lengths {
+        if l == 0 {
+            continue
+        }
+        c := next[l]
+        next[l]++
+        var reversed uint32
+        for i := uint8(0); i < l; i++ {
+            reversed = reversed<<1 | (c>>i)&1
+        }
+        codes[s] = reversed
+    }
+    return codes
+}
@@ -0,0 +1,91 @@
+// internal/keyring/encryption.go
+package keyring
+
+import (
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+)
+
+// encryptedPrefix 加密保存的密钥材料的前缀，没有前缀的是启用加密前保存的明文
+const encryptedPrefix = "enc:v1:"
+
+// MinKeyEncryptionKeyLength 密钥加密密钥的最小长度（字节）
+const MinKeyEncryptionKeyLength = 32
+
+var (
+    ErrNoKeyEncryptionKey = errors.New("JWT_KEY_ENCRYPTION_KEY must be set to store rotated signing keys in the database")
+    ErrMaterialDecrypt    = errors.New("failed to decrypt signing key material")
+)
+
+// KeyEncryptionKeyFromEnv 读取 JWT_KEY_ENCRYPTION_KEY。轮换生成的签名密钥保存在数据库中，
+// 用它加密后能读取数据库或备份的人也无法伪造令牌；未设置时返回 nil，此时不能开启轮换
+func KeyEncryptionKeyFromEnv() ([]byte, error) {
+    secret := os.Getenv("JWT_KEY_ENCRYPTION_KEY")
+    if secret == "" {
+        return nil, nil
+    }
+    if len(secret) < MinKeyEncryptionKeyLength {
+        return nil, fmt.Errorf("JWT_KEY_ENCRYPTION_KEY must be at least %d bytes", MinKeyEncryptionKeyLength)
+    }
+    return []byte(secret), nil
+}
+
+// newMaterialCipher 由密钥加密密钥派生 AES-256-GCM
+func newMaterialCipher(kek []byte) (cipher.AEAD, error) {
+    sum := sha256.Sum256(kek)
+    block, err := aes.NewCipher(sum[:])
+    if err != nil {
+        return nil, err
+    }
+    return cipher.NewGCM(block)
+}
+
+// sealMaterial 加密密钥材料，kid 作为附加数据，密文不能挪用到其他密钥
+func sealMaterial(kek []byte, kid, material string) (string, error) {
+    if len(kek) == 0 {
+        return "", ErrNoKeyEncryptionKey
+    }
+    aead, err := newMaterialCipher(kek)
+    if err != nil {
+        return "", err
+    }
+    nonce := make([]byte, aead.NonceSize())
+    if _, err := rand.Read(nonce); err != nil {
+        return "", err
+    }
+    sealed := aead.Seal(nonce, nonce, []byte(material), []byte(kid))
+    return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openMaterial 解密 sealMaterial 的结果；启用加密前保存的明文原样返回，以便旧令牌在宽限期内仍可验证
+func openMaterial(kek []byte, kid, stored string) (string, error) {
+    if !strings.HasPrefix(stored, encryptedPrefix) {
+        return stored, nil
+    }
+    if len(kek) == 0 {
+        return "", ErrNoKeyEncryptionKey
+    }
+    sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, encryptedPrefix))
+    if err != nil {
+        return "", ErrMaterialDecrypt
+    }
+    aead, err := newMaterialCipher(kek)
+    if err != nil {
+        return "", err
+    }
+    if len(sealed) < aead.NonceSize() {
+        return "", ErrMaterialDecrypt
+    }
+    material, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(kid))
+    if err != nil {
+        return "", ErrMaterialDecrypt
+    }
+    return string(material), nil
+}
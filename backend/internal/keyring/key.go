@@ -0,0 +1,197 @@
+// internal/keyring/key.go
+package keyring
+
+import (
+    "crypto/ed25519"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "math/big"
+    "time"
+
+    "github.com/golang-jwt/jwt/v4"
+)
+
+// 支持的签名算法
+const (
+    AlgHS256 = "HS256"
+    AlgRS256 = "RS256"
+    AlgEdDSA = "EdDSA"
+)
+
+// MinHMACSecretLength HMAC 密钥的最小字节数
+const MinHMACSecretLength = 32
+
+const rsaKeyBits = 2048
+
+// Key 一个 JWT 签名密钥，令牌头部的 kid 即 Key.ID。
+// 加入密钥环后不再修改，签名和验证在锁外读取；退役时密钥环换成带 RetiredAt 的副本
+type Key struct {
+    ID        string
+    Algorithm string
+    CreatedAt time.Time
+    RetiredAt *time.Time // 退役后不再用于签名，宽限期内仍可验证
+
+    signKey   interface{} // []byte / *rsa.PrivateKey / ed25519.PrivateKey
+    verifyKey interface{} // []byte / *rsa.PublicKey / ed25519.PublicKey
+}
+
+// NewHMACKey 使用共享密钥创建 HS256 签名密钥
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+    if len(secret) < MinHMACSecretLength {
+        return nil, fmt.Errorf("HMAC secret of key %q must be at least %d bytes", id, MinHMACSecretLength)
+    }
+    return &Key{ID: id, Algorithm: AlgHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+// NewPrivateKey 使用 RSA 或 Ed25519 私钥创建 RS256 / EdDSA 签名密钥
+func NewPrivateKey(id string, private interface{}) (*Key, error) {
+    switch k := private.(type) {
+    case *rsa.PrivateKey:
+        return &Key{ID: id, Algorithm: AlgRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+    case ed25519.PrivateKey:
+        return &Key{ID: id, Algorithm: AlgEdDSA, signKey: k, verifyKey: k.Public()}, nil
+    default:
+        return nil, fmt.Errorf("unsupported private key type %T of key %q", private, id)
+    }
+}
+
+// ParsePrivateKeyPEM 解析 PEM 格式（PKCS#8 或 PKCS#1）的 RSA / Ed25519 私钥
+func ParsePrivateKeyPEM(id string, data []byte) (*Key, error) {
+    block, _ := pem.Decode(data)
+    if block == nil {
+        return nil, fmt.Errorf("key %q is not PEM encoded", id)
+    }
+    private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+    if err != nil {
+        rsaKey, rsaErr := x509.ParsePKCS1PrivateKey(block.Bytes)
+        if rsaErr != nil {
+            return nil, fmt.Errorf("failed to parse private key %q: %w", id, err)
+        }
+        private = rsaKey
+    }
+    return NewPrivateKey(id, private)
+}
+
+// GenerateKey 随机生成指定算法的新密钥，kid 随机生成
+func GenerateKey(algorithm string, now time.Time) (*Key, error) {
+    id, err := newKeyID()
+    if err != nil {
+        return nil, err
+    }
+
+    var key *Key
+    switch algorithm {
+    case AlgHS256:
+        secret := make([]byte, MinHMACSecretLength)
+        if _, err := rand.Read(secret); err != nil {
+            return nil, err
+        }
+        key, err = NewHMACKey(id, secret)
+    case AlgRS256:
+        var private *rsa.PrivateKey
+        if private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits); err == nil {
+            key, err = NewPrivateKey(id, private)
+        }
+    case AlgEdDSA:
+        var private ed25519.PrivateKey
+        if _, private, err = ed25519.GenerateKey(rand.Reader); err == nil {
+            key, err = NewPrivateKey(id, private)
+        }
+    default:
+        return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+    }
+    if err != nil {
+        return nil, err
+    }
+    key.CreatedAt = now
+    return key, nil
+}
+
+// ParseMaterial 还原 Material 序列化的密钥
+func ParseMaterial(id, algorithm, material string) (*Key, error) {
+    if algorithm == AlgHS256 {
+        secret, err := base64.StdEncoding.DecodeString(material)
+        if err != nil {
+            return nil, fmt.Errorf("failed to decode HMAC secret of key %q: %w", id, err)
+        }
+        return NewHMACKey(id, secret)
+    }
+    key, err := ParsePrivateKeyPEM(id, []byte(material))
+    if err != nil {
+        return nil, err
+    }
+    if key.Algorithm != algorithm {
+        return nil, fmt.Errorf("key %q is %s, not %s", id, key.Algorithm, algorithm)
+    }
+    return key, nil
+}
+
+// Material 序列化私钥以便保存：HMAC 密钥为 base64，非对称密钥为 PKCS#8 PEM
+func (k *Key) Material() (string, error) {
+    if secret, ok := k.signKey.([]byte); ok {
+        return base64.StdEncoding.EncodeToString(secret), nil
+    }
+    der, err := x509.MarshalPKCS8PrivateKey(k.signKey)
+    if err != nil {
+        return "", err
+    }
+    return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// method 返回密钥对应的 JWT 签名方法
+func (k *Key) method() jwt.SigningMethod {
+    return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// expired 密钥退役超过宽限期后不能再用于验证
+func (k *Key) expired(now time.Time, gracePeriod time.Duration) bool {
+    return k.RetiredAt != nil && now.Sub(*k.RetiredAt) > gracePeriod
+}
+
+// JWK 以 JSON Web Key 格式表示的公钥（RFC 7517 / RFC 8037）
+type JWK struct {
+    KeyType   string `json:"kty"`
+    KeyID     string `json:"kid"`
+    Use       string `json:"use"`
+    Algorithm string `json:"alg"`
+    N         string `json:"n,omitempty"`   // RSA 模数
+    E         string `json:"e,omitempty"`   // RSA 公钥指数
+    Curve     string `json:"crv,omitempty"` // OKP 曲线
+    X         string `json:"x,omitempty"`   // Ed25519 公钥
+}
+
+// JWKSet JWKS 接口返回的公钥集合
+type JWKSet struct {
+    Keys []JWK `json:"keys"`
+}
+
+// JWK 返回密钥的公钥部分，HMAC 密钥没有可公开的部分，返回错误
+func (k *Key) JWK() (JWK, error) {
+    b64 := base64.RawURLEncoding.EncodeToString
+    switch pub := k.verifyKey.(type) {
+    case *rsa.PublicKey:
+        return JWK{
+            KeyType: "RSA", KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm,
+            N: b64(pub.N.Bytes()), E: b64(big.NewInt(int64(pub.E)).Bytes()),
+        }, nil
+    case ed25519.PublicKey:
+        return JWK{KeyType: "OKP", KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm, Curve: "Ed25519", X: b64(pub)}, nil
+    default:
+        return JWK{}, errors.New("symmetric keys cannot be published")
+    }
+}
+
+// newKeyID 生成随机的 kid
+func newKeyID() (string, error) {
+    b := make([]byte, 8)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
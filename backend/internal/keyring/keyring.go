@@ -0,0 +1,319 @@
+// internal/keyring/keyring.go
+package keyring
+
+import (
+    "errors"
+    "fmt"
+    "log"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/golang-jwt/jwt/v4"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/config"
+)
+
+var (
+    ErrNoSigningKey  = errors.New("no signing key available")
+    ErrUnknownKey    = errors.New("unknown signing key")
+    ErrMethodInvalid = errors.New("unexpected signing method")
+)
+
+// Keyring 保存所有可用的签名密钥：已生效的最新未退役密钥用于签名，其余密钥在退役后的宽限期内仍可验证令牌
+type Keyring struct {
+    mu          sync.RWMutex
+    keys        map[string]*Key
+    algorithm   string        // 轮换时生成新密钥使用的算法
+    gracePeriod time.Duration // 退役密钥继续用于验证的时间
+    kek         []byte        // 加密数据库中保存的密钥材料，见 KeyEncryptionKeyFromEnv
+    now         func() time.Time
+}
+
+// New 创建密钥环，keys 中至少要有一个密钥
+func New(algorithm string, gracePeriod time.Duration, keys ...*Key) (*Keyring, error) {
+    if !supported(algorithm) {
+        return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+    }
+    if len(keys) == 0 {
+        return nil, ErrNoSigningKey
+    }
+    r := &Keyring{keys: make(map[string]*Key), algorithm: algorithm, gracePeriod: gracePeriod, now: time.Now}
+    for _, k := range keys {
+        if _, dup := r.keys[k.ID]; dup {
+            return nil, fmt.Errorf("duplicate signing key id %q", k.ID)
+        }
+        r.keys[k.ID] = k
+    }
+    return r, nil
+}
+
+func supported(algorithm string) bool {
+    return algorithm == AlgHS256 || algorithm == AlgRS256 || algorithm == AlgEdDSA
+}
+
+// Current 返回当前用于签名的密钥：已生效（CreatedAt 不晚于现在）的最新未退役密钥，
+// 都未生效时使用最新的未退役密钥
+func (r *Keyring) Current() *Key {
+    return r.currentAt(r.now())
+}
+
+func (r *Keyring) currentAt(now time.Time) *Key {
+    var current, pending *Key
+    for _, k := range r.Keys() {
+        if k.RetiredAt != nil {
+            continue
+        }
+        if k.CreatedAt.After(now) {
+            if pending == nil {
+                pending = k
+            }
+            continue
+        }
+        current = k
+        break
+    }
+    if current == nil {
+        return pending
+    }
+    return current
+}
+
+// Sign 使用当前密钥签名，令牌头部带上 kid
+func (r *Keyring) Sign(claims jwt.Claims) (string, error) {
+    key := r.Current()
+    if key == nil {
+        return "", ErrNoSigningKey
+    }
+    token := jwt.NewWithClaims(key.method(), claims)
+    token.Header["kid"] = key.ID
+    return token.SignedString(key.signKey)
+}
+
+// Parse 按令牌头部的 kid 选择密钥验证令牌，签名算法必须与密钥一致
+func (r *Keyring) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+    return jwt.ParseWithClaims(tokenString, claims, r.verificationKey,
+        jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}))
+}
+
+func (r *Keyring) verificationKey(token *jwt.Token) (interface{}, error) {
+    kid, _ := token.Header["kid"].(string)
+
+    r.mu.RLock()
+    key, ok := r.keys[kid]
+    r.mu.RUnlock()
+    if !ok || key.expired(r.now(), r.gracePeriod) {
+        return nil, ErrUnknownKey
+    }
+    if token.Method.Alg() != key.Algorithm {
+        return nil, ErrMethodInvalid
+    }
+    return key.verifyKey, nil
+}
+
+// Add 加入一个密钥，已存在同 kid 的密钥时替换
+func (r *Keyring) Add(key *Key) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.keys[key.ID] = key
+}
+
+// Retire 退役密钥，之后只用于验证。已取出的 *Key 可能正在其他 goroutine 中使用，因此替换为副本而不修改原密钥
+func (r *Keyring) Retire(id string, at time.Time) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if key, ok := r.keys[id]; ok && key.RetiredAt == nil {
+        retired := *key
+        retired.RetiredAt = &at
+        r.keys[id] = &retired
+    }
+}
+
+// Prune 删除退役超过宽限期的密钥
+func (r *Keyring) Prune(now time.Time) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    for id, key := range r.keys {
+        if key.expired(now, r.gracePeriod) {
+            delete(r.keys, id)
+        }
+    }
+}
+
+// Keys 返回所有密钥，按创建时间从新到旧
+func (r *Keyring) Keys() []*Key {
+    r.mu.RLock()
+    keys := make([]*Key, 0, len(r.keys))
+    for _, k := range r.keys {
+        keys = append(keys, k)
+    }
+    r.mu.RUnlock()
+    sort.Slice(keys, func(i, j int) bool {
+        if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+            return keys[i].CreatedAt.After(keys[j].CreatedAt)
+        }
+        return keys[i].ID > keys[j].ID
+    })
+    return keys
+}
+
+// JWKS 返回所有仍可用于验证的非对称密钥的公钥，HMAC 密钥不公开
+func (r *Keyring) JWKS() JWKSet {
+    now := r.now()
+    set := JWKSet{Keys: []JWK{}}
+    for _, key := range r.Keys() {
+        if key.expired(now, r.gracePeriod) {
+            continue
+        }
+        if jwk, err := key.JWK(); err == nil {
+            set.Keys = append(set.Keys, jwk)
+        }
+    }
+    return set
+}
+
+var (
+    defaultMu      sync.Mutex
+    defaultKeyring *Keyring
+)
+
+// SetDefault 设置全局密钥环，通常在启动时调用一次
+func SetDefault(r *Keyring) {
+    defaultMu.Lock()
+    defer defaultMu.Unlock()
+    defaultKeyring = r
+}
+
+// Default 返回全局密钥环，未设置时按当前环境变量创建并保存
+func Default() (*Keyring, error) {
+    defaultMu.Lock()
+    defer defaultMu.Unlock()
+    if defaultKeyring == nil {
+        r, err := FromEnv()
+        if err != nil {
+            return nil, err
+        }
+        defaultKeyring = r
+    }
+    return defaultKeyring, nil
+}
+
+// SetKeyEncryptionKey 设置加密数据库中签名密钥的密钥，Sync 轮换前必须设置
+func (r *Keyring) SetKeyEncryptionKey(kek []byte) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.kek = kek
+}
+
+// FromEnv 按环境变量创建密钥环：
+//   JWT_SIGNING_ALG：轮换时生成新密钥的算法，HS256（默认）/ RS256 / EdDSA
+//   JWT_KEYS_DIR：密钥目录，<kid>.pem 为 RSA / Ed25519 私钥，<kid>.key 为 HMAC 密钥，修改时间最新的用于签名
+//   JWT_SECRET、JWT_KEY_ID：单个 HMAC 密钥及其 kid（默认 default）
+//   JWT_KEY_GRACE_PERIOD：退役密钥的验证宽限期，默认与 Refresh Token 有效期相同
+// 都未配置时使用随机生成的临时密钥，重启后已签发的令牌全部失效
+func FromEnv() (*Keyring, error) {
+    algorithm := os.Getenv("JWT_SIGNING_ALG")
+    if algorithm == "" {
+        algorithm = AlgHS256
+    }
+
+    var keys []*Key
+    if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+        dirKeys, err := loadKeysDir(dir)
+        if err != nil {
+            return nil, err
+        }
+        keys = append(keys, dirKeys...)
+    }
+    if secret := os.Getenv("JWT_SECRET"); secret != "" {
+        id := os.Getenv("JWT_KEY_ID")
+        if id == "" {
+            id = "default"
+        }
+        key, err := NewHMACKey(id, []byte(secret))
+        if err != nil {
+            return nil, err
+        }
+        keys = append(keys, key)
+    }
+    if len(keys) == 0 {
+        log.Println("未配置 JWT 签名密钥，使用随机生成的临时密钥，重启后已签发的令牌将失效")
+        // 创建时间取零值，开启轮换后数据库中的共享密钥总是优先
+        key, err := GenerateKey(algorithm, time.Time{})
+        if err != nil {
+            return nil, err
+        }
+        keys = append(keys, key)
+    }
+    kek, err := KeyEncryptionKeyFromEnv()
+    if err != nil {
+        return nil, err
+    }
+    r, err := New(algorithm, GracePeriod(), keys...)
+    if err != nil {
+        return nil, err
+    }
+    r.SetKeyEncryptionKey(kek)
+    return r, nil
+}
+
+// loadKeysDir 读取密钥目录中的 .pem / .key 文件，文件名（不含扩展名）为 kid
+func loadKeysDir(dir string) ([]*Key, error) {
+    entries, err := os.ReadDir(dir)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read JWT_KEYS_DIR: %w", err)
+    }
+
+    var keys []*Key
+    for _, entry := range entries {
+        ext := filepath.Ext(entry.Name())
+        if entry.IsDir() || (ext != ".pem" && ext != ".key") {
+            continue
+        }
+        path := filepath.Join(dir, entry.Name())
+        data, err := os.ReadFile(path)
+        if err != nil {
+            return nil, err
+        }
+        info, err := entry.Info()
+        if err != nil {
+            return nil, err
+        }
+
+        id := strings.TrimSuffix(entry.Name(), ext)
+        var key *Key
+        if ext == ".pem" {
+            key, err = ParsePrivateKeyPEM(id, data)
+        } else {
+            key, err = NewHMACKey(id, []byte(strings.TrimSpace(string(data))))
+        }
+        if err != nil {
+            return nil, err
+        }
+        key.CreatedAt = info.ModTime()
+        keys = append(keys, key)
+    }
+    return keys, nil
+}
+
+// GracePeriod 读取 JWT_KEY_GRACE_PERIOD，未设置或无效时与 Refresh Token 有效期相同，保证轮换后已签发的令牌仍可使用
+func GracePeriod() time.Duration {
+    if v := os.Getenv("JWT_KEY_GRACE_PERIOD"); v != "" {
+        if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+            return d
+        }
+    }
+    return config.JWTConfig.RefreshTokenExpiration
+}
+
+// RotationInterval 读取 JWT_KEY_ROTATION_INTERVAL，为 0（默认）时不自动轮换
+func RotationInterval() time.Duration {
+    if v := os.Getenv("JWT_KEY_ROTATION_INTERVAL"); v != "" {
+        if d, err := time.ParseDuration(v); err == nil && d > 0 {
+            return d
+        }
+    }
+    return 0
+}
@@ -0,0 +1,275 @@
+// internal/keyring/keyring_test.go
+package keyring
+
+import (
+    "crypto/ed25519"
+    "encoding/base64"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/golang-jwt/jwt/v4"
+    "github.com/stretchr/testify/assert"
+    "gorm.io/driver/sqlite"
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+func parseSubject(r *Keyring, token string) (string, error) {
+    claims := &jwt.RegisteredClaims{}
+    if _, err := r.Parse(token, claims); err != nil {
+        return "", err
+    }
+    return claims.Subject, nil
+}
+
+func TestSignAndParse(t *testing.T) {
+    for _, alg := range []string{AlgHS256, AlgRS256, AlgEdDSA} {
+        t.Run(alg, func(t *testing.T) {
+            key, err := GenerateKey(alg, time.Now())
+            assert.NoError(t, err)
+
+            // 序列化后还原的密钥与原密钥等价
+            material, err := key.Material()
+            assert.NoError(t, err)
+            restored, err := ParseMaterial(key.ID, alg, material)
+            assert.NoError(t, err)
+
+            signer, err := New(alg, time.Hour, key)
+            assert.NoError(t, err)
+            verifier, err := New(alg, time.Hour, restored)
+            assert.NoError(t, err)
+
+            token, err := signer.Sign(&jwt.RegisteredClaims{Subject: "42"})
+            assert.NoError(t, err)
+            parsed, _ := jwt.Parse(token, nil)
+            assert.Equal(t, key.ID, parsed.Header["kid"])
+            assert.Equal(t, alg, parsed.Header["alg"])
+
+            subject, err := parseSubject(verifier, token)
+            assert.NoError(t, err)
+            assert.Equal(t, "42", subject)
+        })
+    }
+
+    t.Run("Rejects Unknown Kid And Algorithm Confusion", func(t *testing.T) {
+        rsaKey, err := GenerateKey(AlgRS256, time.Now())
+        assert.NoError(t, err)
+        r, err := New(AlgRS256, time.Hour, rsaKey)
+        assert.NoError(t, err)
+
+        other, _ := GenerateKey(AlgRS256, time.Now())
+        otherRing, _ := New(AlgRS256, time.Hour, other)
+        token, _ := otherRing.Sign(&jwt.RegisteredClaims{Subject: "1"})
+        _, err = parseSubject(r, token)
+        assert.Error(t, err)
+
+        // 用 RSA 公钥当作 HMAC 密钥伪造的令牌
+        jwk, _ := rsaKey.JWK()
+        forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.RegisteredClaims{Subject: "1"})
+        forged.Header["kid"] = rsaKey.ID
+        forgedToken, _ := forged.SignedString([]byte(jwk.N))
+        _, err = parseSubject(r, forgedToken)
+        assert.Error(t, err)
+
+        // 没有 kid 的令牌
+        plain, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.RegisteredClaims{Subject: "1"}).
+            SignedString([]byte("your_secret_key"))
+        _, err = parseSubject(r, plain)
+        assert.Error(t, err)
+    })
+}
+
+func TestJWKS(t *testing.T) {
+    edKey, _ := GenerateKey(AlgEdDSA, time.Now())
+    rsaKey, _ := GenerateKey(AlgRS256, time.Now().Add(-time.Hour))
+    hmacKey, _ := GenerateKey(AlgHS256, time.Now().Add(-2*time.Hour))
+    r, err := New(AlgEdDSA, time.Hour, edKey, rsaKey, hmacKey)
+    assert.NoError(t, err)
+
+    set := r.JWKS()
+    if assert.Len(t, set.Keys, 2) {
+        assert.Equal(t, edKey.ID, set.Keys[0].KeyID)
+        assert.Equal(t, "OKP", set.Keys[0].KeyType)
+        assert.Equal(t, rsaKey.ID, set.Keys[1].KeyID)
+        assert.Equal(t, "RSA", set.Keys[1].KeyType)
+    }
+
+    // 其他服务只凭 JWKS 中的公钥即可验证令牌
+    token, _ := r.Sign(&jwt.RegisteredClaims{Subject: "7"})
+    x, err := base64.RawURLEncoding.DecodeString(set.Keys[0].X)
+    assert.NoError(t, err)
+    parsed, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) {
+        return ed25519.PublicKey(x), nil
+    })
+    assert.NoError(t, err)
+    assert.True(t, parsed.Valid)
+}
+
+var testKEK = []byte("kek-0123456789abcdef0123456789abcdef")
+
+func TestSyncRotation(t *testing.T) {
+    db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+    assert.NoError(t, err)
+    assert.NoError(t, db.AutoMigrate(&models.SigningKey{}))
+
+    const interval = 24 * time.Hour
+    const grace = 2 * time.Hour
+    clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+    configured, _ := NewHMACKey("configured", []byte("0123456789abcdef0123456789abcdef"))
+    r, err := New(AlgEdDSA, grace, configured)
+    assert.NoError(t, err)
+    r.now = func() time.Time { return clock }
+    r.SetKeyEncryptionKey(testKEK)
+    configuredToken, _ := r.Sign(&jwt.RegisteredClaims{Subject: "configured"})
+
+    // 首次同步：配置的密钥不在数据库中，立即生成共享密钥
+    assert.NoError(t, r.Sync(db, interval, clock))
+    first := r.Current()
+    assert.NotEqual(t, "configured", first.ID)
+    assert.Equal(t, AlgEdDSA, first.Algorithm)
+    firstToken, _ := r.Sign(&jwt.RegisteredClaims{Subject: "first"})
+    _, err = parseSubject(r, configuredToken)
+    assert.NoError(t, err, "退役的密钥在宽限期内仍可验证")
+
+    // 另一个实例使用随机临时密钥启动，同步后改用共享密钥
+    ephemeral, _ := GenerateKey(AlgEdDSA, time.Time{})
+    other, _ := New(AlgEdDSA, grace, ephemeral)
+    other.now = r.now
+    other.SetKeyEncryptionKey(testKEK)
+    assert.NoError(t, other.Sync(db, interval, clock))
+    assert.Equal(t, first.ID, other.Current().ID)
+    subject, err := parseSubject(other, firstToken)
+    assert.NoError(t, err)
+    assert.Equal(t, "first", subject)
+
+    // 到期轮换：新密钥先发布，生效前仍用旧密钥签名
+    clock = clock.Add(interval)
+    assert.NoError(t, r.Sync(db, interval, clock))
+    assert.Equal(t, first.ID, r.Current().ID)
+    assert.NoError(t, other.Sync(db, interval, clock))
+    assert.Len(t, other.Keys(), 2)
+
+    clock = clock.Add(KeyActivationDelay)
+    second := r.Current()
+    assert.NotEqual(t, first.ID, second.ID)
+    secondToken, _ := r.Sign(&jwt.RegisteredClaims{Subject: "second"})
+    _, err = parseSubject(other, secondToken)
+    assert.NoError(t, err)
+
+    assert.NoError(t, r.Sync(db, interval, clock))
+    _, err = parseSubject(r, firstToken)
+    assert.NoError(t, err)
+
+    // 超过宽限期后旧密钥被删除
+    clock = clock.Add(grace + time.Minute)
+    assert.NoError(t, r.Sync(db, interval, clock))
+    _, err = parseSubject(r, firstToken)
+    assert.Error(t, err)
+    _, err = parseSubject(r, configuredToken)
+    assert.Error(t, err)
+    _, err = parseSubject(r, secondToken)
+    assert.NoError(t, err)
+
+    var kids []string
+    db.Model(&models.SigningKey{}).Pluck("kid", &kids)
+    assert.Equal(t, []string{second.ID}, kids)
+}
+
+// TestSyncConcurrentWithSignAndParse 轮换和退役密钥时其他 goroutine 仍在签名和验证，需配合 -race 运行
+func TestSyncConcurrentWithSignAndParse(t *testing.T) {
+    db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+    assert.NoError(t, err)
+    assert.NoError(t, db.AutoMigrate(&models.SigningKey{}))
+
+    configured, _ := NewHMACKey("configured", []byte("0123456789abcdef0123456789abcdef"))
+    r, _ := New(AlgHS256, 24*time.Hour, configured)
+    r.SetKeyEncryptionKey(testKEK)
+    clock := time.Now()
+    assert.NoError(t, r.Sync(db, time.Minute, clock))
+
+    done := make(chan struct{})
+    var wg sync.WaitGroup
+    for i := 0; i < 4; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for {
+                select {
+                case <-done:
+                    return
+                default:
+                }
+                token, err := r.Sign(&jwt.RegisteredClaims{Subject: "concurrent"})
+                if err != nil {
+                    t.Error(err)
+                    return
+                }
+                if _, err := parseSubject(r, token); err != nil {
+                    t.Error(err)
+                    return
+                }
+                r.JWKS()
+            }
+        }()
+    }
+
+    // 每次同步都会生效一个新密钥并退役旧密钥
+    for i := 0; i < 20; i++ {
+        clock = clock.Add(KeyActivationDelay + time.Minute)
+        assert.NoError(t, r.Sync(db, time.Minute, clock))
+    }
+    close(done)
+    wg.Wait()
+}
+
+// TestSyncEncryptsMaterial 数据库中只保存加密后的密钥材料，未设置或设置了错误的加密密钥时不能使用
+func TestSyncEncryptsMaterial(t *testing.T) {
+    db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+    assert.NoError(t, err)
+    assert.NoError(t, db.AutoMigrate(&models.SigningKey{}))
+    clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+    // 未设置加密密钥时拒绝轮换，不写入明文密钥
+    configured, _ := NewHMACKey("configured", []byte("0123456789abcdef0123456789abcdef"))
+    r, _ := New(AlgHS256, time.Hour, configured)
+    assert.ErrorIs(t, r.Sync(db, 24*time.Hour, clock), ErrNoKeyEncryptionKey)
+    var count int64
+    db.Model(&models.SigningKey{}).Count(&count)
+    assert.Zero(t, count)
+
+    r.SetKeyEncryptionKey(testKEK)
+    assert.NoError(t, r.Sync(db, 24*time.Hour, clock))
+    current := r.Current()
+    plain, err := current.Material()
+    assert.NoError(t, err)
+    var row models.SigningKey
+    assert.NoError(t, db.First(&row, "kid = ?", current.ID).Error)
+    assert.True(t, strings.HasPrefix(row.Material, encryptedPrefix))
+    assert.NotContains(t, row.Material, plain)
+
+    // 加密密钥错误时无法解密
+    _, err = openMaterial([]byte("another-kek-0123456789abcdef01234567"), row.KID, row.Material)
+    assert.ErrorIs(t, err, ErrMaterialDecrypt)
+
+    // 密文不能挪用到其他 kid
+    _, err = openMaterial(testKEK, "other", row.Material)
+    assert.ErrorIs(t, err, ErrMaterialDecrypt)
+
+    // 启用加密前保存的明文密钥仍可加载
+    legacy, _ := GenerateKey(AlgHS256, clock)
+    legacyMaterial, _ := legacy.Material()
+    assert.NoError(t, db.Create(&models.SigningKey{KID: legacy.ID, Algorithm: AlgHS256, Material: legacyMaterial, CreatedAt: clock}).Error)
+    other, _ := New(AlgHS256, time.Hour, configured)
+    other.SetKeyEncryptionKey(testKEK)
+    other.now = func() time.Time { return clock }
+    assert.NoError(t, other.Sync(db, 24*time.Hour, clock))
+    assert.True(t, other.has(current.ID))
+    assert.True(t, other.has(legacy.ID))
+
+    t.Setenv("JWT_KEY_ENCRYPTION_KEY", "too-short")
+    _, err = KeyEncryptionKeyFromEnv()
+    assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+// internal/keyring/rotation.go
+package keyring
+
+import (
+    "log"
+    "time"
+
+    "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+)
+
+// KeyActivationDelay 轮换出的新密钥保存到数据库后经过这段时间才开始签名，
+// 保证各实例（每分钟同步一次）在收到新密钥签发的令牌之前都已加载该密钥
+const KeyActivationDelay = 3 * time.Minute
+
+// Sync 与数据库中的签名密钥同步，由后台任务定期调用：
+//   1. 加载其他实例生成的密钥，同步退役状态；
+//   2. 当前密钥使用超过 interval（或不是数据库中的共享密钥）时生成新密钥；
+//   3. 新密钥生效后退役较旧的密钥，删除退役超过宽限期的密钥。
+// 数据库中的密钥材料用 SetKeyEncryptionKey 设置的密钥加密，未设置时返回 ErrNoKeyEncryptionKey，不以明文保存签名密钥
+func (r *Keyring) Sync(db *gorm.DB, interval time.Duration, now time.Time) error {
+    kek := r.keyEncryptionKey()
+    if len(kek) == 0 {
+        return ErrNoKeyEncryptionKey
+    }
+
+    var rows []models.SigningKey
+    if err := db.Find(&rows).Error; err != nil {
+        return err
+    }
+
+    stored := make(map[string]bool, len(rows))
+    for _, row := range rows {
+        stored[row.KID] = true
+        if row.RetiredAt != nil {
+            if now.Sub(*row.RetiredAt) > r.gracePeriod {
+                continue
+            }
+            r.Retire(row.KID, *row.RetiredAt)
+        }
+        if r.has(row.KID) {
+            continue
+        }
+        material, err := openMaterial(kek, row.KID, row.Material)
+        if err != nil {
+            log.Printf("跳过无法解密的签名密钥 %s: %v", row.KID, err)
+            continue
+        }
+        key, err := ParseMaterial(row.KID, row.Algorithm, material)
+        if err != nil {
+            log.Printf("跳过无法解析的签名密钥 %s: %v", row.KID, err)
+            continue
+        }
+        key.CreatedAt = row.CreatedAt
+        key.RetiredAt = row.RetiredAt
+        r.Add(key)
+    }
+
+    current := r.currentAt(now)
+    if !r.hasPending(now) && (current == nil || !stored[current.ID] || now.Sub(current.CreatedAt) >= interval) {
+        // 各实例已在使用共享密钥时，新密钥延迟生效；首次启用轮换时立即生效
+        activateAt := now
+        if current != nil && stored[current.ID] {
+            activateAt = now.Add(KeyActivationDelay)
+        }
+        if err := r.rotate(db, kek, activateAt); err != nil {
+            return err
+        }
+        current = r.currentAt(now)
+    }
+
+    // 只有当前密钥用于签名，比它旧的密钥（包括配置文件中的密钥）按宽限期退役
+    for _, key := range r.Keys() {
+        if key.ID != current.ID && key.RetiredAt == nil && !key.CreatedAt.After(current.CreatedAt) {
+            r.Retire(key.ID, now)
+        }
+    }
+    if err := db.Model(&models.SigningKey{}).
+        Where("kid <> ? AND retired_at IS NULL AND created_at <= ?", current.ID, current.CreatedAt).
+        Update("retired_at", now).Error; err != nil {
+        return err
+    }
+
+    if err := db.Where("retired_at < ?", now.Add(-r.gracePeriod)).Delete(&models.SigningKey{}).Error; err != nil {
+        return err
+    }
+    r.Prune(now)
+    return nil
+}
+
+// rotate 生成在 activateAt 生效的新密钥，加密后保存到数据库
+func (r *Keyring) rotate(db *gorm.DB, kek []byte, activateAt time.Time) error {
+    key, err := GenerateKey(r.algorithm, activateAt)
+    if err != nil {
+        return err
+    }
+    material, err := key.Material()
+    if err != nil {
+        return err
+    }
+    material, err = sealMaterial(kek, key.ID, material)
+    if err != nil {
+        return err
+    }
+    if err := db.Create(&models.SigningKey{
+        KID:       key.ID,
+        Algorithm: key.Algorithm,
+        Material:  material,
+        CreatedAt: key.CreatedAt,
+    }).Error; err != nil {
+        return err
+    }
+
+    r.Add(key)
+    log.Printf("已生成新的 JWT 签名密钥 kid=%s，%s 起用于签名", key.ID, activateAt.Format(time.RFC3339))
+    return nil
+}
+
+func (r *Keyring) keyEncryptionKey() []byte {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return r.kek
+}
+
+func (r *Keyring) has(id string) bool {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    _, ok := r.keys[id]
+    return ok
+}
+
+// hasPending 是否有已生成但尚未生效的密钥
+func (r *Keyring) hasPending(now time.Time) bool {
+    for _, key := range r.Keys() {
+        if key.RetiredAt == nil && key.CreatedAt.After(now) {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+    "os"
+    "strconv"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+)
+
+// AdminMiddleware 只允许 ADMIN_USER_IDS（逗号分隔的用户 ID）中的用户访问，需在 AuthMiddleware 之后使用
+func AdminMiddleware() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        userID, exists := c.Get("user_id")
+        if !exists {
+            apierror.Abort(c, apierror.ErrUnauthorized)
+            return
+        }
+
+        if !IsAdmin(userID.(uint)) {
+            apierror.Abort(c, apierror.ErrForbidden.WithMessage("Admin privileges required"))
+            return
+        }
+
+        c.Next()
+    }
+}
+
+// IsAdmin 判断用户是否在 ADMIN_USER_IDS 中
+func IsAdmin(userID uint) bool {
+    for _, field := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+        id, err := strconv.ParseUint(strings.TrimSpace(field), 10, 64)
+        if err == nil && uint(id) == userID {
+            return true
+        }
+    }
+    return false
+}
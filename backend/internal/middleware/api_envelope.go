@@ -0,0 +1,144 @@
+package middleware
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+)
+
+// APIEnvelope 把 JSON 响应改写为 /api/v1 的统一格式：
+// 成功时为 {"data": 原响应, "request_id": ...}，
+// 失败时为 {"error": {"code": 错误码, "message": 本地化提示}, "request_id": ...}。
+// 错误码取自 apierror.Respond 写入的错误，没有时按状态码推断；文件等非 JSON 响应原样返回，
+// 首次写出时即可由 Content-Type 判断，不缓存直接写出，大文件导出不会整个留在内存中
+func APIEnvelope() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        requestID := c.GetString("request_id")
+        if requestID == "" {
+            requestID = assignRequestID(c)
+        }
+
+        original := c.Writer
+        w := &envelopeWriter{ResponseWriter: original, status: http.StatusOK}
+        c.Writer = w
+        c.Next()
+        c.Writer = original
+        if w.passthrough {
+            return
+        }
+
+        body := w.body.Bytes()
+        contentType := original.Header().Get("Content-Type")
+        if !strings.HasPrefix(contentType, gin.MIMEJSON) || !bodyAllowed(w.status) {
+            original.WriteHeader(w.status)
+            original.Write(body)
+            return
+        }
+
+        if w.status < http.StatusBadRequest {
+            c.JSON(w.status, gin.H{"data": json.RawMessage(body), "request_id": requestID})
+            return
+        }
+
+        apiErr, ok := apierror.FromContext(c)
+        if !ok || apiErr.Status != w.status {
+            apiErr = apierror.FromStatus(w.status)
+            var legacy struct {
+                Error string `json:"error"`
+            }
+            if json.Unmarshal(body, &legacy) == nil && legacy.Error != "" {
+                apiErr = apiErr.WithMessage(legacy.Error)
+            }
+        }
+        c.JSON(w.status, gin.H{
+            "error": gin.H{
+                "code":    apiErr.Code,
+                "message": apiErr.Localize(apierror.Language(c)),
+            },
+            "request_id": requestID,
+        })
+    }
+}
+
+// envelopeWriter 缓存处理函数写出的 JSON 状态码和响应体，由 APIEnvelope 改写后再写入原 ResponseWriter；
+// 首次写出时 Content-Type 已确定且不是 JSON 的响应直接写入原 ResponseWriter
+type envelopeWriter struct {
+    gin.ResponseWriter
+    body        bytes.Buffer
+    status      int
+    written     bool
+    passthrough bool
+}
+
+func (w *envelopeWriter) WriteHeader(code int) {
+    if code > 0 && !w.written {
+        w.status = code
+    }
+}
+
+// start 在首次写出时判断是否需要改写响应
+func (w *envelopeWriter) start() {
+    if w.written {
+        return
+    }
+    w.written = true
+    contentType := w.ResponseWriter.Header().Get("Content-Type")
+    if contentType != "" && (!strings.HasPrefix(contentType, gin.MIMEJSON) || !bodyAllowed(w.status)) {
+        w.passthrough = true
+        w.ResponseWriter.WriteHeader(w.status)
+        w.ResponseWriter.WriteHeaderNow()
+    }
+}
+
+func (w *envelopeWriter) WriteHeaderNow() {
+    w.start()
+}
+
+func (w *envelopeWriter) Write(data []byte) (int, error) {
+    w.start()
+    if w.passthrough {
+        return w.ResponseWriter.Write(data)
+    }
+    return w.body.Write(data)
+}
+
+func (w *envelopeWriter) WriteString(s string) (int, error) {
+    w.start()
+    if w.passthrough {
+        return w.ResponseWriter.WriteString(s)
+    }
+    return w.body.WriteString(s)
+}
+
+func (w *envelopeWriter) Status() int {
+    return w.status
+}
+
+func (w *envelopeWriter) Size() int {
+    if w.passthrough {
+        return w.ResponseWriter.Size()
+    }
+    if !w.written {
+        return -1
+    }
+    return w.body.Len()
+}
+
+func (w *envelopeWriter) Written() bool {
+    return w.written
+}
+
+// Flush JSON 响应需要改写后才能发送，缓存期间不刷新；直接写出的响应照常刷新
+func (w *envelopeWriter) Flush() {
+    if w.passthrough {
+        w.ResponseWriter.Flush()
+    }
+}
+
+func bodyAllowed(status int) bool {
+    return status != http.StatusNoContent && status != http.StatusNotModified && (status < 100 || status >= 200)
+}
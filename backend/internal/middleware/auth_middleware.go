@@ -2,10 +2,10 @@ package middleware
 
 import (
     "fmt"
-    "net/http"
     "strconv"
 
     "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils" // 替换为你的 utils 包路径
 )
 
@@ -14,37 +14,62 @@ func AuthMiddleware() gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header missing"})
-            c.Abort()
+            apierror.Abort(c, apierror.ErrInvalidToken.WithMessage("Authorization header missing"))
             return
         }
 
-        // 假设 Bearer <token>
-        var tokenString string
-        _, err := fmt.Sscanf(authHeader, "Bearer %s", &tokenString)
-        if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-            c.Abort()
+        userID, errMsg := parseUserID(authHeader)
+        if errMsg != "" {
+            apierror.Abort(c, apierror.ErrInvalidToken.WithMessage(errMsg))
             return
         }
 
-        claims, err := utils.ValidateToken(tokenString)
-        if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-            c.Abort()
+        // 设置用户 ID 到上下文
+        c.Set("user_id", userID)
+
+        c.Next()
+    }
+}
+
+// OptionalAuthMiddleware 允许匿名访问：没有 Authorization 头时不设置 user_id 直接放行；
+// 提供了令牌但无效时仍返回 401，便于客户端刷新令牌而不是静默降级为匿名
+func OptionalAuthMiddleware() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        authHeader := c.GetHeader("Authorization")
+        if authHeader == "" {
+            c.Next()
             return
         }
 
-        userID, err := strconv.Atoi(claims.Subject)
-        if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token subject"})
-            c.Abort()
+        userID, errMsg := parseUserID(authHeader)
+        if errMsg != "" {
+            apierror.Abort(c, apierror.ErrInvalidToken.WithMessage(errMsg))
             return
         }
 
-        // 设置用户 ID 到上下文
-        c.Set("user_id", uint(userID))
+        c.Set("user_id", userID)
 
         c.Next()
     }
-}
\ No newline at end of file
+}
+
+// parseUserID 从 Authorization 头解析用户 ID，失败时返回错误信息
+func parseUserID(authHeader string) (uint, string) {
+    // 假设 Bearer <token>
+    var tokenString string
+    _, err := fmt.Sscanf(authHeader, "Bearer %s", &tokenString)
+    if err != nil {
+        return 0, "Invalid authorization header format"
+    }
+
+    claims, err := utils.ValidateToken(tokenString)
+    if err != nil {
+        return 0, err.Error()
+    }
+
+    userID, err := strconv.Atoi(claims.Subject)
+    if err != nil {
+        return 0, "Invalid token subject"
+    }
+    return uint(userID), ""
+}
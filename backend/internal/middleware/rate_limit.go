@@ -0,0 +1,65 @@
+package middleware
+
+import (
+    "fmt"
+    "log"
+    "math"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/ratelimit"
+)
+
+// RateLimitMiddleware 按令牌桶限制请求频率，令牌桶保存在 ratelimit.Default() 中，group 区分不同路由组的令牌桶。
+// 已登录用户按用户 ID 计数，匿名请求按 IP 计数（只采信信任的代理转发的地址，见 ConfigureTrustedProxies），需在 AuthMiddleware 或 OptionalAuthMiddleware 之后使用。
+// limit 可用环境变量 RATE_LIMIT_<GROUP> 覆盖，见 ratelimit.LimitFromEnv
+func RateLimitMiddleware(group string, limit ratelimit.Limit) gin.HandlerFunc {
+    return rateLimit(group, ratelimit.LimitFromEnv(group, limit), false)
+}
+
+// AnonymousRateLimitMiddleware 按 IP 限制匿名请求的频率，已登录用户不受限制，需在 OptionalAuthMiddleware 之后使用。
+// limit 可用环境变量 RATE_LIMIT_PUBLIC 覆盖
+func AnonymousRateLimitMiddleware(limit ratelimit.Limit) gin.HandlerFunc {
+    return rateLimit("public", ratelimit.LimitFromEnv("public", limit), true)
+}
+
+func rateLimit(group string, limit ratelimit.Limit, anonymousOnly bool) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var key string
+        if userID, exists := c.Get("user_id"); exists {
+            if anonymousOnly {
+                c.Next()
+                return
+            }
+            key = fmt.Sprintf("%s:user:%v", group, userID)
+        } else {
+            key = fmt.Sprintf("%s:ip:%s", group, c.ClientIP())
+        }
+
+        // 存储后端不可用时放行，不因限流影响正常请求
+        result, err := ratelimit.Default().Take(c.Request.Context(), key, limit, time.Now())
+        if err != nil {
+            log.Printf("限流存储后端出错，放行请求: %v", err)
+            c.Next()
+            return
+        }
+
+        c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+        c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+        c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+        if !result.Allowed {
+            c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+            apierror.Abort(c, apierror.ErrTooManyRequests)
+            return
+        }
+
+        c.Next()
+    }
+}
+
+// ceilSeconds 向上取整到秒
+func ceilSeconds(d time.Duration) int {
+    return int(math.Ceil(d.Seconds()))
+}
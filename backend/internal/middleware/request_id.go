@@ -0,0 +1,43 @@
+package middleware
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "regexp"
+
+    "github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader 请求 ID 的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// 只接受由字母、数字和 -_. 组成的请求 ID，避免把任意内容写入日志和响应头
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID 为每个请求分配请求 ID：沿用客户端或网关传入的合法 X-Request-ID，否则随机生成。
+// 请求 ID 保存在上下文的 request_id 中并写入响应头
+func RequestID() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        assignRequestID(c)
+        c.Next()
+    }
+}
+
+// assignRequestID 设置请求 ID 并返回
+func assignRequestID(c *gin.Context) string {
+    requestID := c.GetHeader(RequestIDHeader)
+    if !requestIDPattern.MatchString(requestID) {
+        requestID = newRequestID()
+    }
+    c.Set("request_id", requestID)
+    c.Header(RequestIDHeader, requestID)
+    return requestID
+}
+
+func newRequestID() string {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        panic("failed to generate request ID: " + err.Error())
+    }
+    return hex.EncodeToString(b)
+}
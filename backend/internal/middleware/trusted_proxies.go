@@ -0,0 +1,27 @@
+package middleware
+
+import (
+    "os"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+)
+
+// ConfigureTrustedProxies 按环境变量设置 router 信任的反向代理。c.ClientIP() 只采信这些代理转发的
+// X-Forwarded-For / X-Real-IP，否则客户端伪造请求头即可在按 IP 限流时每次换一个令牌桶。
+// TRUSTED_PROXIES 为逗号分隔的 IP 或 CIDR，如 nginx 所在的 127.0.0.1,10.0.0.0/8；
+// TRUSTED_PLATFORM 为托管平台写入客户端 IP 的请求头，如 Cloudflare 的 CF-Connecting-IP。
+// 都未设置时不信任任何代理，使用连接的对端地址
+func ConfigureTrustedProxies(router *gin.Engine) error {
+    var proxies []string
+    for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+        if proxy = strings.TrimSpace(proxy); proxy != "" {
+            proxies = append(proxies, proxy)
+        }
+    }
+    if err := router.SetTrustedProxies(proxies); err != nil {
+        return err
+    }
+    router.TrustedPlatform = strings.TrimSpace(os.Getenv("TRUSTED_PLATFORM"))
+    return nil
+}
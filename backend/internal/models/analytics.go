@@ -0,0 +1,91 @@
+// models/analytics.go
+package models
+
+import (
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// StatDateLayout 日汇总的日期格式，按服务器本地时区划分自然日
+const StatDateLayout = "2006-01-02"
+
+// 新闻日汇总中的指标列
+const (
+    StatViews     = "views"
+    StatLikes     = "likes"
+    StatFavorites = "favorites"
+    StatShares    = "shares"
+    StatComments  = "comments"
+)
+
+// NewsStatColumns 可按日汇总和排序的新闻指标
+var NewsStatColumns = []string{StatViews, StatLikes, StatFavorites, StatShares, StatComments}
+
+// NewsDailyStat 新闻每天的互动汇总，在互动发生时累加，统计接口只读汇总表。
+// 点赞和收藏记录当天的净增量，取消时从取消当天扣除，因此可能为负
+type NewsDailyStat struct {
+    NewsID    uint   `gorm:"primaryKey" json:"news_id"`
+    Date      string `gorm:"primaryKey;size:10" json:"date"`
+    AuthorID  uint   `gorm:"not null;index:idx_news_daily_stats_author_date" json:"author_id"`
+    Views     int    `gorm:"default:0" json:"views"`
+    Likes     int    `gorm:"default:0" json:"likes"`
+    Favorites int    `gorm:"default:0" json:"favorites"`
+    Shares    int    `gorm:"default:0" json:"shares"`
+    Comments  int    `gorm:"default:0" json:"comments"`
+}
+
+// AuthorDailyStat 作者每天新增和流失的关注者
+type AuthorDailyStat struct {
+    AuthorID      uint   `gorm:"primaryKey" json:"author_id"`
+    Date          string `gorm:"primaryKey;size:10" json:"date"`
+    NewFollowers  int    `gorm:"default:0" json:"new_followers"`
+    LostFollowers int    `gorm:"default:0" json:"lost_followers"`
+}
+
+// StatDate 返回时间所在的汇总日期
+func StatDate(t time.Time) string {
+    return t.Local().Format(StatDateLayout)
+}
+
+// RecordNewsStat 将新闻某项指标在 at 当天的汇总累加 delta
+func RecordNewsStat(db *gorm.DB, newsID, authorID uint, column string, delta int, at time.Time) error {
+    stat := NewsDailyStat{NewsID: newsID, Date: StatDate(at), AuthorID: authorID}
+    switch column {
+    case StatViews:
+        stat.Views = delta
+    case StatLikes:
+        stat.Likes = delta
+    case StatFavorites:
+        stat.Favorites = delta
+    case StatShares:
+        stat.Shares = delta
+    case StatComments:
+        stat.Comments = delta
+    default:
+        return fmt.Errorf("unknown news stat %q", column)
+    }
+    return db.Clauses(clause.OnConflict{
+        Columns:   []clause.Column{{Name: "news_id"}, {Name: "date"}},
+        DoUpdates: clause.Assignments(map[string]interface{}{column: gorm.Expr(column+" + ?", delta)}),
+    }).Create(&stat).Error
+}
+
+// RecordFollowerStat 记录作者在 at 当天新增（delta > 0）或流失（delta < 0）的关注者
+func RecordFollowerStat(db *gorm.DB, authorID uint, delta int, at time.Time) error {
+    stat := AuthorDailyStat{AuthorID: authorID, Date: StatDate(at)}
+    column := "new_followers"
+    if delta > 0 {
+        stat.NewFollowers = delta
+    } else {
+        column = "lost_followers"
+        delta = -delta
+        stat.LostFollowers = delta
+    }
+    return db.Clauses(clause.OnConflict{
+        Columns:   []clause.Column{{Name: "author_id"}, {Name: "date"}},
+        DoUpdates: clause.Assignments(map[string]interface{}{column: gorm.Expr(column+" + ?", delta)}),
+    }).Create(&stat).Error
+}
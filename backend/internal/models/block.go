@@ -0,0 +1,35 @@
+// models/block.go
+package models
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// UserBlock 用户屏蔽关系：屏蔽者看不到被屏蔽用户的新闻和评论，被屏蔽用户不能评论屏蔽者的新闻、回复或 @ 屏蔽者
+type UserBlock struct {
+    BlockerID uint      `gorm:"primaryKey" json:"blocker_id"`
+    BlockedID uint      `gorm:"primaryKey;index" json:"blocked_id"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// NotBlockedBy 过滤掉 viewerID 屏蔽的用户发布的内容，column 为内容作者 ID 所在的列；匿名访问（viewerID 为 0）时不过滤
+func NotBlockedBy(viewerID uint, column string) func(*gorm.DB) *gorm.DB {
+    return func(db *gorm.DB) *gorm.DB {
+        if viewerID == 0 {
+            return db
+        }
+        return db.Where(column+" NOT IN (SELECT blocked_id FROM user_blocks WHERE blocker_id = ?)", viewerID)
+    }
+}
+
+// IsBlocked 判断 blockerID 是否屏蔽了 blockedID
+func IsBlocked(db *gorm.DB, blockerID, blockedID uint) (bool, error) {
+    if blockerID == 0 || blockedID == 0 {
+        return false, nil
+    }
+    var count int64
+    err := db.Model(&UserBlock{}).Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Count(&count).Error
+    return count > 0, err
+}
@@ -0,0 +1,37 @@
+// models/collection.go
+package models
+
+import (
+    "time"
+)
+
+// 收藏夹中条目的类型
+const (
+    CollectionItemNews   = "news"
+    CollectionItemRecipe = "recipe"
+)
+
+// MaxCollectionNameLength 收藏夹名称的最大字数
+const MaxCollectionNameLength = 50
+
+// Collection 用户的收藏夹，可以同时收藏新闻和食谱；公开的收藏夹其他用户也能查看
+type Collection struct {
+    ID          uint      `gorm:"primaryKey" json:"id"`
+    UserID      uint      `gorm:"not null;uniqueIndex:idx_collections_user_name" json:"user_id"`
+    Name        string    `gorm:"size:50;not null;uniqueIndex:idx_collections_user_name" json:"name"`
+    Description string    `gorm:"size:200" json:"description"`
+    IsPublic    bool      `gorm:"default:false" json:"is_public"`
+    Position    int       `gorm:"not null;default:0" json:"position"` // 在用户收藏夹列表中的顺序，从小到大
+    CreatedAt   time.Time `json:"created_at"`
+    UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CollectionItem 收藏夹中的一条新闻或食谱，同一收藏夹内不重复
+type CollectionItem struct {
+    ID           uint      `gorm:"primaryKey" json:"id"`
+    CollectionID uint      `gorm:"not null;uniqueIndex:idx_collection_items_item" json:"collection_id"`
+    ItemType     string    `gorm:"size:20;not null;uniqueIndex:idx_collection_items_item" json:"item_type"`
+    ItemID       uint      `gorm:"not null;uniqueIndex:idx_collection_items_item;index" json:"item_id"`
+    Position     int       `gorm:"not null;default:0" json:"position"` // 在收藏夹中的顺序，从小到大
+    CreatedAt    time.Time `json:"created_at"`
+}
@@ -11,14 +11,19 @@ type Comment struct {
     PublishTime time.Time `json:"publish_time"`
     LikeCount  int       `json:"like_count"`
     UserID     uint      `json:"user_id"`
-    NewsID     uint      `json:"news_id"`
+    NewsID     uint      `gorm:"index:idx_comments_news_parent" json:"news_id"`
     Replies    []Comment `gorm:"foreignKey:ParentID" json:"replies"`
-    ParentID   *uint     `json:"parent_id"`
+    ParentID   *uint     `gorm:"index:idx_comments_news_parent;index:idx_comments_parent" json:"parent_id"`
     IsReply    bool      `json:"is_reply"`
+    EditedAt   *time.Time `json:"edited_at"` // 最近一次编辑的时间，未编辑过为空
+    IsDeleted  bool      `gorm:"default:false" json:"is_deleted"` // 已删除但仍有回复的评论保留为占位，以维持楼层结构
     Author      User      `gorm:"foreignKey:UserID" json:"author"`  // 绑定 User 类型的 Author 字段
 
     LikedByUsers []User `gorm:"many2many:user_likes_comments;" json:"-"`
 
     // 是否已点赞（不存数据库）
     DidLike  bool  `gorm:"-" json:"did_like"`
-}
\ No newline at end of file
+}
+
+// DeletedCommentContent 已删除评论的占位内容
+const DeletedCommentContent = "comment deleted"
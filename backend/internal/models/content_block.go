@@ -0,0 +1,313 @@
+// models/content_block.go
+package models
+
+import (
+    "errors"
+    "fmt"
+    "math"
+    "strings"
+    "unicode/utf8"
+
+    "gorm.io/gorm"
+)
+
+// 正文块类型
+const (
+    BlockText    = "text"    // 普通段落
+    BlockHeading = "heading" // 小标题
+    BlockImage   = "image"   // 图片
+    BlockQuote   = "quote"   // 引用
+    BlockRecipe  = "recipe"  // 嵌入食谱卡片
+    BlockFood    = "food"    // 嵌入食物及其碳排放
+)
+
+// 正文块的校验限制
+const (
+    MaxContentBlocks    = 200
+    MaxBlockTextLength  = 10000
+    MaxHeadingLength    = 200
+    MaxFoodBlockWeight  = 100.0 // 单位：kg
+    DefaultHeadingLevel = 2
+)
+
+// ContentBlock 正文中的一个有序内容块，不同类型使用不同字段
+type ContentBlock struct {
+    Type        string  `gorm:"size:20;not null" json:"type"`
+    Position    int     `gorm:"not null" json:"position"`
+    Text        string  `gorm:"type:text" json:"text,omitempty"`        // text / heading / quote
+    Level       int     `json:"level,omitempty"`                        // heading 级别 1-3
+    Source      string  `gorm:"size:255" json:"source,omitempty"`       // quote 出处
+    URL         string  `gorm:"size:255" json:"url,omitempty"`          // image
+    Description string  `gorm:"type:text" json:"description,omitempty"` // image 描述
+    RecipeID    *uint   `json:"recipe_id,omitempty"`                    // recipe
+    FoodID      *uint   `json:"food_id,omitempty"`                      // food
+    Weight      float64 `json:"weight,omitempty"`                       // food 重量，单位：kg
+}
+
+// NewsBlock 新闻正文块
+type NewsBlock struct {
+    ID           uint `gorm:"primaryKey" json:"id"`
+    NewsID       uint `gorm:"not null;index" json:"news_id"`
+    ContentBlock `gorm:"embedded"`
+}
+
+// DraftBlock 草稿正文块
+type DraftBlock struct {
+    ID           uint `gorm:"primaryKey" json:"id"`
+    DraftID      uint `gorm:"not null;index" json:"draft_id"`
+    ContentBlock `gorm:"embedded"`
+}
+
+// BlockError 正文块校验失败，Index 为出错块的下标（-1 表示针对整个正文）
+type BlockError struct {
+    Index  int
+    Reason string
+}
+
+func (e *BlockError) Error() string {
+    if e.Index < 0 {
+        return e.Reason
+    }
+    return fmt.Sprintf("Block %d: %s", e.Index, e.Reason)
+}
+
+// OrderedBlocks 用于 Preload("Blocks", OrderedBlocks)，按位置顺序加载正文块
+func OrderedBlocks(db *gorm.DB) *gorm.DB {
+    return db.Order("position ASC")
+}
+
+// NormalizeBlocks 校验正文块并返回规范化后的副本：去除首尾空白、清空与类型无关的字段、重新编号位置
+func NormalizeBlocks(blocks []ContentBlock) ([]ContentBlock, error) {
+    if len(blocks) > MaxContentBlocks {
+        return nil, &BlockError{Index: -1, Reason: fmt.Sprintf("Too many content blocks (max %d)", MaxContentBlocks)}
+    }
+
+    normalized := make([]ContentBlock, 0, len(blocks))
+    for i, b := range blocks {
+        n, err := normalizeBlock(b)
+        if err != nil {
+            return nil, &BlockError{Index: i, Reason: err.Error()}
+        }
+        n.Position = i
+        normalized = append(normalized, n)
+    }
+    return normalized, nil
+}
+
+func normalizeBlock(b ContentBlock) (ContentBlock, error) {
+    switch b.Type {
+    case BlockText:
+        text := strings.TrimSpace(b.Text)
+        if err := checkBlockText(text, MaxBlockTextLength); err != nil {
+            return ContentBlock{}, err
+        }
+        return ContentBlock{Type: BlockText, Text: text}, nil
+
+    case BlockHeading:
+        text := strings.TrimSpace(b.Text)
+        if err := checkBlockText(text, MaxHeadingLength); err != nil {
+            return ContentBlock{}, err
+        }
+        level := b.Level
+        if level == 0 {
+            level = DefaultHeadingLevel
+        }
+        if level < 1 || level > 3 {
+            return ContentBlock{}, errors.New("heading level must be between 1 and 3")
+        }
+        return ContentBlock{Type: BlockHeading, Text: text, Level: level}, nil
+
+    case BlockQuote:
+        text := strings.TrimSpace(b.Text)
+        if err := checkBlockText(text, MaxBlockTextLength); err != nil {
+            return ContentBlock{}, err
+        }
+        source := strings.TrimSpace(b.Source)
+        if utf8.RuneCountInString(source) > 255 {
+            return ContentBlock{}, errors.New("quote source is too long")
+        }
+        return ContentBlock{Type: BlockQuote, Text: text, Source: source}, nil
+
+    case BlockImage:
+        url := strings.TrimSpace(b.URL)
+        if url == "" {
+            return ContentBlock{}, errors.New("image url is required")
+        }
+        if len(url) > 255 {
+            return ContentBlock{}, errors.New("image url is too long")
+        }
+        return ContentBlock{Type: BlockImage, URL: url, Description: b.Description}, nil
+
+    case BlockRecipe:
+        if b.RecipeID == nil || *b.RecipeID == 0 {
+            return ContentBlock{}, errors.New("recipe_id is required")
+        }
+        id := *b.RecipeID
+        return ContentBlock{Type: BlockRecipe, RecipeID: &id}, nil
+
+    case BlockFood:
+        if b.FoodID == nil || *b.FoodID == 0 {
+            return ContentBlock{}, errors.New("food_id is required")
+        }
+        if b.Weight <= 0 || b.Weight > MaxFoodBlockWeight || math.IsNaN(b.Weight) {
+            return ContentBlock{}, fmt.Errorf("food weight must be greater than 0 and at most %g kg", MaxFoodBlockWeight)
+        }
+        id := *b.FoodID
+        return ContentBlock{Type: BlockFood, FoodID: &id, Weight: b.Weight}, nil
+
+    default:
+        return ContentBlock{}, fmt.Errorf("unknown block type %q", b.Type)
+    }
+}
+
+func checkBlockText(text string, limit int) error {
+    if text == "" {
+        return errors.New("text is required")
+    }
+    if utf8.RuneCountInString(text) > limit {
+        return fmt.Errorf("text exceeds %d characters", limit)
+    }
+    return nil
+}
+
+// BlocksFromLegacy 由旧的段落和图片列表生成正文块：旧数据没有记录相对位置，段落在前，图片依次排在其后
+func BlocksFromLegacy(paragraphs []string, images []RevisionImage) []ContentBlock {
+    blocks := make([]ContentBlock, 0, len(paragraphs)+len(images))
+    for _, text := range paragraphs {
+        blocks = append(blocks, ContentBlock{Type: BlockText, Position: len(blocks), Text: text})
+    }
+    for _, img := range images {
+        blocks = append(blocks, ContentBlock{Type: BlockImage, Position: len(blocks), URL: img.URL, Description: img.Description})
+    }
+    return blocks
+}
+
+// ContentFromBlocks 由正文块生成完整的正文内容，同时推导出供旧客户端使用的段落和图片列表
+func ContentFromBlocks(blocks []ContentBlock) RevisionContent {
+    content := RevisionContent{
+        Paragraphs: []string{},
+        Images:     []RevisionImage{},
+        Blocks:     blocks,
+    }
+    for _, b := range blocks {
+        switch b.Type {
+        case BlockText, BlockHeading, BlockQuote:
+            content.Paragraphs = append(content.Paragraphs, b.Text)
+        case BlockImage:
+            content.Images = append(content.Images, RevisionImage{URL: b.URL, Description: b.Description})
+        }
+    }
+    return content
+}
+
+// WithBlocks 返回带有正文块的内容，旧快照中没有正文块时由段落和图片生成
+func (content RevisionContent) WithBlocks() RevisionContent {
+    if len(content.Blocks) == 0 {
+        content.Blocks = BlocksFromLegacy(content.Paragraphs, content.Images)
+    }
+    return content
+}
+
+// DraftBlocksOf 将正文块转换为草稿正文块记录
+func DraftBlocksOf(blocks []ContentBlock) []DraftBlock {
+    rows := make([]DraftBlock, 0, len(blocks))
+    for _, b := range blocks {
+        rows = append(rows, DraftBlock{ContentBlock: b})
+    }
+    return rows
+}
+
+// NewsBlocksOf 将正文块转换为新闻正文块记录
+func NewsBlocksOf(blocks []ContentBlock) []NewsBlock {
+    rows := make([]NewsBlock, 0, len(blocks))
+    for _, b := range blocks {
+        rows = append(rows, NewsBlock{ContentBlock: b})
+    }
+    return rows
+}
+
+// FoodEmission 计算指定重量（kg）食物的碳排放，保留一位小数
+func FoodEmission(food *Food, weight float64) float64 {
+    return math.Round(food.GHG*weight*10) / 10
+}
+
+// RecipeEmission 按配料表计算食谱的碳排放（配料重量单位为克），需预加载 Foods，找不到对应食物的配料不计入
+func RecipeEmission(recipe *Recipe) float64 {
+    ingredients, err := recipe.GetIngredients()
+    if err != nil {
+        return 0
+    }
+
+    ghgByName := make(map[string]float64, len(recipe.Foods))
+    for _, food := range recipe.Foods {
+        ghgByName[strings.ToLower(food.EnFoodName)] = food.GHG
+        ghgByName[food.ZhFoodName] = food.GHG
+    }
+
+    total := 0.0
+    for name, grams := range ingredients {
+        if ghg, ok := ghgByName[strings.ToLower(name)]; ok {
+            total += ghg * grams / 1000
+        }
+    }
+    return math.Round(total*10) / 10
+}
+
+// MigrateLegacyBlocks 为尚无正文块的新闻和草稿由旧段落和图片生成正文块，可重复执行，返回迁移的新闻数和草稿数
+func MigrateLegacyBlocks(db *gorm.DB) (int, int, error) {
+    var newsIDs []uint
+    if err := db.Model(&News{}).
+        Where("NOT EXISTS (SELECT 1 FROM news_blocks WHERE news_blocks.news_id = news.id)").
+        Pluck("id", &newsIDs).Error; err != nil {
+        return 0, 0, err
+    }
+
+    migratedNews := 0
+    for _, id := range newsIDs {
+        var news News
+        if err := db.Preload("Paragraphs").Preload("Images").First(&news, id).Error; err != nil {
+            return migratedNews, 0, err
+        }
+        content := ContentOfNews(&news)
+        blocks := NewsBlocksOf(BlocksFromLegacy(content.Paragraphs, content.Images))
+        if len(blocks) == 0 {
+            continue
+        }
+        for i := range blocks {
+            blocks[i].NewsID = news.ID
+        }
+        if err := db.Create(&blocks).Error; err != nil {
+            return migratedNews, 0, err
+        }
+        migratedNews++
+    }
+
+    var draftIDs []uint
+    if err := db.Model(&Draft{}).
+        Where("NOT EXISTS (SELECT 1 FROM draft_blocks WHERE draft_blocks.draft_id = drafts.id)").
+        Pluck("id", &draftIDs).Error; err != nil {
+        return migratedNews, 0, err
+    }
+
+    migratedDrafts := 0
+    for _, id := range draftIDs {
+        var draft Draft
+        if err := db.Preload("Paragraphs").Preload("Images").First(&draft, id).Error; err != nil {
+            return migratedNews, migratedDrafts, err
+        }
+        content := ContentOfDraft(&draft)
+        blocks := DraftBlocksOf(BlocksFromLegacy(content.Paragraphs, content.Images))
+        if len(blocks) == 0 {
+            continue
+        }
+        for i := range blocks {
+            blocks[i].DraftID = draft.ID
+        }
+        if err := db.Create(&blocks).Error; err != nil {
+            return migratedNews, migratedDrafts, err
+        }
+        migratedDrafts++
+    }
+
+    return migratedNews, migratedDrafts, nil
+}
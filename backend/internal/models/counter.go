@@ -0,0 +1,146 @@
+// models/counter.go
+package models
+
+import (
+    "fmt"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// CounterRelation 描述一种“用户-对象”关联及其在对象表中的计数列。
+// 计数只在关联行实际插入或删除时通过 SQL 原子增减，重复或并发请求不会让计数偏离关联表
+type CounterRelation struct {
+    Name       string // 用于日志和报告
+    JoinTable  string // 关联表
+    ForeignKey string // 关联表中对象 ID 的列名
+    Table      string // 对象表
+    Column     string // 计数列
+}
+
+var (
+    NewsLikes     = CounterRelation{Name: "news likes", JoinTable: "user_likes_news", ForeignKey: "news_id", Table: "news", Column: "like_count"}
+    NewsFavorites = CounterRelation{Name: "news favorites", JoinTable: "user_favorites_news", ForeignKey: "news_id", Table: "news", Column: "favorite_count"}
+    NewsDislikes  = CounterRelation{Name: "news dislikes", JoinTable: "user_dislikes_news", ForeignKey: "news_id", Table: "news", Column: "dislike_count"}
+    CommentLikes  = CounterRelation{Name: "comment likes", JoinTable: "user_likes_comments", ForeignKey: "comment_id", Table: "comments", Column: "like_count"}
+    NewsViews     = CounterRelation{Name: "news views", JoinTable: "user_viewed_news", ForeignKey: "news_id", Table: "news", Column: "view_count"}
+
+    // CounterRelations 所有可以由关联表重新计算的计数。浏览量统计的是浏览次数而非浏览人数，NewsViews 不在其中
+    CounterRelations = []CounterRelation{NewsLikes, NewsFavorites, NewsDislikes, CommentLikes}
+)
+
+// 关联表的行，复合主键用于冲突检测
+type newsRelationRow struct {
+    UserID uint `gorm:"primaryKey"`
+    NewsID uint `gorm:"primaryKey"`
+}
+
+type commentRelationRow struct {
+    UserID    uint `gorm:"primaryKey"`
+    CommentID uint `gorm:"primaryKey"`
+}
+
+func (r CounterRelation) row(userID, targetID uint) interface{} {
+    if r.ForeignKey == "comment_id" {
+        return &commentRelationRow{UserID: userID, CommentID: targetID}
+    }
+    return &newsRelationRow{UserID: userID, NewsID: targetID}
+}
+
+// Link 插入关联行，关联已存在时返回 false
+func (r CounterRelation) Link(tx *gorm.DB, userID, targetID uint) (bool, error) {
+    result := tx.Table(r.JoinTable).Clauses(clause.OnConflict{DoNothing: true}).Create(r.row(userID, targetID))
+    return result.RowsAffected > 0, result.Error
+}
+
+// Unlink 删除关联行，关联不存在时返回 false
+func (r CounterRelation) Unlink(tx *gorm.DB, userID, targetID uint) (bool, error) {
+    result := tx.Table(r.JoinTable).Delete(r.row(userID, targetID))
+    return result.RowsAffected > 0, result.Error
+}
+
+// Lock 锁定关联行直到事务结束，关联行不存在时先插入。
+// 同一用户对同一对象的并发请求在此串行执行，可以安全地先检查再写入
+func (r CounterRelation) Lock(tx *gorm.DB, userID, targetID uint) error {
+    if _, err := r.Link(tx, userID, targetID); err != nil {
+        return err
+    }
+    row := r.row(userID, targetID)
+    return tx.Table(r.JoinTable).Clauses(clause.Locking{Strength: "UPDATE"}).Take(row).Error
+}
+
+// Adjust 原子地增减计数并返回更新后的值，计数不会减到 0 以下
+func (r CounterRelation) Adjust(tx *gorm.DB, targetID uint, delta int) (int, error) {
+    query := tx.Table(r.Table).Where("id = ?", targetID)
+    if delta < 0 {
+        query = query.Where(r.Column+" >= ?", -delta)
+    }
+    if err := query.UpdateColumn(r.Column, gorm.Expr(r.Column+" + ?", delta)).Error; err != nil {
+        return 0, err
+    }
+    var counts []int
+    if err := tx.Table(r.Table).Where("id = ?", targetID).Pluck(r.Column, &counts).Error; err != nil {
+        return 0, err
+    }
+    if len(counts) == 0 {
+        return 0, gorm.ErrRecordNotFound
+    }
+    return counts[0], nil
+}
+
+// CounterDrift 计数与关联表不一致的一行
+type CounterDrift struct {
+    Counter string `json:"counter"`
+    ID      uint   `json:"id"`
+    Stored  int    `json:"stored"` // 对象表中记录的计数
+    Actual  int    `json:"actual"` // 关联表中的行数
+}
+
+func (d CounterDrift) String() string {
+    return fmt.Sprintf("%s #%d: stored %d, actual %d (%+d)", d.Counter, d.ID, d.Stored, d.Actual, d.Stored-d.Actual)
+}
+
+// Reconcile 找出计数与关联表行数不一致的对象；fix 为 true 时按关联表重新计算这些计数
+func (r CounterRelation) Reconcile(db *gorm.DB, fix bool) ([]CounterDrift, error) {
+    var rows []struct {
+        ID     uint
+        Stored int
+        Actual int
+    }
+    err := db.Raw(fmt.Sprintf(`
+        SELECT t.id AS id, t.%[1]s AS stored, COUNT(j.user_id) AS actual
+        FROM %[2]s t LEFT JOIN %[3]s j ON j.%[4]s = t.id
+        GROUP BY t.id, t.%[1]s
+        HAVING t.%[1]s <> COUNT(j.user_id)
+        ORDER BY t.id`, r.Column, r.Table, r.JoinTable, r.ForeignKey)).Scan(&rows).Error
+    if err != nil {
+        return nil, err
+    }
+
+    drifts := make([]CounterDrift, 0, len(rows))
+    for _, row := range rows {
+        drifts = append(drifts, CounterDrift{Counter: r.Name, ID: row.ID, Stored: row.Stored, Actual: row.Actual})
+        if !fix {
+            continue
+        }
+        // 更新时重新计数，扫描之后发生的点赞也会被计入
+        if err := db.Exec(fmt.Sprintf("UPDATE %[1]s SET %[2]s = (SELECT COUNT(*) FROM %[3]s WHERE %[3]s.%[4]s = %[1]s.id) WHERE id = ?",
+            r.Table, r.Column, r.JoinTable, r.ForeignKey), row.ID).Error; err != nil {
+            return drifts, err
+        }
+    }
+    return drifts, nil
+}
+
+// ReconcileCounters 检查（并可选修复）所有计数
+func ReconcileCounters(db *gorm.DB, fix bool) ([]CounterDrift, error) {
+    var drifts []CounterDrift
+    for _, r := range CounterRelations {
+        d, err := r.Reconcile(db, fix)
+        drifts = append(drifts, d...)
+        if err != nil {
+            return drifts, fmt.Errorf("reconcile %s: %w", r.Name, err)
+        }
+    }
+    return drifts, nil
+}
@@ -0,0 +1,151 @@
+// internal/models/dietary_profile.go
+package models
+
+import (
+    "strings"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// DietaryOption 饮食档案中可选的一项，Foods 为对应的食材英文名（小写，与 foods.en_food_name 比较时忽略大小写）
+type DietaryOption struct {
+    Code   string   `json:"code"`
+    NameEn string   `json:"name_en"`
+    NameZh string   `json:"name_zh-Hans"`
+    Foods  []string `json:"foods,omitempty"`
+}
+
+// Allergens 过敏原，推荐食材和菜谱时作为硬性约束，含有过敏原的食材和菜谱一律不返回
+var Allergens = []DietaryOption{
+    {Code: "gluten", NameEn: "gluten", NameZh: "麸质", Foods: []string{"wheat", "barley", "flour", "bread", "noodles", "pasta/spaghetti", "crumbs/breadcrumbs", "oat", "oatmeal", "corn flakes", "beer"}},
+    {Code: "dairy", NameEn: "dairy", NameZh: "乳制品", Foods: []string{"milk", "cheese", "butter", "cream"}},
+    {Code: "egg", NameEn: "egg", NameZh: "鸡蛋", Foods: []string{"egg"}},
+    {Code: "peanut", NameEn: "peanut", NameZh: "花生", Foods: []string{"peanut", "peanut butter"}},
+    {Code: "tree_nuts", NameEn: "tree nuts", NameZh: "坚果", Foods: []string{"walnut", "chestnut", "pistachios", "hazelnut", "cashew", "almond"}},
+    {Code: "soy", NameEn: "soy", NameZh: "大豆", Foods: []string{"soybean", "bean curd/tofu", "soybean powder"}},
+    {Code: "fish", NameEn: "fish", NameZh: "鱼类", Foods: []string{"fish", "canned tuna", "bream", "cod", "salmon", "catfish"}},
+    {Code: "shellfish", NameEn: "shellfish", NameZh: "甲壳类和贝类", Foods: []string{"shrimp", "prawn", "crab", "lobster", "crayfish", "scallops", "mussel", "oyster", "squid", "octopus"}},
+    {Code: "sesame", NameEn: "sesame", NameZh: "芝麻", Foods: []string{"sesame"}},
+}
+
+var meatFoods = []string{"pork", "pork liver", "bacon", "ham", "sausage", "beef", "lamb/mutton", "chicken", "duck meat", "goose",
+    "fish", "canned tuna", "bream", "cod", "salmon", "catfish",
+    "shrimp", "prawn", "crab", "lobster", "crayfish", "scallops", "mussel", "oyster", "squid", "octopus"}
+
+// DietaryRestrictions 宗教或伦理上的饮食限制，推荐时排除对应食材
+var DietaryRestrictions = []DietaryOption{
+    {Code: "halal", NameEn: "halal", NameZh: "清真", Foods: []string{"pork", "pork liver", "bacon", "ham", "sausage", "wine", "beer"}},
+    {Code: "vegetarian", NameEn: "vegetarian", NameZh: "素食", Foods: meatFoods},
+    {Code: "vegan", NameEn: "vegan", NameZh: "纯素", Foods: append(append([]string{}, meatFoods...), "egg", "milk", "cheese", "butter", "cream", "honey")},
+}
+
+// 烹饪水平
+var CookingSkills = []DietaryOption{
+    {Code: "beginner", NameEn: "beginner", NameZh: "新手"},
+    {Code: "intermediate", NameEn: "intermediate", NameZh: "熟练"},
+    {Code: "advanced", NameEn: "advanced", NameZh: "精通"},
+}
+
+// 厨房设备
+var KitchenEquipment = []DietaryOption{
+    {Code: "stove", NameEn: "stove", NameZh: "灶台"},
+    {Code: "oven", NameEn: "oven", NameZh: "烤箱"},
+    {Code: "microwave", NameEn: "microwave", NameZh: "微波炉"},
+    {Code: "rice_cooker", NameEn: "rice cooker", NameZh: "电饭煲"},
+    {Code: "steamer", NameEn: "steamer", NameZh: "蒸锅"},
+    {Code: "air_fryer", NameEn: "air fryer", NameZh: "空气炸锅"},
+    {Code: "blender", NameEn: "blender", NameZh: "料理机"},
+    {Code: "grill", NameEn: "grill", NameZh: "烤架"},
+}
+
+// DietaryProfile 用户的结构化饮食档案，没有记录时各项为空
+type DietaryProfile struct {
+    UserID           uint      `gorm:"primaryKey" json:"-"`
+    Allergens        []string  `gorm:"serializer:json;type:text" json:"allergens"`
+    Restrictions     []string  `gorm:"serializer:json;type:text" json:"restrictions"`
+    CookingSkill     string    `gorm:"size:20" json:"cooking_skill"`
+    KitchenEquipment []string  `gorm:"serializer:json;type:text" json:"kitchen_equipment"`
+    MealBudget       float64   `json:"meal_budget"` // 每餐默认预算，单位：元，0 表示不限
+    UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ValidDietaryCodes 判断 codes 是否都在 options 中
+func ValidDietaryCodes(options []DietaryOption, codes []string) bool {
+    for _, code := range codes {
+        if findDietaryOption(options, code) == nil {
+            return false
+        }
+    }
+    return true
+}
+
+func findDietaryOption(options []DietaryOption, code string) *DietaryOption {
+    for i := range options {
+        if options[i].Code == code {
+            return &options[i]
+        }
+    }
+    return nil
+}
+
+// dietaryFoods 返回 codes 对应的食材名，去重
+func dietaryFoods(options []DietaryOption, codes []string) []string {
+    seen := make(map[string]bool)
+    var foods []string
+    for _, code := range codes {
+        option := findDietaryOption(options, code)
+        if option == nil {
+            continue
+        }
+        for _, food := range option.Foods {
+            if !seen[food] {
+                seen[food] = true
+                foods = append(foods, food)
+            }
+        }
+    }
+    return foods
+}
+
+// GetDietaryProfile 读取用户的饮食档案，没有记录时返回空档案
+func GetDietaryProfile(db *gorm.DB, userID uint) (DietaryProfile, error) {
+    profile := DietaryProfile{UserID: userID}
+    if err := db.Where("user_id = ?", userID).Limit(1).Find(&profile).Error; err != nil {
+        return DietaryProfile{}, err
+    }
+    if profile.Allergens == nil {
+        profile.Allergens = []string{}
+    }
+    if profile.Restrictions == nil {
+        profile.Restrictions = []string{}
+    }
+    if profile.KitchenEquipment == nil {
+        profile.KitchenEquipment = []string{}
+    }
+    return profile, nil
+}
+
+// findFoodIDsByNames 按英文名（忽略大小写）查找食材 ID
+func findFoodIDsByNames(db *gorm.DB, names []string) ([]uint, error) {
+    ids := []uint{}
+    if len(names) == 0 {
+        return ids, nil
+    }
+    lower := make([]string, len(names))
+    for i, name := range names {
+        lower[i] = strings.ToLower(name)
+    }
+    err := db.Model(&Food{}).Where("LOWER(en_food_name) IN ?", lower).Pluck("id", &ids).Error
+    return ids, err
+}
+
+// AllergenFoodIDs 返回用户过敏的食材 ID
+func (p DietaryProfile) AllergenFoodIDs(db *gorm.DB) ([]uint, error) {
+    return findFoodIDsByNames(db, dietaryFoods(Allergens, p.Allergens))
+}
+
+// RestrictedFoodIDs 返回饮食限制排除的食材 ID
+func (p DietaryProfile) RestrictedFoodIDs(db *gorm.DB) ([]uint, error) {
+    return findFoodIDsByNames(db, dietaryFoods(DietaryRestrictions, p.Restrictions))
+}
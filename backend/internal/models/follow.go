@@ -0,0 +1,13 @@
+// models/follow.go
+package models
+
+import (
+    "time"
+)
+
+// UserFollow 用户之间的关注关系
+type UserFollow struct {
+    FollowerID uint      `gorm:"primaryKey" json:"follower_id"`
+    FolloweeID uint      `gorm:"primaryKey;index" json:"followee_id"`
+    CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,36 @@
+// models/identity.go
+package models
+
+import (
+    "time"
+)
+
+// 登录身份的类型
+const (
+    IdentityWeChat = "wechat" // Subject 为微信 OpenID
+    IdentityEmail  = "email"  // Subject 为小写的邮箱地址
+    IdentityPhone  = "phone"  // Subject 为去掉分隔符的手机号
+)
+
+// UserIdentity 用户可用于登录的身份，一个用户可以关联多个身份
+type UserIdentity struct {
+    ID           uint       `gorm:"primaryKey" json:"id"`
+    UserID       uint       `gorm:"not null;index" json:"-"`
+    Provider     string     `gorm:"size:20;not null;uniqueIndex:idx_user_identities_subject" json:"provider"`
+    Subject      string     `gorm:"size:128;not null;uniqueIndex:idx_user_identities_subject" json:"subject"`
+    PasswordHash string     `gorm:"size:100" json:"-"` // 仅邮箱 / 手机号身份可设置密码
+    CreatedAt    time.Time  `json:"created_at"`
+    LastLoginAt  *time.Time `json:"last_login_at"`
+}
+
+// LoginCode 发送到邮箱或手机的一次性验证码，只保存摘要
+type LoginCode struct {
+    ID        uint       `gorm:"primaryKey"`
+    Channel   string     `gorm:"size:20;not null;index:idx_login_codes_target"` // IdentityEmail / IdentityPhone
+    Target    string     `gorm:"size:128;not null;index:idx_login_codes_target"`
+    CodeHash  string     `gorm:"type:char(64);not null"`
+    Attempts  int        `gorm:"not null;default:0"`
+    ExpiresAt time.Time
+    UsedAt    *time.Time
+    CreatedAt time.Time
+}
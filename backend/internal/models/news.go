@@ -2,6 +2,7 @@
 package models
 
 import (
+    "encoding/json"
     "time"
 )
 
@@ -15,6 +16,7 @@ type News struct {
     FavoriteCount    int            `json:"favorite_count"`
     DislikeCount     int            `json:"dislike_count"`
     ShareCount       int            `json:"share_count"`
+    EditedAt         *time.Time     `json:"edited_at"`              // 最近一次修订时间，未修订时为空
     Comments         []Comment      `gorm:"foreignKey:NewsID" json:"comments"`
 
     // 作者信息
@@ -30,6 +32,7 @@ type News struct {
     // 内容
     Paragraphs       []Paragraph    `gorm:"foreignKey:NewsID" json:"paragraphs"`
     Images           []NewsImage    `gorm:"foreignKey:NewsID" json:"images"`
+    Blocks           []NewsBlock    `gorm:"foreignKey:NewsID" json:"blocks"` // 有序正文块，Paragraphs 和 Images 由其推导
 }
 
 // 段落模型
@@ -45,6 +48,7 @@ type NewsImage struct {
     NewsID      uint   `json:"news_id"`
     URL         string `gorm:"size:255;not null" json:"url"`
     Description string `gorm:"type:text" json:"description"` // 新增描述字段
+    Variants    []ImageVariant `gorm:"-" json:"variants,omitempty"` // 经过处理的图片的各尺寸版本，仅用于响应
 }
 
 // Draft 结构体，表示新闻草稿
@@ -55,6 +59,8 @@ type Draft struct {
     Author       User             `gorm:"foreignKey:AuthorID" json:"author"`
     Paragraphs   []DraftParagraph `gorm:"foreignKey:DraftID" json:"paragraphs"`
     Images       []DraftImage     `gorm:"foreignKey:DraftID" json:"images"`
+    Blocks       []DraftBlock     `gorm:"foreignKey:DraftID" json:"blocks"`          // 有序正文块，Paragraphs 和 Images 由其推导
+    ScheduledAt  *time.Time       `gorm:"index" json:"scheduled_at"` // 定时发布时间，为空表示未设置
     CreatedAt    time.Time        `json:"created_at"`
     UpdatedAt    time.Time        `json:"updated_at"`
 }
@@ -74,6 +80,96 @@ type DraftImage struct {
     DraftID     uint   `json:"draft_id"`
     URL         string `gorm:"size:255;not null" json:"url"`
     Description string `gorm:"type:text" json:"description"` // 新增描述字段
+    Variants    []ImageVariant `gorm:"-" json:"variants,omitempty"` // 经过处理的图片的各尺寸版本，仅用于响应
     CreatedAt   time.Time `json:"created_at"`
     UpdatedAt   time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// DraftRevision 结构体，表示草稿的一个历史版本（每次更新或恢复草稿前保存旧内容）
+type DraftRevision struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    DraftID   uint      `gorm:"not null;index;uniqueIndex:idx_draft_revisions_draft_version" json:"draft_id"`
+    Version   int       `gorm:"not null;uniqueIndex:idx_draft_revisions_draft_version" json:"version"`
+    Title     string    `gorm:"size:255;not null" json:"title"`
+    Content   string    `gorm:"type:text" json:"-"` // RevisionContent 的 JSON 编码
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// NewsRevision 结构体，表示已发布新闻的一个历史版本
+type NewsRevision struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    NewsID    uint      `gorm:"not null;index;uniqueIndex:idx_news_revisions_news_version" json:"news_id"`
+    Version   int       `gorm:"not null;uniqueIndex:idx_news_revisions_news_version" json:"version"`
+    Title     string    `gorm:"size:255;not null" json:"title"`
+    Content   string    `gorm:"type:text" json:"-"` // RevisionContent 的 JSON 编码
+    EditorID  uint      `json:"editor_id"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// RevisionContent 修订快照中保存的正文内容
+type RevisionContent struct {
+    Paragraphs []string        `json:"paragraphs"`
+    Images     []RevisionImage `json:"images"`
+    Blocks     []ContentBlock  `json:"blocks,omitempty"` // 早期快照没有正文块
+}
+
+// RevisionImage 修订快照中的图片
+type RevisionImage struct {
+    URL         string `json:"url"`
+    Description string `json:"description"`
+}
+
+// EncodeRevisionContent 将正文快照编码为 JSON 字符串
+func EncodeRevisionContent(content RevisionContent) (string, error) {
+    data, err := json.Marshal(content)
+    if err != nil {
+        return "", err
+    }
+    return string(data), nil
+}
+
+// DecodeRevisionContent 解析 JSON 编码的正文快照
+func DecodeRevisionContent(data string) (RevisionContent, error) {
+    var content RevisionContent
+    if data == "" {
+        return content, nil
+    }
+    err := json.Unmarshal([]byte(data), &content)
+    return content, err
+}
+
+// ContentOfDraft 提取草稿当前的正文快照（正文块需按位置顺序预加载）
+func ContentOfDraft(draft *Draft) RevisionContent {
+    content := RevisionContent{
+        Paragraphs: make([]string, 0, len(draft.Paragraphs)),
+        Images:     make([]RevisionImage, 0, len(draft.Images)),
+    }
+    for _, b := range draft.Blocks {
+        content.Blocks = append(content.Blocks, b.ContentBlock)
+    }
+    for _, p := range draft.Paragraphs {
+        content.Paragraphs = append(content.Paragraphs, p.Text)
+    }
+    for _, img := range draft.Images {
+        content.Images = append(content.Images, RevisionImage{URL: img.URL, Description: img.Description})
+    }
+    return content
+}
+
+// ContentOfNews 提取新闻当前的正文快照（正文块需按位置顺序预加载）
+func ContentOfNews(news *News) RevisionContent {
+    content := RevisionContent{
+        Paragraphs: make([]string, 0, len(news.Paragraphs)),
+        Images:     make([]RevisionImage, 0, len(news.Images)),
+    }
+    for _, b := range news.Blocks {
+        content.Blocks = append(content.Blocks, b.ContentBlock)
+    }
+    for _, p := range news.Paragraphs {
+        content.Paragraphs = append(content.Paragraphs, p.Text)
+    }
+    for _, img := range news.Images {
+        content.Images = append(content.Images, RevisionImage{URL: img.URL, Description: img.Description})
+    }
+    return content
+}
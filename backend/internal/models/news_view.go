@@ -0,0 +1,24 @@
+// models/news_view.go
+package models
+
+import (
+    "time"
+)
+
+const (
+    // ViewDedupeWindow 同一用户在开始阅读后该时间内再次打开同一篇新闻视为同一次阅读，不重复计入浏览量
+    ViewDedupeWindow = 30 * time.Minute
+    // MaxViewDuration 单次阅读上报的最长时长（秒）
+    MaxViewDuration = 24 * 60 * 60
+)
+
+// NewsView 一次阅读记录。ID 随时间递增，阅读历史按 ID 倒序排列
+type NewsView struct {
+    ID              uint      `gorm:"primaryKey" json:"id"`
+    UserID          uint      `gorm:"not null;index:idx_news_views_user_news" json:"user_id"`
+    NewsID          uint      `gorm:"not null;index:idx_news_views_user_news;index" json:"news_id"`
+    DurationSeconds int       `gorm:"default:0" json:"duration_seconds"` // 客户端上报的累计阅读时长
+    ScrollDepth     int       `gorm:"default:0" json:"scroll_depth"`     // 最大滚动深度，0-100 的百分比
+    CreatedAt       time.Time `json:"created_at"`                        // 开始阅读的时间
+    UpdatedAt       time.Time `json:"updated_at"`                        // 最近一次打开或上报进度的时间
+}
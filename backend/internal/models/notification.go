@@ -0,0 +1,28 @@
+// models/notification.go
+package models
+
+import (
+    "time"
+)
+
+// 通知类型
+const (
+    NotificationMention  = "mention"  // 在评论中被 @
+    NotificationReply    = "reply"    // 评论被回复
+    NotificationComment  = "comment"  // 新闻被评论
+    NotificationLike     = "like"     // 新闻被点赞
+    NotificationFavorite = "favorite" // 新闻被收藏
+)
+
+// Notification 发给用户的站内通知
+type Notification struct {
+    ID        uint       `gorm:"primaryKey" json:"id"`
+    UserID    uint       `gorm:"not null;index:idx_notifications_user_read" json:"user_id"` // 接收者
+    ActorID   uint       `gorm:"not null" json:"actor_id"`                                  // 触发通知的用户
+    Type      string     `gorm:"size:20;not null" json:"type"`
+    NewsID    uint       `gorm:"index" json:"news_id"`
+    CommentID *uint      `json:"comment_id"` // 评论、回复和提及通知对应的评论
+    IsRead    bool       `gorm:"default:false;index:idx_notifications_user_read" json:"is_read"`
+    ReadAt    *time.Time `json:"read_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
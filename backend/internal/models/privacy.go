@@ -0,0 +1,114 @@
+// models/privacy.go
+package models
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// 主页可见范围
+const (
+    ProfilePublic    = "public"    // 所有人可见
+    ProfileFollowers = "followers" // 仅关注自己的用户可见
+    ProfilePrivate   = "private"   // 仅自己可见
+)
+
+// 家庭成员可见的饮食数据
+const (
+    FamilyShareAll    = "all"    // 营养和碳排放数据都可见
+    FamilyShareCarbon = "carbon" // 仅碳排放数据可见
+    FamilyShareNone   = "none"   // 都不可见
+)
+
+// PrivacySettings 用户隐私设置，没有记录时使用默认值
+type PrivacySettings struct {
+    UserID            uint      `gorm:"primaryKey" json:"-"`
+    ProfileVisibility string    `gorm:"size:20;not null;default:public" json:"profile_visibility"`
+    FamilyDataSharing string    `gorm:"size:20;not null;default:all" json:"family_data_sharing"`
+    LikesPublic       bool      `gorm:"not null;default:false" json:"likes_public"`
+    FavoritesPublic   bool      `gorm:"not null;default:false" json:"favorites_public"`
+    UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// DefaultPrivacySettings 返回未设置过隐私的用户的默认值
+func DefaultPrivacySettings(userID uint) PrivacySettings {
+    return PrivacySettings{
+        UserID:            userID,
+        ProfileVisibility: ProfilePublic,
+        FamilyDataSharing: FamilyShareAll,
+    }
+}
+
+// ValidProfileVisibility 判断主页可见范围是否合法
+func ValidProfileVisibility(v string) bool {
+    return v == ProfilePublic || v == ProfileFollowers || v == ProfilePrivate
+}
+
+// ValidFamilyDataSharing 判断家庭数据共享范围是否合法
+func ValidFamilyDataSharing(v string) bool {
+    return v == FamilyShareAll || v == FamilyShareCarbon || v == FamilyShareNone
+}
+
+// SharesNutrition 家庭成员是否可以查看营养数据
+func (p PrivacySettings) SharesNutrition() bool {
+    return p.FamilyDataSharing == FamilyShareAll
+}
+
+// SharesCarbon 家庭成员是否可以查看碳排放数据
+func (p PrivacySettings) SharesCarbon() bool {
+    return p.FamilyDataSharing == FamilyShareAll || p.FamilyDataSharing == FamilyShareCarbon
+}
+
+// GetPrivacySettings 读取用户的隐私设置
+func GetPrivacySettings(db *gorm.DB, userID uint) (PrivacySettings, error) {
+    settings, err := GetPrivacySettingsMap(db, []uint{userID})
+    if err != nil {
+        return PrivacySettings{}, err
+    }
+    return settings[userID], nil
+}
+
+// GetPrivacySettingsMap 批量读取隐私设置，结果包含每个 userIDs 的条目
+func GetPrivacySettingsMap(db *gorm.DB, userIDs []uint) (map[uint]PrivacySettings, error) {
+    result := make(map[uint]PrivacySettings, len(userIDs))
+    for _, id := range userIDs {
+        result[id] = DefaultPrivacySettings(id)
+    }
+    if len(userIDs) == 0 {
+        return result, nil
+    }
+
+    var rows []PrivacySettings
+    if err := db.Where("user_id IN ?", userIDs).Find(&rows).Error; err != nil {
+        return nil, err
+    }
+    for _, row := range rows {
+        result[row.UserID] = row
+    }
+    return result, nil
+}
+
+// CanViewProfile 判断 viewerID 能否查看 ownerID 的主页内容；匿名访问时 viewerID 为 0
+func CanViewProfile(db *gorm.DB, viewerID, ownerID uint) (bool, error) {
+    if viewerID != 0 && viewerID == ownerID {
+        return true, nil
+    }
+    settings, err := GetPrivacySettings(db, ownerID)
+    if err != nil {
+        return false, err
+    }
+    switch settings.ProfileVisibility {
+    case ProfilePublic:
+        return true, nil
+    case ProfileFollowers:
+        if viewerID == 0 {
+            return false, nil
+        }
+        var count int64
+        err := db.Model(&UserFollow{}).Where("follower_id = ? AND followee_id = ?", viewerID, ownerID).Count(&count).Error
+        return count > 0, err
+    default:
+        return false, nil
+    }
+}
@@ -0,0 +1,31 @@
+// models/session.go
+package models
+
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// Session 一次设备登录，会话内的 refresh token 每次刷新都会轮换
+type Session struct {
+    ID         uint       `gorm:"primaryKey" json:"id"`
+    UserID     uint       `gorm:"not null;index" json:"-"`
+    DeviceName string     `gorm:"size:100" json:"device_name"`
+    UserAgent  string     `gorm:"size:255" json:"user_agent"`
+    IP         string     `gorm:"size:45" json:"ip"`
+    CreatedAt  time.Time  `json:"created_at"`
+    LastUsedAt time.Time  `json:"last_used_at"`
+    ExpiresAt  time.Time  `json:"expires_at"` // 最新一个 refresh token 的过期时间
+    RevokedAt  *time.Time `json:"-"`
+}
+
+// RevokeSession 注销会话并撤销其下所有 refresh token
+func RevokeSession(tx *gorm.DB, sessionID uint) error {
+    if err := tx.Model(&Session{}).Where("id = ? AND revoked_at IS NULL", sessionID).
+        Update("revoked_at", time.Now()).Error; err != nil {
+        return err
+    }
+    return tx.Model(&RefreshToken{}).Where("session_id = ? AND revoked = ?", sessionID, false).
+        Update("revoked", true).Error
+}
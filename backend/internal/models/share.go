@@ -0,0 +1,47 @@
+// models/share.go
+package models
+
+import (
+    "time"
+)
+
+// 分享渠道
+const (
+    ShareChannelWeChatFriend = "wechat_friend" // 微信好友
+    ShareChannelMoment       = "moment"        // 朋友圈
+    ShareChannelCopyLink     = "copy_link"     // 复制链接
+)
+
+// ShareDedupeWindow 同一用户在该时间内通过同一渠道重复分享同一篇新闻，只计入一次分享数
+const ShareDedupeWindow = 24 * time.Hour
+
+// ShareChannels 支持的分享渠道
+var ShareChannels = []string{ShareChannelWeChatFriend, ShareChannelMoment, ShareChannelCopyLink}
+
+// ShareCode 指向新闻的分享短码，同一用户分享同一篇新闻复用同一个短码，便于统计打开次数
+type ShareCode struct {
+    Code      string    `gorm:"primaryKey;size:16" json:"code"`
+    NewsID    uint      `gorm:"not null;uniqueIndex:idx_share_codes_user_news" json:"news_id"`
+    UserID    uint      `gorm:"not null;uniqueIndex:idx_share_codes_user_news" json:"user_id"` // 分享者
+    OpenCount int       `gorm:"default:0" json:"open_count"`                                   // 通过短码打开的次数
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// ShareEvent 一次分享
+type ShareEvent struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    NewsID    uint      `gorm:"not null;index" json:"news_id"`
+    UserID    uint      `gorm:"not null;index" json:"user_id"`
+    Channel   string    `gorm:"size:20;not null" json:"channel"`
+    Code      string    `gorm:"size:16" json:"code"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// CarbonTips 新闻中没有嵌入食谱或食物时，分享卡片上展示的低碳饮食小贴士
+var CarbonTips = []string{
+    "每少吃 1 kg 牛肉，约可减少 60 kg 二氧化碳当量排放",
+    "选择应季、本地的蔬果，可以减少运输和温室种植带来的排放",
+    "按需购买、吃完剩菜，减少食物浪费就是在减少碳排放",
+    "用豆制品替代部分肉类，既能补充蛋白质，又能大幅降低碳足迹",
+    "多蒸煮、少油炸，低碳烹饪也更健康",
+}
@@ -0,0 +1,15 @@
+// models/signing_key.go
+package models
+
+import (
+    "time"
+)
+
+// SigningKey 定时轮换生成的 JWT 签名密钥，多个实例通过数据库共享
+type SigningKey struct {
+    KID       string     `gorm:"column:kid;primaryKey;size:64"`
+    Algorithm string     `gorm:"size:10;not null"`
+    Material  string     `gorm:"type:text;not null"` // 用 JWT_KEY_ENCRYPTION_KEY 加密的私钥（HMAC 密钥为 base64，非对称密钥为 PKCS#8 PEM），见 keyring.Sync
+    CreatedAt time.Time
+    RetiredAt *time.Time `gorm:"index"` // 退役后不再签名，宽限期内仍用于验证
+}
@@ -0,0 +1,75 @@
+// models/uploaded_image.go
+package models
+
+import (
+    "encoding/json"
+    "path"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// UploadedImage 经过处理的上传图片。同一类图片（Kind）按原始文件内容的 SHA-256 去重，文件路径由哈希决定
+type UploadedImage struct {
+    ID         uint      `gorm:"primaryKey" json:"id"`
+    Hash       string    `gorm:"size:64;not null;uniqueIndex:idx_uploaded_images_hash_kind" json:"hash"`
+    Kind       string    `gorm:"size:20;not null;uniqueIndex:idx_uploaded_images_hash_kind" json:"kind"` // news / avatar
+    UploaderID uint      `gorm:"index" json:"uploader_id"`                                               // 首次上传者
+    Format     string    `gorm:"size:10" json:"format"`                                                  // 原始格式
+    Width      int       `json:"width"`
+    Height     int       `json:"height"`
+    Path       string    `gorm:"size:255;not null;index" json:"path"` // 默认展示版本的相对路径，即 NewsImage.URL / User.AvatarURL 中保存的值
+    Variants   string    `gorm:"type:text" json:"-"`                  // []ImageVariant 的 JSON 编码
+    CreatedAt  time.Time `json:"created_at"`
+    UpdatedAt  time.Time `json:"updated_at"` // 最近一次上传（包括去重命中）的时间，垃圾回收据此计算宽限期
+}
+
+// ImageVariant 图片的一个尺寸或格式版本
+type ImageVariant struct {
+    Name   string `json:"name"`   // thumb / small / medium / large
+    Format string `json:"format"` // jpeg / png / webp
+    URL    string `json:"url"`
+    Width  int    `json:"width"`
+    Height int    `json:"height"`
+}
+
+// VariantList 解析图片的所有版本
+func (img *UploadedImage) VariantList() []ImageVariant {
+    var variants []ImageVariant
+    if img.Variants != "" {
+        _ = json.Unmarshal([]byte(img.Variants), &variants)
+    }
+    return variants
+}
+
+// SetVariants 设置图片的所有版本
+func (img *UploadedImage) SetVariants(variants []ImageVariant) error {
+    data, err := json.Marshal(variants)
+    if err != nil {
+        return err
+    }
+    img.Variants = string(data)
+    return nil
+}
+
+// ImageVariantsByPath 按默认展示路径批量查询图片版本，未经处理的旧图片不在结果中
+func ImageVariantsByPath(db *gorm.DB, paths []string) (map[string][]ImageVariant, error) {
+    result := make(map[string][]ImageVariant)
+    if len(paths) == 0 {
+        return result, nil
+    }
+
+    var images []UploadedImage
+    if err := db.Where("path IN ?", paths).Find(&images).Error; err != nil {
+        return nil, err
+    }
+    for i := range images {
+        result[images[i].Path] = images[i].VariantList()
+    }
+    return result, nil
+}
+
+// ImageDir 图片所有版本所在的目录：images/<类型>/<哈希前两位>/<哈希>
+func (img *UploadedImage) ImageDir() string {
+    return path.Dir(img.Path)
+}
@@ -14,6 +14,9 @@ type User struct {
     CreatedAt   time.Time `json:"created_at"`                   // 用户创建时间
     UpdatedAt   time.Time `json:"updated_at"`                   // 用户更新时间
 
+    DeletionScheduledAt *time.Time `gorm:"index" json:"-"` // 申请注销后计划清除数据的时间，为空表示未申请
+    AnonymizedAt        *time.Time `json:"-"`              // 注销完成、账号被匿名化的时间
+
     RefreshTokens  []RefreshToken
 
     FamilyID    *uint      `json:"family_id"`                    // 所属家庭 ID，唯一
@@ -45,10 +48,13 @@ type User struct {
     UserLastSelectedFoods []UserLastSelectedFoods `gorm:"foreignKey:UserID" json:"user_last_selected_foods"`
 }
 
+// RefreshToken 刷新令牌，按 JTI 查找，只保存令牌的摘要；同一次登录轮换出的令牌属于同一个会话
 type RefreshToken struct {
     gorm.Model
-    Token     string    `gorm:"type:varchar(255);uniqueIndex;not null"` // 改为 VARCHAR 并设置长度
+    JTI       string `gorm:"type:varchar(64);uniqueIndex;not null"`
+    TokenHash string `gorm:"type:char(64);not null"`
     UserID    uint   `gorm:"not null;index"`
+    SessionID uint   `gorm:"not null;index"`
     ExpiresAt time.Time
     Revoked   bool `gorm:"default:false"`
 }
\ No newline at end of file
@@ -0,0 +1,139 @@
+// internal/openapi/document.go
+package openapi
+
+import "net/http"
+
+// Version 生成的文档使用的 OpenAPI 版本
+const Version = "3.0.3"
+
+const (
+    refPrefix  = "#/components/schemas/"
+    bearerAuth = "bearerAuth"
+)
+
+// Document OpenAPI 文档，只包含本项目用到的字段
+type Document struct {
+    OpenAPI    string               `json:"openapi"`
+    Info       Info                 `json:"info"`
+    Servers    []Server             `json:"servers,omitempty"`
+    Paths      map[string]*PathItem `json:"paths"`
+    Components Components           `json:"components"`
+}
+
+type Info struct {
+    Title       string `json:"title"`
+    Description string `json:"description,omitempty"`
+    Version     string `json:"version"`
+}
+
+type Server struct {
+    URL         string `json:"url"`
+    Description string `json:"description,omitempty"`
+}
+
+type PathItem struct {
+    Servers []Server   `json:"servers,omitempty"`
+    Get     *Operation `json:"get,omitempty"`
+    Put     *Operation `json:"put,omitempty"`
+    Post    *Operation `json:"post,omitempty"`
+    Delete  *Operation `json:"delete,omitempty"`
+    Head    *Operation `json:"head,omitempty"`
+    Patch   *Operation `json:"patch,omitempty"`
+    Options *Operation `json:"options,omitempty"`
+}
+
+// Operation 返回 method 对应的接口，没有时返回 nil
+func (p *PathItem) Operation(method string) *Operation {
+    switch method {
+    case http.MethodGet:
+        return p.Get
+    case http.MethodPut:
+        return p.Put
+    case http.MethodPost:
+        return p.Post
+    case http.MethodDelete:
+        return p.Delete
+    case http.MethodHead:
+        return p.Head
+    case http.MethodPatch:
+        return p.Patch
+    case http.MethodOptions:
+        return p.Options
+    }
+    return nil
+}
+
+func (p *PathItem) set(method string, op *Operation) {
+    switch method {
+    case http.MethodGet:
+        p.Get = op
+    case http.MethodPut:
+        p.Put = op
+    case http.MethodPost:
+        p.Post = op
+    case http.MethodDelete:
+        p.Delete = op
+    case http.MethodHead:
+        p.Head = op
+    case http.MethodPatch:
+        p.Patch = op
+    case http.MethodOptions:
+        p.Options = op
+    }
+}
+
+type Operation struct {
+    OperationID string                `json:"operationId"`
+    Summary     string                `json:"summary,omitempty"`
+    Description string                `json:"description,omitempty"`
+    Tags        []string              `json:"tags,omitempty"`
+    Parameters  []*Parameter          `json:"parameters,omitempty"`
+    RequestBody *RequestBody          `json:"requestBody,omitempty"`
+    Responses   map[string]*Response  `json:"responses"`
+    Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+    Name     string  `json:"name"`
+    In       string  `json:"in"`
+    Required bool    `json:"required,omitempty"`
+    Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+    Required bool                  `json:"required,omitempty"`
+    Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+    Description string                `json:"description"`
+    Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+    Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+    Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+    SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+    Type         string `json:"type"`
+    Scheme       string `json:"scheme,omitempty"`
+    BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Schema JSON Schema 的子集
+type Schema struct {
+    Ref                  string             `json:"$ref,omitempty"`
+    Type                 string             `json:"type,omitempty"`
+    Format               string             `json:"format,omitempty"`
+    Description          string             `json:"description,omitempty"`
+    Nullable             bool               `json:"nullable,omitempty"`
+    Items                *Schema            `json:"items,omitempty"`
+    Properties           map[string]*Schema `json:"properties,omitempty"`
+    Required             []string           `json:"required,omitempty"`
+    AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
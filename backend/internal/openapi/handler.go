@@ -0,0 +1,43 @@
+// internal/openapi/handler.go
+package openapi
+
+import (
+    _ "embed"
+    "encoding/json"
+    "html"
+    "net/http"
+    "strings"
+    "sync"
+
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/apierror"
+)
+
+// viewerHTML 浏览文档的页面，不依赖外部脚本和样式
+//go:embed viewer.html
+var viewerHTML string
+
+// Handler 返回 JSON 格式的文档。文档在第一次请求时生成并缓存，此时全部路由均已注册
+func Handler(generate func() *Document) gin.HandlerFunc {
+    var once sync.Once
+    var body []byte
+    var err error
+    return func(c *gin.Context) {
+        once.Do(func() {
+            body, err = json.Marshal(generate())
+        })
+        if err != nil {
+            apierror.Respond(c, apierror.ErrInternal.WithMessage("Failed to generate OpenAPI document"))
+            return
+        }
+        c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+    }
+}
+
+// ViewerHandler 返回浏览 specURL 处文档的页面
+func ViewerHandler(specURL string) gin.HandlerFunc {
+    page := []byte(strings.Replace(viewerHTML, "{{SPEC_URL}}", html.EscapeString(specURL), 1))
+    return func(c *gin.Context) {
+        c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+    }
+}
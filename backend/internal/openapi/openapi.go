@@ -0,0 +1,227 @@
+// internal/openapi/openapi.go
+package openapi
+
+import (
+    "net/http"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+)
+
+// Doc 一个路由的接口说明，与 gin 中注册的路由合并生成 OpenAPI 文档
+type Doc struct {
+    Summary     string
+    Description string
+    Public      bool        // 不需要登录即可访问
+    Query       []string    // 查询参数，"name" 为字符串，"name:integer" 等指定类型
+    Request     interface{} // 请求体类型的零值，nil 表示没有请求体；上传文件使用 Upload
+    Response    interface{} // 成功响应类型的零值，nil 表示未定义结构的 JSON 对象；非 JSON 响应使用 Binary
+    Status      int         // 成功时的状态码，默认 200
+}
+
+// Upload multipart/form-data 上传文件的请求体，File 为文件字段名
+type Upload struct {
+    File string
+}
+
+// Binary 非 JSON 的响应，例如文件下载和导出
+type Binary struct {
+    ContentType string
+}
+
+// Key 返回 Doc 对应的键，path 使用 gin 的路由语法，例如 "GET /news/:id"
+func Key(method, path string) string {
+    return method + " " + path
+}
+
+// Generate 根据 gin 中已注册的路由和 docs 生成 OpenAPI 文档。
+// 注册在 prefix 下的路由与去掉 prefix 后的同名旧路由合并为一个接口，响应使用统一格式（见 middleware.APIEnvelope），
+// 只注册在根路径的路由（静态文件、JWKS 等）单独指定 servers，响应原样描述
+func Generate(info Info, routes gin.RoutesInfo, prefix string, docs map[string]Doc) *Document {
+    g := newGenerator()
+    doc := &Document{
+        OpenAPI: Version,
+        Info:    info,
+        Servers: []Server{
+            {URL: prefix, Description: "统一响应格式"},
+            {URL: "/", Description: "旧路由，成功时直接返回 data，失败时返回 {\"error\": 信息}"},
+        },
+        Paths: map[string]*PathItem{},
+        Components: Components{
+            Schemas: g.schemas,
+            SecuritySchemes: map[string]*SecurityScheme{
+                bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+            },
+        },
+    }
+    g.schemas["Error"] = errorEnvelopeSchema()
+
+    enveloped := map[string]bool{}
+    for _, route := range routes {
+        if path, ok := trimPrefix(route.Path, prefix); ok {
+            enveloped[Key(route.Method, path)] = true
+        }
+    }
+
+    operationIDs := map[string]bool{}
+    for _, route := range sortedRoutes(routes) {
+        path, ok := trimPrefix(route.Path, prefix)
+        if !ok {
+            path = route.Path
+        }
+        key := Key(route.Method, path)
+        if !ok && enveloped[key] {
+            continue // 旧路由与 prefix 下的路由合并，只生成一次
+        }
+
+        openAPIPath, params := convertPath(path)
+        item := doc.Paths[openAPIPath]
+        if item == nil {
+            item = &PathItem{}
+            doc.Paths[openAPIPath] = item
+        }
+        if !ok {
+            item.Servers = []Server{{URL: "/"}}
+        }
+
+        d := docs[key]
+        op := &Operation{
+            OperationID: uniqueOperationID(operationIDs, route),
+            Summary:     d.Summary,
+            Description: d.Description,
+            Tags:        []string{tag(path)},
+            Parameters:  params,
+            Responses:   map[string]*Response{},
+        }
+        for _, q := range d.Query {
+            name, typ := q, "string"
+            if i := strings.Index(q, ":"); i >= 0 {
+                name, typ = q[:i], q[i+1:]
+            }
+            op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "query", Schema: &Schema{Type: typ}})
+        }
+        if !d.Public {
+            op.Security = []map[string][]string{{bearerAuth: {}}}
+        }
+        op.RequestBody = g.requestBody(d.Request)
+
+        status := d.Status
+        if status == 0 {
+            status = http.StatusOK
+        }
+        op.Responses[strconv.Itoa(status)] = g.response(d.Response, ok)
+        if ok {
+            op.Responses["default"] = &Response{
+                Description: "错误",
+                Content:     map[string]*MediaType{gin.MIMEJSON: {Schema: &Schema{Ref: refPrefix + "Error"}}},
+            }
+        }
+        item.set(route.Method, op)
+    }
+    return doc
+}
+
+// Missing 返回没有接口说明的路由，格式与 Key 相同，prefix 下的路由已去掉 prefix
+func Missing(routes gin.RoutesInfo, prefix string, docs map[string]Doc) []string {
+    seen := map[string]bool{}
+    var missing []string
+    for _, route := range routes {
+        path, ok := trimPrefix(route.Path, prefix)
+        if !ok {
+            path = route.Path
+        }
+        key := Key(route.Method, path)
+        if _, exists := docs[key]; !exists && !seen[key] {
+            missing = append(missing, key)
+        }
+        seen[key] = true
+    }
+    sort.Strings(missing)
+    return missing
+}
+
+// Unused 返回没有对应路由的接口说明，通常是路由改名或删除后遗留的
+func Unused(routes gin.RoutesInfo, prefix string, docs map[string]Doc) []string {
+    registered := map[string]bool{}
+    for _, route := range routes {
+        path, ok := trimPrefix(route.Path, prefix)
+        if !ok {
+            path = route.Path
+        }
+        registered[Key(route.Method, path)] = true
+    }
+    var unused []string
+    for key := range docs {
+        if !registered[key] {
+            unused = append(unused, key)
+        }
+    }
+    sort.Strings(unused)
+    return unused
+}
+
+func trimPrefix(path, prefix string) (string, bool) {
+    if prefix == "" || prefix == "/" || !strings.HasPrefix(path, prefix+"/") {
+        return path, false
+    }
+    return strings.TrimPrefix(path, prefix), true
+}
+
+func sortedRoutes(routes gin.RoutesInfo) gin.RoutesInfo {
+    sorted := append(gin.RoutesInfo(nil), routes...)
+    sort.SliceStable(sorted, func(i, j int) bool {
+        if sorted[i].Path != sorted[j].Path {
+            return sorted[i].Path < sorted[j].Path
+        }
+        return sorted[i].Method < sorted[j].Method
+    })
+    return sorted
+}
+
+// convertPath 把 gin 的 :name、*name 参数转为 OpenAPI 的 {name}
+func convertPath(path string) (string, []*Parameter) {
+    segments := strings.Split(path, "/")
+    var params []*Parameter
+    for i, segment := range segments {
+        if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+            continue
+        }
+        name := segment[1:]
+        segments[i] = "{" + name + "}"
+        typ := "string"
+        if name == "id" || strings.HasSuffix(name, "_id") || name == "version" {
+            typ = "integer"
+        }
+        params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: typ}})
+    }
+    return strings.Join(segments, "/"), params
+}
+
+// tag 按路径的第一段分组
+func tag(path string) string {
+    segment := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+    if segment == "" {
+        return "default"
+    }
+    return segment
+}
+
+// uniqueOperationID 使用处理函数名作为 operationId，例如 "(*NewsController).LikeNews-fm" 对应 LikeNews
+func uniqueOperationID(used map[string]bool, route gin.RouteInfo) string {
+    name := route.Handler
+    if i := strings.LastIndex(name, "."); i >= 0 {
+        name = name[i+1:]
+    }
+    name = strings.TrimSuffix(name, "-fm")
+    if name == "" || strings.HasPrefix(name, "func") {
+        name = strings.ToLower(route.Method) + strings.NewReplacer("/", "_", ":", "", "*", "", ".", "", "-", "_").Replace(route.Path)
+    }
+    id := name
+    for i := 2; used[id]; i++ {
+        id = name + strconv.Itoa(i)
+    }
+    used[id] = true
+    return id
+}
@@ -0,0 +1,114 @@
+// internal/openapi/openapi_test.go
+package openapi_test
+
+import (
+    "net/http"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/openapi"
+)
+
+type Author struct {
+    ID   uint   `json:"id"`
+    Name string `json:"name"`
+}
+
+type Base struct {
+    CreatedAt time.Time `json:"created_at"`
+}
+
+type Article struct {
+    Base
+    Title   string   `json:"title" binding:"required"`
+    Tags    []string `json:"tags"`
+    Author  *Author  `json:"author"`
+    Secret  string   `json:"-"`
+    private string
+}
+
+func setupRoutes() *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    handler := func(c *gin.Context) {}
+    for _, api := range []gin.IRouter{router, router.Group("/api/v1")} {
+        api.GET("/articles/:id", handler)
+        api.POST("/articles", handler)
+    }
+    router.GET("/health", handler)
+    return router
+}
+
+var docs = map[string]openapi.Doc{
+    "GET /articles/:id": {Summary: "获取文章", Public: true, Query: []string{"lang", "page:integer"}, Response: Article{}},
+    "POST /articles":    {Summary: "创建文章", Request: Article{}, Status: http.StatusCreated},
+    "GET /health":       {Summary: "健康检查", Public: true},
+}
+
+func TestGenerate(t *testing.T) {
+    router := setupRoutes()
+    doc := openapi.Generate(openapi.Info{Title: "test", Version: "1"}, router.Routes(), "/api/v1", docs)
+
+    assert.Equal(t, openapi.Version, doc.OpenAPI)
+    assert.Len(t, doc.Paths, 3)
+
+    get := doc.Paths["/articles/{id}"].Get
+    if assert.NotNil(t, get) {
+        assert.Equal(t, "获取文章", get.Summary)
+        assert.Empty(t, get.Security)
+        assert.Equal(t, []string{"articles"}, get.Tags)
+        if assert.Len(t, get.Parameters, 3) {
+            assert.Equal(t, &openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}, get.Parameters[0])
+            assert.Equal(t, "lang", get.Parameters[1].Name)
+            assert.Equal(t, "integer", get.Parameters[2].Schema.Type)
+        }
+        // /api/v1 下的响应放在 data 中
+        data := get.Responses["200"].Content[gin.MIMEJSON].Schema.Properties["data"]
+        assert.Equal(t, "#/components/schemas/Article", data.Ref)
+        assert.Contains(t, get.Responses, "default")
+    }
+
+    post := doc.Paths["/articles"].Post
+    if assert.NotNil(t, post) {
+        assert.NotEmpty(t, post.Security)
+        assert.Contains(t, post.Responses, "201")
+        assert.Equal(t, "#/components/schemas/Article", post.RequestBody.Content[gin.MIMEJSON].Schema.Ref)
+    }
+
+    article := doc.Components.Schemas["Article"]
+    if assert.NotNil(t, article) {
+        assert.ElementsMatch(t, []string{"created_at", "title", "tags", "author"}, keys(article.Properties))
+        assert.Equal(t, []string{"title"}, article.Required)
+        assert.Equal(t, &openapi.Schema{Type: "string", Format: "date-time"}, article.Properties["created_at"])
+        assert.Equal(t, "#/components/schemas/Author", article.Properties["author"].Ref)
+    }
+    assert.Contains(t, doc.Components.Schemas, "Author")
+
+    // 只注册在根路径的路由不使用统一响应格式
+    health := doc.Paths["/health"]
+    assert.Equal(t, []openapi.Server{{URL: "/"}}, health.Servers)
+    assert.NotContains(t, health.Get.Responses, "default")
+}
+
+func TestMissingAndUnused(t *testing.T) {
+    router := setupRoutes()
+    partial := map[string]openapi.Doc{
+        "GET /articles/:id": docs["GET /articles/:id"],
+        "DELETE /articles":  {Summary: "已删除的路由"},
+    }
+
+    assert.Equal(t, []string{"GET /health", "POST /articles"}, openapi.Missing(router.Routes(), "/api/v1", partial))
+    assert.Equal(t, []string{"DELETE /articles"}, openapi.Unused(router.Routes(), "/api/v1", partial))
+    assert.Empty(t, openapi.Missing(router.Routes(), "/api/v1", docs))
+    assert.Empty(t, openapi.Unused(router.Routes(), "/api/v1", docs))
+}
+
+func keys(m map[string]*openapi.Schema) []string {
+    var result []string
+    for k := range m {
+        result = append(result, k)
+    }
+    return result
+}
@@ -0,0 +1,212 @@
+// internal/openapi/schema.go
+package openapi
+
+import (
+    "encoding/json"
+    "reflect"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+var (
+    timeType      = reflect.TypeOf(time.Time{})
+    marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// generator 通过反射把 Go 类型转为 Schema，具名结构体放入 components.schemas 并以 $ref 引用
+type generator struct {
+    schemas map[string]*Schema
+    names   map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+    return &generator{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+func (g *generator) requestBody(request interface{}) *RequestBody {
+    switch r := request.(type) {
+    case nil:
+        return nil
+    case Upload:
+        return &RequestBody{
+            Required: true,
+            Content: map[string]*MediaType{gin.MIMEMultipartPOSTForm: {Schema: &Schema{
+                Type:       "object",
+                Properties: map[string]*Schema{r.File: {Type: "string", Format: "binary"}},
+                Required:   []string{r.File},
+            }}},
+        }
+    default:
+        return &RequestBody{
+            Required: true,
+            Content:  map[string]*MediaType{gin.MIMEJSON: {Schema: g.schema(reflect.TypeOf(request))}},
+        }
+    }
+}
+
+// response 生成成功响应，enveloped 时放在统一格式的 data 字段中
+func (g *generator) response(response interface{}, enveloped bool) *Response {
+    if b, ok := response.(Binary); ok {
+        return &Response{
+            Description: "成功",
+            Content:     map[string]*MediaType{b.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}},
+        }
+    }
+
+    schema := &Schema{Type: "object"}
+    if response != nil {
+        schema = g.schema(reflect.TypeOf(response))
+    }
+    if enveloped {
+        schema = &Schema{
+            Type: "object",
+            Properties: map[string]*Schema{
+                "data":       schema,
+                "request_id": {Type: "string"},
+            },
+            Required: []string{"data", "request_id"},
+        }
+    }
+    return &Response{Description: "成功", Content: map[string]*MediaType{gin.MIMEJSON: {Schema: schema}}}
+}
+
+// errorEnvelopeSchema /api/v1 的错误响应，见 middleware.APIEnvelope
+func errorEnvelopeSchema() *Schema {
+    return &Schema{
+        Type: "object",
+        Properties: map[string]*Schema{
+            "error": {
+                Type: "object",
+                Properties: map[string]*Schema{
+                    "code":    {Type: "string", Description: "稳定的错误码，见 apierror 包"},
+                    "message": {Type: "string", Description: "按 Accept-Language 或 lang 参数本地化的提示"},
+                },
+                Required: []string{"code", "message"},
+            },
+            "request_id": {Type: "string"},
+        },
+        Required: []string{"error", "request_id"},
+    }
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+    nullable := false
+    for t.Kind() == reflect.Ptr {
+        t = t.Elem()
+        nullable = true
+    }
+
+    var schema *Schema
+    switch {
+    case t == timeType:
+        schema = &Schema{Type: "string", Format: "date-time"}
+    case t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType):
+        schema = g.marshalerSchema(t)
+    default:
+        schema = g.kindSchema(t)
+    }
+    if nullable && schema.Ref == "" {
+        schema.Nullable = true
+    }
+    return schema
+}
+
+// marshalerSchema 自定义 JSON 编码的类型：sql.NullTime 一类的时间按可空时间处理，其余不限制结构
+func (g *generator) marshalerSchema(t reflect.Type) *Schema {
+    if t.Kind() == reflect.Struct {
+        if field, ok := t.FieldByName("Time"); ok && field.Type == timeType {
+            return &Schema{Type: "string", Format: "date-time", Nullable: true}
+        }
+    }
+    return &Schema{}
+}
+
+func (g *generator) kindSchema(t reflect.Type) *Schema {
+    switch t.Kind() {
+    case reflect.Bool:
+        return &Schema{Type: "boolean"}
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+        return &Schema{Type: "integer"}
+    case reflect.Int64, reflect.Uint64:
+        return &Schema{Type: "integer", Format: "int64"}
+    case reflect.Float32:
+        return &Schema{Type: "number", Format: "float"}
+    case reflect.Float64:
+        return &Schema{Type: "number", Format: "double"}
+    case reflect.String:
+        return &Schema{Type: "string"}
+    case reflect.Slice, reflect.Array:
+        if t.Elem().Kind() == reflect.Uint8 {
+            return &Schema{Type: "string", Format: "byte"}
+        }
+        return &Schema{Type: "array", Items: g.schema(t.Elem())}
+    case reflect.Map:
+        return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+    case reflect.Struct:
+        if t.Name() == "" {
+            return g.structSchema(t)
+        }
+        return &Schema{Ref: refPrefix + g.component(t)}
+    default:
+        // interface{} 等任意值
+        return &Schema{}
+    }
+}
+
+// component 把具名结构体登记到 components.schemas，返回引用名。不同包的同名类型以包名区分
+func (g *generator) component(t reflect.Type) string {
+    if name, ok := g.names[t]; ok {
+        return name
+    }
+    name := t.Name()
+    if _, taken := g.schemas[name]; taken {
+        pkg := t.PkgPath()
+        pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+        name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+    }
+    g.names[t] = name
+    // 先占位再生成字段，结构体互相引用时不会无限递归
+    g.schemas[name] = &Schema{}
+    *g.schemas[name] = *g.structSchema(t)
+    return name
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+    schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+    g.addFields(schema, t)
+    return schema
+}
+
+// addFields 按 encoding/json 的规则添加字段：匿名嵌入且没有指定名称的结构体展开到外层
+func (g *generator) addFields(schema *Schema, t reflect.Type) {
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        tag := field.Tag.Get("json")
+        if tag == "-" {
+            continue
+        }
+        name := strings.Split(tag, ",")[0]
+
+        fieldType := field.Type
+        for fieldType.Kind() == reflect.Ptr {
+            fieldType = fieldType.Elem()
+        }
+        if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct && fieldType != timeType {
+            g.addFields(schema, fieldType)
+            continue
+        }
+        if !field.IsExported() {
+            continue
+        }
+        if name == "" {
+            name = field.Name
+        }
+
+        schema.Properties[name] = g.schema(field.Type)
+        if strings.Contains(","+field.Tag.Get("binding")+",", ",required,") {
+            schema.Required = append(schema.Required, name)
+        }
+    }
+}
@@ -0,0 +1,62 @@
+// internal/ratelimit/memory.go
+package ratelimit
+
+import (
+    "context"
+    "math"
+    "sync"
+    "time"
+)
+
+// 已经补满的令牌桶与新建的桶没有区别，定期清理以免占用内存
+const memorySweepInterval = 10 * time.Minute
+
+type memoryBucket struct {
+    tokens float64
+    last   time.Time
+    full   time.Time // 令牌桶补满的时间
+}
+
+// MemoryStore 保存在进程内存中的令牌桶，只在单实例部署时有效
+type MemoryStore struct {
+    mu        sync.Mutex
+    buckets   map[string]*memoryBucket
+    lastSweep time.Time
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+    return &MemoryStore{buckets: make(map[string]*memoryBucket)}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if now.Sub(s.lastSweep) > memorySweepInterval {
+        for k, b := range s.buckets {
+            if !now.Before(b.full) {
+                delete(s.buckets, k)
+            }
+        }
+        s.lastSweep = now
+    }
+
+    burst := float64(limit.Burst)
+    b, ok := s.buckets[key]
+    if !ok {
+        b = &memoryBucket{tokens: burst, last: now}
+        s.buckets[key] = b
+    }
+    if elapsed := now.Sub(b.last); elapsed > 0 {
+        b.tokens = math.Min(burst, b.tokens+elapsed.Seconds()*limit.rate())
+        b.last = now
+    }
+
+    allowed := b.tokens >= 1
+    if allowed {
+        b.tokens--
+    }
+    b.full = now.Add(limit.fillTime(b.tokens))
+    return newResult(limit, allowed, b.tokens), nil
+}
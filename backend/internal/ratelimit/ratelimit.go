@@ -0,0 +1,126 @@
+// internal/ratelimit/ratelimit.go
+package ratelimit
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "os"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Limit 令牌桶配置：桶容量为 Burst，每分钟补充 RequestsPerMinute 个令牌
+type Limit struct {
+    RequestsPerMinute int // 每分钟补充的请求数
+    Burst             int // 允许的突发请求数
+}
+
+// rate 每秒补充的令牌数
+func (l Limit) rate() float64 {
+    return float64(l.RequestsPerMinute) / 60
+}
+
+// fillTime 令牌从 tokens 个补满所需的时间
+func (l Limit) fillTime(tokens float64) time.Duration {
+    if l.RequestsPerMinute <= 0 || tokens >= float64(l.Burst) {
+        return 0
+    }
+    return time.Duration((float64(l.Burst) - tokens) / l.rate() * float64(time.Second))
+}
+
+// Result 一次取令牌的结果
+type Result struct {
+    Allowed    bool
+    Limit      int           // 桶容量
+    Remaining  int           // 剩余的令牌数
+    RetryAfter time.Duration // 被拒绝时需要等待的时间
+    Reset      time.Duration // 令牌桶补满所需的时间
+}
+
+// newResult 根据取令牌后桶中剩余的令牌数构造结果
+func newResult(limit Limit, allowed bool, tokens float64) Result {
+    result := Result{
+        Allowed:   allowed,
+        Limit:     limit.Burst,
+        Remaining: int(math.Floor(tokens)),
+        Reset:     limit.fillTime(tokens),
+    }
+    if !allowed {
+        if limit.RequestsPerMinute > 0 {
+            result.RetryAfter = time.Duration((1 - tokens) / limit.rate() * float64(time.Second))
+        } else {
+            result.RetryAfter = time.Minute
+        }
+    }
+    return result
+}
+
+// Store 令牌桶的存储后端。key 相同的请求共用一个令牌桶
+type Store interface {
+    // Take 从 key 对应的令牌桶中取一个令牌，桶不存在时按 limit 创建满桶
+    Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+var (
+    defaultMu    sync.RWMutex
+    defaultStore Store
+)
+
+// SetDefault 设置全局存储后端，通常在启动时调用一次
+func SetDefault(s Store) {
+    defaultMu.Lock()
+    defer defaultMu.Unlock()
+    defaultStore = s
+}
+
+// Default 返回全局存储后端，未设置时创建并保存一个内存存储
+func Default() Store {
+    defaultMu.RLock()
+    s := defaultStore
+    defaultMu.RUnlock()
+    if s != nil {
+        return s
+    }
+
+    defaultMu.Lock()
+    defer defaultMu.Unlock()
+    if defaultStore == nil {
+        defaultStore = NewMemoryStore()
+    }
+    return defaultStore
+}
+
+// FromEnv 按 RATE_LIMIT_BACKEND 环境变量（memory / redis，默认 memory）创建存储后端；
+// 多个实例部署时应使用 redis，使各实例共用令牌桶
+func FromEnv() (Store, error) {
+    switch backend := os.Getenv("RATE_LIMIT_BACKEND"); backend {
+    case "", "memory":
+        return NewMemoryStore(), nil
+    case "redis":
+        return NewRedisStore(RedisConfigFromEnv()), nil
+    default:
+        return nil, fmt.Errorf("unknown rate limit backend %q", backend)
+    }
+}
+
+// LimitFromEnv 读取 RATE_LIMIT_<NAME> 环境变量覆盖默认限制，格式为 "每分钟请求数,突发数"，如 RATE_LIMIT_AI=10,3；
+// 未设置或格式错误时返回 def
+func LimitFromEnv(name string, def Limit) Limit {
+    v := os.Getenv("RATE_LIMIT_" + strings.ToUpper(name))
+    if v == "" {
+        return def
+    }
+    parts := strings.Split(v, ",")
+    if len(parts) != 2 {
+        return def
+    }
+    rpm, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+    burst, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+    if err1 != nil || err2 != nil || rpm < 0 || burst < 1 {
+        return def
+    }
+    return Limit{RequestsPerMinute: rpm, Burst: burst}
+}
@@ -0,0 +1,238 @@
+// internal/ratelimit/ratelimit_test.go
+package ratelimit_test
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/ratelimit"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/ratelimit/redistest"
+)
+
+// testTokenBucket 两种存储后端的令牌桶行为应一致
+func testTokenBucket(t *testing.T, store ratelimit.Store) {
+    ctx := context.Background()
+    limit := ratelimit.Limit{RequestsPerMinute: 60, Burst: 3}
+    now := time.Unix(1700000000, 0)
+
+    for i := 0; i < 3; i++ {
+        result, err := store.Take(ctx, "bucket", limit, now)
+        assert.NoError(t, err)
+        assert.True(t, result.Allowed)
+        assert.Equal(t, 2-i, result.Remaining)
+        assert.Equal(t, 3, result.Limit)
+    }
+    result, err := store.Take(ctx, "bucket", limit, now)
+    assert.NoError(t, err)
+    assert.False(t, result.Allowed)
+    assert.Equal(t, 0, result.Remaining)
+    assert.Equal(t, time.Second, result.RetryAfter)
+    assert.Equal(t, 3*time.Second, result.Reset)
+
+    // 其他 Key 不受影响
+    result, err = store.Take(ctx, "other", limit, now)
+    assert.NoError(t, err)
+    assert.True(t, result.Allowed)
+
+    // 每秒补充一个令牌，最多补满 Burst 个
+    result, _ = store.Take(ctx, "bucket", limit, now.Add(1500*time.Millisecond))
+    assert.True(t, result.Allowed)
+    assert.Equal(t, 0, result.Remaining)
+    assert.Equal(t, 2500*time.Millisecond, result.Reset)
+    result, _ = store.Take(ctx, "bucket", limit, now.Add(time.Hour))
+    assert.True(t, result.Allowed)
+    assert.Equal(t, 2, result.Remaining)
+}
+
+func TestMemoryStore(t *testing.T) {
+    testTokenBucket(t, ratelimit.NewMemoryStore())
+}
+
+func TestRedisStore(t *testing.T) {
+    server := redistest.NewServer()
+    defer server.Close()
+    store := server.Store()
+    defer store.Close()
+
+    testTokenBucket(t, store)
+    assert.ElementsMatch(t, []string{ratelimit.DefaultRedisKeyPrefix + "bucket", ratelimit.DefaultRedisKeyPrefix + "other"}, server.Keys())
+
+    // 错误回复不影响后续请求
+    server.FailNext(1)
+    _, err := store.Take(context.Background(), "bucket", ratelimit.Limit{RequestsPerMinute: 60, Burst: 3}, time.Now())
+    var redisErr *ratelimit.RedisError
+    assert.True(t, errors.As(err, &redisErr))
+    _, err = store.Take(context.Background(), "bucket", ratelimit.Limit{RequestsPerMinute: 60, Burst: 3}, time.Now())
+    assert.NoError(t, err)
+
+    // 需要密码的服务
+    secured := redistest.NewServerWithPassword("secret")
+    defer secured.Close()
+    cfg := secured.Config()
+    _, err = ratelimit.NewRedisStore(ratelimit.RedisConfig{Addr: cfg.Addr}).Take(context.Background(), "k", ratelimit.Limit{RequestsPerMinute: 60, Burst: 1}, time.Now())
+    assert.True(t, errors.As(err, &redisErr))
+    _, err = ratelimit.NewRedisStore(cfg).Take(context.Background(), "k", ratelimit.Limit{RequestsPerMinute: 60, Burst: 1}, time.Now())
+    assert.NoError(t, err)
+    assert.Equal(t, 1, secured.Commands("AUTH"))
+}
+
+func TestLimitFromEnv(t *testing.T) {
+    def := ratelimit.Limit{RequestsPerMinute: 10, Burst: 5}
+    assert.Equal(t, def, ratelimit.LimitFromEnv("comment", def))
+
+    t.Setenv("RATE_LIMIT_COMMENT", "30, 10")
+    assert.Equal(t, ratelimit.Limit{RequestsPerMinute: 30, Burst: 10}, ratelimit.LimitFromEnv("comment", def))
+
+    for _, v := range []string{"30", "a,b", "30,0", "-1,5"} {
+        t.Setenv("RATE_LIMIT_COMMENT", v)
+        assert.Equal(t, def, ratelimit.LimitFromEnv("comment", def), v)
+    }
+
+    t.Setenv("RATE_LIMIT_BACKEND", "memcached")
+    _, err := ratelimit.FromEnv()
+    assert.Error(t, err)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+    ratelimit.SetDefault(ratelimit.NewMemoryStore())
+    defer ratelimit.SetDefault(nil)
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.Use(func(c *gin.Context) {
+        if user := c.GetHeader("X-Test-User"); user != "" {
+            c.Set("user_id", user)
+        }
+        c.Next()
+    })
+    router.GET("/limited", middleware.RateLimitMiddleware("test", ratelimit.Limit{RequestsPerMinute: 6, Burst: 2}), func(c *gin.Context) {
+        c.Status(http.StatusOK)
+    })
+
+    request := func(ip, user string) *httptest.ResponseRecorder {
+        req, _ := http.NewRequest("GET", "/limited", nil)
+        req.RemoteAddr = ip + ":12345"
+        if user != "" {
+            req.Header.Set("X-Test-User", user)
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    w := request("10.1.0.1", "")
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+    assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+    assert.Equal(t, http.StatusOK, request("10.1.0.1", "").Code)
+
+    w = request("10.1.0.1", "")
+    assert.Equal(t, http.StatusTooManyRequests, w.Code)
+    assert.Equal(t, "10", w.Header().Get("Retry-After"))
+    assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+    assert.Equal(t, "20", w.Header().Get("X-RateLimit-Reset"))
+    assert.JSONEq(t, `{"error":"Too many requests"}`, w.Body.String())
+
+    // 其他 IP 和登录用户各自计数；同一用户换 IP 仍共用令牌桶
+    assert.Equal(t, http.StatusOK, request("10.1.0.2", "").Code)
+    assert.Equal(t, http.StatusOK, request("10.1.0.1", "7").Code)
+    assert.Equal(t, http.StatusOK, request("10.1.0.3", "7").Code)
+    assert.Equal(t, http.StatusTooManyRequests, request("10.1.0.4", "7").Code)
+
+    // 存储后端不可用时放行
+    server := redistest.NewServer()
+    defer server.Close()
+    ratelimit.SetDefault(server.Store())
+    server.FailNext(10)
+    assert.Equal(t, http.StatusOK, request("10.1.0.1", "").Code)
+}
+
+// TestRateLimitSpoofedForwardedFor 客户端伪造的 X-Forwarded-For 不能换来新的令牌桶，只采信信任的代理转发的地址
+func TestRateLimitSpoofedForwardedFor(t *testing.T) {
+    ratelimit.SetDefault(ratelimit.NewMemoryStore())
+    defer ratelimit.SetDefault(nil)
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    t.Setenv("TRUSTED_PROXIES", "10.2.0.100, 10.3.0.0/16")
+    assert.NoError(t, middleware.ConfigureTrustedProxies(router))
+    router.POST("/users/auth", middleware.RateLimitMiddleware("auth", ratelimit.Limit{RequestsPerMinute: 6, Burst: 2}), func(c *gin.Context) {
+        c.Status(http.StatusOK)
+    })
+
+    request := func(remoteIP, forwardedFor string) int {
+        req, _ := http.NewRequest("POST", "/users/auth", nil)
+        req.RemoteAddr = remoteIP + ":12345"
+        if forwardedFor != "" {
+            req.Header.Set("X-Forwarded-For", forwardedFor)
+            req.Header.Set("X-Real-IP", forwardedFor)
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w.Code
+    }
+
+    // 直接连接的客户端每次伪造不同的地址，仍按连接地址共用一个令牌桶
+    assert.Equal(t, http.StatusOK, request("10.2.0.1", "1.1.1.1"))
+    assert.Equal(t, http.StatusOK, request("10.2.0.1", "1.1.1.2"))
+    assert.Equal(t, http.StatusTooManyRequests, request("10.2.0.1", "1.1.1.3"))
+
+    // 经信任的代理转发时按代理追加的客户端地址计数，客户端在前面伪造的地址不起作用
+    assert.Equal(t, http.StatusOK, request("10.2.0.100", "2.2.2.2"))
+    assert.Equal(t, http.StatusOK, request("10.3.0.7", "9.9.9.9, 2.2.2.2"))
+    assert.Equal(t, http.StatusTooManyRequests, request("10.2.0.100", "8.8.8.8, 2.2.2.2"))
+    assert.Equal(t, http.StatusOK, request("10.2.0.100", "2.2.2.3"))
+
+    // 默认不信任任何代理
+    t.Setenv("TRUSTED_PROXIES", "")
+    assert.NoError(t, middleware.ConfigureTrustedProxies(router))
+    assert.Equal(t, http.StatusOK, request("10.2.0.100", "3.3.3.3"))
+    assert.Equal(t, http.StatusOK, request("10.2.0.100", "3.3.3.4"))
+    assert.Equal(t, http.StatusTooManyRequests, request("10.2.0.100", "3.3.3.5"))
+
+    t.Setenv("TRUSTED_PROXIES", "not-an-ip")
+    assert.Error(t, middleware.ConfigureTrustedProxies(router))
+}
+
+// TestAnonymousRateLimitFromEnv 公开接口的按 IP 限流与其他路由组一样可用 RATE_LIMIT_PUBLIC 覆盖，登录用户不受限制
+func TestAnonymousRateLimitFromEnv(t *testing.T) {
+    ratelimit.SetDefault(ratelimit.NewMemoryStore())
+    defer ratelimit.SetDefault(nil)
+    t.Setenv("RATE_LIMIT_PUBLIC", "60,1")
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.Use(func(c *gin.Context) {
+        if user := c.GetHeader("X-Test-User"); user != "" {
+            c.Set("user_id", user)
+        }
+        c.Next()
+    })
+    router.GET("/news/feed", middleware.AnonymousRateLimitMiddleware(ratelimit.Limit{RequestsPerMinute: 60, Burst: 20}), func(c *gin.Context) {
+        c.Status(http.StatusOK)
+    })
+
+    request := func(user string) *httptest.ResponseRecorder {
+        req, _ := http.NewRequest("GET", "/news/feed", nil)
+        req.RemoteAddr = "10.4.0.1:12345"
+        if user != "" {
+            req.Header.Set("X-Test-User", user)
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    w := request("")
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+    assert.Equal(t, http.StatusTooManyRequests, request("").Code)
+    assert.Equal(t, http.StatusOK, request("7").Code)
+}
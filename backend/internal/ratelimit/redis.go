@@ -0,0 +1,294 @@
+// internal/ratelimit/redis.go
+package ratelimit
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// 默认的 Redis 连接配置
+const (
+    DefaultRedisAddr      = "127.0.0.1:6379"
+    DefaultRedisTimeout   = 500 * time.Millisecond
+    DefaultRedisPoolSize  = 8
+    DefaultRedisKeyPrefix = "ratelimit:"
+)
+
+// RedisConfig Redis（或兼容 Redis 协议的服务）的连接配置
+type RedisConfig struct {
+    Addr      string
+    Password  string
+    DB        int
+    Timeout   time.Duration // 连接和单次命令的超时时间
+    PoolSize  int           // 最多保留的空闲连接数
+    KeyPrefix string
+}
+
+// RedisConfigFromEnv 从 REDIS_ADDR、REDIS_PASSWORD、REDIS_DB 环境变量读取配置
+func RedisConfigFromEnv() RedisConfig {
+    cfg := RedisConfig{
+        Addr:     os.Getenv("REDIS_ADDR"),
+        Password: os.Getenv("REDIS_PASSWORD"),
+    }
+    if v := os.Getenv("REDIS_DB"); v != "" {
+        if db, err := strconv.Atoi(v); err == nil {
+            cfg.DB = db
+        }
+    }
+    return cfg
+}
+
+// RedisError Redis 返回的错误回复
+type RedisError struct {
+    Message string
+}
+
+func (e *RedisError) Error() string {
+    return "redis: " + e.Message
+}
+
+// tokenBucketScript 在 Redis 中原子地补充并取出令牌。
+// KEYS[1] 为令牌桶，ARGV 依次为每秒补充的令牌数、桶容量、当前时间（毫秒）；
+// 返回 {是否允许, 剩余令牌数}，剩余令牌数为字符串以保留小数部分
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+    tokens = burst
+    ts = now
+end
+if now > ts then
+    tokens = math.min(burst, tokens + (now - ts) / 1000 * rate)
+    ts = now
+end
+local allowed = 0
+if tokens >= 1 then
+    tokens = tokens - 1
+    allowed = 1
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+local ttl = 60000
+if rate > 0 then
+    ttl = math.ceil((burst - tokens) / rate * 1000) + 1000
+end
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`
+
+// RedisStore 保存在 Redis 中的令牌桶，多个实例共用。使用 EVAL 执行 Lua 脚本，需要 Redis 2.6 以上
+type RedisStore struct {
+    cfg  RedisConfig
+    idle chan *redisConn
+}
+
+// NewRedisStore 创建 Redis 存储，连接在第一次使用时建立
+func NewRedisStore(cfg RedisConfig) *RedisStore {
+    if cfg.Addr == "" {
+        cfg.Addr = DefaultRedisAddr
+    }
+    if cfg.Timeout <= 0 {
+        cfg.Timeout = DefaultRedisTimeout
+    }
+    if cfg.PoolSize <= 0 {
+        cfg.PoolSize = DefaultRedisPoolSize
+    }
+    if cfg.KeyPrefix == "" {
+        cfg.KeyPrefix = DefaultRedisKeyPrefix
+    }
+    return &RedisStore{cfg: cfg, idle: make(chan *redisConn, cfg.PoolSize)}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+    reply, err := s.do(ctx, "EVAL", tokenBucketScript, "1", s.cfg.KeyPrefix+key,
+        strconv.FormatFloat(limit.rate(), 'f', -1, 64),
+        strconv.Itoa(limit.Burst),
+        strconv.FormatInt(now.UnixMilli(), 10))
+    if err != nil {
+        return Result{}, err
+    }
+
+    values, ok := reply.([]interface{})
+    if !ok || len(values) != 2 {
+        return Result{}, fmt.Errorf("redis: unexpected reply %v", reply)
+    }
+    allowed, ok := values[0].(int64)
+    if !ok {
+        return Result{}, fmt.Errorf("redis: unexpected reply %v", reply)
+    }
+    tokensStr, ok := values[1].(string)
+    if !ok {
+        return Result{}, fmt.Errorf("redis: unexpected reply %v", reply)
+    }
+    tokens, err := strconv.ParseFloat(tokensStr, 64)
+    if err != nil {
+        return Result{}, fmt.Errorf("redis: unexpected reply %v", reply)
+    }
+    return newResult(limit, allowed == 1, tokens), nil
+}
+
+// Close 关闭所有空闲连接
+func (s *RedisStore) Close() error {
+    for {
+        select {
+        case conn := <-s.idle:
+            conn.Close()
+        default:
+            return nil
+        }
+    }
+}
+
+// do 执行一条命令。出错的连接直接关闭，不放回连接池
+func (s *RedisStore) do(ctx context.Context, args ...string) (interface{}, error) {
+    conn, err := s.get(ctx)
+    if err != nil {
+        return nil, err
+    }
+    reply, err := conn.do(ctx, s.cfg.Timeout, args...)
+    var redisErr *RedisError
+    if err != nil && !errors.As(err, &redisErr) {
+        conn.Close()
+        return nil, err
+    }
+    s.put(conn)
+    return reply, err
+}
+
+func (s *RedisStore) get(ctx context.Context) (*redisConn, error) {
+    select {
+    case conn := <-s.idle:
+        return conn, nil
+    default:
+    }
+
+    dialer := net.Dialer{Timeout: s.cfg.Timeout}
+    netConn, err := dialer.DialContext(ctx, "tcp", s.cfg.Addr)
+    if err != nil {
+        return nil, err
+    }
+    conn := &redisConn{Conn: netConn, r: bufio.NewReader(netConn)}
+    if s.cfg.Password != "" {
+        if _, err := conn.do(ctx, s.cfg.Timeout, "AUTH", s.cfg.Password); err != nil {
+            conn.Close()
+            return nil, err
+        }
+    }
+    if s.cfg.DB != 0 {
+        if _, err := conn.do(ctx, s.cfg.Timeout, "SELECT", strconv.Itoa(s.cfg.DB)); err != nil {
+            conn.Close()
+            return nil, err
+        }
+    }
+    return conn, nil
+}
+
+func (s *RedisStore) put(conn *redisConn) {
+    select {
+    case s.idle <- conn:
+    default:
+        conn.Close()
+    }
+}
+
+// redisConn 使用 RESP 协议通信的连接
+type redisConn struct {
+    net.Conn
+    r *bufio.Reader
+}
+
+func (c *redisConn) do(ctx context.Context, timeout time.Duration, args ...string) (interface{}, error) {
+    deadline := time.Now().Add(timeout)
+    if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+        deadline = d
+    }
+    if err := c.SetDeadline(deadline); err != nil {
+        return nil, err
+    }
+
+    var b strings.Builder
+    fmt.Fprintf(&b, "*%d\r\n", len(args))
+    for _, arg := range args {
+        fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+    }
+    if _, err := io.WriteString(c.Conn, b.String()); err != nil {
+        return nil, err
+    }
+    return ReadReply(c.r)
+}
+
+// ReadReply 读取一条 RESP 回复：简单字符串和批量字符串返回 string，整数返回 int64，
+// 数组返回 []interface{}，空值返回 nil，错误回复返回 *RedisError
+func ReadReply(r *bufio.Reader) (interface{}, error) {
+    line, err := readLine(r)
+    if err != nil {
+        return nil, err
+    }
+    if len(line) == 0 {
+        return nil, errors.New("redis: empty reply")
+    }
+
+    switch line[0] {
+    case '+':
+        return line[1:], nil
+    case '-':
+        return nil, &RedisError{Message: line[1:]}
+    case ':':
+        return strconv.ParseInt(line[1:], 10, 64)
+    case '$':
+        n, err := strconv.Atoi(line[1:])
+        if err != nil {
+            return nil, err
+        }
+        if n < 0 {
+            return nil, nil
+        }
+        buf := make([]byte, n+2)
+        if _, err := io.ReadFull(r, buf); err != nil {
+            return nil, err
+        }
+        return string(buf[:n]), nil
+    case '*':
+        n, err := strconv.Atoi(line[1:])
+        if err != nil {
+            return nil, err
+        }
+        if n < 0 {
+            return nil, nil
+        }
+        values := make([]interface{}, n)
+        for i := range values {
+            v, err := ReadReply(r)
+            var redisErr *RedisError
+            if err != nil && !errors.As(err, &redisErr) {
+                return nil, err
+            }
+            if err != nil {
+                v = err
+            }
+            values[i] = v
+        }
+        return values, nil
+    default:
+        return nil, fmt.Errorf("redis: unexpected reply %q", line)
+    }
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+    line, err := r.ReadString('\n')
+    if err != nil {
+        return "", err
+    }
+    return strings.TrimSuffix(line, "\r\n"), nil
+}
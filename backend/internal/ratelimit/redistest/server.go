@@ -0,0 +1,192 @@
+// internal/ratelimit/redistest/server.go
+package redistest
+
+import (
+    "bufio"
+    "fmt"
+    "math"
+    "net"
+    "strconv"
+    "strings"
+    "sync"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/ratelimit"
+)
+
+// Server 测试用的 Redis 服务，只实现 ratelimit.RedisStore 用到的命令。
+// EVAL 不解释 Lua 脚本，而是直接按令牌桶脚本的语义执行
+type Server struct {
+    password string // 非空时要求先 AUTH，启动后不再修改
+
+    listener net.Listener
+    mu       sync.Mutex
+    buckets  map[string]bucket
+    failNext int
+    commands map[string]int
+    wg       sync.WaitGroup
+}
+
+type bucket struct {
+    tokens float64
+    ts     int64
+}
+
+// NewServer 在本地随机端口启动服务
+func NewServer() *Server {
+    return NewServerWithPassword("")
+}
+
+// NewServerWithPassword 启动要求先用 password 认证的服务
+func NewServerWithPassword(password string) *Server {
+    l, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        panic(fmt.Sprintf("redistest: failed to listen: %v", err))
+    }
+    s := &Server{password: password, listener: l, buckets: make(map[string]bucket), commands: make(map[string]int)}
+    s.wg.Add(1)
+    go s.serve()
+    return s
+}
+
+// Addr 服务地址
+func (s *Server) Addr() string {
+    return s.listener.Addr().String()
+}
+
+// Config 连接该服务的配置
+func (s *Server) Config() ratelimit.RedisConfig {
+    return ratelimit.RedisConfig{Addr: s.Addr(), Password: s.password}
+}
+
+// Store 连接该服务的 ratelimit.RedisStore
+func (s *Server) Store() *ratelimit.RedisStore {
+    return ratelimit.NewRedisStore(s.Config())
+}
+
+// Close 停止服务
+func (s *Server) Close() {
+    s.listener.Close()
+    s.wg.Wait()
+}
+
+// FailNext 接下来的 n 条命令返回错误回复
+func (s *Server) FailNext(n int) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.failNext = n
+}
+
+// Commands 返回收到的 name 命令的次数
+func (s *Server) Commands(name string) int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.commands[strings.ToUpper(name)]
+}
+
+// Keys 返回保存的令牌桶 Key
+func (s *Server) Keys() []string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    keys := make([]string, 0, len(s.buckets))
+    for k := range s.buckets {
+        keys = append(keys, k)
+    }
+    return keys
+}
+
+func (s *Server) serve() {
+    defer s.wg.Done()
+    for {
+        conn, err := s.listener.Accept()
+        if err != nil {
+            return
+        }
+        go s.handle(conn)
+    }
+}
+
+func (s *Server) handle(conn net.Conn) {
+    defer conn.Close()
+    r := bufio.NewReader(conn)
+    authed := s.password == ""
+    for {
+        req, err := ratelimit.ReadReply(r)
+        if err != nil {
+            return
+        }
+        values, ok := req.([]interface{})
+        if !ok || len(values) == 0 {
+            conn.Write([]byte("-ERR invalid request\r\n"))
+            continue
+        }
+        args := make([]string, len(values))
+        for i, v := range values {
+            args[i], _ = v.(string)
+        }
+        name := strings.ToUpper(args[0])
+
+        s.mu.Lock()
+        s.commands[name]++
+        fail := s.failNext > 0
+        if fail {
+            s.failNext--
+        }
+        s.mu.Unlock()
+
+        switch {
+        case fail:
+            conn.Write([]byte("-ERR injected failure\r\n"))
+        case name == "AUTH":
+            if len(args) == 2 && args[1] == s.password {
+                authed = true
+                conn.Write([]byte("+OK\r\n"))
+            } else {
+                conn.Write([]byte("-WRONGPASS invalid password\r\n"))
+            }
+        case !authed:
+            conn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+        case name == "PING":
+            conn.Write([]byte("+PONG\r\n"))
+        case name == "SELECT":
+            conn.Write([]byte("+OK\r\n"))
+        case name == "EVAL":
+            conn.Write([]byte(s.eval(args)))
+        default:
+            conn.Write([]byte("-ERR unknown command '" + args[0] + "'\r\n"))
+        }
+    }
+}
+
+// eval 按 ratelimit 令牌桶脚本的语义执行：EVAL script 1 key rate burst now
+func (s *Server) eval(args []string) string {
+    if len(args) != 7 || args[2] != "1" {
+        return "-ERR wrong number of arguments for 'eval' command\r\n"
+    }
+    key := args[3]
+    rate, err1 := strconv.ParseFloat(args[4], 64)
+    burst, err2 := strconv.ParseFloat(args[5], 64)
+    now, err3 := strconv.ParseInt(args[6], 10, 64)
+    if err1 != nil || err2 != nil || err3 != nil {
+        return "-ERR invalid arguments\r\n"
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    b, ok := s.buckets[key]
+    if !ok {
+        b = bucket{tokens: burst, ts: now}
+    }
+    if now > b.ts {
+        b.tokens = math.Min(burst, b.tokens+float64(now-b.ts)/1000*rate)
+        b.ts = now
+    }
+    allowed := 0
+    if b.tokens >= 1 {
+        b.tokens--
+        allowed = 1
+    }
+    s.buckets[key] = b
+
+    tokens := strconv.FormatFloat(b.tokens, 'f', -1, 64)
+    return fmt.Sprintf("*2\r\n:%d\r\n$%d\r\n%s\r\n", allowed, len(tokens), tokens)
+}
@@ -0,0 +1,21 @@
+// routes/admin_routes.go
+package routes
+
+import (
+    "gorm.io/gorm"
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+)
+
+// RegisterAdminRoutes 注册管理员路由，仅 ADMIN_USER_IDS 中的用户可访问
+func RegisterAdminRoutes(router gin.IRouter, db *gorm.DB) {
+    uploadGCController := controllers.NewUploadGCController(db)
+    adminGroup := router.Group("/admin")
+    adminGroup.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+    {
+        // 上传文件垃圾回收
+        adminGroup.GET("/uploads/orphans", uploadGCController.ListOrphanUploads) // 只报告，不删除
+        adminGroup.POST("/uploads/gc", uploadGCController.CollectUploads)        // 删除超过宽限期的孤立文件，dry_run=true 时只报告
+    }
+}
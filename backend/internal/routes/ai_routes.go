@@ -3,13 +3,14 @@ package routes
 
 import (
     "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/config"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
 
     "github.com/gin-gonic/gin"
 )
 
-func RegisterAIRoutes(router *gin.Engine, db *gorm.DB) {
+func RegisterAIRoutes(router gin.IRouter, db *gorm.DB) {
     aiController := controllers.AIController{
         DB: db,
     }
@@ -18,7 +19,7 @@ func RegisterAIRoutes(router *gin.Engine, db *gorm.DB) {
     {
         // 需要认证的路由
         authGroup := aiGroup.Group("")
-        authGroup.Use(middleware.AuthMiddleware())
+        authGroup.Use(middleware.AuthMiddleware(), middleware.RateLimitMiddleware("ai", config.RateLimits.AI))
         {
             // 分析图像
             authGroup.POST("/analyze-image", aiController.AnalyzeImage)
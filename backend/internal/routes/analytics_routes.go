@@ -0,0 +1,21 @@
+// routes/analytics_routes.go
+package routes
+
+import (
+    "gorm.io/gorm"
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+)
+
+func RegisterAnalyticsRoutes(router gin.IRouter, db *gorm.DB) {
+    analyticsController := controllers.NewAnalyticsController(db)
+    analyticsGroup := router.Group("/analytics")
+    analyticsGroup.Use(middleware.AuthMiddleware())
+    {
+        analyticsGroup.GET("/overview", analyticsController.GetOverview)      // 作者整体数据和关注者增长
+        analyticsGroup.GET("/news/:id", analyticsController.GetNewsAnalytics) // 单篇新闻的数据
+        analyticsGroup.GET("/top", analyticsController.GetTopNews)            // 时间段内表现最好的新闻
+        analyticsGroup.GET("/export", analyticsController.ExportAnalytics)    // 导出 CSV
+    }
+}
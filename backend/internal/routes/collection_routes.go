@@ -0,0 +1,26 @@
+// routes/collection_routes.go
+package routes
+
+import (
+    "gorm.io/gorm"
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+)
+
+func RegisterCollectionRoutes(router gin.IRouter, db *gorm.DB) {
+    collectionController := controllers.NewCollectionController(db)
+    collectionGroup := router.Group("/collections")
+    collectionGroup.Use(middleware.AuthMiddleware())
+    {
+        collectionGroup.GET("", collectionController.GetCollections)              // 自己的收藏夹，或 ?user_id= 指定用户的公开收藏夹
+        collectionGroup.POST("", collectionController.CreateCollection)           // 新建收藏夹
+        collectionGroup.PUT("/order", collectionController.ReorderCollections)    // 调整收藏夹顺序
+        collectionGroup.GET("/:id", collectionController.GetCollection)           // 查看收藏夹内容
+        collectionGroup.PUT("/:id", collectionController.UpdateCollection)        // 修改名称、描述、公开状态
+        collectionGroup.DELETE("/:id", collectionController.DeleteCollection)     // 删除收藏夹
+        collectionGroup.POST("/:id/items", collectionController.AddCollectionItem) // 添加新闻或食谱
+        collectionGroup.PUT("/:id/items/order", collectionController.ReorderCollectionItems) // 调整条目顺序
+        collectionGroup.DELETE("/:id/items/:item_id", collectionController.RemoveCollectionItem) // 移除条目
+    }
+}
@@ -9,7 +9,7 @@ import (
     "github.com/gin-gonic/gin"
 )
 
-func RegisterFamilyRoutes(router *gin.Engine, db *gorm.DB) {
+func RegisterFamilyRoutes(router gin.IRouter, db *gorm.DB) {
     familyController := controllers.NewFamilyController(db)
 
     familyGroup := router.Group("/families")
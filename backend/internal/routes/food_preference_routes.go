@@ -9,7 +9,7 @@ import (
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
 )
 
-func RegisterFoodPreferenceRoutes(router *gin.Engine, db *gorm.DB) {
+func RegisterFoodPreferenceRoutes(router gin.IRouter, db *gorm.DB) {
     fpc := &controllers.FoodPreferenceController{DB: db}
     
     // 添加认证中间件
@@ -24,5 +24,10 @@ func RegisterFoodPreferenceRoutes(router *gin.Engine, db *gorm.DB) {
         authorized.POST("/disliked_preferences", fpc.AddDislikedFoodPreference)
         authorized.DELETE("/disliked_preferences", fpc.DeleteDislikedFoodPreference)
         authorized.GET("/disliked_preferences", fpc.GetUserDislikedPreferences)
+
+        // 饮食档案：过敏原、饮食限制、烹饪水平、厨房设备和每餐预算
+        authorized.GET("/dietary_profile/options", fpc.GetDietaryOptions)
+        authorized.GET("/dietary_profile", fpc.GetDietaryProfile)
+        authorized.PUT("/dietary_profile", fpc.UpdateDietaryProfile)
     }
 }
\ No newline at end of file
@@ -8,7 +8,7 @@ import (
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
 )
 
-func RegisterFoodRoutes(router *gin.Engine, db *gorm.DB) {
+func RegisterFoodRoutes(router gin.IRouter, db *gorm.DB) {
     foodController := controllers.NewFoodController(db)
     foodGroup := router.Group("/foods")
     {
@@ -0,0 +1,14 @@
+// routes/jwks_routes.go
+package routes
+
+import (
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/keyring"
+)
+
+// RegisterJWKSRoutes 注册 JWT 公钥集合的标准地址
+func RegisterJWKSRoutes(router *gin.Engine, keys *keyring.Keyring) {
+    jwksController := controllers.NewJWKSController(keys)
+    router.GET("/.well-known/jwks.json", jwksController.GetJWKS)
+}
@@ -4,67 +4,103 @@ package routes
 import (
     "gorm.io/gorm"
     "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/config"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
 )
 
-func RegisterNewsRoutes(router *gin.Engine, db *gorm.DB) {
+func RegisterNewsRoutes(router gin.IRouter, db *gorm.DB) {
     newsController := controllers.NewNewsController(db)
+    commentLimit := middleware.RateLimitMiddleware("comment", config.RateLimits.Comment)
+    likeLimit := middleware.RateLimitMiddleware("like", config.RateLimits.Like)
+    uploadLimit := middleware.RateLimitMiddleware("upload", config.RateLimits.Upload)
+    shareLimit := middleware.RateLimitMiddleware("share", config.RateLimits.Share)
+
     newsGroup := router.Group("/news")
     {
+        // 公开的只读路由：未登录也可访问已发布的新闻，匿名请求按 IP 限流；
+        // 携带令牌时按登录用户处理，返回点赞状态等个人字段
+        publicGroup := newsGroup.Group("")
+        publicGroup.Use(
+            middleware.OptionalAuthMiddleware(),
+            middleware.AnonymousRateLimitMiddleware(config.PublicRateLimit),
+        )
+        {
+            // 预览新闻
+            publicGroup.POST("/preview_news", newsController.PreviewNews)
+            // 详细查看新闻
+            publicGroup.GET("/details/news/:id", newsController.GetNewsDetails)
+            // 获取新闻 ID
+            publicGroup.GET("/paginated/view_count", newsController.GetNewsByViewCount) // 观看量降序
+            publicGroup.GET("/paginated/like_count", newsController.GetNewsByLikeCount) // 点赞量降序
+            publicGroup.GET("/paginated/upload_time", newsController.GetNewsByUploadTime) // 时间由旧到新
+            // 分页获取预览卡片，sort 为 view_count、like_count 或 upload_time
+            publicGroup.GET("/feed", newsController.GetNewsFeed)
+            // 评论
+            publicGroup.GET("/comments/:id/replies", newsController.GetCommentReplies) // 分页获取评论的回复
+            publicGroup.GET("/:id/comments", newsController.GetNewsComments)          // 分页获取新闻的顶级评论
+            // 分享卡片
+            publicGroup.GET("/:id/share_card", newsController.GetShareCard)   // 预览分享卡片
+            publicGroup.GET("/shared/:code", newsController.ResolveShareCode) // 解析分享短码
+            publicGroup.POST("/search", newsController.SearchNews)
+        }
+
         // 需要认证的路由
         authGroup := newsGroup.Group("")
         authGroup.Use(middleware.AuthMiddleware())
         {
-            authGroup.POST("/upload_image", newsController.UploadImage)      // 上传单张图片
+            authGroup.POST("/upload_image", uploadLimit, newsController.UploadImage)      // 上传单张图片
             authGroup.POST("/create_draft", newsController.CreateDraft)     // 创建草稿
             // 更新草稿
             authGroup.PUT("/drafts/:id", newsController.UpdateDraft)
             // 删除草稿
             authGroup.DELETE("/drafts/:id", newsController.DeleteDraft)
+            // 草稿历史版本
+            authGroup.GET("/drafts/:id/revisions", newsController.GetDraftRevisions)
+            authGroup.GET("/drafts/:id/revisions/diff", newsController.DiffDraftRevisions)
+            authGroup.POST("/drafts/:id/revisions/:version/restore", newsController.RestoreDraftRevision)
+            // 定时发布
+            authGroup.PUT("/drafts/:id/schedule", newsController.ScheduleDraft)
+            authGroup.DELETE("/drafts/:id/schedule", newsController.CancelScheduleDraft)
             // 将草稿转换为新闻
             authGroup.POST("/convert_draft", newsController.ConvertDraftToNews)
             // 获取自己的新闻 ID 列表
             authGroup.GET("/my_news", newsController.GetMyNews)
             // 获取自己的草稿 ID 列表
             authGroup.GET("/my_drafts", newsController.GetMyDrafts)
-            // 预览新闻
-            authGroup.POST("/preview_news", newsController.PreviewNews)
             // 预览草稿
             authGroup.POST("/preview_drafts", newsController.PreviewDrafts)
-            // 详细查看新闻
-            authGroup.GET("/details/news/:id", newsController.GetNewsDetails)
             // 详细查看草稿
             authGroup.GET("/details/draft/:id", newsController.GetDraftDetails)
             // 删除新闻
             authGroup.DELETE("/:id", newsController.DeleteNews)
-            
-            // 获取新闻 ID
-            authGroup.GET("/paginated/view_count", newsController.GetNewsByViewCount) // 观看量降序
-            authGroup.GET("/paginated/like_count", newsController.GetNewsByLikeCount) // 点赞量降序
-            authGroup.GET("/paginated/upload_time", newsController.GetNewsByUploadTime) // 时间由旧到新
+            // 修订已发布的新闻
+            authGroup.PUT("/:id", newsController.ReviseNews)
+            authGroup.GET("/:id/revisions", newsController.GetNewsRevisions)
 
             // 评论相关
-            authGroup.POST("/comments", newsController.AddComment)      // 添加评论
+            authGroup.POST("/comments", commentLimit, newsController.AddComment)      // 添加评论
             authGroup.DELETE("/comments/:id", newsController.DeleteComment) // 删除评论
+            authGroup.PUT("/comments/:id", commentLimit, newsController.EditComment)      // 编辑评论
             // 点赞相关
-            authGroup.POST("/:id/like", newsController.LikeNews)            // 点赞新闻
-            authGroup.DELETE("/:id/like", newsController.CancelLikeNews)   // 取消点赞新闻
+            authGroup.POST("/:id/like", likeLimit, newsController.LikeNews)            // 点赞新闻
+            authGroup.DELETE("/:id/like", likeLimit, newsController.CancelLikeNews)   // 取消点赞新闻
             // 收藏相关
-            authGroup.POST("/:id/favorite", newsController.FavoriteNews)          // 收藏新闻
-            authGroup.DELETE("/:id/favorite", newsController.CancelFavoriteNews)  // 取消收藏新闻
+            authGroup.POST("/:id/favorite", likeLimit, newsController.FavoriteNews)          // 收藏新闻
+            authGroup.DELETE("/:id/favorite", likeLimit, newsController.CancelFavoriteNews)  // 取消收藏新闻
             // 点踩相关
-            authGroup.POST("/:id/dislike", newsController.DislikeNews)            // 点踩新闻
-            authGroup.DELETE("/:id/dislike", newsController.CancelDislikeNews)   // 取消点踩新闻
+            authGroup.POST("/:id/dislike", likeLimit, newsController.DislikeNews)            // 点踩新闻
+            authGroup.DELETE("/:id/dislike", likeLimit, newsController.CancelDislikeNews)   // 取消点踩新闻
             // 浏览记录
             authGroup.POST("/:id/view", newsController.ViewNews) // 浏览新闻
+            authGroup.PUT("/views/:id", newsController.ReportViewProgress) // 上报阅读时长和滚动深度
+            // 分享相关
+            authGroup.POST("/:id/share", shareLimit, newsController.ShareNews) // 记录分享并获取分享短码
 
             authGroup.GET("/:id/status", newsController.GetUserNewsStatus) // 返回用户对新闻的过往交互
 
-            authGroup.POST("/:id/comment_like", newsController.LikeComment) // 点赞评论
-            authGroup.DELETE("/:id/comment_like", newsController.CancelLikeComment) // 取消点赞评论
-
-            authGroup.POST("/search", newsController.SearchNews)
+            authGroup.POST("/:id/comment_like", likeLimit, newsController.LikeComment) // 点赞评论
+            authGroup.DELETE("/:id/comment_like", likeLimit, newsController.CancelLikeComment) // 取消点赞评论
         }
     }
 }
\ No newline at end of file
@@ -0,0 +1,20 @@
+// routes/notification_routes.go
+package routes
+
+import (
+    "gorm.io/gorm"
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+)
+
+func RegisterNotificationRoutes(router gin.IRouter, db *gorm.DB) {
+    notificationController := controllers.NewNotificationController(db)
+    notificationGroup := router.Group("/notifications")
+    notificationGroup.Use(middleware.AuthMiddleware())
+    {
+        notificationGroup.GET("", notificationController.GetNotifications)            // 通知列表，unread=true 时只返回未读
+        notificationGroup.GET("/unread_count", notificationController.GetUnreadCount) // 未读通知数
+        notificationGroup.POST("/read", notificationController.MarkNotificationsRead) // 标记已读，未提供 ids 时标记全部
+    }
+}
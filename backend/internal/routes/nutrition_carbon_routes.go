@@ -7,7 +7,7 @@ import (
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
 )
 
-func RegisterNutritionCarbonRoutes(router *gin.Engine, db *gorm.DB) {
+func RegisterNutritionCarbonRoutes(router gin.IRouter, db *gorm.DB) {
     nutritionCarbonController := &controllers.NutritionCarbonController{DB: db}
     
     // 创建营养和碳排放路由组
@@ -0,0 +1,181 @@
+// routes/openapi_docs.go
+package routes
+
+import (
+    "net/http"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/auth"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/keyring"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/openapi"
+)
+
+// apiDocs 各路由的接口说明，键为 "方法 路径"（/api/v1 下的路由去掉前缀）。
+// 请求体和响应使用控制器中的类型，生成文档时通过反射读取字段；新增路由时需在此补充，否则 TestOpenAPICoversRoutes 失败
+var apiDocs = map[string]openapi.Doc{
+    // 用户与登录
+    "POST /users/auth":              {Summary: "微信登录，新用户自动注册", Public: true, Request: controllers.WeChatAuthRequest{}},
+    "POST /users/refresh":           {Summary: "刷新访问令牌，同时轮换刷新令牌", Public: true, Request: controllers.RefreshTokenRequest{}},
+    "POST /users/logout":            {Summary: "登出并吊销刷新令牌", Public: true, Request: controllers.LogoutRequest{}},
+    "POST /users/login/:provider":   {Summary: "使用邮箱、手机号等方式登录", Public: true, Request: controllers.LoginRequest{}},
+    "POST /users/send_code":         {Summary: "发送登录验证码", Public: true, Request: auth.Credentials{}},
+    "POST /users/register":          {Summary: "使用邮箱或手机号注册", Public: true, Request: controllers.LoginRequest{}, Status: http.StatusCreated},
+    "PUT /users/set_nickname":       {Summary: "修改昵称", Request: controllers.SetNicknameRequest{}},
+    "POST /users/set_avatar":        {Summary: "上传头像", Request: openapi.Upload{File: "avatar"}},
+    "GET /users/basic_details":      {Summary: "获取当前用户的基本信息"},
+    "GET /users/liked":              {Summary: "获取自己点赞的新闻"},
+    "GET /users/favorited":          {Summary: "获取自己收藏的新闻", Query: []string{"collection_id:integer", "cursor", "limit:integer"}},
+    "GET /users/viewed":             {Summary: "获取自己浏览过的新闻"},
+    "GET /users/history":            {Summary: "分页获取阅读历史", Query: []string{"cursor", "limit:integer"}},
+    "DELETE /users/history":         {Summary: "清空阅读历史，指定 news_id 时只删除该新闻", Query: []string{"news_id:integer"}},
+    "GET /users/privacy":            {Summary: "获取隐私设置", Response: models.PrivacySettings{}},
+    "PUT /users/privacy":            {Summary: "修改隐私设置", Request: controllers.UpdatePrivacySettingsRequest{}, Response: models.PrivacySettings{}},
+    "GET /users/sessions":           {Summary: "获取已登录的设备会话"},
+    "DELETE /users/sessions/:id":    {Summary: "注销指定设备会话"},
+    "GET /users/identities":         {Summary: "获取已关联的登录方式"},
+    "POST /users/identities/:provider": {Summary: "关联新的登录方式", Request: controllers.LoginRequest{}, Status: http.StatusCreated},
+    "DELETE /users/identities/:id":  {Summary: "解除关联的登录方式"},
+    "GET /users/blocks":             {Summary: "获取已屏蔽的用户"},
+    "GET /users/:id/profile":        {Summary: "获取用户主页，未公开时只返回基本信息"},
+    "GET /users/:id/liked":          {Summary: "获取其他用户点赞的新闻，对方未公开时返回 403"},
+    "GET /users/:id/favorited":      {Summary: "获取其他用户收藏的新闻，对方未公开时返回 403"},
+    "POST /users/:id/follow":        {Summary: "关注用户"},
+    "DELETE /users/:id/follow":      {Summary: "取消关注用户"},
+    "POST /users/:id/block":         {Summary: "屏蔽用户"},
+    "DELETE /users/:id/block":       {Summary: "取消屏蔽用户"},
+    "GET /users/account/export":     {Summary: "导出个人数据，format=zip 时返回压缩包", Query: []string{"format"}, Response: controllers.AccountExport{}},
+    "GET /users/account/deletion":   {Summary: "查询账号注销状态"},
+    "POST /users/account/deletion":  {Summary: "申请注销账号，宽限期后删除数据", Status: http.StatusAccepted},
+    "DELETE /users/account/deletion": {Summary: "撤销注销申请"},
+
+    // 新闻
+    "POST /news/preview_news":                         {Summary: "批量获取新闻预览", Public: true, Request: controllers.PreviewNewsRequest{}, Response: controllers.PreviewNewsResponse{}},
+    "GET /news/details/news/:id":                      {Summary: "获取新闻详情", Public: true, Response: controllers.NewsDetailResponse{}},
+    "GET /news/paginated/view_count":                  {Summary: "按浏览量降序分页获取新闻", Public: true, Query: []string{"page:integer"}},
+    "GET /news/paginated/like_count":                  {Summary: "按点赞数降序分页获取新闻", Public: true, Query: []string{"page:integer"}},
+    "GET /news/paginated/upload_time":                 {Summary: "按发布时间分页获取新闻", Public: true, Query: []string{"page:integer"}},
+    "GET /news/feed":                                  {Summary: "分页获取新闻信息流", Public: true, Query: []string{"page:integer", "sort"}, Response: controllers.NewsFeedResponse{}},
+    "GET /news/comments/:id/replies":                  {Summary: "分页获取评论的回复", Public: true, Query: []string{"sort", "cursor", "limit:integer"}, Response: controllers.CommentPageResponse{}},
+    "GET /news/:id/comments":                          {Summary: "分页获取新闻的顶级评论", Public: true, Query: []string{"sort", "cursor", "limit:integer"}, Response: controllers.CommentPageResponse{}},
+    "GET /news/:id/share_card":                        {Summary: "获取新闻的分享卡片", Public: true, Response: controllers.ShareCard{}},
+    "GET /news/shared/:code":                          {Summary: "解析分享短码", Public: true},
+    "POST /news/search":                               {Summary: "搜索新闻", Public: true, Request: controllers.SearchNewsRequest{}},
+    "POST /news/upload_image":                         {Summary: "上传新闻图片", Request: openapi.Upload{File: "image"}},
+    "POST /news/create_draft":                         {Summary: "创建草稿", Request: controllers.DraftRequest{}, Status: http.StatusCreated},
+    "PUT /news/drafts/:id":                            {Summary: "更新草稿", Request: controllers.DraftRequest{}, Status: http.StatusCreated},
+    "DELETE /news/drafts/:id":                         {Summary: "删除草稿"},
+    "GET /news/details/draft/:id":                     {Summary: "获取草稿详情", Response: controllers.DraftDetailResponse{}},
+    "POST /news/preview_drafts":                       {Summary: "批量获取草稿预览", Request: controllers.PreviewDraftRequest{}, Response: controllers.PreviewDraftResponse{}},
+    "GET /news/my_drafts":                             {Summary: "获取自己的草稿"},
+    "GET /news/my_news":                               {Summary: "获取自己发布的新闻"},
+    "POST /news/convert_draft":                        {Summary: "将草稿发布为新闻", Request: controllers.ConvertDraftToNewsRequest{}},
+    "PUT /news/drafts/:id/schedule":                   {Summary: "设置草稿定时发布", Request: controllers.ScheduleDraftRequest{}},
+    "DELETE /news/drafts/:id/schedule":                {Summary: "取消草稿定时发布"},
+    "GET /news/drafts/:id/revisions":                  {Summary: "获取草稿的历史版本"},
+    "GET /news/drafts/:id/revisions/diff":             {Summary: "比较草稿的两个历史版本", Query: []string{"from:integer", "to:integer"}, Response: controllers.RevisionDiffResponse{}},
+    "POST /news/drafts/:id/revisions/:version/restore": {Summary: "将草稿恢复到指定历史版本"},
+    "PUT /news/:id":                                   {Summary: "修订已发布的新闻", Request: controllers.ReviseNewsRequest{}},
+    "GET /news/:id/revisions":                         {Summary: "获取新闻的修订记录"},
+    "DELETE /news/:id":                                {Summary: "删除新闻"},
+    "GET /news/:id/status":                            {Summary: "获取当前用户对新闻的点赞、收藏等状态"},
+    "POST /news/:id/like":                             {Summary: "点赞新闻"},
+    "DELETE /news/:id/like":                           {Summary: "取消点赞新闻"},
+    "POST /news/:id/favorite":                         {Summary: "收藏新闻，可同时放入收藏夹", Query: []string{"collection_id:integer"}},
+    "DELETE /news/:id/favorite":                       {Summary: "取消收藏新闻"},
+    "POST /news/:id/dislike":                          {Summary: "点踩新闻"},
+    "DELETE /news/:id/dislike":                        {Summary: "取消点踩新闻"},
+    "POST /news/:id/view":                             {Summary: "记录一次浏览"},
+    "PUT /news/views/:id":                             {Summary: "上报阅读时长和滚动深度", Request: controllers.ViewProgressRequest{}},
+    "POST /news/:id/share":                            {Summary: "记录一次分享并生成分享短码", Request: controllers.ShareNewsRequest{}},
+    "POST /news/comments":                             {Summary: "发表评论或回复", Request: controllers.AddCommentRequest{}, Status: http.StatusCreated},
+    "PUT /news/comments/:id":                          {Summary: "编辑评论", Request: controllers.EditCommentRequest{}},
+    "DELETE /news/comments/:id":                       {Summary: "删除评论"},
+    "POST /news/:id/comment_like":                     {Summary: "点赞评论"},
+    "DELETE /news/:id/comment_like":                   {Summary: "取消点赞评论"},
+
+    // 食物
+    "GET /foods/names":      {Summary: "获取全部食物名称", Public: true, Query: []string{"lang"}, Response: []models.FoodInfoResponse{}},
+    "POST /foods/calculate": {Summary: "计算食物的营养成分和碳排放", Request: []models.FoodCalculateItem{}, Response: []models.FoodCalculateResult{}},
+
+    // 家庭
+    "POST /families/create":                 {Summary: "创建家庭", Request: controllers.CreateFamilyRequest{}, Status: http.StatusCreated},
+    "GET /families/details":                 {Summary: "获取家庭详情和成员的营养碳排放汇总", Query: []string{"timezone"}},
+    "GET /families/search":                  {Summary: "按家庭 ID 搜索家庭", Query: []string{"family_id"}},
+    "POST /families/:id/join":               {Summary: "申请加入家庭"},
+    "POST /families/admit":                  {Summary: "批准加入家庭的申请", Request: controllers.FamilyMemberRequest{}},
+    "POST /families/reject":                 {Summary: "拒绝加入家庭的申请", Request: controllers.FamilyMemberRequest{}},
+    "DELETE /families/cancel_join":          {Summary: "撤销加入家庭的申请"},
+    "GET /families/pending_family_details":  {Summary: "获取正在申请加入的家庭"},
+    "PUT /families/set_member":              {Summary: "将管理员设为普通成员", Request: controllers.FamilyMemberRequest{}},
+    "PUT /families/set_admin":               {Summary: "将成员设为管理员", Request: controllers.FamilyMemberRequest{}},
+    "DELETE /families/leave_family":         {Summary: "退出家庭"},
+    "DELETE /families/delete_family_member": {Summary: "将成员移出家庭", Request: controllers.FamilyMemberRequest{}},
+    "DELETE /families/break":                {Summary: "解散家庭"},
+    "POST /families/add_desired_dish":       {Summary: "添加想吃的菜", Request: controllers.AddDesiredDishRequest{}},
+    "GET /families/desired_dishes":          {Summary: "获取家庭想吃的菜", Response: []controllers.GetDesiredDishesResponse{}},
+    "DELETE /families/desired_dishes":       {Summary: "删除自己添加的想吃的菜", Request: controllers.DeleteFamilyDishRequest{}},
+
+    // 食材偏好与饮食档案
+    "POST /preferences":                {Summary: "添加食材偏好", Request: controllers.FoodPreferenceRequest{}},
+    "DELETE /preferences":              {Summary: "删除食材偏好", Request: controllers.FoodPreferenceRequest{}},
+    "GET /preferences":                 {Summary: "获取食材偏好"},
+    "POST /disliked_preferences":       {Summary: "添加不喜欢的食材", Request: controllers.DislikedFoodPreferenceRequest{}},
+    "DELETE /disliked_preferences":     {Summary: "删除不喜欢的食材", Request: controllers.DislikedFoodPreferenceRequest{}},
+    "GET /disliked_preferences":        {Summary: "获取不喜欢的食材"},
+    "GET /dietary_profile/options":     {Summary: "获取过敏原、饮食限制等可选项"},
+    "GET /dietary_profile":             {Summary: "获取饮食档案", Response: models.DietaryProfile{}},
+    "PUT /dietary_profile":             {Summary: "修改饮食档案", Request: controllers.UpdateDietaryProfileRequest{}, Response: models.DietaryProfile{}},
+
+    // 推荐
+    "POST /ingredients/recommend": {Summary: "推荐食材", Request: controllers.IngredientRecommendRequest{}, Response: controllers.IngredientRecommendResponse{}},
+    "POST /ingredients/set":       {Summary: "保存本次选择的食材", Request: controllers.RecipeRecommendAndSetUserLastSelectedFoodsRequest{}},
+    "POST /recipes/recommend":     {Summary: "根据选择的食材推荐菜谱", Request: controllers.RecipeRecommendAndSetUserLastSelectedFoodsRequest{}, Response: controllers.RecipeRecommendResponse{}},
+
+    // 营养与碳排放
+    "POST /nutrition-carbon/nutrition/goals":         {Summary: "设置未来几天的营养目标", Request: []controllers.NutritionGoalRequest{}},
+    "GET /nutrition-carbon/nutrition/goals":          {Summary: "获取营养目标"},
+    "GET /nutrition-carbon/nutrition/intakes":        {Summary: "获取最近一周的营养摄入", Response: []models.NutritionIntake{}},
+    "POST /nutrition-carbon/carbon/goals":            {Summary: "设置未来几天的碳排放目标", Request: []controllers.CarbonGoalRequest{}},
+    "GET /nutrition-carbon/carbon/goals":             {Summary: "获取碳排放目标"},
+    "GET /nutrition-carbon/carbon/intakes":           {Summary: "获取最近一周的碳排放", Response: []models.CarbonIntake{}},
+    "POST /nutrition-carbon/shared/nutrition-carbon": {Summary: "记录与家庭成员共享的一餐", Request: controllers.SharedNutritionCarbonIntakeRequest{}},
+
+    // AI
+    "POST /ai/analyze-image":                 {Summary: "识别图片中的食材", Request: controllers.ImageAnalysisRequest{}, Response: controllers.ImageAnalysisResponse{}},
+    "POST /ai/recommend-similar-ingredients": {Summary: "推荐相似食材", Request: controllers.IngredientsRequest{}, Response: controllers.ImageAnalysisResponse{}},
+    "POST /ai/introduce-ingredient":          {Summary: "介绍食材", Request: controllers.IngredientsRequest{}, Response: controllers.ImageAnalysisResponse{}},
+
+    // 通知
+    "GET /notifications":              {Summary: "分页获取通知", Query: []string{"cursor", "limit:integer", "unread"}},
+    "GET /notifications/unread_count": {Summary: "获取未读通知数"},
+    "POST /notifications/read":        {Summary: "标记通知已读", Request: controllers.MarkNotificationsReadRequest{}},
+
+    // 作者数据统计
+    "GET /analytics/overview": {Summary: "获取作者的数据概览", Query: []string{"days:integer"}},
+    "GET /analytics/news/:id": {Summary: "获取单篇新闻的每日数据", Query: []string{"days:integer"}},
+    "GET /analytics/top":      {Summary: "获取指标最高的新闻", Query: []string{"days:integer", "metric", "limit:integer"}},
+    "GET /analytics/export":   {Summary: "导出每日数据", Query: []string{"days:integer"}, Response: openapi.Binary{ContentType: "text/csv"}},
+
+    // 收藏夹
+    "GET /collections":                            {Summary: "获取收藏夹列表，指定 user_id 时获取其他用户的公开收藏夹", Query: []string{"user_id:integer"}},
+    "POST /collections":                           {Summary: "创建收藏夹", Request: controllers.CreateCollectionRequest{}, Status: http.StatusCreated},
+    "PUT /collections/order":                      {Summary: "调整收藏夹顺序", Request: controllers.ReorderRequest{}},
+    "GET /collections/:id":                        {Summary: "分页获取收藏夹内容", Query: []string{"cursor", "limit:integer"}},
+    "PUT /collections/:id":                        {Summary: "修改收藏夹", Request: controllers.UpdateCollectionRequest{}},
+    "DELETE /collections/:id":                     {Summary: "删除收藏夹"},
+    "POST /collections/:id/items":                 {Summary: "向收藏夹添加新闻或菜谱", Request: controllers.AddCollectionItemRequest{}, Status: http.StatusCreated},
+    "PUT /collections/:id/items/order":            {Summary: "调整收藏夹内容的顺序", Request: controllers.ReorderRequest{}},
+    "DELETE /collections/:id/items/:item_id":      {Summary: "从收藏夹移除条目"},
+
+    // 管理员
+    "GET /admin/uploads/orphans": {Summary: "列出可回收的孤立上传文件", Query: []string{"grace_hours:integer"}, Response: controllers.UploadGCReport{}},
+    "POST /admin/uploads/gc":     {Summary: "删除超过宽限期的孤立上传文件", Query: []string{"grace_hours:integer", "dry_run"}, Response: controllers.UploadGCReport{}},
+
+    // 只注册在根路径的路由
+    "GET /static/*filepath":      {Summary: "访问上传的文件", Public: true, Response: openapi.Binary{ContentType: "application/octet-stream"}},
+    "HEAD /static/*filepath":     {Summary: "查询上传的文件", Public: true, Response: openapi.Binary{ContentType: "application/octet-stream"}},
+    "GET /.well-known/jwks.json": {Summary: "JWT 验证公钥", Public: true, Response: keyring.JWKSet{}},
+    "GET /openapi.json":          {Summary: "本文档", Public: true},
+    "GET /docs":                  {Summary: "浏览本文档的页面", Public: true, Response: openapi.Binary{ContentType: "text/html"}},
+}
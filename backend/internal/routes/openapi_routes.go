@@ -0,0 +1,68 @@
+// routes/openapi_routes.go
+package routes
+
+import (
+    "gorm.io/gorm"
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/openapi"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+// APIPrefix 使用统一响应格式的业务路由前缀
+const APIPrefix = "/api/v1"
+
+// RegisterAPIRoutes 注册全部业务路由，分别在根路径和 APIPrefix 下各调用一次
+func RegisterAPIRoutes(router gin.IRouter, db *gorm.DB, utils utils.UtilsInterface) {
+    // 注册用户路由
+    RegisterUserRoutes(router, db, utils)
+
+    // 注册新闻路由
+    RegisterNewsRoutes(router, db)
+
+    // 注册食物路由
+    RegisterFoodRoutes(router, db)
+
+    // 注册家庭路由
+    RegisterFamilyRoutes(router, db)
+
+    // 注册食材偏好路由
+    RegisterFoodPreferenceRoutes(router, db)
+
+    // 注册食材推荐路由
+    RegisterRecommendRoutes(router, db)
+
+    // 注册营养和碳排放路由
+    RegisterNutritionCarbonRoutes(router, db)
+
+    RegisterAIRoutes(router, db)
+
+    // 注册通知路由
+    RegisterNotificationRoutes(router, db)
+
+    // 注册作者数据统计路由
+    RegisterAnalyticsRoutes(router, db)
+
+    // 注册收藏夹路由
+    RegisterCollectionRoutes(router, db)
+
+    // 注册管理员路由
+    RegisterAdminRoutes(router, db)
+}
+
+// OpenAPIDocument 根据已注册的路由和 apiDocs 生成 OpenAPI 文档
+func OpenAPIDocument(routes gin.RoutesInfo) *openapi.Document {
+    return openapi.Generate(openapi.Info{
+        Title:       "DEC Sustainable Diet Helper API",
+        Description: "可持续饮食助手后端接口。" + APIPrefix + " 下的接口返回 {\"data\", \"request_id\"} 或 {\"error\": {\"code\", \"message\"}, \"request_id\"}；需要登录的接口在 Authorization 头中携带 Bearer 访问令牌",
+        Version:     "1.0.0",
+    }, routes, APIPrefix, apiDocs)
+}
+
+// RegisterOpenAPIRoutes 注册 /openapi.json 和浏览页面 /docs，需在其他路由注册完成后调用。
+// 文档在第一次请求时根据 router 中的路由生成
+func RegisterOpenAPIRoutes(router *gin.Engine) {
+    router.GET("/openapi.json", openapi.Handler(func() *openapi.Document {
+        return OpenAPIDocument(router.Routes())
+    }))
+    router.GET("/docs", openapi.ViewerHandler("/openapi.json"))
+}
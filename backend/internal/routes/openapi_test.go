@@ -0,0 +1,78 @@
+// routes/openapi_test.go
+package routes
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/gin-gonic/gin"
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/keyring"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/openapi"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
+)
+
+// setupRouter 按 main.go 的方式注册全部路由，注册阶段不访问数据库
+func setupRouter(t *testing.T) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    RegisterStaticRoutes(router, storage.NewLocal(t.TempDir(), ""))
+
+    key, err := keyring.NewHMACKey("test", []byte("test-secret-of-at-least-32-bytes"))
+    assert.NoError(t, err)
+    keys, err := keyring.New(keyring.AlgHS256, 0, key)
+    assert.NoError(t, err)
+    RegisterJWKSRoutes(router, keys)
+
+    apiV1 := router.Group(APIPrefix, middleware.APIEnvelope())
+    for _, api := range []gin.IRouter{router, apiV1} {
+        RegisterAPIRoutes(api, nil, utils.UtilsImpl{})
+    }
+    RegisterOpenAPIRoutes(router)
+    return router
+}
+
+// TestOpenAPICoversRoutes 每个注册的路由都要在 apiDocs 中有接口说明，apiDocs 中也不能有已删除的路由
+func TestOpenAPICoversRoutes(t *testing.T) {
+    router := setupRouter(t)
+
+    assert.Empty(t, openapi.Missing(router.Routes(), APIPrefix, apiDocs), "以下路由缺少接口说明，请在 routes/openapi_docs.go 中补充")
+    assert.Empty(t, openapi.Unused(router.Routes(), APIPrefix, apiDocs), "以下接口说明没有对应的路由，请从 routes/openapi_docs.go 中删除")
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+    router := setupRouter(t)
+
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var doc openapi.Document
+    assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+    assert.Equal(t, openapi.Version, doc.OpenAPI)
+
+    // 旧路由与 /api/v1 下的路由合并为一个接口
+    item := doc.Paths["/news/{id}/like"]
+    if assert.NotNil(t, item) && assert.NotNil(t, item.Post) {
+        assert.Empty(t, item.Servers)
+        assert.NotEmpty(t, item.Post.Security)
+        assert.Contains(t, item.Post.Responses, "default")
+    }
+    // 只注册在根路径的路由单独指定 servers
+    jwks := doc.Paths["/.well-known/jwks.json"]
+    if assert.NotNil(t, jwks) && assert.NotNil(t, jwks.Get) {
+        assert.Equal(t, []openapi.Server{{URL: "/"}}, jwks.Servers)
+        assert.Empty(t, jwks.Get.Security)
+    }
+    assert.Contains(t, doc.Components.Schemas, "NutritionGoalRequest")
+
+    w = httptest.NewRecorder()
+    router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+    assert.Contains(t, w.Body.String(), `href="/openapi.json"`)
+}
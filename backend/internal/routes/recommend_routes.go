@@ -8,7 +8,7 @@ import (
     "gorm.io/gorm"
 )
 
-func RegisterRecommendRoutes(router *gin.Engine, db *gorm.DB) {
+func RegisterRecommendRoutes(router gin.IRouter, db *gorm.DB) {
     controller := &controllers.RecommendController{DB: db}
     
     ingredientGroup := router.Group("/ingredients")
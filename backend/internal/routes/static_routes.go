@@ -0,0 +1,20 @@
+// routes/static_routes.go
+package routes
+
+import (
+    "github.com/gin-gonic/gin"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+)
+
+// RegisterStaticRoutes 注册上传文件的访问地址：本地存储直接提供文件，对象存储重定向到签名 URL
+func RegisterStaticRoutes(router *gin.Engine, store storage.Storage) {
+    if local, ok := store.(*storage.Local); ok {
+        router.Static("/static", local.Root())
+        return
+    }
+
+    staticController := controllers.NewStaticController(store)
+    router.GET("/static/*filepath", staticController.ServeFile)
+    router.HEAD("/static/*filepath", staticController.ServeFile)
+}
@@ -3,6 +3,7 @@ package routes
 
 import (
     "gorm.io/gorm"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/config"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/controllers"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/middleware"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/utils"
@@ -10,29 +11,57 @@ import (
     "github.com/gin-gonic/gin"
 )
 
-func RegisterUserRoutes(router *gin.Engine, db *gorm.DB, utils utils.UtilsInterface) {
+func RegisterUserRoutes(router gin.IRouter, db *gorm.DB, utils utils.UtilsInterface) {
     userController := controllers.NewUserController(db, utils)
+    accountController := controllers.NewAccountController(db)
+
+    authLimit := middleware.RateLimitMiddleware("auth", config.RateLimits.Auth)
 
     userGroup := router.Group("/users")
     {
         // 公共路由
-        userGroup.POST("/auth", userController.WeChatAuth) // 注册
+        userGroup.POST("/auth", authLimit, userController.WeChatAuth) // 注册
         userGroup.POST("/refresh", userController.RefreshTokenHandler) // 刷新令牌
         userGroup.POST("/logout", userController.LogoutHandler) // 登出
+        userGroup.POST("/login/:provider", authLimit, userController.Login) // 使用其他方式登录
+        userGroup.POST("/send_code", authLimit, userController.SendLoginCode) // 发送验证码
+        userGroup.POST("/register", authLimit, userController.Register) // 邮箱 / 手机号注册
 
         // 需要认证的路由
         authGroup := userGroup.Group("")
         authGroup.Use(middleware.AuthMiddleware())
         {
             authGroup.PUT("/set_nickname", userController.SetNickname) // 更新用户名
-            authGroup.POST("/set_avatar", userController.SetAvatar) // 更新头像
+            authGroup.POST("/set_avatar", middleware.RateLimitMiddleware("upload", config.RateLimits.Upload), userController.SetAvatar) // 更新头像
             authGroup.GET("/basic_details", userController.UserBasicDetails) // 获取基本信息
 
             authGroup.GET("/liked", userController.GetMyLikedNews)
             authGroup.GET("/favorited", userController.GetMyFavoritedNews)
             authGroup.GET("/viewed", userController.GetMyViewedNews)
+            authGroup.GET("/history", userController.GetViewHistory) // 阅读历史
+            authGroup.DELETE("/history", userController.ClearViewHistory) // 清空阅读历史
+
+            authGroup.GET("/privacy", userController.GetPrivacySettings) // 隐私设置
+            authGroup.PUT("/privacy", userController.UpdatePrivacySettings) // 更新隐私设置
 
             authGroup.GET("/:id/profile", userController.GetUserProfile)
+            authGroup.GET("/:id/liked", userController.GetUserLikedNews) // 其他用户公开的点赞列表
+            authGroup.GET("/:id/favorited", userController.GetUserFavoritedNews) // 其他用户公开的收藏列表
+            authGroup.POST("/:id/follow", userController.FollowUser) // 关注用户
+            authGroup.DELETE("/:id/follow", userController.UnfollowUser) // 取消关注
+            authGroup.POST("/:id/block", userController.BlockUser) // 屏蔽用户
+            authGroup.DELETE("/:id/block", userController.UnblockUser) // 取消屏蔽
+            authGroup.GET("/blocks", userController.GetBlockedUsers) // 屏蔽列表
+            authGroup.GET("/sessions", userController.GetSessions) // 已登录的设备
+            authGroup.DELETE("/sessions/:id", userController.RevokeSession) // 远程登出设备
+            authGroup.GET("/identities", userController.GetIdentities) // 已关联的登录方式
+            authGroup.POST("/identities/:provider", userController.LinkIdentity) // 关联登录方式
+            authGroup.DELETE("/identities/:id", userController.UnlinkIdentity) // 取消关联
+
+            authGroup.GET("/account/export", accountController.ExportAccountData) // 导出个人数据（ZIP，?format=json 为 JSON）
+            authGroup.GET("/account/deletion", accountController.GetAccountDeletion) // 注销申请状态
+            authGroup.POST("/account/deletion", accountController.RequestAccountDeletion) // 申请注销账号
+            authGroup.DELETE("/account/deletion", accountController.CancelAccountDeletion) // 撤销注销申请
         }
     }
 }
\ No newline at end of file
@@ -0,0 +1,84 @@
+// internal/scheduler/scheduler.go
+package scheduler
+
+import (
+    "log"
+    "sync"
+    "time"
+)
+
+// Job 表示一个按固定间隔运行的后台任务
+type Job struct {
+    Name     string
+    Interval time.Duration
+    Run      func(now time.Time) error
+}
+
+// Scheduler 负责在后台周期性地运行任务
+type Scheduler struct {
+    jobs    []Job
+    stop    chan struct{}
+    wg      sync.WaitGroup
+    started bool
+}
+
+// New 创建一个新的调度器
+func New() *Scheduler {
+    return &Scheduler{stop: make(chan struct{})}
+}
+
+// Add 注册任务，必须在 Start 之前调用
+func (s *Scheduler) Add(job Job) {
+    s.jobs = append(s.jobs, job)
+}
+
+// Start 为每个任务启动一个 goroutine
+func (s *Scheduler) Start() {
+    if s.started {
+        return
+    }
+    s.started = true
+
+    for _, job := range s.jobs {
+        s.wg.Add(1)
+        go s.loop(job)
+    }
+}
+
+// Stop 停止所有任务并等待正在执行的任务结束
+func (s *Scheduler) Stop() {
+    if !s.started {
+        return
+    }
+    close(s.stop)
+    s.wg.Wait()
+}
+
+func (s *Scheduler) loop(job Job) {
+    defer s.wg.Done()
+
+    ticker := time.NewTicker(job.Interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-s.stop:
+            return
+        case now := <-ticker.C:
+            runJob(job, now)
+        }
+    }
+}
+
+// runJob 执行一次任务，任务中的 panic 不会影响调度器
+func runJob(job Job, now time.Time) {
+    defer func() {
+        if r := recover(); r != nil {
+            log.Printf("后台任务 %s 发生 panic: %v", job.Name, r)
+        }
+    }()
+
+    if err := job.Run(now); err != nil {
+        log.Printf("后台任务 %s 执行失败: %v", job.Name, err)
+    }
+}
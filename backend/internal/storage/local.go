@@ -0,0 +1,160 @@
+// internal/storage/local.go
+package storage
+
+import (
+    "context"
+    "errors"
+    "io"
+    "io/fs"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+// Local 本地文件系统存储，文件通过 /static 公开访问
+type Local struct {
+    root    string
+    baseURL string
+}
+
+// NewLocal 创建本地存储，root 为存放目录，baseURL 为静态文件服务的 URL 前缀
+func NewLocal(root, baseURL string) *Local {
+    return &Local{root: root, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Root 返回存放目录
+func (l *Local) Root() string {
+    return l.root
+}
+
+// filePath 将 Key 转为本地文件路径
+func (l *Local) filePath(key string) (string, error) {
+    key, err := CleanKey(key)
+    if err != nil {
+        return "", err
+    }
+    return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+// Put 先写入同目录下的临时文件再重命名，避免读到写了一半的文件
+func (l *Local) Put(ctx context.Context, key string, data []byte, contentType string) error {
+    target, err := l.filePath(key)
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+        return err
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+    if err != nil {
+        return err
+    }
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        os.Remove(tmp.Name())
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        os.Remove(tmp.Name())
+        return err
+    }
+    if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+        os.Remove(tmp.Name())
+        return err
+    }
+    if err := os.Rename(tmp.Name(), target); err != nil {
+        os.Remove(tmp.Name())
+        return err
+    }
+    return nil
+}
+
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+    p, err := l.filePath(key)
+    if err != nil {
+        return nil, err
+    }
+    f, err := os.Open(p)
+    if errors.Is(err, fs.ErrNotExist) {
+        return nil, ErrNotExist
+    }
+    return f, err
+}
+
+func (l *Local) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+    p, err := l.filePath(key)
+    if err != nil {
+        return ObjectInfo{}, err
+    }
+    info, err := os.Stat(p)
+    if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+        return ObjectInfo{}, ErrNotExist
+    }
+    if err != nil {
+        return ObjectInfo{}, err
+    }
+    return ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+    p, err := l.filePath(key)
+    if err != nil {
+        return err
+    }
+    if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+        return err
+    }
+    return nil
+}
+
+// List 从前缀所在的目录开始遍历，跳过 Put 留下的临时文件
+func (l *Local) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+    start := l.root
+    if dir := path.Dir(prefix); strings.Contains(prefix, "/") && dir != "." {
+        if _, err := CleanKey(dir); err != nil {
+            return err
+        }
+        start = filepath.Join(l.root, filepath.FromSlash(dir))
+    }
+
+    err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return err
+        }
+        if err := ctx.Err(); err != nil {
+            return err
+        }
+        if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+            return nil
+        }
+        rel, err := filepath.Rel(l.root, p)
+        if err != nil {
+            return err
+        }
+        key := filepath.ToSlash(rel)
+        if !strings.HasPrefix(key, prefix) {
+            return nil
+        }
+        info, err := d.Info()
+        if err != nil {
+            return err
+        }
+        return fn(ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+    })
+    if errors.Is(err, fs.ErrNotExist) {
+        return nil
+    }
+    return err
+}
+
+// SignedURL 本地文件本身公开访问，直接返回静态文件 URL
+func (l *Local) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+    key, err := CleanKey(key)
+    if err != nil {
+        return "", err
+    }
+    return l.baseURL + "/" + escapePath(key), nil
+}
@@ -0,0 +1,88 @@
+// internal/storage/migrate.go
+package storage
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+)
+
+// UploadPrefixes 上传文件所在的 Key 前缀：旧头像、旧草稿图片以及处理后的图片
+var UploadPrefixes = []string{"avatars/", "drafts/", "images/"}
+
+// MigrateOptions 迁移选项
+type MigrateOptions struct {
+    Prefixes     []string                                 // 为空时使用 UploadPrefixes
+    DeleteSource bool                                     // 复制成功后删除源对象
+    DryRun       bool                                     // 只统计，不写入也不删除
+    Logf         func(format string, args ...interface{}) // 可选的进度日志
+}
+
+// MigrateStats 迁移结果
+type MigrateStats struct {
+    Copied  int // 复制的对象数
+    Skipped int // 目标中已存在且大小相同，未复制的对象数
+    Deleted int // 删除的源对象数
+    Bytes   int64
+}
+
+// Migrate 将 src 中指定前缀下的对象复制到 dst，Key 保持不变，可重复执行
+func Migrate(ctx context.Context, src, dst Storage, opts MigrateOptions) (MigrateStats, error) {
+    var stats MigrateStats
+    prefixes := opts.Prefixes
+    if len(prefixes) == 0 {
+        prefixes = UploadPrefixes
+    }
+    logf := opts.Logf
+    if logf == nil {
+        logf = func(string, ...interface{}) {}
+    }
+
+    for _, prefix := range prefixes {
+        err := src.List(ctx, prefix, func(obj ObjectInfo) error {
+            existing, err := dst.Stat(ctx, obj.Key)
+            switch {
+            case err == nil && existing.Size == obj.Size:
+                stats.Skipped++
+            case err != nil && !errors.Is(err, ErrNotExist):
+                return err
+            default:
+                if !opts.DryRun {
+                    if err := copyObject(ctx, src, dst, obj.Key); err != nil {
+                        return err
+                    }
+                }
+                stats.Copied++
+                stats.Bytes += obj.Size
+                logf("copied %s (%d bytes)", obj.Key, obj.Size)
+            }
+
+            if opts.DeleteSource && !opts.DryRun {
+                if err := src.Delete(ctx, obj.Key); err != nil {
+                    return err
+                }
+                stats.Deleted++
+            }
+            return nil
+        })
+        if err != nil {
+            return stats, fmt.Errorf("migrate %s: %w", prefix, err)
+        }
+    }
+    return stats, nil
+}
+
+// copyObject 复制单个对象
+func copyObject(ctx context.Context, src, dst Storage, key string) error {
+    r, err := src.Open(ctx, key)
+    if err != nil {
+        return err
+    }
+    data, err := io.ReadAll(r)
+    r.Close()
+    if err != nil {
+        return err
+    }
+    return dst.Put(ctx, key, data, ContentTypeOf(key))
+}
@@ -0,0 +1,261 @@
+// internal/storage/s3.go
+package storage
+
+import (
+    "bytes"
+    "context"
+    "encoding/xml"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// S3Config S3 兼容存储（AWS S3、MinIO 等）的配置
+type S3Config struct {
+    Endpoint        string // 例如 https://s3.amazonaws.com、http://minio:9000
+    Region          string
+    Bucket          string
+    AccessKeyID     string
+    SecretAccessKey string
+    PathStyle       bool // 使用 <endpoint>/<bucket>/<key> 形式的地址，MinIO 需要开启
+}
+
+// S3ConfigFromEnv 从环境变量读取 S3 配置，S3_REGION 默认 us-east-1，S3_PATH_STYLE 默认开启
+func S3ConfigFromEnv() S3Config {
+    region := os.Getenv("S3_REGION")
+    if region == "" {
+        region = "us-east-1"
+    }
+    pathStyle := true
+    if v := os.Getenv("S3_PATH_STYLE"); v != "" {
+        pathStyle, _ = strconv.ParseBool(v)
+    }
+    return S3Config{
+        Endpoint:        os.Getenv("S3_ENDPOINT"),
+        Region:          region,
+        Bucket:          os.Getenv("S3_BUCKET"),
+        AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+        SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+        PathStyle:       pathStyle,
+    }
+}
+
+// S3 S3 兼容的对象存储，请求使用 Signature Version 4 签名
+type S3 struct {
+    cfg      S3Config
+    endpoint *url.URL
+    signer   signer
+    client   *http.Client
+    now      func() time.Time
+}
+
+// NewS3 创建 S3 存储
+func NewS3(cfg S3Config) (*S3, error) {
+    if cfg.Endpoint == "" || cfg.Bucket == "" {
+        return nil, errors.New("S3 endpoint and bucket are required")
+    }
+    if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+        return nil, errors.New("S3 access key is required")
+    }
+    endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+    if err != nil || endpoint.Host == "" {
+        return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+    }
+    return &S3{
+        cfg:      cfg,
+        endpoint: endpoint,
+        signer:   signer{accessKeyID: cfg.AccessKeyID, secretAccessKey: cfg.SecretAccessKey, region: cfg.Region},
+        client:   &http.Client{Timeout: 60 * time.Second},
+        now:      time.Now,
+    }, nil
+}
+
+// bucketURL 存储桶地址，key 为空时用于列举对象
+func (s *S3) bucketURL(key string) *url.URL {
+    u := *s.endpoint
+    p := "/" + key
+    if s.cfg.PathStyle {
+        p = "/" + s.cfg.Bucket + p
+    } else {
+        u.Host = s.cfg.Bucket + "." + u.Host
+    }
+    u.Path = p
+    u.RawPath = escapePath(p)
+    return &u
+}
+
+// objectURL 对象地址
+func (s *S3) objectURL(key string) (*url.URL, error) {
+    key, err := CleanKey(key)
+    if err != nil {
+        return nil, err
+    }
+    return s.bucketURL(key), nil
+}
+
+// do 签名并发送请求，非 2xx 响应转为错误；404 返回 ErrNotExist
+func (s *S3) do(ctx context.Context, method string, u *url.URL, body []byte, header http.Header) (*http.Response, error) {
+    req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+    if err != nil {
+        return nil, err
+    }
+    req.URL = u
+    for name, values := range header {
+        req.Header[name] = values
+    }
+    payloadHash := emptyPayloadHash
+    if len(body) > 0 {
+        payloadHash = hashHex(body)
+    }
+    s.signer.sign(req, payloadHash, s.now())
+
+    resp, err := s.client.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+        return resp, nil
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode == http.StatusNotFound {
+        return nil, ErrNotExist
+    }
+    var apiErr struct {
+        Code    string `xml:"Code"`
+        Message string `xml:"Message"`
+    }
+    data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+    _ = xml.Unmarshal(data, &apiErr)
+    return nil, fmt.Errorf("s3 %s %s: %d %s %s", method, u.Path, resp.StatusCode, apiErr.Code, apiErr.Message)
+}
+
+func (s *S3) Put(ctx context.Context, key string, data []byte, contentType string) error {
+    u, err := s.objectURL(key)
+    if err != nil {
+        return err
+    }
+    header := http.Header{}
+    if contentType != "" {
+        header.Set("Content-Type", contentType)
+    }
+    resp, err := s.do(ctx, http.MethodPut, u, data, header)
+    if err != nil {
+        return err
+    }
+    resp.Body.Close()
+    return nil
+}
+
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+    u, err := s.objectURL(key)
+    if err != nil {
+        return nil, err
+    }
+    resp, err := s.do(ctx, http.MethodGet, u, nil, nil)
+    if err != nil {
+        return nil, err
+    }
+    return resp.Body, nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+    u, err := s.objectURL(key)
+    if err != nil {
+        return ObjectInfo{}, err
+    }
+    resp, err := s.do(ctx, http.MethodHead, u, nil, nil)
+    if err != nil {
+        return ObjectInfo{}, err
+    }
+    resp.Body.Close()
+
+    modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+    return ObjectInfo{Key: key, Size: resp.ContentLength, ModTime: modTime}, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+    u, err := s.objectURL(key)
+    if err != nil {
+        return err
+    }
+    resp, err := s.do(ctx, http.MethodDelete, u, nil, nil)
+    if errors.Is(err, ErrNotExist) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    resp.Body.Close()
+    return nil
+}
+
+// listBucketResult ListObjectsV2 的响应
+type listBucketResult struct {
+    IsTruncated           bool   `xml:"IsTruncated"`
+    NextContinuationToken string `xml:"NextContinuationToken"`
+    Contents              []struct {
+        Key          string    `xml:"Key"`
+        Size         int64     `xml:"Size"`
+        LastModified time.Time `xml:"LastModified"`
+    } `xml:"Contents"`
+}
+
+// List 使用 ListObjectsV2 分页遍历
+func (s *S3) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+    token := ""
+    for {
+        u := s.bucketURL("")
+        query := url.Values{}
+        query.Set("list-type", "2")
+        if prefix != "" {
+            query.Set("prefix", prefix)
+        }
+        if token != "" {
+            query.Set("continuation-token", token)
+        }
+        u.RawQuery = canonicalQuery(query)
+
+        resp, err := s.do(ctx, http.MethodGet, u, nil, nil)
+        if err != nil {
+            return err
+        }
+        var result listBucketResult
+        err = xml.NewDecoder(resp.Body).Decode(&result)
+        resp.Body.Close()
+        if err != nil {
+            return fmt.Errorf("s3 list %s: %w", prefix, err)
+        }
+
+        for _, obj := range result.Contents {
+            if err := fn(ObjectInfo{Key: obj.Key, Size: obj.Size, ModTime: obj.LastModified}); err != nil {
+                return err
+            }
+        }
+        if !result.IsTruncated || result.NextContinuationToken == "" {
+            return nil
+        }
+        token = result.NextContinuationToken
+    }
+}
+
+// SignedURL 生成限时有效的预签名 GET 地址，有效期最长 7 天
+func (s *S3) SignedURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+    u, err := s.objectURL(key)
+    if err != nil {
+        return "", err
+    }
+    if expires <= 0 {
+        expires = DefaultSignedURLExpiry
+    }
+    if expires > 7*24*time.Hour {
+        expires = 7 * 24 * time.Hour
+    }
+    return s.signer.presign(http.MethodGet, u, expires, s.now()), nil
+}
@@ -0,0 +1,224 @@
+// internal/storage/s3test/server.go
+// Package s3test 提供用于测试的内存 S3 服务，代替本地 MinIO。
+// 只实现存储后端用到的接口：PutObject、GetObject、HeadObject、DeleteObject 和 ListObjectsV2（路径形式地址）
+package s3test
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+)
+
+const (
+    AccessKeyID     = "s3test-access-key"
+    SecretAccessKey = "s3test-secret-key"
+    Region          = "us-east-1"
+)
+
+type object struct {
+    data        []byte
+    contentType string
+    modTime     time.Time
+}
+
+// Server 内存 S3 服务
+type Server struct {
+    *httptest.Server
+    Bucket string
+
+    // PageSize ListObjectsV2 每页返回的最大对象数，用于测试分页
+    PageSize int
+
+    mu      sync.Mutex
+    objects map[string]object
+}
+
+// NewServer 启动只包含一个存储桶的 S3 服务，使用完毕后需调用 Close
+func NewServer(bucket string) *Server {
+    s := &Server{Bucket: bucket, PageSize: 1000, objects: make(map[string]object)}
+    s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+    return s
+}
+
+// Config 返回连接此服务的存储配置
+func (s *Server) Config() storage.S3Config {
+    return storage.S3Config{
+        Endpoint:        s.URL,
+        Region:          Region,
+        Bucket:          s.Bucket,
+        AccessKeyID:     AccessKeyID,
+        SecretAccessKey: SecretAccessKey,
+        PathStyle:       true,
+    }
+}
+
+// Object 返回对象内容和 Content-Type
+func (s *Server) Object(key string) ([]byte, string, bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    obj, ok := s.objects[key]
+    return obj.data, obj.contentType, ok
+}
+
+// Keys 返回所有对象的 Key（已排序）
+func (s *Server) Keys() []string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    keys := make([]string, 0, len(s.objects))
+    for k := range s.objects {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+    bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+    if bucket != s.Bucket {
+        writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+        return
+    }
+
+    body, err := io.ReadAll(r.Body)
+    if err != nil {
+        writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+        return
+    }
+    if code, msg := s.authenticate(r, body); code != "" {
+        writeError(w, http.StatusForbidden, code, msg)
+        return
+    }
+
+    switch {
+    case key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+        s.list(w, r)
+    case key == "":
+        writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "Unsupported bucket operation")
+    case r.Method == http.MethodPut:
+        s.mu.Lock()
+        s.objects[key] = object{data: body, contentType: r.Header.Get("Content-Type"), modTime: time.Now().UTC()}
+        s.mu.Unlock()
+        w.WriteHeader(http.StatusOK)
+    case r.Method == http.MethodGet || r.Method == http.MethodHead:
+        s.mu.Lock()
+        obj, ok := s.objects[key]
+        s.mu.Unlock()
+        if !ok {
+            writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist")
+            return
+        }
+        w.Header().Set("Content-Type", obj.contentType)
+        w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+        w.Header().Set("Last-Modified", obj.modTime.Format(http.TimeFormat))
+        w.WriteHeader(http.StatusOK)
+        if r.Method == http.MethodGet {
+            w.Write(obj.data)
+        }
+    case r.Method == http.MethodDelete:
+        s.mu.Lock()
+        delete(s.objects, key)
+        s.mu.Unlock()
+        w.WriteHeader(http.StatusNoContent)
+    default:
+        writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "Unsupported object operation")
+    }
+}
+
+// authenticate 检查请求头签名或预签名参数中的访问密钥、时效和内容哈希。签名值本身由 storage 包的测试向量覆盖
+func (s *Server) authenticate(r *http.Request, body []byte) (string, string) {
+    query := r.URL.Query()
+    if credential := query.Get("X-Amz-Credential"); credential != "" {
+        if !strings.HasPrefix(credential, AccessKeyID+"/") || query.Get("X-Amz-Signature") == "" {
+            return "AccessDenied", "Invalid presigned credential"
+        }
+        signedAt, err := time.Parse("20060102T150405Z", query.Get("X-Amz-Date"))
+        if err != nil {
+            return "AuthorizationQueryParametersError", "Invalid X-Amz-Date"
+        }
+        expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+        if err != nil || expires <= 0 {
+            return "AuthorizationQueryParametersError", "Invalid X-Amz-Expires"
+        }
+        if time.Now().After(signedAt.Add(time.Duration(expires) * time.Second)) {
+            return "AccessDenied", "Request has expired"
+        }
+        return "", ""
+    }
+
+    auth := r.Header.Get("Authorization")
+    if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+AccessKeyID+"/") || !strings.Contains(auth, "Signature=") {
+        return "AccessDenied", "Missing or invalid Authorization header"
+    }
+    sum := sha256.Sum256(body)
+    if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+        return "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed"
+    }
+    return "", ""
+}
+
+// list 实现 ListObjectsV2，continuation-token 为上一页最后一个 Key
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+    prefix := r.URL.Query().Get("prefix")
+    after := r.URL.Query().Get("continuation-token")
+
+    s.mu.Lock()
+    keys := make([]string, 0, len(s.objects))
+    for k := range s.objects {
+        if strings.HasPrefix(k, prefix) && k > after {
+            keys = append(keys, k)
+        }
+    }
+    sort.Strings(keys)
+
+    type content struct {
+        Key          string `xml:"Key"`
+        LastModified string `xml:"LastModified"`
+        Size         int    `xml:"Size"`
+    }
+    result := struct {
+        XMLName               xml.Name  `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+        Name                  string    `xml:"Name"`
+        Prefix                string    `xml:"Prefix"`
+        KeyCount              int       `xml:"KeyCount"`
+        IsTruncated           bool      `xml:"IsTruncated"`
+        NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+        Contents              []content `xml:"Contents"`
+    }{Name: s.Bucket, Prefix: prefix}
+
+    for _, k := range keys {
+        if len(result.Contents) == s.PageSize {
+            result.IsTruncated = true
+            result.NextContinuationToken = result.Contents[len(result.Contents)-1].Key
+            break
+        }
+        obj := s.objects[k]
+        result.Contents = append(result.Contents, content{
+            Key:          k,
+            LastModified: obj.modTime.Format("2006-01-02T15:04:05.000Z"),
+            Size:         len(obj.data),
+        })
+    }
+    s.mu.Unlock()
+    result.KeyCount = len(result.Contents)
+
+    w.Header().Set("Content-Type", "application/xml")
+    io.WriteString(w, xml.Header)
+    xml.NewEncoder(w).Encode(result)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+    w.Header().Set("Content-Type", "application/xml")
+    w.WriteHeader(status)
+    fmt.Fprintf(w, "%s<Error><Code>%s</Code><Message>%s</Message></Error>", xml.Header, code, message)
+}
@@ -0,0 +1,176 @@
+// internal/storage/sigv4.go
+package storage
+
+import (
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "net/http"
+    "net/url"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// AWS Signature Version 4，参见 https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
+const (
+    sigV4Algorithm   = "AWS4-HMAC-SHA256"
+    sigV4TimeFormat  = "20060102T150405Z"
+    sigV4DateFormat  = "20060102"
+    unsignedPayload  = "UNSIGNED-PAYLOAD"
+    emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// signer 使用访问密钥为 S3 请求签名
+type signer struct {
+    accessKeyID     string
+    secretAccessKey string
+    region          string
+}
+
+// scope 凭证范围：<日期>/<区域>/s3/aws4_request
+func (s signer) scope(t time.Time) string {
+    return t.Format(sigV4DateFormat) + "/" + s.region + "/s3/aws4_request"
+}
+
+// signature 由待签名字符串计算签名
+func (s signer) signature(t time.Time, canonicalRequest string) string {
+    stringToSign := sigV4Algorithm + "\n" +
+        t.Format(sigV4TimeFormat) + "\n" +
+        s.scope(t) + "\n" +
+        hashHex([]byte(canonicalRequest))
+
+    key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), t.Format(sigV4DateFormat))
+    key = hmacSHA256(key, s.region)
+    key = hmacSHA256(key, "s3")
+    key = hmacSHA256(key, "aws4_request")
+    return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// sign 为请求添加 Authorization 头，签名覆盖 Host 以及签名时已设置的所有请求头
+func (s signer) sign(req *http.Request, payloadHash string, t time.Time) {
+    t = t.UTC()
+    req.Header.Set("X-Amz-Date", t.Format(sigV4TimeFormat))
+    req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+    headers := map[string]string{"host": requestHost(req)}
+    for name, values := range req.Header {
+        headers[strings.ToLower(name)] = strings.Join(values, ",")
+    }
+    names := make([]string, 0, len(headers))
+    for name := range headers {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    var canonicalHeaders strings.Builder
+    for _, name := range names {
+        canonicalHeaders.WriteString(name + ":" + trimHeaderValue(headers[name]) + "\n")
+    }
+    signedHeaders := strings.Join(names, ";")
+
+    canonicalRequest := req.Method + "\n" +
+        req.URL.EscapedPath() + "\n" +
+        canonicalQuery(req.URL.Query()) + "\n" +
+        canonicalHeaders.String() + "\n" +
+        signedHeaders + "\n" +
+        payloadHash
+
+    req.Header.Set("Authorization", sigV4Algorithm+
+        " Credential="+s.accessKeyID+"/"+s.scope(t)+
+        ", SignedHeaders="+signedHeaders+
+        ", Signature="+s.signature(t, canonicalRequest))
+}
+
+// presign 生成预签名 URL，只签名 Host 头，内容不参与签名
+func (s signer) presign(method string, u *url.URL, expires time.Duration, t time.Time) string {
+    t = t.UTC()
+    query := u.Query()
+    query.Set("X-Amz-Algorithm", sigV4Algorithm)
+    query.Set("X-Amz-Credential", s.accessKeyID+"/"+s.scope(t))
+    query.Set("X-Amz-Date", t.Format(sigV4TimeFormat))
+    query.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+    query.Set("X-Amz-SignedHeaders", "host")
+
+    canonicalRequest := method + "\n" +
+        u.EscapedPath() + "\n" +
+        canonicalQuery(query) + "\n" +
+        "host:" + u.Host + "\n\n" +
+        "host\n" +
+        unsignedPayload
+
+    query.Set("X-Amz-Signature", s.signature(t, canonicalRequest))
+    signed := *u
+    signed.RawQuery = canonicalQuery(query)
+    return signed.String()
+}
+
+// canonicalQuery 按参数名排序并编码查询参数
+func canonicalQuery(query url.Values) string {
+    keys := make([]string, 0, len(query))
+    for k := range query {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+
+    parts := make([]string, 0, len(keys))
+    for _, k := range keys {
+        values := append([]string(nil), query[k]...)
+        sort.Strings(values)
+        for _, v := range values {
+            parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+        }
+    }
+    return strings.Join(parts, "&")
+}
+
+// escapePath 按 S3 的规则编码对象路径，保留 /
+func escapePath(p string) string {
+    return uriEncode(p, false)
+}
+
+// uriEncode 只保留 RFC 3986 的非保留字符，其余按 UTF-8 字节百分号编码
+func uriEncode(s string, encodeSlash bool) string {
+    const hexDigits = "0123456789ABCDEF"
+    var b strings.Builder
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        switch {
+        case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+            c == '-', c == '_', c == '.', c == '~':
+            b.WriteByte(c)
+        case c == '/' && !encodeSlash:
+            b.WriteByte(c)
+        default:
+            b.WriteByte('%')
+            b.WriteByte(hexDigits[c>>4])
+            b.WriteByte(hexDigits[c&15])
+        }
+    }
+    return b.String()
+}
+
+// requestHost 返回请求实际发送的 Host
+func requestHost(req *http.Request) string {
+    if req.Host != "" {
+        return req.Host
+    }
+    return req.URL.Host
+}
+
+// trimHeaderValue 去掉首尾空白并将连续空格合并为一个
+func trimHeaderValue(v string) string {
+    return strings.Join(strings.Fields(v), " ")
+}
+
+func hashHex(data []byte) string {
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+    mac := hmac.New(sha256.New, key)
+    mac.Write([]byte(data))
+    return mac.Sum(nil)
+}
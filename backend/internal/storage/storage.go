@@ -0,0 +1,116 @@
+// internal/storage/storage.go
+package storage
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path"
+    "strings"
+    "sync"
+    "time"
+)
+
+var (
+    ErrNotExist   = errors.New("Object does not exist")
+    ErrInvalidKey = errors.New("Invalid object key")
+)
+
+// DefaultSignedURLExpiry 签名 URL 的默认有效期
+const DefaultSignedURLExpiry = 15 * time.Minute
+
+// ObjectInfo 存储对象的基本信息
+type ObjectInfo struct {
+    Key     string
+    Size    int64
+    ModTime time.Time
+}
+
+// Storage 上传文件的存储后端。Key 为以 / 分隔的相对路径，即数据库中保存的 avatar_url、图片 url 等值
+type Storage interface {
+    // Put 写入对象，已存在时覆盖
+    Put(ctx context.Context, key string, data []byte, contentType string) error
+    // Open 读取对象，不存在时返回 ErrNotExist
+    Open(ctx context.Context, key string) (io.ReadCloser, error)
+    // Stat 获取对象信息，不存在时返回 ErrNotExist
+    Stat(ctx context.Context, key string) (ObjectInfo, error)
+    // Delete 删除对象，不存在时不视为错误
+    Delete(ctx context.Context, key string) error
+    // List 按 Key 前缀遍历对象，fn 返回错误时停止遍历
+    List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+    // SignedURL 生成可直接访问对象的 URL，私有存储桶的 URL 在 expires 后失效
+    SignedURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// CleanKey 校验对象 Key，拒绝空值、绝对路径以及包含 . 或 .. 的路径
+func CleanKey(key string) (string, error) {
+    key = strings.ReplaceAll(key, "\\", "/")
+    if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == "." || key == ".." || strings.HasPrefix(key, "../") {
+        return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+    }
+    return key, nil
+}
+
+// ContentTypeOf 根据扩展名推断对象的 Content-Type
+func ContentTypeOf(key string) string {
+    switch strings.ToLower(path.Ext(key)) {
+    case ".jpg", ".jpeg":
+        return "image/jpeg"
+    case ".png":
+        return "image/png"
+    case ".gif":
+        return "image/gif"
+    case ".webp":
+        return "image/webp"
+    default:
+        return "application/octet-stream"
+    }
+}
+
+var (
+    defaultMu      sync.RWMutex
+    defaultStorage Storage
+)
+
+// SetDefault 设置全局存储后端，通常在启动时调用一次
+func SetDefault(s Storage) {
+    defaultMu.Lock()
+    defer defaultMu.Unlock()
+    defaultStorage = s
+}
+
+// Default 返回全局存储后端，未设置时按当前环境变量创建
+func Default() (Storage, error) {
+    defaultMu.RLock()
+    s := defaultStorage
+    defaultMu.RUnlock()
+    if s != nil {
+        return s, nil
+    }
+    return FromEnv()
+}
+
+// FromEnv 按 STORAGE_BACKEND 环境变量（local / s3，默认 local）创建存储后端
+func FromEnv() (Storage, error) {
+    return New(os.Getenv("STORAGE_BACKEND"))
+}
+
+// New 按名称创建存储后端，配置从环境变量读取：
+//   local：BASE_UPLOAD_PATH（默认 ./uploads）
+//   s3：S3_ENDPOINT、S3_REGION、S3_BUCKET、S3_ACCESS_KEY_ID、S3_SECRET_ACCESS_KEY、S3_PATH_STYLE
+func New(backend string) (Storage, error) {
+    switch backend {
+    case "", "local":
+        root := os.Getenv("BASE_UPLOAD_PATH")
+        if root == "" {
+            root = "./uploads" // 如果没有设置环境变量，使用默认路径
+        }
+        return NewLocal(root, "/static"), nil
+    case "s3":
+        return NewS3(S3ConfigFromEnv())
+    default:
+        return nil, fmt.Errorf("unknown storage backend %q", backend)
+    }
+}
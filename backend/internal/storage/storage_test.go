@@ -0,0 +1,170 @@
+// internal/storage/storage_test.go
+package storage_test
+
+import (
+    "context"
+    "errors"
+    "io"
+    "net/http"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage/s3test"
+)
+
+// backends 返回需要通过同一组用例的存储后端
+func backends(t *testing.T) map[string]storage.Storage {
+    server := s3test.NewServer("uploads")
+    t.Cleanup(server.Close)
+    s3, err := storage.NewS3(server.Config())
+    assert.NoError(t, err)
+
+    return map[string]storage.Storage{
+        "local": storage.NewLocal(t.TempDir(), "/static"),
+        "s3":    s3,
+    }
+}
+
+func readAll(t *testing.T, s storage.Storage, key string) []byte {
+    r, err := s.Open(context.Background(), key)
+    assert.NoError(t, err)
+    defer r.Close()
+    data, err := io.ReadAll(r)
+    assert.NoError(t, err)
+    return data
+}
+
+func listKeys(t *testing.T, s storage.Storage, prefix string) []string {
+    var keys []string
+    assert.NoError(t, s.List(context.Background(), prefix, func(obj storage.ObjectInfo) error {
+        keys = append(keys, obj.Key)
+        return nil
+    }))
+    return keys
+}
+
+func TestStorageBackends(t *testing.T) {
+    ctx := context.Background()
+    for name, s := range backends(t) {
+        t.Run(name, func(t *testing.T) {
+            assert.NoError(t, s.Put(ctx, "avatars/1_1.jpg", []byte("avatar"), "image/jpeg"))
+            assert.NoError(t, s.Put(ctx, "drafts/1/2_my photo.jpg", []byte("photo"), "image/jpeg"))
+            assert.NoError(t, s.Put(ctx, "images/news/ab/abcd/large.jpg", []byte("large!"), "image/jpeg"))
+
+            // 覆盖写入
+            assert.NoError(t, s.Put(ctx, "avatars/1_1.jpg", []byte("avatar-v2"), "image/jpeg"))
+            assert.Equal(t, []byte("avatar-v2"), readAll(t, s, "avatars/1_1.jpg"))
+            assert.Equal(t, []byte("photo"), readAll(t, s, "drafts/1/2_my photo.jpg"))
+
+            info, err := s.Stat(ctx, "images/news/ab/abcd/large.jpg")
+            assert.NoError(t, err)
+            assert.Equal(t, int64(6), info.Size)
+            assert.False(t, info.ModTime.IsZero())
+
+            _, err = s.Stat(ctx, "images/missing.jpg")
+            assert.True(t, errors.Is(err, storage.ErrNotExist))
+            _, err = s.Open(ctx, "images/missing.jpg")
+            assert.True(t, errors.Is(err, storage.ErrNotExist))
+
+            assert.ElementsMatch(t, []string{"drafts/1/2_my photo.jpg", "images/news/ab/abcd/large.jpg"},
+                append(listKeys(t, s, "drafts/"), listKeys(t, s, "images/")...))
+            assert.Len(t, listKeys(t, s, ""), 3)
+            assert.Empty(t, listKeys(t, s, "nothing/"))
+
+            assert.NoError(t, s.Delete(ctx, "avatars/1_1.jpg"))
+            assert.NoError(t, s.Delete(ctx, "avatars/1_1.jpg")) // 重复删除不报错
+            _, err = s.Stat(ctx, "avatars/1_1.jpg")
+            assert.True(t, errors.Is(err, storage.ErrNotExist))
+
+            for _, key := range []string{"", "/etc/passwd", "../secret", "images/../../x", "images/./a.jpg"} {
+                err := s.Put(ctx, key, []byte("x"), "")
+                assert.True(t, errors.Is(err, storage.ErrInvalidKey), key)
+            }
+        })
+    }
+}
+
+func TestS3SignedURL(t *testing.T) {
+    server := s3test.NewServer("uploads")
+    defer server.Close()
+    s, err := storage.NewS3(server.Config())
+    assert.NoError(t, err)
+
+    ctx := context.Background()
+    assert.NoError(t, s.Put(ctx, "images/avatar/cd/cdef/medium.jpg", []byte("jpeg-bytes"), "image/jpeg"))
+    data, contentType, ok := server.Object("images/avatar/cd/cdef/medium.jpg")
+    assert.True(t, ok)
+    assert.Equal(t, "jpeg-bytes", string(data))
+    assert.Equal(t, "image/jpeg", contentType)
+
+    // 预签名 URL 无需额外请求头即可访问
+    signed, err := s.SignedURL(ctx, "images/avatar/cd/cdef/medium.jpg", time.Minute)
+    assert.NoError(t, err)
+    resp, err := http.Get(signed)
+    assert.NoError(t, err)
+    body, _ := io.ReadAll(resp.Body)
+    resp.Body.Close()
+    assert.Equal(t, http.StatusOK, resp.StatusCode)
+    assert.Equal(t, "jpeg-bytes", string(body))
+
+    // 未签名的请求被拒绝
+    resp, err = http.Get(server.URL + "/uploads/images/avatar/cd/cdef/medium.jpg")
+    assert.NoError(t, err)
+    resp.Body.Close()
+    assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestS3ListPagination(t *testing.T) {
+    server := s3test.NewServer("uploads")
+    defer server.Close()
+    server.PageSize = 2
+    s, err := storage.NewS3(server.Config())
+    assert.NoError(t, err)
+
+    ctx := context.Background()
+    for _, key := range []string{"images/a", "images/b", "images/c", "images/d", "images/e", "other/f"} {
+        assert.NoError(t, s.Put(ctx, key, []byte(key), ""))
+    }
+    assert.Equal(t, []string{"images/a", "images/b", "images/c", "images/d", "images/e"}, listKeys(t, s, "images/"))
+}
+
+func TestMigrate(t *testing.T) {
+    server := s3test.NewServer("uploads")
+    defer server.Close()
+    s3, err := storage.NewS3(server.Config())
+    assert.NoError(t, err)
+    local := storage.NewLocal(t.TempDir(), "/static")
+
+    ctx := context.Background()
+    assert.NoError(t, local.Put(ctx, "avatars/1_1.jpg", []byte("avatar"), "image/jpeg"))
+    assert.NoError(t, local.Put(ctx, "drafts/1/2_photo.png", []byte("photo"), "image/png"))
+    assert.NoError(t, local.Put(ctx, "images/news/ab/abcd/large.webp", []byte("webp"), "image/webp"))
+    assert.NoError(t, local.Put(ctx, "tmp/ignored.txt", []byte("tmp"), ""))
+
+    // 只统计
+    stats, err := storage.Migrate(ctx, local, s3, storage.MigrateOptions{DryRun: true})
+    assert.NoError(t, err)
+    assert.Equal(t, 3, stats.Copied)
+    assert.Empty(t, server.Keys())
+
+    stats, err = storage.Migrate(ctx, local, s3, storage.MigrateOptions{})
+    assert.NoError(t, err)
+    assert.Equal(t, storage.MigrateStats{Copied: 3, Bytes: 15}, stats)
+    assert.Equal(t, []string{"avatars/1_1.jpg", "drafts/1/2_photo.png", "images/news/ab/abcd/large.webp"}, server.Keys())
+    _, contentType, _ := server.Object("images/news/ab/abcd/large.webp")
+    assert.Equal(t, "image/webp", contentType)
+
+    // 再次执行时跳过已复制的对象，并删除源文件
+    stats, err = storage.Migrate(ctx, local, s3, storage.MigrateOptions{DeleteSource: true})
+    assert.NoError(t, err)
+    assert.Equal(t, storage.MigrateStats{Skipped: 3, Deleted: 3}, stats)
+    assert.Equal(t, []string{"tmp/ignored.txt"}, listKeys(t, local, ""))
+
+    // 反向迁移回本地
+    stats, err = storage.Migrate(ctx, s3, local, storage.MigrateOptions{Prefixes: []string{"avatars/"}})
+    assert.NoError(t, err)
+    assert.Equal(t, 1, stats.Copied)
+    assert.Equal(t, []byte("avatar"), readAll(t, local, "avatars/1_1.jpg"))
+}
@@ -0,0 +1,60 @@
+// utils/diff_util.go
+package utils
+
+// DiffOp 表示差异结果中的一项操作
+type DiffOp struct {
+    Op   string `json:"op"`   // equal / insert / delete
+    Text string `json:"text"`
+}
+
+const (
+    DiffEqual  = "equal"
+    DiffInsert = "insert"
+    DiffDelete = "delete"
+)
+
+// DiffLines 基于最长公共子序列计算两组文本之间的差异
+func DiffLines(from, to []string) []DiffOp {
+    n, m := len(from), len(to)
+
+    // lcs[i][j] 表示 from[i:] 与 to[j:] 的最长公共子序列长度
+    lcs := make([][]int, n+1)
+    for i := range lcs {
+        lcs[i] = make([]int, m+1)
+    }
+    for i := n - 1; i >= 0; i-- {
+        for j := m - 1; j >= 0; j-- {
+            if from[i] == to[j] {
+                lcs[i][j] = lcs[i+1][j+1] + 1
+            } else if lcs[i+1][j] >= lcs[i][j+1] {
+                lcs[i][j] = lcs[i+1][j]
+            } else {
+                lcs[i][j] = lcs[i][j+1]
+            }
+        }
+    }
+
+    ops := make([]DiffOp, 0, n+m)
+    i, j := 0, 0
+    for i < n && j < m {
+        switch {
+        case from[i] == to[j]:
+            ops = append(ops, DiffOp{Op: DiffEqual, Text: from[i]})
+            i++
+            j++
+        case lcs[i+1][j] >= lcs[i][j+1]:
+            ops = append(ops, DiffOp{Op: DiffDelete, Text: from[i]})
+            i++
+        default:
+            ops = append(ops, DiffOp{Op: DiffInsert, Text: to[j]})
+            j++
+        }
+    }
+    for ; i < n; i++ {
+        ops = append(ops, DiffOp{Op: DiffDelete, Text: from[i]})
+    }
+    for ; j < m; j++ {
+        ops = append(ops, DiffOp{Op: DiffInsert, Text: to[j]})
+    }
+    return ops
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "strings"
+)
+
+// ETag 根据响应体生成强校验的 ETag（带引号）
+func ETag(body []byte) string {
+    sum := sha256.Sum256(body)
+    return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// WeakETag 根据响应体生成弱校验的 ETag，用于响应体在中间件中还会被改写（如加上 request_id）的接口
+func WeakETag(body []byte) string {
+    return "W/" + ETag(body)
+}
+
+// ETagMatches 判断 If-None-Match 请求头是否命中 etag，支持逗号分隔的多个值和 *，按弱比较忽略双方的 W/ 前缀
+func ETagMatches(ifNoneMatch, etag string) bool {
+    etag = strings.TrimPrefix(etag, "W/")
+    for _, candidate := range strings.Split(ifNoneMatch, ",") {
+        candidate = strings.TrimSpace(candidate)
+        if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+            return true
+        }
+    }
+    return false
+}
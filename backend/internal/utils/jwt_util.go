@@ -2,12 +2,16 @@
 package utils
 
 import (
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/hex"
     "errors"
     "strconv"
     "time"
 
     "github.com/golang-jwt/jwt/v4"
     "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/config"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/keyring"
 )
 
 // GenerateAccessToken 生成 Access Token
@@ -17,32 +21,52 @@ func GenerateAccessToken(userID uint) (string, error) {
         ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.JWTConfig.AccessTokenExpiration)),
         IssuedAt:  jwt.NewNumericDate(time.Now()),
     }
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString(config.JWTSecretKey)
+    return signToken(claims)
 }
 
-// GenerateRefreshToken 生成 Refresh Token
-func GenerateRefreshToken(userID uint) (string, error) {
+// GenerateRefreshToken 生成 Refresh Token，jti 用于在数据库中定位和撤销该令牌
+func GenerateRefreshToken(userID uint, jti string) (string, error) {
     claims := &jwt.RegisteredClaims{
         Subject:   strconv.Itoa(int(userID)),
         ExpiresAt: jwt.NewNumericDate(time.Now().Add(config.JWTConfig.RefreshTokenExpiration)),
         IssuedAt:  jwt.NewNumericDate(time.Now()),
-        // 可选：添加一个唯一标识符（JTI）用于撤销
-        // ID: uuid.New().String(),
+        ID:        jti,
     }
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString(config.JWTSecretKey)
+    return signToken(claims)
+}
+
+// signToken 使用密钥环中的当前密钥签名
+func signToken(claims jwt.Claims) (string, error) {
+    keys, err := keyring.Default()
+    if err != nil {
+        return "", err
+    }
+    return keys.Sign(claims)
+}
+
+// NewTokenID 生成随机的令牌唯一标识符（JTI）
+func NewTokenID() (string, error) {
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
+
+// HashToken 计算令牌的 SHA-256 摘要，数据库中只保存摘要而不保存令牌原文
+func HashToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
 }
 
 // ValidateToken 验证任意 Token（Access 或 Refresh）
 func ValidateToken(tokenString string) (*jwt.RegisteredClaims, error) {
-    token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-        // 检查签名方法是否为 HMAC
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, errors.New("unexpected signing method")
-        }
-        return config.JWTSecretKey, nil
-    })
+    keys, err := keyring.Default()
+    if err != nil {
+        return nil, err
+    }
+    // 按 kid 选择密钥，签名算法必须与该密钥一致
+    token, err := keys.Parse(tokenString, &jwt.RegisteredClaims{})
 
     if err != nil {
         if validationErr, ok := err.(*jwt.ValidationError); ok {
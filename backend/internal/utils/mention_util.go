@@ -0,0 +1,43 @@
+// utils/mention_util.go
+package utils
+
+import (
+    "strings"
+    "unicode"
+)
+
+// MaxMentions 单条内容最多解析的 @提及 数量，避免批量骚扰
+const MaxMentions = 10
+
+// isMentionRune 判断字符能否出现在 @提及 中：字母（含中文）、数字、下划线、连字符和点
+func isMentionRune(r rune) bool {
+    return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+// ParseMentions 按出现顺序提取内容中去重后的 @提及（不含 @）。
+// @ 必须位于开头或跟在非字母数字字符之后（排除邮箱地址），提及在空白或其它标点处结束，末尾的点视为句号
+func ParseMentions(content string) []string {
+    var mentions []string
+    seen := make(map[string]bool)
+    runes := []rune(content)
+    for i := 0; i < len(runes) && len(mentions) < MaxMentions; i++ {
+        if runes[i] != '@' && runes[i] != '＠' {
+            continue
+        }
+        if i > 0 && (unicode.IsLetter(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+            continue
+        }
+        j := i + 1
+        for j < len(runes) && isMentionRune(runes[j]) {
+            j++
+        }
+        name := strings.TrimRight(string(runes[i+1:j]), ".")
+        i = j - 1
+        if name == "" || seen[name] {
+            continue
+        }
+        seen[name] = true
+        mentions = append(mentions, name)
+    }
+    return mentions
+}
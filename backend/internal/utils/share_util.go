@@ -0,0 +1,24 @@
+package utils
+
+import (
+    "crypto/rand"
+    "math/big"
+)
+
+// ShareCodeLength 分享短码的长度
+const ShareCodeLength = 8
+
+// GenerateShareCode 生成用于分享深链的随机短码。短码会公开传播，使用 crypto/rand 避免被枚举
+func GenerateShareCode() (string, error) {
+    const charset = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789" // 去掉易混淆的 0 O 1 l I
+    code := make([]byte, ShareCodeLength)
+    max := big.NewInt(int64(len(charset)))
+    for i := range code {
+        n, err := rand.Int(rand.Reader, max)
+        if err != nil {
+            return "", err
+        }
+        code[i] = charset[n.Int64()]
+    }
+    return string(code), nil
+}
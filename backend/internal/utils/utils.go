@@ -6,7 +6,7 @@ import (
 
 type UtilsInterface interface {
     GenerateAccessToken(userID uint) (string, error)
-    GenerateRefreshToken(userID uint) (string, error)
+    GenerateRefreshToken(userID uint, jti string) (string, error)
     CopyFile(src, dst string) error
 	ValidateToken(tokenString string) (*jwt.RegisteredClaims, error)
 }
@@ -17,8 +17,8 @@ func (u UtilsImpl) GenerateAccessToken(userID uint) (string, error) {
     return GenerateAccessToken(userID)
 }
 
-func (u UtilsImpl) GenerateRefreshToken(userID uint) (string, error) {
-    return GenerateRefreshToken(userID)
+func (u UtilsImpl) GenerateRefreshToken(userID uint, jti string) (string, error) {
+    return GenerateRefreshToken(userID, jti)
 }
 
 func (u UtilsImpl) CopyFile(src, dst string) error {
@@ -0,0 +1,385 @@
+// internal/wechat/client.go
+// Package wechat 封装小程序服务端接口：code2Session 登录、access_token 缓存和内容安全检测（msgSecCheck）
+package wechat
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    DefaultBaseURL    = "https://api.weixin.qq.com"
+    DefaultTimeout    = 5 * time.Second
+    DefaultMaxRetries = 2
+
+    // tokenRefreshMargin access_token 在过期前提前刷新的时间
+    tokenRefreshMargin = 5 * time.Minute
+    // retryBackoff 第一次重试前的等待时间，之后每次翻倍
+    retryBackoff = 100 * time.Millisecond
+)
+
+// 微信接口的错误码
+const (
+    ErrCodeSystemBusy   = -1
+    ErrCodeInvalidToken = 40001
+    ErrCodeInvalidCode  = 40029
+    ErrCodeTokenExpired = 42001
+)
+
+// Config 小程序凭证和接口配置
+type Config struct {
+    AppID      string
+    AppSecret  string
+    BaseURL    string        // 默认 DefaultBaseURL，测试时指向 wechattest.Server
+    Timeout    time.Duration // 单次请求超时，默认 DefaultTimeout
+    MaxRetries int           // 网络错误、5xx 和系统繁忙时的重试次数，默认 DefaultMaxRetries，负数表示不重试
+}
+
+// ConfigFromEnv 从 APP_ID、APP_SECRET、WECHAT_API_BASE_URL 读取配置；
+// 兼容旧的 WECHAT_API_URL（完整的 jscode2session 地址）
+func ConfigFromEnv() Config {
+    baseURL := os.Getenv("WECHAT_API_BASE_URL")
+    if baseURL == "" {
+        baseURL = strings.TrimSuffix(os.Getenv("WECHAT_API_URL"), "/sns/jscode2session")
+    }
+    cfg := Config{
+        AppID:     os.Getenv("APP_ID"),
+        AppSecret: os.Getenv("APP_SECRET"),
+        BaseURL:   baseURL,
+    }
+    if v, err := time.ParseDuration(os.Getenv("WECHAT_API_TIMEOUT")); err == nil {
+        cfg.Timeout = v
+    }
+    if v, err := strconv.Atoi(os.Getenv("WECHAT_API_MAX_RETRIES")); err == nil {
+        cfg.MaxRetries = v
+    }
+    return cfg
+}
+
+// RequestError 调用微信接口失败（网络错误或重试后仍返回 5xx）
+type RequestError struct{ Err error }
+
+func (e *RequestError) Error() string { return "Failed to call WeChat API: " + e.Err.Error() }
+func (e *RequestError) Unwrap() error { return e.Err }
+
+// ResponseError 微信接口的响应无法解析
+type ResponseError struct{ Err error }
+
+func (e *ResponseError) Error() string { return "Failed to parse WeChat API response: " + e.Err.Error() }
+func (e *ResponseError) Unwrap() error { return e.Err }
+
+// APIError 微信接口返回的业务错误，如 code 无效
+type APIError struct {
+    Code    int
+    Message string
+}
+
+func (e *APIError) Error() string { return fmt.Sprintf("WeChat API error %d: %s", e.Code, e.Message) }
+
+// Session code2Session 的结果
+type Session struct {
+    OpenID     string `json:"openid"`
+    SessionKey string `json:"session_key"`
+    UnionID    string `json:"unionid"`
+}
+
+// 内容安全检测的建议
+const (
+    SuggestPass   = "pass"
+    SuggestReview = "review"
+    SuggestRisky  = "risky"
+)
+
+// 内容安全检测的场景值
+const (
+    SceneProfile = 1 // 资料
+    SceneComment = 2 // 评论
+    SceneForum   = 3 // 论坛
+    SceneSocial  = 4 // 社交日志
+)
+
+// SecCheckResult 内容安全检测结果，Label 为命中的标签，100 表示正常
+type SecCheckResult struct {
+    Suggest string `json:"suggest"`
+    Label   int    `json:"label"`
+}
+
+// Pass 内容是否可以直接发布
+func (r SecCheckResult) Pass() bool { return r.Suggest == SuggestPass }
+
+// Client 小程序服务端接口客户端，可并发使用
+type Client struct {
+    cfg   Config
+    http  *http.Client
+    now   func() time.Time
+    sleep func(time.Duration)
+
+    mu          sync.Mutex
+    token       string
+    tokenExpiry time.Time
+}
+
+// NewClient 创建客户端，未设置的配置项使用默认值
+func NewClient(cfg Config) *Client {
+    if cfg.BaseURL == "" {
+        cfg.BaseURL = DefaultBaseURL
+    }
+    cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+    if cfg.Timeout <= 0 {
+        cfg.Timeout = DefaultTimeout
+    }
+    if cfg.MaxRetries == 0 {
+        cfg.MaxRetries = DefaultMaxRetries
+    } else if cfg.MaxRetries < 0 {
+        cfg.MaxRetries = 0
+    }
+    return &Client{
+        cfg:   cfg,
+        http:  &http.Client{Timeout: cfg.Timeout},
+        now:   time.Now,
+        sleep: time.Sleep,
+    }
+}
+
+// Code2Session 使用 wx.login 得到的 code 换取 OpenID 和会话密钥
+func (c *Client) Code2Session(ctx context.Context, code string) (*Session, error) {
+    query := url.Values{
+        "appid":      {c.cfg.AppID},
+        "secret":     {c.cfg.AppSecret},
+        "js_code":    {code},
+        "grant_type": {"authorization_code"},
+    }
+    var session Session
+    if err := c.do(ctx, http.MethodGet, "/sns/jscode2session", query, nil, &session); err != nil {
+        return nil, err
+    }
+    if session.OpenID == "" {
+        return nil, &ResponseError{Err: errors.New("empty openid")}
+    }
+    return &session, nil
+}
+
+// AccessToken 返回接口调用凭证，过期前 tokenRefreshMargin 内自动刷新
+func (c *Client) AccessToken(ctx context.Context) (string, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.token != "" && c.now().Before(c.tokenExpiry) {
+        return c.token, nil
+    }
+
+    query := url.Values{
+        "grant_type": {"client_credential"},
+        "appid":      {c.cfg.AppID},
+        "secret":     {c.cfg.AppSecret},
+    }
+    var resp struct {
+        AccessToken string `json:"access_token"`
+        ExpiresIn   int    `json:"expires_in"`
+    }
+    if err := c.do(ctx, http.MethodGet, "/cgi-bin/token", query, nil, &resp); err != nil {
+        return "", err
+    }
+    if resp.AccessToken == "" {
+        return "", &ResponseError{Err: errors.New("empty access_token")}
+    }
+    c.token = resp.AccessToken
+    c.tokenExpiry = c.now().Add(time.Duration(resp.ExpiresIn)*time.Second - tokenRefreshMargin)
+    return c.token, nil
+}
+
+// invalidateToken 丢弃缓存的 access_token，只在它仍是 token 时生效，避免覆盖其他请求刚刷新的值
+func (c *Client) invalidateToken(token string) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.token == token {
+        c.token = ""
+    }
+}
+
+// MsgSecCheck 检测文本是否含有违法违规内容，openID 为发布内容的用户，需在近两小时内访问过小程序
+func (c *Client) MsgSecCheck(ctx context.Context, openID, content string, scene int) (*SecCheckResult, error) {
+    body := map[string]interface{}{
+        "content": content,
+        "version": 2,
+        "scene":   scene,
+        "openid":  openID,
+    }
+    var resp struct {
+        Result SecCheckResult `json:"result"`
+    }
+    if err := c.withToken(ctx, func(token string) error {
+        return c.do(ctx, http.MethodPost, "/wxa/msg_sec_check", url.Values{"access_token": {token}}, body, &resp)
+    }); err != nil {
+        return nil, err
+    }
+    return &resp.Result, nil
+}
+
+// withToken 使用 access_token 调用接口，凭证失效时刷新后重试一次
+func (c *Client) withToken(ctx context.Context, call func(token string) error) error {
+    token, err := c.AccessToken(ctx)
+    if err != nil {
+        return err
+    }
+    err = call(token)
+    var apiErr *APIError
+    if errors.As(err, &apiErr) && (apiErr.Code == ErrCodeInvalidToken || apiErr.Code == ErrCodeTokenExpired) {
+        c.invalidateToken(token)
+        if token, err = c.AccessToken(ctx); err != nil {
+            return err
+        }
+        return call(token)
+    }
+    return err
+}
+
+// do 发送请求并把响应解析到 out；网络错误、5xx 和系统繁忙时按指数退避重试
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body interface{}, out interface{}) error {
+    var payload []byte
+    if body != nil {
+        var err error
+        if payload, err = json.Marshal(body); err != nil {
+            return err
+        }
+    }
+
+    var lastErr error
+    for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+        if attempt > 0 {
+            c.sleep(retryBackoff << (attempt - 1))
+        }
+        if err := ctx.Err(); err != nil {
+            return &RequestError{Err: err}
+        }
+
+        data, retry, err := c.send(ctx, method, c.cfg.BaseURL+path+"?"+query.Encode(), payload)
+        if err != nil {
+            if !retry {
+                return err
+            }
+            lastErr = err
+            continue
+        }
+
+        var status struct {
+            ErrCode int    `json:"errcode"`
+            ErrMsg  string `json:"errmsg"`
+        }
+        if err := json.Unmarshal(data, &status); err != nil {
+            return &ResponseError{Err: err}
+        }
+        if status.ErrCode == ErrCodeSystemBusy {
+            lastErr = &APIError{Code: status.ErrCode, Message: status.ErrMsg}
+            continue
+        }
+        if status.ErrCode != 0 {
+            return &APIError{Code: status.ErrCode, Message: status.ErrMsg}
+        }
+        if err := json.Unmarshal(data, out); err != nil {
+            return &ResponseError{Err: err}
+        }
+        return nil
+    }
+    return lastErr
+}
+
+// send 发送一次请求，返回 2xx 响应的内容；retry 表示失败的请求是否可以重试（网络错误和 5xx）
+func (c *Client) send(ctx context.Context, method, rawURL string, payload []byte) (data []byte, retry bool, err error) {
+    var reader io.Reader
+    if payload != nil {
+        reader = bytes.NewReader(payload)
+    }
+    req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+    if err != nil {
+        return nil, false, &RequestError{Err: redactURLError(err)}
+    }
+    if payload != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+
+    resp, err := c.http.Do(req)
+    if err != nil {
+        return nil, ctx.Err() == nil, &RequestError{Err: redactURLError(err)}
+    }
+    defer resp.Body.Close()
+    if data, err = io.ReadAll(io.LimitReader(resp.Body, 1<<20)); err != nil {
+        return nil, true, &RequestError{Err: err}
+    }
+    if resp.StatusCode >= 300 {
+        return nil, resp.StatusCode >= 500, &RequestError{Err: fmt.Errorf("unexpected status %d", resp.StatusCode)}
+    }
+    return data, false, nil
+}
+
+// sensitiveParams 请求地址中的凭据，不能出现在错误信息和日志中
+var sensitiveParams = []string{"secret", "access_token", "js_code"}
+
+// redactURLError 隐去 *url.Error 中请求地址携带的 APP_SECRET、access_token 等凭据，其余信息保留用于排查
+func redactURLError(err error) error {
+    var urlErr *url.Error
+    if !errors.As(err, &urlErr) {
+        return err
+    }
+    redacted := *urlErr
+    redacted.URL = redactURL(urlErr.URL)
+    return &redacted
+}
+
+func redactURL(rawURL string) string {
+    u, err := url.Parse(rawURL)
+    if err != nil {
+        // 无法解析时整体去掉查询参数
+        if i := strings.Index(rawURL, "?"); i >= 0 {
+            return rawURL[:i]
+        }
+        return rawURL
+    }
+    query := u.Query()
+    for _, name := range sensitiveParams {
+        if query.Has(name) {
+            query.Set(name, "REDACTED")
+        }
+    }
+    u.RawQuery = query.Encode()
+    return u.String()
+}
+
+var (
+    defaultMu     sync.RWMutex
+    defaultClient *Client
+)
+
+// SetDefault 设置全局客户端，通常在启动时调用一次；测试中可指向 wechattest.Server
+func SetDefault(c *Client) {
+    defaultMu.Lock()
+    defer defaultMu.Unlock()
+    defaultClient = c
+}
+
+// Default 返回全局客户端，未设置时按当前环境变量创建并保存，以便复用缓存的 access_token
+func Default() *Client {
+    defaultMu.RLock()
+    c := defaultClient
+    defaultMu.RUnlock()
+    if c != nil {
+        return c
+    }
+
+    defaultMu.Lock()
+    defer defaultMu.Unlock()
+    if defaultClient == nil {
+        defaultClient = NewClient(ConfigFromEnv())
+    }
+    return defaultClient
+}
@@ -0,0 +1,140 @@
+// internal/wechat/client_test.go
+package wechat_test
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/wechat"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/wechat/wechattest"
+)
+
+func TestCode2Session(t *testing.T) {
+    server := wechattest.NewServer()
+    defer server.Close()
+    client := server.Client()
+    ctx := context.Background()
+
+    server.AddCode("good", "openid-1", "session-key-1")
+    session, err := client.Code2Session(ctx, "good")
+    assert.NoError(t, err)
+    assert.Equal(t, "openid-1", session.OpenID)
+    assert.Equal(t, "session-key-1", session.SessionKey)
+
+    // code 只能使用一次
+    _, err = client.Code2Session(ctx, "good")
+    var apiErr *wechat.APIError
+    assert.True(t, errors.As(err, &apiErr))
+    assert.Equal(t, wechat.ErrCodeInvalidCode, apiErr.Code)
+
+    server.SetRawResponse("bad", "not_json")
+    _, err = client.Code2Session(ctx, "bad")
+    var responseErr *wechat.ResponseError
+    assert.True(t, errors.As(err, &responseErr))
+
+    // 凭证错误不重试
+    cfg := server.Config()
+    cfg.AppSecret = "wrong"
+    _, err = wechat.NewClient(cfg).Code2Session(ctx, "good")
+    assert.True(t, errors.As(err, &apiErr))
+    assert.Equal(t, 40013, apiErr.Code)
+}
+
+func TestRetries(t *testing.T) {
+    server := wechattest.NewServer()
+    defer server.Close()
+    client := server.Client()
+    ctx := context.Background()
+
+    server.AddCode("good", "openid-1", "")
+    server.FailNext(wechat.DefaultMaxRetries)
+    session, err := client.Code2Session(ctx, "good")
+    assert.NoError(t, err)
+    assert.Equal(t, "openid-1", session.OpenID)
+    assert.Equal(t, wechat.DefaultMaxRetries+1, server.Requests("/sns/jscode2session"))
+
+    server.AddCode("again", "openid-2", "")
+    server.FailNext(wechat.DefaultMaxRetries + 1)
+    _, err = client.Code2Session(ctx, "again")
+    var requestErr *wechat.RequestError
+    assert.True(t, errors.As(err, &requestErr))
+
+    cfg := server.Config()
+    cfg.MaxRetries = -1
+    server.FailNext(1)
+    _, err = wechat.NewClient(cfg).Code2Session(ctx, "again")
+    assert.True(t, errors.As(err, &requestErr))
+}
+
+func TestTimeout(t *testing.T) {
+    slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        time.Sleep(200 * time.Millisecond)
+    }))
+    defer slow.Close()
+
+    client := wechat.NewClient(wechat.Config{BaseURL: slow.URL, Timeout: 20 * time.Millisecond, MaxRetries: -1})
+    start := time.Now()
+    _, err := client.Code2Session(context.Background(), "code")
+    var requestErr *wechat.RequestError
+    assert.True(t, errors.As(err, &requestErr))
+    assert.Less(t, time.Since(start), 150*time.Millisecond)
+}
+
+// TestRequestErrorRedactsSecret 网络错误的信息会写入日志，不能包含 APP_SECRET、access_token 和登录 code
+func TestRequestErrorRedactsSecret(t *testing.T) {
+    closed := httptest.NewServer(http.NotFoundHandler())
+    closed.Close()
+
+    client := wechat.NewClient(wechat.Config{BaseURL: closed.URL, AppID: "wx-app", AppSecret: "TOPSECRET", MaxRetries: -1})
+    _, err := client.Code2Session(context.Background(), "login-code")
+    var requestErr *wechat.RequestError
+    if assert.True(t, errors.As(err, &requestErr)) {
+        assert.NotContains(t, err.Error(), "TOPSECRET")
+        assert.NotContains(t, err.Error(), "login-code")
+        assert.Contains(t, err.Error(), "/sns/jscode2session")
+    }
+
+    // 获取 access_token 的 cgi-bin/token 请求同样携带 secret
+    _, err = client.MsgSecCheck(context.Background(), "openid", "text", 2)
+    if assert.Error(t, err) {
+        assert.NotContains(t, err.Error(), "TOPSECRET")
+    }
+}
+
+func TestMsgSecCheck(t *testing.T) {
+    server := wechattest.NewServer()
+    defer server.Close()
+    server.AddRiskyWords("违禁词")
+    client := server.Client()
+    ctx := context.Background()
+
+    result, err := client.MsgSecCheck(ctx, "openid-1", "今天吃了一份沙拉", wechat.SceneComment)
+    assert.NoError(t, err)
+    assert.True(t, result.Pass())
+
+    result, err = client.MsgSecCheck(ctx, "openid-1", "这里有违禁词", wechat.SceneComment)
+    assert.NoError(t, err)
+    assert.False(t, result.Pass())
+    assert.Equal(t, wechat.SuggestRisky, result.Suggest)
+
+    // access_token 被缓存
+    assert.Equal(t, 1, server.Requests("/cgi-bin/token"))
+    token, err := client.AccessToken(ctx)
+    assert.NoError(t, err)
+    assert.Equal(t, 1, server.Requests("/cgi-bin/token"))
+
+    // 凭证失效后刷新并重试
+    server.ExpireToken()
+    result, err = client.MsgSecCheck(ctx, "openid-1", "今天吃了一份沙拉", wechat.SceneComment)
+    assert.NoError(t, err)
+    assert.True(t, result.Pass())
+    assert.Equal(t, 2, server.Requests("/cgi-bin/token"))
+    refreshed, err := client.AccessToken(ctx)
+    assert.NoError(t, err)
+    assert.NotEqual(t, token, refreshed)
+}
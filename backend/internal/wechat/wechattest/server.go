@@ -0,0 +1,180 @@
+// internal/wechat/wechattest/server.go
+// Package wechattest 提供用于测试的进程内微信接口服务，代替手写的 HTTP mock。
+// 只实现 wechat.Client 用到的接口：jscode2session、cgi-bin/token 和 wxa/msg_sec_check
+package wechattest
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/wechat"
+)
+
+const (
+    AppID     = "wechattest-app-id"
+    AppSecret = "wechattest-app-secret"
+
+    // TokenExpiresIn access_token 的有效期（秒）
+    TokenExpiresIn = 7200
+)
+
+// Server 进程内的微信接口服务
+type Server struct {
+    *httptest.Server
+
+    mu         sync.Mutex
+    sessions   map[string]wechat.Session // js_code -> 会话
+    raw        map[string]string         // js_code -> 原样返回的响应体
+    riskyWords []string
+    tokens     []string // 已签发的 access_token，最后一个有效
+    failNext   int
+    requests   map[string]int
+}
+
+// NewServer 启动服务，使用完毕后需调用 Close
+func NewServer() *Server {
+    s := &Server{
+        sessions: make(map[string]wechat.Session),
+        raw:      make(map[string]string),
+        requests: make(map[string]int),
+    }
+    s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+    return s
+}
+
+// Config 返回连接此服务的客户端配置
+func (s *Server) Config() wechat.Config {
+    return wechat.Config{AppID: AppID, AppSecret: AppSecret, BaseURL: s.URL}
+}
+
+// Client 返回连接此服务的客户端
+func (s *Server) Client() *wechat.Client {
+    return wechat.NewClient(s.Config())
+}
+
+// AddCode 登记一个有效的登录 code，使用一次后失效
+func (s *Server) AddCode(code, openID, sessionKey string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.sessions[code] = wechat.Session{OpenID: openID, SessionKey: sessionKey}
+}
+
+// SetRawResponse 使用该 code 登录时原样返回 body，用于测试无法解析的响应
+func (s *Server) SetRawResponse(code, body string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.raw[code] = body
+}
+
+// AddRiskyWords 内容包含这些词时 msg_sec_check 返回 risky
+func (s *Server) AddRiskyWords(words ...string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.riskyWords = append(s.riskyWords, words...)
+}
+
+// FailNext 接下来的 n 个请求返回 503
+func (s *Server) FailNext(n int) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.failNext = n
+}
+
+// ExpireToken 使已签发的 access_token 失效，下一次调用返回 42001
+func (s *Server) ExpireToken() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.tokens = nil
+}
+
+// Requests 返回指定路径收到的请求数（包括返回 503 的请求）
+func (s *Server) Requests(path string) int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.requests[path]
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.requests[r.URL.Path]++
+    if s.failNext > 0 {
+        s.failNext--
+        http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+        return
+    }
+
+    query := r.URL.Query()
+    switch r.URL.Path {
+    case "/sns/jscode2session":
+        if !s.checkApp(w, query.Get("appid"), query.Get("secret")) {
+            return
+        }
+        code := query.Get("js_code")
+        if body, ok := s.raw[code]; ok {
+            w.Write([]byte(body))
+            return
+        }
+        session, ok := s.sessions[code]
+        if !ok {
+            writeError(w, wechat.ErrCodeInvalidCode, "invalid code")
+            return
+        }
+        delete(s.sessions, code)
+        writeJSON(w, session)
+
+    case "/cgi-bin/token":
+        if !s.checkApp(w, query.Get("appid"), query.Get("secret")) {
+            return
+        }
+        token := fmt.Sprintf("wechattest-token-%d", s.requests[r.URL.Path])
+        s.tokens = append(s.tokens, token)
+        writeJSON(w, map[string]interface{}{"access_token": token, "expires_in": TokenExpiresIn})
+
+    case "/wxa/msg_sec_check":
+        if len(s.tokens) == 0 || query.Get("access_token") != s.tokens[len(s.tokens)-1] {
+            writeError(w, wechat.ErrCodeTokenExpired, "access_token expired")
+            return
+        }
+        var body struct {
+            Content string `json:"content"`
+            OpenID  string `json:"openid"`
+        }
+        if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&body) != nil || body.OpenID == "" {
+            writeError(w, 47001, "data format error")
+            return
+        }
+        result := wechat.SecCheckResult{Suggest: wechat.SuggestPass, Label: 100}
+        for _, word := range s.riskyWords {
+            if strings.Contains(body.Content, word) {
+                result = wechat.SecCheckResult{Suggest: wechat.SuggestRisky, Label: 20001}
+            }
+        }
+        writeJSON(w, map[string]interface{}{"errcode": 0, "errmsg": "ok", "result": result})
+
+    default:
+        http.NotFound(w, r)
+    }
+}
+
+// checkApp 校验小程序凭证
+func (s *Server) checkApp(w http.ResponseWriter, appID, secret string) bool {
+    if appID != AppID || secret != AppSecret {
+        writeError(w, 40013, "invalid appid")
+        return false
+    }
+    return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+    writeJSON(w, map[string]interface{}{"errcode": code, "errmsg": msg})
+}
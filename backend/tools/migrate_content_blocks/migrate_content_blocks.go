@@ -0,0 +1,38 @@
+// tools/migrate_content_blocks/migrate_content_blocks.go
+// 为已有的新闻和草稿由旧的段落和图片生成有序正文块，可重复执行
+package main
+
+import (
+    "log"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/config"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "gorm.io/driver/mysql"
+    "gorm.io/gorm"
+)
+
+func main() {
+    // 加载配置
+    cfg := config.GetConfig()
+
+    // 构建DSN (Data Source Name)
+    dsn := cfg.DBUser + ":" + cfg.DBPassword + "@tcp(" + cfg.DBHost + ":" + cfg.DBPort + ")/" + cfg.DBName + "?charset=utf8mb4&parseTime=True&loc=Local"
+
+    // 连接数据库
+    db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+    if err != nil {
+        log.Fatal("无法连接到数据库:", err)
+    }
+    log.Println("数据库连接成功！")
+
+    // 确保正文块表存在
+    if err := db.AutoMigrate(&models.NewsBlock{}, &models.DraftBlock{}); err != nil {
+        log.Fatal("数据库迁移失败:", err)
+    }
+
+    migratedNews, migratedDrafts, err := models.MigrateLegacyBlocks(db)
+    if err != nil {
+        log.Fatalf("正文块迁移失败（已迁移新闻 %d 篇，草稿 %d 篇）: %v", migratedNews, migratedDrafts, err)
+    }
+    log.Printf("正文块迁移完成：新闻 %d 篇，草稿 %d 篇", migratedNews, migratedDrafts)
+}
@@ -0,0 +1,54 @@
+// tools/migrate_storage/migrate_storage.go
+// 在存储后端之间迁移已上传的头像和新闻图片，Key 保持不变，可重复执行。
+// 本地存储读取 BASE_UPLOAD_PATH，S3 存储读取 S3_ENDPOINT、S3_BUCKET 等环境变量。
+//
+// 用法：
+//   go run ./tools/migrate_storage -from local -to s3 [-dry-run] [-delete-source] [-prefixes avatars/,images/]
+package main
+
+import (
+    "context"
+    "flag"
+    "log"
+    "strings"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/storage"
+    "github.com/joho/godotenv"
+)
+
+func main() {
+    from := flag.String("from", "local", "源存储后端：local / s3")
+    to := flag.String("to", "s3", "目标存储后端：local / s3")
+    dryRun := flag.Bool("dry-run", false, "只统计需要迁移的文件，不写入")
+    deleteSource := flag.Bool("delete-source", false, "复制成功后删除源文件")
+    prefixes := flag.String("prefixes", strings.Join(storage.UploadPrefixes, ","), "需要迁移的 Key 前缀，逗号分隔")
+    flag.Parse()
+
+    // 加载.env文件
+    if err := godotenv.Load("../.env"); err != nil {
+        log.Println("没有找到 .env 文件，使用环境变量或默认配置")
+    }
+
+    if *from == *to {
+        log.Fatal("源存储和目标存储不能相同")
+    }
+    src, err := storage.New(*from)
+    if err != nil {
+        log.Fatal("无法创建源存储:", err)
+    }
+    dst, err := storage.New(*to)
+    if err != nil {
+        log.Fatal("无法创建目标存储:", err)
+    }
+
+    stats, err := storage.Migrate(context.Background(), src, dst, storage.MigrateOptions{
+        Prefixes:     strings.Split(*prefixes, ","),
+        DeleteSource: *deleteSource,
+        DryRun:       *dryRun,
+        Logf:         log.Printf,
+    })
+    if err != nil {
+        log.Fatalf("存储迁移失败（已复制 %d 个文件）: %v", stats.Copied, err)
+    }
+    log.Printf("存储迁移完成：复制 %d 个文件（%d 字节），跳过 %d 个，删除源文件 %d 个", stats.Copied, stats.Bytes, stats.Skipped, stats.Deleted)
+}
@@ -0,0 +1,48 @@
+// tools/reconcile_counters/reconcile_counters.go
+// 按关联表重新计算点赞、收藏、点踩计数并报告偏差；默认只报告，加 -fix 修复，可重复执行
+package main
+
+import (
+    "flag"
+    "log"
+
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/config"
+    "github.com/Alchuang22-dev/DEC_sustainable_diet_helper/internal/models"
+    "gorm.io/driver/mysql"
+    "gorm.io/gorm"
+)
+
+func main() {
+    fix := flag.Bool("fix", false, "将偏差的计数改为关联表中的实际行数")
+    flag.Parse()
+
+    // 加载配置
+    cfg := config.GetConfig()
+
+    // 构建DSN (Data Source Name)
+    dsn := cfg.DBUser + ":" + cfg.DBPassword + "@tcp(" + cfg.DBHost + ":" + cfg.DBPort + ")/" + cfg.DBName + "?charset=utf8mb4&parseTime=True&loc=Local"
+
+    // 连接数据库
+    db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+    if err != nil {
+        log.Fatal("无法连接到数据库:", err)
+    }
+    log.Println("数据库连接成功！")
+
+    drifts, err := models.ReconcileCounters(db, *fix)
+    for _, d := range drifts {
+        log.Println(d)
+    }
+    if err != nil {
+        log.Fatalf("计数核对失败（已发现偏差 %d 条）: %v", len(drifts), err)
+    }
+
+    switch {
+    case len(drifts) == 0:
+        log.Println("计数核对完成：没有偏差")
+    case *fix:
+        log.Printf("计数核对完成：已修复 %d 条偏差", len(drifts))
+    default:
+        log.Printf("计数核对完成：发现 %d 条偏差，使用 -fix 修复", len(drifts))
+    }
+}